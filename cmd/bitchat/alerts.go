@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// AlertMode define como o usuário é avisado de uma mensagem recebida
+type AlertMode string
+
+const (
+	AlertNone   AlertMode = "none"   // Sem aviso
+	AlertBell   AlertMode = "bell"   // Campainha do terminal
+	AlertNotify AlertMode = "notify" // Notificação da área de trabalho
+
+	// AlertMentions silencia o escopo, exceto quando o usuário é mencionado
+	// com @apelido; as menções geram uma notificação da área de trabalho
+	AlertMentions AlertMode = "mentions"
+
+	// AlertDefaultScope vale para canais e peers sem configuração própria
+	AlertDefaultScope = "default"
+)
+
+// parseAlertMode converte o nome de um modo de alerta
+func parseAlertMode(name string) (AlertMode, bool) {
+	switch mode := AlertMode(strings.ToLower(name)); mode {
+	case AlertNone, AlertBell, AlertNotify, AlertMentions:
+		return mode, true
+	}
+	return "", false
+}
+
+// alertScope retorna o escopo de alerta de uma mensagem: o canal ou, para
+// mensagens privadas, o @apelido do remetente. Peers são identificados pelo
+// apelido porque o ID do dispositivo muda a cada execução.
+func alertScope(message *protocol.BitchatMessage) string {
+	if message.IsPrivate || message.Channel == "" {
+		return "@" + message.Sender
+	}
+	return message.Channel
+}
+
+// alertModeFor retorna o modo de alerta configurado para uma mensagem
+func alertModeFor(settings *Settings, message *protocol.BitchatMessage) AlertMode {
+	if mode, ok := settings.Alerts[alertScope(message)]; ok {
+		return mode
+	}
+	if mode, ok := settings.Alerts[AlertDefaultScope]; ok {
+		return mode
+	}
+	return AlertNone
+}
+
+// effectiveAlertMode resolve o modo mentions: uma notificação se a mensagem
+// menciona o apelido do usuário, nenhum aviso caso contrário
+func effectiveAlertMode(settings *Settings, message *protocol.BitchatMessage, nickname string) AlertMode {
+	mode := alertModeFor(settings, message)
+	if mode != AlertMentions {
+		return mode
+	}
+	if message.MentionsNickname(nickname) {
+		return AlertNotify
+	}
+	return AlertNone
+}
+
+// alertMessage avisa o usuário de uma mensagem recebida conforme as
+// preferências. Sem um serviço de notificações, a campainha é usada.
+func alertMessage(settings *Settings, message *protocol.BitchatMessage, nickname string) {
+	switch effectiveAlertMode(settings, message, nickname) {
+	case AlertBell:
+		fmt.Fprint(os.Stdout, "\a")
+	case AlertNotify:
+		title := fmt.Sprintf("Bitchat: %s", message.Sender)
+		if !message.IsPrivate && message.Channel != "" {
+			title = fmt.Sprintf("Bitchat: %s em %s", message.Sender, message.Channel)
+		}
+		desktopNotify(title, message.Content)
+	}
+}
+
+// desktopNotify exibe uma notificação da área de trabalho ou, sem um
+// serviço de notificações, toca a campainha
+func desktopNotify(title, body string) {
+	if err := exec.Command("notify-send", title, body).Start(); err != nil {
+		fmt.Fprint(os.Stdout, "\a")
+	}
+}
+
+// notifyCommand processa /notify [escopo modo]
+func notifyCommand(appState *AppState, args string) {
+	parts := strings.Fields(args)
+	if len(parts) == 0 {
+		scopes := make([]string, 0, len(appState.Settings.Alerts))
+		for scope := range appState.Settings.Alerts {
+			scopes = append(scopes, scope)
+		}
+		sort.Strings(scopes)
+
+		fmt.Println("Alertas:")
+		if _, ok := appState.Settings.Alerts[AlertDefaultScope]; !ok {
+			fmt.Printf("  %s: %s\n", AlertDefaultScope, AlertNone)
+		}
+		for _, scope := range scopes {
+			fmt.Printf("  %s: %s\n", scope, appState.Settings.Alerts[scope])
+		}
+		return
+	}
+
+	scope := parts[0]
+	if len(parts) != 2 || (scope != AlertDefaultScope && !strings.HasPrefix(scope, "#") && !strings.HasPrefix(scope, "@")) {
+		fmt.Println("Uso: /notify [#canal|@nome|default] [bell|notify|mentions|none]")
+		return
+	}
+	mode, ok := parseAlertMode(parts[1])
+	if !ok {
+		fmt.Println("Modo inválido. Use: bell, notify, mentions ou none")
+		return
+	}
+
+	appState.Settings.Alerts[scope] = mode
+	if err := appState.Settings.Save(); err != nil {
+		fmt.Println("Erro ao salvar preferências:", err)
+	}
+	fmt.Printf("Alerta de %s: %s\n", scope, mode)
+}
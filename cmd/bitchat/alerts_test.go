@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// channelMessage cria uma mensagem de canal com as menções do conteúdo,
+// como o serviço mesh as entrega
+func channelMessage(sender, content string) *protocol.BitchatMessage {
+	return &protocol.BitchatMessage{
+		Sender:   sender,
+		Channel:  "#geral",
+		Content:  content,
+		Mentions: protocol.ParseMentions(content),
+	}
+}
+
+func TestEffectiveAlertMode(t *testing.T) {
+	mentions := &Settings{Alerts: map[string]AlertMode{"#geral": AlertMentions}}
+
+	testCases := []struct {
+		name     string
+		content  string
+		expected AlertMode
+	}{
+		{"Menção ao apelido", "oi @ana", AlertNotify},
+		{"Maiúsculas e minúsculas", "oi @ANA e @Ana", AlertNotify},
+		{"Pontuação em volta", "(@ana), tudo bem?", AlertNotify},
+		{"Pontuação ao final", "fala @ana!", AlertNotify},
+		{"Apelido mais longo", "oi @anabela", AlertNone},
+		{"Arroba no meio da palavra", "escreva para ana@exemplo.org", AlertNone},
+		{"Apelido sem arroba", "oi ana", AlertNone},
+		{"Menção a outra pessoa", "oi @bia", AlertNone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			message := channelMessage("bia", tc.content)
+			if mode := effectiveAlertMode(mentions, message, "ana"); mode != tc.expected {
+				t.Errorf("Modo esperado %s para %q, obtido %s", tc.expected, tc.content, mode)
+			}
+		})
+	}
+
+	t.Run("Outros modos não dependem de menções", func(t *testing.T) {
+		settings := &Settings{Alerts: map[string]AlertMode{AlertDefaultScope: AlertBell}}
+		if mode := effectiveAlertMode(settings, channelMessage("bia", "oi"), "ana"); mode != AlertBell {
+			t.Errorf("Modo esperado %s, obtido %s", AlertBell, mode)
+		}
+		if mode := effectiveAlertMode(&Settings{}, channelMessage("bia", "oi @ana"), "ana"); mode != AlertNone {
+			t.Errorf("Sem configuração, esperado %s, obtido %s", AlertNone, mode)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// loadAliases adota os aliases da configuração, ignorando os que
+// sobrescreveriam comandos do Bitchat
+func loadAliases(appState *AppState, aliases map[string]string) {
+	builtin := make(map[string]bool, len(commandNames))
+	for _, name := range commandNames {
+		builtin[name] = true
+	}
+
+	appState.Aliases = make(map[string]string, len(aliases))
+	for name, expansion := range aliases {
+		if builtin[name] {
+			fmt.Printf("Aviso: alias %s ignorado, o comando já existe\n", name)
+			continue
+		}
+		appState.Aliases[name] = expansion
+	}
+}
+
+// aliasNames retorna, em ordem, os nomes dos aliases definidos
+func aliasNames(appState *AppState) []string {
+	names := make([]string, 0, len(appState.Aliases))
+	for name := range appState.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// aliasSteps divide a expansão de um alias em passos, separados por ;, e
+// acrescenta os argumentos da chamada ao último passo
+func aliasSteps(expansion, args string) []string {
+	steps := strings.Split(expansion, ";")
+	for i := range steps {
+		steps[i] = strings.TrimSpace(steps[i])
+	}
+	if args = strings.TrimSpace(args); args != "" {
+		steps[len(steps)-1] += " " + args
+	}
+	return steps
+}
+
+// runAlias executa os passos de um alias. Aliases usados dentro de um alias
+// não são expandidos, o que evita recursão. Retorna false se command não é
+// um alias.
+func runAlias(command, args string, appState *AppState) bool {
+	expansion, ok := appState.Aliases[command]
+	if !ok || appState.ExpandingAlias {
+		return false
+	}
+
+	appState.ExpandingAlias = true
+	defer func() { appState.ExpandingAlias = false }()
+	for _, step := range aliasSteps(expansion, args) {
+		processUserInput(step, appState)
+	}
+	return true
+}
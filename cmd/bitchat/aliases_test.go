@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAliasSteps(t *testing.T) {
+	testCases := []struct {
+		name      string
+		expansion string
+		args      string
+		expected  []string
+	}{
+		{"Passo único", "/j #geral", "", []string{"/j #geral"}},
+		{"Vários passos", "/j #geral; /topic", "", []string{"/j #geral", "/topic"}},
+		{"Argumentos vão para o último passo", "/j #geral;/m", "@bia oi", []string{"/j #geral", "/m @bia oi"}},
+		{"Espaços em volta dos argumentos", "/w", "  #geral  ", []string{"/w #geral"}},
+		{"Passo vazio é mantido", "/w;;/stats", "", []string{"/w", "", "/stats"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if steps := aliasSteps(tc.expansion, tc.args); !reflect.DeepEqual(steps, tc.expected) {
+				t.Errorf("Passos esperados %q, obtidos %q", tc.expected, steps)
+			}
+		})
+	}
+}
+
+func TestLoadAliases(t *testing.T) {
+	appState := &AppState{}
+	loadAliases(appState, map[string]string{
+		"/g":    "/j #geral",
+		"/join": "/j #outro", // Sobrescreveria um comando
+	})
+
+	if !reflect.DeepEqual(aliasNames(appState), []string{"/g"}) {
+		t.Errorf("Apenas /g deveria ser adotado, obtidos %v", aliasNames(appState))
+	}
+}
+
+func TestRunAlias(t *testing.T) {
+	t.Run("Comando que não é alias", func(t *testing.T) {
+		appState := &AppState{Aliases: map[string]string{"/g": "/j #geral"}}
+		if runAlias("/x", "", appState) {
+			t.Error("/x não é um alias")
+		}
+	})
+
+	testCases := []struct {
+		name    string
+		aliases map[string]string
+	}{
+		{"Alias que chama a si mesmo", map[string]string{"/loop": "/loop"}},
+		{"Aliases que se chamam mutuamente", map[string]string{"/a": "/b; /a", "/b": "/a"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			appState := &AppState{Aliases: tc.aliases}
+			for name := range tc.aliases {
+				// Os aliases internos não são expandidos, então a execução termina
+				if !runAlias(name, "", appState) {
+					t.Fatalf("%s deveria ser executado", name)
+				}
+				if appState.ExpandingAlias {
+					t.Fatal("Expansão deveria ser encerrada ao fim do alias")
+				}
+			}
+		})
+	}
+
+	t.Run("Alias dentro de outro alias não é expandido", func(t *testing.T) {
+		appState := &AppState{Aliases: map[string]string{"/g": "/j #geral"}, ExpandingAlias: true}
+		if runAlias("/g", "", appState) {
+			t.Error("Alias não deveria ser expandido durante outra expansão")
+		}
+	})
+}
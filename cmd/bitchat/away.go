@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/internal/service"
+)
+
+// awayCommand processa /away [mensagem|log]: sem argumentos mostra o estado
+// da ausência; com log, as respostas automáticas enviadas
+func awayCommand(appState *AppState, args string) {
+	args = strings.TrimSpace(args)
+	switch args {
+	case "":
+		message, since, ok := appState.MeshService.GetAway()
+		if !ok {
+			fmt.Println("Você não está ausente. Uso: /away mensagem")
+			return
+		}
+		fmt.Printf("Ausente desde %s: %q\n", since.Format("15:04"), message)
+		return
+	case "log":
+		awayLog(appState)
+		return
+	}
+
+	appState.MeshService.SetAway(args)
+	appState.Settings.AwayMessage = args
+	if err := appState.Settings.Save(); err != nil {
+		fmt.Println("Erro ao salvar preferências:", err)
+	}
+	fmt.Printf("Ausente: mensagens privadas serão respondidas com %q (/back para voltar)\n", args)
+}
+
+// backCommand processa /back
+func backCommand(appState *AppState) {
+	if _, _, ok := appState.MeshService.GetAway(); !ok {
+		fmt.Println("Você não está ausente")
+		return
+	}
+
+	appState.MeshService.SetAway("")
+	appState.Settings.AwayMessage = ""
+	if err := appState.Settings.Save(); err != nil {
+		fmt.Println("Erro ao salvar preferências:", err)
+	}
+	fmt.Println("Bem-vindo de volta")
+	awayLog(appState)
+}
+
+// awayLog lista as respostas automáticas enviadas
+func awayLog(appState *AppState) {
+	replies := appState.MeshService.AwayReplies()
+	if len(replies) == 0 {
+		fmt.Println("Nenhuma resposta automática enviada")
+		return
+	}
+
+	fmt.Println("Respostas automáticas enviadas:")
+	for _, reply := range replies {
+		fmt.Printf("  [%s] %s: %q\n", reply.SentAt.Format("15:04:05"), appState.Theme.Sender(reply.Nickname, false), reply.Content)
+	}
+}
+
+// OnAwayReplySent é chamado quando uma resposta automática é enviada; ela é
+// guardada na conversa como as mensagens enviadas pelo usuário
+func (md *MeshDelegateImpl) OnAwayReplySent(reply service.AwayReply) {
+	message := &protocol.BitchatMessage{
+		ID:                reply.MessageID,
+		Sender:            md.AppState.Config.DeviceName,
+		Content:           reply.Content,
+		Timestamp:         uint64(reply.SentAt.UnixMilli()),
+		IsPrivate:         true,
+		RecipientNickname: reply.Nickname,
+		DeliveryStatus:    protocol.DeliveryStatusSending,
+	}
+	md.AppState.PrivateMessages[reply.PeerID] = append(md.AppState.PrivateMessages[reply.PeerID], message)
+	md.AppState.Store.AddPrivateMessage(conversationKey(reply.Nickname), message)
+
+	md.AppState.Events.Emit(Event{Event: "away_reply", Time: reply.SentAt.UnixMilli(), PeerID: jsonPeerID(reply.PeerID),
+		MessageID: reply.MessageID, Recipient: reply.Nickname, Content: reply.Content})
+	fmt.Printf("Resposta automática enviada a %s\n", reply.Nickname)
+}
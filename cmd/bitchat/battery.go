@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/permissionlesstech/bitchat/internal/bluetooth"
+)
+
+// batteryUsage descreve os argumentos de /battery
+const batteryUsage = "Uso: /battery [normal|low|ultralow|auto [baixa ultrabaixa]]"
+
+// batteryThresholds retorna os limites do modo automático configurados
+func batteryThresholds(config *Config) bluetooth.BatteryThresholds {
+	return bluetooth.BatteryThresholds{Low: config.BatteryLow, UltraLow: config.BatteryUltraLow}
+}
+
+// batteryCommand processa /battery: sem argumentos exibe o modo atual; com
+// um modo, o fixa; com auto, passa a escolher o modo pelo nível da bateria
+func batteryCommand(appState *AppState, args string) {
+	meshService := appState.MeshService
+	fields := strings.Fields(strings.ToLower(args))
+	if len(fields) == 0 {
+		status := batteryModeName(meshService.GetBatteryMode())
+		status += powerStateSuffix(meshService.GetPowerState())
+		if thresholds, auto := meshService.BatteryAuto(); auto {
+			status += fmt.Sprintf(", automático: low em %d%%, ultralow em %d%%", thresholds.Low, thresholds.UltraLow)
+		}
+		fmt.Println("Modo de bateria:", status)
+		fmt.Println(batteryUsage)
+		return
+	}
+
+	var mode int
+	switch fields[0] {
+	case "normal":
+		mode = bluetooth.BatteryModeNormal
+	case "low":
+		mode = bluetooth.BatteryModeLow
+	case "ultralow":
+		mode = bluetooth.BatteryModeUltraLow
+	case "auto":
+		batteryAutoCommand(appState, fields[1:])
+		return
+	default:
+		fmt.Println("Modo inválido. Use: normal, low, ultralow ou auto")
+		return
+	}
+
+	meshService.DisableBatteryAuto()
+	meshService.SetBatteryMode(mode)
+	fmt.Printf("Modo de bateria alterado para: %s\n", fields[0])
+}
+
+// batteryAutoCommand processa /battery auto [baixa ultrabaixa]
+func batteryAutoCommand(appState *AppState, args []string) {
+	thresholds := batteryThresholds(appState.Config)
+	if len(args) > 0 {
+		if len(args) != 2 {
+			fmt.Println(batteryUsage)
+			return
+		}
+		low, errLow := strconv.Atoi(strings.TrimSuffix(args[0], "%"))
+		ultraLow, errUltraLow := strconv.Atoi(strings.TrimSuffix(args[1], "%"))
+		if errLow != nil || errUltraLow != nil {
+			fmt.Println(batteryUsage)
+			return
+		}
+		thresholds = bluetooth.BatteryThresholds{Low: low, UltraLow: ultraLow}
+	}
+
+	if err := appState.MeshService.EnableBatteryAuto(thresholds); err != nil {
+		fmt.Println("Erro ao ativar modo automático:", err)
+		return
+	}
+	fmt.Printf("Modo de bateria automático: low em %d%%, ultralow em %d%%\n", thresholds.Low, thresholds.UltraLow)
+	if _, ok := appState.MeshService.GetBatteryLevel(); !ok {
+		fmt.Println("Nível de bateria ainda não informado pela plataforma")
+	}
+}
+
+// OnBatteryModeChanged é chamado quando o modo automático troca o modo de bateria
+func (md *MeshDelegateImpl) OnBatteryModeChanged(mode int, level int) {
+	md.AppState.Events.Emit(Event{Event: "battery_mode", Status: batteryModeName(mode), Level: level})
+	fmt.Printf("Bateria em %d%%: modo de bateria alterado para %s\n", level, batteryModeName(mode))
+}
+
+// powerStateSuffix descreve o nível de bateria e o estado de carga, como
+// " (80%, carregando)", ou retorna vazio se a plataforma não os informa
+func powerStateSuffix(state bluetooth.PowerState) string {
+	var parts []string
+	if state.LevelKnown {
+		parts = append(parts, fmt.Sprintf("%d%%", state.Level))
+	}
+	if state.ChargingKnown && state.Charging {
+		parts = append(parts, "carregando")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// powerStatusName retorna o estado de carga como aparece nos eventos JSON
+func powerStatusName(charging bool) string {
+	if charging {
+		return "charging"
+	}
+	return "discharging"
+}
+
+// OnPowerStateChanged é chamado quando o nível de bateria ou o estado de
+// carga muda; só a troca do estado de carga (e não a leitura inicial) é
+// exibida
+func (md *MeshDelegateImpl) OnPowerStateChanged(state bluetooth.PowerState) {
+	if !state.ChargingKnown {
+		return
+	}
+	md.AppState.Events.Emit(Event{Event: "power", Status: powerStatusName(state.Charging), Level: state.Level})
+
+	previous := md.AppState.Charging
+	md.AppState.Charging = powerStatusName(state.Charging)
+	if previous == "" || previous == md.AppState.Charging {
+		return
+	}
+	if state.Charging {
+		fmt.Println("Carregando: limites de bateria suspensos")
+	} else {
+		fmt.Println("Carregador desconectado: limites de bateria restabelecidos")
+	}
+}
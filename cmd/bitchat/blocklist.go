@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/permissionlesstech/bitchat/internal/bluetooth"
+)
+
+// saveBlocklist guarda as identidades bloqueadas para a próxima execução
+func saveBlocklist(appState *AppState) {
+	appState.Settings.Blocked = appState.MeshService.BlockedFingerprints()
+	if err := appState.Settings.Save(); err != nil {
+		fmt.Println("Erro ao salvar preferências:", err)
+	}
+}
+
+// contactNames retorna os apelidos registrados para uma identidade
+func contactNames(appState *AppState, fingerprint string) []string {
+	var names []string
+	for name, known := range appState.Settings.Contacts {
+		if known == fingerprint {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// listBlocked exibe as identidades e os peers bloqueados
+func listBlocked(appState *AppState) {
+	fmt.Println("Peers bloqueados:")
+	fingerprints := appState.MeshService.BlockedFingerprints()
+	if len(fingerprints) == 0 && len(appState.BlockedPeers) == 0 {
+		fmt.Println("  Nenhum peer bloqueado")
+		return
+	}
+	for _, fingerprint := range fingerprints {
+		if names := contactNames(appState, fingerprint); len(names) > 0 {
+			fmt.Printf("  %s (%s)\n", fingerprint, strings.Join(names, ", "))
+		} else {
+			fmt.Printf("  %s\n", fingerprint)
+		}
+	}
+	for id := range appState.BlockedPeers {
+		name := "desconhecido"
+		if n, ok := appState.activePeerName(id); ok {
+			name = n
+		}
+		fmt.Printf("  %s (%x, apenas nesta execução)\n", name, id)
+	}
+}
+
+// blockCommand processa /block [@nome|impressão digital]. Peers com chaves
+// conhecidas são bloqueados pela identidade, o que sobrevive a reinícios e
+// a trocas de apelido.
+func blockCommand(appState *AppState, args string) {
+	target := strings.TrimSpace(args)
+	if target == "" {
+		listBlocked(appState)
+		return
+	}
+
+	if !strings.HasPrefix(target, "@") {
+		blocked, err := appState.MeshService.BlockFingerprint(target)
+		if err != nil {
+			fmt.Println("Uso: /block @usuario|impressão digital")
+			return
+		}
+		saveBlocklist(appState)
+		fmt.Printf("Identidade %s bloqueada", target)
+		if len(blocked) > 0 {
+			fmt.Printf(" (%d peers online)", len(blocked))
+		}
+		fmt.Println()
+		return
+	}
+
+	username := target[1:] // Remover @
+	peerID := findPeerByName(appState, username)
+	if peerID == "" {
+		fmt.Printf("Usuário %s não encontrado\n", username)
+		return
+	}
+
+	info, _ := appState.MeshService.GetPeerInfo(peerID)
+	if info.Fingerprint == "" {
+		// Sem chaves, o bloqueio vale apenas para o ID desta execução
+		appState.BlockedPeers[peerID] = true
+		fmt.Printf("Usuário %s bloqueado até o fim da execução (identidade ainda desconhecida)\n", username)
+		return
+	}
+	if _, err := appState.MeshService.BlockFingerprint(info.Fingerprint); err != nil {
+		fmt.Println("Erro ao bloquear:", err)
+		return
+	}
+	saveBlocklist(appState)
+	fmt.Printf("Usuário %s bloqueado (identidade %s)\n", username, info.Fingerprint)
+}
+
+// unblockCommand processa /unblock @nome|impressão digital
+func unblockCommand(appState *AppState, args string) {
+	target := strings.TrimSpace(args)
+	if target == "" {
+		fmt.Println("Uso: /unblock @usuario|impressão digital")
+		return
+	}
+
+	fingerprint := target
+	if strings.HasPrefix(target, "@") {
+		username := target[1:] // Remover @
+		if peerID := findPeerByName(appState, username); peerID != "" && appState.BlockedPeers[peerID] {
+			delete(appState.BlockedPeers, peerID)
+			fmt.Printf("Usuário %s desbloqueado\n", username)
+			return
+		}
+
+		// Peers bloqueados pela identidade não aparecem entre os ativos
+		known, ok := appState.Settings.Contacts[username]
+		if !ok {
+			fmt.Printf("Usuário %s não encontrado\n", username)
+			return
+		}
+		fingerprint = known
+	}
+
+	if _, err := bluetooth.NormalizeFingerprint(fingerprint); err != nil {
+		fmt.Println("Uso: /unblock @usuario|impressão digital")
+		return
+	}
+	if err := appState.MeshService.UnblockFingerprint(fingerprint); err != nil {
+		fmt.Println("Erro ao desbloquear:", err)
+		return
+	}
+	saveBlocklist(appState)
+	fmt.Printf("%s desbloqueado\n", target)
+}
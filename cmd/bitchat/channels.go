@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// joinChannel entra em um canal, mantendo os demais canais, e o coloca em foco
+func joinChannel(appState *AppState, channel string) {
+	appState.mutex.Lock()
+	joined := appState.JoinedChannels[channel]
+	appState.JoinedChannels[channel] = true
+	appState.mutex.Unlock()
+
+	if !joined {
+		delete(appState.LeftChannels, channel)
+		saveJoinedChannels(appState)
+	}
+	fmt.Printf("Entrando no canal %s\n", channel)
+	printChannelTopic(appState, channel)
+	focusChannel(appState, channel)
+}
+
+// focusChannel coloca em foco um canal em que o usuário já entrou e exibe
+// o histórico
+func focusChannel(appState *AppState, channel string) {
+	appState.CurrentChannel = channel
+	appState.CurrentDM = ""
+
+	appState.mutex.Lock()
+	delete(appState.Unread, channel)
+	messages := append([]*protocol.BitchatMessage(nil), appState.MessageHistory[channel]...)
+	appState.mutex.Unlock()
+
+	// Exibir histórico do canal se disponível
+	if len(messages) > 0 {
+		fmt.Printf("--- Histórico do canal %s ---\n", channel)
+		for _, msg := range messages {
+			fmt.Printf("%s %s: %s\n",
+				appState.Theme.Time("["+time.Unix(0, int64(msg.Timestamp)*int64(time.Millisecond)).Format("15:04:05")+"]"),
+				appState.Theme.Sender(msg.Sender, msg.Sender == appState.Config.DeviceName),
+				appState.Theme.Content(msg.Content, appState.Config.DeviceName))
+		}
+		fmt.Println("--- Fim do histórico ---")
+	}
+}
+
+// joinedChannels retorna, em ordem, os canais em que o usuário entrou
+func joinedChannels(appState *AppState) []string {
+	appState.mutex.RLock()
+	defer appState.mutex.RUnlock()
+
+	channels := make([]string, 0, len(appState.JoinedChannels))
+	for channel := range appState.JoinedChannels {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// announceJoinedChannels anuncia à rede os canais em que o usuário está,
+// para a descoberta de canais dos peers
+func announceJoinedChannels(appState *AppState) {
+	channels := joinedChannels(appState)
+	entries := make([]protocol.ChannelListEntry, 0, len(channels))
+	for _, channel := range channels {
+		entries = append(entries, protocol.ChannelListEntry{Channel: channel})
+	}
+	appState.MeshService.SetLocalChannels(entries)
+}
+
+// saveJoinedChannels guarda os canais para reentrar neles na próxima execução
+// e os anuncia à rede
+func saveJoinedChannels(appState *AppState) {
+	announceJoinedChannels(appState)
+	appState.Settings.Channels = joinedChannels(appState)
+	if err := appState.Settings.Save(); err != nil {
+		fmt.Println("Erro ao salvar preferências:", err)
+	}
+}
+
+// switchCommand processa /switch [#canal]
+func switchCommand(appState *AppState, args string) {
+	channel := strings.TrimSpace(args)
+	if channel == "" {
+		channels := joinedChannels(appState)
+		if len(channels) == 0 {
+			fmt.Println("Você não está em nenhum canal. Use /j #canal para entrar em um canal.")
+			return
+		}
+		fmt.Println("Seus canais:")
+		for _, channel := range channels {
+			marker := " "
+			if channel == appState.CurrentChannel && appState.CurrentDM == "" {
+				marker = "*"
+			}
+			if unread := unreadCount(appState, channel); unread > 0 {
+				fmt.Printf(" %s %s (%d não lidas)\n", marker, channel, unread)
+			} else {
+				fmt.Printf(" %s %s\n", marker, channel)
+			}
+		}
+		return
+	}
+
+	if !appState.isJoined(channel) {
+		fmt.Printf("Você não está no canal %s. Use /j %s para entrar.\n", channel, channel)
+		return
+	}
+	focusChannel(appState, channel)
+}
+
+// leaveCommand processa /leave [#canal]: sai do canal indicado ou do canal em
+// foco, avisando a rede, e deixa de exibir e guardar suas mensagens
+func leaveCommand(appState *AppState, args string) {
+	channel := strings.TrimSpace(args)
+	if channel == "" {
+		channel = appState.CurrentChannel
+	}
+	if !appState.isJoined(channel) {
+		fmt.Println("Uso: /leave [#canal]")
+		return
+	}
+
+	if err := appState.MeshService.LeaveChannel(channel); err != nil {
+		fmt.Println("Erro ao sair do canal:", err)
+		return
+	}
+
+	appState.mutex.Lock()
+	delete(appState.JoinedChannels, channel)
+	delete(appState.MessageHistory, channel)
+	delete(appState.Unread, channel)
+	delete(appState.ChannelMembers, channel)
+	appState.mutex.Unlock()
+	appState.LeftChannels[channel] = true
+	saveJoinedChannels(appState)
+	fmt.Printf("Você saiu do canal %s\n", channel)
+
+	// Colocar em foco outro canal, se houver
+	if appState.CurrentChannel == channel {
+		appState.CurrentChannel = ""
+		if channels := joinedChannels(appState); len(channels) > 0 {
+			focusChannel(appState, channels[0])
+			fmt.Printf("Canal em foco: %s\n", channels[0])
+		}
+	}
+}
+
+// recordChannelMember registra um peer visto em um canal
+func recordChannelMember(appState *AppState, channel string, peerID string) {
+	appState.mutex.Lock()
+	defer appState.mutex.Unlock()
+
+	members, ok := appState.ChannelMembers[channel]
+	if !ok {
+		members = make(map[string]bool)
+		appState.ChannelMembers[channel] = members
+	}
+	members[peerID] = true
+}
+
+// unreadCount retorna as mensagens não lidas de um canal fora de foco
+func unreadCount(appState *AppState, channel string) int {
+	appState.mutex.RLock()
+	defer appState.mutex.RUnlock()
+
+	return appState.Unread[channel]
+}
+
+// printChannelTopic exibe o tópico conhecido de um canal
+func printChannelTopic(appState *AppState, channel string) bool {
+	info, ok := appState.MeshService.ChannelInfo(channel)
+	if !ok || info.Topic == "" {
+		return false
+	}
+	if info.OwnerID == appState.EncryptionService.GetPeerID() {
+		fmt.Printf("Tópico de %s: %s (você é o dono)\n", channel, info.Topic)
+	} else {
+		fmt.Printf("Tópico de %s: %s\n", channel, info.Topic)
+	}
+	return true
+}
+
+// topicCommand processa /topic [#canal] [tópico]: sem tópico exibe o atual,
+// com tópico o define, se o usuário for o dono do canal
+func topicCommand(appState *AppState, args string) {
+	args = strings.TrimSpace(args)
+	channel := appState.CurrentChannel
+	if strings.HasPrefix(args, "#") {
+		parts := strings.SplitN(args, " ", 2)
+		channel = parts[0]
+		args = ""
+		if len(parts) > 1 {
+			args = strings.TrimSpace(parts[1])
+		}
+	}
+	if channel == "" {
+		fmt.Println("Uso: /topic [#canal] [tópico]")
+		return
+	}
+
+	if args == "" {
+		if !printChannelTopic(appState, channel) {
+			fmt.Printf("O canal %s não tem tópico\n", channel)
+		}
+		return
+	}
+
+	if err := appState.MeshService.SetChannelTopic(channel, args); err != nil {
+		fmt.Println("Erro ao definir tópico:", err)
+		return
+	}
+	fmt.Printf("Tópico de %s definido: %s\n", channel, args)
+}
+
+// channelsCommand processa /channels: lista os canais anunciados pelos peers
+// e os canais com histórico local, com o número de membros
+func channelsCommand(appState *AppState) {
+	discovered := appState.MeshService.DiscoveredChannels()
+	listed := make(map[string]bool, len(discovered))
+
+	appState.mutex.RLock()
+	defer appState.mutex.RUnlock()
+
+	var lines []string
+	for _, channel := range discovered {
+		listed[channel.Name] = true
+		details := []string{fmt.Sprintf("%d membros", channel.Members)}
+		if channel.Joined || appState.JoinedChannels[channel.Name] {
+			details = append([]string{"membro"}, details...)
+		}
+		if unread := appState.Unread[channel.Name]; unread > 0 {
+			details = append(details, fmt.Sprintf("%d não lidas", unread))
+		}
+		if channel.Protected {
+			details = append(details, "protegido")
+		}
+		lines = append(lines, fmt.Sprintf("  %s (%s)", channel.Name, strings.Join(details, ", ")))
+	}
+
+	// Canais com histórico local que nenhum peer anunciou
+	var local []string
+	for channel := range appState.MessageHistory {
+		if !listed[channel] {
+			local = append(local, channel)
+		}
+	}
+	sort.Strings(local)
+	for _, channel := range local {
+		lines = append(lines, fmt.Sprintf("  %s (sem membros anunciados, %d peers vistos)", channel, len(appState.ChannelMembers[channel])))
+	}
+
+	fmt.Println("Canais na rede:")
+	if len(lines) == 0 {
+		fmt.Println("  Nenhum canal conhecido")
+		return
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
+
+// updateChannelDelivery atualiza o status de uma mensagem enviada a um canal
+// e mostra quantos membros já a receberam. Retorna false se a mensagem não é
+// de canal.
+func updateChannelDelivery(appState *AppState, messageID string, status protocol.DeliveryStatus, info *protocol.DeliveryInfo) bool {
+	if info == nil || info.TotalPeers == 0 {
+		return false
+	}
+
+	appState.mutex.Lock()
+	defer appState.mutex.Unlock()
+
+	for channel, messages := range appState.MessageHistory {
+		for _, message := range messages {
+			if message.ID != messageID {
+				continue
+			}
+			message.DeliveryStatus = status
+			fmt.Printf("✓ Mensagem entregue a %d de %d membros de %s\n", info.ReachedPeers, info.TotalPeers, channel)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ConfigFileName é o arquivo de configuração lido do diretório de dados
+// quando --config não é informado
+const ConfigFileName = "bitchat.conf"
+
+// FileConfig é a configuração lida do arquivo, no formato INI:
+//
+//	[aliases]
+//	/ops = /j #operations
+//
+//	[hooks]
+//	message = /usr/local/bin/on-message
+//
+// Linhas iniciadas por # ou ; são comentários. Seções desconhecidas são
+// ignoradas.
+type FileConfig struct {
+	Aliases map[string]string // Comando -> comandos separados por ;
+	Hooks   map[string]string // Evento -> script executado
+}
+
+// LoadFileConfig lê o arquivo de configuração. Um arquivo inexistente
+// resulta em configuração vazia.
+func LoadFileConfig(path string) (*FileConfig, error) {
+	config := &FileConfig{Aliases: make(map[string]string), Hooks: make(map[string]string)}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, fmt.Errorf("erro ao ler configuração: %v", err)
+	}
+	defer file.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return config, fmt.Errorf("erro na configuração %s, linha %d: esperado chave = valor", path, number)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch section {
+		case "aliases":
+			if !strings.HasPrefix(key, "/") || strings.ContainsAny(key, " \t") {
+				return config, fmt.Errorf("erro na configuração %s, linha %d: alias inválido %q", path, number, key)
+			}
+			config.Aliases[key] = value
+		case "hooks":
+			if !validHook(key) {
+				return config, fmt.Errorf("erro na configuração %s, linha %d: evento desconhecido %q", path, number, key)
+			}
+			config.Hooks[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return config, fmt.Errorf("erro ao ler configuração: %v", err)
+	}
+	return config, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfig grava um arquivo de configuração temporário
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Erro ao gravar configuração: %v", err)
+	}
+	return path
+}
+
+func TestLoadFileConfig(t *testing.T) {
+	t.Run("Arquivo inexistente", func(t *testing.T) {
+		config, err := LoadFileConfig(filepath.Join(t.TempDir(), ConfigFileName))
+		if err != nil {
+			t.Fatalf("Arquivo inexistente não deveria falhar: %v", err)
+		}
+		if len(config.Aliases) != 0 || len(config.Hooks) != 0 {
+			t.Errorf("Configuração deveria estar vazia: %+v", config)
+		}
+	})
+
+	t.Run("Seções, comentários e chaves desconhecidas", func(t *testing.T) {
+		path := writeConfig(t, `
+# Comentário
+; Outro comentário
+[Aliases]
+/ops = /j #operations ; /topic
+  /g=/j #geral
+
+[hooks]
+message = /usr/local/bin/on-message
+
+[extras]
+qualquer = coisa
+`)
+		config, err := LoadFileConfig(path)
+		if err != nil {
+			t.Fatalf("Erro ao ler configuração: %v", err)
+		}
+
+		aliases := map[string]string{"/ops": "/j #operations ; /topic", "/g": "/j #geral"}
+		if !reflect.DeepEqual(config.Aliases, aliases) {
+			t.Errorf("Aliases esperados %v, obtidos %v", aliases, config.Aliases)
+		}
+		hooks := map[string]string{HookMessage: "/usr/local/bin/on-message"}
+		if !reflect.DeepEqual(config.Hooks, hooks) {
+			t.Errorf("Hooks esperados %v, obtidos %v", hooks, config.Hooks)
+		}
+	})
+
+	testCases := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"Linha sem chave e valor", "[aliases]\n/g = /j #geral\n/ops\n", "linha 3: esperado chave = valor"},
+		{"Alias sem barra", "[aliases]\nops = /j #operations\n", `alias inválido "ops"`},
+		{"Alias com espaço", "[aliases]\n/o ps = /j #operations\n", `alias inválido "/o ps"`},
+		{"Evento desconhecido", "[hooks]\nboot = /bin/true\n", `evento desconhecido "boot"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadFileConfig(writeConfig(t, tc.content))
+			if err == nil || !strings.Contains(err.Error(), tc.message) {
+				t.Errorf("Erro esperado com %q, obtido %v", tc.message, err)
+			}
+		})
+	}
+}
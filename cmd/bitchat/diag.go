@@ -0,0 +1,350 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/logging"
+)
+
+const (
+	// DiagDefaultWait é a espera padrão pela descoberta de vizinhos antes de
+	// coletar as estatísticas da rede mesh
+	DiagDefaultWait = 10 * time.Second
+
+	// MaxDiagLogBytes é o máximo lido do fim de cada arquivo de log
+	MaxDiagLogBytes = 256 << 10
+
+	// DiagCommandTimeout limita cada comando do sistema executado na coleta
+	DiagCommandTimeout = 5 * time.Second
+
+	// diagRedacted substitui os dados removidos do pacote de diagnóstico
+	diagRedacted = "[redigido]"
+)
+
+// diagSensitiveSettings são as preferências omitidas do pacote de diagnóstico
+var diagSensitiveSettings = []string{"contacts", "blocked", "favorites", "away_message"}
+
+var (
+	// Endereços Bluetooth (MAC) do adaptador e dos dispositivos vizinhos
+	diagMACPattern = regexp.MustCompile(`\b([0-9A-Fa-f]{2}[:_-]){5}[0-9A-Fa-f]{2}\b`)
+
+	// Impressões digitais e chaves em hexadecimal
+	diagHexPattern = regexp.MustCompile(`\b[0-9A-Fa-f]{32,}\b`)
+)
+
+// diagRedactor remove do texto dados que identificam o usuário: endereços
+// Bluetooth, chaves e o diretório home
+type diagRedactor struct {
+	home string
+}
+
+// newDiagRedactor cria o filtro para o usuário atual
+func newDiagRedactor() *diagRedactor {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "/" {
+		home = ""
+	}
+	return &diagRedactor{home: home}
+}
+
+// Redact retorna o texto sem os dados sensíveis
+func (dr *diagRedactor) Redact(text string) string {
+	text = diagMACPattern.ReplaceAllString(text, "XX:XX:XX:XX:XX:XX")
+	text = diagHexPattern.ReplaceAllString(text, diagRedacted)
+	if dr.home != "" {
+		text = strings.ReplaceAll(text, dr.home, "~")
+	}
+	return text
+}
+
+// diagArchive escreve os arquivos do pacote de diagnóstico em um .tar.gz,
+// passando todo o conteúdo pelo filtro
+type diagArchive struct {
+	tar      *tar.Writer
+	redactor *diagRedactor
+	created  time.Time
+}
+
+// Add acrescenta um arquivo ao pacote
+func (da *diagArchive) Add(name string, content string) error {
+	data := []byte(da.redactor.Redact(content))
+	header := &tar.Header{
+		Name:    "bitchat-diag/" + name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: da.created,
+	}
+	if err := da.tar.WriteHeader(header); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %v", name, err)
+	}
+	if _, err := da.tar.Write(data); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %v", name, err)
+	}
+	return nil
+}
+
+// runDiagCommand executa o subcomando diag: aguarda a descoberta de vizinhos
+// e grava um pacote com versões, adaptador, log, estatísticas e
+// configuração, sem dados pessoais, para anexar a relatos de problemas
+//
+//	bitchat diag [--output bitchat-diag.tar.gz] [--wait 10s]
+func runDiagCommand(appState *AppState, args []string) int {
+	flags := flag.NewFlagSet("diag", flag.ContinueOnError)
+	defaultOutput := fmt.Sprintf("bitchat-diag-%s.tar.gz", time.Now().Format("20060102-150405"))
+	output := flags.String("output", defaultOutput, "Arquivo do pacote de diagnóstico")
+	wait := flags.Duration("wait", DiagDefaultWait, "Espera pela descoberta de vizinhos antes de coletar as estatísticas")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		fmt.Println("Uso: bitchat diag [--output arquivo.tar.gz] [--wait 10s]")
+		return 2
+	}
+
+	fmt.Printf("Coletando diagnóstico por %v...\n", *wait)
+	time.Sleep(*wait)
+
+	if err := writeDiagArchive(appState, *output); err != nil {
+		fmt.Println("Erro ao gerar diagnóstico:", err)
+		return 1
+	}
+	fmt.Println("Diagnóstico gravado em", *output)
+	fmt.Println("Confira o conteúdo antes de anexá-lo a um relato de problema")
+	return 0
+}
+
+// writeDiagArchive grava o pacote de diagnóstico em path
+func writeDiagArchive(appState *AppState, path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("erro ao criar pacote: %v", err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	archive := &diagArchive{
+		tar:      tar.NewWriter(gz),
+		redactor: newDiagRedactor(),
+		created:  time.Now(),
+	}
+
+	files := []struct {
+		name    string
+		collect func(*AppState) string
+	}{
+		{"versions.txt", diagVersions},
+		{"adapter.txt", diagAdapter},
+		{"stats.txt", diagStats},
+		{"health.txt", diagHealth},
+		{"metrics.prom", diagMetrics},
+		{"config.json", diagConfig},
+		{"settings.json", diagSettings},
+	}
+	for _, f := range files {
+		if err := archive.Add(f.name, f.collect(appState)); err != nil {
+			return err
+		}
+	}
+	if err := addDiagLogs(archive, appState.Config); err != nil {
+		return err
+	}
+
+	if err := archive.tar.Close(); err != nil {
+		return fmt.Errorf("erro ao finalizar pacote: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("erro ao finalizar pacote: %v", err)
+	}
+	return file.Close()
+}
+
+// diagVersions descreve as versões do Bitchat, do Go, do sistema e do BlueZ
+func diagVersions(appState *AppState) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "bitchat: %s\n", AppVersion)
+	fmt.Fprintf(&b, "go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if release, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		fmt.Fprintf(&b, "kernel: %s\n", strings.TrimSpace(string(release)))
+	}
+	fmt.Fprintf(&b, "bluez: %s\n", diagExec("bluetoothctl", "--version"))
+	fmt.Fprintf(&b, "gerado em: %s\n", time.Now().Format(time.RFC3339))
+	return b.String()
+}
+
+// diagAdapter descreve o adaptador Bluetooth e o estado do rádio
+func diagAdapter(appState *AppState) string {
+	var b strings.Builder
+	config := appState.Config
+	fmt.Fprintf(&b, "adaptador: %q, adaptador de escaneamento: %q\n", config.Adapter, config.ScanAdapter)
+	fmt.Fprintf(&b, "rádio: %s\n", appState.MeshService.GetRadioState())
+	fmt.Fprintf(&b, "conexões: %+v\n", appState.MeshService.GetConnectionConfig())
+	fmt.Fprintf(&b, "\n$ bluetoothctl show\n%s\n", diagExec("bluetoothctl", "show"))
+	if entries, err := os.ReadDir("/sys/class/bluetooth"); err == nil {
+		b.WriteString("\n/sys/class/bluetooth:\n")
+		for _, entry := range entries {
+			fmt.Fprintf(&b, "  %s\n", entry.Name())
+		}
+	}
+	return b.String()
+}
+
+// diagStats retorna as estatísticas exibidas por /stats
+func diagStats(appState *AppState) string {
+	var b strings.Builder
+	writeStats(&b, appState)
+	return b.String()
+}
+
+// diagHealth retorna a situação exibida por /health
+func diagHealth(appState *AppState) string {
+	var b strings.Builder
+	writeHealth(&b, checkHealth(appState))
+	return b.String()
+}
+
+// diagMetrics retorna as métricas da rede mesh no formato do Prometheus
+func diagMetrics(appState *AppState) string {
+	var b strings.Builder
+	writeMetrics(&b, appState)
+	return b.String()
+}
+
+// diagConfig retorna as opções de linha de comando, sem o apelido, e os
+// nomes dos aliases e hooks configurados; os comandos, que podem conter
+// credenciais, são omitidos
+func diagConfig(appState *AppState) string {
+	flags := *appState.Config
+	flags.DeviceName = diagRedacted
+
+	aliases := make([]string, 0, len(appState.Aliases))
+	for name := range appState.Aliases {
+		aliases = append(aliases, name)
+	}
+	sort.Strings(aliases)
+	hooks := make([]string, 0, len(appState.Hooks))
+	for event := range appState.Hooks {
+		hooks = append(hooks, event)
+	}
+	sort.Strings(hooks)
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"flags":   flags,
+		"aliases": aliases,
+		"hooks":   hooks,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("erro ao codificar configuração: %v\n", err)
+	}
+	return string(data) + "\n"
+}
+
+// diagSettings retorna as preferências salvas sem contatos, listas de
+// bloqueio e favoritos e a mensagem de ausência
+func diagSettings(appState *AppState) string {
+	data, err := json.Marshal(appState.Settings)
+	if err != nil {
+		return fmt.Sprintf("erro ao codificar preferências: %v\n", err)
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return fmt.Sprintf("erro ao codificar preferências: %v\n", err)
+	}
+	for _, key := range diagSensitiveSettings {
+		if _, ok := settings[key]; ok {
+			settings[key] = diagRedacted
+		}
+	}
+
+	data, err = json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("erro ao codificar preferências: %v\n", err)
+	}
+	return string(data) + "\n"
+}
+
+// addDiagLogs acrescenta ao pacote o fim do arquivo de log e dos arquivos
+// rotacionados
+func addDiagLogs(archive *diagArchive, config *Config) error {
+	if config.LogFile == "" {
+		return archive.Add("log.txt", "Log não gravado em arquivo; execute com --log-file para incluí-lo\n")
+	}
+
+	paths := []string{config.LogFile}
+	for i := 1; i <= logging.DefaultMaxBackups; i++ {
+		paths = append(paths, fmt.Sprintf("%s.%d", config.LogFile, i))
+	}
+	for i, path := range paths {
+		content, err := readTail(path, MaxDiagLogBytes)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			content = fmt.Sprintf("erro ao ler log: %v\n", err)
+		}
+		name := "log.txt"
+		if i > 0 {
+			name = fmt.Sprintf("log.%d.txt", i)
+		}
+		if err := archive.Add(name, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readTail lê no máximo limit bytes do fim do arquivo, a partir do início de
+// uma linha
+func readTail(path string, limit int64) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	offset := info.Size() - limit
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	content := string(data)
+	if offset > 0 {
+		if i := strings.IndexByte(content, '\n'); i >= 0 {
+			content = content[i+1:]
+		}
+	}
+	return content, nil
+}
+
+// diagExec executa um comando de diagnóstico do sistema, retornando sua
+// saída ou o motivo da falha
+func diagExec(name string, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), DiagCommandTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil && len(out) == 0 {
+		return fmt.Sprintf("indisponível (%v)", err)
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/utils"
+)
+
+// conversationKey identifica no armazenamento a conversa privada com um
+// peer. O apelido é usado porque o ID do dispositivo muda a cada execução.
+func conversationKey(nickname string) string {
+	return utils.Hash(nickname)
+}
+
+// deliveryMarker retorna o marcador exibido ao lado de uma mensagem enviada
+func deliveryMarker(status protocol.DeliveryStatus) string {
+	switch status {
+	case protocol.DeliveryStatusSending:
+		return "…"
+	case protocol.DeliveryStatusSent:
+		return "✓"
+	case protocol.DeliveryStatusDelivered, protocol.DeliveryStatusPartiallyDelivered:
+		return "✓✓"
+	case protocol.DeliveryStatusRead:
+		return "✓✓ lida"
+	case protocol.DeliveryStatusFailed:
+		return "✗ falhou"
+	}
+	return ""
+}
+
+// printConversationMessage exibe uma mensagem da conversa privada em foco.
+// Mensagens enviadas levam o marcador de entrega.
+func printConversationMessage(appState *AppState, message *protocol.BitchatMessage) {
+	theme := appState.Theme
+	timestamp := theme.Time("[" + time.UnixMilli(int64(message.Timestamp)).Format("15:04:05") + "]")
+	if message.Sender == appState.Config.DeviceName {
+		fmt.Printf("%s %s: %s %s\n", timestamp, theme.Sender(message.Sender, true), message.Content, deliveryMarker(message.DeliveryStatus))
+		return
+	}
+	fmt.Printf("%s %s: %s\n", timestamp, theme.Sender(message.Sender, false), theme.Content(message.Content, appState.Config.DeviceName))
+}
+
+// openConversation coloca em foco a conversa privada com um peer e exibe o
+// histórico guardado
+func openConversation(appState *AppState, nickname string) {
+	appState.CurrentDM = nickname
+	fmt.Printf("Conversa privada com %s (/dm para voltar ao canal)\n", nickname)
+
+	messages := appState.Store.GetPrivateMessages(conversationKey(nickname))
+	if len(messages) == 0 {
+		return
+	}
+	fmt.Printf("--- Histórico com %s ---\n", nickname)
+	for _, message := range messages {
+		printConversationMessage(appState, message)
+	}
+	fmt.Println("--- Fim do histórico ---")
+	markConversationRead(appState, nickname)
+}
+
+// dmCommand processa /dm [@nome]
+func dmCommand(appState *AppState, args string) {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		if appState.CurrentDM == "" {
+			fmt.Println("Uso: /dm @usuario")
+			return
+		}
+		fmt.Printf("Conversa com %s fechada\n", appState.CurrentDM)
+		appState.CurrentDM = ""
+		return
+	}
+	if !strings.HasPrefix(args, "@") {
+		fmt.Println("Uso: /dm @usuario")
+		return
+	}
+
+	nickname := args[1:] // Remover @
+	if findPeerByName(appState, nickname) == "" && len(appState.Store.GetPrivateMessages(conversationKey(nickname))) == 0 {
+		fmt.Printf("Usuário %s não encontrado\n", nickname)
+		return
+	}
+	openConversation(appState, nickname)
+}
+
+// sendConversationMessage envia uma mensagem à conversa privada em foco
+func sendConversationMessage(appState *AppState, content string) {
+	nickname := appState.CurrentDM
+	peerID := findPeerByName(appState, nickname)
+	if peerID == "" {
+		fmt.Printf("%s não está online\n", nickname)
+		return
+	}
+
+	if _, err := sendPrivateMessage(appState, peerID, nickname, content, false); err != nil {
+		fmt.Println("Erro ao enviar mensagem privada:", err)
+		return
+	}
+
+	messages := appState.PrivateMessages[peerID]
+	printConversationMessage(appState, messages[len(messages)-1])
+}
+
+// updateDeliveryStatus atualiza o status de uma mensagem privada enviada e
+// mostra a mudança se a conversa estiver em foco. Retorna true se a mudança
+// foi exibida.
+func updateDeliveryStatus(appState *AppState, messageID string, status protocol.DeliveryStatus) bool {
+	for _, messages := range appState.PrivateMessages {
+		for _, message := range messages {
+			if message.ID != messageID {
+				continue
+			}
+			if message.DeliveryStatus == status {
+				return false
+			}
+			message.DeliveryStatus = status
+
+			if appState.CurrentDM != "" && message.RecipientNickname == appState.CurrentDM {
+				fmt.Printf("  %s %q\n", deliveryMarker(status), message.Content)
+				return true
+			}
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/store"
+)
+
+// exportDateLayout é o formato das datas aceitas por /export
+const exportDateLayout = "2006-01-02"
+
+// parseExportRange interpreta as datas opcionais de /export. A data final
+// é inclusiva.
+func parseExportRange(args []string) (store.ExportRange, error) {
+	var r store.ExportRange
+	if len(args) > 0 {
+		from, err := time.ParseInLocation(exportDateLayout, args[0], time.Local)
+		if err != nil {
+			return r, fmt.Errorf("data inicial inválida: %s", args[0])
+		}
+		r.From = from
+	}
+	if len(args) > 1 {
+		to, err := time.ParseInLocation(exportDateLayout, args[1], time.Local)
+		if err != nil {
+			return r, fmt.Errorf("data final inválida: %s", args[1])
+		}
+		r.To = to.AddDate(0, 0, 1)
+	}
+	return r, nil
+}
+
+// exportCommand processa /export #canal|@nome arquivo [desde [até]]
+func exportCommand(appState *AppState, args string) {
+	parts := strings.Fields(args)
+	if len(parts) < 2 || len(parts) > 4 || (!strings.HasPrefix(parts[0], "#") && !strings.HasPrefix(parts[0], "@")) {
+		fmt.Println("Uso: /export #canal|@usuario arquivo [desde [até]] (datas AAAA-MM-DD)")
+		return
+	}
+
+	r, err := parseExportRange(parts[2:])
+	if err != nil {
+		fmt.Println("Erro:", err)
+		return
+	}
+
+	path := parts[1]
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		fmt.Println("Erro ao criar arquivo:", err)
+		return
+	}
+	defer file.Close()
+
+	format := store.ExportFormatForPath(path)
+	var count int
+	if target := parts[0]; strings.HasPrefix(target, "#") {
+		count, err = appState.Store.ExportChannelMessages(target, file, format, r)
+	} else {
+		nickname := target[1:] // Remover @
+		count, err = appState.Store.ExportPrivateMessages(conversationKey(nickname), "Conversa com "+nickname, file, format, r)
+	}
+	if err != nil {
+		fmt.Println("Erro ao exportar:", err)
+		return
+	}
+	fmt.Printf("%d mensagens de %s exportadas para %s\n", count, parts[0], path)
+}
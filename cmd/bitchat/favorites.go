@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// saveFavorites guarda as identidades favoritas para a próxima execução
+func saveFavorites(appState *AppState) {
+	appState.Settings.Favorites = appState.MeshService.Favorites()
+	if err := appState.Settings.Save(); err != nil {
+		fmt.Println("Erro ao salvar preferências:", err)
+	}
+}
+
+// favoriteFingerprint retorna a identidade de um peer online ou, se ele não
+// estiver no alcance, a registrada para o apelido
+func favoriteFingerprint(appState *AppState, username string) string {
+	if peerID := findPeerByName(appState, username); peerID != "" {
+		if info, ok := appState.MeshService.GetPeerInfo(peerID); ok && info.Fingerprint != "" {
+			return info.Fingerprint
+		}
+	}
+	return appState.Settings.Contacts[username]
+}
+
+// favoriteCommand processa /favorite [@nome]; sem argumento lista os
+// favoritos
+func favoriteCommand(appState *AppState, args string) {
+	target := strings.TrimSpace(args)
+	if target == "" {
+		fmt.Println("Favoritos:")
+		favorites := appState.MeshService.Favorites()
+		if len(favorites) == 0 {
+			fmt.Println("  Nenhum favorito")
+		}
+		for _, fingerprint := range favorites {
+			if names := contactNames(appState, fingerprint); len(names) > 0 {
+				fmt.Printf("  ★ %s (%s)\n", strings.Join(names, ", "), fingerprint)
+			} else {
+				fmt.Printf("  ★ %s\n", fingerprint)
+			}
+		}
+		return
+	}
+	if !strings.HasPrefix(target, "@") {
+		fmt.Println("Uso: /favorite [@usuario]")
+		return
+	}
+
+	username := target[1:] // Remover @
+	fingerprint := favoriteFingerprint(appState, username)
+	if fingerprint == "" {
+		fmt.Printf("Identidade de %s ainda desconhecida\n", username)
+		return
+	}
+	if err := appState.MeshService.AddFavorite(fingerprint); err != nil {
+		fmt.Println("Erro ao marcar favorito:", err)
+		return
+	}
+	appState.Settings.Contacts[username] = fingerprint
+	saveFavorites(appState)
+	fmt.Printf("★ %s marcado como favorito; aviso quando estiver no alcance\n", username)
+}
+
+// unfavoriteCommand processa /unfavorite @nome
+func unfavoriteCommand(appState *AppState, args string) {
+	target := strings.TrimSpace(args)
+	if !strings.HasPrefix(target, "@") {
+		fmt.Println("Uso: /unfavorite @usuario")
+		return
+	}
+
+	username := target[1:] // Remover @
+	fingerprint := favoriteFingerprint(appState, username)
+	if fingerprint == "" {
+		fmt.Printf("Usuário %s não encontrado\n", username)
+		return
+	}
+	if err := appState.MeshService.RemoveFavorite(fingerprint); err != nil {
+		fmt.Println("Erro ao desmarcar favorito:", err)
+		return
+	}
+	saveFavorites(appState)
+	fmt.Printf("%s não é mais favorito\n", username)
+}
+
+// OnFavoriteInRange é chamado quando um peer favorito entra no alcance
+func (md *MeshDelegateImpl) OnFavoriteInRange(peerID string, name string) {
+	md.AppState.Events.Emit(Event{Event: "favorite_in_range", PeerID: jsonPeerID(peerID), Name: name})
+	fmt.Printf("★ %s está no alcance\n", name)
+	desktopNotify("Bitchat", fmt.Sprintf("%s está no alcance", name))
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/permissionlesstech/bitchat/internal/bluetooth"
+)
+
+// formatBytes formata um tamanho em bytes para exibição
+func formatBytes(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+// peerName retorna o apelido de um peer ativo ou, sem ele, o próprio ID
+func peerName(appState *AppState, peerID string) string {
+	if name, ok := appState.activePeerName(peerID); ok {
+		return name
+	}
+	return fmt.Sprintf("%x", peerID)
+}
+
+// sendFileCommand processa /send @nome caminho
+func sendFileCommand(appState *AppState, args string) {
+	parts := strings.SplitN(strings.TrimSpace(args), " ", 2)
+	if len(parts) < 2 || !strings.HasPrefix(parts[0], "@") {
+		fmt.Println("Uso: /send @usuario caminho")
+		return
+	}
+
+	username := parts[0][1:] // Remover @
+	peerID := findPeerByName(appState, username)
+	if peerID == "" {
+		fmt.Printf("Usuário %s não encontrado\n", username)
+		return
+	}
+
+	path := strings.TrimSpace(parts[1])
+	transferID, err := appState.MeshService.SendFile(peerID, path)
+	if err != nil {
+		fmt.Println("Erro ao enviar arquivo:", err)
+		return
+	}
+	fmt.Printf("Arquivo oferecido a %s (oferta %s); aguardando aceite\n", username, transferID)
+}
+
+// acceptFileCommand processa /accept [oferta]; sem oferta lista as pendentes
+func acceptFileCommand(appState *AppState, args string) {
+	transferID := strings.TrimSpace(args)
+	if transferID == "" {
+		listFileOffers(appState)
+		return
+	}
+
+	path, err := appState.MeshService.AcceptFile(transferID, appState.Config.DownloadDir)
+	if err != nil {
+		fmt.Println("Erro ao aceitar arquivo:", err)
+		return
+	}
+	fmt.Printf("Recebendo arquivo em %s\n", path)
+}
+
+// rejectFileCommand processa /reject oferta
+func rejectFileCommand(appState *AppState, args string) {
+	transferID := strings.TrimSpace(args)
+	if transferID == "" {
+		fmt.Println("Uso: /reject oferta")
+		return
+	}
+
+	if err := appState.MeshService.RejectFile(transferID); err != nil {
+		fmt.Println("Erro ao recusar arquivo:", err)
+		return
+	}
+	fmt.Printf("Oferta %s recusada\n", transferID)
+}
+
+// listFileOffers exibe as transferências em andamento
+func listFileOffers(appState *AppState) {
+	transfers := appState.MeshService.GetFileTransfers()
+	if len(transfers) == 0 {
+		fmt.Println("Nenhuma transferência em andamento")
+		return
+	}
+	sort.Slice(transfers, func(i, j int) bool { return transfers[i].ID < transfers[j].ID })
+
+	fmt.Println("Transferências:")
+	for _, transfer := range transfers {
+		direction := "para"
+		if transfer.Incoming {
+			direction = "de"
+		}
+		fmt.Printf("  %s %s %s %s: %s (%s de %s)\n", transfer.ID, transfer.Name, direction,
+			peerName(appState, transfer.PeerID), transferState(transfer),
+			formatBytes(transfer.Transferred), formatBytes(transfer.Size))
+	}
+}
+
+// transferState descreve a etapa de uma transferência
+func transferState(transfer bluetooth.FileTransfer) string {
+	switch {
+	case transfer.Incoming && transfer.Path == "":
+		return "aguardando /accept"
+	case transfer.Transferred == 0 && transfer.Size > 0:
+		return "aguardando"
+	}
+	return "em andamento"
+}
+
+// OnFileOffered é chamado quando um peer oferece um arquivo
+func (md *MeshDelegateImpl) OnFileOffered(transfer bluetooth.FileTransfer) {
+	name := peerName(md.AppState, transfer.PeerID)
+	md.AppState.Events.Emit(Event{Event: "file_offer", PeerID: jsonPeerID(transfer.PeerID), Name: name,
+		Ref: transfer.ID, Content: transfer.Name})
+
+	// Arquivos pequenos são aceitos automaticamente, se configurado
+	if limit := md.AppState.Config.AutoAcceptSize; limit > 0 && transfer.Size <= limit {
+		path, err := md.AppState.MeshService.AcceptFile(transfer.ID, md.AppState.Config.DownloadDir)
+		if err == nil {
+			fmt.Printf("Recebendo %s (%s) de %s em %s\n", transfer.Name, formatBytes(transfer.Size), name, path)
+			return
+		}
+		fmt.Println("Erro ao aceitar arquivo:", err)
+	}
+
+	fmt.Printf("%s oferece o arquivo %s (%s). Use /accept %s ou /reject %s\n",
+		name, transfer.Name, formatBytes(transfer.Size), transfer.ID, transfer.ID)
+}
+
+// OnFileProgress é chamado a cada pedaço enviado ou recebido; o progresso é
+// exibido a cada quarto do arquivo
+func (md *MeshDelegateImpl) OnFileProgress(transfer bluetooth.FileTransfer) {
+	if transfer.Size == 0 {
+		return
+	}
+	step := int(transfer.Transferred * 4 / transfer.Size)
+	if step <= md.AppState.FileProgress[transfer.ID] || step >= 4 {
+		return
+	}
+	md.AppState.FileProgress[transfer.ID] = step
+	fmt.Printf("%s: %d%% (%s de %s)\n", transfer.Name, step*25,
+		formatBytes(transfer.Transferred), formatBytes(transfer.Size))
+}
+
+// OnFileTransferDone é chamado quando uma transferência termina
+func (md *MeshDelegateImpl) OnFileTransferDone(transfer bluetooth.FileTransfer, err error) {
+	delete(md.AppState.FileProgress, transfer.ID)
+
+	event := Event{Event: "file_done", PeerID: jsonPeerID(transfer.PeerID), Ref: transfer.ID, Content: transfer.Name}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	md.AppState.Events.Emit(event)
+
+	name := peerName(md.AppState, transfer.PeerID)
+	switch {
+	case err != nil:
+		fmt.Printf("Transferência de %s com %s falhou: %v\n", transfer.Name, name, err)
+	case transfer.Incoming:
+		fmt.Printf("Arquivo %s recebido de %s: %s\n", transfer.Name, name, transfer.Path)
+	default:
+		fmt.Printf("Arquivo %s enviado a %s\n", transfer.Name, name)
+	}
+}
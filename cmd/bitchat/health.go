@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/permissionlesstech/bitchat/internal/bluetooth"
+)
+
+// HealthComponentStore é o nome do componente do armazenamento de mensagens
+const HealthComponentStore = "armazenamento"
+
+// checkHealth verifica os componentes do serviço mesh e o armazenamento
+func checkHealth(appState *AppState) bluetooth.HealthReport {
+	report := appState.MeshService.CheckHealth()
+	report.Add(storeHealth(filepath.Join(appState.Config.DataDir, "messages")))
+	return report
+}
+
+// storeHealth verifica se o diretório do armazenamento aceita gravações
+func storeHealth(dir string) bluetooth.ComponentHealth {
+	component := bluetooth.ComponentHealth{Name: HealthComponentStore, Status: bluetooth.HealthOK}
+
+	file, err := os.CreateTemp(dir, ".health-")
+	if err != nil {
+		component.Status, component.Detail = bluetooth.HealthFailed, fmt.Sprintf("sem permissão de gravação: %v", err)
+		return component
+	}
+	file.Close()
+	os.Remove(file.Name())
+	return component
+}
+
+// writeHealth escreve a situação de cada componente, uma por linha
+func writeHealth(w io.Writer, report bluetooth.HealthReport) {
+	fmt.Fprintf(w, "Situação: %s\n", report.Status())
+	for _, component := range report.Components {
+		line := fmt.Sprintf("  %s: %s", component.Name, component.Status)
+		if component.Detail != "" {
+			line += " (" + component.Detail + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// healthCommand processa /health
+func healthCommand(appState *AppState) {
+	writeHealth(os.Stdout, checkHealth(appState))
+}
+
+// serveHealthz responde ao /healthz do servidor de métricas: 200 enquanto
+// nenhum componente falhou, 503 caso contrário
+func serveHealthz(appState *AppState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := checkHealth(appState)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if report.Status() == bluetooth.HealthFailed {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		writeHealth(w, report)
+	}
+}
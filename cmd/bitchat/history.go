@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// DefaultHistoryPage é o número de mensagens exibidas por /history sem N
+const DefaultHistoryPage = 20
+
+// HistoryCursor guarda a posição de /history para que chamadas seguintes
+// exibam as mensagens mais antigas
+type HistoryCursor struct {
+	Target string // Canal ou @apelido da conversa
+	Skip   int    // Mensagens recentes já exibidas
+	Size   int    // Tamanho da página
+}
+
+// historyCommand processa /history [N]. Com N, exibe as últimas N mensagens
+// da conversa em foco guardadas no armazenamento; sem N, continua com as
+// mensagens anteriores às já exibidas.
+func historyCommand(appState *AppState, args string) {
+	target := appState.CurrentChannel
+	if appState.CurrentDM != "" {
+		target = "@" + appState.CurrentDM
+	}
+	if target == "" {
+		fmt.Println("Entre em um canal ou abra uma conversa para ver o histórico")
+		return
+	}
+
+	cursor := appState.History
+	if args = strings.TrimSpace(args); args != "" {
+		size, err := strconv.Atoi(args)
+		if err != nil || size <= 0 {
+			fmt.Println("Uso: /history [N]")
+			return
+		}
+		cursor = HistoryCursor{Target: target, Size: size}
+	} else if cursor.Target != target {
+		cursor = HistoryCursor{Target: target, Size: DefaultHistoryPage}
+	}
+
+	var messages []*protocol.BitchatMessage
+	var more bool
+	if appState.CurrentDM != "" {
+		messages, more = appState.Store.GetPrivateHistory(conversationKey(appState.CurrentDM), cursor.Skip, cursor.Size)
+	} else {
+		messages, more = appState.Store.GetChannelHistory(target, cursor.Skip, cursor.Size)
+	}
+	if len(messages) == 0 {
+		fmt.Printf("Nenhuma mensagem anterior em %s\n", target)
+		appState.History = cursor
+		return
+	}
+
+	var page Page
+	page.Printf("--- Histórico de %s ---\n", target)
+	for _, msg := range messages {
+		page.Printf("%s %s: %s\n",
+			appState.Theme.Time("["+time.UnixMilli(int64(msg.Timestamp)).Format("2006-01-02 15:04:05")+"]"),
+			appState.Theme.Sender(msg.Sender, msg.Sender == appState.Config.DeviceName),
+			appState.Theme.Content(msg.Content, appState.Config.DeviceName))
+	}
+	if more {
+		page.Println("--- /history para mensagens anteriores ---")
+	} else {
+		page.Println("--- Início do histórico ---")
+	}
+	appState.Pager.Show(&page)
+
+	cursor.Skip += len(messages)
+	appState.History = cursor
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/bluetooth"
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// HookTimeout limita a execução de um script de evento
+const HookTimeout = 30 * time.Second
+
+// Eventos que podem disparar scripts, configurados na seção [hooks]:
+//
+//	[hooks]
+//	message = /usr/local/bin/on-message
+//	peer_discovered = ~/bin/novo-peer.sh
+//	delivery_failed = /usr/local/bin/alerta
+const (
+	HookMessage        = "message"
+	HookPeerDiscovered = "peer_discovered"
+	HookDeliveryFailed = "delivery_failed"
+)
+
+// validHook informa se um evento aceita scripts
+func validHook(name string) bool {
+	switch name {
+	case HookMessage, HookPeerDiscovered, HookDeliveryFailed:
+		return true
+	}
+	return false
+}
+
+// hookEnv traduz um evento para variáveis de ambiente BITCHAT_*
+func hookEnv(name string, event Event) []string {
+	env := []string{"BITCHAT_EVENT=" + name, "BITCHAT_TIME=" + strconv.FormatInt(event.Time, 10)}
+	for key, value := range map[string]string{
+		"BITCHAT_PEER_ID":    event.PeerID,
+		"BITCHAT_NAME":       event.Name,
+		"BITCHAT_MESSAGE_ID": event.MessageID,
+		"BITCHAT_SENDER":     event.Sender,
+		"BITCHAT_CHANNEL":    event.Channel,
+		"BITCHAT_CONTENT":    event.Content,
+		"BITCHAT_STATUS":     event.Status,
+		"BITCHAT_RECIPIENT":  event.Recipient,
+		"BITCHAT_ERROR":      event.Error,
+	} {
+		if value != "" {
+			env = append(env, key+"="+value)
+		}
+	}
+	if event.Private {
+		env = append(env, "BITCHAT_PRIVATE=1")
+	}
+	return env
+}
+
+// runHook executa em segundo plano o script configurado para um evento. Os
+// dados do evento chegam ao script pelas variáveis BITCHAT_* e, em JSON, pela
+// entrada padrão; a saída do script aparece junto das mensagens.
+func runHook(appState *AppState, name string, event Event) {
+	command := strings.Fields(appState.Hooks[name])
+	if len(command) == 0 {
+		return
+	}
+	if event.Time == 0 {
+		event.Time = time.Now().UnixMilli()
+	}
+	input, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), HookTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Env = append(os.Environ(), hookEnv(name, event)...)
+		cmd.Stdin = bytes.NewReader(append(input, '\n'))
+		cmd.Stdout = stdoutWriter{}
+		cmd.Stderr = stdoutWriter{}
+		if err := cmd.Run(); err != nil {
+			fmt.Printf("Erro no script do evento %s: %v\n", name, err)
+		}
+	}()
+}
+
+// subscribeHooks inscreve os scripts de evento no barramento do serviço
+// mesh, independentemente do delegate
+func subscribeHooks(appState *AppState) {
+	events := appState.MeshService.Events()
+	events.SubscribePeers(func(event bluetooth.PeerEvent) {
+		if event.Type == bluetooth.PeerDiscovered {
+			runHook(appState, HookPeerDiscovered, peerDiscoveredEvent(event.PeerID, event.Name))
+		}
+	})
+	events.SubscribeMessages(func(event bluetooth.MessageEvent) {
+		if !appState.BlockedPeers[event.Message.SenderPeerID] {
+			runHook(appState, HookMessage, messageEvent(event.Message))
+		}
+	})
+	events.SubscribeDeliveries(func(event bluetooth.DeliveryEvent) {
+		if event.Status == protocol.DeliveryStatusFailed {
+			runHook(appState, HookDeliveryFailed, deliveryEvent(event.MessageID, event.Status, event.Info))
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+)
+
+// Event é um evento emitido no modo --json, um objeto JSON por linha
+type Event struct {
+	Event     string `json:"event"`
+	Time      int64  `json:"time"` // Milissegundos desde epoch
+	PeerID    string `json:"peer_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	OldName   string `json:"old_name,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
+	Sender    string `json:"sender,omitempty"`
+	Channel   string `json:"channel,omitempty"`
+	Private   bool   `json:"private,omitempty"`
+	Content   string `json:"content,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Recipient string `json:"recipient,omitempty"`
+	Hops      int    `json:"hops,omitempty"`
+	Reached   int    `json:"reached,omitempty"` // Membros do canal que confirmaram a entrega
+	Total     int    `json:"total,omitempty"`   // Membros do canal conhecidos no envio
+	Level     int    `json:"level,omitempty"`   // Nível de bateria (%)
+	Ref       string `json:"ref,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// JSONCommand é um comando recebido pela entrada padrão no modo --json.
+// Type "message" envia Content ao canal Channel ou, com To, ao peer com esse
+// apelido; type "peers" lista os peers ativos em eventos "peer" seguidos de
+// "done"; type "command" executa Command com Args como na linha de comando.
+// Ref é devolvido nos eventos de resposta para correlacionar o pedido.
+type JSONCommand struct {
+	Type    string `json:"type"`
+	Ref     string `json:"ref,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	To      string `json:"to,omitempty"`
+	Content string `json:"content,omitempty"`
+	Urgent  bool   `json:"urgent,omitempty"`
+	Command string `json:"command,omitempty"`
+	Args    string `json:"args,omitempty"`
+}
+
+// EventWriter serializa eventos na saída, um por linha. É seguro para uso
+// concorrente pelos callbacks do serviço mesh. Um EventWriter nil ignora os
+// eventos, de modo que o modo interativo não precisa testar o modo --json.
+type EventWriter struct {
+	encoder *json.Encoder
+	mutex   sync.Mutex
+}
+
+// NewEventWriter cria um EventWriter que escreve em w
+func NewEventWriter(w io.Writer) *EventWriter {
+	return &EventWriter{encoder: json.NewEncoder(w)}
+}
+
+// Emit escreve um evento, preenchendo o horário se necessário
+func (ew *EventWriter) Emit(event Event) {
+	if ew == nil {
+		return
+	}
+	if event.Time == 0 {
+		event.Time = time.Now().UnixMilli()
+	}
+
+	ew.mutex.Lock()
+	defer ew.mutex.Unlock()
+	ew.encoder.Encode(event)
+}
+
+// enableJSONMode reserva a saída padrão para os eventos; o texto destinado
+// ao usuário passa a ir para a saída de erro
+func enableJSONMode(appState *AppState) {
+	appState.Events = NewEventWriter(os.Stdout)
+	os.Stdout = os.Stderr
+}
+
+// jsonPeerID codifica um ID de peer para os eventos
+func jsonPeerID(peerID string) string {
+	return hex.EncodeToString([]byte(peerID))
+}
+
+// deliveryStatusName retorna o nome estável de um status de entrega
+func deliveryStatusName(status protocol.DeliveryStatus) string {
+	switch status {
+	case protocol.DeliveryStatusSending:
+		return "sending"
+	case protocol.DeliveryStatusSent:
+		return "sent"
+	case protocol.DeliveryStatusDelivered:
+		return "delivered"
+	case protocol.DeliveryStatusRead:
+		return "read"
+	case protocol.DeliveryStatusFailed:
+		return "failed"
+	case protocol.DeliveryStatusPartiallyDelivered:
+		return "partially_delivered"
+	}
+	return "unknown"
+}
+
+// presenceStateName retorna o nome estável de um estado de presença
+func presenceStateName(state mesh.PresenceState) string {
+	switch state {
+	case mesh.PresenceOnline:
+		return "online"
+	case mesh.PresenceRecent:
+		return "recent"
+	}
+	return "offline"
+}
+
+// jsonInputLoop lê comandos JSON da entrada padrão, um por linha
+func jsonInputLoop(appState *AppState) {
+	scanner := bufio.NewScanner(os.Stdin)
+
+	for appState.Running && scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var command JSONCommand
+		if err := json.Unmarshal([]byte(line), &command); err != nil {
+			appState.Events.Emit(Event{Event: "error", Error: fmt.Sprintf("comando inválido: %v", err)})
+			continue
+		}
+		processJSONCommand(&command, appState)
+	}
+}
+
+// processJSONCommand executa um comando recebido no modo --json
+func processJSONCommand(command *JSONCommand, appState *AppState) {
+	switch command.Type {
+	case "message":
+		if command.Content == "" {
+			appState.Events.Emit(Event{Event: "error", Ref: command.Ref, Error: "mensagem sem conteúdo"})
+			return
+		}
+
+		var messageID string
+		var err error
+		if command.To != "" {
+			recipient := strings.TrimPrefix(command.To, "@")
+			peerID := findPeerByName(appState, recipient)
+			if peerID == "" {
+				appState.Events.Emit(Event{Event: "error", Ref: command.Ref, Error: fmt.Sprintf("usuário %s não encontrado", recipient)})
+				return
+			}
+			messageID, err = sendPrivateMessage(appState, peerID, recipient, command.Content, command.Urgent)
+		} else {
+			channel := command.Channel
+			if channel == "" {
+				channel = appState.CurrentChannel
+			}
+			if !strings.HasPrefix(channel, "#") {
+				appState.Events.Emit(Event{Event: "error", Ref: command.Ref, Error: "canal não informado"})
+				return
+			}
+			messageID, err = sendChannelMessage(appState, channel, command.Content)
+		}
+
+		if err != nil {
+			appState.Events.Emit(Event{Event: "error", Ref: command.Ref, Error: err.Error()})
+			return
+		}
+		appState.Events.Emit(Event{Event: "sent", Ref: command.Ref, MessageID: messageID})
+
+	case "peers":
+		for peerID, name := range appState.activePeers() {
+			appState.Events.Emit(Event{Event: "peer", Ref: command.Ref, PeerID: jsonPeerID(peerID), Name: name})
+		}
+		appState.Events.Emit(Event{Event: "done", Ref: command.Ref})
+
+	case "command":
+		if !strings.HasPrefix(command.Command, "/") {
+			appState.Events.Emit(Event{Event: "error", Ref: command.Ref, Error: fmt.Sprintf("comando desconhecido: %s", command.Command)})
+			return
+		}
+		processCommand(command.Command, command.Args, appState)
+		appState.Events.Emit(Event{Event: "done", Ref: command.Ref})
+
+	default:
+		appState.Events.Emit(Event{Event: "error", Ref: command.Ref, Error: fmt.Sprintf("tipo de comando desconhecido: %s", command.Type)})
+	}
+}
+
+// peerDiscoveredEvent descreve a descoberta de um peer
+func peerDiscoveredEvent(peerID string, name string) Event {
+	return Event{Event: "peer_discovered", PeerID: jsonPeerID(peerID), Name: name}
+}
+
+// messageEvent descreve uma mensagem recebida
+func messageEvent(message *protocol.BitchatMessage) Event {
+	return Event{
+		Event:     "message",
+		Time:      int64(message.Timestamp),
+		PeerID:    jsonPeerID(message.SenderPeerID),
+		MessageID: message.ID,
+		Sender:    message.Sender,
+		Channel:   message.Channel,
+		Private:   message.IsPrivate,
+		Content:   message.Content,
+		Hops:      message.HopCount,
+	}
+}
+
+// deliveryEvent descreve uma mudança no status de entrega
+func deliveryEvent(messageID string, status protocol.DeliveryStatus, info *protocol.DeliveryInfo) Event {
+	event := Event{Event: "delivery", MessageID: messageID, Status: deliveryStatusName(status)}
+	if info != nil {
+		event.Recipient = info.Recipient
+		event.Hops = info.HopCount
+		event.Error = info.FailReason
+		event.Reached = info.ReachedPeers
+		event.Total = info.TotalPeers
+	}
+	return event
+}
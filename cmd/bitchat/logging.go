@@ -0,0 +1,36 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/permissionlesstech/bitchat/internal/logging"
+)
+
+// stdoutWriter escreve na saída padrão vigente no momento da escrita, que
+// muda no modo --json e quando o terminal interativo assume a saída
+type stdoutWriter struct{}
+
+func (stdoutWriter) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
+// setupLogging configura o logger dos pacotes do Bitchat conforme as flags.
+// Sem --log-file, os registros aparecem na saída junto das mensagens.
+func setupLogging(config *Config) (io.Closer, error) {
+	level, err := logging.ParseLevel(config.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+	if config.Debug {
+		level = slog.LevelDebug
+	}
+
+	return logging.Setup(logging.Options{
+		Level:   level,
+		File:    config.LogFile,
+		MaxSize: config.LogMaxSize << 20,
+		Output:  stdoutWriter{},
+	})
+}
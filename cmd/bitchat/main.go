@@ -4,21 +4,29 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/permissionlesstech/bitchat/internal/bluetooth"
 	"github.com/permissionlesstech/bitchat/internal/crypto"
 	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/internal/service"
+	"github.com/permissionlesstech/bitchat/internal/store"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
 	"github.com/permissionlesstech/bitchat/pkg/utils"
 )
 
 const (
 	AppVersion = "0.1.0"
+	
+	// BenchDiscoveryTimeout é a espera pela descoberta do peer no subcomando bench
+	BenchDiscoveryTimeout = 30 * time.Second
 )
 
 // Opções de configuração
@@ -28,19 +36,96 @@ type Config struct {
 	BatteryMode      int
 	CoverTraffic     bool
 	Debug            bool
+	MetricsAddr      string
+	Adapter          string
+	ScanAdapter      string
+	MaxCentrals      int
+	ConnIntervalMin  time.Duration
+	ConnIntervalMax  time.Duration
+	JSON             bool
+	NoColor          bool
+	DownloadDir      string
+	AutoAcceptSize   int64
+	ConfigPath       string
+	LogLevel         string
+	LogFile          string
+	LogMaxSize       int64 // Em MB
+	Pipe             string // Canal do modo --pipe
+	BatteryAuto      bool   // Escolher o modo de bateria pelo nível
+	BatteryLow       int    // Nível para o modo low no modo automático
+	BatteryUltraLow  int    // Nível para o modo ultralow no modo automático
+	RateLimit        mesh.RateLimitConfig // Limites de envio de mensagens
+	SyncBudgets      bluetooth.ReconnectSyncBudgets // Bytes pedidos ao sincronizar no retorno de um peer
+	QueueCapacity    int    // Capacidade das filas de entrada e saída
+	QueuePolicy      string // Política de descarte das filas cheias
 }
 
 // Estado global do aplicativo
 type AppState struct {
 	Config           *Config
+	Settings         *Settings
 	EncryptionService *crypto.EncryptionService
 	MeshService      *bluetooth.BluetoothMeshService
 	CurrentChannel   string
+	CurrentDM        string // Apelido da conversa privada em foco
+	JoinedChannels   map[string]bool
+	Unread           map[string]int // canal -> mensagens não lidas fora de foco
+	LeftChannels     map[string]bool // Canais deixados com /leave, cujas mensagens são ignoradas
+	ChannelMembers   map[string]map[string]bool // canal -> peers vistos no canal
+	Store            *store.MessageStore
 	ActivePeers      map[string]string // peerID -> nickname
 	BlockedPeers     map[string]bool
 	MessageHistory   map[string][]*protocol.BitchatMessage // canal -> mensagens
 	PrivateMessages  map[string][]*protocol.BitchatMessage // peerID -> mensagens
+	LineReader       *LineReader // Entrada com edição de linha (nil fora de um terminal)
+	Pager            *Pager      // Paginação da saída (nil fora de um terminal)
+	Signals          chan os.Signal // Pedidos de encerramento (SIGINT, /quit)
+	Events           *EventWriter // Eventos do modo --json (nil no modo interativo)
+	Theme            *Theme       // Cores da saída (nil sem cores)
+	FileProgress     map[string]int // Transferência -> último quarto exibido
+	History          HistoryCursor  // Posição de /history na conversa em foco
+	Aliases          map[string]string // Alias -> comandos separados por ;
+	Deliveries       chan deliveryUpdate // Status de entrega aguardados pelo subcomando send
+	Pipe             *PipeWriter         // Saída do modo --pipe (nil fora dele)
+	Hooks            map[string]string   // Evento -> script executado
+	Scheduler        *service.Scheduler  // Mensagens agendadas com /schedule
+	Charging         string            // Último estado de carga exibido (vazio antes da primeira leitura)
+	ExpandingAlias   bool              // Um alias está sendo executado
 	Running          bool
+
+	// Protege ActivePeers, JoinedChannels, Unread, ChannelMembers e
+	// MessageHistory, escritos também pelas goroutines do serviço mesh e lidos
+	// pela completação com Tab. Não deve ser mantido ao chamar o serviço.
+	mutex sync.RWMutex
+}
+
+// activePeerName retorna o apelido de um peer ativo
+func (as *AppState) activePeerName(peerID string) (string, bool) {
+	as.mutex.RLock()
+	defer as.mutex.RUnlock()
+
+	name, ok := as.ActivePeers[peerID]
+	return name, ok
+}
+
+// activePeers retorna uma cópia dos peers ativos (ID -> apelido)
+func (as *AppState) activePeers() map[string]string {
+	as.mutex.RLock()
+	defer as.mutex.RUnlock()
+
+	peers := make(map[string]string, len(as.ActivePeers))
+	for id, name := range as.ActivePeers {
+		peers[id] = name
+	}
+	return peers
+}
+
+// isJoined informa se o usuário está em um canal
+func (as *AppState) isJoined(channel string) bool {
+	as.mutex.RLock()
+	defer as.mutex.RUnlock()
+
+	return as.JoinedChannels[channel]
 }
 
 // Implementação de MeshDelegate
@@ -50,15 +135,60 @@ type MeshDelegateImpl struct {
 
 // OnPeerDiscovered é chamado quando um novo peer é descoberto
 func (md *MeshDelegateImpl) OnPeerDiscovered(peerID string, name string) {
+	md.AppState.mutex.Lock()
 	md.AppState.ActivePeers[peerID] = name
+	md.AppState.mutex.Unlock()
+	md.AppState.Events.Emit(peerDiscoveredEvent(peerID, name))
 	fmt.Printf("Peer descoberto: %s (%s)\n", name, peerID)
 }
 
+// OnPeerNicknameChanged é chamado quando um peer conhecido troca de apelido
+func (md *MeshDelegateImpl) OnPeerNicknameChanged(peerID string, oldName string, newName string) {
+	md.AppState.mutex.Lock()
+	md.AppState.ActivePeers[peerID] = newName
+	md.AppState.mutex.Unlock()
+	md.AppState.Events.Emit(Event{Event: "peer_renamed", PeerID: jsonPeerID(peerID), Name: newName, OldName: oldName})
+	fmt.Printf("%s agora é conhecido como %s\n", oldName, newName)
+}
+
+// OnPeerLeftChannel é chamado quando um peer avisa que saiu de um canal
+func (md *MeshDelegateImpl) OnPeerLeftChannel(peerID string, channel string) {
+	md.AppState.mutex.Lock()
+	if members, ok := md.AppState.ChannelMembers[channel]; ok {
+		delete(members, peerID)
+	}
+	joined := md.AppState.JoinedChannels[channel]
+	md.AppState.mutex.Unlock()
+	md.AppState.Events.Emit(Event{Event: "peer_left_channel", PeerID: jsonPeerID(peerID), Channel: channel})
+	
+	if joined {
+		name := peerID
+		if n, ok := md.AppState.activePeerName(peerID); ok {
+			name = n
+		}
+		fmt.Printf("%s saiu do canal %s\n", name, channel)
+	}
+}
+
+// OnChannelTopicChanged é chamado quando o dono de um canal muda o tópico
+func (md *MeshDelegateImpl) OnChannelTopicChanged(channel string, topic string, ownerID string) {
+	md.AppState.Events.Emit(Event{Event: "channel_topic", Channel: channel, Content: topic})
+	
+	if md.AppState.isJoined(channel) {
+		fmt.Printf("Tópico de %s: %s\n", channel, topic)
+	}
+}
+
 // OnPeerLost é chamado quando um peer não é mais visível
 func (md *MeshDelegateImpl) OnPeerLost(peerID string) {
-	if name, ok := md.AppState.ActivePeers[peerID]; ok {
+	md.AppState.mutex.Lock()
+	name, ok := md.AppState.ActivePeers[peerID]
+	delete(md.AppState.ActivePeers, peerID)
+	md.AppState.mutex.Unlock()
+	
+	if ok {
+		md.AppState.Events.Emit(Event{Event: "peer_lost", PeerID: jsonPeerID(peerID), Name: name})
 		fmt.Printf("Peer perdido: %s (%s)\n", name, peerID)
-		delete(md.AppState.ActivePeers, peerID)
 	}
 }
 
@@ -68,6 +198,15 @@ func (md *MeshDelegateImpl) OnMessageReceived(message *protocol.BitchatMessage)
 	if md.AppState.BlockedPeers[message.SenderPeerID] {
 		return
 	}
+	
+	md.AppState.Events.Emit(messageEvent(message))
+	
+	// Mensagens silenciadas são guardadas, mas não exibidas nem alertadas
+	muted := isMuted(md.AppState.Settings, message)
+	if !muted {
+		alertMessage(md.AppState.Settings, message, md.AppState.Config.DeviceName)
+	}
+	md.AppState.Pipe.WriteMessage(message)
 
 	// Processar a mensagem
 	if message.IsPrivate {
@@ -77,27 +216,70 @@ func (md *MeshDelegateImpl) OnMessageReceived(message *protocol.BitchatMessage)
 		}
 		md.AppState.PrivateMessages[message.SenderPeerID] = append(
 			md.AppState.PrivateMessages[message.SenderPeerID], message)
+		md.AppState.Store.AddPrivateMessage(conversationKey(message.Sender), message)
 		
-		fmt.Printf("[Privado de %s]: %s\n", message.Sender, message.Content)
+		// Mensagens privadas aparecem na conversa em foco; fora dela, apenas o aviso
+		if muted {
+			return
+		}
+		if md.AppState.CurrentDM == message.Sender {
+			printConversationMessage(md.AppState, message)
+			markConversationRead(md.AppState, message.Sender)
+		} else {
+			fmt.Printf("[Nova mensagem privada de %s] /dm @%s para abrir a conversa\n", message.Sender, message.Sender)
+		}
 	} else if message.Channel != "" {
-		// Mensagem de canal
-		if message.Channel == md.AppState.CurrentChannel {
-			fmt.Printf("[%s] %s: %s\n", message.Channel, message.Sender, message.Content)
+		if md.AppState.LeftChannels[message.Channel] {
+			return
 		}
+		recordChannelMember(md.AppState, message.Channel, message.SenderPeerID)
 		
-		if _, ok := md.AppState.MessageHistory[message.Channel]; !ok {
-			md.AppState.MessageHistory[message.Channel] = make([]*protocol.BitchatMessage, 0)
+		// Mensagem de canal: exibida no canal em foco, contada nos demais
+		switch {
+		case muted:
+		case message.Channel == md.AppState.CurrentChannel && md.AppState.CurrentDM == "":
+			theme := md.AppState.Theme
+			fmt.Printf("[%s] %s: %s\n", theme.ChannelName(message.Channel), theme.Sender(message.Sender, false),
+				theme.Content(message.Content, md.AppState.Config.DeviceName))
+		default:
+			md.AppState.mutex.Lock()
+			if md.AppState.JoinedChannels[message.Channel] {
+				md.AppState.Unread[message.Channel]++
+			}
+			md.AppState.mutex.Unlock()
 		}
+		
+		md.AppState.mutex.Lock()
 		md.AppState.MessageHistory[message.Channel] = append(
 			md.AppState.MessageHistory[message.Channel], message)
-	} else {
+		md.AppState.mutex.Unlock()
+		md.AppState.Store.AddChannelMessage(message.Channel, message)
+	} else if !muted {
 		// Mensagem broadcast
-		fmt.Printf("[Broadcast] %s: %s\n", message.Sender, message.Content)
+		theme := md.AppState.Theme
+		fmt.Printf("[Broadcast] %s: %s\n", theme.Sender(message.Sender, false),
+			theme.Content(message.Content, md.AppState.Config.DeviceName))
 	}
 }
 
 // OnMessageDeliveryChanged é chamado quando o status de entrega de uma mensagem muda
 func (md *MeshDelegateImpl) OnMessageDeliveryChanged(messageID string, status protocol.DeliveryStatus, info *protocol.DeliveryInfo) {
+	md.AppState.Events.Emit(deliveryEvent(messageID, status, info))
+	
+	if md.AppState.Deliveries != nil {
+		select {
+		case md.AppState.Deliveries <- deliveryUpdate{MessageID: messageID, Status: status}:
+		default:
+		}
+	}
+	
+	if updateDeliveryStatus(md.AppState, messageID, status) {
+		return
+	}
+	if updateChannelDelivery(md.AppState, messageID, status, info) {
+		return
+	}
+	
 	// Implementação básica - apenas log
 	statusText := "desconhecido"
 	switch status {
@@ -115,11 +297,118 @@ func (md *MeshDelegateImpl) OnMessageDeliveryChanged(messageID string, status pr
 		statusText = "parcialmente entregue"
 	}
 	
+	// Mostrar ao usuário quão longe a mensagem viajou
+	if status == protocol.DeliveryStatusDelivered && info != nil && info.HopCount > 0 {
+		fmt.Printf("✓ Mensagem entregue a %s via %s\n", info.Recipient, formatHops(info.HopCount))
+		return
+	}
+	
 	if md.AppState.Config.Debug {
 		fmt.Printf("Status da mensagem %s: %s\n", messageID, statusText)
 	}
 }
 
+// formatHops formata um número de saltos para exibição
+func formatHops(hops int) string {
+	if hops == 1 {
+		return "conexão direta"
+	}
+	return fmt.Sprintf("%d saltos", hops)
+}
+
+// OnPacketDropped é chamado quando um pacote é descartado por excesso de carga
+func (md *MeshDelegateImpl) OnPacketDropped(queue string, packet *protocol.BitchatPacket, priority mesh.Priority) {
+	if md.AppState.Config.Debug {
+		fmt.Printf("Pacote descartado na fila %s (prioridade %s)\n", queue, priority)
+	}
+}
+
+// OnRadioStateChanged é chamado quando o rádio Bluetooth muda de estado
+func (md *MeshDelegateImpl) OnRadioStateChanged(state bluetooth.RadioState) {
+	switch state {
+	case bluetooth.RadioStateOn:
+		fmt.Println("Rádio Bluetooth disponível; mesh retomada")
+	case bluetooth.RadioStateBlocked:
+		fmt.Println("Rádio Bluetooth bloqueado (rfkill); a mesh será retomada quando for liberado")
+	default:
+		fmt.Printf("Rádio Bluetooth %s; a mesh será retomada quando ele voltar\n", state)
+	}
+}
+
+// OnPeerProximityChanged é chamado quando um vizinho começa a se aproximar
+// ou a se afastar
+func (md *MeshDelegateImpl) OnPeerProximityChanged(event mesh.ProximityEvent) {
+	name, ok := md.AppState.activePeerName(event.PeerID)
+	if !ok {
+		return
+	}
+	
+	switch event.Trend {
+	case mesh.ProximityApproaching:
+		fmt.Printf("%s está se aproximando (%d dBm)\n", name, event.RSSI)
+	case mesh.ProximityLeaving:
+		fmt.Printf("%s está se afastando (%d dBm); o enlace pode cair\n", name, event.RSSI)
+	}
+}
+
+// OnPeerPresenceChanged é chamado quando um peer fica online, passa a visto
+// recentemente ou fica offline
+func (md *MeshDelegateImpl) OnPeerPresenceChanged(event mesh.PresenceEvent) {
+	name, ok := md.AppState.activePeerName(event.PeerID)
+	if !ok {
+		return
+	}
+	md.AppState.Events.Emit(Event{Event: "presence", PeerID: jsonPeerID(event.PeerID), Name: name, Status: presenceStateName(event.State)})
+	
+	if event.State == mesh.PresenceOffline {
+		fmt.Printf("%s está offline; mensagens privadas serão entregues quando voltar\n", name)
+	}
+}
+
+// OnHealthEvent é chamado a cada etapa da recuperação automática do Bluetooth
+func (md *MeshDelegateImpl) OnHealthEvent(event bluetooth.HealthEvent) {
+	switch event.Status {
+	case bluetooth.RecoveryStarted:
+		fmt.Printf("Falha no Bluetooth (%s); tentando recuperar\n", event.Reason)
+	case bluetooth.RecoveryRetrying:
+		fmt.Printf("Tentativa %d de recuperação do Bluetooth falhou: %v\n", event.Attempt, event.Err)
+	case bluetooth.RecoverySucceeded:
+		fmt.Println("Bluetooth recuperado")
+	case bluetooth.RecoveryFailed:
+		fmt.Printf("Não foi possível recuperar o Bluetooth após %d tentativas: %v\n", event.Attempt, event.Err)
+	}
+}
+
+// OnTraceResult é chamado quando um rastreamento de rota termina
+func (md *MeshDelegateImpl) OnTraceResult(result *mesh.TraceResult) {
+	target := result.TargetID
+	if name, ok := md.AppState.activePeerName(target); ok {
+		target = name
+	}
+	
+	if result.TimedOut && len(result.Hops) == 0 {
+		fmt.Printf("Rastreamento até %s expirou sem resposta\n", target)
+		return
+	}
+	
+	if result.TimedOut {
+		fmt.Printf("Rastreamento até %s expirou; caminho parcial:\n", target)
+	} else {
+		fmt.Printf("Rota até %s (%d saltos, RTT %v):\n", target, len(result.Hops), result.RTT.Round(time.Millisecond))
+	}
+	for i, hop := range result.Hops {
+		name := hop.PeerID
+		if n, ok := md.AppState.activePeerName(hop.PeerID); ok {
+			name = n
+		}
+		verified := "?"
+		if hop.Verified {
+			verified = "✓"
+		}
+		fmt.Printf("  %d. %s %s RSSI %d dBm\n", i+1, verified, name, hop.RSSI)
+	}
+}
+
 func main() {
 	// Configuração via flags
 	config := &Config{}
@@ -127,9 +416,46 @@ func main() {
 	flag.StringVar(&config.DeviceName, "name", "", "Nome do dispositivo (se não definido, será gerado)")
 	flag.StringVar(&config.DataDir, "data", "", "Diretório para dados persistentes (padrão: ~/.bitchat)")
 	flag.BoolVar(&config.CoverTraffic, "cover", true, "Ativar tráfego de cobertura para privacidade")
+	flag.BoolVar(&config.BatteryAuto, "battery-auto", false, "Escolher o modo de bateria pelo nível da bateria (como /battery auto)")
+	flag.IntVar(&config.BatteryLow, "battery-low", bluetooth.DefaultLowBatteryThreshold, "Nível de bateria (%) para o modo low no modo automático")
+	flag.IntVar(&config.BatteryUltraLow, "battery-ultralow", bluetooth.DefaultUltraLowBatteryThreshold, "Nível de bateria (%) para o modo ultralow no modo automático")
+	defaults := mesh.DefaultRateLimitConfig()
+	flag.Float64Var(&config.RateLimit.Global.MessagesPerSecond, "rate-msgs", defaults.Global.MessagesPerSecond, "Máximo de mensagens enviadas por segundo (0: sem limite)")
+	flag.Float64Var(&config.RateLimit.Global.BytesPerSecond, "rate-bytes", defaults.Global.BytesPerSecond, "Máximo de bytes de mensagens enviados por segundo (0: sem limite)")
+	flag.Float64Var(&config.RateLimit.PerConversation.MessagesPerSecond, "conv-rate-msgs", defaults.PerConversation.MessagesPerSecond, "Máximo de mensagens por segundo a cada canal ou conversa privada (0: sem limite)")
+	flag.Float64Var(&config.RateLimit.PerConversation.BytesPerSecond, "conv-rate-bytes", defaults.PerConversation.BytesPerSecond, "Máximo de bytes por segundo a cada canal ou conversa privada (0: sem limite)")
+	budgets := bluetooth.DefaultReconnectSyncBudgets
+	flag.IntVar(&config.SyncBudgets.Normal, "sync-budget", budgets.Normal, "Bytes de mensagens perdidas pedidos quando um peer ou canal volta, no modo de bateria normal (0: não sincronizar)")
+	flag.IntVar(&config.SyncBudgets.Low, "sync-budget-low", budgets.Low, "Bytes de mensagens perdidas pedidos quando um peer ou canal volta, no modo de bateria low")
+	flag.IntVar(&config.SyncBudgets.UltraLow, "sync-budget-ultralow", budgets.UltraLow, "Bytes de mensagens perdidas pedidos quando um peer ou canal volta, no modo de bateria ultralow")
+	flag.IntVar(&config.QueueCapacity, "queue-capacity", bluetooth.DefaultQueueConfig.Capacity, "Pacotes aguardando nas filas de entrada e saída antes de descartar")
+	flag.StringVar(&config.QueuePolicy, "queue-policy", bluetooth.DefaultQueueConfig.Policy.String(), "Pacote descartado com a fila cheia: lowest-priority (o menos prioritário) ou oldest (o mais antigo)")
 	flag.BoolVar(&config.Debug, "debug", false, "Ativar modo de depuração")
+	flag.StringVar(&config.MetricsAddr, "metrics", "", "Endereço para expor métricas Prometheus em /metrics e a situação dos componentes em /healthz (ex.: :9100)")
+	flag.StringVar(&config.Adapter, "adapter", "", "Adaptador Bluetooth a usar (ex.: hci1; padrão: adaptador padrão do sistema)")
+	flag.StringVar(&config.ScanAdapter, "scan-adapter", "", "Segundo adaptador dedicado ao escaneamento e às conexões iniciadas (ex.: hci1; padrão: usar apenas --adapter)")
+	flag.IntVar(&config.MaxCentrals, "max-centrals", 0, "Máximo de conexões BLE iniciadas por este nó (0: sem limite além do alvo)")
+	flag.DurationVar(&config.ConnIntervalMin, "conn-interval-min", 0, "Intervalo de conexão BLE mínimo (ex.: 15ms; 0: padrão do controlador)")
+	flag.DurationVar(&config.ConnIntervalMax, "conn-interval-max", 0, "Intervalo de conexão BLE máximo (ex.: 30ms; 0: padrão do controlador)")
+	flag.BoolVar(&config.JSON, "json", false, "Emitir eventos e aceitar comandos como JSON, um objeto por linha")
+	flag.BoolVar(&config.NoColor, "no-color", false, "Desativar cores na saída")
+	flag.StringVar(&config.Pipe, "pipe", "", "Modo para bots: enviar cada linha da entrada ao canal e escrever as mensagens recebidas na saída (ex.: #bots)")
+	flag.StringVar(&config.DownloadDir, "downloads", "", "Diretório dos arquivos recebidos (padrão: downloads no diretório de dados)")
+	flag.Int64Var(&config.AutoAcceptSize, "auto-accept", 0, "Aceitar automaticamente arquivos de até este tamanho em bytes (0: sempre perguntar)")
+	flag.StringVar(&config.ConfigPath, "config", "", "Arquivo de configuração (padrão: bitchat.conf no diretório de dados)")
+	flag.StringVar(&config.LogLevel, "log-level", "info", "Nível de log: debug, info, warn ou error")
+	flag.StringVar(&config.LogFile, "log-file", "", "Gravar o log neste arquivo, com rotação, em vez de exibi-lo no chat")
+	flag.Int64Var(&config.LogMaxSize, "log-max-size", 10, "Tamanho em MB a partir do qual o arquivo de log é rotacionado")
 	flag.Parse()
 	
+	// Configurar log
+	logCloser, err := setupLogging(config)
+	if err != nil {
+		fmt.Println("Erro ao configurar log:", err)
+		os.Exit(1)
+	}
+	defer logCloser.Close()
+	
 	// Configurar diretório de dados
 	if config.DataDir == "" {
 		homeDir, err := os.UserHomeDir()
@@ -146,6 +472,14 @@ func main() {
 		os.Exit(1)
 	}
 	
+	if config.ConfigPath == "" {
+		config.ConfigPath = filepath.Join(config.DataDir, ConfigFileName)
+	}
+	
+	if config.DownloadDir == "" {
+		config.DownloadDir = filepath.Join(config.DataDir, "downloads")
+	}
+	
 	// Gerar nome do dispositivo se não fornecido
 	if config.DeviceName == "" {
 		config.DeviceName = fmt.Sprintf("user-%x", utils.GenerateRandomID(4))
@@ -157,10 +491,65 @@ func main() {
 		ActivePeers:     make(map[string]string),
 		BlockedPeers:    make(map[string]bool),
 		MessageHistory:  make(map[string][]*protocol.BitchatMessage),
+		JoinedChannels:  make(map[string]bool),
+		Unread:          make(map[string]int),
+		LeftChannels:    make(map[string]bool),
+		ChannelMembers:  make(map[string]map[string]bool),
+		FileProgress:    make(map[string]int),
 		PrivateMessages: make(map[string][]*protocol.BitchatMessage),
 		Running:         true,
 	}
 	
+	// No modo --json a saída padrão fica reservada aos eventos
+	if config.JSON {
+		enableJSONMode(appState)
+	}
+	
+	// No modo --pipe a saída padrão fica reservada às mensagens do canal
+	if config.Pipe != "" {
+		if config.JSON || !strings.HasPrefix(config.Pipe, "#") {
+			fmt.Println("Uso: bitchat --pipe #canal (incompatível com --json)")
+			os.Exit(2)
+		}
+		enablePipeMode(appState, config.Pipe)
+	}
+	
+	// Carregar preferências salvas
+	settings, err := LoadSettings(config.DataDir)
+	if err != nil {
+		fmt.Println("Aviso:", err)
+	}
+	appState.Settings = settings
+	
+	// Carregar arquivo de configuração
+	fileConfig, err := LoadFileConfig(config.ConfigPath)
+	if err != nil {
+		fmt.Println("Aviso:", err)
+	}
+	loadAliases(appState, fileConfig.Aliases)
+	appState.Hooks = fileConfig.Hooks
+	
+	disableColorIfUnsupported(config)
+	appState.Theme = selectTheme(config, settings)
+	for _, channel := range settings.Channels {
+		appState.JoinedChannels[channel] = true
+	}
+	if len(settings.Channels) > 0 {
+		appState.CurrentChannel = settings.Channels[0]
+	}
+	if config.Pipe != "" {
+		appState.JoinedChannels[config.Pipe] = true
+		appState.CurrentChannel = config.Pipe
+	}
+	
+	// Abrir o armazenamento de mensagens
+	messageStore, err := store.NewMessageStore(filepath.Join(config.DataDir, "messages"))
+	if err != nil {
+		fmt.Println("Erro ao abrir armazenamento de mensagens:", err)
+		os.Exit(1)
+	}
+	appState.Store = messageStore
+	
 	// Carregar ou criar chave de identidade
 	identityKeyPath := filepath.Join(config.DataDir, "identity.key")
 	var identityKey []byte
@@ -219,15 +608,65 @@ func main() {
 	// Configurar delegate
 	meshDelegate := &MeshDelegateImpl{AppState: appState}
 	meshService.SetDelegate(meshDelegate)
+	subscribeHooks(appState)
 	
 	// Configurar opções
+	meshService.SetAdapterID(config.Adapter)
+	meshService.SetScanAdapterID(config.ScanAdapter)
+	connectionConfig := bluetooth.ConnectionConfig{
+		MaxCentralConnections: config.MaxCentrals,
+		MinInterval:           config.ConnIntervalMin,
+		MaxInterval:           config.ConnIntervalMax,
+	}
+	if err := meshService.SetConnectionConfig(connectionConfig); err != nil {
+		fmt.Println("Erro na configuração de conexões:", err)
+		os.Exit(1)
+	}
 	meshService.SetCoverTraffic(config.CoverTraffic)
+	meshService.SetRateLimit(config.RateLimit)
+	if err := meshService.SetReconnectSyncBudgets(config.SyncBudgets); err != nil {
+		fmt.Println("Erro na configuração de sincronização:", err)
+		os.Exit(1)
+	}
+	policy, err := mesh.ParseDropPolicy(config.QueuePolicy)
+	if err != nil {
+		fmt.Println("Erro na configuração das filas:", err)
+		os.Exit(1)
+	}
+	for _, queue := range []string{bluetooth.QueueIncoming, bluetooth.QueueOutgoing} {
+		if err := meshService.SetQueueConfig(queue, bluetooth.QueueConfig{Capacity: config.QueueCapacity, Policy: policy}); err != nil {
+			fmt.Println("Erro na configuração das filas:", err)
+			os.Exit(1)
+		}
+	}
+	meshService.SetOutgoingStore(messageStore)
+	if config.BatteryAuto {
+		if err := meshService.EnableBatteryAuto(batteryThresholds(config)); err != nil {
+			fmt.Println("Erro ao ativar modo de bateria automático:", err)
+			os.Exit(1)
+		}
+	}
+	if err := meshService.SetBlockedFingerprints(settings.Blocked); err != nil {
+		fmt.Println("Aviso: lista de bloqueio inválida:", err)
+	}
+	if err := meshService.SetFavorites(settings.Favorites); err != nil {
+		fmt.Println("Aviso: lista de favoritos inválida:", err)
+	}
+	meshService.SetReadReceipts(!settings.NoReadReceipts)
+	meshService.SetTypingIndicators(!settings.NoTyping)
+	meshService.SetAway(settings.AwayMessage)
 	
 	// Iniciar serviço mesh
 	if err := meshService.Start(); err != nil {
 		fmt.Println("Erro ao iniciar serviço mesh:", err)
 		os.Exit(1)
 	}
+	announceJoinedChannels(appState)
+	
+	// Expor métricas para o Prometheus, se configurado
+	if config.MetricsAddr != "" {
+		go serveMetrics(config.MetricsAddr, appState)
+	}
 	
 	// Exibir informações iniciais
 	fmt.Println("Bitchat", AppVersion)
@@ -235,28 +674,161 @@ func main() {
 	fmt.Println("ID do dispositivo:", fmt.Sprintf("%x", deviceID))
 	fmt.Println("Diretório de dados:", config.DataDir)
 	fmt.Println("Tráfego de cobertura:", config.CoverTraffic)
+	if channels := joinedChannels(appState); len(channels) > 0 {
+		fmt.Printf("Canais: %s (em foco: %s)\n", strings.Join(channels, ", "), appState.CurrentChannel)
+	}
 	fmt.Println("Digite /help para ajuda")
 	
+	// Subcomando: bitchat bench @peer [quantidade] [tamanho]
+	if args := flag.Args(); len(args) > 0 && args[0] == "bench" {
+		status := runBenchCommand(appState, args[1:])
+		meshService.Stop()
+		os.Exit(status)
+	}
+	
+	// Subcomando: bitchat send --channel #canal|--to @peer texto
+	if args := flag.Args(); len(args) > 0 && args[0] == "send" {
+		status := runSendCommand(appState, args[1:])
+		meshService.Stop()
+		os.Exit(status)
+	}
+	
+	// Subcomando: bitchat diag [--output arquivo] [--wait 10s]
+	if args := flag.Args(); len(args) > 0 && args[0] == "diag" {
+		status := runDiagCommand(appState, args[1:])
+		meshService.Stop()
+		os.Exit(status)
+	}
+	
+	// Enviar as mensagens agendadas no horário marcado
+	startScheduler(appState)
+	
 	// Configurar captura de sinais para encerramento limpo
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	appState.Signals = sigChan
 	
 	// Iniciar loop de entrada do usuário em uma goroutine
-	go inputLoop(appState)
+	go inputLoop(appState, sigChan)
 	
-	// Aguardar sinal de encerramento
+	// Aguardar sinal de encerramento, também enviado por /quit
 	<-sigChan
-	fmt.Println("\nEncerrando...")
+	shutdown(appState)
+}
+
+// serveMetrics expõe as estatísticas da rede mesh no formato do Prometheus
+// e a situação dos componentes em /healthz
+func serveMetrics(addr string, appState *AppState) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeMetrics(w, appState)
+	})
+	mux.HandleFunc("/healthz", serveHealthz(appState))
+	
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		fmt.Println("Erro ao servir métricas:", err)
+	}
+}
+
+// runBenchCommand executa o subcomando bench: aguarda a descoberta do peer,
+// mede o enlace e retorna o código de saída do processo
+func runBenchCommand(appState *AppState, args []string) int {
+	if len(args) == 0 || !strings.HasPrefix(args[0], "@") {
+		fmt.Println("Uso: bitchat bench @usuario [quantidade] [tamanho]")
+		return 2
+	}
 	
-	// Parar serviços
-	appState.Running = false
-	meshService.Stop()
+	username := args[0][1:] // Remover @
+	fmt.Printf("Aguardando o peer %s...\n", username)
 	
-	fmt.Println("Bitchat encerrado")
+	deadline := time.Now().Add(BenchDiscoveryTimeout)
+	for findPeerByName(appState, username) == "" {
+		if time.Now().After(deadline) {
+			fmt.Printf("Usuário %s não encontrado\n", username)
+			return 1
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	
+	if !runBench(appState, username, strings.Join(args[1:], " ")) {
+		return 1
+	}
+	return 0
 }
 
-// inputLoop processa entrada do usuário
-func inputLoop(appState *AppState) {
+// runBench mede goodput, RTT e perda no enlace com um peer e exibe o
+// resultado. Retorna false se a medição não pôde ser feita.
+func runBench(appState *AppState, username string, options string) bool {
+	peerID := findPeerByName(appState, username)
+	if peerID == "" {
+		fmt.Printf("Usuário %s não encontrado\n", username)
+		return false
+	}
+	
+	count, size := 0, 0
+	if options != "" {
+		if _, err := fmt.Sscanf(options, "%d %d", &count, &size); err != nil && count == 0 {
+			fmt.Println("Uso: /bench @usuario [quantidade] [tamanho]")
+			return false
+		}
+	}
+	
+	fmt.Printf("Medindo enlace com %s...\n", username)
+	result, err := appState.MeshService.Benchmark(peerID, count, size)
+	if err != nil {
+		fmt.Println("Erro na medição:", err)
+		return false
+	}
+	
+	fmt.Printf("Resultado com %s (%d cargas de %d bytes):\n", username, result.Sent, result.PayloadSize)
+	fmt.Printf("  Goodput: %.1f B/s\n", result.Goodput())
+	fmt.Printf("  Perda: %.1f%% (%d de %d confirmadas)\n", result.Loss()*100, result.Received, result.Sent)
+	if result.Received > 0 {
+		fmt.Printf("  RTT: mín %v / méd %v / máx %v\n", result.MinRTT, result.AvgRTT, result.MaxRTT)
+	}
+	return result.Received > 0
+}
+
+// findPeerByName busca o ID de um peer ativo pelo nickname
+func findPeerByName(appState *AppState, username string) string {
+	appState.mutex.RLock()
+	defer appState.mutex.RUnlock()
+
+	for id, name := range appState.ActivePeers {
+		if name == username {
+			return id
+		}
+	}
+	return ""
+}
+
+// inputLoop processa entrada do usuário. No terminal, Ctrl+C ou Ctrl+D em
+// uma linha vazia encerram o Bitchat.
+func inputLoop(appState *AppState, sigChan chan<- os.Signal) {
+	if appState.Events != nil {
+		jsonInputLoop(appState)
+		return
+	}
+	if appState.Pipe != nil {
+		pipeInputLoop(appState)
+		return
+	}
+	
+	if reader := NewLineReader(appState); reader != nil {
+		appState.LineReader = reader
+		for appState.Running {
+			input, err := reader.ReadLine()
+			if err != nil {
+				sigChan <- os.Interrupt
+				return
+			}
+			appState.MeshService.StopTyping(currentConversation(appState))
+			processUserInput(input, appState)
+		}
+		return
+	}
+	
 	scanner := bufio.NewScanner(os.Stdin)
 	
 	for appState.Running && scanner.Scan() {
@@ -281,6 +853,9 @@ func processUserInput(input string, appState *AppState) {
 		}
 		
 		processCommand(command, args, appState)
+	} else if appState.CurrentDM != "" {
+		// Mensagem para a conversa privada em foco
+		sendConversationMessage(appState, input)
 	} else {
 		// Mensagem normal para o canal atual
 		if appState.CurrentChannel == "" {
@@ -288,35 +863,78 @@ func processUserInput(input string, appState *AppState) {
 			return
 		}
 		
-		// Criar mensagem
-		message := &protocol.BitchatMessage{
-			Content: input,
-			Channel: appState.CurrentChannel,
-		}
-		
-		// Enviar mensagem
-		messageID, err := appState.MeshService.SendMessage(message)
-		if err != nil {
+		if _, err := sendChannelMessage(appState, appState.CurrentChannel, input); err != nil {
 			fmt.Println("Erro ao enviar mensagem:", err)
-			return
 		}
-		
-		// Adicionar à história local
-		if _, ok := appState.MessageHistory[appState.CurrentChannel]; !ok {
-			appState.MessageHistory[appState.CurrentChannel] = make([]*protocol.BitchatMessage, 0)
-		}
-		
-		// Adicionar informações locais
-		message.ID = messageID
-		message.Timestamp = uint64(time.Now().UnixMilli())
-		message.Sender = appState.Config.DeviceName
-		message.DeliveryStatus = protocol.DeliveryStatusSending
-		
-		appState.MessageHistory[appState.CurrentChannel] = append(
-			appState.MessageHistory[appState.CurrentChannel], message)
 	}
 }
 
+// sendChannelMessage envia uma mensagem a um canal e a guarda no histórico local
+func sendChannelMessage(appState *AppState, channel string, content string) (string, error) {
+	// Criar mensagem
+	message := &protocol.BitchatMessage{
+		Content: content,
+		Channel: channel,
+	}
+	
+	// Enviar mensagem
+	messageID, err := appState.MeshService.SendMessage(message)
+	if err != nil {
+		return "", err
+	}
+	
+	// Adicionar informações locais
+	message.ID = messageID
+	message.Timestamp = uint64(time.Now().UnixMilli())
+	message.Sender = appState.Config.DeviceName
+	message.DeliveryStatus = protocol.DeliveryStatusSending
+	
+	// Adicionar à história local
+	appState.mutex.Lock()
+	appState.MessageHistory[channel] = append(appState.MessageHistory[channel], message)
+	appState.mutex.Unlock()
+	appState.Store.AddChannelMessage(channel, message)
+	return messageID, nil
+}
+
+// sendPrivateMessage envia uma mensagem privada a um peer ativo e a guarda
+// no histórico local
+func sendPrivateMessage(appState *AppState, recipientPeerID string, recipient string, content string, urgent bool) (string, error) {
+	// Criar mensagem privada
+	message := &protocol.BitchatMessage{
+		Content:          content,
+		IsPrivate:        true,
+		IsUrgent:         urgent,
+		RecipientNickname: recipient,
+	}
+	
+	// Enviar mensagem; a peers offline ela é guardada até voltarem
+	offline := appState.MeshService.GetDeliveryRoute(recipientPeerID) == mesh.RouteMailbox
+	messageID, err := appState.MeshService.SendMessage(message)
+	if err != nil {
+		return "", err
+	}
+	if offline {
+		fmt.Printf("%s está offline; a mensagem será entregue quando voltar (/queue)\n", recipient)
+	}
+	
+	// Adicionar à história local
+	if _, ok := appState.PrivateMessages[recipientPeerID]; !ok {
+		appState.PrivateMessages[recipientPeerID] = make([]*protocol.BitchatMessage, 0)
+	}
+	
+	// Adicionar informações locais
+	message.ID = messageID
+	message.Timestamp = uint64(time.Now().UnixMilli())
+	message.Sender = appState.Config.DeviceName
+	message.DeliveryStatus = protocol.DeliveryStatusSending
+	
+	appState.PrivateMessages[recipientPeerID] = append(
+		appState.PrivateMessages[recipientPeerID], message)
+	appState.Store.AddPrivateMessage(conversationKey(recipient), message)
+	return messageID, nil
+}
+
 // processCommand processa comandos do usuário
 func processCommand(command, args string, appState *AppState) {
 	switch command {
@@ -326,26 +944,24 @@ func processCommand(command, args string, appState *AppState) {
 			return
 		}
 		
-		channel := args
-		appState.CurrentChannel = channel
-		fmt.Printf("Entrando no canal %s\n", channel)
+		joinChannel(appState, args)
 		
-		// Exibir histórico do canal se disponível
-		if messages, ok := appState.MessageHistory[channel]; ok && len(messages) > 0 {
-			fmt.Printf("--- Histórico do canal %s ---\n", channel)
-			for _, msg := range messages {
-				fmt.Printf("[%s] %s: %s\n", 
-					time.Unix(0, int64(msg.Timestamp)*int64(time.Millisecond)).Format("15:04:05"),
-					msg.Sender, 
-					msg.Content)
-			}
-			fmt.Println("--- Fim do histórico ---")
-		}
+	case "/switch":
+		switchCommand(appState, args)
+		
+	case "/leave":
+		leaveCommand(appState, args)
+		
+	case "/topic":
+		topicCommand(appState, args)
+		
+	case "/m", "/msg", "/urgent":
+		// Mensagens urgentes são enviadas por caminhos redundantes
+		urgent := command == "/urgent"
 		
-	case "/m", "/msg":
 		parts := strings.SplitN(args, " ", 2)
 		if len(parts) < 2 || !strings.HasPrefix(parts[0], "@") {
-			fmt.Println("Uso: /m @usuario mensagem")
+			fmt.Printf("Uso: %s @usuario mensagem\n", command)
 			return
 		}
 		
@@ -353,165 +969,199 @@ func processCommand(command, args string, appState *AppState) {
 		content := parts[1]
 		
 		// Buscar peer pelo nickname
-		var recipientPeerID string
-		for id, name := range appState.ActivePeers {
-			if name == recipient {
-				recipientPeerID = id
-				break
-			}
-		}
+		recipientPeerID := findPeerByName(appState, recipient)
 		
 		if recipientPeerID == "" {
 			fmt.Printf("Usuário %s não encontrado\n", recipient)
 			return
 		}
 		
-		// Criar mensagem privada
-		message := &protocol.BitchatMessage{
-			Content:          content,
-			IsPrivate:        true,
-			RecipientNickname: recipient,
-		}
-		
-		// Enviar mensagem
-		messageID, err := appState.MeshService.SendMessage(message)
-		if err != nil {
+		if _, err := sendPrivateMessage(appState, recipientPeerID, recipient, content, urgent); err != nil {
 			fmt.Println("Erro ao enviar mensagem privada:", err)
 			return
 		}
 		
-		// Adicionar à história local
-		if _, ok := appState.PrivateMessages[recipientPeerID]; !ok {
-			appState.PrivateMessages[recipientPeerID] = make([]*protocol.BitchatMessage, 0)
+		if urgent {
+			fmt.Printf("[Urgente para %s]: %s\n", recipient, content)
+		} else {
+			fmt.Printf("[Privado para %s]: %s\n", recipient, content)
 		}
 		
-		// Adicionar informações locais
-		message.ID = messageID
-		message.Timestamp = uint64(time.Now().UnixMilli())
-		message.Sender = appState.Config.DeviceName
-		message.DeliveryStatus = protocol.DeliveryStatusSending
+	case "/dm":
+		dmCommand(appState, args)
 		
-		appState.PrivateMessages[recipientPeerID] = append(
-			appState.PrivateMessages[recipientPeerID], message)
+	case "/w", "/who":
+		whoCommand(appState)
 		
-		fmt.Printf("[Privado para %s]: %s\n", recipient, content)
+	case "/whois":
+		whoisCommand(appState, args)
 		
-	case "/w", "/who":
-		fmt.Println("Peers online:")
-		if len(appState.ActivePeers) == 0 {
-			fmt.Println("  Nenhum peer encontrado")
-		} else {
-			for id, name := range appState.ActivePeers {
-				fmt.Printf("  %s (%s)\n", name, id)
-			}
+	case "/export":
+		exportCommand(appState, args)
+		
+	case "/history":
+		historyCommand(appState, args)
+		
+	case "/more":
+		appState.Pager.More()
+		
+	case "/bond":
+		parts := strings.Fields(args)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], "@") {
+			fmt.Println("Uso: /bond @usuario [on|off]")
+			return
 		}
 		
-	case "/channels":
-		fmt.Println("Canais ativos:")
-		if len(appState.MessageHistory) == 0 {
-			fmt.Println("  Nenhum canal ativo")
-		} else {
-			for channel := range appState.MessageHistory {
-				fmt.Printf("  %s\n", channel)
-			}
+		username := parts[0][1:] // Remover @
+		
+		// Buscar peer pelo nickname
+		peerID := findPeerByName(appState, username)
+		
+		if peerID == "" {
+			fmt.Printf("Usuário %s não encontrado\n", username)
+			return
 		}
 		
-	case "/block":
-		if args == "" {
-			// Listar peers bloqueados
-			fmt.Println("Peers bloqueados:")
-			if len(appState.BlockedPeers) == 0 {
-				fmt.Println("  Nenhum peer bloqueado")
-			} else {
-				for id := range appState.BlockedPeers {
-					name := "desconhecido"
-					if n, ok := appState.ActivePeers[id]; ok {
-						name = n
-					}
-					fmt.Printf("  %s (%s)\n", name, id)
-				}
-			}
-		} else if !strings.HasPrefix(args, "@") {
-			fmt.Println("Uso: /block @usuario")
+		enabled := strings.ToLower(parts[1]) == "on"
+		if err := appState.MeshService.SetPeerBonding(peerID, enabled); err != nil {
+			fmt.Println("Erro ao configurar vínculo:", err)
+			return
+		}
+		
+		if enabled {
+			fmt.Printf("Vínculo BLE com %s ativado (o sistema passa a reconhecer este dispositivo)\n", username)
 		} else {
-			// Bloquear peer
-			username := args[1:] // Remover @
-			
-			// Buscar peer pelo nickname
-			var peerID string
-			for id, name := range appState.ActivePeers {
-				if name == username {
-					peerID = id
-					break
-				}
-			}
-			
-			if peerID == "" {
-				fmt.Printf("Usuário %s não encontrado\n", username)
-				return
-			}
-			
-			appState.BlockedPeers[peerID] = true
-			fmt.Printf("Usuário %s bloqueado\n", username)
+			fmt.Printf("Vínculo BLE com %s removido\n", username)
 		}
 		
-	case "/unblock":
+	case "/trace":
 		if args == "" || !strings.HasPrefix(args, "@") {
-			fmt.Println("Uso: /unblock @usuario")
+			fmt.Println("Uso: /trace @usuario")
 			return
 		}
 		
 		username := args[1:] // Remover @
 		
 		// Buscar peer pelo nickname
-		var peerID string
-		for id, name := range appState.ActivePeers {
-			if name == username {
-				peerID = id
-				break
-			}
-		}
+		peerID := findPeerByName(appState, username)
 		
 		if peerID == "" {
 			fmt.Printf("Usuário %s não encontrado\n", username)
 			return
 		}
 		
-		delete(appState.BlockedPeers, peerID)
-		fmt.Printf("Usuário %s desbloqueado\n", username)
+		if err := appState.MeshService.Trace(peerID); err != nil {
+			fmt.Println("Erro ao rastrear rota:", err)
+			return
+		}
+		fmt.Printf("Rastreando rota até %s...\n", username)
+		
+	case "/bench":
+		parts := strings.SplitN(args, " ", 2)
+		if args == "" || !strings.HasPrefix(parts[0], "@") {
+			fmt.Println("Uso: /bench @usuario [quantidade] [tamanho]")
+			return
+		}
+		
+		options := ""
+		if len(parts) > 1 {
+			options = parts[1]
+		}
+		
+		// A medição leva alguns segundos; não bloquear a entrada
+		go runBench(appState, parts[0][1:], options)
+		
+	case "/channels":
+		channelsCommand(appState)
+		
+	case "/mute":
+		muteCommand(appState, args)
+		
+	case "/unmute":
+		unmuteCommand(appState, args)
+		
+	case "/favorite":
+		favoriteCommand(appState, args)
+		
+	case "/unfavorite":
+		unfavoriteCommand(appState, args)
+		
+	case "/block":
+		blockCommand(appState, args)
+		
+	case "/unblock":
+		unblockCommand(appState, args)
 		
 	case "/clear":
 		if appState.CurrentChannel != "" {
 			// Limpar histórico do canal atual
+			appState.mutex.Lock()
 			delete(appState.MessageHistory, appState.CurrentChannel)
+			appState.mutex.Unlock()
 			fmt.Printf("Histórico do canal %s limpo\n", appState.CurrentChannel)
 		} else {
 			fmt.Println("Você não está em nenhum canal")
 		}
 		
-	case "/battery":
+	case "/nick":
+		args = strings.TrimSpace(args)
 		if args == "" {
-			fmt.Println("Uso: /battery [normal|low|ultralow]")
+			fmt.Println("Seu apelido:", appState.Config.DeviceName)
+			fmt.Println("Uso: /nick novo_nome")
 			return
 		}
 		
-		mode := strings.ToLower(args)
-		var batteryMode int
-		
-		switch mode {
-		case "normal":
-			batteryMode = bluetooth.BatteryModeNormal
-		case "low":
-			batteryMode = bluetooth.BatteryModeLow
-		case "ultralow":
-			batteryMode = bluetooth.BatteryModeUltraLow
-		default:
-			fmt.Println("Modo inválido. Use: normal, low ou ultralow")
+		if err := appState.MeshService.SetNickname(args); err != nil {
+			fmt.Printf("Erro ao trocar apelido: %v\n", err)
 			return
 		}
+		appState.Config.DeviceName = args
+		fmt.Printf("Apelido alterado para: %s\n", args)
+		
+	case "/send":
+		sendFileCommand(appState, args)
+		
+	case "/accept":
+		acceptFileCommand(appState, args)
+		
+	case "/reject":
+		rejectFileCommand(appState, args)
+		
+	case "/theme":
+		themeCommand(appState, args)
+		
+	case "/notify":
+		notifyCommand(appState, args)
+		
+	case "/receipts":
+		receiptsCommand(appState, args)
 		
-		appState.MeshService.SetBatteryMode(batteryMode)
-		fmt.Printf("Modo de bateria alterado para: %s\n", mode)
+	case "/typing":
+		typingCommand(appState, args)
+		
+	case "/away":
+		awayCommand(appState, args)
+		
+	case "/back":
+		backCommand(appState)
+		
+	case "/schedule":
+		scheduleCommand(appState, args)
+		
+	case "/queue":
+		queueCommand(appState)
+		
+	case "/unschedule":
+		unscheduleCommand(appState, args)
+		
+	case "/health":
+		healthCommand(appState)
+		
+	case "/stats":
+		statsCommand(appState)
+		
+	case "/battery":
+		batteryCommand(appState, args)
 		
 	case "/cover":
 		if args == "" {
@@ -528,27 +1178,149 @@ func processCommand(command, args string, appState *AppState) {
 			fmt.Println("Tráfego de cobertura desativado")
 		}
 		
+	case "/relay":
+		policy := appState.MeshService.GetRelayPolicy()
+		parts := strings.Fields(args)
+		
+		if len(parts) == 0 {
+			packets, bytes, denied := appState.MeshService.GetRelayStats()
+			limit := "ilimitado"
+			if policy.MaxBytesPerMinute > 0 {
+				limit = fmt.Sprintf("%d bytes/min", policy.MaxBytesPerMinute)
+			}
+			fmt.Println("Política de relay:")
+			fmt.Printf("  Limite: %s\n", limit)
+			fmt.Printf("  Apenas verificados: %v\n", policy.VerifiedOnly)
+			fmt.Printf("  Arquivos: %v\n", policy.RelayFileTransfers)
+			fmt.Printf("  Bateria ultra baixa: %v\n", policy.RelayInUltraLowPower)
+			fmt.Printf("  Bateria mínima para broadcasts: %d%%\n", policy.MinBatteryLevel)
+			if level, ok := appState.MeshService.GetBatteryLevel(); ok {
+				fmt.Printf("  Bateria atual: %d%%\n", level)
+			}
+			fmt.Printf("  Retransmitidos: %d pacotes (%d bytes)\n", packets, bytes)
+			for reason, count := range denied {
+				fmt.Printf("  Recusados (%s): %d\n", reason, count)
+			}
+			return
+		}
+		
+		if len(parts) != 2 {
+			fmt.Println("Uso: /relay [limit <bytes/min>|verified on|off|files on|off|ultralow on|off|battery <%>]")
+			return
+		}
+		
+		enabled := strings.ToLower(parts[1]) == "on"
+		switch strings.ToLower(parts[0]) {
+		case "limit":
+			var limit int
+			if _, err := fmt.Sscanf(parts[1], "%d", &limit); err != nil || limit < 0 {
+				fmt.Println("Limite inválido")
+				return
+			}
+			policy.MaxBytesPerMinute = limit
+		case "verified":
+			policy.VerifiedOnly = enabled
+		case "files":
+			policy.RelayFileTransfers = enabled
+		case "ultralow":
+			policy.RelayInUltraLowPower = enabled
+		case "battery":
+			var level int
+			if _, err := fmt.Sscanf(parts[1], "%d", &level); err != nil || level < 0 || level > 100 {
+				fmt.Println("Nível de bateria inválido")
+				return
+			}
+			policy.MinBatteryLevel = level
+		default:
+			fmt.Println("Uso: /relay [limit <bytes/min>|verified on|off|files on|off|ultralow on|off|battery <%>]")
+			return
+		}
+		
+		appState.MeshService.SetRelayPolicy(&policy)
+		fmt.Println("Política de relay atualizada")
+		
+	case "/topology":
+		snapshot := appState.MeshService.GetTopology()
+		
+		switch strings.ToLower(strings.TrimSpace(args)) {
+		case "":
+			fmt.Print(snapshot.ASCII())
+		case "dot":
+			fmt.Print(snapshot.DOT())
+		default:
+			// Exportar DOT para arquivo
+			if err := os.WriteFile(args, []byte(snapshot.DOT()), 0644); err != nil {
+				fmt.Println("Erro ao exportar topologia:", err)
+				return
+			}
+			fmt.Printf("Topologia exportada para %s\n", args)
+		}
+		
 	case "/help":
-		fmt.Println("Comandos disponíveis:")
-		fmt.Println("  /j #canal - Entrar ou criar um canal")
-		fmt.Println("  /m @nome mensagem - Enviar uma mensagem privada")
-		fmt.Println("  /w - Listar usuários online")
-		fmt.Println("  /channels - Mostrar todos os canais descobertos")
-		fmt.Println("  /block @nome - Bloquear um peer")
-		fmt.Println("  /block - Listar todos os peers bloqueados")
-		fmt.Println("  /unblock @nome - Desbloquear um peer")
-		fmt.Println("  /clear - Limpar mensagens do chat atual")
-		fmt.Println("  /battery [normal|low|ultralow] - Definir modo de economia de bateria")
-		fmt.Println("  /cover [on|off] - Ativar/desativar tráfego de cobertura")
-		fmt.Println("  /help - Mostrar esta ajuda")
-		fmt.Println("  /quit - Sair do aplicativo")
+		var page Page
+		page.Println("Comandos disponíveis:")
+		page.Println("  /j #canal - Entrar ou criar um canal")
+		page.Println("  /switch [#canal] - Trocar o canal em foco ou listar seus canais")
+		page.Println("  /leave [#canal] - Sair de um canal (padrão: canal em foco)")
+		page.Println("  /topic [#canal] [tópico] - Mostrar ou definir (se dono) o tópico do canal")
+		page.Println("  /m @nome mensagem - Enviar uma mensagem privada")
+		page.Println("  /urgent @nome mensagem - Enviar mensagem privada por múltiplos caminhos")
+		page.Println("  /dm @nome - Abrir a conversa privada com um peer (/dm sem nome volta ao canal)")
+		page.Println("  /w - Listar usuários online, do sinal mais forte ao mais fraco, com distância, verificação (✓) e favoritos (★)")
+		page.Println("  /whois @nome - Mostrar identidade, distância, capacidades e reputação de um peer")
+		page.Println("  /export #canal|@nome arquivo [desde [até]] - Exportar o histórico em Markdown (.md) ou JSON (.json)")
+		page.Println("  /history [N] - Mostrar as últimas N mensagens guardadas da conversa em foco (repetir para ver anteriores)")
+		page.Println("  /more - Continuar uma saída longa (PageUp e PageDown também rolam a saída)")
+		page.Println("  /trace @nome - Rastrear a rota até um peer, com RSSI por salto")
+		page.Println("  /bench @nome [quantidade] [tamanho] - Medir goodput, RTT e perda do enlace com um peer")
+		page.Println("  /channels - Listar os canais anunciados na rede, com membros")
+		page.Println("  /mute [#canal|@nome] - Silenciar um canal ou peer, sem descartar as mensagens (sem argumento, listar)")
+		page.Println("  /unmute #canal|@nome - Deixar de silenciar um canal ou peer")
+		page.Println("  /favorite [@nome] - Marcar um favorito, avisado ao entrar no alcance (sem argumento, listar)")
+		page.Println("  /unfavorite @nome - Desmarcar um favorito")
+		page.Println("  /block @nome|impressão - Bloquear um peer pela identidade, mesmo após reinícios e trocas de apelido")
+		page.Println("  /block - Listar todos os peers bloqueados")
+		page.Println("  /unblock @nome|impressão - Desbloquear um peer")
+		page.Println("  /bond @nome [on|off] - Parear com um peer confiável para cifrar o enlace BLE")
+		page.Println("  /clear - Limpar mensagens do chat atual")
+		page.Println("  /nick nome - Trocar seu apelido")
+		page.Println("  /notify [#canal|@nome|default] [bell|notify|mentions|none] - Configurar alertas de mensagens")
+		page.Println("  /receipts [on|off] - Mostrar ou configurar o envio de confirmações de leitura")
+		page.Println("  /typing [on|off] - Mostrar ou configurar o aviso de que você está digitando")
+		page.Println("  /away [mensagem|log] - Responder automaticamente às mensagens privadas durante a ausência (log: respostas enviadas)")
+		page.Println("  /back - Voltar da ausência")
+		page.Println("  /queue - Listar as mensagens aguardando transmissão, também após reinícios")
+		page.Println("  /schedule [HH:MM|30m|online #canal|@nome texto] - Agendar uma mensagem (sem argumentos, listar as agendadas)")
+		page.Println("  /unschedule id - Cancelar uma mensagem agendada")
+		page.Println("  /send @nome caminho - Oferecer um arquivo a um peer")
+		page.Println("  /accept [oferta] - Aceitar um arquivo oferecido (sem oferta, listar transferências)")
+		page.Println("  /reject oferta - Recusar um arquivo oferecido")
+		page.Println("  /theme [nome] - Mostrar ou trocar o tema de cores")
+		page.Println("  /battery [normal|low|ultralow|auto [baixa ultrabaixa]] - Definir modo de economia de bateria ou escolhê-lo pelo nível")
+		page.Println("  /stats - Mostrar estatísticas da rede, das filas e do armazenamento")
+		page.Println("  /health - Mostrar a situação do adaptador, do transporte, do armazenamento e da fila de reenvio")
+		page.Println("  /cover [on|off] - Ativar/desativar tráfego de cobertura")
+		page.Println("  /relay [opção valor] - Mostrar ou configurar a política de relay")
+		page.Println("  /topology [dot|arquivo.dot] - Mostrar ou exportar o mapa da rede")
+		page.Println("  /help - Mostrar esta ajuda")
+		page.Println("  /quit - Sair do aplicativo")
+		if len(appState.Aliases) > 0 {
+			page.Println("Aliases:")
+			for _, name := range aliasNames(appState) {
+				page.Printf("  %s = %s\n", name, appState.Aliases[name])
+			}
+		}
+		appState.Pager.Show(&page)
 		
 	case "/quit", "/exit":
 		fmt.Println("Saindo...")
 		appState.Running = false
-		os.Exit(0)
+		requestShutdown(appState)
 		
 	default:
+		if runAlias(command, args, appState) {
+			return
+		}
 		fmt.Printf("Comando desconhecido: %s\nDigite /help para ajuda\n", command)
 	}
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// isMuted informa se uma mensagem pertence a um canal silenciado ou foi
+// enviada por um peer silenciado. Mensagens silenciadas são recebidas e
+// guardadas, mas não exibidas nem alertadas; para descartar o tráfego de um
+// peer use /block.
+func isMuted(settings *Settings, message *protocol.BitchatMessage) bool {
+	return settings.Muted[alertScope(message)] || settings.Muted["@"+message.Sender]
+}
+
+// muteCommand processa /mute [#canal|@nome]; sem argumento lista os
+// silenciados
+func muteCommand(appState *AppState, args string) {
+	scope := strings.TrimSpace(args)
+	if scope == "" {
+		if len(appState.Settings.Muted) == 0 {
+			fmt.Println("Nenhum canal ou peer silenciado")
+			return
+		}
+		scopes := make([]string, 0, len(appState.Settings.Muted))
+		for scope := range appState.Settings.Muted {
+			scopes = append(scopes, scope)
+		}
+		sort.Strings(scopes)
+		fmt.Println("Silenciados:", strings.Join(scopes, ", "))
+		return
+	}
+	if !strings.HasPrefix(scope, "#") && !strings.HasPrefix(scope, "@") {
+		fmt.Println("Uso: /mute [#canal|@nome]")
+		return
+	}
+
+	appState.Settings.Muted[scope] = true
+	if err := appState.Settings.Save(); err != nil {
+		fmt.Println("Erro ao salvar preferências:", err)
+		return
+	}
+	fmt.Printf("%s silenciado; as mensagens continuam sendo guardadas\n", scope)
+}
+
+// unmuteCommand processa /unmute #canal|@nome
+func unmuteCommand(appState *AppState, args string) {
+	scope := strings.TrimSpace(args)
+	if !strings.HasPrefix(scope, "#") && !strings.HasPrefix(scope, "@") {
+		fmt.Println("Uso: /unmute #canal|@nome")
+		return
+	}
+	if !appState.Settings.Muted[scope] {
+		fmt.Printf("%s não está silenciado\n", scope)
+		return
+	}
+
+	delete(appState.Settings.Muted, scope)
+	if err := appState.Settings.Save(); err != nil {
+		fmt.Println("Erro ao salvar preferências:", err)
+		return
+	}
+	fmt.Printf("%s não está mais silenciado\n", scope)
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+const (
+	// ScrollbackLines é o número de linhas de saída guardadas para PageUp
+	ScrollbackLines = 1000
+
+	// minPageSize é a menor página usada quando o terminal é muito baixo
+	minPageSize = 5
+)
+
+// Sequências enviadas pelo terminal para as teclas PageUp e PageDown
+var (
+	pageUpKey   = []byte("\x1b[5~")
+	pageDownKey = []byte("\x1b[6~")
+)
+
+// Page acumula uma saída longa para exibição paginada
+type Page struct {
+	buffer bytes.Buffer
+}
+
+// Printf acrescenta texto formatado à página
+func (p *Page) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(&p.buffer, format, args...)
+}
+
+// Println acrescenta uma linha à página
+func (p *Page) Println(args ...interface{}) {
+	fmt.Fprintln(&p.buffer, args...)
+}
+
+// lines retorna as linhas acumuladas
+func (p *Page) lines() []string {
+	return strings.Split(strings.TrimSuffix(p.buffer.String(), "\n"), "\n")
+}
+
+// Pager guarda a saída recente do terminal e exibe saídas longas uma página
+// por vez: /more ou PageDown avançam, PageUp volta. Sem saída paginada em
+// andamento, PageUp percorre a saída recente. Um Pager nil, fora do terminal
+// interativo, exibe tudo de uma vez.
+type Pager struct {
+	out      io.Writer
+	pageSize func() int
+
+	mutex      sync.Mutex
+	scrollback []string
+	partial    []byte   // Linha da saída ainda sem quebra
+	view       []string // Saída sendo paginada
+	top, end   int      // Linhas de view na página exibida
+}
+
+// NewPager cria um pager que escreve em out, com páginas do tamanho
+// retornado por pageSize
+func NewPager(out io.Writer, pageSize func() int) *Pager {
+	return &Pager{out: out, pageSize: pageSize}
+}
+
+// terminalPageSize retorna a altura do terminal menos as linhas do prompt e
+// do aviso de continuação
+func terminalPageSize(fd int) func() int {
+	return func() int {
+		_, height, err := term.GetSize(fd)
+		if err != nil || height-2 < minPageSize {
+			return minPageSize
+		}
+		return height - 2
+	}
+}
+
+// Write guarda a saída do programa na memória de rolagem
+func (p *Pager) Write(data []byte) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.partial = append(p.partial, data...)
+	for {
+		i := bytes.IndexByte(p.partial, '\n')
+		if i < 0 {
+			break
+		}
+		p.scrollback = append(p.scrollback, string(p.partial[:i]))
+		p.partial = p.partial[i+1:]
+	}
+	if excess := len(p.scrollback) - ScrollbackLines; excess > 0 {
+		p.scrollback = append([]string(nil), p.scrollback[excess:]...)
+	}
+	return len(data), nil
+}
+
+// Show exibe uma página acumulada, paginando se não couber no terminal
+func (p *Pager) Show(page *Page) {
+	if p == nil {
+		fmt.Print(page.buffer.String())
+		return
+	}
+	if page.buffer.Len() == 0 {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.view = page.lines()
+	p.top, p.end = 0, 0
+	p.nextLocked()
+}
+
+// More exibe a próxima página da saída paginada
+func (p *Pager) More() {
+	if p == nil {
+		fmt.Println("Nada mais a exibir")
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.view == nil {
+		fmt.Fprintln(p.out, "Nada mais a exibir")
+		return
+	}
+	p.nextLocked()
+}
+
+// PageUp exibe a página anterior da saída paginada ou, sem ela, da memória
+// de rolagem
+func (p *Pager) PageUp() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	size := p.pageSize()
+	if p.view == nil {
+		if len(p.scrollback) <= size {
+			return
+		}
+		p.view = append([]string(nil), p.scrollback...)
+		p.top = len(p.view) - size
+		p.end = len(p.view)
+	}
+	if p.top == 0 {
+		fmt.Fprintln(p.out, "-- início --")
+		return
+	}
+
+	top := p.top - size
+	if top < 0 {
+		top = 0
+	}
+	p.writeLinesLocked(p.view[top:p.top])
+	p.top, p.end = top, p.top
+	p.hintLocked()
+}
+
+// PageDown exibe a próxima página, se houver saída paginada
+func (p *Pager) PageDown() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.view != nil {
+		p.nextLocked()
+	}
+}
+
+// nextLocked exibe a página seguinte à exibida. Exige p.mutex.
+func (p *Pager) nextLocked() {
+	end := p.end + p.pageSize()
+	if end > len(p.view) {
+		end = len(p.view)
+	}
+	p.writeLinesLocked(p.view[p.end:end])
+	p.top, p.end = p.end, end
+	p.hintLocked()
+}
+
+// hintLocked avisa quantas linhas faltam ou encerra a paginação. Exige
+// p.mutex.
+func (p *Pager) hintLocked() {
+	if remaining := len(p.view) - p.end; remaining > 0 {
+		fmt.Fprintf(p.out, "-- %d linhas restantes: /more ou PageDown --\n", remaining)
+		return
+	}
+	p.view = nil
+}
+
+// writeLinesLocked escreve linhas direto no terminal, sem passar pela
+// memória de rolagem. Exige p.mutex.
+func (p *Pager) writeLinesLocked(lines []string) {
+	for _, line := range lines {
+		fmt.Fprintln(p.out, line)
+	}
+}
+
+// pageKeyReader intercepta PageUp e PageDown na entrada do terminal, que o
+// editor de linha não distingue
+type pageKeyReader struct {
+	reader io.Reader
+	pager  *Pager
+}
+
+// Read repassa a entrada sem as sequências de PageUp e PageDown
+func (r *pageKeyReader) Read(data []byte) (int, error) {
+	for {
+		n, err := r.reader.Read(data)
+		if n == 0 {
+			return n, err
+		}
+
+		input := data[:n]
+		for _, key := range []struct {
+			sequence []byte
+			action   func()
+		}{{pageUpKey, r.pager.PageUp}, {pageDownKey, r.pager.PageDown}} {
+			for bytes.Contains(input, key.sequence) {
+				input = bytes.Replace(input, key.sequence, nil, 1)
+				key.action()
+			}
+		}
+		if len(input) > 0 || err != nil {
+			return len(input), err
+		}
+	}
+}
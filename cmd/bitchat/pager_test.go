@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newTestPager cria um pager com páginas de size linhas que escreve em out
+func newTestPager(out *bytes.Buffer, size int) *Pager {
+	return NewPager(out, func() int { return size })
+}
+
+// linesPage cria uma página com as linhas "linha 1" a "linha n"
+func linesPage(n int) *Page {
+	var page Page
+	for i := 1; i <= n; i++ {
+		page.Printf("linha %d\n", i)
+	}
+	return &page
+}
+
+// expectOutput compara a saída do pager com as linhas esperadas e a descarta
+func expectOutput(t *testing.T, out *bytes.Buffer, expected ...string) {
+	t.Helper()
+
+	got := out.String()
+	out.Reset()
+	want := ""
+	if len(expected) > 0 {
+		want = strings.Join(expected, "\n") + "\n"
+	}
+	if got != want {
+		t.Errorf("Saída esperada %q, obtida %q", want, got)
+	}
+}
+
+func TestPager(t *testing.T) {
+	t.Run("Página vazia", func(t *testing.T) {
+		var out bytes.Buffer
+		pager := newTestPager(&out, 3)
+
+		pager.Show(&Page{})
+		expectOutput(t, &out)
+		pager.More()
+		expectOutput(t, &out, "Nada mais a exibir")
+	})
+
+	t.Run("Exatamente uma página", func(t *testing.T) {
+		var out bytes.Buffer
+		pager := newTestPager(&out, 3)
+
+		pager.Show(linesPage(3))
+		expectOutput(t, &out, "linha 1", "linha 2", "linha 3")
+		pager.More()
+		expectOutput(t, &out, "Nada mais a exibir")
+	})
+
+	t.Run("Última página parcial", func(t *testing.T) {
+		var out bytes.Buffer
+		pager := newTestPager(&out, 3)
+
+		pager.Show(linesPage(7))
+		expectOutput(t, &out, "linha 1", "linha 2", "linha 3", "-- 4 linhas restantes: /more ou PageDown --")
+		pager.More()
+		expectOutput(t, &out, "linha 4", "linha 5", "linha 6", "-- 1 linhas restantes: /more ou PageDown --")
+		pager.PageDown()
+		expectOutput(t, &out, "linha 7")
+		pager.More()
+		expectOutput(t, &out, "Nada mais a exibir")
+	})
+
+	t.Run("Voltar até o início", func(t *testing.T) {
+		var out bytes.Buffer
+		pager := newTestPager(&out, 3)
+
+		pager.Show(linesPage(8))
+		pager.More()
+		out.Reset()
+		pager.PageUp()
+		expectOutput(t, &out, "linha 1", "linha 2", "linha 3", "-- 5 linhas restantes: /more ou PageDown --")
+		pager.PageUp()
+		expectOutput(t, &out, "-- início --")
+	})
+
+	t.Run("Memória de rolagem", func(t *testing.T) {
+		var out bytes.Buffer
+		pager := newTestPager(&out, 3)
+
+		// Saída que cabe na tela não é paginada
+		fmt.Fprint(pager, "linha 1\nlinha 2\nlinha 3\nlinha ")
+		pager.PageUp()
+		expectOutput(t, &out)
+
+		// A linha incompleta só entra na memória com a quebra
+		fmt.Fprint(pager, "4\nlinha 5\n")
+		pager.PageUp()
+		expectOutput(t, &out, "linha 1", "linha 2", "-- 3 linhas restantes: /more ou PageDown --")
+	})
+
+	t.Run("Memória de rolagem limitada", func(t *testing.T) {
+		var out bytes.Buffer
+		pager := newTestPager(&out, ScrollbackLines)
+
+		for i := 1; i <= ScrollbackLines+2; i++ {
+			fmt.Fprintf(pager, "linha %d\n", i)
+		}
+		if len(pager.scrollback) != ScrollbackLines || pager.scrollback[0] != "linha 3" {
+			t.Errorf("Esperadas %d linhas a partir de linha 3, obtidas %d a partir de %q",
+				ScrollbackLines, len(pager.scrollback), pager.scrollback[0])
+		}
+	})
+}
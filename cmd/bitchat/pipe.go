@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// PipeWriter escreve as mensagens recebidas no modo --pipe como linhas
+// simples: "remetente: texto" para o canal configurado e "@remetente: texto"
+// para mensagens privadas. Um PipeWriter nil ignora as mensagens.
+type PipeWriter struct {
+	channel string
+	out     io.Writer
+	mutex   sync.Mutex
+}
+
+// enablePipeMode reserva a saída padrão para as mensagens do canal; o texto
+// destinado ao usuário passa a ir para a saída de erro
+func enablePipeMode(appState *AppState, channel string) {
+	appState.Pipe = &PipeWriter{channel: channel, out: os.Stdout}
+	os.Stdout = os.Stderr
+}
+
+// WriteMessage escreve uma mensagem recebida, se ela for do canal
+// configurado ou privada
+func (pw *PipeWriter) WriteMessage(message *protocol.BitchatMessage) {
+	if pw == nil {
+		return
+	}
+
+	sender := message.Sender
+	switch {
+	case message.IsPrivate:
+		sender = "@" + sender
+	case message.Channel != pw.channel:
+		return
+	}
+
+	// Quebras de linha dividiriam a mensagem em várias linhas da saída
+	content := strings.ReplaceAll(message.Content, "\n", " ")
+
+	pw.mutex.Lock()
+	defer pw.mutex.Unlock()
+	fmt.Fprintf(pw.out, "%s: %s\n", sender, content)
+}
+
+// pipeInputLoop envia ao canal configurado cada linha da entrada padrão.
+// Linhas vazias são ignoradas.
+func pipeInputLoop(appState *AppState) {
+	scanner := bufio.NewScanner(os.Stdin)
+
+	for appState.Running && scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if _, err := sendChannelMessage(appState, appState.Pipe.channel, line); err != nil {
+			fmt.Println("Erro ao enviar mensagem:", err)
+		}
+	}
+}
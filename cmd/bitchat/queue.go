@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// queueCommand processa /queue: lista as mensagens compostas que ainda não
+// foram transmitidas, inclusive as guardadas de execuções anteriores
+func queueCommand(appState *AppState) {
+	messages := appState.MeshService.OutgoingMessages()
+	if len(messages) == 0 {
+		fmt.Println("Nenhuma mensagem aguardando transmissão")
+		return
+	}
+
+	fmt.Println("Mensagens aguardando transmissão:")
+	for _, outgoing := range messages {
+		message := outgoing.Message
+		target := message.Channel
+		switch {
+		case message.IsPrivate:
+			target = "@" + message.RecipientNickname
+		case target == "":
+			target = "broadcast"
+		}
+
+		state := "na fila"
+		switch {
+		case outgoing.Held:
+			state = "aguardando peer ao alcance"
+		case message.IsPrivate:
+			state = "aguardando confirmação"
+		}
+		fmt.Printf("  há %v  %s: %s (%s)\n", time.Since(outgoing.QueuedAt).Round(time.Second), target, message.Content, state)
+	}
+}
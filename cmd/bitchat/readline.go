@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Prompt é exibido na linha de entrada do terminal interativo
+const Prompt = "> "
+
+// commandNames são os comandos oferecidos pela completação com Tab
+var commandNames = []string{
+	"/j", "/join", "/switch", "/leave", "/topic", "/m", "/msg", "/urgent", "/dm", "/send", "/accept", "/reject", "/w", "/who", "/whois", "/export", "/history", "/more", "/trace",
+	"/bench", "/channels", "/mute", "/unmute", "/favorite", "/unfavorite", "/block", "/unblock", "/bond", "/clear", "/nick", "/notify", "/receipts", "/typing", "/away", "/back", "/schedule", "/queue", "/unschedule", "/theme", "/battery", "/stats", "/health",
+	"/cover", "/relay", "/topology", "/help", "/quit", "/exit",
+}
+
+// LineReader lê a entrada do usuário com edição de linha, histórico de
+// comandos (setas para cima e para baixo) e completação com Tab de comandos,
+// canais e @nicknames. A saída do programa passa pelo terminal, para que
+// mensagens recebidas durante a digitação não corrompam a linha em edição.
+type LineReader struct {
+	terminal *term.Terminal
+	state    *term.State
+	stdout   *os.File
+	output   *os.File      // Ponta de escrita do pipe que substitui os.Stdout
+	copied   chan struct{} // Fechado quando toda a saída chegou ao terminal
+}
+
+// NewLineReader coloca o terminal em modo bruto e prepara a leitura de
+// linhas. Retorna nil se a entrada padrão não for um terminal, como quando
+// o Bitchat é usado por scripts.
+func NewLineReader(appState *AppState) *LineReader {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		fmt.Println("Erro ao configurar terminal:", err)
+		return nil
+	}
+
+	lr := &LineReader{
+		state:  state,
+		stdout: os.Stdout,
+	}
+	pager := NewPager(nil, terminalPageSize(int(os.Stdout.Fd())))
+	lr.terminal = term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{&pageKeyReader{reader: os.Stdin, pager: pager}, os.Stdout}, Prompt)
+	pager.out = lr.terminal
+	appState.Pager = pager
+	lr.terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			noteTyping(appState, line, key)
+			return "", 0, false
+		}
+		return lr.complete(appState, line, pos)
+	}
+
+	// Redirecionar a saída do programa para o terminal, guardando-a na
+	// memória de rolagem do pager
+	if reader, writer, err := os.Pipe(); err == nil {
+		lr.output = writer
+		lr.copied = make(chan struct{})
+		os.Stdout = writer
+		go func() {
+			defer close(lr.copied)
+			io.Copy(io.MultiWriter(lr.terminal, pager), reader)
+			reader.Close()
+		}()
+	}
+
+	return lr
+}
+
+// ReadLine lê uma linha. Ctrl+C ou Ctrl+D em uma linha vazia retornam io.EOF.
+func (lr *LineReader) ReadLine() (string, error) {
+	return lr.terminal.ReadLine()
+}
+
+// Close restaura a saída padrão e o terminal ao modo original, depois de
+// entregar ao terminal o que ainda estava no pipe
+func (lr *LineReader) Close() {
+	os.Stdout = lr.stdout
+	if lr.output != nil {
+		lr.output.Close()
+		<-lr.copied
+	}
+	term.Restore(int(os.Stdin.Fd()), lr.state)
+}
+
+// complete completa a palavra sob o cursor. Com vários candidatos, avança
+// até o prefixo comum e, sem progresso, lista as opções.
+func (lr *LineReader) complete(appState *AppState, line string, pos int) (string, int, bool) {
+	prefix := line[:pos]
+	start := strings.LastIndex(prefix, " ") + 1
+	word := prefix[start:]
+	if word == "" {
+		return "", 0, false
+	}
+
+	var candidates []string
+	for _, candidate := range completionCandidates(appState, word, start == 0) {
+		if strings.HasPrefix(candidate, word) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+
+	completed := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		completed += " "
+	} else if completed == word {
+		fmt.Fprintf(lr.terminal, "%s\n", strings.Join(candidates, "  "))
+		return "", 0, false
+	}
+
+	newLine := prefix[:start] + completed + line[pos:]
+	return newLine, start + len(completed), true
+}
+
+// completionCandidates retorna as palavras que podem completar word:
+// comandos no início da linha, canais e @nicknames dos peers ativos. Roda
+// na goroutine do terminal, por isso copia canais e peers sob o mutex.
+func completionCandidates(appState *AppState, word string, first bool) []string {
+	var candidates []string
+	switch {
+	case first && strings.HasPrefix(word, "/"):
+		candidates = append(candidates, commandNames...)
+		candidates = append(candidates, aliasNames(appState)...)
+	case strings.HasPrefix(word, "#"):
+		appState.mutex.RLock()
+		channels := make(map[string]bool)
+		if appState.CurrentChannel != "" {
+			channels[appState.CurrentChannel] = true
+		}
+		for channel := range appState.MessageHistory {
+			channels[channel] = true
+		}
+		for channel := range appState.JoinedChannels {
+			channels[channel] = true
+		}
+		appState.mutex.RUnlock()
+		for channel := range channels {
+			candidates = append(candidates, channel)
+		}
+	case strings.HasPrefix(word, "@"):
+		for _, name := range appState.activePeers() {
+			candidates = append(candidates, "@"+name)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// commonPrefix retorna o maior prefixo comum a todas as palavras
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markConversationRead marca como lidas as mensagens recebidas na conversa
+// privada com um peer e confirma a leitura ao remetente. Com o peer fora de
+// alcance, as mensagens continuam não lidas até a próxima exibição, para que
+// a confirmação não se perca.
+func markConversationRead(appState *AppState, nickname string) {
+	peerID := findPeerByName(appState, nickname)
+	if peerID == "" && appState.MeshService.ReadReceipts() {
+		return
+	}
+
+	var messageIDs []string
+	for _, message := range appState.Store.MarkPrivateRead(conversationKey(nickname)) {
+		messageIDs = append(messageIDs, message.ID)
+	}
+	if peerID != "" && len(messageIDs) > 0 {
+		appState.MeshService.SendReadReceipts(peerID, messageIDs)
+	}
+}
+
+// receiptsCommand processa /receipts [on|off]
+func receiptsCommand(appState *AppState, args string) {
+	switch strings.TrimSpace(args) {
+	case "":
+		if appState.MeshService.ReadReceipts() {
+			fmt.Println("Confirmações de leitura: ativadas")
+		} else {
+			fmt.Println("Confirmações de leitura: desativadas")
+		}
+		return
+	case "on":
+		appState.Settings.NoReadReceipts = false
+	case "off":
+		appState.Settings.NoReadReceipts = true
+	default:
+		fmt.Println("Uso: /receipts [on|off]")
+		return
+	}
+
+	appState.MeshService.SetReadReceipts(!appState.Settings.NoReadReceipts)
+	if err := appState.Settings.Save(); err != nil {
+		fmt.Println("Erro ao salvar preferências:", err)
+	}
+	if appState.Settings.NoReadReceipts {
+		fmt.Println("Confirmações de leitura desativadas: os remetentes não saberão quando você leu suas mensagens")
+	} else {
+		fmt.Println("Confirmações de leitura ativadas")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/service"
+)
+
+// ScheduleFile é o arquivo do diretório de dados com as mensagens agendadas
+const ScheduleFile = "scheduled.json"
+
+// startScheduler carrega as mensagens agendadas e passa a enviá-las no
+// horário marcado
+func startScheduler(appState *AppState) {
+	scheduler, err := service.NewScheduler(filepath.Join(appState.Config.DataDir, ScheduleFile),
+		func(message *service.ScheduledMessage) error {
+			return sendScheduledMessage(appState, message)
+		},
+		func(recipient string) bool {
+			return findPeerByName(appState, recipient) != ""
+		})
+	if err != nil {
+		fmt.Println("Aviso:", err)
+	}
+	appState.Scheduler = scheduler
+	scheduler.Start()
+}
+
+// sendScheduledMessage envia uma mensagem agendada cujo horário chegou
+func sendScheduledMessage(appState *AppState, message *service.ScheduledMessage) error {
+	if !message.Private() {
+		if _, err := sendChannelMessage(appState, message.Channel, message.Content); err != nil {
+			return err
+		}
+		fmt.Printf("Mensagem agendada enviada a %s: %s\n", message.Channel, message.Content)
+		return nil
+	}
+
+	peerID := findPeerByName(appState, message.Recipient)
+	if peerID == "" {
+		return fmt.Errorf("usuário %s não encontrado", message.Recipient)
+	}
+	if _, err := sendPrivateMessage(appState, peerID, message.Recipient, message.Content, false); err != nil {
+		return err
+	}
+	fmt.Printf("Mensagem agendada enviada a @%s: %s\n", message.Recipient, message.Content)
+	return nil
+}
+
+// parseScheduleTime interpreta o horário de /schedule: HH:MM (hoje ou, se já
+// passou, amanhã), uma espera como 30m ou +2h, ou online para enviar quando
+// o destinatário estiver ao alcance (horário zero)
+func parseScheduleTime(value string, now time.Time) (time.Time, error) {
+	if value == "online" {
+		return time.Time{}, nil
+	}
+	if clock, err := time.ParseInLocation("15:04", value, now.Location()); err == nil {
+		at := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		return at, nil
+	}
+	delay, err := time.ParseDuration(strings.TrimPrefix(value, "+"))
+	if err != nil || delay <= 0 {
+		return time.Time{}, fmt.Errorf("horário inválido %q: use HH:MM, uma espera como 30m ou online", value)
+	}
+	return now.Add(delay), nil
+}
+
+// formatScheduleTime formata o horário de uma mensagem agendada para a listagem
+func formatScheduleTime(at time.Time, now time.Time) string {
+	switch {
+	case at.IsZero():
+		return "quando online"
+	case at.YearDay() == now.YearDay() && at.Year() == now.Year():
+		return at.Format("15:04")
+	}
+	return at.Format("02/01 15:04")
+}
+
+// scheduleCommand processa /schedule [quando #canal|@nome texto]; sem
+// argumentos lista as mensagens agendadas
+func scheduleCommand(appState *AppState, args string) {
+	const usage = "Uso: /schedule HH:MM|30m|online #canal|@nome texto"
+	now := time.Now()
+
+	fields := strings.SplitN(strings.TrimSpace(args), " ", 3)
+	if fields[0] == "" {
+		messages := appState.Scheduler.List()
+		if len(messages) == 0 {
+			fmt.Println("Nenhuma mensagem agendada")
+			return
+		}
+		fmt.Println("Mensagens agendadas:")
+		for _, message := range messages {
+			target := message.Channel
+			if message.Private() {
+				target = "@" + message.Recipient
+			}
+			fmt.Printf("  %s  %s %s: %s\n", message.ID, formatScheduleTime(message.SendAt, now), target, message.Content)
+		}
+		return
+	}
+	if len(fields) < 3 || strings.TrimSpace(fields[2]) == "" {
+		fmt.Println(usage)
+		return
+	}
+
+	sendAt, err := parseScheduleTime(fields[0], now)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	message := &service.ScheduledMessage{Content: strings.TrimSpace(fields[2]), SendAt: sendAt}
+	switch target := fields[1]; {
+	case strings.HasPrefix(target, "#"):
+		if sendAt.IsZero() {
+			fmt.Println("online só vale para mensagens privadas")
+			return
+		}
+		message.Channel = target
+	case strings.HasPrefix(target, "@"):
+		message.Recipient = target[1:]
+	default:
+		fmt.Println(usage)
+		return
+	}
+
+	if err := appState.Scheduler.Schedule(message); err != nil {
+		fmt.Println("Erro ao agendar mensagem:", err)
+		return
+	}
+	fmt.Printf("Mensagem %s agendada para %s (/unschedule %s para cancelar)\n", message.ID, formatScheduleTime(sendAt, now), message.ID)
+}
+
+// unscheduleCommand processa /unschedule id
+func unscheduleCommand(appState *AppState, args string) {
+	id := strings.TrimSpace(args)
+	if id == "" {
+		fmt.Println("Uso: /unschedule id (veja /schedule)")
+		return
+	}
+	if !appState.Scheduler.Cancel(id) {
+		fmt.Printf("Mensagem agendada %s não encontrada\n", id)
+		return
+	}
+	fmt.Printf("Mensagem agendada %s cancelada\n", id)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+const (
+	// SendPeerWait é a espera por vizinhos antes de enviar a um canal
+	SendPeerWait = 10 * time.Second
+
+	// SendFlushTimeout limita a espera pelo esvaziamento da fila de saída
+	SendFlushTimeout = 10 * time.Second
+)
+
+// Códigos de saída do subcomando send
+const (
+	SendExitOK          = 0 // Entregue (privada) ou transmitida (canal)
+	SendExitFailed      = 1 // Destinatário não encontrado ou erro ao enviar
+	SendExitUsage       = 2
+	SendExitUnconfirmed = 3 // Transmitida sem confirmação de entrega
+)
+
+// deliveryUpdate é uma mudança de status aguardada pelo subcomando send
+type deliveryUpdate struct {
+	MessageID string
+	Status    protocol.DeliveryStatus
+}
+
+// runSendCommand executa o subcomando send: envia uma mensagem a um canal
+// ou peer, aguarda a entrega e retorna o código de saída do processo
+//
+//	bitchat send --channel "#ops" "texto"
+//	bitchat send --to @peer [--timeout 30s] "texto"
+func runSendCommand(appState *AppState, args []string) int {
+	flags := flag.NewFlagSet("send", flag.ContinueOnError)
+	channel := flags.String("channel", "", "Canal de destino (ex.: #ops)")
+	to := flags.String("to", "", "Peer de destino (ex.: @alice)")
+	timeout := flags.Duration("timeout", 30*time.Second, "Espera pelo peer e pela confirmação de entrega")
+	if err := flags.Parse(args); err != nil {
+		return SendExitUsage
+	}
+
+	content := strings.Join(flags.Args(), " ")
+	if content == "" || (*channel == "") == (*to == "") || (*channel != "" && !strings.HasPrefix(*channel, "#")) {
+		fmt.Println(`Uso: bitchat send --channel "#canal" "texto" | --to @usuario [--timeout 30s] "texto"`)
+		return SendExitUsage
+	}
+
+	if *channel != "" {
+		return sendToChannel(appState, *channel, content)
+	}
+	return sendToPeer(appState, strings.TrimPrefix(*to, "@"), content, *timeout)
+}
+
+// sendToChannel transmite uma mensagem a um canal assim que houver vizinhos
+func sendToChannel(appState *AppState, channel string, content string) int {
+	// Sem vizinhos a mensagem fica apenas no cache local, de onde é
+	// reenviada aos peers que aparecerem enquanto o processo estiver ativo
+	deadline := time.Now().Add(SendPeerWait)
+	for len(appState.activePeers()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	if _, err := sendChannelMessage(appState, channel, content); err != nil {
+		fmt.Println("Erro ao enviar mensagem:", err)
+		return SendExitFailed
+	}
+	if !waitOutgoingFlush(appState, SendFlushTimeout) {
+		fmt.Println("Mensagem enfileirada, mas não transmitida a tempo")
+		return SendExitUnconfirmed
+	}
+	fmt.Printf("Mensagem transmitida em %s para %d peers\n", channel, len(appState.activePeers()))
+	return SendExitOK
+}
+
+// sendToPeer envia uma mensagem privada e aguarda a confirmação de entrega
+func sendToPeer(appState *AppState, username string, content string, timeout time.Duration) int {
+	deadline := time.Now().Add(timeout)
+	peerID := findPeerByName(appState, username)
+	for peerID == "" {
+		if time.Now().After(deadline) {
+			fmt.Printf("Usuário %s não encontrado\n", username)
+			return SendExitFailed
+		}
+		time.Sleep(500 * time.Millisecond)
+		peerID = findPeerByName(appState, username)
+	}
+
+	deliveries := make(chan deliveryUpdate, 8)
+	appState.Deliveries = deliveries
+	messageID, err := sendPrivateMessage(appState, peerID, username, content, false)
+	if err != nil {
+		fmt.Println("Erro ao enviar mensagem privada:", err)
+		return SendExitFailed
+	}
+
+	expire := time.After(time.Until(deadline))
+	for {
+		select {
+		case update := <-deliveries:
+			if update.MessageID != messageID {
+				continue
+			}
+			switch update.Status {
+			case protocol.DeliveryStatusDelivered, protocol.DeliveryStatusRead:
+				fmt.Printf("Mensagem entregue a %s\n", username)
+				return SendExitOK
+			case protocol.DeliveryStatusFailed:
+				fmt.Printf("Falha na entrega a %s\n", username)
+				return SendExitFailed
+			}
+		case <-expire:
+			fmt.Printf("Mensagem enviada a %s, sem confirmação de entrega\n", username)
+			return SendExitUnconfirmed
+		}
+	}
+}
+
+// waitOutgoingFlush aguarda a fila de saída esvaziar
+func waitOutgoingFlush(appState *AppState, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if _, outgoing := appState.MeshService.QueueDepth(); outgoing == 0 {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
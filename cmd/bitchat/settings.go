@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SettingsFile é o arquivo, no diretório de dados, com as preferências
+// alteradas por comandos durante a execução
+const SettingsFile = "settings.json"
+
+// Settings são as preferências do usuário que sobrevivem entre execuções
+type Settings struct {
+	Alerts         map[string]AlertMode `json:"alerts,omitempty"`           // Escopo (#canal, @nome ou default) -> modo
+	Channels       []string             `json:"channels,omitempty"`         // Canais em que o usuário entrou
+	Contacts       map[string]string    `json:"contacts,omitempty"`         // Apelido -> impressão digital da identidade
+	Theme          string               `json:"theme,omitempty"`            // Tema de cores (none: sem cores)
+	Muted          map[string]bool      `json:"muted,omitempty"`            // Escopos (#canal ou @nome) silenciados
+	Blocked        []string             `json:"blocked,omitempty"`          // Impressões digitais das identidades bloqueadas
+	Favorites      []string             `json:"favorites,omitempty"`        // Impressões digitais das identidades favoritas
+	NoReadReceipts bool                 `json:"no_read_receipts,omitempty"` // Não confirmar a leitura de mensagens privadas
+	NoTyping       bool                 `json:"no_typing,omitempty"`        // Não avisar quando o usuário está digitando
+	AwayMessage    string               `json:"away_message,omitempty"`     // Resposta automática durante a ausência
+
+	path string
+}
+
+// LoadSettings carrega as preferências do diretório de dados. Um arquivo
+// inexistente resulta em preferências vazias.
+func LoadSettings(dataDir string) (*Settings, error) {
+	settings := &Settings{
+		Alerts:   make(map[string]AlertMode),
+		Contacts: make(map[string]string),
+		Muted:    make(map[string]bool),
+		path:     filepath.Join(dataDir, SettingsFile),
+	}
+
+	data, err := os.ReadFile(settings.path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, fmt.Errorf("erro ao ler preferências: %v", err)
+	}
+	if err := json.Unmarshal(data, settings); err != nil {
+		return settings, fmt.Errorf("erro ao decodificar preferências: %v", err)
+	}
+	if settings.Alerts == nil {
+		settings.Alerts = make(map[string]AlertMode)
+	}
+	if settings.Contacts == nil {
+		settings.Contacts = make(map[string]string)
+	}
+	if settings.Muted == nil {
+		settings.Muted = make(map[string]bool)
+	}
+	return settings, nil
+}
+
+// Save grava as preferências no diretório de dados
+func (s *Settings) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao codificar preferências: %v", err)
+	}
+
+	// Gravar em um arquivo temporário para não corromper as preferências
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("erro ao gravar preferências: %v", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("erro ao gravar preferências: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// ShutdownTimeout limita a espera pelo envio dos pacotes na fila ao encerrar
+const ShutdownTimeout = 3 * time.Second
+
+// requestShutdown pede ao laço principal que encerre o Bitchat, como um
+// SIGINT recebido
+func requestShutdown(appState *AppState) {
+	select {
+	case appState.Signals <- os.Interrupt:
+	default:
+	}
+}
+
+// shutdown encerra o Bitchat em ordem: restaura o terminal, anuncia a saída
+// à rede, aguarda o envio dos pacotes na fila, para os provedores e grava o
+// armazenamento em disco
+func shutdown(appState *AppState) {
+	appState.Running = false
+	if appState.LineReader != nil {
+		appState.LineReader.Close()
+	}
+	fmt.Println("\nEncerrando...")
+
+	if appState.Scheduler != nil {
+		appState.Scheduler.Stop()
+	}
+	appState.MeshService.Shutdown(ShutdownTimeout)
+	if appState.Store != nil {
+		appState.Store.Close()
+	}
+
+	fmt.Println("Bitchat encerrado")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/bluetooth"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+)
+
+// batteryModeName retorna o nome de um modo de bateria, como aceito por /battery
+func batteryModeName(mode int) string {
+	switch mode {
+	case bluetooth.BatteryModeLow:
+		return "low"
+	case bluetooth.BatteryModeUltraLow:
+		return "ultralow"
+	}
+	return "normal"
+}
+
+// statsCommand processa /stats: exibe as estatísticas da rede mesh, das
+// filas e do armazenamento para diagnosticar falhas de entrega
+func statsCommand(appState *AppState) {
+	writeStats(os.Stdout, appState)
+}
+
+// writeStats escreve as estatísticas exibidas por /stats, também incluídas
+// no pacote de diagnóstico
+func writeStats(w io.Writer, appState *AppState) {
+	meshService := appState.MeshService
+	stats := meshService.GetMeshStats()
+	queues := meshService.QueueStats()
+	relayed, _, denied := meshService.GetRelayStats()
+	scheduled, suppressed := meshService.GetSuppressionStats()
+	storeStats := appState.Store.Stats()
+
+	fmt.Fprintf(w, "Ativo há %v\n", stats.Uptime.Round(time.Second))
+	fmt.Fprintf(w, "Peers ativos: %d\n", len(appState.activePeers()))
+
+	battery := batteryModeName(meshService.GetBatteryMode())
+	battery += powerStateSuffix(meshService.GetPowerState())
+	fmt.Fprintf(w, "Bateria: %s\n", battery)
+
+	fmt.Fprintln(w, "Tráfego:")
+	fmt.Fprintf(w, "  Enviados: %d pacotes (%s)\n", stats.PacketsSent, formatBytes(int64(stats.BytesSent)))
+	fmt.Fprintf(w, "  Recebidos: %d pacotes (%s)\n", stats.PacketsReceived, formatBytes(int64(stats.BytesReceived)))
+	fmt.Fprintf(w, "  Retransmitidos: %d pacotes (%s)\n", relayed, formatBytes(int64(stats.BytesRelayed)))
+	fmt.Fprintf(w, "  Duplicados descartados: %d, falhas de remontagem: %d\n", stats.DedupHits, stats.FragmentFailures)
+	fmt.Fprintf(w, "  Relays suprimidos: %d de %d agendados\n", suppressed, scheduled)
+	fmt.Fprintf(w, "  Sincronizações no retorno de peers e canais: %d\n", meshService.ReconnectSyncs())
+	var refused uint64
+	for _, count := range denied {
+		refused += count
+	}
+	fmt.Fprintf(w, "  Relays recusados pela política: %d\n", refused)
+	if limited := meshService.RateLimitedMessages(); limited > 0 {
+		fmt.Fprintf(w, "  Mensagens recusadas pelo limite de envio: %d\n", limited)
+	}
+	if compressed, saved := meshService.GetCompressionStats(); compressed > 0 {
+		fmt.Fprintf(w, "  Mensagens comprimidas: %d (%s economizados)\n", compressed, formatBytes(int64(saved)))
+	}
+
+	fmt.Fprintln(w, "Filas:")
+	fmt.Fprintf(w, "  Entrada: %s\n", formatQueueStats(queues[bluetooth.QueueIncoming]))
+	fmt.Fprintf(w, "  Saída: %s\n", formatQueueStats(queues[bluetooth.QueueOutgoing]))
+	fmt.Fprintf(w, "  Mensagens guardadas para peers ausentes: %d\n", meshService.CachedMessages())
+	fmt.Fprintf(w, "  Mensagens privadas aguardando confirmação: %d\n", meshService.PendingRetries())
+	fmt.Fprintf(w, "  Mensagens de canal aguardando confirmação: %d\n", meshService.PendingChannelDeliveries())
+	fmt.Fprintf(w, "  Mensagens aguardando reenvio: %d\n", storeStats.PendingMessages)
+	fmt.Fprintf(w, "  Mensagens aguardando transmissão: %d (/queue)\n", len(meshService.OutgoingMessages()))
+
+	fmt.Fprintln(w, "Armazenamento:")
+	fmt.Fprintf(w, "  Canais: %d (%d mensagens)\n", storeStats.Channels, storeStats.ChannelMessages)
+	fmt.Fprintf(w, "  Conversas privadas: %d (%d mensagens)\n", storeStats.Conversations, storeStats.PrivateMessages)
+}
+
+// formatQueueStats descreve a ocupação e os descartes de uma fila interna
+func formatQueueStats(stats mesh.QueueStats) string {
+	return fmt.Sprintf("%d aguardando (máximo %d de %d), %d descartados (%s)",
+		stats.Length, stats.HighWater, stats.Capacity, stats.Dropped, stats.Policy)
+}
+
+// writeMetrics escreve as estatísticas da rede mesh e das filas internas no
+// formato do Prometheus
+func writeMetrics(w io.Writer, appState *AppState) {
+	appState.MeshService.GetMeshStats().WritePrometheus(w)
+	mesh.WriteQueuesPrometheus(w, appState.MeshService.QueueStats())
+}
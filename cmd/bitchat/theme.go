@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Códigos ANSI usados pelos temas
+const (
+	ansiReset = "\033[0m"
+	ansiBold  = "\033[1m"
+	ansiDim   = "\033[2m"
+)
+
+// DefaultTheme é o tema usado quando nenhum foi escolhido
+const DefaultTheme = "default"
+
+// Theme define as cores da saída do chat. Um Theme nil não colore nada, de
+// modo que a formatação não precisa testar se as cores estão ativas.
+type Theme struct {
+	Timestamp string   // Horários
+	Channel   string   // Nomes de canal
+	Self      string   // Mensagens do próprio usuário
+	Mention   string   // Menções ao usuário
+	Senders   []string // Paleta dos remetentes, escolhida pelo apelido
+}
+
+// Themes são os temas embutidos
+var Themes = map[string]*Theme{
+	"default": {
+		Timestamp: ansiDim,
+		Channel:   "\033[1;36m",
+		Self:      "\033[1;37m",
+		Mention:   "\033[1;33m",
+		Senders:   []string{"\033[31m", "\033[32m", "\033[33m", "\033[34m", "\033[35m", "\033[36m"},
+	},
+	"pastel": {
+		Timestamp: "\033[38;5;244m",
+		Channel:   "\033[38;5;117m",
+		Self:      "\033[38;5;255m",
+		Mention:   "\033[1;38;5;222m",
+		Senders:   []string{"\033[38;5;210m", "\033[38;5;150m", "\033[38;5;180m", "\033[38;5;147m", "\033[38;5;218m", "\033[38;5;116m"},
+	},
+	"mono": {
+		Timestamp: ansiDim,
+		Channel:   ansiBold,
+		Self:      ansiBold,
+		Mention:   "\033[1;7m",
+	},
+}
+
+// themeNames retorna os nomes dos temas embutidos, em ordem
+func themeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// disableColorIfUnsupported desativa as cores no modo --json, fora de um
+// terminal ou com a variável NO_COLOR. Deve ser chamada antes de a saída ser
+// redirecionada pelo LineReader.
+func disableColorIfUnsupported(config *Config) {
+	if config.JSON || os.Getenv("NO_COLOR") != "" || !term.IsTerminal(int(os.Stdout.Fd())) {
+		config.NoColor = true
+	}
+}
+
+// selectTheme escolhe o tema da saída; com --no-color ou o tema "none" a
+// saída não é colorida. Um tema desconhecido, como o de preferências de uma
+// versão anterior, dá lugar ao tema padrão.
+func selectTheme(config *Config, settings *Settings) *Theme {
+	if config.NoColor || settings.Theme == "none" {
+		return nil
+	}
+	if theme, ok := Themes[settings.Theme]; ok {
+		return theme
+	}
+	return Themes[DefaultTheme]
+}
+
+// paint aplica um código de cor ao texto
+func (t *Theme) paint(code string, text string) string {
+	if t == nil || code == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// Time formata um horário
+func (t *Theme) Time(text string) string {
+	if t == nil {
+		return text
+	}
+	return t.paint(t.Timestamp, text)
+}
+
+// ChannelName formata o nome de um canal
+func (t *Theme) ChannelName(text string) string {
+	if t == nil {
+		return text
+	}
+	return t.paint(t.Channel, text)
+}
+
+// Sender formata o apelido de um remetente, sempre com a mesma cor
+func (t *Theme) Sender(name string, self bool) string {
+	if t == nil {
+		return name
+	}
+	if self {
+		return t.paint(t.Self, name)
+	}
+	if len(t.Senders) == 0 {
+		return t.paint(ansiBold, name)
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	return t.paint(t.Senders[hash.Sum32()%uint32(len(t.Senders))], name)
+}
+
+// Content destaca no conteúdo as menções ao usuário
+func (t *Theme) Content(content string, nickname string) string {
+	if t == nil || t.Mention == "" {
+		return content
+	}
+	return strings.ReplaceAll(content, "@"+nickname, t.paint(t.Mention, "@"+nickname))
+}
+
+// themeCommand processa /theme [nome]
+func themeCommand(appState *AppState, args string) {
+	name := strings.TrimSpace(args)
+	if name == "" {
+		current := appState.Settings.Theme
+		if current == "" {
+			current = DefaultTheme
+		}
+		fmt.Printf("Tema atual: %s (disponíveis: %s, none)\n", current, strings.Join(themeNames(), ", "))
+		return
+	}
+	if _, ok := Themes[name]; !ok && name != "none" {
+		fmt.Printf("Tema desconhecido. Use: %s ou none\n", strings.Join(themeNames(), ", "))
+		return
+	}
+
+	appState.Settings.Theme = name
+	if err := appState.Settings.Save(); err != nil {
+		fmt.Println("Erro ao salvar preferências:", err)
+	}
+	appState.Theme = selectTheme(appState.Config, appState.Settings)
+	fmt.Printf("Tema alterado para: %s\n", name)
+}
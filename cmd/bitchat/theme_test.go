@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelectTheme(t *testing.T) {
+	testCases := []struct {
+		name     string
+		noColor  bool
+		theme    string
+		expected *Theme
+	}{
+		{"Sem tema escolhido", false, "", Themes[DefaultTheme]},
+		{"Tema embutido", false, "pastel", Themes["pastel"]},
+		{"Tema none", false, "none", nil},
+		{"Tema desconhecido usa o padrão", false, "neon", Themes[DefaultTheme]},
+		{"Sem cores", true, "pastel", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			theme := selectTheme(&Config{NoColor: tc.noColor}, &Settings{Theme: tc.theme})
+			if theme != tc.expected {
+				t.Errorf("Tema inesperado para %q: %+v", tc.theme, theme)
+			}
+		})
+	}
+}
+
+func TestTheme(t *testing.T) {
+	t.Run("Tema nil não colore", func(t *testing.T) {
+		var theme *Theme
+		if theme.Time("12:00") != "12:00" || theme.ChannelName("#geral") != "#geral" ||
+			theme.Sender("ana", false) != "ana" || theme.Content("oi @ana", "ana") != "oi @ana" {
+			t.Error("Tema nil deveria devolver o texto sem alteração")
+		}
+	})
+
+	t.Run("Remetente com cor estável", func(t *testing.T) {
+		theme := Themes[DefaultTheme]
+		if theme.Sender("ana", false) != theme.Sender("ana", false) {
+			t.Error("O mesmo apelido deveria ter sempre a mesma cor")
+		}
+		if self := theme.Sender("ana", true); self != theme.Self+"ana"+ansiReset {
+			t.Errorf("Mensagens próprias deveriam usar a cor Self: %q", self)
+		}
+	})
+
+	t.Run("Tema sem paleta de remetentes", func(t *testing.T) {
+		if sender := Themes["mono"].Sender("ana", false); sender != ansiBold+"ana"+ansiReset {
+			t.Errorf("Remetente deveria ficar em negrito: %q", sender)
+		}
+	})
+
+	t.Run("Menções destacadas", func(t *testing.T) {
+		theme := Themes[DefaultTheme]
+		content := theme.Content("oi @ana e @bia", "ana")
+		if !strings.Contains(content, theme.Mention+"@ana"+ansiReset) || !strings.Contains(content, " @bia") {
+			t.Errorf("Apenas @ana deveria ser destacada: %q", content)
+		}
+	})
+}
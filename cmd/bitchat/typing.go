@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/permissionlesstech/bitchat/internal/service"
+)
+
+// currentConversation retorna a conversa em foco como o serviço mesh a
+// identifica: "@" seguido do apelido na conversa privada, ou o canal atual
+func currentConversation(appState *AppState) string {
+	if appState.CurrentDM != "" {
+		return "@" + appState.CurrentDM
+	}
+	return appState.CurrentChannel
+}
+
+// noteTyping informa a digitação de uma tecla na linha de entrada. Comandos
+// não contam como digitação de mensagem.
+func noteTyping(appState *AppState, line string, key rune) {
+	if key < ' ' || strings.HasPrefix(line, "/") || (line == "" && key == '/') {
+		return
+	}
+	appState.MeshService.NotifyTyping(currentConversation(appState))
+}
+
+// OnPeerTyping é chamado quando um peer começa ou para de digitar. O aviso
+// é exibido apenas na conversa em foco.
+func (md *MeshDelegateImpl) OnPeerTyping(event service.TypingEvent) {
+	name, ok := md.AppState.activePeerName(event.PeerID)
+	if !ok {
+		return
+	}
+
+	jsonEvent := Event{Event: "typing", PeerID: jsonPeerID(event.PeerID), Name: name, Status: "stopped"}
+	if event.Active {
+		jsonEvent.Status = "active"
+	}
+	if strings.HasPrefix(event.Conversation, "@") {
+		jsonEvent.Private = true
+	} else {
+		jsonEvent.Channel = event.Conversation
+	}
+	md.AppState.Events.Emit(jsonEvent)
+
+	if event.Active && event.Conversation == currentConversation(md.AppState) {
+		fmt.Printf("%s está digitando...\n", md.AppState.Theme.Sender(name, false))
+	}
+}
+
+// typingCommand processa /typing [on|off]
+func typingCommand(appState *AppState, args string) {
+	switch strings.TrimSpace(args) {
+	case "":
+		if appState.MeshService.TypingIndicators() {
+			fmt.Println("Aviso de digitação: ativado")
+		} else {
+			fmt.Println("Aviso de digitação: desativado")
+		}
+		return
+	case "on":
+		appState.Settings.NoTyping = false
+	case "off":
+		appState.Settings.NoTyping = true
+	default:
+		fmt.Println("Uso: /typing [on|off]")
+		return
+	}
+
+	appState.MeshService.SetTypingIndicators(!appState.Settings.NoTyping)
+	if err := appState.Settings.Save(); err != nil {
+		fmt.Println("Erro ao salvar preferências:", err)
+	}
+	if appState.Settings.NoTyping {
+		fmt.Println("Aviso de digitação desativado: os outros não saberão quando você está digitando")
+	} else {
+		fmt.Println("Aviso de digitação ativado")
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/bluetooth"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+)
+
+// contactBadge marca se a identidade de um peer confere com a registrada
+// para o seu apelido, sem registrar contatos novos
+func contactBadge(appState *AppState, info bluetooth.PeerInfo) string {
+	known, ok := appState.Settings.Contacts[info.Name]
+	switch {
+	case info.Fingerprint == "" || !ok:
+		return ""
+	case known == info.Fingerprint:
+		return " ✓"
+	}
+	return " ⚠ identidade diferente"
+}
+
+// sortPeers ordena os peers pelo sinal, do mais forte ao mais fraco, e em
+// seguida pelo contato mais recente. Peers sem RSSI medido vêm por último.
+func sortPeers(peers []bluetooth.PeerInfo) {
+	sort.Slice(peers, func(i, j int) bool {
+		a, b := peers[i], peers[j]
+		if (a.RSSI == 0) != (b.RSSI == 0) {
+			return a.RSSI != 0
+		}
+		if a.RSSI != b.RSSI {
+			return a.RSSI > b.RSSI
+		}
+		return a.LastSeen.After(b.LastSeen)
+	})
+}
+
+// whoCommand processa /who: lista os peers ativos ordenados pelo sinal, com
+// distância, verificação e favoritos
+func whoCommand(appState *AppState) {
+	fmt.Println("Peers online:")
+	active := appState.activePeers()
+	peers := make([]bluetooth.PeerInfo, 0, len(active))
+	for id, name := range active {
+		info, ok := appState.MeshService.GetPeerInfo(id)
+		if !ok {
+			info = bluetooth.PeerInfo{ID: id, Name: name}
+		}
+		info.Name = name
+		peers = append(peers, info)
+	}
+	if len(peers) == 0 {
+		fmt.Println("  Nenhum peer encontrado")
+		return
+	}
+	sortPeers(peers)
+
+	for _, info := range peers {
+		favorite := " "
+		if info.Favorite {
+			favorite = "★"
+		}
+
+		distance := "rota desconhecida"
+		switch {
+		case info.Neighbor:
+			distance = "direto"
+		case info.Hops > 0:
+			distance = fmt.Sprintf("via relay, %s", formatHops(info.Hops))
+		}
+
+		signal := ""
+		if info.RSSI != 0 {
+			signal = fmt.Sprintf(", %d dBm", info.RSSI)
+		}
+		seen := ""
+		if !info.LastSeen.IsZero() {
+			seen = fmt.Sprintf(", visto há %v", time.Since(info.LastSeen).Round(time.Second))
+		}
+		if info.Presence != mesh.PresenceOnline {
+			seen += fmt.Sprintf(" (%s)", info.Presence)
+		}
+
+		fmt.Printf("  %s %s%s (%x) - %s%s%s\n", favorite, appState.Theme.Sender(info.Name, false),
+			contactBadge(appState, info), info.ID, distance, signal, seen)
+	}
+}
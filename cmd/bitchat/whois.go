@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/bluetooth"
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+)
+
+// Estados de verificação da identidade de um contato
+const (
+	ContactUnknown  = "chaves ainda não recebidas"
+	ContactNew      = "primeiro contato (identidade registrada)"
+	ContactVerified = "mesma identidade dos contatos anteriores"
+	ContactChanged  = "ATENÇÃO: identidade diferente da registrada para este apelido"
+)
+
+// verifyContact compara a identidade de um peer com a registrada para o seu
+// apelido, registrando-a no primeiro contato
+func verifyContact(appState *AppState, info bluetooth.PeerInfo) string {
+	if info.Fingerprint == "" {
+		return ContactUnknown
+	}
+
+	known, ok := appState.Settings.Contacts[info.Name]
+	switch {
+	case !ok:
+		appState.Settings.Contacts[info.Name] = info.Fingerprint
+		if err := appState.Settings.Save(); err != nil {
+			fmt.Println("Erro ao salvar preferências:", err)
+		}
+		return ContactNew
+	case known == info.Fingerprint:
+		return ContactVerified
+	}
+	return ContactChanged
+}
+
+// capabilityNames descreve as capacidades anunciadas no hello
+func capabilityNames(flags uint8) string {
+	capabilities := []string{"relay"}
+	if flags&protocol.HelloFlagNoRelay != 0 {
+		capabilities[0] = "sem relay"
+	}
+	if flags&protocol.HelloFlagMainsPowered != 0 {
+		capabilities = append(capabilities, "ligado à rede elétrica")
+	}
+	return strings.Join(capabilities, ", ")
+}
+
+// sharedChannels retorna os canais do usuário em que o peer foi visto
+func sharedChannels(appState *AppState, peerID string) []string {
+	appState.mutex.RLock()
+	defer appState.mutex.RUnlock()
+
+	var channels []string
+	for channel := range appState.JoinedChannels {
+		if appState.ChannelMembers[channel][peerID] {
+			channels = append(channels, channel)
+		}
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// whoisCommand processa /whois @nome
+func whoisCommand(appState *AppState, args string) {
+	args = strings.TrimSpace(args)
+	if args == "" || !strings.HasPrefix(args, "@") {
+		fmt.Println("Uso: /whois @usuario")
+		return
+	}
+
+	username := args[1:] // Remover @
+	peerID := findPeerByName(appState, username)
+	if peerID == "" {
+		fmt.Printf("Usuário %s não encontrado\n", username)
+		return
+	}
+	info, ok := appState.MeshService.GetPeerInfo(peerID)
+	if !ok {
+		fmt.Printf("Usuário %s não encontrado\n", username)
+		return
+	}
+
+	fmt.Printf("%s (%x)\n", username, peerID)
+	if info.Fingerprint != "" {
+		fmt.Printf("  Impressão digital: %s\n", info.Fingerprint)
+	}
+	fmt.Printf("  Verificação: %s\n", verifyContact(appState, info))
+
+	if info.RSSI != 0 {
+		fmt.Printf("  RSSI: %d dBm\n", info.RSSI)
+	}
+	switch {
+	case info.Neighbor:
+		fmt.Println("  Distância: vizinho direto")
+	case info.Hops > 0:
+		fmt.Printf("  Distância: %s\n", formatHops(info.Hops))
+	default:
+		fmt.Println("  Distância: desconhecida")
+	}
+	if !info.LastSeen.IsZero() {
+		fmt.Printf("  Visto por último: %s (há %v)\n",
+			info.LastSeen.Format("15:04:05"), time.Since(info.LastSeen).Round(time.Second))
+	}
+	if info.FlagsKnown {
+		fmt.Printf("  Capacidades: %s\n", capabilityNames(info.Flags))
+	}
+
+	if channels := sharedChannels(appState, peerID); len(channels) > 0 {
+		fmt.Printf("  Canais em comum: %s\n", strings.Join(channels, ", "))
+	} else {
+		fmt.Println("  Canais em comum: nenhum")
+	}
+
+	fmt.Printf("  Tráfego: %d pacotes recebidos (%d bytes), %d enviados (%d bytes)\n",
+		info.Traffic.PacketsReceived, info.Traffic.BytesReceived,
+		info.Traffic.PacketsSent, info.Traffic.BytesSent)
+	fmt.Printf("  Reputação: %d/%d (%s)\n", info.Reputation.Score, mesh.MaxPeerScore, info.Reputation.Standing)
+	for kind, count := range info.Reputation.Counts {
+		fmt.Printf("    %s: %d\n", kind, count)
+	}
+	fmt.Printf("  Vínculo BLE: %v\n", info.Bonded)
+}
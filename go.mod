@@ -2,13 +2,17 @@ module github.com/permissionlesstech/bitchat
 
 go 1.24.2
 
+require (
+	github.com/godbus/dbus/v5 v5.0.3
+	github.com/muka/go-bluetooth v0.0.0-20240701044517-04c4f09c514e
+	github.com/pierrec/lz4/v4 v4.1.22
+	golang.org/x/crypto v0.40.0
+	golang.org/x/sys v0.34.0
+	golang.org/x/term v0.33.0
+)
+
 require (
 	github.com/fatih/structs v1.1.0 // indirect
-	github.com/godbus/dbus/v5 v5.0.3 // indirect
 	github.com/konsorten/go-windows-terminal-sequences v1.0.3 // indirect
-	github.com/muka/go-bluetooth v0.0.0-20240701044517-04c4f09c514e // indirect
-	github.com/pierrec/lz4/v4 v4.1.22 // indirect
 	github.com/sirupsen/logrus v1.6.0 // indirect
-	golang.org/x/crypto v0.40.0 // indirect
-	golang.org/x/sys v0.34.0 // indirect
 )
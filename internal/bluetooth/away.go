@@ -0,0 +1,71 @@
+package bluetooth
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/internal/service"
+)
+
+// AwayDelegate pode ser implementado pelo delegate para ser notificado das
+// respostas automáticas enviadas durante a ausência, para guardá-las na
+// conversa
+type AwayDelegate interface {
+	OnAwayReplySent(reply service.AwayReply)
+}
+
+// SetAway marca o usuário como ausente: mensagens privadas recebidas são
+// respondidas automaticamente com message, uma vez por remetente a cada
+// service.DefaultAwayCooldown. Uma mensagem vazia marca o usuário como
+// presente.
+func (bms *BluetoothMeshService) SetAway(message string) {
+	if message == "" {
+		bms.away.SetBack()
+		return
+	}
+	bms.away.SetAway(message, time.Now())
+}
+
+// GetAway retorna a mensagem de ausência e o início da ausência, com ok
+// false se o usuário está presente
+func (bms *BluetoothMeshService) GetAway() (message string, since time.Time, ok bool) {
+	return bms.away.Away()
+}
+
+// AwayReplies retorna as respostas automáticas enviadas, da mais antiga para
+// a mais recente
+func (bms *BluetoothMeshService) AwayReplies() []service.AwayReply {
+	return bms.away.Log()
+}
+
+// autoRespond responde automaticamente a uma mensagem privada recebida
+// durante a ausência do usuário
+func (bms *BluetoothMeshService) autoRespond(received *protocol.BitchatMessage) {
+	now := time.Now()
+	content, ok := bms.away.Due(received.Sender, now)
+	if !ok {
+		return
+	}
+
+	reply := &protocol.BitchatMessage{
+		Content:           content,
+		IsPrivate:         true,
+		RecipientNickname: received.Sender,
+	}
+	messageID, err := bms.SendMessage(reply)
+	if err != nil {
+		slog.Warn("erro ao enviar resposta automática", "to", received.Sender, "err", err)
+		return
+	}
+
+	sent := service.AwayReply{
+		PeerID:    received.SenderPeerID,
+		Nickname:  received.Sender,
+		MessageID: messageID,
+		Content:   content,
+		SentAt:    now,
+	}
+	bms.away.Record(sent)
+	bms.events.publish(topicActivity, ActivityEvent{Type: ActivityAwayReplySent, AwayReply: sent})
+}
@@ -0,0 +1,122 @@
+package bluetooth
+
+import "errors"
+
+const (
+	// Limites padrão do modo automático de bateria, em porcentagem
+	DefaultLowBatteryThreshold      = 30
+	DefaultUltraLowBatteryThreshold = 10
+
+	// BatteryAutoHysteresis é a folga, em pontos percentuais, exigida acima
+	// de um limite para voltar a um modo de menor economia. Evita alternar
+	// de modo a cada leitura quando o nível oscila perto do limite.
+	BatteryAutoHysteresis = 5
+)
+
+// ErrInvalidBatteryThresholds indica limites fora de 0-100 ou com o limite
+// de bateria muito baixa acima do de bateria baixa
+var ErrInvalidBatteryThresholds = errors.New("limites de bateria inválidos")
+
+// BatteryThresholds são os níveis de bateria em que o modo automático passa
+// aos modos de economia
+type BatteryThresholds struct {
+	Low      int // Nível, ou abaixo, para BatteryModeLow
+	UltraLow int // Nível, ou abaixo, para BatteryModeUltraLow
+}
+
+// DefaultBatteryThresholds retorna os limites padrão do modo automático
+func DefaultBatteryThresholds() BatteryThresholds {
+	return BatteryThresholds{Low: DefaultLowBatteryThreshold, UltraLow: DefaultUltraLowBatteryThreshold}
+}
+
+// BatteryModeDelegate pode ser implementado pelo delegate para ser
+// notificado quando o modo automático troca o modo de bateria
+type BatteryModeDelegate interface {
+	OnBatteryModeChanged(mode int, level int)
+}
+
+// EnableBatteryAuto passa a escolher o modo de bateria pelo nível informado
+// pela plataforma, com os limites indicados. O modo muda já se o nível for
+// conhecido.
+func (bms *BluetoothMeshService) EnableBatteryAuto(thresholds BatteryThresholds) error {
+	if thresholds.UltraLow < 0 || thresholds.Low > 100 || thresholds.UltraLow > thresholds.Low {
+		return ErrInvalidBatteryThresholds
+	}
+
+	bms.mutex.Lock()
+	bms.batteryAuto = true
+	bms.batteryThresholds = thresholds
+	bms.mutex.Unlock()
+
+	bms.applyBatteryAuto()
+	return nil
+}
+
+// DisableBatteryAuto desliga o modo automático, mantendo o modo atual
+func (bms *BluetoothMeshService) DisableBatteryAuto() {
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+
+	bms.batteryAuto = false
+}
+
+// BatteryAuto informa se o modo automático está ligado e seus limites
+func (bms *BluetoothMeshService) BatteryAuto() (BatteryThresholds, bool) {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	return bms.batteryThresholds, bms.batteryAuto
+}
+
+// applyBatteryAuto troca o modo de bateria conforme o nível atual e o estado
+// de carga, se o modo automático estiver ligado, e avisa o delegate da mudança
+func (bms *BluetoothMeshService) applyBatteryAuto() {
+	bms.mutex.RLock()
+	enabled, known, charging := bms.batteryAuto, bms.batteryKnown, bms.batteryCharging
+	level, current, thresholds := bms.batteryLevel, bms.batteryMode, bms.batteryThresholds
+	bms.mutex.RUnlock()
+
+	if !enabled || !known {
+		return
+	}
+
+	// Carregando, não há por que economizar
+	mode := BatteryModeNormal
+	if !charging {
+		mode = autoBatteryMode(current, level, thresholds)
+	}
+	if mode == current {
+		return
+	}
+
+	bms.SetBatteryMode(mode)
+	bms.events.publish(topicTransport, TransportEvent{Type: TransportBatteryModeChanged, BatteryMode: mode, BatteryLevel: level})
+}
+
+// batteryModeForLevel retorna o modo de bateria para um nível
+func batteryModeForLevel(level int, thresholds BatteryThresholds) int {
+	switch {
+	case level <= thresholds.UltraLow:
+		return BatteryModeUltraLow
+	case level <= thresholds.Low:
+		return BatteryModeLow
+	}
+	return BatteryModeNormal
+}
+
+// autoBatteryMode escolhe o modo de bateria para um nível. A economia
+// aumenta assim que um limite é atingido, mas só diminui quando o nível
+// passa do limite por BatteryAutoHysteresis.
+func autoBatteryMode(current, level int, thresholds BatteryThresholds) int {
+	mode := batteryModeForLevel(level, thresholds)
+	if mode < current {
+		held := batteryModeForLevel(level-BatteryAutoHysteresis, thresholds)
+		if held > current {
+			held = current
+		}
+		if held > mode {
+			mode = held
+		}
+	}
+	return mode
+}
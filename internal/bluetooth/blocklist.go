@@ -0,0 +1,132 @@
+package bluetooth
+
+import (
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// FingerprintLength é o tamanho, em caracteres hex, da impressão digital de
+// uma identidade
+const FingerprintLength = 16
+
+// ErrInvalidFingerprint é retornado para impressões digitais malformadas
+var ErrInvalidFingerprint = errors.New("impressão digital inválida")
+
+// NormalizeFingerprint valida uma impressão digital e a converte para
+// minúsculas
+func NormalizeFingerprint(fingerprint string) (string, error) {
+	fingerprint = strings.ToLower(strings.TrimSpace(fingerprint))
+	if len(fingerprint) != FingerprintLength {
+		return "", ErrInvalidFingerprint
+	}
+	if _, err := hex.DecodeString(fingerprint); err != nil {
+		return "", ErrInvalidFingerprint
+	}
+	return fingerprint, nil
+}
+
+// peerFingerprint retorna a impressão digital da identidade de um peer, ou
+// "" se as chaves ainda não foram recebidas
+func (bms *BluetoothMeshService) peerFingerprint(peerID string) string {
+	identityKey := bms.encryptionService.GetPeerIdentityKey(peerID)
+	if identityKey == nil {
+		return ""
+	}
+	return bms.encryptionService.GetPublicKeyFingerprint(identityKey)
+}
+
+// SetBlockedFingerprints define as identidades bloqueadas, normalmente as
+// guardadas da execução anterior
+func (bms *BluetoothMeshService) SetBlockedFingerprints(fingerprints []string) error {
+	blocked := make(map[string]bool, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		normalized, err := NormalizeFingerprint(fingerprint)
+		if err != nil {
+			return err
+		}
+		blocked[normalized] = true
+	}
+
+	bms.mutex.Lock()
+	bms.blockedFingerprints = blocked
+	bms.mutex.Unlock()
+
+	bms.applyBlockedFingerprints()
+	return nil
+}
+
+// BlockFingerprint bloqueia uma identidade: os pacotes dos peers que a
+// apresentam são descartados pelo roteador, mesmo que troquem de ID ou de
+// apelido. Retorna os peers conhecidos bloqueados agora.
+func (bms *BluetoothMeshService) BlockFingerprint(fingerprint string) ([]string, error) {
+	fingerprint, err := NormalizeFingerprint(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	bms.mutex.Lock()
+	bms.blockedFingerprints[fingerprint] = true
+	bms.mutex.Unlock()
+
+	return bms.applyBlockedFingerprints(), nil
+}
+
+// UnblockFingerprint desbloqueia uma identidade e os peers que a apresentam
+func (bms *BluetoothMeshService) UnblockFingerprint(fingerprint string) error {
+	fingerprint, err := NormalizeFingerprint(fingerprint)
+	if err != nil {
+		return err
+	}
+
+	bms.mutex.Lock()
+	delete(bms.blockedFingerprints, fingerprint)
+	bms.mutex.Unlock()
+
+	for _, peerID := range bms.router.GetBlockedPeers() {
+		if bms.peerFingerprint(peerID) == fingerprint {
+			bms.router.UnblockPeer(peerID)
+		}
+	}
+	return nil
+}
+
+// BlockedFingerprints retorna, em ordem, as identidades bloqueadas
+func (bms *BluetoothMeshService) BlockedFingerprints() []string {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	fingerprints := make([]string, 0, len(bms.blockedFingerprints))
+	for fingerprint := range bms.blockedFingerprints {
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	sort.Strings(fingerprints)
+	return fingerprints
+}
+
+// applyBlockedFingerprints bloqueia no roteador os peers conhecidos cuja
+// identidade está bloqueada, retornando-os
+func (bms *BluetoothMeshService) applyBlockedFingerprints() []string {
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+
+	var blocked []string
+	for peerID := range bms.peers {
+		if bms.blockIfFingerprintBlockedLocked(peerID) {
+			blocked = append(blocked, peerID)
+		}
+	}
+	return blocked
+}
+
+// blockIfFingerprintBlockedLocked bloqueia no roteador um peer cuja
+// identidade está bloqueada. Exige bms.mutex.
+func (bms *BluetoothMeshService) blockIfFingerprintBlockedLocked(peerID string) bool {
+	fingerprint := bms.peerFingerprint(peerID)
+	if fingerprint == "" || !bms.blockedFingerprints[fingerprint] {
+		return false
+	}
+	bms.router.BlockPeer(peerID)
+	return true
+}
@@ -0,0 +1,142 @@
+package bluetooth
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/muka/go-bluetooth/bluez"
+	"github.com/muka/go-bluetooth/bluez/profile/agent"
+	"github.com/muka/go-bluetooth/bluez/profile/device"
+)
+
+// EnableBonding passa a parear e manter vínculo com o dispositivo no
+// endereço informado. Se ele já estiver conectado o pareamento é feito
+// agora; caso contrário, na próxima conexão.
+func (lba *LinuxBluetoothAdapter) EnableBonding(address string) error {
+	lba.deviceMutex.Lock()
+	lba.bondAddresses[address] = true
+	lba.deviceMutex.Unlock()
+
+	_, dev := lba.deviceByAddress(address)
+	if dev == nil {
+		return nil
+	}
+
+	connected, err := dev.GetConnected()
+	if err != nil || !connected {
+		return nil
+	}
+	return lba.bond(dev)
+}
+
+// DisableBonding deixa de manter vínculo com o dispositivo e apaga as chaves
+// armazenadas pelo BlueZ, para que o sistema não guarde uma identidade
+// estável do peer
+func (lba *LinuxBluetoothAdapter) DisableBonding(address string) error {
+	lba.deviceMutex.Lock()
+	delete(lba.bondAddresses, address)
+	lba.deviceMutex.Unlock()
+
+	path, dev := lba.deviceByAddress(address)
+	if dev == nil {
+		return nil
+	}
+
+	paired, err := dev.GetPaired()
+	if err != nil || !paired {
+		return nil
+	}
+
+	// Remover o dispositivo descarta o vínculo; ele volta na próxima descoberta
+	if err := lba.adapter.RemoveDevice(dev.Path()); err != nil {
+		return fmt.Errorf("erro ao remover vínculo: %v", err)
+	}
+
+	lba.deviceMutex.Lock()
+	delete(lba.devices, path)
+	lba.deviceMutex.Unlock()
+	lba.connections.Disconnected(path)
+	lba.connections.Forget(path)
+	return nil
+}
+
+// bondIfRequested pareia um dispositivo recém-conectado quando o vínculo foi
+// habilitado para ele
+func (lba *LinuxBluetoothAdapter) bondIfRequested(dev *device.Device1) {
+	address, err := dev.GetAddress()
+	if err != nil {
+		return
+	}
+
+	lba.deviceMutex.RLock()
+	requested := lba.bondAddresses[address]
+	lba.deviceMutex.RUnlock()
+
+	if !requested {
+		return
+	}
+	if err := lba.bond(dev); err != nil {
+		slog.Error("erro ao criar vínculo", "address", address, "err", err)
+	}
+}
+
+// bond pareia com o dispositivo, se ainda não houver vínculo, e o marca como
+// confiável para que as reconexões dispensem autorização
+func (lba *LinuxBluetoothAdapter) bond(dev *device.Device1) error {
+	paired, err := dev.GetPaired()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar pareamento: %v", err)
+	}
+
+	if !paired {
+		if err := lba.ensureBondingAgent(); err != nil {
+			return err
+		}
+		if err := dev.Pair(); err != nil {
+			return fmt.Errorf("erro ao parear dispositivo: %v", err)
+		}
+	}
+
+	if err := dev.SetTrusted(true); err != nil {
+		return fmt.Errorf("erro ao marcar dispositivo como confiável: %v", err)
+	}
+	return nil
+}
+
+// ensureBondingAgent registra no BlueZ um agente sem entrada nem saída
+// (pareamento "Just Works"). O agente não é o padrão do sistema, então só
+// atende os pareamentos iniciados pelo Bitchat.
+func (lba *LinuxBluetoothAdapter) ensureBondingAgent() error {
+	lba.deviceMutex.Lock()
+	defer lba.deviceMutex.Unlock()
+
+	if lba.bondAgent != nil {
+		return nil
+	}
+
+	conn, err := bluez.GetConnection(bluez.SystemBus)
+	if err != nil {
+		return fmt.Errorf("erro ao conectar ao D-Bus: %v", err)
+	}
+
+	ag := agent.NewSimpleAgent()
+	if err := agent.ExposeAgent(conn, ag, agent.CapNoInputNoOutput, false); err != nil {
+		return fmt.Errorf("erro ao registrar agente de pareamento: %v", err)
+	}
+
+	lba.bondAgent = ag
+	return nil
+}
+
+// deviceByAddress localiza um dispositivo conhecido pelo endereço
+func (lba *LinuxBluetoothAdapter) deviceByAddress(address string) (string, *device.Device1) {
+	lba.deviceMutex.RLock()
+	defer lba.deviceMutex.RUnlock()
+
+	for path, dev := range lba.devices {
+		if addr, err := dev.GetAddress(); err == nil && addr == address {
+			return path, dev
+		}
+	}
+	return "", nil
+}
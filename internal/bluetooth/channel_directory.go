@@ -0,0 +1,191 @@
+package bluetooth
+
+import (
+	"sort"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+)
+
+// DiscoveredChannelTTL é por quanto tempo um peer conta como membro de um
+// canal depois do último anúncio da sua lista de canais
+const DiscoveredChannelTTL = 10 * time.Minute
+
+// channelSighting é o anúncio, por um peer, de que está em um canal
+type channelSighting struct {
+	flags uint8
+	seen  time.Time
+}
+
+// DiscoveredChannel é um canal conhecido pelos anúncios dos peers
+type DiscoveredChannel struct {
+	Name      string
+	Members   int  // Peers que anunciaram o canal, mais este nó se for membro
+	Joined    bool // Este nó está no canal
+	Protected bool // Algum membro anunciou que o canal exige senha
+}
+
+// SetLocalChannels define os canais em que este nó está e os anuncia à rede,
+// para que apareçam na descoberta de canais dos peers
+func (bms *BluetoothMeshService) SetLocalChannels(channels []protocol.ChannelListEntry) {
+	bms.mutex.Lock()
+	bms.localChannels = append([]protocol.ChannelListEntry(nil), channels...)
+	running := bms.isRunning
+	bms.mutex.Unlock()
+
+	if running {
+		bms.sendChannelList()
+	}
+}
+
+// DiscoveredChannels retorna os canais anunciados pelos peers e os deste nó,
+// do mais populoso para o menos
+func (bms *BluetoothMeshService) DiscoveredChannels() []DiscoveredChannel {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	found := make(map[string]*DiscoveredChannel)
+	get := func(name string) *DiscoveredChannel {
+		channel, ok := found[name]
+		if !ok {
+			channel = &DiscoveredChannel{Name: name}
+			found[name] = channel
+		}
+		return channel
+	}
+
+	threshold := time.Now().Add(-DiscoveredChannelTTL)
+	for name, members := range bms.channelDirectory {
+		for _, sighting := range members {
+			if sighting.seen.Before(threshold) {
+				continue
+			}
+			channel := get(name)
+			channel.Members++
+			if sighting.flags&protocol.ChannelFlagProtected != 0 {
+				channel.Protected = true
+			}
+		}
+	}
+	for _, entry := range bms.localChannels {
+		channel := get(entry.Channel)
+		channel.Members++
+		channel.Joined = true
+		if entry.Protected() {
+			channel.Protected = true
+		}
+	}
+
+	channels := make([]DiscoveredChannel, 0, len(found))
+	for _, channel := range found {
+		channels = append(channels, *channel)
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		if channels[i].Members != channels[j].Members {
+			return channels[i].Members > channels[j].Members
+		}
+		return channels[i].Name < channels[j].Name
+	})
+	return channels
+}
+
+// sendChannelList anuncia os canais em que este nó está. Uma lista vazia
+// também é anunciada, para que os peers esqueçam canais deixados.
+func (bms *BluetoothMeshService) sendChannelList() {
+	bms.mutex.RLock()
+	payload := protocol.EncodeChannelList(bms.localChannels)
+	bms.mutex.RUnlock()
+
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeChannelList,
+		SenderID:    bms.deviceID,
+		RecipientID: protocol.BroadcastRecipient,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     payload,
+		TTL:         bms.messageTTL(),
+	}
+
+	bms.enqueuePacket(packet)
+}
+
+// handleChannelList registra os canais anunciados por um peer, substituindo
+// o anúncio anterior
+func (bms *BluetoothMeshService) handleChannelList(packet *protocol.BitchatPacket) {
+	entries, err := protocol.DecodeChannelList(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+
+	peerID := string(packet.SenderID)
+	now := time.Now()
+
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+
+	bms.forgetChannelMemberLocked(peerID)
+	for _, entry := range entries {
+		if !validChannel(entry.Channel) {
+			continue
+		}
+		members, ok := bms.channelDirectory[entry.Channel]
+		if !ok {
+			members = make(map[string]channelSighting)
+			bms.channelDirectory[entry.Channel] = members
+		}
+		members[peerID] = channelSighting{flags: entry.Flags, seen: now}
+	}
+	bms.checkChannelsRepopulatedLocked(peerID, entries)
+}
+
+// removeChannelMemberLocked registra a saída de um peer de um canal. Exige
+// bms.mutex.
+func (bms *BluetoothMeshService) removeChannelMemberLocked(channel, peerID string) {
+	if members, ok := bms.channelDirectory[channel]; ok {
+		delete(members, peerID)
+		if len(members) == 0 {
+			delete(bms.channelDirectory, channel)
+		}
+	}
+}
+
+// forgetChannelMemberLocked remove um peer de todos os canais. Exige
+// bms.mutex.
+func (bms *BluetoothMeshService) forgetChannelMemberLocked(peerID string) {
+	for channel := range bms.channelDirectory {
+		bms.removeChannelMemberLocked(channel, peerID)
+	}
+}
+
+// cleanupChannelDirectory esquece anúncios de canais expirados
+func (bms *BluetoothMeshService) cleanupChannelDirectory() {
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+
+	threshold := time.Now().Add(-DiscoveredChannelTTL)
+	for channel, members := range bms.channelDirectory {
+		for peerID, sighting := range members {
+			if sighting.seen.Before(threshold) {
+				bms.removeChannelMemberLocked(channel, peerID)
+			}
+		}
+	}
+}
+
+// channelMembers retorna os peers que anunciaram estar em um canal dentro de
+// DiscoveredChannelTTL
+func (bms *BluetoothMeshService) channelMembers(channel string) []string {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	threshold := time.Now().Add(-DiscoveredChannelTTL)
+	var members []string
+	for peerID, sighting := range bms.channelDirectory[channel] {
+		if !sighting.seen.Before(threshold) {
+			members = append(members, peerID)
+		}
+	}
+	return members
+}
@@ -0,0 +1,225 @@
+package bluetooth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+)
+
+var (
+	// ErrInvalidChannel indica um nome de canal sem o prefixo # ou longo demais
+	ErrInvalidChannel = errors.New("canal inválido")
+
+	// ErrNotChannelOwner indica uma alteração de metadados por quem não é o
+	// dono do canal
+	ErrNotChannelOwner = errors.New("apenas o dono do canal pode alterá-lo")
+
+	// ErrTopicTooLong indica um tópico maior que protocol.MaxChannelTopicLength
+	ErrTopicTooLong = errors.New("tópico longo demais")
+)
+
+// ChannelMembershipDelegate pode ser implementado pelo delegate para ser
+// notificado quando um peer sai de um canal
+type ChannelMembershipDelegate interface {
+	OnPeerLeftChannel(peerID string, channel string)
+}
+
+// ChannelTopicDelegate pode ser implementado pelo delegate para ser
+// notificado quando o tópico de um canal muda
+type ChannelTopicDelegate interface {
+	OnChannelTopicChanged(channel string, topic string, ownerID string)
+}
+
+// validChannel verifica se um nome de canal pode ser anunciado
+func validChannel(channel string) bool {
+	return strings.HasPrefix(channel, "#") && len(channel) > 1 && len(channel) <= 255
+}
+
+// LeaveChannel avisa a rede que este nó saiu de um canal. O payload do
+// pacote MessageTypeLeave é o nome do canal.
+func (bms *BluetoothMeshService) LeaveChannel(channel string) error {
+	if !validChannel(channel) {
+		return ErrInvalidChannel
+	}
+
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeLeave,
+		SenderID:    bms.deviceID,
+		RecipientID: protocol.BroadcastRecipient,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     []byte(channel),
+		TTL:         bms.messageTTL(),
+	}
+
+	bms.enqueuePacket(packet)
+	return nil
+}
+
+// handleLeave processa a saída de um peer de um canal ou, sem payload, da
+// rede
+func (bms *BluetoothMeshService) handleLeave(packet *protocol.BitchatPacket) {
+	if len(packet.Payload) == 0 {
+		peerID := string(packet.SenderID)
+		bms.mutex.Lock()
+		if _, ok := bms.peers[peerID]; ok {
+			bms.removePeerLocked(peerID)
+		}
+		bms.mutex.Unlock()
+		return
+	}
+
+	channel := string(packet.Payload)
+	if !validChannel(channel) {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+
+	bms.mutex.Lock()
+	bms.removeChannelMemberLocked(channel, string(packet.SenderID))
+	bms.mutex.Unlock()
+
+	bms.events.publish(topicChannel, ChannelEvent{Type: ChannelMemberLeft, Channel: channel, PeerID: string(packet.SenderID)})
+}
+
+// SetChannelTopic define o tópico de um canal e o anuncia à rede. O primeiro
+// nó a definir o tópico de um canal sem dono conhecido passa a ser o dono.
+func (bms *BluetoothMeshService) SetChannelTopic(channel string, topic string) error {
+	if !validChannel(channel) {
+		return ErrInvalidChannel
+	}
+	if len(topic) > protocol.MaxChannelTopicLength {
+		return ErrTopicTooLong
+	}
+
+	ownerID := bms.encryptionService.GetPeerID()
+	bms.mutex.Lock()
+	if known, ok := bms.channels[channel]; ok && known.OwnerID != ownerID {
+		bms.mutex.Unlock()
+		return ErrNotChannelOwner
+	}
+	announce := &protocol.ChannelAnnounce{
+		Channel:   channel,
+		OwnerID:   ownerID,
+		Topic:     topic,
+		Timestamp: uint64(time.Now().UnixMilli()),
+	}
+	bms.channels[channel] = announce
+	bms.mutex.Unlock()
+
+	return bms.sendChannelAnnounce(announce)
+}
+
+// ChannelInfo retorna os metadados conhecidos de um canal
+func (bms *BluetoothMeshService) ChannelInfo(channel string) (protocol.ChannelAnnounce, bool) {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	announce, ok := bms.channels[channel]
+	if !ok {
+		return protocol.ChannelAnnounce{}, false
+	}
+	return *announce, true
+}
+
+// sendChannelAnnounce anuncia, assinados, os metadados de um canal
+func (bms *BluetoothMeshService) sendChannelAnnounce(announce *protocol.ChannelAnnounce) error {
+	payload := protocol.EncodeChannelAnnounce(announce)
+	signature, err := bms.encryptionService.Sign(payload)
+	if err != nil {
+		return fmt.Errorf("erro ao assinar anúncio de canal: %w", err)
+	}
+
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeChannelAnnounce,
+		SenderID:    bms.deviceID,
+		RecipientID: protocol.BroadcastRecipient,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     payload,
+		Signature:   signature,
+		TTL:         bms.messageTTL(),
+	}
+
+	bms.enqueuePacket(packet)
+	return nil
+}
+
+// announceOwnedChannels reanuncia os canais de que este nó é dono, para que
+// peers que chegaram depois conheçam o tópico
+func (bms *BluetoothMeshService) announceOwnedChannels() {
+	ownerID := bms.encryptionService.GetPeerID()
+
+	bms.mutex.RLock()
+	var owned []*protocol.ChannelAnnounce
+	for _, announce := range bms.channels {
+		if announce.OwnerID == ownerID {
+			owned = append(owned, announce)
+		}
+	}
+	bms.mutex.RUnlock()
+
+	for _, announce := range owned {
+		if err := bms.sendChannelAnnounce(announce); err != nil {
+			slog.Error("erro ao anunciar canal", "channel", announce.Channel, "err", err)
+		}
+	}
+}
+
+// peerIdentityID retorna o ID de identidade de um peer conhecido, no mesmo
+// formato de EncryptionService.GetPeerID
+func (bms *BluetoothMeshService) peerIdentityID(peerID string) string {
+	identityKey := bms.encryptionService.GetPeerIdentityKey(peerID)
+	if identityKey == nil {
+		return ""
+	}
+	hash := sha256.Sum256(identityKey)
+	return hex.EncodeToString(hash[:16])
+}
+
+// handleChannelAnnounce processa os metadados anunciados para um canal.
+// O anúncio só é aceito se assinado pelo próprio dono e mais recente que o
+// conhecido; o primeiro dono visto para um canal é mantido.
+func (bms *BluetoothMeshService) handleChannelAnnounce(packet *protocol.BitchatPacket) {
+	announce, err := protocol.DecodeChannelAnnounce(packet.Payload)
+	if err != nil || !validChannel(announce.Channel) {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+
+	senderID := string(packet.SenderID)
+	valid, err := bms.encryptionService.VerifyWithPeerID(packet.Signature, protocol.SignedPayload(packet), senderID)
+	if err != nil {
+		return // Peer ainda não anunciou suas chaves
+	}
+	if !valid {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorInvalidSignature)
+		return
+	}
+	if bms.peerIdentityID(senderID) != announce.OwnerID {
+		return
+	}
+
+	bms.mutex.Lock()
+	if known, ok := bms.channels[announce.Channel]; ok &&
+		(known.OwnerID != announce.OwnerID || known.Timestamp >= announce.Timestamp) {
+		bms.mutex.Unlock()
+		return
+	}
+	bms.channels[announce.Channel] = announce
+	bms.mutex.Unlock()
+
+	bms.events.publish(topicChannel, ChannelEvent{
+		Type:    ChannelTopicChanged,
+		Channel: announce.Channel,
+		Topic:   announce.Topic,
+		OwnerID: announce.OwnerID,
+	})
+}
@@ -0,0 +1,67 @@
+package bluetooth
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/utils"
+)
+
+const (
+	// CompressionThreshold é o menor conteúdo, em bytes, que vale comprimir;
+	// abaixo disso o cabeçalho LZ4 anula o ganho
+	CompressionThreshold = 128
+
+	// MaxDecompressedSize limita o conteúdo descomprimido de uma mensagem
+	MaxDecompressedSize = 64 * 1024
+)
+
+// compressionStats conta o efeito da compressão no conteúdo enviado
+type compressionStats struct {
+	messages   atomic.Uint64 // Mensagens enviadas comprimidas
+	bytesSaved atomic.Uint64 // Bytes economizados antes da criptografia
+}
+
+// GetCompressionStats retorna quantas mensagens foram enviadas comprimidas
+// e quantos bytes a compressão economizou
+func (bms *BluetoothMeshService) GetCompressionStats() (messages uint64, bytesSaved uint64) {
+	return bms.compression.messages.Load(), bms.compression.bytesSaved.Load()
+}
+
+// compressContent comprime o conteúdo de uma mensagem se for grande o
+// bastante e de um tipo que se beneficia, como texto. Retorna o conteúdo
+// original quando a compressão não reduz o tamanho.
+func (bms *BluetoothMeshService) compressContent(content []byte) ([]byte, bool) {
+	if len(content) < CompressionThreshold {
+		return content, false
+	}
+
+	compressed, ok, err := utils.CompressIfNeeded(content, http.DetectContentType(content))
+	if err != nil || !ok {
+		return content, false
+	}
+
+	bms.compression.messages.Add(1)
+	bms.compression.bytesSaved.Add(uint64(len(content) - len(compressed)))
+	return compressed, true
+}
+
+// decompressContent descomprime o conteúdo de um pacote com
+// PacketFlagCompressed; outros conteúdos são retornados sem alteração
+func decompressContent(packet *protocol.BitchatPacket, content []byte) ([]byte, error) {
+	if packet.Flags&protocol.PacketFlagCompressed == 0 {
+		return content, nil
+	}
+	return utils.DecompressDataLimited(content, MaxDecompressedSize)
+}
+
+// packContent prepara o conteúdo de uma mensagem para o payload,
+// comprimindo-o se vantajoso e marcando o pacote
+func (bms *BluetoothMeshService) packContent(packet *protocol.BitchatPacket, content string) []byte {
+	data, compressed := bms.compressContent([]byte(content))
+	if compressed {
+		packet.Flags |= protocol.PacketFlagCompressed
+	}
+	return data
+}
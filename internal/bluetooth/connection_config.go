@@ -0,0 +1,58 @@
+package bluetooth
+
+import (
+	"fmt"
+	"time"
+)
+
+// Limites do intervalo de conexão BLE definidos pela especificação
+const (
+	MinConnectionInterval = 7500 * time.Microsecond
+	MaxConnectionInterval = 4 * time.Second
+)
+
+// ConnectionConfig ajusta quantas conexões BLE são mantidas e o intervalo
+// de conexão pedido ao controlador. Valores zero mantêm os padrões.
+type ConnectionConfig struct {
+	MaxCentralConnections int           // Máximo de enlaces em que este nó é central, dentro do alvo de conexões
+	MinInterval           time.Duration // Menor intervalo entre eventos de conexão
+	MaxInterval           time.Duration // Maior intervalo entre eventos de conexão
+}
+
+// Validate verifica se os intervalos respeitam os limites do BLE
+func (cc ConnectionConfig) Validate() error {
+	if cc.MaxCentralConnections < 0 {
+		return fmt.Errorf("número máximo de conexões centrais inválido: %d", cc.MaxCentralConnections)
+	}
+	for _, interval := range []time.Duration{cc.MinInterval, cc.MaxInterval} {
+		if interval != 0 && (interval < MinConnectionInterval || interval > MaxConnectionInterval) {
+			return fmt.Errorf("intervalo de conexão fora dos limites (%v a %v): %v", MinConnectionInterval, MaxConnectionInterval, interval)
+		}
+	}
+	if cc.MinInterval != 0 && cc.MaxInterval != 0 && cc.MinInterval > cc.MaxInterval {
+		return fmt.Errorf("intervalo de conexão mínimo maior que o máximo: %v > %v", cc.MinInterval, cc.MaxInterval)
+	}
+	return nil
+}
+
+// SetConnectionConfig define os limites e parâmetros das conexões BLE.
+// Deve ser chamado antes de Start.
+func (bms *BluetoothMeshService) SetConnectionConfig(config ConnectionConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+
+	bms.connectionConfig = config
+	return nil
+}
+
+// GetConnectionConfig retorna os limites e parâmetros das conexões BLE
+func (bms *BluetoothMeshService) GetConnectionConfig() ConnectionConfig {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	return bms.connectionConfig
+}
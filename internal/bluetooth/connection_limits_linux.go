@@ -0,0 +1,80 @@
+package bluetooth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// connectionIntervalUnit é a unidade do intervalo de conexão no controlador
+const connectionIntervalUnit = 1250 * time.Microsecond
+
+// SetConnectionConfig aplica o limite de enlaces centrais ao gerenciador de
+// conexões e o intervalo de conexão às próximas conexões do controlador
+func (lba *LinuxBluetoothAdapter) SetConnectionConfig(config ConnectionConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	lba.radioMutex.Lock()
+	lba.connectionConfig = config
+	lba.radioMutex.Unlock()
+
+	lba.connections.SetMaxCentrals(config.MaxCentralConnections)
+	return lba.applyConnectionIntervals(config.MinInterval, config.MaxInterval)
+}
+
+// applyConnectionIntervals ajusta o intervalo de conexão das novas
+// conexões. Como o supervision timeout, o parâmetro só é exposto pelo
+// debugfs do controlador; sem debugfs ou sem privilégios o padrão do
+// controlador é mantido.
+func (lba *LinuxBluetoothAdapter) applyConnectionIntervals(min, max time.Duration) error {
+	dir := filepath.Join("/sys/kernel/debug/bluetooth", lba.adapterID)
+
+	// O kernel recusa um mínimo acima do máximo atual (e vice-versa), então
+	// a ordem de escrita depende do intervalo já configurado
+	entries := []struct {
+		name  string
+		value time.Duration
+	}{{"conn_min_interval", min}, {"conn_max_interval", max}}
+	if current, err := readConnectionInterval(filepath.Join(dir, "conn_max_interval")); err == nil && min > current {
+		entries[0], entries[1] = entries[1], entries[0]
+	}
+
+	for _, entry := range entries {
+		if entry.value == 0 {
+			continue
+		}
+		value := strconv.Itoa(int(entry.value / connectionIntervalUnit))
+		if err := os.WriteFile(filepath.Join(dir, entry.name), []byte(value), 0644); err != nil {
+			if os.IsNotExist(err) || os.IsPermission(err) {
+				return nil
+			}
+			return fmt.Errorf("erro ao ajustar intervalo de conexão: %v", err)
+		}
+	}
+	return nil
+}
+
+// readConnectionInterval lê um intervalo de conexão do debugfs
+func readConnectionInterval(path string) (time.Duration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	units, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(units) * connectionIntervalUnit, nil
+}
+
+// isConnectionLimitError verifica se o BlueZ recusou a conexão por falta de
+// recursos no controlador (erro HCI Connection Limit Exceeded)
+func isConnectionLimitError(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "no resources") || strings.Contains(message, "connection limit")
+}
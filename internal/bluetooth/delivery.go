@@ -0,0 +1,46 @@
+package bluetooth
+
+import (
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// PendingChannelDeliveries retorna o número de mensagens de canal ainda não
+// confirmadas por todos os membros conhecidos
+func (bms *BluetoothMeshService) PendingChannelDeliveries() int {
+	return bms.deliveries.Pending()
+}
+
+// trackChannelDelivery passa a contar as confirmações de uma mensagem de
+// canal contra os membros que anunciaram o canal
+func (bms *BluetoothMeshService) trackChannelDelivery(messageID string, channel string) {
+	bms.deliveries.Track(messageID, bms.channelMembers(channel))
+}
+
+// handleChannelDeliveryAck conta a confirmação de uma mensagem de canal e
+// informa o progresso aos inscritos. Retorna false se a mensagem não é
+// acompanhada.
+func (bms *BluetoothMeshService) handleChannelDeliveryAck(messageID string, peerID string, ack *protocol.DeliveryAck, recipient string) bool {
+	info, tracked := bms.deliveries.Ack(messageID, peerID)
+	if !tracked {
+		return false
+	}
+	if info != nil {
+		info.Recipient = recipient
+		info.HopCount = int(ack.HopCount)
+		bms.publishDelivery(messageID, info)
+	}
+	return true
+}
+
+// publishDelivery informa aos inscritos o novo status de entrega de uma
+// mensagem enviada por este nó
+func (bms *BluetoothMeshService) publishDelivery(messageID string, info *protocol.DeliveryInfo) {
+	bms.events.publish(topicDelivery, DeliveryEvent{MessageID: messageID, Status: info.Status, Info: info})
+}
+
+// cleanupChannelDeliveries esquece as mensagens de canal enviadas há muito tempo
+func (bms *BluetoothMeshService) cleanupChannelDeliveries() {
+	bms.deliveries.Expire(time.Now())
+}
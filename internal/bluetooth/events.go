@@ -0,0 +1,354 @@
+package bluetooth
+
+import (
+	"sync"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/internal/service"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+)
+
+// PeerEventType é o tipo de um PeerEvent
+type PeerEventType int
+
+const (
+	PeerDiscovered       PeerEventType = iota // Peer visto pela primeira vez
+	PeerLost                                  // Peer removido por inatividade
+	PeerPresenceChanged                       // Peer ficou online, visto recentemente ou offline
+	PeerNicknameChanged                       // Peer conhecido trocou de apelido
+	PeerFavoriteInRange                       // Peer favorito entrou no alcance
+	PeerProximityChanged                      // Vizinho começou a se aproximar ou a se afastar
+	PeerTyping                                // Peer começou ou parou de digitar
+)
+
+// PeerEvent informa a descoberta, a perda ou uma mudança no estado de um peer.
+// Os eventos PeerFavoriteInRange são publicados com o mutex do serviço
+// adquirido: seus inscritos não devem chamar métodos do serviço.
+type PeerEvent struct {
+	Type      PeerEventType
+	PeerID    string
+	Name      string              // Apelido, em PeerDiscovered, PeerNicknameChanged e PeerFavoriteInRange
+	OldName   string              // Apelido anterior, em PeerNicknameChanged
+	Presence  mesh.PresenceEvent  // Em PeerPresenceChanged
+	Proximity mesh.ProximityEvent // Em PeerProximityChanged
+	Typing    service.TypingEvent // Em PeerTyping
+}
+
+// MessageEvent informa uma mensagem recebida
+type MessageEvent struct {
+	Message *protocol.BitchatMessage
+}
+
+// DeliveryEvent informa uma mudança no status de entrega de uma mensagem
+// enviada por este nó
+type DeliveryEvent struct {
+	MessageID string
+	Status    protocol.DeliveryStatus
+	Info      *protocol.DeliveryInfo
+}
+
+// TransportEventType é o tipo de um TransportEvent
+type TransportEventType int
+
+const (
+	TransportRadioChanged       TransportEventType = iota // Rádio desligado, bloqueado, removido ou de volta
+	TransportRecovery                                     // Etapa da recuperação automática do BlueZ
+	TransportPowerChanged                                 // Nível de bateria ou estado de carga mudou
+	TransportBatteryModeChanged                           // Modo automático trocou o modo de bateria
+	TransportPacketDropped                                // Pacote descartado por excesso de carga nas filas
+)
+
+// TransportEvent informa uma mudança no rádio, na recuperação do BlueZ, na
+// energia do dispositivo ou a carga das filas internas
+type TransportEvent struct {
+	Type         TransportEventType
+	Radio        RadioState              // Em TransportRadioChanged
+	Recovery     HealthEvent             // Em TransportRecovery
+	Power        PowerState              // Em TransportPowerChanged
+	BatteryMode  int                     // Novo modo, em TransportBatteryModeChanged
+	BatteryLevel int                     // Nível que causou a troca, em TransportBatteryModeChanged
+	Queue        string                  // Fila, em TransportPacketDropped
+	Packet       *protocol.BitchatPacket // Em TransportPacketDropped
+	Priority     mesh.Priority           // Em TransportPacketDropped
+}
+
+// ChannelEventType é o tipo de um ChannelEvent
+type ChannelEventType int
+
+const (
+	ChannelMemberLeft   ChannelEventType = iota // Peer saiu do canal
+	ChannelTopicChanged                         // Tópico do canal mudou
+)
+
+// ChannelEvent informa a saída de um membro ou a troca de tópico de um canal
+type ChannelEvent struct {
+	Type    ChannelEventType
+	Channel string
+	PeerID  string // Em ChannelMemberLeft
+	Topic   string // Em ChannelTopicChanged
+	OwnerID string // Em ChannelTopicChanged
+}
+
+// FileEventType é o tipo de um FileEvent
+type FileEventType int
+
+const (
+	FileOffered  FileEventType = iota // Peer ofereceu um arquivo
+	FileProgress                      // Mais pedaços enviados ou recebidos
+	FileDone                          // Transferência concluída, recusada ou com falha
+)
+
+// FileEvent informa o andamento de uma transferência de arquivo
+type FileEvent struct {
+	Type     FileEventType
+	Transfer FileTransfer
+	Err      error // Em FileDone, nil se a transferência foi concluída
+}
+
+// ActivityEventType é o tipo de um ActivityEvent
+type ActivityEventType int
+
+const (
+	ActivityAwayReplySent  ActivityEventType = iota // Resposta automática enviada durante a ausência
+	ActivityTraceCompleted                          // Rastreamento de rota concluído ou expirado
+)
+
+// ActivityEvent informa o que o serviço fez por conta própria ou a pedido de
+// um comando de diagnóstico
+type ActivityEvent struct {
+	Type      ActivityEventType
+	AwayReply service.AwayReply // Em ActivityAwayReplySent
+	Trace     *mesh.TraceResult // Em ActivityTraceCompleted
+}
+
+// Tópicos do barramento de eventos
+type eventTopic int
+
+const (
+	topicPeer eventTopic = iota
+	topicMessage
+	topicDelivery
+	topicTransport
+	topicChannel
+	topicFile
+	topicActivity
+)
+
+// EventBus distribui os eventos do serviço mesh a qualquer número de
+// inscritos, para que armazenamento, notificações, pontes e interface
+// acompanhem a mesh de forma independente. Os inscritos são chamados em
+// ordem de inscrição, na goroutine que publicou o evento, e não devem
+// bloquear.
+type EventBus struct {
+	handlers map[eventTopic][]subscription
+	nextID   int
+	mutex    sync.RWMutex
+}
+
+// subscription é um inscrito em um tópico
+type subscription struct {
+	id      int
+	handler func(event interface{})
+}
+
+// NewEventBus cria um barramento sem inscritos
+func NewEventBus() *EventBus {
+	return &EventBus{handlers: make(map[eventTopic][]subscription)}
+}
+
+// SubscribePeers inscreve handler nos eventos de peers. A função retornada
+// cancela a inscrição.
+func (eb *EventBus) SubscribePeers(handler func(PeerEvent)) func() {
+	return eb.subscribe(topicPeer, func(event interface{}) { handler(event.(PeerEvent)) })
+}
+
+// SubscribeMessages inscreve handler nas mensagens recebidas. A função
+// retornada cancela a inscrição.
+func (eb *EventBus) SubscribeMessages(handler func(MessageEvent)) func() {
+	return eb.subscribe(topicMessage, func(event interface{}) { handler(event.(MessageEvent)) })
+}
+
+// SubscribeDeliveries inscreve handler nas mudanças de status de entrega. A
+// função retornada cancela a inscrição.
+func (eb *EventBus) SubscribeDeliveries(handler func(DeliveryEvent)) func() {
+	return eb.subscribe(topicDelivery, func(event interface{}) { handler(event.(DeliveryEvent)) })
+}
+
+// SubscribeTransport inscreve handler nos eventos do rádio e da energia. A
+// função retornada cancela a inscrição.
+func (eb *EventBus) SubscribeTransport(handler func(TransportEvent)) func() {
+	return eb.subscribe(topicTransport, func(event interface{}) { handler(event.(TransportEvent)) })
+}
+
+// SubscribeChannels inscreve handler nos eventos de canais. A função
+// retornada cancela a inscrição.
+func (eb *EventBus) SubscribeChannels(handler func(ChannelEvent)) func() {
+	return eb.subscribe(topicChannel, func(event interface{}) { handler(event.(ChannelEvent)) })
+}
+
+// SubscribeFiles inscreve handler nos eventos das transferências de arquivo.
+// A função retornada cancela a inscrição.
+func (eb *EventBus) SubscribeFiles(handler func(FileEvent)) func() {
+	return eb.subscribe(topicFile, func(event interface{}) { handler(event.(FileEvent)) })
+}
+
+// SubscribeActivity inscreve handler nas respostas automáticas e nos
+// resultados de diagnóstico. A função retornada cancela a inscrição.
+func (eb *EventBus) SubscribeActivity(handler func(ActivityEvent)) func() {
+	return eb.subscribe(topicActivity, func(event interface{}) { handler(event.(ActivityEvent)) })
+}
+
+func (eb *EventBus) subscribe(topic eventTopic, handler func(event interface{})) func() {
+	eb.mutex.Lock()
+	defer eb.mutex.Unlock()
+
+	eb.nextID++
+	id := eb.nextID
+	eb.handlers[topic] = append(eb.handlers[topic], subscription{id: id, handler: handler})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { eb.unsubscribe(topic, id) })
+	}
+}
+
+func (eb *EventBus) unsubscribe(topic eventTopic, id int) {
+	eb.mutex.Lock()
+	defer eb.mutex.Unlock()
+
+	handlers := eb.handlers[topic]
+	for i, s := range handlers {
+		if s.id == id {
+			// Uma nova fatia mantém intactas as cópias em uso por publish
+			eb.handlers[topic] = append(append([]subscription{}, handlers[:i]...), handlers[i+1:]...)
+			return
+		}
+	}
+}
+
+// publish entrega event aos inscritos do tópico. Inscritos adicionados ou
+// removidos durante a entrega valem a partir do próximo evento.
+func (eb *EventBus) publish(topic eventTopic, event interface{}) {
+	eb.mutex.RLock()
+	handlers := eb.handlers[topic]
+	eb.mutex.RUnlock()
+
+	for _, s := range handlers {
+		s.handler(event)
+	}
+}
+
+// Events retorna o barramento de eventos do serviço
+func (bms *BluetoothMeshService) Events() *EventBus {
+	return bms.events
+}
+
+// subscribeDelegate inscreve o delegate no barramento, traduzindo os eventos
+// para os métodos de MeshDelegate e das interfaces opcionais que ele
+// implementa. A função retornada cancela todas as inscrições.
+func subscribeDelegate(bus *EventBus, delegate MeshDelegate) func() {
+	cancels := []func(){
+		bus.SubscribePeers(func(event PeerEvent) {
+			switch event.Type {
+			case PeerDiscovered:
+				delegate.OnPeerDiscovered(event.PeerID, event.Name)
+			case PeerLost:
+				delegate.OnPeerLost(event.PeerID)
+			case PeerPresenceChanged:
+				if d, ok := delegate.(PresenceDelegate); ok {
+					d.OnPeerPresenceChanged(event.Presence)
+				}
+			case PeerNicknameChanged:
+				if d, ok := delegate.(NicknameDelegate); ok {
+					d.OnPeerNicknameChanged(event.PeerID, event.OldName, event.Name)
+				}
+			case PeerFavoriteInRange:
+				if d, ok := delegate.(FavoriteDelegate); ok {
+					d.OnFavoriteInRange(event.PeerID, event.Name)
+				}
+			case PeerProximityChanged:
+				if d, ok := delegate.(ProximityDelegate); ok {
+					d.OnPeerProximityChanged(event.Proximity)
+				}
+			case PeerTyping:
+				if d, ok := delegate.(TypingDelegate); ok {
+					d.OnPeerTyping(event.Typing)
+				}
+			}
+		}),
+		bus.SubscribeMessages(func(event MessageEvent) {
+			delegate.OnMessageReceived(event.Message)
+		}),
+		bus.SubscribeDeliveries(func(event DeliveryEvent) {
+			delegate.OnMessageDeliveryChanged(event.MessageID, event.Status, event.Info)
+		}),
+		bus.SubscribeTransport(func(event TransportEvent) {
+			switch event.Type {
+			case TransportRadioChanged:
+				if d, ok := delegate.(RadioStateDelegate); ok {
+					d.OnRadioStateChanged(event.Radio)
+				}
+			case TransportRecovery:
+				if d, ok := delegate.(HealthDelegate); ok {
+					d.OnHealthEvent(event.Recovery)
+				}
+			case TransportPowerChanged:
+				if d, ok := delegate.(PowerStateDelegate); ok {
+					d.OnPowerStateChanged(event.Power)
+				}
+			case TransportBatteryModeChanged:
+				if d, ok := delegate.(BatteryModeDelegate); ok {
+					d.OnBatteryModeChanged(event.BatteryMode, event.BatteryLevel)
+				}
+			case TransportPacketDropped:
+				if d, ok := delegate.(QueueDropDelegate); ok {
+					d.OnPacketDropped(event.Queue, event.Packet, event.Priority)
+				}
+			}
+		}),
+		bus.SubscribeChannels(func(event ChannelEvent) {
+			switch event.Type {
+			case ChannelMemberLeft:
+				if d, ok := delegate.(ChannelMembershipDelegate); ok {
+					d.OnPeerLeftChannel(event.PeerID, event.Channel)
+				}
+			case ChannelTopicChanged:
+				if d, ok := delegate.(ChannelTopicDelegate); ok {
+					d.OnChannelTopicChanged(event.Channel, event.Topic, event.OwnerID)
+				}
+			}
+		}),
+		bus.SubscribeFiles(func(event FileEvent) {
+			d, ok := delegate.(FileTransferDelegate)
+			if !ok {
+				return
+			}
+			switch event.Type {
+			case FileOffered:
+				d.OnFileOffered(event.Transfer)
+			case FileProgress:
+				d.OnFileProgress(event.Transfer)
+			case FileDone:
+				d.OnFileTransferDone(event.Transfer, event.Err)
+			}
+		}),
+		bus.SubscribeActivity(func(event ActivityEvent) {
+			switch event.Type {
+			case ActivityAwayReplySent:
+				if d, ok := delegate.(AwayDelegate); ok {
+					d.OnAwayReplySent(event.AwayReply)
+				}
+			case ActivityTraceCompleted:
+				if d, ok := delegate.(TraceDelegate); ok {
+					d.OnTraceResult(event.Trace)
+				}
+			}
+		}),
+	}
+
+	return func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}
+}
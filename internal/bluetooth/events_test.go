@@ -0,0 +1,172 @@
+package bluetooth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/permissionlesstech/bitchat/internal/crypto"
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/internal/service"
+)
+
+// eventRecorder é um delegate que guarda os eventos recebidos
+type eventRecorder struct {
+	events []string
+}
+
+func (r *eventRecorder) OnPeerDiscovered(peerID string, name string) {
+	r.events = append(r.events, "discovered:"+peerID+":"+name)
+}
+
+func (r *eventRecorder) OnPeerLost(peerID string) {
+	r.events = append(r.events, "lost:"+peerID)
+}
+
+func (r *eventRecorder) OnMessageReceived(message *protocol.BitchatMessage) {
+	r.events = append(r.events, "message:"+message.Content)
+}
+
+func (r *eventRecorder) OnMessageDeliveryChanged(messageID string, status protocol.DeliveryStatus, info *protocol.DeliveryInfo) {
+	r.events = append(r.events, "delivery:"+messageID)
+}
+
+func (r *eventRecorder) OnPeerTyping(event service.TypingEvent) {
+	r.events = append(r.events, "typing:"+event.PeerID)
+}
+
+func (r *eventRecorder) OnPeerNicknameChanged(peerID string, oldName string, newName string) {
+	r.events = append(r.events, "nickname:"+oldName+">"+newName)
+}
+
+func (r *eventRecorder) OnChannelTopicChanged(channel string, topic string, ownerID string) {
+	r.events = append(r.events, "topic:"+channel+":"+topic)
+}
+
+func (r *eventRecorder) OnFileOffered(transfer FileTransfer) {
+	r.events = append(r.events, "offered")
+}
+
+func (r *eventRecorder) OnFileProgress(transfer FileTransfer) {
+	r.events = append(r.events, "progress")
+}
+
+func (r *eventRecorder) OnFileTransferDone(transfer FileTransfer, err error) {
+	r.events = append(r.events, "done:"+err.Error())
+}
+
+func (r *eventRecorder) OnBatteryModeChanged(mode int, level int) {
+	r.events = append(r.events, "battery")
+}
+
+// expectEvents compara os eventos guardados com os esperados e os descarta
+func (r *eventRecorder) expectEvents(t *testing.T, expected ...string) {
+	t.Helper()
+
+	if len(r.events) != len(expected) {
+		t.Fatalf("Eventos esperados %v, obtidos %v", expected, r.events)
+	}
+	for i := range expected {
+		if r.events[i] != expected[i] {
+			t.Fatalf("Eventos esperados %v, obtidos %v", expected, r.events)
+		}
+	}
+	r.events = nil
+}
+
+func TestEventBus(t *testing.T) {
+	t.Run("Inscritos recebem na ordem de inscrição", func(t *testing.T) {
+		bus := NewEventBus()
+		var order []int
+		bus.SubscribePeers(func(event PeerEvent) { order = append(order, 1) })
+		bus.SubscribePeers(func(event PeerEvent) { order = append(order, 2) })
+		bus.SubscribeMessages(func(event MessageEvent) { order = append(order, 3) })
+
+		bus.publish(topicPeer, PeerEvent{Type: PeerDiscovered, PeerID: "peer1"})
+		if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+			t.Errorf("Entrega inesperada: %v", order)
+		}
+	})
+
+	t.Run("Cancelar a inscrição", func(t *testing.T) {
+		bus := NewEventBus()
+		count := 0
+		unsubscribe := bus.SubscribeFiles(func(event FileEvent) { count++ })
+		other := 0
+		bus.SubscribeFiles(func(event FileEvent) { other++ })
+
+		bus.publish(topicFile, FileEvent{Type: FileProgress})
+		unsubscribe()
+		unsubscribe() // Cancelar de novo não afeta os demais inscritos
+		bus.publish(topicFile, FileEvent{Type: FileProgress})
+
+		if count != 1 || other != 2 {
+			t.Errorf("Esperado 1 e 2 eventos, obtidos %d e %d", count, other)
+		}
+	})
+
+	t.Run("Inscrição cancelada durante a entrega vale no próximo evento", func(t *testing.T) {
+		bus := NewEventBus()
+		count := 0
+		var unsubscribe func()
+		bus.SubscribeChannels(func(event ChannelEvent) { unsubscribe() })
+		unsubscribe = bus.SubscribeChannels(func(event ChannelEvent) { count++ })
+
+		bus.publish(topicChannel, ChannelEvent{Type: ChannelMemberLeft})
+		bus.publish(topicChannel, ChannelEvent{Type: ChannelMemberLeft})
+		if count != 1 {
+			t.Errorf("Esperado 1 evento, obtidos %d", count)
+		}
+	})
+
+	t.Run("Delegate recebe os eventos das interfaces que implementa", func(t *testing.T) {
+		bus := NewEventBus()
+		recorder := &eventRecorder{}
+		cancel := subscribeDelegate(bus, recorder)
+
+		bus.publish(topicPeer, PeerEvent{Type: PeerDiscovered, PeerID: "peer1", Name: "ana"})
+		bus.publish(topicPeer, PeerEvent{Type: PeerNicknameChanged, PeerID: "peer1", OldName: "ana", Name: "bia"})
+		bus.publish(topicPeer, PeerEvent{Type: PeerTyping, PeerID: "peer1", Typing: service.TypingEvent{PeerID: "peer1"}})
+		bus.publish(topicMessage, MessageEvent{Message: &protocol.BitchatMessage{Content: "oi"}})
+		bus.publish(topicChannel, ChannelEvent{Type: ChannelTopicChanged, Channel: "#geral", Topic: "mesh"})
+		bus.publish(topicFile, FileEvent{Type: FileDone, Err: errors.New("recusado")})
+		bus.publish(topicTransport, TransportEvent{Type: TransportBatteryModeChanged})
+
+		// Eventos de interfaces não implementadas são ignorados
+		bus.publish(topicPeer, PeerEvent{Type: PeerFavoriteInRange, PeerID: "peer1"})
+		bus.publish(topicActivity, ActivityEvent{Type: ActivityTraceCompleted})
+
+		recorder.expectEvents(t,
+			"discovered:peer1:ana",
+			"nickname:ana>bia",
+			"typing:peer1",
+			"message:oi",
+			"topic:#geral:mesh",
+			"done:recusado",
+			"battery",
+		)
+
+		cancel()
+		bus.publish(topicPeer, PeerEvent{Type: PeerLost, PeerID: "peer1"})
+		recorder.expectEvents(t)
+	})
+
+	t.Run("Trocar o delegate do serviço", func(t *testing.T) {
+		encryptionService, err := crypto.NewEncryptionService(&crypto.EncryptionConfig{UseEphemeralOnly: true})
+		if err != nil {
+			t.Fatalf("Erro ao criar serviço de criptografia: %v", err)
+		}
+		bms := NewBluetoothMeshService([]byte("nodeaaaa"), "", encryptionService)
+
+		first, second := &eventRecorder{}, &eventRecorder{}
+		bms.SetDelegate(first)
+		bms.SetDelegate(second)
+		bms.notifyTyping(service.TypingEvent{PeerID: "peer1"})
+
+		first.expectEvents(t)
+		second.expectEvents(t, "typing:peer1")
+
+		bms.SetDelegate(nil)
+		bms.notifyTyping(service.TypingEvent{PeerID: "peer1"})
+		second.expectEvents(t)
+	})
+}
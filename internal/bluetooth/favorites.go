@@ -0,0 +1,97 @@
+package bluetooth
+
+import (
+	"sort"
+	"time"
+)
+
+// FavoriteMessageCacheTTL é por quanto tempo mensagens endereçadas a um
+// favorito aguardam no cache a volta do peer, em vez de DefaultMessageCacheTTL
+const FavoriteMessageCacheTTL = time.Hour
+
+// FavoriteDelegate é implementado por delegates que desejam saber quando um
+// peer favorito entra no alcance. É chamado com o mutex do serviço
+// adquirido: não deve chamar métodos do serviço.
+type FavoriteDelegate interface {
+	OnFavoriteInRange(peerID string, name string)
+}
+
+// SetFavorites define as identidades favoritas, normalmente as guardadas da
+// execução anterior
+func (bms *BluetoothMeshService) SetFavorites(fingerprints []string) error {
+	favorites := make(map[string]bool, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		normalized, err := NormalizeFingerprint(fingerprint)
+		if err != nil {
+			return err
+		}
+		favorites[normalized] = true
+	}
+
+	bms.mutex.Lock()
+	bms.favorites = favorites
+	bms.mutex.Unlock()
+
+	return bms.applyScanWhitelist()
+}
+
+// AddFavorite marca uma identidade como favorita. Favoritos são aceitos pela
+// descoberta filtrada e suas mensagens ficam mais tempo no cache de
+// store-and-forward, aguardando o peer voltar ao alcance.
+func (bms *BluetoothMeshService) AddFavorite(fingerprint string) error {
+	fingerprint, err := NormalizeFingerprint(fingerprint)
+	if err != nil {
+		return err
+	}
+
+	bms.mutex.Lock()
+	bms.favorites[fingerprint] = true
+	bms.mutex.Unlock()
+
+	return bms.applyScanWhitelist()
+}
+
+// RemoveFavorite desmarca uma identidade favorita
+func (bms *BluetoothMeshService) RemoveFavorite(fingerprint string) error {
+	fingerprint, err := NormalizeFingerprint(fingerprint)
+	if err != nil {
+		return err
+	}
+
+	bms.mutex.Lock()
+	delete(bms.favorites, fingerprint)
+	bms.mutex.Unlock()
+
+	return bms.applyScanWhitelist()
+}
+
+// Favorites retorna, em ordem, as identidades favoritas
+func (bms *BluetoothMeshService) Favorites() []string {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	fingerprints := make([]string, 0, len(bms.favorites))
+	for fingerprint := range bms.favorites {
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	sort.Strings(fingerprints)
+	return fingerprints
+}
+
+// IsFavorite informa se a identidade de um peer é favorita
+func (bms *BluetoothMeshService) IsFavorite(peerID string) bool {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	return bms.isFavoriteLocked(peerID)
+}
+
+// isFavoriteLocked informa se a identidade de um peer é favorita. Exige
+// bms.mutex.
+func (bms *BluetoothMeshService) isFavoriteLocked(peerID string) bool {
+	if len(bms.favorites) == 0 {
+		return false
+	}
+	fingerprint := bms.peerFingerprint(peerID)
+	return fingerprint != "" && bms.favorites[fingerprint]
+}
@@ -0,0 +1,553 @@
+package bluetooth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+	"github.com/permissionlesstech/bitchat/pkg/utils"
+)
+
+const (
+	// FileChunkSize é o tamanho dos pedaços de arquivo enviados
+	FileChunkSize = 2048
+
+	// MaxFileSize é o maior arquivo aceito em uma transferência
+	MaxFileSize = 32 << 20
+
+	// FileSendWindow limita os pedaços aguardando na fila de saída, para que
+	// uma transferência não descarte o restante do tráfego
+	FileSendWindow = 8
+
+	// FileTransferTimeout é o tempo sem progresso após o qual uma
+	// transferência é abandonada
+	FileTransferTimeout = 5 * time.Minute
+)
+
+var (
+	// ErrFileTooLarge indica um arquivo maior que MaxFileSize
+	ErrFileTooLarge = errors.New("arquivo grande demais")
+
+	// ErrTransferNotFound indica uma transferência desconhecida ou já encerrada
+	ErrTransferNotFound = errors.New("transferência não encontrada")
+
+	// ErrFileRejected indica que o destinatário recusou o arquivo
+	ErrFileRejected = errors.New("arquivo recusado pelo destinatário")
+
+	// ErrFileCorrupted indica um arquivo recebido cujo hash não confere
+	ErrFileCorrupted = errors.New("arquivo recebido corrompido")
+
+	// ErrTransferTimeout indica uma transferência abandonada por falta de progresso
+	ErrTransferTimeout = errors.New("transferência expirou")
+)
+
+// FileTransfer descreve uma transferência de arquivo em andamento
+type FileTransfer struct {
+	ID          string // Identificador da oferta, em hexadecimal
+	PeerID      string
+	Name        string
+	Size        int64
+	Transferred int64
+	Incoming    bool
+	Path        string // Arquivo local (origem ou destino)
+}
+
+// FileTransferDelegate pode ser implementado pelo delegate para acompanhar
+// as transferências de arquivo
+type FileTransferDelegate interface {
+	OnFileOffered(transfer FileTransfer)
+	OnFileProgress(transfer FileTransfer)
+	OnFileTransferDone(transfer FileTransfer, err error)
+}
+
+// fileTransfer é o estado interno de uma transferência
+type fileTransfer struct {
+	offer    protocol.FileOffer
+	peerID   string
+	incoming bool
+	path     string
+	file     *os.File
+	received map[uint32]bool
+	written  int64
+	accepted bool
+	updated  time.Time
+}
+
+// chunks retorna o número de pedaços do arquivo
+func (ft *fileTransfer) chunks() uint32 {
+	size := uint64(ft.offer.ChunkSize)
+	return uint32((ft.offer.Size + size - 1) / size)
+}
+
+// snapshot descreve a transferência para o delegate
+func (ft *fileTransfer) snapshot() FileTransfer {
+	return FileTransfer{
+		ID:          hex.EncodeToString(ft.offer.TransferID[:]),
+		PeerID:      ft.peerID,
+		Name:        ft.offer.Name,
+		Size:        int64(ft.offer.Size),
+		Transferred: ft.written,
+		Incoming:    ft.incoming,
+		Path:        ft.path,
+	}
+}
+
+// fileTransfers guarda as transferências em andamento
+type fileTransfers struct {
+	transfers map[string]*fileTransfer
+	mutex     sync.Mutex
+}
+
+// newFileTransfers cria o registro de transferências
+func newFileTransfers() *fileTransfers {
+	return &fileTransfers{transfers: make(map[string]*fileTransfer)}
+}
+
+// SendFile oferece um arquivo a um peer. O conteúdo só é enviado depois que
+// o destinatário aceitar a oferta. Retorna o identificador da transferência.
+func (bms *BluetoothMeshService) SendFile(peerID string, path string) (string, error) {
+	if _, exists := bms.getPeer(peerID); !exists {
+		return "", ErrPeerNotFound
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("erro ao abrir arquivo: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler arquivo: %v", err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s é um diretório", path)
+	}
+	if info.Size() > MaxFileSize {
+		return "", ErrFileTooLarge
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("erro ao ler arquivo: %v", err)
+	}
+
+	transfer := &fileTransfer{
+		offer: protocol.FileOffer{
+			Name:      filepath.Base(path),
+			Size:      uint64(info.Size()),
+			ChunkSize: FileChunkSize,
+		},
+		peerID:  peerID,
+		path:    path,
+		updated: time.Now(),
+	}
+	copy(transfer.offer.Hash[:], hash.Sum(nil))
+	if _, err := rand.Read(transfer.offer.TransferID[:]); err != nil {
+		return "", fmt.Errorf("erro ao gerar identificador: %v", err)
+	}
+
+	id := hex.EncodeToString(transfer.offer.TransferID[:])
+	bms.files.mutex.Lock()
+	bms.files.transfers[id] = transfer
+	bms.files.mutex.Unlock()
+
+	if err := bms.sendFilePacket(peerID, protocol.MessageTypeFileOffer, protocol.EncodeFileOffer(&transfer.offer)); err != nil {
+		bms.files.mutex.Lock()
+		delete(bms.files.transfers, id)
+		bms.files.mutex.Unlock()
+		return "", err
+	}
+	return id, nil
+}
+
+// AcceptFile aceita uma oferta recebida, gravando o arquivo em dir. Um nome
+// já existente recebe um sufixo numérico em vez de ser sobrescrito.
+func (bms *BluetoothMeshService) AcceptFile(transferID string, dir string) (string, error) {
+	bms.files.mutex.Lock()
+	transfer, ok := bms.files.transfers[transferID]
+	if !ok || !transfer.incoming || transfer.accepted {
+		bms.files.mutex.Unlock()
+		return "", ErrTransferNotFound
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		bms.files.mutex.Unlock()
+		return "", fmt.Errorf("erro ao criar diretório de downloads: %v", err)
+	}
+	file, path, err := createUniqueFile(dir, transfer.offer.Name)
+	if err != nil {
+		bms.files.mutex.Unlock()
+		return "", err
+	}
+	transfer.file = file
+	transfer.path = path
+	transfer.accepted = true
+	transfer.updated = time.Now()
+	peerID := transfer.peerID
+	empty := transfer.chunks() == 0
+	bms.files.mutex.Unlock()
+
+	accept := &protocol.FileAccept{TransferID: transfer.offer.TransferID, Accepted: true}
+	if err := bms.sendFilePacket(peerID, protocol.MessageTypeFileAccept, protocol.EncodeFileAccept(accept)); err != nil {
+		return "", err
+	}
+
+	// Arquivos vazios não têm pedaços a aguardar
+	if empty {
+		bms.finishIncomingFile(transferID)
+	}
+	return path, nil
+}
+
+// RejectFile recusa uma oferta recebida
+func (bms *BluetoothMeshService) RejectFile(transferID string) error {
+	bms.files.mutex.Lock()
+	transfer, ok := bms.files.transfers[transferID]
+	if !ok || !transfer.incoming || transfer.accepted {
+		bms.files.mutex.Unlock()
+		return ErrTransferNotFound
+	}
+	delete(bms.files.transfers, transferID)
+	bms.files.mutex.Unlock()
+
+	accept := &protocol.FileAccept{TransferID: transfer.offer.TransferID}
+	return bms.sendFilePacket(transfer.peerID, protocol.MessageTypeFileAccept, protocol.EncodeFileAccept(accept))
+}
+
+// GetFileTransfers retorna as transferências em andamento
+func (bms *BluetoothMeshService) GetFileTransfers() []FileTransfer {
+	bms.files.mutex.Lock()
+	defer bms.files.mutex.Unlock()
+
+	transfers := make([]FileTransfer, 0, len(bms.files.transfers))
+	for _, transfer := range bms.files.transfers {
+		transfers = append(transfers, transfer.snapshot())
+	}
+	return transfers
+}
+
+// createUniqueFile cria um arquivo em dir sem sobrescrever arquivos existentes
+func createUniqueFile(dir string, name string) (*os.File, string, error) {
+	name = filepath.Base(name)
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		name = "arquivo"
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	for i := 0; i < 1000; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+		}
+		path := filepath.Join(dir, candidate)
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			return file, path, nil
+		}
+		if !os.IsExist(err) {
+			return nil, "", fmt.Errorf("erro ao criar arquivo: %v", err)
+		}
+	}
+	return nil, "", fmt.Errorf("erro ao criar arquivo: nomes esgotados para %s", name)
+}
+
+// sendFilePacket envia, cifrado para o peer, um pacote da transferência
+func (bms *BluetoothMeshService) sendFilePacket(peerID string, messageType protocol.MessageType, payload []byte) error {
+	encrypted, _, err := bms.encryptionService.Encrypt(payload, []byte(peerID))
+	if err != nil {
+		return fmt.Errorf("erro ao cifrar transferência: %v", err)
+	}
+
+	bms.enqueuePacket(&protocol.BitchatPacket{
+		Version:     1,
+		Type:        messageType,
+		SenderID:    bms.deviceID,
+		RecipientID: []byte(peerID),
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     encrypted,
+		TTL:         bms.unicastTTL(peerID),
+	})
+	return nil
+}
+
+// decryptFilePacket decifra o payload de um pacote da transferência
+func (bms *BluetoothMeshService) decryptFilePacket(packet *protocol.BitchatPacket) ([]byte, bool) {
+	if !utils.ByteArraysEqual(packet.RecipientID, bms.deviceID) {
+		return nil, false
+	}
+	payload, err := bms.encryptionService.Decrypt(packet.Payload, packet.SenderID, nil)
+	if err != nil {
+		return nil, false
+	}
+	return payload, true
+}
+
+// handleFileOffer registra uma oferta de arquivo e a apresenta ao delegate
+func (bms *BluetoothMeshService) handleFileOffer(packet *protocol.BitchatPacket) {
+	payload, ok := bms.decryptFilePacket(packet)
+	if !ok {
+		return
+	}
+	offer, err := protocol.DecodeFileOffer(payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	if offer.Size > MaxFileSize {
+		accept := &protocol.FileAccept{TransferID: offer.TransferID}
+		bms.sendFilePacket(string(packet.SenderID), protocol.MessageTypeFileAccept, protocol.EncodeFileAccept(accept))
+		return
+	}
+
+	transfer := &fileTransfer{
+		offer:    *offer,
+		peerID:   string(packet.SenderID),
+		incoming: true,
+		received: make(map[uint32]bool),
+		updated:  time.Now(),
+	}
+	id := hex.EncodeToString(offer.TransferID[:])
+
+	bms.files.mutex.Lock()
+	if _, exists := bms.files.transfers[id]; exists {
+		bms.files.mutex.Unlock()
+		return
+	}
+	bms.files.transfers[id] = transfer
+	snapshot := transfer.snapshot()
+	bms.files.mutex.Unlock()
+
+	bms.events.publish(topicFile, FileEvent{Type: FileOffered, Transfer: snapshot})
+}
+
+// handleFileAccept inicia o envio de um arquivo aceito ou encerra a
+// transferência recusada
+func (bms *BluetoothMeshService) handleFileAccept(packet *protocol.BitchatPacket) {
+	payload, ok := bms.decryptFilePacket(packet)
+	if !ok {
+		return
+	}
+	accept, err := protocol.DecodeFileAccept(payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	id := hex.EncodeToString(accept.TransferID[:])
+
+	bms.files.mutex.Lock()
+	transfer, ok := bms.files.transfers[id]
+	if !ok || transfer.incoming || transfer.accepted || transfer.peerID != string(packet.SenderID) {
+		bms.files.mutex.Unlock()
+		return
+	}
+	if !accept.Accepted {
+		delete(bms.files.transfers, id)
+		bms.files.mutex.Unlock()
+		bms.notifyFileDone(transfer.snapshot(), ErrFileRejected)
+		return
+	}
+	transfer.accepted = true
+	transfer.updated = time.Now()
+	bms.files.mutex.Unlock()
+
+	go bms.streamFile(id, transfer)
+}
+
+// streamFile envia os pedaços de um arquivo aceito, respeitando a janela de
+// envio
+func (bms *BluetoothMeshService) streamFile(id string, transfer *fileTransfer) {
+	err := bms.sendFileChunks(transfer)
+
+	bms.files.mutex.Lock()
+	delete(bms.files.transfers, id)
+	snapshot := transfer.snapshot()
+	bms.files.mutex.Unlock()
+
+	bms.notifyFileDone(snapshot, err)
+}
+
+// sendFileChunks lê e enfileira os pedaços de um arquivo
+func (bms *BluetoothMeshService) sendFileChunks(transfer *fileTransfer) error {
+	file, err := os.Open(transfer.path)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo: %v", err)
+	}
+	defer file.Close()
+
+	bms.mutex.RLock()
+	ctx := bms.ctx
+	bms.mutex.RUnlock()
+
+	buf := make([]byte, transfer.offer.ChunkSize)
+	for index := uint32(0); index < transfer.chunks(); index++ {
+		for bms.outgoingQueue.LenAt(mesh.PriorityFile) >= FileSendWindow {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(50 * time.Millisecond):
+			}
+		}
+
+		n, err := file.ReadAt(buf, int64(index)*int64(transfer.offer.ChunkSize))
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("erro ao ler arquivo: %v", err)
+		}
+		chunk := &protocol.FileChunk{TransferID: transfer.offer.TransferID, Index: index, Data: buf[:n]}
+		if err := bms.sendFilePacket(transfer.peerID, protocol.MessageTypeFileChunk, protocol.EncodeFileChunk(chunk)); err != nil {
+			return err
+		}
+
+		bms.files.mutex.Lock()
+		transfer.written += int64(n)
+		transfer.updated = time.Now()
+		snapshot := transfer.snapshot()
+		bms.files.mutex.Unlock()
+		bms.notifyFileProgress(snapshot)
+	}
+	return nil
+}
+
+// handleFileChunk grava um pedaço de um arquivo aceito
+func (bms *BluetoothMeshService) handleFileChunk(packet *protocol.BitchatPacket) {
+	payload, ok := bms.decryptFilePacket(packet)
+	if !ok {
+		return
+	}
+	chunk, err := protocol.DecodeFileChunk(payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	id := hex.EncodeToString(chunk.TransferID[:])
+
+	bms.files.mutex.Lock()
+	transfer, ok := bms.files.transfers[id]
+	if !ok || !transfer.incoming || !transfer.accepted || transfer.peerID != string(packet.SenderID) ||
+		chunk.Index >= transfer.chunks() || transfer.received[chunk.Index] {
+		bms.files.mutex.Unlock()
+		return
+	}
+
+	offset := int64(chunk.Index) * int64(transfer.offer.ChunkSize)
+	if uint64(offset)+uint64(len(chunk.Data)) > transfer.offer.Size || len(chunk.Data) > int(transfer.offer.ChunkSize) {
+		bms.files.mutex.Unlock()
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	if _, err := transfer.file.WriteAt(chunk.Data, offset); err != nil {
+		bms.files.mutex.Unlock()
+		bms.failFileTransfer(id, fmt.Errorf("erro ao gravar arquivo: %v", err))
+		return
+	}
+	transfer.received[chunk.Index] = true
+	transfer.written += int64(len(chunk.Data))
+	transfer.updated = time.Now()
+	complete := uint32(len(transfer.received)) == transfer.chunks()
+	snapshot := transfer.snapshot()
+	bms.files.mutex.Unlock()
+
+	bms.notifyFileProgress(snapshot)
+	if complete {
+		bms.finishIncomingFile(id)
+	}
+}
+
+// finishIncomingFile confere o hash de um arquivo recebido por completo
+func (bms *BluetoothMeshService) finishIncomingFile(id string) {
+	bms.files.mutex.Lock()
+	transfer, ok := bms.files.transfers[id]
+	if !ok {
+		bms.files.mutex.Unlock()
+		return
+	}
+	delete(bms.files.transfers, id)
+	bms.files.mutex.Unlock()
+
+	err := transfer.file.Close()
+	if err == nil {
+		err = verifyFileHash(transfer.path, transfer.offer.Hash)
+	}
+	if err != nil {
+		os.Remove(transfer.path)
+	}
+	bms.notifyFileDone(transfer.snapshot(), err)
+}
+
+// verifyFileHash confere o SHA-256 de um arquivo
+func verifyFileHash(path string, expected [32]byte) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("erro ao conferir arquivo: %v", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("erro ao conferir arquivo: %v", err)
+	}
+	var sum [32]byte
+	copy(sum[:], hash.Sum(nil))
+	if sum != expected {
+		return ErrFileCorrupted
+	}
+	return nil
+}
+
+// failFileTransfer encerra uma transferência com erro, descartando o arquivo
+// parcial recebido
+func (bms *BluetoothMeshService) failFileTransfer(id string, err error) {
+	bms.files.mutex.Lock()
+	transfer, ok := bms.files.transfers[id]
+	if !ok {
+		bms.files.mutex.Unlock()
+		return
+	}
+	delete(bms.files.transfers, id)
+	bms.files.mutex.Unlock()
+
+	if transfer.file != nil {
+		transfer.file.Close()
+		os.Remove(transfer.path)
+	}
+	bms.notifyFileDone(transfer.snapshot(), err)
+}
+
+// cleanupFileTransfers abandona as transferências sem progresso recente
+func (bms *BluetoothMeshService) cleanupFileTransfers() {
+	threshold := time.Now().Add(-FileTransferTimeout)
+
+	bms.files.mutex.Lock()
+	var stale []string
+	for id, transfer := range bms.files.transfers {
+		if transfer.updated.Before(threshold) {
+			stale = append(stale, id)
+		}
+	}
+	bms.files.mutex.Unlock()
+
+	for _, id := range stale {
+		bms.failFileTransfer(id, ErrTransferTimeout)
+	}
+}
+
+// notifyFileProgress publica o progresso de uma transferência
+func (bms *BluetoothMeshService) notifyFileProgress(transfer FileTransfer) {
+	bms.events.publish(topicFile, FileEvent{Type: FileProgress, Transfer: transfer})
+}
+
+// notifyFileDone publica o fim de uma transferência
+func (bms *BluetoothMeshService) notifyFileDone(transfer FileTransfer, err error) {
+	bms.events.publish(topicFile, FileEvent{Type: FileDone, Transfer: transfer, Err: err})
+}
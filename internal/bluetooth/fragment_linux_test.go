@@ -0,0 +1,80 @@
+package bluetooth
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// FuzzFragmentManagerAddFragment entrega ao FragmentManager, na ordem do
+// roteiro, fragmentos de um pacote real misturados a payloads de fragmento
+// arbitrários, lidos como em handleFragmentPacket. O pacote real precisa ser
+// remontado intacto sempre que completar, e nada pode entrar em pânico.
+func FuzzFragmentManagerAddFragment(f *testing.F) {
+	original := protocol.NewBroadcastPacket(protocol.MessageTypeMessage, []byte("nodeaaaa"),
+		bytes.Repeat([]byte("fragmento de teste "), 12))
+	encoded, err := protocol.Encode(original)
+	if err != nil {
+		f.Fatalf("Erro ao codificar pacote: %v", err)
+	}
+
+	// Fragmentos do pacote real, no formato de sendFragmentedPacket
+	fragmentID := []byte{0xde, 0xad, 0xbe, 0xef}
+	const chunkSize = 48
+	var fragments [][]byte
+	total := (len(encoded) + chunkSize - 1) / chunkSize
+	for i := 0; i < total; i++ {
+		end := min((i+1)*chunkSize, len(encoded))
+		fragment := append([]byte{}, fragmentID...)
+		fragment = append(fragment, byte(i), byte(total))
+		fragments = append(fragments, append(fragment, encoded[i*chunkSize:end]...))
+	}
+
+	// Roteiro: bytes abaixo de 0x80 entregam o fragmento real de índice
+	// op % total; os demais entregam os próximos op-0x80 bytes como payload
+	f.Add([]byte{0, 1, 2, 3, 4})
+	f.Add([]byte{4, 3, 2, 1, 0, 4, 3, 2, 1, 0})
+	f.Add([]byte{0, 0x86, 1, 2, 3, 4, 0, 9, 1, 2, 3, 4})
+	f.Add([]byte{0x87, 1, 2, 3, 4, 0, 0, 0xff, 0x88, 1, 2, 3, 4, 7, 3, 0xaa, 0xbb, 0, 1, 2, 3, 4})
+	f.Add([]byte{0x84, 0xde, 0xad, 0xbe, 0xef, 0x92, 0xde, 0xad, 0xbe, 0xef, 0, 5})
+
+	f.Fuzz(func(t *testing.T, script []byte) {
+		fm := NewFragmentManager()
+
+		for step := 0; step < len(script); step++ {
+			op := script[step]
+			if op < 0x80 {
+				complete, reassembled := fm.AddFragment("alice", fragmentID, int(op)%total, total, fragments[int(op)%total][6:])
+				if !complete {
+					continue
+				}
+				if !bytes.Equal(reassembled, encoded) {
+					t.Fatalf("Passo %d: pacote remontado incorretamente: %x", step, reassembled)
+				}
+				if _, err := protocol.Decode(reassembled); err != nil {
+					t.Fatalf("Passo %d: pacote remontado não decodifica: %v", step, err)
+				}
+				continue
+			}
+
+			end := min(step+1+int(op-0x80), len(script))
+			payload := script[step+1 : end]
+			step = end - 1
+			if len(payload) < 6 {
+				continue // Descartado por handleFragmentPacket
+			}
+
+			// Payloads arbitrários não reutilizam o ID do pacote real, para
+			// que sua remontagem continue verificável
+			id := append([]byte{}, payload[0:4]...)
+			if bytes.Equal(id, fragmentID) {
+				id[0] ^= 0xff
+			}
+			complete, reassembled := fm.AddFragment("alice", id, int(payload[4]), int(payload[5]), payload[6:])
+			if complete {
+				protocol.Decode(reassembled) // Apenas não pode entrar em pânico
+			}
+		}
+	})
+}
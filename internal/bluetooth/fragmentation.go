@@ -0,0 +1,57 @@
+package bluetooth
+
+import (
+	"fmt"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+const (
+	// UnlimitedMTU indica um enlace capaz de transportar pacotes inteiros
+	// (por exemplo TCP ou UDP), dispensando a fragmentação
+	UnlimitedMTU = 0
+
+	// MinLinkMTU é o MTU ATT mínimo garantido pelo BLE
+	MinLinkMTU = 23
+
+	// MaxATTMTU é o maior MTU ATT permitido pelo BLE
+	MaxATTMTU = 517
+
+	attHeaderSize      = 3  // Cabeçalho ATT de uma escrita ou notificação
+	fragmentHeaderSize = 6  // ID (4), índice (1) e total (1) do fragmento
+	minFragmentPayload = 16 // Menor pedaço de dados útil por fragmento
+	maxFragments       = 255
+)
+
+// needsFragmentation verifica se um pacote codificado excede o MTU do enlace
+func needsFragmentation(size int, mtu int) bool {
+	if mtu == UnlimitedMTU {
+		return false
+	}
+	return size+attHeaderSize > mtu
+}
+
+// fragmentPayloadSize calcula quantos bytes do pacote original cabem em cada
+// fragmento para o MTU informado, descontando o cabeçalho ATT, o cabeçalho do
+// fragmento e os campos do pacote que o transporta
+func fragmentPayloadSize(mtu int, template *protocol.BitchatPacket) int {
+	empty := *template
+	empty.Payload = nil
+
+	overhead := attHeaderSize + fragmentHeaderSize + protocol.EncodedSize(&empty)
+
+	size := mtu - overhead
+	if size < minFragmentPayload {
+		size = minFragmentPayload
+	}
+	return size
+}
+
+// fragmentCount calcula o número de fragmentos necessários
+func fragmentCount(size int, payloadSize int) (int, error) {
+	count := (size + payloadSize - 1) / payloadSize
+	if count > maxFragments {
+		return 0, fmt.Errorf("pacote de %d bytes exige %d fragmentos (máximo %d)", size, count, maxFragments)
+	}
+	return count, nil
+}
@@ -0,0 +1,236 @@
+package bluetooth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/muka/go-bluetooth/api/service"
+	"github.com/muka/go-bluetooth/bluez/profile/gatt"
+)
+
+// RegisterGATTService exporta o serviço Bitchat no BlueZ: os centrais
+// conectados escrevem pacotes na característica de dados e se inscrevem nas
+// notificações da característica TX. Após uma recuperação do BlueZ ou a
+// reinserção do adaptador a mesma aplicação é registrada novamente.
+func (lba *LinuxBluetoothAdapter) RegisterGATTService() error {
+	lba.radioMutex.Lock()
+	registered, app := lba.gattRegistered, lba.gattApp
+	lba.radioMutex.Unlock()
+
+	if registered {
+		return nil
+	}
+	if app != nil {
+		return lba.reregisterGATTService(app)
+	}
+
+	app, err := service.NewApp(service.AppOptions{AdapterID: lba.adapterID})
+	if err != nil {
+		return fmt.Errorf("erro ao criar aplicação GATT: %v", err)
+	}
+
+	// Os UUIDs já são completos, sem base ou sufixo a gerar
+	app.Options.UUID = ""
+	app.Options.UUIDSuffix = ""
+
+	svc, err := app.NewService(ServiceUUID)
+	if err != nil {
+		app.Close()
+		return fmt.Errorf("erro ao criar serviço GATT: %v", err)
+	}
+	if err := app.AddService(svc); err != nil {
+		app.Close()
+		return fmt.Errorf("erro ao adicionar serviço GATT: %v", err)
+	}
+
+	rx, err := lba.newGATTCharacteristic(svc, CharacteristicUUID,
+		gatt.FlagCharacteristicWrite,
+		gatt.FlagCharacteristicWriteWithoutResponse,
+	)
+	if err != nil {
+		app.Close()
+		return err
+	}
+
+	// O callback de escrita da biblioteca não informa o central de origem,
+	// então a característica é reexportada com um WriteValue que o repassa
+	writable := &writableChar{Char: rx, onWrite: lba.handleGATTWrite}
+	if err := app.DBusConn().Export(writable, rx.Path(), gatt.GattCharacteristic1Interface); err != nil {
+		app.Close()
+		return fmt.Errorf("erro ao exportar característica %s: %v", CharacteristicUUID, err)
+	}
+
+	tx, err := lba.newGATTCharacteristic(svc, NotifyCharacteristicUUID,
+		gatt.FlagCharacteristicRead,
+		gatt.FlagCharacteristicNotify,
+	)
+	if err != nil {
+		app.Close()
+		return err
+	}
+
+	// Centrais que só se conectam de tempos em tempos leem os pacotes
+	// guardados para eles, um por leitura
+	mailbox, err := lba.newGATTCharacteristic(svc, MailboxCharacteristicUUID,
+		gatt.FlagCharacteristicRead,
+	)
+	if err != nil {
+		app.Close()
+		return err
+	}
+	mailbox.OnRead(func(c *service.Char, options map[string]interface{}) ([]byte, error) {
+		return lba.handleMailboxRead(optionDevice(options), optionOffset(options)), nil
+	})
+
+	if err := lba.bluezCall(app.Run()); err != nil {
+		app.Close()
+		return fmt.Errorf("erro ao registrar aplicação GATT: %v", err)
+	}
+
+	lba.radioMutex.Lock()
+	lba.gattApp = app
+	lba.gattTX = tx
+	lba.gattRegistered = true
+	lba.radioMutex.Unlock()
+	return nil
+}
+
+// reregisterGATTService registra de novo no BlueZ a aplicação GATT já
+// exportada, cujo registro se perde quando o daemon reinicia
+func (lba *LinuxBluetoothAdapter) reregisterGATTService(app *service.App) error {
+	manager, err := gatt.NewGattManager1FromAdapterID(lba.adapterID)
+	if err != nil {
+		return fmt.Errorf("erro ao obter gerenciador GATT: %v", err)
+	}
+
+	err = manager.RegisterApplication(app.Path(), map[string]interface{}{})
+	if err != nil && !strings.Contains(err.Error(), "org.bluez.Error.AlreadyExists") {
+		return fmt.Errorf("erro ao registrar aplicação GATT: %v", lba.bluezCall(err))
+	}
+
+	lba.radioMutex.Lock()
+	lba.gattRegistered = true
+	lba.radioMutex.Unlock()
+	return nil
+}
+
+// NotifyData atualiza o valor da característica TX, que o BlueZ entrega
+// como notificação aos centrais inscritos
+func (lba *LinuxBluetoothAdapter) NotifyData(data []byte) error {
+	lba.radioMutex.Lock()
+	tx, registered := lba.gattTX, lba.gattRegistered
+	lba.radioMutex.Unlock()
+
+	if tx == nil || !registered {
+		return fmt.Errorf("serviço GATT não registrado")
+	}
+
+	value := make([]byte, len(data))
+	copy(value, data)
+	tx.Properties.Value = value
+	if err := tx.DBusProperties().Instance().Set(gatt.GattCharacteristic1Interface, "Value", dbus.MakeVariant(value)); err != nil {
+		return fmt.Errorf("erro ao notificar característica TX: %v", err)
+	}
+	return nil
+}
+
+// newGATTCharacteristic cria e adiciona uma característica ao serviço GATT
+func (lba *LinuxBluetoothAdapter) newGATTCharacteristic(svc *service.Service, uuid string, flags ...string) (*service.Char, error) {
+	char, err := svc.NewChar(uuid)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar característica %s: %v", uuid, err)
+	}
+	char.Properties.Flags = flags
+
+	if err := svc.AddChar(char); err != nil {
+		return nil, fmt.Errorf("erro ao adicionar característica %s: %v", uuid, err)
+	}
+	return char, nil
+}
+
+// GATTRegistered informa se o serviço Bitchat está registrado no BlueZ
+func (lba *LinuxBluetoothAdapter) GATTRegistered() bool {
+	lba.radioMutex.Lock()
+	defer lba.radioMutex.Unlock()
+
+	return lba.gattRegistered
+}
+
+// handleGATTWrite repassa os dados escritos por um central na característica
+// de dados
+func (lba *LinuxBluetoothAdapter) handleGATTWrite(devicePath string, value []byte) {
+	address := addressFromDevicePath(devicePath)
+	if address == "" || len(value) == 0 {
+		return
+	}
+
+	data := make([]byte, len(value))
+	copy(data, value)
+	if lba.onDataReceived != nil {
+		lba.onDataReceived(data, address)
+	}
+}
+
+// closeGATTService remove a aplicação GATT do BlueZ
+func (lba *LinuxBluetoothAdapter) closeGATTService() {
+	lba.radioMutex.Lock()
+	app := lba.gattApp
+	lba.gattApp = nil
+	lba.gattTX = nil
+	lba.gattRegistered = false
+	lba.radioMutex.Unlock()
+
+	if app != nil {
+		app.Close()
+	}
+}
+
+// writableChar expõe uma característica GATT repassando as escritas recebidas
+// junto com o dispositivo que as originou
+type writableChar struct {
+	*service.Char
+	onWrite func(devicePath string, value []byte)
+}
+
+// WriteValue implementa org.bluez.GattCharacteristic1.WriteValue
+func (c *writableChar) WriteValue(value []byte, options map[string]interface{}) *dbus.Error {
+	c.onWrite(optionDevice(options), value)
+	return nil
+}
+
+// optionDevice extrai o caminho do dispositivo das opções de uma operação GATT
+func optionDevice(options map[string]interface{}) string {
+	switch device := options["device"].(type) {
+	case dbus.ObjectPath:
+		return string(device)
+	case dbus.Variant:
+		if path, ok := device.Value().(dbus.ObjectPath); ok {
+			return string(path)
+		}
+	}
+	return ""
+}
+
+// optionOffset extrai o deslocamento das opções de uma leitura GATT, usado
+// pelo BlueZ ao ler valores maiores que o MTU em partes
+func optionOffset(options map[string]interface{}) int {
+	value := options["offset"]
+	if variant, ok := value.(dbus.Variant); ok {
+		value = variant.Value()
+	}
+	if offset, ok := value.(uint16); ok {
+		return int(offset)
+	}
+	return 0
+}
+
+// addressFromDevicePath extrai o endereço BLE do caminho de um dispositivo no
+// BlueZ (ex.: /org/bluez/hci0/dev_AA_BB_CC_DD_EE_FF)
+func addressFromDevicePath(path string) string {
+	index := strings.LastIndex(path, "/dev_")
+	if index < 0 {
+		return ""
+	}
+	return strings.ReplaceAll(path[index+len("/dev_"):], "_", ":")
+}
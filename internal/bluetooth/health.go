@@ -0,0 +1,177 @@
+package bluetooth
+
+import (
+	"fmt"
+	"time"
+)
+
+// RecoveryStatus descreve a etapa de uma recuperação automática do BlueZ
+type RecoveryStatus int
+
+const (
+	RecoveryStarted   RecoveryStatus = iota // Falha do BlueZ detectada; recuperação iniciada
+	RecoveryRetrying                        // Uma tentativa falhou; outra será feita
+	RecoverySucceeded                       // Adaptador religado e mesh retomada
+	RecoveryFailed                          // Tentativas esgotadas; mesh parada
+)
+
+// String retorna o nome da etapa da recuperação
+func (rs RecoveryStatus) String() string {
+	switch rs {
+	case RecoveryStarted:
+		return "iniciada"
+	case RecoveryRetrying:
+		return "repetindo"
+	case RecoverySucceeded:
+		return "concluída"
+	case RecoveryFailed:
+		return "falhou"
+	default:
+		return "desconhecida"
+	}
+}
+
+// HealthEvent informa uma etapa da recuperação automática do transporte
+// Bluetooth, para que a mesh não fique parada silenciosamente
+type HealthEvent struct {
+	Status  RecoveryStatus
+	Reason  string // O que disparou a recuperação
+	Attempt int    // Tentativa atual (zero ao iniciar)
+	Err     error  // Erro da última tentativa, se houver
+}
+
+// HealthDelegate pode ser implementado pelo delegate para ser notificado
+// das recuperações automáticas do BlueZ
+type HealthDelegate interface {
+	OnHealthEvent(event HealthEvent)
+}
+
+// reportHealthEvent repassa aos inscritos um evento de recuperação informado
+// pelo provedor da plataforma
+func (bms *BluetoothMeshService) reportHealthEvent(event HealthEvent) {
+	bms.mutex.Lock()
+	bms.lastRecovery = &event
+	bms.mutex.Unlock()
+
+	bms.events.publish(topicTransport, TransportEvent{Type: TransportRecovery, Recovery: event})
+}
+
+// HealthRetryBacklogLimit é o número de mensagens aguardando confirmação ou
+// transmissão a partir do qual o nó é considerado degradado
+const HealthRetryBacklogLimit = 100
+
+// Nomes dos componentes verificados por CheckHealth
+const (
+	HealthComponentAdapter     = "adaptador"
+	HealthComponentAdvertising = "advertising"
+	HealthComponentGATT        = "gatt"
+	HealthComponentScanning    = "escaneamento"
+	HealthComponentBacklog     = "fila de reenvio"
+)
+
+// HealthStatus é a situação de um componente do nó
+type HealthStatus int
+
+const (
+	HealthOK       HealthStatus = iota // Funcionando
+	HealthUnknown                      // Não informado pela plataforma
+	HealthDegraded                     // Funcionando com restrições
+	HealthFailed                       // Parado
+)
+
+// String retorna o nome da situação
+func (hs HealthStatus) String() string {
+	switch hs {
+	case HealthOK:
+		return "ok"
+	case HealthDegraded:
+		return "degradado"
+	case HealthFailed:
+		return "falhou"
+	default:
+		return "desconhecido"
+	}
+}
+
+// ComponentHealth é a situação de um componente do nó
+type ComponentHealth struct {
+	Name   string
+	Status HealthStatus
+	Detail string // Detalhe ou motivo da situação
+}
+
+// HealthReport é a situação de todos os componentes verificados
+type HealthReport struct {
+	Components []ComponentHealth
+	CheckedAt  time.Time
+}
+
+// Status retorna a pior situação entre os componentes; componentes não
+// informados pela plataforma não degradam o nó
+func (hr HealthReport) Status() HealthStatus {
+	status := HealthOK
+	for _, component := range hr.Components {
+		if component.Status != HealthUnknown && component.Status > status {
+			status = component.Status
+		}
+	}
+	return status
+}
+
+// Add acrescenta um componente verificado fora do serviço mesh, como o
+// armazenamento da aplicação
+func (hr *HealthReport) Add(component ComponentHealth) {
+	hr.Components = append(hr.Components, component)
+}
+
+// TransportHealthProvider é implementado por provedores capazes de informar
+// a situação do advertising, do servidor GATT ou de outros componentes do
+// transporte
+type TransportHealthProvider interface {
+	TransportHealth() []ComponentHealth
+}
+
+// CheckHealth verifica o adaptador, o transporte e a fila de reenvio
+func (bms *BluetoothMeshService) CheckHealth() HealthReport {
+	report := HealthReport{CheckedAt: time.Now()}
+
+	bms.mutex.RLock()
+	recovery := bms.lastRecovery
+	platformProvider := bms.platformProvider
+	bms.mutex.RUnlock()
+
+	adapter := ComponentHealth{Name: HealthComponentAdapter, Status: HealthOK}
+	switch state := bms.GetRadioState(); {
+	case recovery != nil && recovery.Status == RecoveryFailed:
+		adapter.Status, adapter.Detail = HealthFailed, fmt.Sprintf("recuperação falhou: %v", recovery.Err)
+	case recovery != nil && recovery.Status != RecoverySucceeded:
+		adapter.Status, adapter.Detail = HealthDegraded, "recuperação em andamento: "+recovery.Reason
+	case state != RadioStateOn:
+		adapter.Status, adapter.Detail = HealthFailed, "rádio "+state.String()
+	}
+	report.Add(adapter)
+
+	// O provedor informa o que conhece; o restante fica como desconhecido
+	reported := map[string]bool{}
+	if provider, ok := platformProvider.(TransportHealthProvider); ok {
+		for _, component := range provider.TransportHealth() {
+			reported[component.Name] = true
+			report.Add(component)
+		}
+	}
+	for _, name := range []string{HealthComponentAdvertising, HealthComponentGATT} {
+		if !reported[name] {
+			report.Add(ComponentHealth{Name: name, Status: HealthUnknown, Detail: "não informado pela plataforma"})
+		}
+	}
+
+	backlog := ComponentHealth{Name: HealthComponentBacklog, Status: HealthOK}
+	pending := bms.PendingRetries() + len(bms.OutgoingMessages())
+	if pending >= HealthRetryBacklogLimit {
+		backlog.Status = HealthDegraded
+	}
+	backlog.Detail = fmt.Sprintf("%d mensagens pendentes", pending)
+	report.Add(backlog)
+
+	return report
+}
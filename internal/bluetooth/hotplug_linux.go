@@ -0,0 +1,135 @@
+package bluetooth
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/muka/go-bluetooth/bluez"
+	"github.com/muka/go-bluetooth/bluez/profile/adapter"
+)
+
+// AdapterRetryInterval é o intervalo entre tentativas de religar um
+// adaptador reinserido que ainda não está pronto
+const AdapterRetryInterval = 2 * time.Second
+
+// SetOnRadioStateChanged define o callback chamado quando o rádio é
+// desligado, bloqueado, removido ou volta a ficar disponível
+func (lba *LinuxBluetoothAdapter) SetOnRadioStateChanged(callback func(state RadioState)) {
+	lba.onRadioStateChanged = callback
+}
+
+// AdapterID retorna o adaptador BlueZ em uso
+func (lba *LinuxBluetoothAdapter) AdapterID() string {
+	return lba.adapterID
+}
+
+// hotplugLoop acompanha os sinais InterfacesAdded/InterfacesRemoved do
+// BlueZ para detectar a remoção e a reinserção do adaptador em uso
+func (lba *LinuxBluetoothAdapter) hotplugLoop() {
+	conn, err := bluez.GetConnection(bluez.SystemBus)
+	if err != nil {
+		slog.Error("erro ao acompanhar adaptadores Bluetooth", "err", err)
+		return
+	}
+
+	options := []dbus.MatchOption{
+		dbus.WithMatchSender(bluez.OrgBluezInterface),
+		dbus.WithMatchInterface(bluez.ObjectManagerInterface),
+	}
+	if err := conn.AddMatchSignal(options...); err != nil {
+		slog.Error("erro ao acompanhar adaptadores Bluetooth", "err", err)
+		return
+	}
+	defer conn.RemoveMatchSignal(options...)
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	adapterPath := dbus.ObjectPath("/org/bluez/" + lba.adapterID)
+
+	for {
+		select {
+		case <-lba.ctx.Done():
+			return
+		case sig := <-signals:
+			if sig == nil || len(sig.Body) < 2 {
+				continue
+			}
+			path, ok := sig.Body[0].(dbus.ObjectPath)
+			if !ok || path != adapterPath {
+				continue
+			}
+
+			switch sig.Name {
+			case bluez.InterfacesAdded:
+				ifaces, ok := sig.Body[1].(map[string]map[string]dbus.Variant)
+				if ok && ifaces[adapter.Adapter1Interface] != nil {
+					lba.handleAdapterAdded()
+				}
+			case bluez.InterfacesRemoved:
+				ifaces, _ := sig.Body[1].([]string)
+				for _, iface := range ifaces {
+					if iface == adapter.Adapter1Interface {
+						lba.handleAdapterRemoved()
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// handleAdapterRemoved descarta o estado ligado ao adaptador removido até
+// que ele seja reinserido
+func (lba *LinuxBluetoothAdapter) handleAdapterRemoved() {
+	slog.Warn("adaptador Bluetooth removido", "adapter", lba.adapterID)
+
+	lba.setRadioState(RadioStateUnavailable)
+	lba.forgetDevices()
+}
+
+// forgetDevices descarta os objetos dos dispositivos, que pertenciam ao
+// adaptador removido ou ao BlueZ encerrado
+func (lba *LinuxBluetoothAdapter) forgetDevices() {
+	lba.deviceMutex.Lock()
+	defer lba.deviceMutex.Unlock()
+
+	for path := range lba.devices {
+		lba.connections.Forget(path)
+		delete(lba.devices, path)
+	}
+}
+
+// handleAdapterAdded religa o adaptador reinserido. Logo após a inserção o
+// BlueZ pode ainda não aceitar comandos, então a operação é repetida por
+// algumas vezes antes de desistir.
+func (lba *LinuxBluetoothAdapter) handleAdapterAdded() {
+	var err error
+	for attempt := 0; attempt < 5; attempt++ {
+		if err = lba.attachAdapter(); err == nil {
+			break
+		}
+
+		// Um rádio bloqueado não liga até o rfkill ser liberado
+		if rfkillBlocked(lba.adapterID) {
+			lba.setRadioState(RadioStateBlocked)
+			return
+		}
+
+		select {
+		case <-lba.ctx.Done():
+			return
+		case <-time.After(AdapterRetryInterval):
+		}
+	}
+	if err != nil {
+		slog.Error("erro ao religar adaptador Bluetooth", "err", err)
+		lba.setRadioState(RadioStateOff)
+		return
+	}
+
+	slog.Info("adaptador Bluetooth disponível", "adapter", lba.adapterID)
+	lba.setRadioState(RadioStateOn)
+}
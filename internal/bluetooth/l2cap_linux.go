@@ -0,0 +1,203 @@
+package bluetooth
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// BulkChannelPSM é o PSM LE dinâmico em que o Bitchat aceita canais
+	// L2CAP orientados a conexão para tráfego volumoso
+	BulkChannelPSM = 0x0085
+
+	// BulkChannelMTU é o maior SDU trocado por um canal L2CAP
+	BulkChannelMTU = 65535
+
+	btRcvMTU = 13 // Opção BT_RCVMTU de SOL_BLUETOOTH
+)
+
+// L2CAPChannel é um canal L2CAP LE orientado a conexão (CoC) com um peer.
+// O socket é SOCK_SEQPACKET, então cada escrita chega inteira ao outro lado
+// e pacotes grandes dispensam a fragmentação usada no GATT.
+type L2CAPChannel struct {
+	fd        int
+	address   string
+	closeOnce sync.Once
+}
+
+// DialL2CAP abre um canal L2CAP com o dispositivo no endereço informado
+func DialL2CAP(address string, randomAddress bool, psm uint16) (*L2CAPChannel, error) {
+	addr, err := parseBluetoothAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := newL2CAPSocket(0)
+	if err != nil {
+		return nil, err
+	}
+
+	addrType := uint8(unix.BDADDR_LE_PUBLIC)
+	if randomAddress {
+		addrType = unix.BDADDR_LE_RANDOM
+	}
+	if err := unix.Connect(fd, &unix.SockaddrL2{PSM: psm, Addr: addr, AddrType: addrType}); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("erro ao conectar canal L2CAP: %v", err)
+	}
+
+	return &L2CAPChannel{fd: fd, address: address}, nil
+}
+
+// newL2CAPSocket cria um socket L2CAP LE com o MTU de canais volumosos,
+// associado ao PSM informado (0 para canais de saída)
+func newL2CAPSocket(psm uint16) (int, error) {
+	fd, err := unix.Socket(unix.AF_BLUETOOTH, unix.SOCK_SEQPACKET, unix.BTPROTO_L2CAP)
+	if err != nil {
+		return -1, fmt.Errorf("erro ao criar socket L2CAP: %v", err)
+	}
+
+	// O socket precisa estar associado a um endereço LE para abrir canais CoC
+	if err := unix.Bind(fd, &unix.SockaddrL2{PSM: psm, AddrType: unix.BDADDR_LE_PUBLIC}); err != nil {
+		unix.Close(fd)
+		return -1, fmt.Errorf("erro ao associar socket L2CAP: %v", err)
+	}
+
+	mtu := string(binary.NativeEndian.AppendUint16(nil, BulkChannelMTU))
+	if err := unix.SetsockoptString(fd, unix.SOL_BLUETOOTH, btRcvMTU, mtu); err != nil {
+		unix.Close(fd)
+		return -1, fmt.Errorf("erro ao configurar MTU L2CAP: %v", err)
+	}
+
+	return fd, nil
+}
+
+// Address retorna o endereço do peer
+func (c *L2CAPChannel) Address() string {
+	return c.address
+}
+
+// Write envia um SDU pelo canal
+func (c *L2CAPChannel) Write(data []byte) error {
+	if len(data) > BulkChannelMTU {
+		return fmt.Errorf("dados excedem o MTU do canal L2CAP: %d bytes", len(data))
+	}
+
+	n, err := unix.Write(c.fd, data)
+	if err != nil {
+		return fmt.Errorf("erro ao escrever no canal L2CAP: %v", err)
+	}
+	if n != len(data) {
+		return fmt.Errorf("escrita incompleta no canal L2CAP: %d de %d bytes", n, len(data))
+	}
+	return nil
+}
+
+// Read recebe um SDU do canal. Retorna 0 quando o peer fecha o canal.
+func (c *L2CAPChannel) Read(buf []byte) (int, error) {
+	n, err := unix.Read(c.fd, buf)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao ler do canal L2CAP: %v", err)
+	}
+	return n, nil
+}
+
+// Close fecha o canal, desbloqueando leituras pendentes
+func (c *L2CAPChannel) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		unix.Shutdown(c.fd, unix.SHUT_RDWR)
+		err = unix.Close(c.fd)
+	})
+	return err
+}
+
+// L2CAPListener aceita canais L2CAP abertos pelos peers
+type L2CAPListener struct {
+	fd        int
+	closeOnce sync.Once
+}
+
+// ListenL2CAP passa a aceitar canais L2CAP no PSM informado
+func ListenL2CAP(psm uint16) (*L2CAPListener, error) {
+	fd, err := newL2CAPSocket(psm)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unix.Listen(fd, 4); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("erro ao escutar canais L2CAP: %v", err)
+	}
+
+	return &L2CAPListener{fd: fd}, nil
+}
+
+// Accept aguarda o próximo canal aberto por um peer
+func (l *L2CAPListener) Accept() (*L2CAPChannel, error) {
+	fd, sa, err := unix.Accept(l.fd)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao aceitar canal L2CAP: %v", err)
+	}
+
+	address := ""
+	if l2, ok := sa.(*unix.SockaddrL2); ok {
+		address = formatBluetoothAddress(l2.Addr)
+	}
+	return &L2CAPChannel{fd: fd, address: address}, nil
+}
+
+// Close para de aceitar canais, desbloqueando um Accept pendente
+func (l *L2CAPListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		unix.Shutdown(l.fd, unix.SHUT_RDWR)
+		err = unix.Close(l.fd)
+	})
+	return err
+}
+
+// parseBluetoothAddress converte um endereço no formato AA:BB:CC:DD:EE:FF
+func parseBluetoothAddress(address string) ([6]uint8, error) {
+	var addr [6]uint8
+
+	parts := strings.Split(address, ":")
+	if len(parts) != len(addr) {
+		return addr, fmt.Errorf("endereço Bluetooth inválido: %s", address)
+	}
+	for i, part := range parts {
+		value, err := strconv.ParseUint(part, 16, 8)
+		if err != nil {
+			return addr, fmt.Errorf("endereço Bluetooth inválido: %s", address)
+		}
+		addr[i] = uint8(value)
+	}
+	return addr, nil
+}
+
+// formatBluetoothAddress formata um endereço como AA:BB:CC:DD:EE:FF
+func formatBluetoothAddress(addr [6]uint8) string {
+	parts := make([]string, len(addr))
+	for i, b := range addr {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// usesBulkChannel decide se um pacote direcionado deve seguir pelo canal
+// L2CAP: transferências de arquivos, sincronização de histórico e qualquer
+// pacote que precisaria ser fragmentado no GATT
+func usesBulkChannel(packet *protocol.BitchatPacket, size int, mtu int) bool {
+	switch packet.Type {
+	case protocol.MessageTypeSyncRequest, protocol.MessageTypeSyncSummary:
+		return true
+	}
+	return mesh.PacketPriority(packet) == mesh.PriorityFile || needsFragmentation(size, mtu)
+}
@@ -3,104 +3,215 @@ package bluetooth
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/godbus/dbus/v5"
 	"github.com/muka/go-bluetooth/api"
+	"github.com/muka/go-bluetooth/api/service"
 	"github.com/muka/go-bluetooth/bluez/profile/adapter"
 	"github.com/muka/go-bluetooth/bluez/profile/advertising"
+	"github.com/muka/go-bluetooth/bluez/profile/agent"
 	"github.com/muka/go-bluetooth/bluez/profile/device"
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
 )
 
+// ConnectionCheckInterval é o intervalo entre avaliações do gerenciador de conexões
+const ConnectionCheckInterval = 5 * time.Second
+
 // LinuxBluetoothAdapter implementa a funcionalidade BLE específica para Linux
 type LinuxBluetoothAdapter struct {
+	adapterID         string // Adaptador BlueZ em uso (ex.: hci0)
 	adapter           *adapter.Adapter1
 	adMgr             *advertising.LEAdvertisingManager1
 	advertisement     *advertising.LEAdvertisement1
 	devices           map[string]*device.Device1
+	linkMTUs          map[string]int // Endereço -> MTU negociado
+	notifications     map[string]func() // Endereço -> cancelamento da inscrição na TX do peer
+	mailboxReads      map[string][]byte // Endereço -> pacote da caixa de correio em leitura pelo central
+	deviceWatches     map[string]func() // Caminho -> fim do acompanhamento das propriedades
+	deviceRSSI        map[string]int    // Endereço -> último RSSI recebido
+	writeQueue        *mesh.WriteQueue // Escritas GATT serializadas por dispositivo
+	connections       *mesh.ConnectionManager
+	dialing           map[string]bool // Dispositivos com conexão em andamento
+	bulkChannels      map[string]*L2CAPChannel // Endereço -> canal L2CAP para tráfego volumoso
+	bulkListener      *L2CAPListener
+	bondAddresses     map[string]bool // Endereços com vínculo BLE habilitado
+	bondAgent         *agent.SimpleAgent
 	deviceMutex       sync.RWMutex
 	onDataReceived    func([]byte, string)
+	onMailboxRead     func(address string) []byte // Próximo pacote guardado para o central que lê a caixa de correio
+	onAnnounce        func(address string, announce *protocol.CompactAnnounce) bool // Decide se o dispositivo anunciado é preferido
 	ctx               context.Context
 	cancel            context.CancelFunc
-	isScanning        bool
-	isAdvertising     bool
+	isScanning        bool // Guardado por radioMutex
+	isAdvertising     bool // Guardado por radioMutex
+	extendedAdvertising bool // Controlador suporta advertising estendido (BLE 5)
 	cleanupAdvertisement func()
+	gattApp           *service.App  // Aplicação GATT exportada com o serviço Bitchat
+	gattTX            *service.Char // Característica TX notificada aos centrais inscritos
+	gattRegistered    bool          // Aplicação GATT registrada no BlueZ
+	profile           RadioProfile  // Comportamento do rádio no modo de bateria atual
+	localPeerUUID     string        // UUID de identidade anunciado junto de ServiceUUID
+	scanWhitelist     []string      // UUIDs de identidade aceitos na descoberta filtrada
+	connectionConfig  ConnectionConfig // Limites e intervalos de conexão configurados
+	scanPaused        bool          // Descoberta suspensa fora da janela de escaneamento
+	profileChanged    chan struct{} // Sinaliza ao ciclo de escaneamento uma troca de perfil
+	radioMutex        sync.Mutex
+	stopDiscovery     func()           // Encerra a descoberta iniciada por StartScanning
+	radioState        RadioState            // Disponibilidade atual do rádio
+	onRadioStateChanged func(state RadioState) // Notificado quando o rádio muda de estado
+	bluezFailures     int                     // Falhas seguidas do BlueZ com o rádio ligado
+	recovering        bool                    // Recuperação do BlueZ em andamento
+	onHealthEvent     func(event HealthEvent) // Notificado a cada etapa da recuperação
+}
+
+// NewLinuxBluetoothAdapter cria um novo adaptador BLE para Linux. Um
+// adapterID vazio seleciona o adaptador padrão do BlueZ.
+func NewLinuxBluetoothAdapter(adapterID string) (*LinuxBluetoothAdapter, error) {
+	if adapterID == "" {
+		adapterID = adapter.GetDefaultAdapterID()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lba := &LinuxBluetoothAdapter{
+		adapterID:   adapterID,
+		devices:     make(map[string]*device.Device1),
+		linkMTUs:    make(map[string]int),
+		notifications: make(map[string]func()),
+		mailboxReads: make(map[string][]byte),
+		deviceWatches: make(map[string]func()),
+		deviceRSSI:  make(map[string]int),
+		writeQueue:  mesh.NewWriteQueue(),
+		connections: mesh.NewConnectionManager(mesh.DefaultTargetConnections, mesh.DefaultLinkIdleTimeout),
+		dialing:     make(map[string]bool),
+		bulkChannels: make(map[string]*L2CAPChannel),
+		bondAddresses: make(map[string]bool),
+		profile:     RadioProfileForMode(BatteryModeNormal),
+		profileChanged: make(chan struct{}, 1),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+
+	if err := lba.attachAdapter(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go lba.connectionLoop()
+	go lba.scanDutyLoop()
+	go lba.hotplugLoop()
+	go lba.radioStateLoop()
+	go lba.bluezOwnerLoop()
+
+	return lba, nil
 }
 
-// NewLinuxBluetoothAdapter cria um novo adaptador BLE para Linux
-func NewLinuxBluetoothAdapter() (*LinuxBluetoothAdapter, error) {
-	// Obter adaptador padrão
-	a, err := api.GetDefaultAdapter()
+// attachAdapter obtém o adaptador configurado, liga o rádio e prepara o
+// gerenciador de advertising. É chamado na criação e sempre que o
+// adaptador volta a aparecer no BlueZ.
+func (lba *LinuxBluetoothAdapter) attachAdapter() error {
+	a, err := adapter.GetAdapter(lba.adapterID)
+	if err != nil {
+		return fmt.Errorf("erro ao obter adaptador Bluetooth %s: %v", lba.adapterID, err)
+	}
+
+	// Obter gerenciador de advertising
+	adMgr, err := advertising.NewLEAdvertisingManager1(a.Path())
 	if err != nil {
-		return nil, fmt.Errorf("erro ao obter adaptador Bluetooth: %v", err)
+		return fmt.Errorf("erro ao criar gerenciador de advertising: %v", err)
 	}
 
+	lba.adapter = a
+	lba.adMgr = adMgr
+
 	// Verificar se o adaptador está ligado
 	powered, err := a.GetPowered()
 	if err != nil {
-		return nil, fmt.Errorf("erro ao verificar estado do adaptador: %v", err)
+		return fmt.Errorf("erro ao verificar estado do adaptador: %v", err)
 	}
 
 	if !powered {
 		// Tentar ligar o adaptador
 		if err := a.SetPowered(true); err != nil {
-			return nil, fmt.Errorf("erro ao ligar adaptador Bluetooth: %v", err)
+			return fmt.Errorf("erro ao ligar adaptador Bluetooth: %v", err)
 		}
 	}
 
-	// Obter gerenciador de advertising
-	adMgr, err := advertising.NewLEAdvertisingManager1(a.Path())
-	if err != nil {
-		return nil, fmt.Errorf("erro ao criar gerenciador de advertising: %v", err)
-	}
+	// Canais secundários só são anunciados por controladores com advertising estendido
+	channels, err := adMgr.GetSupportedSecondaryChannels()
+	lba.extendedAdvertising = err == nil && len(channels) > 0
 
-	ctx, cancel := context.WithCancel(context.Background())
+	// Reaplicar o supervision timeout do perfil atual
+	if err := lba.applySupervisionTimeout(lba.radioProfile().SupervisionTimeout); err != nil {
+		slog.Error("erro ao aplicar perfil do rádio", "err", err)
+	}
 
-	return &LinuxBluetoothAdapter{
-		adapter:     a,
-		adMgr:       adMgr,
-		devices:     make(map[string]*device.Device1),
-		ctx:         ctx,
-		cancel:      cancel,
-	}, nil
+	// Reaplicar o intervalo de conexão configurado
+	lba.radioMutex.Lock()
+	config := lba.connectionConfig
+	lba.radioMutex.Unlock()
+	if err := lba.applyConnectionIntervals(config.MinInterval, config.MaxInterval); err != nil {
+		slog.Error("erro ao aplicar intervalo de conexão", "err", err)
+	}
+	return nil
 }
 
 // StartScanning inicia o escaneamento por dispositivos BLE
 func (lba *LinuxBluetoothAdapter) StartScanning() error {
-	if lba.isScanning {
+	if lba.IsScanning() {
 		return nil
 	}
 
 	// Configurar filtro de descoberta
-	filter := adapter.NewDiscoveryFilter()
-	filter.Transport = "le"
-	filter.UUIDs = []string{ServiceUUID}
+	if err := lba.applyDiscoveryFilter(); err != nil {
+		return err
+	}
 
-	if err := lba.adapter.SetDiscoveryFilter(filter.ToMap()); err != nil {
-		return fmt.Errorf("erro ao configurar filtro de descoberta: %v", err)
+	if err := lba.bluezCall(lba.adapter.StartDiscovery()); err != nil {
+		return fmt.Errorf("erro ao iniciar descoberta: %v", err)
 	}
 
 	// Registrar callback para novos dispositivos
-	discovery, cancel, err := api.Discover(lba.adapter, nil)
+	discovery, cancel, err := lba.adapter.OnDeviceDiscovered()
 	if err != nil {
+		lba.adapter.StopDiscovery()
 		return fmt.Errorf("erro ao iniciar descoberta: %v", err)
 	}
 
-	lba.isScanning = true
+	scanCtx, stop := context.WithCancel(lba.ctx)
+	lba.stopDiscovery = stop
+	lba.setScanning(true)
 
 	// Processar dispositivos descobertos em goroutine
 	go func() {
 		defer cancel()
 
+		// Dispositivos já no cache do BlueZ não geram InterfacesAdded
+		if devices, err := lba.adapter.GetDevices(); err == nil {
+			for _, dev := range devices {
+				lba.handleDeviceFound(string(dev.Path()), dev)
+			}
+		}
+
 		for {
 			select {
-			case <-lba.ctx.Done():
+			case <-scanCtx.Done():
 				return
-			case ev := <-discovery:
+			case ev, ok := <-discovery:
+				if !ok {
+					return
+				}
 				if ev.Type == adapter.DeviceRemoved {
-					lba.deviceMutex.Lock()
-					delete(lba.devices, string(ev.Path))
-					lba.deviceMutex.Unlock()
+					lba.forgetDevice(string(ev.Path))
 					continue
 				}
 
@@ -111,23 +222,10 @@ func (lba *LinuxBluetoothAdapter) StartScanning() error {
 				// Novo dispositivo encontrado
 				dev, err := device.NewDevice1(ev.Path)
 				if err != nil {
-					fmt.Printf("Erro ao criar objeto de dispositivo: %v\n", err)
-					continue
-				}
-
-				// Verificar se o dispositivo oferece o serviço Bitchat
-				uuids, err := dev.GetUUIDs()
-				if err != nil || !containsUUID(uuids, ServiceUUID) {
+					slog.Error("erro ao criar objeto de dispositivo", "err", err)
 					continue
 				}
-
-				// Armazenar dispositivo
-				lba.deviceMutex.Lock()
-				lba.devices[string(ev.Path)] = dev
-				lba.deviceMutex.Unlock()
-
-				// Conectar ao dispositivo
-				go lba.connectToDevice(dev)
+				lba.handleDeviceFound(string(ev.Path), dev)
 			}
 		}
 	}()
@@ -137,28 +235,66 @@ func (lba *LinuxBluetoothAdapter) StartScanning() error {
 
 // StopScanning para o escaneamento por dispositivos
 func (lba *LinuxBluetoothAdapter) StopScanning() error {
-	if !lba.isScanning {
+	if !lba.IsScanning() {
 		return nil
 	}
 
-	if err := lba.adapter.StopDiscovery(); err != nil {
-		return fmt.Errorf("erro ao parar descoberta: %v", err)
+	// Fora da janela de escaneamento a descoberta já está parada
+	lba.radioMutex.Lock()
+	paused := lba.scanPaused
+	lba.scanPaused = false
+	lba.radioMutex.Unlock()
+
+	if !paused {
+		if err := lba.adapter.StopDiscovery(); err != nil {
+			return fmt.Errorf("erro ao parar descoberta: %v", err)
+		}
 	}
 
-	lba.isScanning = false
+	lba.endDiscovery()
 	return nil
 }
 
+// endDiscovery encerra o processamento de dispositivos descobertos
+func (lba *LinuxBluetoothAdapter) endDiscovery() {
+	if lba.stopDiscovery != nil {
+		lba.stopDiscovery()
+		lba.stopDiscovery = nil
+	}
+	lba.setScanning(false)
+}
+
+// IsScanning informa se o escaneamento está ligado, mesmo que suspenso fora
+// da janela de escaneamento
+func (lba *LinuxBluetoothAdapter) IsScanning() bool {
+	lba.radioMutex.Lock()
+	defer lba.radioMutex.Unlock()
+
+	return lba.isScanning
+}
+
+// setScanning registra se o escaneamento está ligado
+func (lba *LinuxBluetoothAdapter) setScanning(scanning bool) {
+	lba.radioMutex.Lock()
+	defer lba.radioMutex.Unlock()
+
+	lba.isScanning = scanning
+}
+
 // StartAdvertising inicia o advertising BLE
 func (lba *LinuxBluetoothAdapter) StartAdvertising(deviceName string, serviceData []byte) error {
-	if lba.isAdvertising {
+	if lba.IsAdvertising() {
 		return nil
 	}
 
-	// Criar anúncio
+	// Criar anúncio com o intervalo do perfil de rádio atual
+	interval := uint32(lba.radioProfile().AdvertisingInterval.Milliseconds())
+	serviceUUIDs := []string{ServiceUUID}
 	props := &advertising.LEAdvertisement1Properties{
+		MinInterval: interval,
+		MaxInterval: interval,
 		Type:      advertising.AdvertisementTypeBroadcast,
-		ServiceUUIDs: []string{ServiceUUID},
+		ServiceUUIDs: serviceUUIDs,
 		LocalName: deviceName,
 		ServiceData: map[string]interface{}{
 			ServiceUUID: serviceData,
@@ -166,53 +302,172 @@ func (lba *LinuxBluetoothAdapter) StartAdvertising(deviceName string, serviceDat
 		Includes: []string{advertising.SupportedIncludesTxPower},
 	}
 
+	// O advertising estendido comporta bem mais que os 31 bytes do legado
+	// e também leva a identidade usada pela descoberta filtrada dos peers
+	if lba.extendedAdvertising {
+		props.SecondaryChannel = "1M"
+		lba.radioMutex.Lock()
+		if lba.localPeerUUID != "" {
+			props.ServiceUUIDs = append(serviceUUIDs, lba.localPeerUUID)
+		}
+		lba.radioMutex.Unlock()
+	}
+
 	// Registrar anúncio usando ExposeAdvertisement
 	adapterID, err := lba.adapter.GetAdapterID()
 	if err != nil {
 		return fmt.Errorf("erro ao obter ID do adaptador: %v", err)
 	}
 	cleanup, err := api.ExposeAdvertisement(adapterID, props, 0)
-	if err != nil {
+	if err := lba.bluezCall(err); err != nil {
 		return fmt.Errorf("erro ao criar anúncio: %v", err)
 	}
 
 	// Armazenar função de limpeza para uso posterior
 	lba.cleanupAdvertisement = cleanup
 
-	lba.isAdvertising = true
+	lba.setAdvertising(true)
 
 	return nil
 }
 
 // StopAdvertising para o advertising BLE
 func (lba *LinuxBluetoothAdapter) StopAdvertising() error {
-	if !lba.isAdvertising || lba.advertisement == nil {
+	if !lba.IsAdvertising() {
 		return nil
 	}
 
-	if err := lba.adMgr.UnregisterAdvertisement(lba.advertisement.Path()); err != nil {
-		return fmt.Errorf("erro ao cancelar anúncio: %v", err)
+	if lba.cleanupAdvertisement != nil {
+		lba.cleanupAdvertisement()
+		lba.cleanupAdvertisement = nil
+	} else if lba.advertisement != nil {
+		if err := lba.adMgr.UnregisterAdvertisement(lba.advertisement.Path()); err != nil {
+			return fmt.Errorf("erro ao cancelar anúncio: %v", err)
+		}
 	}
 
-	lba.isAdvertising = false
+	lba.setAdvertising(false)
 	return nil
 }
 
-// SendData envia dados para um dispositivo específico
-func (lba *LinuxBluetoothAdapter) SendData(data []byte, deviceID string) error {
-	lba.deviceMutex.RLock()
-	defer lba.deviceMutex.RUnlock()
+// IsAdvertising informa se o anúncio do serviço Bitchat está registrado
+func (lba *LinuxBluetoothAdapter) IsAdvertising() bool {
+	lba.radioMutex.Lock()
+	defer lba.radioMutex.Unlock()
 
-	// Encontrar dispositivo pelo ID
-	var targetDevice *device.Device1
-	for _, dev := range lba.devices {
-		addr, err := dev.GetAddress()
-		if err == nil && addr == deviceID {
-			targetDevice = dev
-			break
+	return lba.isAdvertising
+}
+
+// setAdvertising registra se o anúncio está registrado
+func (lba *LinuxBluetoothAdapter) setAdvertising(advertising bool) {
+	lba.radioMutex.Lock()
+	defer lba.radioMutex.Unlock()
+
+	lba.isAdvertising = advertising
+}
+
+// SetRadioProfile aplica um perfil de rádio. O ciclo de escaneamento e os
+// próximos anúncios passam a usar os novos intervalos; o supervision timeout
+// vale para as próximas conexões.
+func (lba *LinuxBluetoothAdapter) SetRadioProfile(profile RadioProfile) error {
+	lba.radioMutex.Lock()
+	filterChanged := lba.profile.WhitelistScan != profile.WhitelistScan
+	lba.profile = profile
+	lba.radioMutex.Unlock()
+
+	select {
+	case lba.profileChanged <- struct{}{}:
+	default:
+	}
+
+	// Entrar ou sair da descoberta filtrada troca o filtro do BlueZ
+	if filterChanged && lba.IsScanning() {
+		if err := lba.applyDiscoveryFilter(); err != nil {
+			return err
+		}
+	}
+
+	return lba.applySupervisionTimeout(profile.SupervisionTimeout)
+}
+
+// radioProfile retorna o perfil de rádio atual
+func (lba *LinuxBluetoothAdapter) radioProfile() RadioProfile {
+	lba.radioMutex.Lock()
+	defer lba.radioMutex.Unlock()
+
+	return lba.profile
+}
+
+// applySupervisionTimeout ajusta o supervision timeout das novas conexões.
+// O BlueZ não expõe o parâmetro via D-Bus, então o valor é escrito no
+// debugfs do controlador (em unidades de 10ms); sem debugfs montado ou sem
+// privilégios o padrão do controlador é mantido.
+func (lba *LinuxBluetoothAdapter) applySupervisionTimeout(timeout time.Duration) error {
+	adapterID, err := lba.adapter.GetAdapterID()
+	if err != nil {
+		return fmt.Errorf("erro ao obter ID do adaptador: %v", err)
+	}
+
+	path := filepath.Join("/sys/kernel/debug/bluetooth", adapterID, "supervision_timeout")
+	value := strconv.Itoa(int(timeout / (10 * time.Millisecond)))
+	if err := os.WriteFile(path, []byte(value), 0644); err != nil {
+		if os.IsNotExist(err) || os.IsPermission(err) {
+			return nil
+		}
+		return fmt.Errorf("erro ao ajustar supervision timeout: %v", err)
+	}
+	return nil
+}
+
+// scanDutyLoop alterna a descoberta entre a janela de escaneamento e o
+// restante do ciclo quando o perfil de rádio não escaneia continuamente
+func (lba *LinuxBluetoothAdapter) scanDutyLoop() {
+	for {
+		wait := time.Second
+		profile := lba.radioProfile()
+
+		lba.radioMutex.Lock()
+		paused, scanning := lba.scanPaused, lba.isScanning
+		lba.radioMutex.Unlock()
+
+		switch {
+		case !scanning:
+			// Escaneamento desligado, nada a alternar
+		case paused:
+			// Nova janela de escaneamento (ou perfil contínuo)
+			if err := lba.bluezCall(lba.adapter.StartDiscovery()); err == nil {
+				lba.setScanPaused(false)
+				wait = profile.ScanWindow
+			}
+		case !profile.ContinuousScan():
+			if err := lba.bluezCall(lba.adapter.StopDiscovery()); err == nil {
+				lba.setScanPaused(true)
+				wait = profile.ScanInterval - profile.ScanWindow
+			}
+		}
+
+		select {
+		case <-lba.ctx.Done():
+			return
+		case <-lba.profileChanged:
+		case <-time.After(wait):
 		}
 	}
+}
+
+// setScanPaused registra se a descoberta está suspensa pelo ciclo de escaneamento
+func (lba *LinuxBluetoothAdapter) setScanPaused(paused bool) {
+	lba.radioMutex.Lock()
+	defer lba.radioMutex.Unlock()
 
+	lba.scanPaused = paused
+}
+
+// SendData envia dados para um dispositivo específico, escrevendo na
+// característica de dados do serviço Bitchat do peer
+func (lba *LinuxBluetoothAdapter) SendData(data []byte, deviceID string) error {
+	// O lock dos dispositivos não é mantido durante a conexão e as escritas
+	targetPath, targetDevice := lba.deviceByAddress(deviceID)
 	if targetDevice == nil {
 		return fmt.Errorf("dispositivo não encontrado: %s", deviceID)
 	}
@@ -247,26 +502,55 @@ func (lba *LinuxBluetoothAdapter) SendData(data []byte, deviceID string) error {
 				// Continuar tentando
 			}
 		}
+		lba.connections.Connected(targetPath, mesh.LinkRoleCentral)
 	}
+	lba.connections.Activity(targetPath)
 
-	// Enviar dados (implementação depende da configuração GATT)
-	// Esta é uma implementação simplificada; a real precisaria configurar
-	// serviços GATT e características
-	return fmt.Errorf("envio de dados não implementado completamente")
+	if !lba.waitServicesResolved(targetDevice) {
+		return fmt.Errorf("timeout ao resolver serviços do dispositivo")
+	}
+	char, err := targetDevice.GetCharByUUID(CharacteristicUUID)
+	if err != nil {
+		return fmt.Errorf("erro ao obter característica de dados: %v", err)
+	}
+	if char == nil {
+		return fmt.Errorf("característica de dados não encontrada: %s", deviceID)
+	}
+
+	// Escritas seguem pela fila do dispositivo, divididas no MTU negociado.
+	// Escritas com resposta confirmam cada pedaço antes do próximo.
+	options := map[string]interface{}{"type": "request"}
+	return lba.writeQueue.Write(deviceID, data, func(chunk []byte) error {
+		if err := lba.bluezCall(char.WriteValue(chunk, options)); err != nil {
+			return fmt.Errorf("erro ao escrever na característica de dados: %v", err)
+		}
+		return nil
+	})
 }
 
 // BroadcastData envia dados para todos os dispositivos conectados
 func (lba *LinuxBluetoothAdapter) BroadcastData(data []byte) error {
+	// Copiar os endereços sob o lock: SendData o adquire de novo e pode
+	// aguardar a escrita no dispositivo
 	lba.deviceMutex.RLock()
-	defer lba.deviceMutex.RUnlock()
+	devices := make(map[string]*device.Device1, len(lba.devices))
+	for path, dev := range lba.devices {
+		devices[path] = dev
+	}
+	lba.deviceMutex.RUnlock()
 
-	var lastError error
-	for _, dev := range lba.devices {
-		addr, err := dev.GetAddress()
-		if err != nil {
+	var addresses []string
+	for path, dev := range devices {
+		if !lba.connections.IsConnected(path) {
 			continue
 		}
+		if addr, err := dev.GetAddress(); err == nil {
+			addresses = append(addresses, addr)
+		}
+	}
 
+	var lastError error
+	for _, addr := range addresses {
 		if err := lba.SendData(data, addr); err != nil {
 			lastError = err
 		}
@@ -275,64 +559,439 @@ func (lba *LinuxBluetoothAdapter) BroadcastData(data []byte) error {
 	return lastError
 }
 
+// StartBulkChannels passa a aceitar canais L2CAP abertos pelos peers
+func (lba *LinuxBluetoothAdapter) StartBulkChannels() error {
+	lba.deviceMutex.RLock()
+	listening := lba.bulkListener != nil
+	lba.deviceMutex.RUnlock()
+	if listening {
+		return nil
+	}
+
+	listener, err := ListenL2CAP(BulkChannelPSM)
+	if err != nil {
+		return err
+	}
+
+	lba.deviceMutex.Lock()
+	lba.bulkListener = listener
+	lba.deviceMutex.Unlock()
+
+	go func() {
+		for {
+			channel, err := listener.Accept()
+			if err != nil {
+				if lba.ctx.Err() == nil {
+					slog.Error("erro ao aceitar canal L2CAP", "err", err)
+				}
+				return
+			}
+			lba.addBulkChannel(channel)
+		}
+	}()
+
+	return nil
+}
+
+// SendBulk envia dados a um dispositivo conectado pelo canal L2CAP,
+// abrindo o canal se necessário
+func (lba *LinuxBluetoothAdapter) SendBulk(data []byte, deviceID string) error {
+	lba.deviceMutex.RLock()
+	channel := lba.bulkChannels[deviceID]
+	var targetDevice *device.Device1
+	if channel == nil {
+		for _, dev := range lba.devices {
+			addr, err := dev.GetAddress()
+			if err == nil && addr == deviceID {
+				targetDevice = dev
+				break
+			}
+		}
+	}
+	lba.deviceMutex.RUnlock()
+
+	if channel == nil {
+		if targetDevice == nil {
+			return fmt.Errorf("dispositivo não encontrado: %s", deviceID)
+		}
+		if connected, err := targetDevice.GetConnected(); err != nil || !connected {
+			return fmt.Errorf("dispositivo não conectado: %s", deviceID)
+		}
+
+		addressType, _ := targetDevice.GetAddressType()
+		opened, err := DialL2CAP(deviceID, addressType == "random", BulkChannelPSM)
+		if err != nil {
+			return err
+		}
+		channel = lba.addBulkChannel(opened)
+	}
+
+	if err := channel.Write(data); err != nil {
+		lba.removeBulkChannel(channel)
+		return err
+	}
+	return nil
+}
+
+// addBulkChannel registra um canal L2CAP e passa a ler os dados recebidos
+// por ele. Um canal anterior com o mesmo peer é substituído.
+func (lba *LinuxBluetoothAdapter) addBulkChannel(channel *L2CAPChannel) *L2CAPChannel {
+	lba.deviceMutex.Lock()
+	previous := lba.bulkChannels[channel.Address()]
+	lba.bulkChannels[channel.Address()] = channel
+	lba.deviceMutex.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+
+	go func() {
+		defer lba.removeBulkChannel(channel)
+
+		buf := make([]byte, BulkChannelMTU)
+		for {
+			n, err := channel.Read(buf)
+			if err != nil || n == 0 {
+				return
+			}
+
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			if lba.onDataReceived != nil {
+				lba.onDataReceived(data, channel.Address())
+			}
+		}
+	}()
+
+	return channel
+}
+
+// removeBulkChannel fecha um canal L2CAP e o remove se ainda estiver registrado
+func (lba *LinuxBluetoothAdapter) removeBulkChannel(channel *L2CAPChannel) {
+	lba.deviceMutex.Lock()
+	if lba.bulkChannels[channel.Address()] == channel {
+		delete(lba.bulkChannels, channel.Address())
+	}
+	lba.deviceMutex.Unlock()
+
+	channel.Close()
+}
+
+// SetLinkMTU registra o MTU negociado com um dispositivo. UnlimitedMTU
+// marca enlaces que transportam pacotes inteiros sem fragmentação.
+func (lba *LinuxBluetoothAdapter) SetLinkMTU(deviceID string, mtu int) {
+	lba.deviceMutex.Lock()
+	defer lba.deviceMutex.Unlock()
+
+	if mtu != UnlimitedMTU && mtu < MinLinkMTU {
+		mtu = MinLinkMTU
+	}
+	lba.linkMTUs[deviceID] = mtu
+	if mtu != UnlimitedMTU {
+		lba.writeQueue.SetMTU(deviceID, mtu)
+	}
+}
+
+// LinkMTU retorna o MTU negociado com um dispositivo
+func (lba *LinuxBluetoothAdapter) LinkMTU(deviceID string) (int, bool) {
+	lba.deviceMutex.RLock()
+	defer lba.deviceMutex.RUnlock()
+
+	mtu, ok := lba.linkMTUs[deviceID]
+	return mtu, ok
+}
+
+// MinLinkMTU retorna o menor MTU entre os enlaces conhecidos, usado em
+// broadcasts (MaxPacketSize se nenhum MTU foi negociado). Enlaces sem limite
+// não restringem o tamanho.
+func (lba *LinuxBluetoothAdapter) MinLinkMTU() int {
+	lba.deviceMutex.RLock()
+	defer lba.deviceMutex.RUnlock()
+
+	min := MaxPacketSize
+	for _, mtu := range lba.linkMTUs {
+		if mtu != UnlimitedMTU && mtu < min {
+			min = mtu
+		}
+	}
+	return min
+}
+
 // SetOnDataReceived define o callback para dados recebidos
 func (lba *LinuxBluetoothAdapter) SetOnDataReceived(callback func([]byte, string)) {
 	lba.onDataReceived = callback
 }
 
+// SetOnAnnounce define quem decide, a partir do anúncio compacto, se um
+// dispositivo descoberto deve ser discado antes dos demais
+func (lba *LinuxBluetoothAdapter) SetOnAnnounce(callback func(address string, announce *protocol.CompactAnnounce) bool) {
+	lba.onAnnounce = callback
+}
+
 // Close libera recursos do adaptador
 func (lba *LinuxBluetoothAdapter) Close() error {
 	lba.cancel()
 
 	// Parar advertising
-	if lba.isAdvertising {
+	if lba.IsAdvertising() {
 		lba.StopAdvertising()
 	}
 
 	// Parar escaneamento
-	if lba.isScanning {
+	if lba.IsScanning() {
 		lba.StopScanning()
 	}
 
-	// Desconectar dispositivos
+	// Cancelar inscrições, fechar canais L2CAP e desconectar dispositivos
+	lba.unsubscribeAll()
 	lba.deviceMutex.Lock()
+	if lba.bulkListener != nil {
+		lba.bulkListener.Close()
+		lba.bulkListener = nil
+	}
+	for address, channel := range lba.bulkChannels {
+		channel.Close()
+		delete(lba.bulkChannels, address)
+	}
 	for _, dev := range lba.devices {
 		dev.Disconnect()
 	}
+	if lba.bondAgent != nil {
+		agent.RemoveAgent(lba.bondAgent)
+		lba.bondAgent = nil
+	}
 	lba.deviceMutex.Unlock()
+	lba.writeQueue.Close()
+	lba.closeGATTService()
 
 	return nil
 }
 
 // Funções auxiliares
 
-// connectToDevice conecta a um dispositivo e configura para receber dados
-func (lba *LinuxBluetoothAdapter) connectToDevice(dev *device.Device1) {
+// connectionLoop avalia periodicamente os enlaces até o adaptador ser fechado
+func (lba *LinuxBluetoothAdapter) connectionLoop() {
+	ticker := time.NewTicker(ConnectionCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-lba.ctx.Done():
+			return
+		case <-ticker.C:
+			lba.manageConnections()
+		}
+	}
+}
+
+// manageConnections sincroniza o estado das conexões com o BlueZ e aplica o
+// plano do gerenciador: disca os candidatos escolhidos e encerra os enlaces
+// ociosos ou excedentes
+func (lba *LinuxBluetoothAdapter) manageConnections() {
+	lba.deviceMutex.RLock()
+	devices := make(map[string]*device.Device1, len(lba.devices))
+	for path, dev := range lba.devices {
+		devices[path] = dev
+	}
+	lba.deviceMutex.RUnlock()
+
+	for path, dev := range devices {
+		connected, err := dev.GetConnected()
+		if err != nil {
+			continue
+		}
+
+		lba.deviceMutex.RLock()
+		dialing := lba.dialing[path]
+		lba.deviceMutex.RUnlock()
+
+		switch {
+		case connected && !dialing && !lba.connections.IsConnected(path):
+			// Conexão iniciada pelo peer
+			lba.connections.Connected(path, mesh.LinkRolePeripheral)
+			go lba.bondIfRequested(dev)
+		case !connected && lba.connections.IsConnected(path):
+			lba.connections.Disconnected(path)
+			if address, err := dev.GetAddress(); err == nil {
+				lba.deviceMutex.Lock()
+				delete(lba.linkMTUs, address)
+				delete(lba.mailboxReads, address)
+				lba.deviceMutex.Unlock()
+				lba.writeQueue.Remove(address)
+				lba.unsubscribeNotifications(address)
+			}
+		}
+	}
+
+	plan := lba.connections.Plan(time.Now())
+
+	for _, path := range plan.Disconnect {
+		if dev, ok := devices[path]; ok {
+			if err := dev.Disconnect(); err != nil {
+				slog.Error("erro ao desconectar dispositivo", "err", err)
+			}
+		}
+		lba.connections.Disconnected(path)
+	}
+
+	for _, path := range plan.Dial {
+		dev, ok := devices[path]
+		if !ok {
+			continue
+		}
+
+		lba.deviceMutex.Lock()
+		if lba.dialing[path] {
+			lba.deviceMutex.Unlock()
+			continue
+		}
+		lba.dialing[path] = true
+		lba.deviceMutex.Unlock()
+
+		go lba.connectToDevice(path, dev)
+	}
+}
+
+// connectToDevice conecta a um dispositivo como central e configura para receber dados
+func (lba *LinuxBluetoothAdapter) connectToDevice(deviceID string, dev *device.Device1) {
+	defer func() {
+		lba.deviceMutex.Lock()
+		delete(lba.dialing, deviceID)
+		lba.deviceMutex.Unlock()
+	}()
+
 	// Verificar se já está conectado
 	connected, err := dev.GetConnected()
 	if err != nil {
-		fmt.Printf("Erro ao verificar conexão: %v\n", err)
+		slog.Error("erro ao verificar conexão", "err", err)
 		return
 	}
 
 	if !connected {
 		// Tentar conectar
 		if err := dev.Connect(); err != nil {
-			fmt.Printf("Erro ao conectar ao dispositivo: %v\n", err)
+			// Sem vagas no controlador o peer aguarda na fila, sem backoff
+			if isConnectionLimitError(err) {
+				if _, limited := lba.connections.ControllerLimit(); !limited {
+					slog.Warn("limite de conexões do controlador atingido; peers aguardarão uma vaga", "links", len(lba.connections.Links()))
+				}
+				lba.connections.ConnectLimited(deviceID)
+				return
+			}
+
+			// Registrar apenas a primeira falha de uma sequência
+			lba.connections.ConnectFailed(deviceID)
+			if _, failures := lba.connections.RetryAt(deviceID); failures == 1 {
+				slog.Error("erro ao conectar ao dispositivo", "err", err)
+			}
 			return
 		}
 	}
 
-	// Configurar para receber notificações
-	// Esta é uma implementação simplificada; a real precisaria descobrir
-	// serviços e características GATT e configurar notificações
-	fmt.Println("Dispositivo conectado, configuração para receber dados não implementada completamente")
+	lba.connections.Connected(deviceID, mesh.LinkRoleCentral)
+	lba.negotiateMTU(dev)
+	lba.bondIfRequested(dev)
+
+	// Os pacotes do peer chegam como notificações da característica TX; os
+	// guardados enquanto estávamos fora de alcance, pela caixa de correio
+	lba.subscribeNotifications(dev)
+	lba.pullMailbox(dev)
+}
+
+// negotiateMTU obtém o MTU ATT do enlace com um dispositivo recém-conectado,
+// pedindo o máximo permitido, e o registra para dimensionar os fragmentos
+func (lba *LinuxBluetoothAdapter) negotiateMTU(dev *device.Device1) {
+	address, err := dev.GetAddress()
+	if err != nil {
+		return
+	}
+
+	// As características só ficam disponíveis após a resolução dos serviços
+	if !lba.waitServicesResolved(dev) {
+		return
+	}
+
+	char, err := dev.GetCharByUUID(CharacteristicUUID)
+	if err != nil || char == nil {
+		return
+	}
+
+	// O BlueZ troca o MTU ao conectar; AcquireWrite pede o máximo quando o
+	// valor negociado ainda não foi publicado
+	mtu, err := char.GetMTU()
+	if err != nil || int(mtu) <= MinLinkMTU {
+		fd, acquired, err := char.AcquireWrite(map[string]interface{}{"mtu": uint16(MaxATTMTU)})
+		if err != nil {
+			return
+		}
+		syscall.Close(int(fd))
+		mtu = acquired
+	}
+
+	if int(mtu) > MaxATTMTU {
+		mtu = MaxATTMTU
+	}
+	lba.SetLinkMTU(address, int(mtu))
+}
+
+// waitServicesResolved aguarda o BlueZ resolver os serviços GATT de um
+// dispositivo conectado, por até 5 segundos
+func (lba *LinuxBluetoothAdapter) waitServicesResolved(dev *device.Device1) bool {
+	timeout := time.After(5 * time.Second)
+	for {
+		resolved, err := dev.GetServicesResolved()
+		if err == nil && resolved {
+			return true
+		}
+
+		select {
+		case <-timeout:
+			return false
+		case <-lba.ctx.Done():
+			return false
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// GetConnectionLinks retorna os enlaces mantidos pelo gerenciador de conexões
+func (lba *LinuxBluetoothAdapter) GetConnectionLinks() []mesh.Link {
+	return lba.connections.Links()
+}
+
+// compactAnnounce extrai o anúncio compacto dos dados de serviço de um
+// dispositivo, ou nil se ele não anunciar no formato compacto
+func compactAnnounce(dev *device.Device1) *protocol.CompactAnnounce {
+	serviceData, err := dev.GetServiceData()
+	if err != nil {
+		return nil
+	}
+
+	for uuid, value := range serviceData {
+		if !strings.EqualFold(uuid, ServiceUUID) {
+			continue
+		}
+		if variant, ok := value.(dbus.Variant); ok {
+			value = variant.Value()
+		}
+		data, ok := value.([]byte)
+		if !ok {
+			return nil
+		}
+		announce, err := protocol.DecodeCompactAnnounce(data)
+		if err != nil {
+			return nil
+		}
+		return announce
+	}
+	return nil
 }
 
-// containsUUID verifica se uma lista contém um UUID específico
+// containsUUID verifica se uma lista contém um UUID específico. O BlueZ
+// informa os UUIDs em minúsculas.
 func containsUUID(uuids []string, target string) bool {
 	for _, uuid := range uuids {
-		if uuid == target {
+		if strings.EqualFold(uuid, target) {
 			return true
 		}
 	}
@@ -0,0 +1,80 @@
+package bluetooth
+
+import (
+	"log/slog"
+
+	"github.com/muka/go-bluetooth/bluez/profile/device"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+)
+
+// MaxMailboxPacketSize é o maior pacote guardado na caixa de correio, o
+// limite de um valor de atributo GATT
+const MaxMailboxPacketSize = 512
+
+// SetOnMailboxRead define quem entrega, a cada leitura da caixa de correio,
+// o próximo pacote guardado para o central no endereço informado
+func (lba *LinuxBluetoothAdapter) SetOnMailboxRead(callback func(address string) []byte) {
+	lba.onMailboxRead = callback
+}
+
+// handleMailboxRead responde a uma leitura da caixa de correio. O BlueZ lê
+// valores maiores que o MTU em partes, com deslocamento: um novo pacote só é
+// retirado na primeira parte. Uma leitura vazia indica que não há mais
+// pacotes.
+func (lba *LinuxBluetoothAdapter) handleMailboxRead(devicePath string, offset int) []byte {
+	address := addressFromDevicePath(devicePath)
+	if address == "" || lba.onMailboxRead == nil {
+		return []byte{}
+	}
+
+	if offset == 0 {
+		data := lba.onMailboxRead(address)
+		lba.deviceMutex.Lock()
+		lba.mailboxReads[address] = data
+		lba.deviceMutex.Unlock()
+
+		if data == nil {
+			return []byte{}
+		}
+		return data
+	}
+
+	lba.deviceMutex.RLock()
+	data := lba.mailboxReads[address]
+	lba.deviceMutex.RUnlock()
+
+	if offset >= len(data) {
+		return []byte{}
+	}
+	return data[offset:]
+}
+
+// pullMailbox lê os pacotes que um peer recém-conectado guardou para este nó
+// enquanto estávamos fora de alcance e os repassa ao callback de dados
+func (lba *LinuxBluetoothAdapter) pullMailbox(dev *device.Device1) {
+	address, err := dev.GetAddress()
+	if err != nil {
+		return
+	}
+
+	char, err := dev.GetCharByUUID(MailboxCharacteristicUUID)
+	if err != nil || char == nil {
+		// Peer sem caixa de correio
+		return
+	}
+
+	for i := 0; i < mesh.DefaultMailboxCapacity; i++ {
+		data, err := char.ReadValue(map[string]interface{}{})
+		if err := lba.bluezCall(err); err != nil {
+			slog.Warn("erro ao ler caixa de correio do peer", "device", address, "err", err)
+			return
+		}
+		if len(data) == 0 {
+			return
+		}
+
+		if lba.onDataReceived != nil {
+			lba.onDataReceived(data, address)
+		}
+	}
+}
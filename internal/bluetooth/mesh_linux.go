@@ -1,41 +1,190 @@
 package bluetooth
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
 	"github.com/permissionlesstech/bitchat/pkg/utils"
 )
 
+// MeshAdapter é o adaptador BLE sobre o qual o provedor mesh Linux opera:
+// o LinuxBluetoothAdapter do BlueZ ou um adaptador simulado, que permite
+// exercitar o provedor em CI sem hardware. Os dispositivos remotos são
+// identificados pelo endereço BLE.
+type MeshAdapter interface {
+	// Escaneamento e advertising
+	StartScanning() error
+	StopScanning() error
+	IsScanning() bool
+	StartAdvertising(deviceName string, serviceData []byte) error
+	StopAdvertising() error
+	IsAdvertising() bool
+	SetLocalPeerID(peerID []byte)
+	SetScanWhitelist(peerIDs []string) error
+	SetRadioProfile(profile RadioProfile) error
+	SetConnectionConfig(config ConnectionConfig) error
+
+	// Servidor GATT
+	RegisterGATTService() error
+	GATTRegistered() bool
+	NotifyData(data []byte) error
+
+	// Enlaces com os vizinhos
+	SendData(data []byte, address string) error
+	BroadcastData(data []byte) error
+	StartBulkChannels() error
+	SendBulk(data []byte, address string) error
+	LinkMTU(address string) (int, bool)
+	MinLinkMTU() int
+	RSSI(address string) (int, bool)
+	ForgetRotatedAddress(address string)
+	EnableBonding(address string) error
+	DisableBonding(address string) error
+
+	// Callbacks
+	SetOnDataReceived(callback func(data []byte, address string))
+	SetOnAnnounce(callback func(address string, announce *protocol.CompactAnnounce) bool)
+	SetOnMailboxRead(callback func(address string) []byte)
+	SetOnRadioStateChanged(callback func(state RadioState))
+	SetOnHealthEvent(callback func(event HealthEvent))
+
+	Close() error
+}
+
 // LinuxMeshProvider implementa a funcionalidade mesh BLE para Linux
 type LinuxMeshProvider struct {
-	adapter          *LinuxBluetoothAdapter
+	adapter          MeshAdapter
+	scanner          MeshAdapter // Adaptador dedicado ao escaneamento e ao papel central (nil se não houver)
 	meshService      *BluetoothMeshService
 	fragmentManager  *FragmentManager
+	neighborAddresses map[string]string // Peer -> endereço BLE dos vizinhos diretos
+	addressIdentities map[string]string // Endereço BLE -> caixa de correio do peer (hash da identidade)
+	mailbox          *mesh.Mailbox      // Pacotes não entregues aguardando a leitura do vizinho
+	bondedPeers      map[string]bool    // Peers com vínculo BLE habilitado
+	announceFlags    uint8              // Capacidades levadas no anúncio compacto
+	deviceName       string             // Apelido anunciado no advertising
+	radioPaused      bool               // Escaneamento e advertising suspensos pelo ciclo de trabalho
 	mutex            sync.RWMutex
 	isInitialized    bool
 }
 
-// NewLinuxMeshProvider cria um novo provedor mesh para Linux
+// NewLinuxMeshProvider cria um novo provedor mesh para Linux com os
+// adaptadores e as conexões configurados no serviço. Exige meshService.mutex.
 func NewLinuxMeshProvider(meshService *BluetoothMeshService) (*LinuxMeshProvider, error) {
-	adapter, err := NewLinuxBluetoothAdapter()
+	adapter, err := NewLinuxBluetoothAdapter(meshService.adapterID)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao criar adaptador Bluetooth: %v", err)
 	}
 
+	// Em nós fixos, um segundo adaptador pode escanear continuamente e discar
+	// os vizinhos, deixando o primeiro com o advertising e as conexões recebidas
+	var scanner MeshAdapter
+	scanAdapterID := meshService.scanAdapterID
+	switch {
+	case scanAdapterID == adapter.adapterID:
+		slog.Warn("adaptador de escaneamento é o próprio adaptador principal", "adapter", scanAdapterID)
+		scanAdapterID = ""
+	case scanAdapterID != "":
+		scanAdapter, err := NewLinuxBluetoothAdapter(scanAdapterID)
+		if err != nil {
+			adapter.Close()
+			return nil, fmt.Errorf("erro ao criar adaptador de escaneamento: %v", err)
+		}
+		scanner = scanAdapter
+	}
+
+	// Os adaptadores efetivos, com o padrão do BlueZ já resolvido
+	slog.Info("adaptadores Bluetooth em uso",
+		"adapter", adapter.adapterID,
+		"scan_adapter", scanAdapterID,
+		"max_centrals", meshService.connectionConfig.MaxCentralConnections,
+	)
+	return newLinuxMeshProvider(meshService, adapter, scanner), nil
+}
+
+// NewLinuxMeshProviderWithAdapter cria um provedor mesh sobre um adaptador
+// já criado, como o adaptador simulado usado nos testes. O provedor é
+// entregue ao serviço por SetPlatformProvider.
+func NewLinuxMeshProviderWithAdapter(meshService *BluetoothMeshService, adapter MeshAdapter) *LinuxMeshProvider {
+	meshService.mutex.RLock()
+	defer meshService.mutex.RUnlock()
+
+	return newLinuxMeshProvider(meshService, adapter, nil)
+}
+
+// newLinuxMeshProvider configura o provedor sobre os adaptadores informados;
+// scanner é nil sem adaptador dedicado ao escaneamento. Exige
+// meshService.mutex.
+func newLinuxMeshProvider(meshService *BluetoothMeshService, adapter, scanner MeshAdapter) *LinuxMeshProvider {
 	provider := &LinuxMeshProvider{
 		adapter:         adapter,
+		scanner:         scanner,
+		deviceName:      meshService.deviceName,
 		meshService:     meshService,
 		fragmentManager: NewFragmentManager(),
+		neighborAddresses: make(map[string]string),
+		addressIdentities: make(map[string]string),
+		mailbox:         mesh.NewMailbox(mesh.DefaultMailboxCapacity, mesh.DefaultMailboxTTL),
+		bondedPeers:     make(map[string]bool),
+	}
+
+	// Aplicar limites e intervalos de conexão configurados e o perfil do
+	// modo de bateria escolhido antes do início
+	for _, a := range provider.adapters() {
+		if err := a.SetConnectionConfig(meshService.connectionConfig); err != nil {
+			slog.Error("erro ao configurar conexões", "err", err)
+		}
+		if err := a.SetRadioProfile(RadioProfileForMode(meshService.batteryMode)); err != nil {
+			slog.Error("erro ao aplicar perfil do rádio", "err", err)
+		}
 	}
 
+	// Anunciar a identidade e filtrar a descoberta pelos peers conhecidos
+	adapter.SetLocalPeerID(meshService.deviceID)
+	if err := provider.central().SetScanWhitelist(meshService.scanWhitelistLocked()); err != nil {
+		slog.Error("erro ao configurar lista de peers conhecidos", "err", err)
+	}
+
+	// Remontagens abandonadas contam como falhas nas estatísticas
+	provider.fragmentManager.onExpired = meshService.stats.RecordFragmentFailure
+	
 	// Configurar callback para dados recebidos
-	adapter.SetOnDataReceived(provider.handleReceivedData)
+	for _, a := range provider.adapters() {
+		a.SetOnDataReceived(provider.handleReceivedData)
+		a.SetOnHealthEvent(meshService.reportHealthEvent)
+	}
+	provider.central().SetOnAnnounce(provider.handleAnnounce)
+	adapter.SetOnMailboxRead(provider.handleMailboxRead)
+
+	// Retomar escaneamento e advertising quando o rádio voltar
+	adapter.SetOnRadioStateChanged(provider.handleRadioStateChanged)
+	if scanner != nil {
+		scanner.SetOnRadioStateChanged(provider.handleScannerStateChanged)
+	}
+
+	return provider
+}
+
+// central retorna o adaptador que escaneia e disca os vizinhos
+func (lmp *LinuxMeshProvider) central() MeshAdapter {
+	if lmp.scanner != nil {
+		return lmp.scanner
+	}
+	return lmp.adapter
+}
 
-	return provider, nil
+// adapters retorna os adaptadores em uso
+func (lmp *LinuxMeshProvider) adapters() []MeshAdapter {
+	if lmp.scanner != nil {
+		return []MeshAdapter{lmp.adapter, lmp.scanner}
+	}
+	return []MeshAdapter{lmp.adapter}
 }
 
 // Initialize inicializa o provedor mesh
@@ -48,31 +197,297 @@ func (lmp *LinuxMeshProvider) Initialize() error {
 	}
 
 	// Iniciar escaneamento
-	if err := lmp.adapter.StartScanning(); err != nil {
+	if err := lmp.central().StartScanning(); err != nil {
 		return fmt.Errorf("erro ao iniciar escaneamento: %v", err)
 	}
 
 	// Iniciar advertising
-	deviceName := lmp.meshService.deviceName
-	
-	// Dados do serviço para advertising (versão simplificada)
-	serviceData := []byte{
-		0x01, // Versão do protocolo
-		byte(len(deviceName)),
+	if err := lmp.adapter.StartAdvertising(lmp.deviceName, lmp.advertisementData()); err != nil {
+		lmp.central().StopScanning()
+		return fmt.Errorf("erro ao iniciar advertising: %v", err)
 	}
-	serviceData = append(serviceData, []byte(deviceName)...)
 
-	if err := lmp.adapter.StartAdvertising(deviceName, serviceData); err != nil {
-		lmp.adapter.StopScanning()
-		return fmt.Errorf("erro ao iniciar advertising: %v", err)
+	// Serviço GATT em que os centrais escrevem e se inscrevem
+	if err := lmp.adapter.RegisterGATTService(); err != nil {
+		lmp.adapter.StopAdvertising()
+		lmp.central().StopScanning()
+		return fmt.Errorf("erro ao registrar serviço GATT: %v", err)
+	}
+
+	// Canais L2CAP aceleram arquivos e sincronização; sem eles o GATT é usado
+	if err := lmp.adapter.StartBulkChannels(); err != nil {
+		slog.Warn("canais L2CAP indisponíveis", "err", err)
 	}
 
 	lmp.isInitialized = true
 	return nil
 }
 
-// Shutdown desliga o provedor mesh
-func (lmp *LinuxMeshProvider) Shutdown() error {
+// handleRadioStateChanged informa o serviço mesh sobre o estado do rádio e
+// reinicia escaneamento, advertising, serviço GATT e canais L2CAP quando ele
+// volta de um desligamento, bloqueio rfkill ou remoção, dispensando
+// reiniciar o Bitchat
+func (lmp *LinuxMeshProvider) handleRadioStateChanged(state RadioState) {
+	lmp.meshService.setRadioState(state)
+
+	lmp.mutex.Lock()
+	defer lmp.mutex.Unlock()
+
+	if !lmp.isInitialized || state != RadioStateOn {
+		return
+	}
+
+	if err := lmp.central().StartScanning(); err != nil {
+		slog.Error("erro ao retomar escaneamento", "err", err)
+	}
+	if err := lmp.adapter.StartAdvertising(lmp.deviceName, lmp.advertisementData()); err != nil {
+		slog.Error("erro ao retomar advertising", "err", err)
+	}
+	if err := lmp.adapter.RegisterGATTService(); err != nil {
+		slog.Error("erro ao registrar serviço GATT", "err", err)
+	}
+	if err := lmp.adapter.StartBulkChannels(); err != nil {
+		slog.Warn("canais L2CAP indisponíveis", "err", err)
+	}
+}
+
+// handleScannerStateChanged retoma o escaneamento quando o adaptador
+// dedicado volta. O estado do rádio informado ao serviço mesh é o do
+// adaptador principal, que mantém o advertising.
+func (lmp *LinuxMeshProvider) handleScannerStateChanged(state RadioState) {
+	lmp.mutex.Lock()
+	defer lmp.mutex.Unlock()
+
+	if !lmp.isInitialized || state != RadioStateOn {
+		return
+	}
+	if err := lmp.scanner.StartScanning(); err != nil {
+		slog.Error("erro ao retomar escaneamento", "err", err)
+	}
+}
+
+// advertisementData monta o anúncio compacto levado nos dados de serviço.
+// Deve ser chamada com o mutex adquirido.
+func (lmp *LinuxMeshProvider) advertisementData() []byte {
+	announce := protocol.NewCompactAnnounce(lmp.meshService.deviceID, lmp.deviceName, lmp.announceFlags)
+	return protocol.EncodeCompactAnnounce(announce)
+}
+
+// handleAnnounce prioriza a conexão com peers conhecidos reconhecidos pelo
+// anúncio compacto e associa o endereço do dispositivo à identidade
+// anunciada, que se mantém quando o endereço MAC é rotacionado
+func (lmp *LinuxMeshProvider) handleAnnounce(address string, announce *protocol.CompactAnnounce) bool {
+	if address != "" {
+		lmp.mutex.Lock()
+		rotated, bonded := lmp.bindIdentityLocked(hex.EncodeToString(announce.IdentityHash[:]), address)
+		lmp.mutex.Unlock()
+
+		// O endereço anterior deixa de ser candidato a conexão
+		for _, previous := range rotated {
+			lmp.central().ForgetRotatedAddress(previous)
+		}
+		if bonded {
+			go func() {
+				if err := lmp.central().EnableBonding(address); err != nil {
+					slog.Error("erro ao criar vínculo com peer", "err", err)
+				}
+			}()
+		}
+	}
+	return lmp.meshService.isKnownAnnounce(announce)
+}
+
+// bindIdentityLocked associa um endereço BLE à identidade anunciada.
+// Celulares trocam o endereço MAC periodicamente: os endereços anteriores da
+// mesma identidade são retornados, já substituídos nos vizinhos conhecidos,
+// junto com a indicação de que algum desses vizinhos tem vínculo habilitado.
+// Exige lmp.mutex.
+func (lmp *LinuxMeshProvider) bindIdentityLocked(identity, address string) ([]string, bool) {
+	var rotated []string
+	bonded := false
+	for previous, known := range lmp.addressIdentities {
+		if known != identity || previous == address {
+			continue
+		}
+		delete(lmp.addressIdentities, previous)
+		for peerID, neighbor := range lmp.neighborAddresses {
+			if neighbor == previous {
+				lmp.neighborAddresses[peerID] = address
+				bonded = bonded || lmp.bondedPeers[peerID]
+			}
+		}
+		rotated = append(rotated, previous)
+	}
+	lmp.addressIdentities[address] = identity
+	return rotated, bonded
+}
+
+// mailboxKey retorna a caixa de correio de um peer: o hash da identidade,
+// o mesmo levado no anúncio compacto
+func mailboxKey(peerID string) string {
+	hash := protocol.HashIdentity([]byte(peerID))
+	return hex.EncodeToString(hash[:])
+}
+
+// handleMailboxRead entrega ao central que lê a caixa de correio o próximo
+// pacote guardado para ele, ou nil se não houver
+func (lmp *LinuxMeshProvider) handleMailboxRead(address string) []byte {
+	lmp.mutex.RLock()
+	key, ok := lmp.addressIdentities[address]
+	lmp.mutex.RUnlock()
+
+	if !ok {
+		return nil
+	}
+	if data, ok := lmp.mailbox.Take(key, time.Now()); ok {
+		return data
+	}
+	return nil
+}
+
+// SetNickname troca o apelido anunciado, reanunciando se necessário
+func (lmp *LinuxMeshProvider) SetNickname(name string) error {
+	lmp.mutex.Lock()
+	defer lmp.mutex.Unlock()
+
+	lmp.deviceName = name
+	return lmp.readvertise()
+}
+
+// SetAnnounceFlags atualiza as capacidades do anúncio compacto, reanunciando
+// se elas mudaram
+func (lmp *LinuxMeshProvider) SetAnnounceFlags(flags uint8) error {
+	lmp.mutex.Lock()
+	defer lmp.mutex.Unlock()
+
+	if flags == lmp.announceFlags {
+		return nil
+	}
+	lmp.announceFlags = flags
+	return lmp.readvertise()
+}
+
+// readvertise reinicia o advertising em andamento com os dados atuais.
+// Deve ser chamada com o mutex adquirido.
+func (lmp *LinuxMeshProvider) readvertise() error {
+	if !lmp.isInitialized || !lmp.adapter.IsAdvertising() {
+		return nil
+	}
+	if err := lmp.adapter.StopAdvertising(); err != nil {
+		return fmt.Errorf("erro ao parar advertising: %v", err)
+	}
+	if err := lmp.adapter.StartAdvertising(lmp.deviceName, lmp.advertisementData()); err != nil {
+		return fmt.Errorf("erro ao retomar advertising: %v", err)
+	}
+	return nil
+}
+
+// SetRadioActive retoma ou suspende escaneamento e advertising conforme o
+// ciclo de trabalho do serviço mesh
+func (lmp *LinuxMeshProvider) SetRadioActive(active bool) error {
+	lmp.mutex.Lock()
+	defer lmp.mutex.Unlock()
+
+	if !lmp.isInitialized {
+		return nil
+	}
+
+	lmp.radioPaused = !active
+	if !active {
+		lmp.adapter.StopAdvertising()
+		return lmp.central().StopScanning()
+	}
+
+	if err := lmp.central().StartScanning(); err != nil {
+		return fmt.Errorf("erro ao retomar escaneamento: %v", err)
+	}
+	if err := lmp.adapter.StartAdvertising(lmp.deviceName, lmp.advertisementData()); err != nil {
+		return fmt.Errorf("erro ao retomar advertising: %v", err)
+	}
+	return nil
+}
+
+// TransportHealth informa a situação do advertising, do escaneamento e do
+// servidor GATT. Suspensos pelo ciclo de trabalho, advertising e
+// escaneamento estão ok.
+func (lmp *LinuxMeshProvider) TransportHealth() []ComponentHealth {
+	lmp.mutex.RLock()
+	defer lmp.mutex.RUnlock()
+
+	check := func(name string, active bool) ComponentHealth {
+		switch {
+		case !lmp.isInitialized:
+			return ComponentHealth{Name: name, Status: HealthFailed, Detail: "provedor não inicializado"}
+		case active:
+			return ComponentHealth{Name: name, Status: HealthOK}
+		case lmp.radioPaused:
+			return ComponentHealth{Name: name, Status: HealthOK, Detail: "suspenso pelo ciclo de trabalho"}
+		default:
+			return ComponentHealth{Name: name, Status: HealthFailed, Detail: "inativo"}
+		}
+	}
+
+	// O servidor GATT continua registrado com o rádio suspenso
+	gattHealth := ComponentHealth{Name: HealthComponentGATT, Status: HealthOK}
+	switch {
+	case !lmp.isInitialized:
+		gattHealth.Status, gattHealth.Detail = HealthFailed, "provedor não inicializado"
+	case !lmp.adapter.GATTRegistered():
+		gattHealth.Status, gattHealth.Detail = HealthFailed, "serviço não registrado no BlueZ"
+	}
+
+	return []ComponentHealth{
+		check(HealthComponentAdvertising, lmp.adapter.IsAdvertising()),
+		check(HealthComponentScanning, lmp.central().IsScanning()),
+		gattHealth,
+	}
+}
+
+// SetScanWhitelist restringe a descoberta aos peers conhecidos nos perfis de
+// rádio com WhitelistScan
+func (lmp *LinuxMeshProvider) SetScanWhitelist(peerIDs []string) error {
+	lmp.mutex.Lock()
+	defer lmp.mutex.Unlock()
+
+	if err := lmp.central().SetScanWhitelist(peerIDs); err != nil {
+		return fmt.Errorf("erro ao aplicar lista de peers conhecidos: %v", err)
+	}
+	return nil
+}
+
+// SetRadioProfile ajusta escaneamento, advertising e conexões ao modo de
+// bateria do serviço mesh
+func (lmp *LinuxMeshProvider) SetRadioProfile(profile RadioProfile) error {
+	lmp.mutex.Lock()
+	defer lmp.mutex.Unlock()
+
+	for _, adapter := range lmp.adapters() {
+		if err := adapter.SetRadioProfile(profile); err != nil {
+			return fmt.Errorf("erro ao aplicar perfil do rádio: %v", err)
+		}
+	}
+
+	if !lmp.isInitialized || !lmp.adapter.IsAdvertising() {
+		return nil
+	}
+
+	// Reanunciar para aplicar o novo intervalo de advertising
+	if err := lmp.adapter.StopAdvertising(); err != nil {
+		return fmt.Errorf("erro ao parar advertising: %v", err)
+	}
+	if err := lmp.adapter.StartAdvertising(lmp.deviceName, lmp.advertisementData()); err != nil {
+		return fmt.Errorf("erro ao retomar advertising: %v", err)
+	}
+	return nil
+}
+
+// Start não tem efeito: escaneamento e advertising começam em Initialize
+func (lmp *LinuxMeshProvider) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop desliga o provedor mesh
+func (lmp *LinuxMeshProvider) Stop() error {
 	lmp.mutex.Lock()
 	defer lmp.mutex.Unlock()
 
@@ -82,11 +497,13 @@ func (lmp *LinuxMeshProvider) Shutdown() error {
 
 	// Parar advertising e escaneamento
 	lmp.adapter.StopAdvertising()
-	lmp.adapter.StopScanning()
+	lmp.central().StopScanning()
 	
-	// Fechar adaptador
-	if err := lmp.adapter.Close(); err != nil {
-		return fmt.Errorf("erro ao fechar adaptador: %v", err)
+	// Fechar adaptadores
+	for _, adapter := range lmp.adapters() {
+		if err := adapter.Close(); err != nil {
+			return fmt.Errorf("erro ao fechar adaptador: %v", err)
+		}
 	}
 
 	lmp.isInitialized = false
@@ -95,37 +512,153 @@ func (lmp *LinuxMeshProvider) Shutdown() error {
 
 // SendPacket envia um pacote BitchatPacket
 func (lmp *LinuxMeshProvider) SendPacket(packet *protocol.BitchatPacket) error {
-	// Codificar pacote
-	data, err := protocol.Encode(packet)
+	// Codificar pacote em um buffer do pool: os envios não retêm os dados
+	buf := protocol.GetBuffer()
+	defer protocol.PutBuffer(buf)
+	data, err := protocol.AppendEncode(*buf, packet)
+	if err != nil {
+		return fmt.Errorf("erro ao codificar pacote: %v", err)
+	}
+	*buf = data
+
+	// Pacotes para um vizinho direto seguem só pelo seu enlace; os demais,
+	// inclusive os direcionados a peers distantes, são inundados
+	if isDirectedPacket(packet) && lmp.isNeighbor(string(packet.RecipientID)) {
+		return lmp.sendDirected(packet, data, string(packet.RecipientID))
+	}
+
+	// Broadcasts precisam caber no menor MTU entre os vizinhos
+	if mtu := lmp.central().MinLinkMTU(); needsFragmentation(len(data), mtu) {
+		return lmp.sendFragmentedPacket(packet, data, mtu, "")
+	}
+
+	// Pacote broadcast
+	return lmp.broadcast(data)
+}
+
+// NeighborLink retorna o endereço BLE de um vizinho direto, o mesmo
+// informado em ReceivedFrom nos pacotes recebidos dele
+func (lmp *LinuxMeshProvider) NeighborLink(peerID string) (string, bool) {
+	lmp.mutex.RLock()
+	defer lmp.mutex.RUnlock()
+
+	address, ok := lmp.neighborAddresses[peerID]
+	return address, ok
+}
+
+// isNeighbor informa se o endereço BLE de um peer vizinho é conhecido
+func (lmp *LinuxMeshProvider) isNeighbor(peerID string) bool {
+	lmp.mutex.RLock()
+	defer lmp.mutex.RUnlock()
+
+	_, ok := lmp.neighborAddresses[peerID]
+	return ok
+}
+
+// broadcast entrega dados aos periféricos conectados pelo adaptador central e
+// aos centrais inscritos na característica TX do servidor GATT
+func (lmp *LinuxMeshProvider) broadcast(data []byte) error {
+	err := lmp.central().BroadcastData(data)
+	if lmp.adapter.GATTRegistered() {
+		if notifyErr := lmp.adapter.NotifyData(data); notifyErr != nil {
+			err = notifyErr
+		}
+	}
+	return err
+}
+
+// GetPeerSignalStrength retorna o RSSI do último anúncio recebido de um
+// vizinho direto, ou 0 se desconhecido
+func (lmp *LinuxMeshProvider) GetPeerSignalStrength(peerID string) int {
+	lmp.mutex.RLock()
+	address, ok := lmp.neighborAddresses[peerID]
+	lmp.mutex.RUnlock()
+
+	if !ok {
+		return 0
+	}
+	rssi, _ := lmp.central().RSSI(address)
+	return rssi
+}
+
+// linkMTU retorna o MTU do enlace com um dispositivo (MaxPacketSize se desconhecido)
+func (lmp *LinuxMeshProvider) linkMTU(deviceID string) int {
+	if mtu, ok := lmp.central().LinkMTU(deviceID); ok {
+		return mtu
+	}
+	return MaxPacketSize
+}
+
+// SendPacketTo envia um pacote apenas para um vizinho específico
+func (lmp *LinuxMeshProvider) SendPacketTo(packet *protocol.BitchatPacket, neighborID string) error {
+	buf := protocol.GetBuffer()
+	defer protocol.PutBuffer(buf)
+	data, err := protocol.AppendEncode(*buf, packet)
 	if err != nil {
 		return fmt.Errorf("erro ao codificar pacote: %v", err)
 	}
+	*buf = data
+
+	return lmp.sendDirected(packet, data, neighborID)
+}
 
-	// Verificar se precisa fragmentar
-	if len(data) > MaxPacketSize {
-		return lmp.sendFragmentedPacket(packet, data)
+// sendDirected envia um pacote codificado a um vizinho. Tráfego volumoso
+// segue pelo canal L2CAP quando disponível; o restante, e qualquer pacote
+// cujo canal falhar, usa o GATT, fragmentando pelo MTU do enlace. Sem
+// conseguir entregar, o pacote fica guardado até o vizinho ler a caixa de
+// correio ao se conectar.
+func (lmp *LinuxMeshProvider) sendDirected(packet *protocol.BitchatPacket, data []byte, peerID string) error {
+	lmp.mutex.RLock()
+	address, ok := lmp.neighborAddresses[peerID]
+	lmp.mutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("endereço do vizinho desconhecido")
+	}
+
+	mtu := lmp.linkMTU(address)
+	if usesBulkChannel(packet, len(data), mtu) {
+		if err := lmp.central().SendBulk(data, address); err == nil {
+			return nil
+		}
 	}
 
-	// Enviar pacote diretamente
-	if isDirectedPacket(packet) {
-		// Pacote direcionado para um peer específico
-		recipientID := hex.EncodeToString(packet.RecipientID)
-		return lmp.adapter.SendData(data, recipientID)
+	var err error
+	if needsFragmentation(len(data), mtu) {
+		err = lmp.sendFragmentedPacket(packet, data, mtu, address)
 	} else {
-		// Pacote broadcast
-		return lmp.adapter.BroadcastData(data)
+		err = lmp.central().SendData(data, address)
 	}
+	if err != nil && len(data) <= MaxMailboxPacketSize {
+		now := time.Now()
+		lmp.mailbox.Expire(now)
+		lmp.mailbox.Put(mailboxKey(peerID), data, now)
+	}
+	return err
 }
 
-// sendFragmentedPacket fragmenta e envia um pacote grande
-func (lmp *LinuxMeshProvider) sendFragmentedPacket(packet *protocol.BitchatPacket, data []byte) error {
+// sendFragmentedPacket fragmenta e envia um pacote grande em pedaços que
+// cabem no MTU do enlace, ao vizinho no endereço informado ou, sem
+// endereço, a todos os vizinhos
+func (lmp *LinuxMeshProvider) sendFragmentedPacket(packet *protocol.BitchatPacket, data []byte, mtu int, address string) error {
 	// Gerar ID de fragmentação único
 	fragmentID := utils.GenerateRandomID(4)
 	
-	// Calcular número de fragmentos
-	numFragments := (len(data) + MaxFragmentPayloadSize - 1) / MaxFragmentPayloadSize
+	// Calcular tamanho e número de fragmentos
+	payloadSize := fragmentPayloadSize(mtu, &protocol.BitchatPacket{
+		Version:     packet.Version,
+		SenderID:    packet.SenderID,
+		RecipientID: packet.RecipientID,
+	})
+	numFragments, err := fragmentCount(len(data), payloadSize)
+	if err != nil {
+		return err
+	}
 	
-	// Criar e enviar fragmentos
+	// Criar e enviar fragmentos, reutilizando os buffers: cada fragmento é
+	// enviado antes de o próximo ser montado
+	fragPayload := make([]byte, 0, 6+payloadSize)
+	fragBuf := protocol.GetBuffer()
+	defer protocol.PutBuffer(fragBuf)
 	for i := 0; i < numFragments; i++ {
 		// Determinar tipo de fragmento
 		var fragType protocol.MessageType
@@ -138,14 +671,14 @@ func (lmp *LinuxMeshProvider) sendFragmentedPacket(packet *protocol.BitchatPacke
 		}
 		
 		// Calcular offset e tamanho do fragmento
-		offset := i * MaxFragmentPayloadSize
-		end := offset + MaxFragmentPayloadSize
+		offset := i * payloadSize
+		end := offset + payloadSize
 		if end > len(data) {
 			end = len(data)
 		}
 		
 		// Criar payload do fragmento
-		fragPayload := make([]byte, 6+end-offset)
+		fragPayload = fragPayload[:6+end-offset]
 		copy(fragPayload[0:4], fragmentID)                  // ID do fragmento
 		fragPayload[4] = byte(i)                            // Índice do fragmento
 		fragPayload[5] = byte(numFragments)                 // Total de fragmentos
@@ -163,24 +696,22 @@ func (lmp *LinuxMeshProvider) sendFragmentedPacket(packet *protocol.BitchatPacke
 		}
 		
 		// Codificar e enviar fragmento
-		fragData, err := protocol.Encode(fragPacket)
+		fragData, err := protocol.AppendEncode((*fragBuf)[:0], fragPacket)
 		if err != nil {
 			return fmt.Errorf("erro ao codificar fragmento: %v", err)
 		}
+		*fragBuf = fragData
 		
-		if isDirectedPacket(packet) {
-			recipientID := hex.EncodeToString(packet.RecipientID)
-			if err := lmp.adapter.SendData(fragData, recipientID); err != nil {
+		// A fila de escrita do adaptador cadencia os fragmentos
+		if address != "" {
+			if err := lmp.central().SendData(fragData, address); err != nil {
 				return err
 			}
 		} else {
-			if err := lmp.adapter.BroadcastData(fragData); err != nil {
+			if err := lmp.broadcast(fragData); err != nil {
 				return err
 			}
 		}
-		
-		// Pequena pausa entre fragmentos
-		time.Sleep(20 * time.Millisecond)
 	}
 	
 	return nil
@@ -191,10 +722,15 @@ func (lmp *LinuxMeshProvider) handleReceivedData(data []byte, senderID string) {
 	// Tentar decodificar pacote
 	packet, err := protocol.Decode(data)
 	if err != nil {
-		fmt.Printf("Erro ao decodificar pacote: %v\n", err)
+		slog.Error("erro ao decodificar pacote", "err", err)
 		return
 	}
 	
+	// Pacotes sem saltos vêm direto do remetente: aprender seu endereço BLE
+	if packet.HopCount == 0 {
+		lmp.learnNeighborAddress(string(packet.SenderID), senderID)
+	}
+	
 	// Verificar se é um fragmento
 	if isFragmentPacket(packet) {
 		lmp.handleFragmentPacket(packet, senderID)
@@ -202,14 +738,15 @@ func (lmp *LinuxMeshProvider) handleReceivedData(data []byte, senderID string) {
 	}
 	
 	// Processar pacote normal
-	lmp.meshService.incomingMessages <- packet
+	packet.ReceivedFrom = senderID
+	lmp.meshService.enqueueIncoming(packet)
 }
 
 // handleFragmentPacket processa pacotes fragmentados
 func (lmp *LinuxMeshProvider) handleFragmentPacket(packet *protocol.BitchatPacket, senderID string) {
 	// Extrair informações do fragmento
 	if len(packet.Payload) < 6 {
-		fmt.Println("Fragmento inválido: payload muito pequeno")
+		slog.Warn("fragmento inválido: payload muito pequeno")
 		return
 	}
 	
@@ -218,34 +755,35 @@ func (lmp *LinuxMeshProvider) handleFragmentPacket(packet *protocol.BitchatPacke
 	totalFragments := int(packet.Payload[5])
 	fragmentData := packet.Payload[6:]
 	
-	// Adicionar fragmento ao gerenciador
+	// Adicionar fragmento ao gerenciador; o ID do fragmento só identifica o
+	// pacote junto com o remetente original
 	complete, reassembled := lmp.fragmentManager.AddFragment(
+		string(packet.SenderID),
 		fragmentID, 
 		fragmentIndex, 
 		totalFragments, 
 		fragmentData,
-		packet.Type == protocol.MessageTypeFragmentStart,
-		packet.Type == protocol.MessageTypeFragmentEnd,
 	)
 	
 	if complete {
 		// Tentar decodificar pacote completo
 		completePacket, err := protocol.Decode(reassembled)
 		if err != nil {
-			fmt.Printf("Erro ao decodificar pacote reassemblado: %v\n", err)
+			slog.Error("erro ao decodificar pacote reassemblado", "err", err)
+			lmp.meshService.stats.RecordFragmentFailure()
 			return
 		}
 		
 		// Enviar para processamento
-		lmp.meshService.incomingMessages <- completePacket
+		completePacket.ReceivedFrom = senderID
+		lmp.meshService.enqueueIncoming(completePacket)
 	}
 }
 
 // Constantes e funções auxiliares
 
 const (
-	MaxPacketSize         = 512  // Tamanho máximo de pacote BLE
-	MaxFragmentPayloadSize = 480  // Tamanho máximo de payload por fragmento
+	MaxPacketSize         = 512  // MTU assumido para enlaces BLE sem MTU negociado
 )
 
 // isDirectedPacket verifica se um pacote é direcionado a um peer específico
@@ -273,98 +811,97 @@ func isFragmentPacket(packet *protocol.BitchatPacket) bool {
 
 // FragmentManager gerencia a reassemblagem de pacotes fragmentados
 type FragmentManager struct {
-	fragments    map[string]map[int][]byte  // fragmentID -> index -> data
-	startTime    map[string]time.Time       // fragmentID -> tempo de início
-	totalFrags   map[string]int             // fragmentID -> total de fragmentos
+	reassembler  *mesh.Reassembler
+	onExpired    func()                     // Chamada para cada remontagem abandonada
+	reported     uint64                     // Remontagens abandonadas já informadas a onExpired
 	mutex        sync.Mutex
 }
 
 // NewFragmentManager cria um novo gerenciador de fragmentos
 func NewFragmentManager() *FragmentManager {
 	return &FragmentManager{
-		fragments:  make(map[string]map[int][]byte),
-		startTime:  make(map[string]time.Time),
-		totalFrags: make(map[string]int),
+		reassembler: mesh.NewReassembler(mesh.DefaultReassemblyTimeout, mesh.DefaultMaxReassemblies),
 	}
 }
 
-// AddFragment adiciona um fragmento e tenta reassemblar
+// AddFragment adiciona um fragmento do pacote enviado por source e tenta
+// reassemblar. Fragmentos fora de ordem, repetidos ou de outros pacotes são
+// aceitos; fragmentos inconsistentes são descartados.
 // Retorna: completo, dados reassemblados
 func (fm *FragmentManager) AddFragment(
+	source string,
 	fragmentID []byte,
 	index int,
 	total int,
 	data []byte,
-	isStart bool,
-	isEnd bool,
 ) (bool, []byte) {
 	fm.mutex.Lock()
 	defer fm.mutex.Unlock()
 	
-	// Converter ID para string para usar como chave
-	idStr := hex.EncodeToString(fragmentID)
-	
-	// Verificar se já temos este fragmento
-	if _, exists := fm.fragments[idStr]; !exists {
-		fm.fragments[idStr] = make(map[int][]byte)
-		fm.startTime[idStr] = time.Now()
-		fm.totalFrags[idStr] = total
-	}
-	
-	// Armazenar fragmento
-	fm.fragments[idStr][index] = data
-	
-	// Verificar se temos todos os fragmentos
-	if len(fm.fragments[idStr]) == fm.totalFrags[idStr] {
-		// Reassemblar pacote
-		reassembled := fm.reassemblePacket(idStr)
-		
-		// Limpar dados deste fragmento
-		delete(fm.fragments, idStr)
-		delete(fm.startTime, idStr)
-		delete(fm.totalFrags, idStr)
-		
-		return true, reassembled
+	reassembled, complete, err := fm.reassembler.Add(source, hex.EncodeToString(fragmentID), index, total, data, time.Now())
+	if err != nil {
+		slog.Warn("fragmento descartado", "index", index, "total", total, "err", err)
 	}
+	fm.reportExpired()
 	
-	// Limpar fragmentos antigos (mais de 30 segundos)
-	fm.cleanupOldFragments()
-	
-	return false, nil
+	return complete, reassembled
 }
 
-// reassemblePacket combina os fragmentos em um pacote completo
-func (fm *FragmentManager) reassemblePacket(fragmentID string) []byte {
-	fragments := fm.fragments[fragmentID]
-	total := fm.totalFrags[fragmentID]
-	
-	// Calcular tamanho total
-	totalSize := 0
-	for i := 0; i < total; i++ {
-		if frag, ok := fragments[i]; ok {
-			totalSize += len(frag)
+// reportExpired informa onExpired das remontagens abandonadas desde a última
+// chamada. Exige fm.mutex.
+func (fm *FragmentManager) reportExpired() {
+	expired := fm.reassembler.Expired()
+	for ; fm.reported < expired; fm.reported++ {
+		if fm.onExpired != nil {
+			fm.onExpired()
 		}
 	}
-	
-	// Combinar fragmentos
-	result := make([]byte, 0, totalSize)
-	for i := 0; i < total; i++ {
-		if frag, ok := fragments[i]; ok {
-			result = append(result, frag...)
-		}
+}
+
+// SetPeerBonding habilita ou desabilita o vínculo BLE com um peer. Enquanto
+// o endereço do peer for desconhecido, o pareamento aguarda o primeiro
+// pacote recebido diretamente dele.
+func (lmp *LinuxMeshProvider) SetPeerBonding(peerID string, enabled bool) error {
+	lmp.mutex.Lock()
+	if enabled {
+		lmp.bondedPeers[peerID] = true
+	} else {
+		delete(lmp.bondedPeers, peerID)
 	}
-	
-	return result
+	address, known := lmp.neighborAddresses[peerID]
+	lmp.mutex.Unlock()
+
+	if !known {
+		return nil
+	}
+	if enabled {
+		return lmp.central().EnableBonding(address)
+	}
+	return lmp.central().DisableBonding(address)
 }
 
-// cleanupOldFragments remove fragmentos antigos
-func (fm *FragmentManager) cleanupOldFragments() {
-	now := time.Now()
-	for id, startTime := range fm.startTime {
-		if now.Sub(startTime) > 30*time.Second {
-			delete(fm.fragments, id)
-			delete(fm.startTime, id)
-			delete(fm.totalFrags, id)
-		}
+// learnNeighborAddress registra o endereço BLE de um vizinho direto e inicia
+// o vínculo pendente, se houver. Celulares trocam o endereço MAC
+// periodicamente: o vizinho continua identificado pelo remetente dos
+// pacotes, e o endereço anterior é substituído.
+func (lmp *LinuxMeshProvider) learnNeighborAddress(peerID string, address string) {
+	lmp.mutex.Lock()
+	previous := lmp.neighborAddresses[peerID]
+	lmp.neighborAddresses[peerID] = address
+	lmp.addressIdentities[address] = mailboxKey(peerID)
+	bonded := lmp.bondedPeers[peerID]
+	lmp.mutex.Unlock()
+
+	// O endereço anterior deixa de ser candidato a conexão
+	if previous != "" && previous != address {
+		lmp.central().ForgetRotatedAddress(previous)
+	}
+
+	if bonded && previous != address {
+		go func() {
+			if err := lmp.central().EnableBonding(address); err != nil {
+				slog.Error("erro ao criar vínculo com peer", "err", err)
+			}
+		}()
 	}
 }
@@ -1,14 +1,19 @@
 package bluetooth
 
 import (
+	"container/list"
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/permissionlesstech/bitchat/internal/protocol"
 	"github.com/permissionlesstech/bitchat/internal/crypto"
+	"github.com/permissionlesstech/bitchat/internal/service"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
 	"github.com/permissionlesstech/bitchat/pkg/utils"
 )
 
@@ -16,12 +21,41 @@ const (
 	// Constantes para o serviço BLE
 	ServiceUUID        = "6E400001-B5A3-F393-E0A9-E50E24DCCA9E" // UUID do serviço Bitchat
 	CharacteristicUUID = "6E400002-B5A3-F393-E0A9-E50E24DCCA9E" // UUID da característica de dados
+	NotifyCharacteristicUUID = "6E400003-B5A3-F393-E0A9-E50E24DCCA9E" // Característica TX, notificada aos centrais inscritos
+	MailboxCharacteristicUUID = "6E400004-B5A3-F393-E0A9-E50E24DCCA9E" // Caixa de correio lida pelos centrais ao se conectarem
+	ManufacturerTag    = "BTCHT" // Identificador Bitchat nos dados do fabricante anunciados
 	
 	// Configurações de operação
 	DefaultScanInterval    = 10 * time.Second
 	DefaultAdvertiseInterval = 5 * time.Second
 	DefaultMessageCacheTTL = 5 * time.Minute
 	DefaultMessageCacheSize = 1000
+	DefaultLinkProbeInterval = 15 * time.Second
+	DefaultMessageTTL       = 7 // TTL de mensagens enquanto o diâmetro da rede é desconhecido
+	MaxAcceptedTTL          = 16 // TTL + saltos acima disso é considerado abuso
+	DefaultQueueCapacity    = 100
+	DefaultTopologyTTL      = 3 // Alcance dos anúncios de topologia (saltos)
+	UrgentPathCount         = 2 // Caminhos usados para mensagens urgentes
+	
+	// Store-and-forward para peers recém-vistos
+	DefaultChannelReplayWindow = 2 * time.Minute // Idade máxima de tráfego de canal reenviado
+	MaxCachedReplay            = 20              // Máximo de mensagens reenviadas por peer
+	
+	// Sincronização de histórico após cura de partição e anti-entropia
+	DefaultSyncWindow  = 5 * time.Minute // Idade máxima das mensagens sincronizadas
+	MaxSyncDigests     = 64              // Máximo de resumos enviados por solicitação
+	MaxSyncReplay      = 32              // Máximo de mensagens reenviadas por solicitação
+	MaxSyncPeers       = 3               // Peers consultados a cada cura detectada
+	MaxSyncReplayBytes = 4096            // Limite de bytes reenviados por solicitação
+	
+	// Economia de energia guiada pelo nível de bateria
+	DefaultCoverMinBattery = 30          // Nível abaixo do qual o tráfego de cobertura é suspenso
+	BatteryPollInterval    = time.Minute // Intervalo de leitura do nível de bateria
+	
+	// Ciclo de trabalho do rádio nos modos de economia de bateria
+	LowBatteryWakeFraction      = 0.5         // Fração do ciclo acordada no modo de bateria baixa
+	UltraLowBatteryWakeFraction = 0.2         // Fração do ciclo acordada no modo de bateria ultra baixa
+	DutyCycleCheckInterval      = time.Second // Intervalo de verificação das janelas do rádio
 	
 	// Modos de economia de bateria
 	BatteryModeNormal      = 0
@@ -32,12 +66,16 @@ const (
 // Erros do serviço Bluetooth Mesh
 var (
 	ErrBluetoothNotAvailable = errors.New("bluetooth não disponível")
+	ErrBondingNotSupported   = errors.New("vínculo BLE não suportado pela plataforma")
 	ErrSendFailed            = errors.New("falha ao enviar mensagem")
 	ErrInvalidPacket         = errors.New("pacote inválido")
 	ErrPeerNotFound          = errors.New("peer não encontrado")
+	ErrRateLimited           = errors.New("limite de envio de mensagens excedido")
 )
 
-// MeshDelegate é a interface para receber eventos do serviço mesh
+// MeshDelegate é a interface para receber eventos do serviço mesh. Ele é
+// inscrito no barramento de eventos (veja EventBus), onde outros componentes
+// podem se inscrever de forma independente.
 type MeshDelegate interface {
 	OnPeerDiscovered(peerID string, name string)
 	OnPeerLost(peerID string)
@@ -45,25 +83,111 @@ type MeshDelegate interface {
 	OnMessageDeliveryChanged(messageID string, status protocol.DeliveryStatus, info *protocol.DeliveryInfo)
 }
 
+// QueueDropDelegate pode ser implementado pelo delegate para ser notificado
+// quando pacotes são descartados por excesso de carga nas filas internas
+type QueueDropDelegate interface {
+	OnPacketDropped(queue string, packet *protocol.BitchatPacket, priority mesh.Priority)
+}
+
+// TraceDelegate pode ser implementado pelo delegate para receber o resultado
+// dos rastreamentos de rota iniciados com Trace
+type TraceDelegate interface {
+	OnTraceResult(result *mesh.TraceResult)
+}
+
+// RadioStateDelegate pode ser implementado pelo delegate para ser notificado
+// quando o rádio é desligado, bloqueado, removido ou volta a operar
+type RadioStateDelegate interface {
+	OnRadioStateChanged(state RadioState)
+}
+
+// ProximityDelegate pode ser implementado pelo delegate para ser notificado
+// quando um vizinho direto começa a se aproximar ou a se afastar
+type ProximityDelegate interface {
+	OnPeerProximityChanged(event mesh.ProximityEvent)
+}
+
+// Nomes das filas internas informados em QueueDropDelegate e aceitos por
+// SetQueueConfig
+const (
+	QueueIncoming = "incoming"
+	QueueOutgoing = "outgoing"
+)
+
 // BluetoothMeshService gerencia a rede mesh Bluetooth
 type BluetoothMeshService struct {
 	// Identificação
 	deviceID        []byte
 	deviceName      string
+	adapterID       string // Adaptador Bluetooth escolhido (vazio para o padrão)
+	scanAdapterID   string // Adaptador dedicado ao escaneamento (vazio para usar adapterID)
+	connectionConfig ConnectionConfig // Limites e parâmetros das conexões BLE
 	
 	// Dependências
 	encryptionService *crypto.EncryptionService
-	delegate          MeshDelegate
+	unsubscribeDelegate func() // Cancela a inscrição do delegate no barramento (protegido por mutex)
+	events            *EventBus
 	platformProvider  PlatformProvider
 	
 	// Estado da rede mesh
 	peers            map[string]*Peer
+	bondedPeers      map[string]bool // Peers com vínculo BLE habilitado
+	scanWhitelist    map[string]bool // Peers favoritos aceitos pela descoberta filtrada
+	blockedFingerprints map[string]bool // Identidades bloqueadas
+	favorites        map[string]bool // Identidades favoritas
+	channels         map[string]*protocol.ChannelAnnounce // Metadados conhecidos dos canais
+	localChannels    []protocol.ChannelListEntry // Canais deste nó anunciados à rede
+	channelDirectory map[string]map[string]channelSighting // Canal -> peers que o anunciaram
+	files            *fileTransfers // Transferências de arquivo em andamento
+	retry            *service.RetryService // Reenvio de mensagens privadas sem confirmação
+	retryConfig      *service.RetryConfig
+	retryAliases     map[string]string // ID de um reenvio -> ID original da mensagem
+	receivedPrivate  *utils.ExpiringSet // Mensagens privadas recebidas, para ignorar reenvios
+	deliveries       *service.DeliveryTracker // Confirmações de mensagens de canal
+	readReceiptsOff  bool // Não enviar confirmações de leitura
+	typing           *service.TypingService // Temporização dos indicadores de digitação
+	typingOff        bool // Não enviar avisos de digitação
+	away             *service.AwayResponder // Respostas automáticas durante a ausência
+	rateLimiter      *mesh.RateLimiter // Limites de envio de mensagens
+	outgoing         map[string]*outgoingEntry // Mensagens compostas aguardando transmissão
+	outgoingStore    OutgoingStore
+	lastRecovery     *HealthEvent // Última etapa da recuperação automática do BlueZ
+	reconnect        *mesh.ReconnectTracker // Retornos de peers e canais após um intervalo
+	reconnectBudgets ReconnectSyncBudgets   // Orçamento da sincronização no retorno, por modo de bateria
+	reconnectSyncs   uint64                 // Sincronizações disparadas por retornos
+	compression      compressionStats
 	messageCache     *MessageCache
-	seenMessages     *utils.ExpiringSet
+	
+	// Roteamento
+	router           *mesh.MessageRouter
+	routeDiscovery   *mesh.RouteDiscovery
+	linkQuality      *mesh.LinkQualityTracker
+	proximity        *mesh.ProximityTracker
+	presence         *mesh.PresenceTracker
+	topology         *mesh.Topology
+	hello            *mesh.HelloProtocol
+	partition        *mesh.PartitionDetector
+	relayGovernor    *mesh.RelayGovernor
+	reputation       *mesh.Reputation
+	stats            *mesh.MeshStats
+	tracer           *mesh.Tracer
+	suppressor       *mesh.StormSuppressor
+	dutyCycle        *mesh.DutyCycle
+	probeSequence    uint32
+	benchmarks       map[uint32]*mesh.Benchmark // Medições de desempenho em andamento
+	benchSequence    uint32
 	
 	// Configurações
 	batteryMode      int
+	batteryLevel     int  // Último nível de bateria conhecido (0-100)
+	batteryKnown     bool // Se batteryLevel foi informado
+	batteryCharging  bool // Dispositivo carregando ou ligado à rede elétrica
+	chargingKnown    bool // Se batteryCharging foi informado
+	batteryAuto      bool // Modo de bateria escolhido pelo nível (/battery auto)
+	batteryThresholds BatteryThresholds // Limites do modo automático
+	coverMinBattery  int
 	coverTraffic     bool
+	radioState       RadioState // Último estado informado pelo provedor
 	
 	// Controle de operação
 	ctx              context.Context
@@ -71,9 +195,9 @@ type BluetoothMeshService struct {
 	mutex            sync.RWMutex
 	isRunning        bool
 	
-	// Canais para comunicação interna
-	outgoingMessages chan *protocol.BitchatPacket
-	incomingMessages chan *protocol.BitchatPacket
+	// Filas limitadas de entrada e saída com prioridades
+	outgoingQueue    *mesh.PriorityQueue
+	incomingQueue    *mesh.PriorityQueue
 }
 
 // Peer representa um dispositivo na rede mesh
@@ -88,9 +212,12 @@ type Peer struct {
 	MessageQueue    []*protocol.BitchatPacket
 }
 
-// MessageCache implementa cache para store-and-forward
+// MessageCache implementa cache para store-and-forward. As mensagens entram
+// com ReceivedAt igual ao momento da inserção, então a lista order, da mais
+// antiga para a mais recente, permite remover a mais antiga em O(1).
 type MessageCache struct {
 	messages        map[string]*CachedMessage
+	order           *list.List // IDs das mensagens por ReceivedAt
 	maxSize         int
 	mutex           sync.RWMutex
 }
@@ -102,6 +229,7 @@ type CachedMessage struct {
 	ExpiresAt       time.Time
 	DeliveredTo     map[string]bool
 	OriginalSender  string
+	element         *list.Element // Posição em MessageCache.order
 }
 
 // NewBluetoothMeshService cria um novo serviço mesh Bluetooth
@@ -111,34 +239,103 @@ func NewBluetoothMeshService(
 	encryptionService *crypto.EncryptionService,
 ) *BluetoothMeshService {
 	ctx, cancel := context.WithCancel(context.Background())
+	router := mesh.NewMessageRouter()
 	
-	return &BluetoothMeshService{
+	bms := &BluetoothMeshService{
 		deviceID:         deviceID,
+		events:           NewEventBus(),
+		reconnect:        mesh.NewReconnectTracker(0),
+		reconnectBudgets: DefaultReconnectSyncBudgets,
 		deviceName:       deviceName,
 		encryptionService: encryptionService,
 		peers:            make(map[string]*Peer),
+		bondedPeers:      make(map[string]bool),
+		scanWhitelist:    make(map[string]bool),
+		blockedFingerprints: make(map[string]bool),
+		favorites:        make(map[string]bool),
+		channels:         make(map[string]*protocol.ChannelAnnounce),
+		channelDirectory: make(map[string]map[string]channelSighting),
+		files:            newFileTransfers(),
+		retryAliases:     make(map[string]string),
+		receivedPrivate:  utils.NewExpiringSet(RetryDedupWindow, time.Minute),
+		deliveries:       service.NewDeliveryTracker(service.DefaultDeliveryTrackerTTL),
+		rateLimiter:      mesh.NewRateLimiter(mesh.DefaultRateLimitConfig()),
+		away:             service.NewAwayResponder(service.DefaultAwayCooldown),
+		outgoing:         make(map[string]*outgoingEntry),
 		messageCache:     newMessageCache(DefaultMessageCacheSize),
-		seenMessages:     utils.NewExpiringSet(DefaultMessageCacheTTL, DefaultMessageCacheTTL),
+		router:           router,
+		routeDiscovery:   mesh.NewRouteDiscovery(router, string(deviceID)),
+		linkQuality:      mesh.NewLinkQualityTracker(),
+		proximity:        mesh.NewProximityTracker(mesh.DefaultProximityThreshold),
+		presence:         mesh.NewPresenceTracker(mesh.DefaultPresenceOnlineWindow, mesh.DefaultPresenceRecentWindow),
+		topology:         mesh.NewTopology(mesh.DefaultTopologyMaxAge),
+		hello:            mesh.NewHelloProtocol(router, string(deviceID), mesh.DefaultHelloInterval),
+		partition:        mesh.NewPartitionDetector(mesh.DefaultHealMinPeers, mesh.DefaultHealFraction, mesh.DefaultHealCooldown),
+		relayGovernor:    mesh.NewRelayGovernor(mesh.DefaultRelayPolicy()),
+		reputation:       mesh.NewReputation(mesh.DefaultFloodLimit),
+		stats:            mesh.NewMeshStats(),
+		tracer:           mesh.NewTracer(string(deviceID)),
+		suppressor:       mesh.NewStormSuppressor(0, 0, 0),
+		dutyCycle:        mesh.NewDutyCycle(mesh.DefaultDutyPeriod),
+		benchmarks:       make(map[uint32]*mesh.Benchmark),
 		batteryMode:      BatteryModeNormal,
+		coverMinBattery:  DefaultCoverMinBattery,
 		coverTraffic:     true,
 		ctx:              ctx,
 		cancel:           cancel,
-		outgoingMessages: make(chan *protocol.BitchatPacket, 100),
-		incomingMessages: make(chan *protocol.BitchatPacket, 100),
+		outgoingQueue:    mesh.NewPriorityQueue(DefaultQueueCapacity),
+		incomingQueue:    mesh.NewPriorityQueue(DefaultQueueCapacity),
 	}
+	bms.typing = service.NewTypingService(bms.sendTyping, bms.notifyTyping)
+	return bms
 }
 
 // newMessageCache cria um novo cache de mensagens
 func newMessageCache(maxSize int) *MessageCache {
 	return &MessageCache{
 		messages: make(map[string]*CachedMessage),
+		order:    list.New(),
 		maxSize:  maxSize,
 	}
 }
 
-// SetDelegate define o delegate para receber eventos
+// addLocked insere uma mensagem como a mais recente. Exige mc.mutex.
+func (mc *MessageCache) addLocked(id string, msg *CachedMessage) {
+	msg.element = mc.order.PushBack(id)
+	mc.messages[id] = msg
+}
+
+// removeLocked remove uma mensagem do cache. Exige mc.mutex.
+func (mc *MessageCache) removeLocked(id string) {
+	msg, ok := mc.messages[id]
+	if !ok {
+		return
+	}
+	mc.order.Remove(msg.element)
+	delete(mc.messages, id)
+}
+
+// evictOldestLocked remove a mensagem recebida há mais tempo. Exige mc.mutex.
+func (mc *MessageCache) evictOldestLocked() {
+	if oldest := mc.order.Front(); oldest != nil {
+		mc.removeLocked(oldest.Value.(string))
+	}
+}
+
+// SetDelegate define o delegate para receber eventos. O delegate é um
+// inscrito do barramento de eventos como qualquer outro (veja Events);
+// definir outro cancela a inscrição do anterior.
 func (bms *BluetoothMeshService) SetDelegate(delegate MeshDelegate) {
-	bms.delegate = delegate
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+	
+	if bms.unsubscribeDelegate != nil {
+		bms.unsubscribeDelegate()
+		bms.unsubscribeDelegate = nil
+	}
+	if delegate != nil {
+		bms.unsubscribeDelegate = subscribeDelegate(bms.events, delegate)
+	}
 }
 
 // Start inicia o serviço Bluetooth mesh
@@ -165,17 +362,29 @@ func (bms *BluetoothMeshService) Start() error {
 	}
 	
 	// Iniciar goroutines
-	go bms.maintenanceLoop()
-	go bms.processOutgoingMessages()
-	go bms.processIncomingMessages()
+	go bms.maintenanceLoop(bms.ctx)
+	go bms.processOutgoingMessages(bms.ctx)
+	go bms.processIncomingMessages(bms.ctx)
+	go bms.linkProbeLoop(bms.ctx)
+	go bms.helloLoop(bms.ctx)
+	go bms.antiEntropyLoop(bms.ctx)
+	go bms.batteryLoop(bms.ctx)
+	go bms.dutyCycleLoop(bms.ctx)
+	go bms.typingLoop(bms.ctx)
+	bms.startRetriesLocked()
+	
+	// Mensagens não transmitidas antes do último encerramento
+	go bms.restoreOutgoing()
 	
 	bms.isRunning = true
-	fmt.Println("Serviço Bluetooth mesh iniciado com sucesso")
+	slog.Info("serviço Bluetooth mesh iniciado")
 	return nil
 }
 
 // Stop para o serviço Bluetooth mesh
 func (bms *BluetoothMeshService) Stop() {
+	bms.stopRetries()
+	
 	bms.mutex.Lock()
 	defer bms.mutex.Unlock()
 	
@@ -183,15 +392,19 @@ func (bms *BluetoothMeshService) Stop() {
 		return
 	}
 	
+	// Descartar relays aguardando a janela de supressão
+	bms.suppressor.Stop()
+	
 	// Parar provedor de plataforma
 	if bms.platformProvider != nil {
 		if err := bms.platformProvider.Stop(); err != nil {
-			fmt.Printf("Erro ao desligar provedor de plataforma: %v\n", err)
+			slog.Error("erro ao desligar provedor de plataforma", "err", err)
 		}
 	}
 	
 	// Parar goroutines
 	bms.cancel()
+	bms.router.Clear()
 	
 	// Criar novo contexto para próximo início
 	ctx, cancel := context.WithCancel(context.Background())
@@ -199,7 +412,40 @@ func (bms *BluetoothMeshService) Stop() {
 	bms.cancel = cancel
 	
 	bms.isRunning = false
-	fmt.Println("Serviço Bluetooth mesh parado")
+	slog.Info("serviço Bluetooth mesh parado")
+}
+
+// Shutdown encerra o serviço de forma ordenada: anuncia a saída deste nó,
+// aguarda até timeout o envio dos pacotes na fila de saída e para o serviço
+func (bms *BluetoothMeshService) Shutdown(timeout time.Duration) {
+	bms.mutex.RLock()
+	running := bms.isRunning
+	bms.mutex.RUnlock()
+	
+	if running {
+		bms.announceLeave()
+		deadline := time.Now().Add(timeout)
+		for bms.outgoingQueue.Len() > 0 && time.Now().Before(deadline) {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	bms.Stop()
+}
+
+// announceLeave avisa a rede que este nó está saindo. Um pacote
+// MessageTypeLeave sem payload indica a saída da rede; com o nome de um
+// canal, apenas a saída do canal.
+func (bms *BluetoothMeshService) announceLeave() {
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeLeave,
+		SenderID:    bms.deviceID,
+		RecipientID: protocol.BroadcastRecipient,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		TTL:         bms.messageTTL(),
+	}
+	
+	bms.enqueuePacket(packet)
 }
 
 // SendMessage envia uma mensagem através da rede mesh
@@ -210,10 +456,11 @@ func (bms *BluetoothMeshService) SendMessage(message *protocol.BitchatMessage) (
 		Type:       protocol.MessageTypeMessage,
 		SenderID:   bms.deviceID,
 		Timestamp:  uint64(time.Now().UnixMilli()),
-		TTL:        7, // Valor padrão para TTL
+		TTL:        bms.messageTTL(),
 	}
 	
 	// Definir destinatário
+	route := mesh.RouteRelay
 	if message.IsPrivate {
 		// Buscar peer pelo nickname
 		peerID := bms.findPeerIDByNickname(message.RecipientNickname)
@@ -221,50 +468,308 @@ func (bms *BluetoothMeshService) SendMessage(message *protocol.BitchatMessage) (
 			return "", ErrPeerNotFound
 		}
 		
-		// Criptografar conteúdo para mensagem privada
-		encryptedContent, _, err := bms.encryptionService.Encrypt([]byte(message.Content), []byte(peerID))
+		// Destinatários offline recebem a mensagem quando voltarem a ficar online
+		route = bms.GetDeliveryRoute(peerID)
+		if route == mesh.RouteMailbox {
+			return bms.holdForMailbox(message), nil
+		}
+		
+		// Descobrir rota sob demanda se ainda não conhecemos uma
+		bms.discoverRoute(peerID)
+		
+		// Criptografar conteúdo, comprimido se vantajoso, para mensagem privada
+		content := bms.packContent(packet, message.Content)
+		encryptedContent, _, err := bms.encryptionService.Encrypt(content, []byte(peerID))
 		if err != nil {
 			return "", err
 		}
 		
 		packet.RecipientID = []byte(peerID)
 		packet.Payload = encryptedContent
+		
+		// Caminhos alternativos de mensagens urgentes podem ser mais longos
+		if !message.IsUrgent {
+			packet.TTL = bms.unicastTTL(peerID)
+		}
 		message.EncryptedContent = encryptedContent
 		message.IsEncrypted = true
 	} else if message.Channel != "" {
 		// Mensagem de canal (broadcast com criptografia de canal)
 		// Implementação completa requer serviço de canal
 		packet.RecipientID = protocol.BroadcastRecipient
-		packet.Payload = []byte(message.Content)
+		packet.Payload = bms.packContent(packet, message.Content)
 	} else {
 		// Broadcast simples
 		packet.RecipientID = protocol.BroadcastRecipient
-		packet.Payload = []byte(message.Content)
+		packet.Payload = bms.packContent(packet, message.Content)
 	}
 	
 	// Assinar pacote
-	signature, err := bms.encryptionService.Sign(packet.Payload)
+	signature, err := bms.encryptionService.Sign(protocol.SignedPayload(packet))
 	if err != nil {
 		return "", fmt.Errorf("erro ao assinar pacote: %w", err)
 	}
 	packet.Signature = signature
 	
-	// Gerar ID de mensagem
-	messageID := utils.GenerateMessageID(packet)
+	// Proteger o enlace de rajadas de mensagens, como as de bots e scripts
+	if !bms.allowSend(message, packet) {
+		return "", ErrRateLimited
+	}
+	
+	// Gerar ID de mensagem derivado do conteúdo do pacote, que o destinatário
+	// reproduz ao recebê-lo e devolve na confirmação de entrega
+	messageID := mesh.PacketKey(packet)
+	
+	// Mensagens reenviadas da fila persistente mantêm o ID original, ao qual
+	// as confirmações do novo pacote são associadas
+	if message.ID != "" {
+		bms.mutex.Lock()
+		bms.retryAliases[messageID] = message.ID
+		bms.mutex.Unlock()
+		messageID = message.ID
+	}
 	message.ID = messageID
+	packet.ID = messageID
+	bms.queueOutgoing(message, packet)
+	
+	// Mensagens privadas são reenviadas até a confirmação de entrega; as de
+	// canal têm as confirmações contadas contra os membros conhecidos
+	if message.IsPrivate {
+		bms.trackDelivery(packet, string(packet.RecipientID), message.RecipientNickname)
+	} else if message.Channel != "" {
+		bms.trackChannelDelivery(messageID, message.Channel)
+	}
+	
+	// Mensagens urgentes seguem por caminhos redundantes quando conhecidos;
+	// o destinatário descarta as cópias duplicadas
+	if message.IsUrgent && message.IsPrivate {
+		if bms.sendMultipath(packet, UrgentPathCount) {
+			return messageID, nil
+		}
+	}
+	
+	// Mensagens privadas a vizinhos seguem direto; as demais pelo próximo hop
+	// conhecido e, sem rota, o pacote é inundado com o TTL limitado pelo
+	// diâmetro da rede
+	if message.IsPrivate {
+		if route != mesh.RouteDirect || !bms.assignDirectHop(packet) {
+			bms.assignNextHop(packet, "")
+		}
+	}
 	
 	// Enviar para processamento
-	bms.outgoingMessages <- packet
+	bms.enqueuePacket(packet)
 	
 	return messageID, nil
 }
 
 // SetBatteryMode define o modo de economia de bateria
+// Nos modos de economia o rádio passa a dormir parte de cada ciclo, com
+// janelas de atividade combinadas com os vizinhos.
 func (bms *BluetoothMeshService) SetBatteryMode(mode int) {
+	bms.mutex.Lock()
+	bms.batteryMode = mode
+	bms.mutex.Unlock()
+	
+	switch mode {
+	case BatteryModeLow:
+		bms.dutyCycle.SetWakeFraction(LowBatteryWakeFraction)
+	case BatteryModeUltraLow:
+		bms.dutyCycle.SetWakeFraction(UltraLowBatteryWakeFraction)
+	default:
+		bms.dutyCycle.SetWakeFraction(1)
+	}
+	
+	// Ajustar escaneamento, advertising e conexões, se o provedor permitir
+	if controller, ok := bms.platformProvider.(RadioProfileController); ok {
+		if err := controller.SetRadioProfile(RadioProfileForMode(mode)); err != nil {
+			slog.Error("erro ao ajustar perfil do rádio", "err", err)
+		}
+	}
+	
+	// Vizinhos ficam sabendo da nova agenda e da capacidade de relay
+	// imediatamente, inclusive quando voltamos a ficar sempre acordados
+	bms.sendSleepSchedule()
+	bms.sendHello()
+}
+
+// sendSleepSchedule anuncia aos vizinhos diretos as janelas de atividade do rádio
+func (bms *BluetoothMeshService) sendSleepSchedule() {
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeSleepSchedule,
+		SenderID:    bms.deviceID,
+		RecipientID: protocol.BroadcastRecipient,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     protocol.EncodeSleepSchedule(bms.dutyCycle.Schedule()),
+		TTL:         1, // Apenas vizinhos diretos
+	}
+	
+	bms.enqueuePacket(packet)
+}
+
+// handleSleepSchedule registra a agenda de um vizinho e, se isso deslocou
+// nossas janelas de atividade, anuncia a nova agenda para propagar o alinhamento
+func (bms *BluetoothMeshService) handleSleepSchedule(packet *protocol.BitchatPacket) {
+	schedule, err := protocol.DecodeSleepSchedule(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	
+	if bms.dutyCycle.Observe(string(packet.SenderID), schedule) {
+		bms.sendSleepSchedule()
+	}
+}
+
+// dutyCycleLoop liga e desliga o rádio conforme as janelas de atividade,
+// se o provedor de plataforma permitir
+func (bms *BluetoothMeshService) dutyCycleLoop(ctx context.Context) {
+	controller, ok := bms.platformProvider.(RadioDutyController)
+	if !ok {
+		return
+	}
+	
+	ticker := time.NewTicker(DutyCycleCheckInterval)
+	defer ticker.Stop()
+	
+	active := true
+	for {
+		select {
+		case <-ctx.Done():
+			if !active {
+				controller.SetRadioActive(true)
+			}
+			return
+		case <-ticker.C:
+			awake := bms.dutyCycle.IsAwake(time.Now())
+			if awake == active {
+				continue
+			}
+			if err := controller.SetRadioActive(awake); err != nil {
+				slog.Error("erro ao alternar rádio", "err", err)
+				continue
+			}
+			active = awake
+		}
+	}
+}
+
+// SetBatteryLevel informa o nível de bateria atual (0-100). Abaixo dos
+// limites configurados o nó deixa de retransmitir broadcasts e suspende o
+// tráfego de cobertura, mas continua enviando e recebendo as próprias mensagens.
+func (bms *BluetoothMeshService) SetBatteryLevel(level int) {
+	if level < 0 {
+		level = 0
+	} else if level > 100 {
+		level = 100
+	}
+	
+	bms.mutex.Lock()
+	changed := !bms.batteryKnown || bms.batteryLevel != level
+	bms.batteryLevel = level
+	bms.batteryKnown = true
+	state := bms.powerStateLocked()
+	bms.mutex.Unlock()
+	
+	if changed {
+		bms.notifyPowerState(state)
+	}
+	bms.applyBatteryAuto()
+}
+
+// GetBatteryMode retorna o modo de economia de bateria atual
+func (bms *BluetoothMeshService) GetBatteryMode() int {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+	
+	return bms.batteryMode
+}
+
+// GetBatteryLevel retorna o último nível de bateria conhecido
+func (bms *BluetoothMeshService) GetBatteryLevel() (int, bool) {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+	
+	return bms.batteryLevel, bms.batteryKnown
+}
+
+// SetCoverMinBattery define o nível de bateria abaixo do qual o tráfego de
+// cobertura é suspenso (0 = nunca suspender)
+func (bms *BluetoothMeshService) SetCoverMinBattery(level int) {
 	bms.mutex.Lock()
 	defer bms.mutex.Unlock()
 	
-	bms.batteryMode = mode
+	bms.coverMinBattery = level
+}
+
+// coverSuspended verifica se o tráfego de cobertura deve ser suspenso pela bateria
+func (bms *BluetoothMeshService) coverSuspended() bool {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+	
+	return bms.onBatteryLocked() && bms.batteryLevel < bms.coverMinBattery
+}
+
+// batteryLoop lê periodicamente o nível de bateria e o estado de carga do
+// provedor de plataforma até ctx ser cancelado
+func (bms *BluetoothMeshService) batteryLoop(ctx context.Context) {
+	provider, ok := bms.platformProvider.(BatteryLevelProvider)
+	if !ok {
+		return
+	}
+	charging, _ := bms.platformProvider.(ChargingStateProvider)
+	
+	ticker := time.NewTicker(BatteryPollInterval)
+	defer ticker.Stop()
+	
+	for {
+		// Dispositivos sem bateria simplesmente não informam o nível
+		if charging != nil {
+			if isCharging, err := charging.IsCharging(); err == nil {
+				bms.SetCharging(isCharging)
+			}
+		}
+		if level, err := provider.GetBatteryLevel(); err == nil {
+			bms.SetBatteryLevel(level)
+		}
+		
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetAdapterID escolhe o adaptador Bluetooth usado pelo serviço (ex.: hci1).
+// Deve ser chamado antes de Start; vazio seleciona o adaptador padrão.
+func (bms *BluetoothMeshService) SetAdapterID(adapterID string) {
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+	
+	bms.adapterID = adapterID
+}
+
+// SetScanAdapterID dedica um segundo adaptador ao escaneamento contínuo e
+// às conexões como central, deixando o adaptador principal com o advertising
+// e as conexões recebidas. Deve ser chamado antes de Start; vazio usa apenas
+// o adaptador principal.
+func (bms *BluetoothMeshService) SetScanAdapterID(adapterID string) {
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+	
+	bms.scanAdapterID = adapterID
+}
+
+// SetPlatformProvider substitui o provedor que Start criaria para a
+// plataforma, como um provedor sobre um adaptador simulado. Deve ser chamado
+// antes de Start.
+func (bms *BluetoothMeshService) SetPlatformProvider(provider PlatformProvider) {
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+
+	bms.platformProvider = provider
 }
 
 // SetCoverTraffic ativa ou desativa o tráfego de cobertura
@@ -276,23 +781,49 @@ func (bms *BluetoothMeshService) SetCoverTraffic(enabled bool) {
 }
 
 // maintenanceLoop executa tarefas periódicas de manutenção
-func (bms *BluetoothMeshService) maintenanceLoop() {
+func (bms *BluetoothMeshService) maintenanceLoop(ctx context.Context) {
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
 	
 	for {
 		select {
-		case <-bms.ctx.Done():
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			// Limpar mensagens expiradas do cache
 			bms.cleanupExpiredMessages()
 			
+			// Descartar descobertas de rota sem resposta
+			bms.routeDiscovery.CleanupExpired()
+			
+			// Esquecer peers com pontuação recuperada
+			bms.reputation.Cleanup()
+			
+			// Atualizar o mapa da rede
+			bms.topology.Cleanup()
+			bms.sendTopologyAnnounce()
+			
+			// Reanunciar os tópicos dos canais próprios e os canais deste nó
+			bms.announceOwnedChannels()
+			bms.cleanupChannelDirectory()
+			bms.sendChannelList()
+			
+			// Esquecer confirmações de mensagens antigas e limites ociosos
+			bms.cleanupChannelDeliveries()
+			bms.cleanupRateLimits()
+			bms.pruneReconnect()
+			
+			// Tentar de novo as mensagens retidas
+			bms.releaseHeldOutgoing()
+			
+			// Abandonar transferências de arquivo paradas
+			bms.cleanupFileTransfers()
+			
 			// Remover peers inativos
 			bms.cleanupInactivePeers()
 			
-			// Gerar tráfego de cobertura se habilitado
-			if bms.coverTraffic {
+			// Gerar tráfego de cobertura se habilitado e a bateria permitir
+			if bms.coverTraffic && !bms.coverSuspended() {
 				bms.generateCoverTraffic()
 			}
 		}
@@ -300,34 +831,179 @@ func (bms *BluetoothMeshService) maintenanceLoop() {
 }
 
 // processOutgoingMessages processa mensagens de saída
-func (bms *BluetoothMeshService) processOutgoingMessages() {
+// Pacotes de controle são sempre enviados antes de dados em massa
+func (bms *BluetoothMeshService) processOutgoingMessages(ctx context.Context) {
 	for {
-		select {
-		case <-bms.ctx.Done():
+		packet, _, ok := bms.outgoingQueue.Pop(ctx)
+		if !ok {
 			return
-		case packet := <-bms.outgoingMessages:
-			// Adicionar ao cache local
-			messageID := fmt.Sprintf("%x", utils.Hash(string(packet.Payload)))
-			bms.addToMessageCache(messageID, packet, "self")
-			
-			// Enviar pacote usando o provedor de plataforma
-			if err := bms.platformProvider.SendPacket(packet); err != nil {
-				fmt.Printf("Erro ao enviar pacote: %v\n", err)
+		}
+		
+		// Não reprocessar nossos próprios pacotes quando retornarem pela rede
+		bms.router.MarkProcessed(packet)
+		
+		// Adicionar ao cache local
+		messageID := mesh.PacketKey(packet)
+		bms.addToMessageCache(messageID, packet, "self")
+		
+		// Enviar pacote usando o provedor de plataforma
+		if err := bms.sendToProvider(packet); err != nil {
+			slog.Error("erro ao enviar pacote", "err", err)
+			bms.outgoingTransmitted(packet, false)
+			continue
+		}
+		bms.outgoingTransmitted(packet, true)
+		bms.stats.RecordSent(statsPeer(packet), mesh.PacketSize(packet))
+	}
+}
+
+// sendToProvider entrega um pacote ao provedor de plataforma, usando envio
+// direcionado quando o pacote tem um próximo salto designado
+// Se o vizinho designado não puder ser alcançado, a rota é descartada e o
+// pacote volta a ser inundado.
+func (bms *BluetoothMeshService) sendToProvider(packet *protocol.BitchatPacket) error {
+	if packet.NextHop != "" {
+		if sender, ok := bms.platformProvider.(DirectedSender); ok {
+			err := sender.SendPacketTo(packet, packet.NextHop)
+			if err == nil {
+				return nil
 			}
+			
+			bms.router.RemoveRoute(string(packet.RecipientID), packet.NextHop)
+			packet.NextHop = ""
 		}
 	}
+	return bms.platformProvider.SendPacket(packet)
+}
+
+// assignNextHop designa o próximo hop de um pacote unicast segundo a tabela
+// de roteamento. Nada é feito para broadcasts, quando o provedor não suporta
+// envio direcionado ou quando a rota voltaria pelo vizinho de onde o pacote
+// veio (exclude). Retorna true se um próximo hop foi designado.
+func (bms *BluetoothMeshService) assignNextHop(packet *protocol.BitchatPacket, exclude string) bool {
+	if len(packet.RecipientID) == 0 || utils.ByteArraysEqual(packet.RecipientID, protocol.BroadcastRecipient) {
+		return false
+	}
+	if _, ok := bms.platformProvider.(DirectedSender); !ok {
+		return false
+	}
+	
+	nextHop, ok := bms.router.GetNextHop(string(packet.RecipientID))
+	if !ok || nextHop == "" || nextHop == exclude || nextHop == string(bms.deviceID) {
+		return false
+	}
+	
+	packet.NextHop = nextHop
+	return true
+}
+
+// sendMultipath envia cópias de um pacote pelos melhores próximos hops
+// distintos até o destinatário. Retorna false se não há caminhos suficientes
+// ou o provedor não suporta envio direcionado.
+func (bms *BluetoothMeshService) sendMultipath(packet *protocol.BitchatPacket, paths int) bool {
+	if _, ok := bms.platformProvider.(DirectedSender); !ok {
+		return false
+	}
+	
+	nextHops := bms.router.GetNextHops(string(packet.RecipientID), paths)
+	if len(nextHops) < 2 {
+		return false
+	}
+	
+	for _, nextHop := range nextHops {
+		cp := *packet
+		cp.NextHop = nextHop
+		bms.enqueuePacket(&cp)
+	}
+	return true
+}
+
+// enqueuePacket adiciona um pacote à fila de saída com a prioridade do seu tipo
+func (bms *BluetoothMeshService) enqueuePacket(packet *protocol.BitchatPacket) {
+	bms.pushOutgoing(packet, mesh.PacketPriority(packet))
+}
+
+// pushOutgoing adiciona um pacote à fila de saída, notificando descartes
+func (bms *BluetoothMeshService) pushOutgoing(packet *protocol.BitchatPacket, priority mesh.Priority) {
+	if dropped, droppedPriority := bms.outgoingQueue.Push(packet, priority); dropped != nil {
+		bms.notifyPacketDropped(QueueOutgoing, dropped, droppedPriority)
+	}
+}
+
+// enqueueIncoming adiciona um pacote recebido à fila de entrada
+// Chamado pelos provedores de plataforma
+func (bms *BluetoothMeshService) enqueueIncoming(packet *protocol.BitchatPacket) {
+	if dropped, droppedPriority := bms.incomingQueue.Push(packet, mesh.PacketPriority(packet)); dropped != nil {
+		bms.notifyPacketDropped(QueueIncoming, dropped, droppedPriority)
+	}
+}
+
+// notifyPacketDropped publica o descarte de um pacote
+func (bms *BluetoothMeshService) notifyPacketDropped(queue string, packet *protocol.BitchatPacket, priority mesh.Priority) {
+	bms.stats.RecordDropped()
+	
+	bms.events.publish(topicTransport, TransportEvent{
+		Type:     TransportPacketDropped,
+		Queue:    queue,
+		Packet:   packet,
+		Priority: priority,
+	})
+}
+
+// DroppedPackets retorna o número de pacotes descartados nas filas de
+// entrada e saída por excesso de carga
+func (bms *BluetoothMeshService) DroppedPackets() (incoming uint64, outgoing uint64) {
+	return bms.incomingQueue.TotalDropped(), bms.outgoingQueue.TotalDropped()
+}
+
+// QueueDepth retorna o número de pacotes aguardando nas filas de entrada e
+// saída
+func (bms *BluetoothMeshService) QueueDepth() (incoming int, outgoing int) {
+	return bms.incomingQueue.Len(), bms.outgoingQueue.Len()
+}
+
+// CachedMessages retorna o número de mensagens guardadas para peers que
+// ainda não as receberam
+func (bms *BluetoothMeshService) CachedMessages() int {
+	bms.messageCache.mutex.RLock()
+	defer bms.messageCache.mutex.RUnlock()
+	
+	return len(bms.messageCache.messages)
+}
+
+// GetSuppressionStats retorna os relays de broadcast agendados e os
+// cancelados por já terem sido retransmitidos por vizinhos
+func (bms *BluetoothMeshService) GetSuppressionStats() (scheduled uint64, suppressed uint64) {
+	return bms.suppressor.Stats()
+}
+
+// GetMeshStats retorna as estatísticas de tráfego da rede mesh
+func (bms *BluetoothMeshService) GetMeshStats() mesh.StatsSnapshot {
+	return bms.stats.Snapshot()
+}
+
+// statsPeer retorna o peer ao qual um pacote enviado é atribuído nas
+// estatísticas: o próximo hop, o destinatário ou nenhum em broadcasts
+func statsPeer(packet *protocol.BitchatPacket) string {
+	if packet.NextHop != "" {
+		return packet.NextHop
+	}
+	if len(packet.RecipientID) == 0 || utils.ByteArraysEqual(packet.RecipientID, protocol.BroadcastRecipient) {
+		return ""
+	}
+	return string(packet.RecipientID)
 }
 
 // processIncomingMessages processa mensagens recebidas
-func (bms *BluetoothMeshService) processIncomingMessages() {
+func (bms *BluetoothMeshService) processIncomingMessages(ctx context.Context) {
 	for {
-		select {
-		case <-bms.ctx.Done():
+		packet, _, ok := bms.incomingQueue.Pop(ctx)
+		if !ok {
 			return
-		case packet := <-bms.incomingMessages:
-			// Processar mensagem recebida
-			bms.handleIncomingPacket(packet)
 		}
+		
+		// Processar mensagem recebida
+		bms.handleIncomingPacket(packet)
 	}
 }
 
@@ -335,46 +1011,137 @@ func (bms *BluetoothMeshService) processIncomingMessages() {
 // Implementação específica da plataforma
 func (bms *BluetoothMeshService) scanForPeers() {
 	// Placeholder - implementação real depende da biblioteca BLE específica
-	fmt.Println("Escaneando por peers...")
+	slog.Debug("escaneando por peers")
 }
 
 // advertise faz advertising do dispositivo
 // Implementação específica da plataforma
 func (bms *BluetoothMeshService) advertise() {
 	// Placeholder - implementação real depende da biblioteca BLE específica
-	fmt.Println("Fazendo advertising...")
+	slog.Debug("fazendo advertising")
 }
 
 // handleIncomingPacket processa um pacote recebido
 func (bms *BluetoothMeshService) handleIncomingPacket(packet *protocol.BitchatPacket) {
-	// Verificar se já vimos esta mensagem
-	messageID := utils.GenerateMessageID(packet)
-	if bms.seenMessages.Contains(messageID) {
-		return // Ignorar mensagens duplicadas
+	// Descartar duplicadas, expiradas e de peers bloqueados
+	messageID := mesh.PacketKey(packet)
+	bms.stats.RecordReceived(string(packet.SenderID), mesh.PacketSize(packet))
+	if !bms.router.ShouldProcess(packet) {
+		if bms.router.IsProcessed(packet) {
+			bms.stats.RecordDedupHit()
+			
+			// Vizinho retransmitiu um broadcast que ainda aguarda nosso relay
+			bms.suppressor.Overheard(messageID)
+		}
+		return
 	}
 	
-	// Marcar como vista
-	bms.seenMessages.Add(messageID)
+	// Vizinhos com péssimo histórico são ignorados por completo. A reputação
+	// é do enlace de chegada: o remetente declarado pode ser forjado para
+	// penalizar outro peer.
+	senderID := string(packet.SenderID)
+	standing := mesh.StandingGood
+	flooding := false
+	if packet.ReceivedFrom != "" {
+		standing = bms.reputation.Standing(packet.ReceivedFrom)
+		if standing == mesh.StandingIgnored {
+			return
+		}
+		if mesh.CountsTowardFlood(packet) {
+			flooding = bms.reputation.RecordPacket(packet.ReceivedFrom)
+		}
+	}
 	
-	// Verificar TTL
-	if packet.TTL <= 0 {
-		return // TTL expirado, não repassar
+	// TTL inflado além do razoável: penalizar e limitar
+	if int(packet.TTL)+int(packet.HopCount) > MaxAcceptedTTL {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorTTLAbuse)
+		if int(packet.HopCount) >= MaxAcceptedTTL {
+			return
+		}
+		packet.TTL = uint8(MaxAcceptedTTL - int(packet.HopCount))
 	}
 	
 	// Decrementar TTL para repassar
 	packet.TTL--
 	
+	// Pacotes de descoberta de rota possuem encaminhamento próprio
+	switch packet.Type {
+	case protocol.MessageTypeRouteRequest:
+		bms.handleRouteRequest(packet)
+		return
+	case protocol.MessageTypeRouteReply:
+		bms.handleRouteReply(packet)
+		return
+	case protocol.MessageTypeLinkProbe:
+		bms.handleLinkProbe(packet)
+		return
+	case protocol.MessageTypeLinkProbeReply:
+		bms.handleLinkProbeReply(packet)
+		return
+	case protocol.MessageTypeHello:
+		bms.handleHello(packet)
+		return
+	case protocol.MessageTypeSyncRequest:
+		bms.handleSyncRequest(packet)
+		return
+	case protocol.MessageTypeSyncSummary:
+		bms.handleSyncSummary(packet)
+		return
+	case protocol.MessageTypeTraceRequest:
+		bms.handleTraceRequest(packet)
+		return
+	case protocol.MessageTypeTraceReply:
+		bms.handleTraceReply(packet)
+		return
+	case protocol.MessageTypeSleepSchedule:
+		bms.handleSleepSchedule(packet)
+		return
+	case protocol.MessageTypeBenchProbe:
+		bms.handleBenchProbe(packet)
+		return
+	case protocol.MessageTypeBenchReply:
+		bms.handleBenchReply(packet)
+		return
+	}
+	
+	// Distância até a origem alimenta a estimativa do diâmetro da rede
+	bms.router.RecordHopCount(int(packet.HopCount) + 1)
+	
 	// Adicionar ao cache para store-and-forward
-	senderID := string(packet.SenderID)
 	bms.addToMessageCache(messageID, packet, senderID)
 	
 	// Verificar se é para nós
 	isForUs := bms.isPacketForUs(packet)
 	
-	// Repassar para outros peers (relay)
-	if packet.TTL > 0 {
-		// Relay do pacote agora é gerenciado pelo PlatformProvider
-		// Não é mais necessário chamar relayPacket
+	// Repassar para outros peers (relay), respeitando a política configurada;
+	// em áreas densas broadcasts são retransmitidos apenas por probabilidade.
+	// Peers mal pontuados perdem prioridade e, depois, o relay.
+	if !flooding && standing < mesh.StandingNoRelay &&
+		bms.router.ShouldRelay(packet, string(bms.deviceID)) &&
+		bms.router.ShouldGossip(packet, len(bms.directNeighbors())) &&
+		bms.allowRelay(packet) {
+		relayed := *packet
+		relayed.HopCount++
+		if !isForUs {
+			bms.assignNextHop(&relayed, senderID)
+		}
+		
+		priority := mesh.PacketPriority(&relayed)
+		if standing == mesh.StandingDeprioritized {
+			priority = mesh.DemotePriority(priority)
+		}
+		
+		// Broadcasts aguardam um atraso aleatório e são cancelados se vizinhos
+		// suficientes já os retransmitiram nesse intervalo
+		if utils.ByteArraysEqual(relayed.RecipientID, protocol.BroadcastRecipient) {
+			bms.suppressor.Schedule(messageID, func() {
+				bms.pushOutgoing(&relayed, priority)
+				bms.stats.RecordRelayed(mesh.PacketSize(&relayed))
+			})
+		} else {
+			bms.pushOutgoing(&relayed, priority)
+			bms.stats.RecordRelayed(mesh.PacketSize(&relayed))
+		}
 	}
 	
 	// Se for para nós, processar
@@ -383,22 +1150,160 @@ func (bms *BluetoothMeshService) handleIncomingPacket(packet *protocol.BitchatPa
 	}
 }
 
-// isPacketForUs verifica se um pacote é destinado a este dispositivo
-func (bms *BluetoothMeshService) isPacketForUs(packet *protocol.BitchatPacket) bool {
-	// Broadcast é para todos
-	if len(packet.RecipientID) == len(protocol.BroadcastRecipient) {
-		isBroadcast := true
-		for i := 0; i < len(packet.RecipientID); i++ {
-			if packet.RecipientID[i] != protocol.BroadcastRecipient[i] {
-				isBroadcast = false
-				break
-			}
-		}
-		if isBroadcast {
-			return true
-		}
+// reportMisbehavior penaliza por comportamento indevido o vizinho que
+// entregou o pacote. Pacotes sem enlace de chegada conhecido não penalizam
+// ninguém, já que o remetente declarado não é autenticado.
+func (bms *BluetoothMeshService) reportMisbehavior(packet *protocol.BitchatPacket, kind mesh.Misbehavior) {
+	if packet.ReceivedFrom == "" {
+		return
 	}
-	
+	bms.reputation.Penalize(packet.ReceivedFrom, kind)
+}
+
+// GetPeerReputation retorna a pontuação de comportamento de um vizinho
+// direto, registrada pelo enlace com ele
+func (bms *BluetoothMeshService) GetPeerReputation(peerID string) mesh.PeerReputation {
+	if resolver, ok := bms.platformProvider.(NeighborLinkResolver); ok {
+		if link, ok := resolver.NeighborLink(peerID); ok {
+			return bms.reputation.Report(link)
+		}
+	}
+	// Sem enlace conhecido não há histórico: relatório de peer sem problemas
+	return bms.reputation.Report("")
+}
+
+// SetPeerBonding habilita ou desabilita o vínculo BLE com um peer. O vínculo
+// cifra o enlace na camada BLE e acelera as reconexões, mas revela ao sistema
+// operacional uma identidade estável do peer, por isso é escolhido por contato.
+func (bms *BluetoothMeshService) SetPeerBonding(peerID string, enabled bool) error {
+	controller, ok := bms.platformProvider.(BondingController)
+	if !ok {
+		return ErrBondingNotSupported
+	}
+	
+	if err := controller.SetPeerBonding(peerID, enabled); err != nil {
+		return err
+	}
+	
+	bms.mutex.Lock()
+	if enabled {
+		bms.bondedPeers[peerID] = true
+	} else {
+		delete(bms.bondedPeers, peerID)
+	}
+	bms.mutex.Unlock()
+	
+	// Peers com vínculo também são aceitos pela descoberta filtrada
+	return bms.applyScanWhitelist()
+}
+
+// isKnownAnnounce verifica se um anúncio compacto pertence a um peer
+// conhecido: com vínculo BLE, favorito ou visto recentemente
+func (bms *BluetoothMeshService) isKnownAnnounce(announce *protocol.CompactAnnounce) bool {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+	
+	for _, peers := range []map[string]bool{bms.bondedPeers, bms.scanWhitelist} {
+		for peerID := range peers {
+			if announce.MatchesIdentity([]byte(peerID)) {
+				return true
+			}
+		}
+	}
+	for peerID := range bms.peers {
+		if announce.MatchesIdentity([]byte(peerID)) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPeerBonded verifica se o vínculo BLE está habilitado para um peer
+func (bms *BluetoothMeshService) IsPeerBonded(peerID string) bool {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+	
+	return bms.bondedPeers[peerID]
+}
+
+// messageTTL retorna o TTL de mensagens originadas por este nó, ajustado ao
+// diâmetro estimado da rede quando houver amostras suficientes
+func (bms *BluetoothMeshService) messageTTL() uint8 {
+	if ttl, ok := bms.router.GetAdaptiveTTL(); ok {
+		return ttl
+	}
+	return DefaultMessageTTL
+}
+
+// unicastTTL retorna o TTL de um pacote endereçado a um peer: o comprimento da
+// rota conhecida mais uma folga, sem exceder o TTL de mensagens do nó
+func (bms *BluetoothMeshService) unicastTTL(peerID string) uint8 {
+	ttl := bms.messageTTL()
+	if routeTTL, ok := bms.router.GetRouteTTL(peerID); ok && routeTTL < ttl {
+		return routeTTL
+	}
+	return ttl
+}
+
+// allowRelay aplica a política de relay a um pacote alheio
+func (bms *BluetoothMeshService) allowRelay(packet *protocol.BitchatPacket) bool {
+	bms.mutex.RLock()
+	conditions := mesh.RelayConditions{
+		UltraLowPower: bms.batteryMode == BatteryModeUltraLow,
+		BatteryKnown:  bms.onBatteryLocked(),
+		BatteryLevel:  bms.batteryLevel,
+	}
+	bms.mutex.RUnlock()
+	
+	if bms.relayGovernor.GetPolicy().VerifiedOnly {
+		conditions.SenderVerified = bms.isVerifiedSender(packet)
+	}
+	
+	allowed, _ := bms.relayGovernor.Allow(packet, conditions)
+	return allowed
+}
+
+// isVerifiedSender verifica se o pacote foi assinado por um peer cuja chave conhecemos
+func (bms *BluetoothMeshService) isVerifiedSender(packet *protocol.BitchatPacket) bool {
+	if len(packet.Signature) == 0 {
+		return false
+	}
+	
+	valid, err := bms.encryptionService.VerifyWithPeerID(packet.Signature, protocol.SignedPayload(packet), string(packet.SenderID))
+	return err == nil && valid
+}
+
+// SetRelayPolicy define a política de participação no relay de pacotes
+func (bms *BluetoothMeshService) SetRelayPolicy(policy *mesh.RelayPolicy) {
+	bms.relayGovernor.SetPolicy(policy)
+}
+
+// GetRelayPolicy retorna a política de relay atual
+func (bms *BluetoothMeshService) GetRelayPolicy() mesh.RelayPolicy {
+	return bms.relayGovernor.GetPolicy()
+}
+
+// GetRelayStats retorna os pacotes e bytes retransmitidos e as recusas por motivo
+func (bms *BluetoothMeshService) GetRelayStats() (packets uint64, bytes uint64, denied map[string]uint64) {
+	return bms.relayGovernor.Stats()
+}
+
+// isPacketForUs verifica se um pacote é destinado a este dispositivo
+func (bms *BluetoothMeshService) isPacketForUs(packet *protocol.BitchatPacket) bool {
+	// Broadcast é para todos
+	if len(packet.RecipientID) == len(protocol.BroadcastRecipient) {
+		isBroadcast := true
+		for i := 0; i < len(packet.RecipientID); i++ {
+			if packet.RecipientID[i] != protocol.BroadcastRecipient[i] {
+				isBroadcast = false
+				break
+			}
+		}
+		if isBroadcast {
+			return true
+		}
+	}
+	
 	// Verificar se é para o nosso ID
 	return utils.ByteArraysEqual(packet.RecipientID, bms.deviceID)
 }
@@ -416,6 +1321,22 @@ func (bms *BluetoothMeshService) processPacketForUs(packet *protocol.BitchatPack
 		bms.handleDeliveryAck(packet)
 	case protocol.MessageTypeReadReceipt:
 		bms.handleReadReceipt(packet)
+	case protocol.MessageTypeTopologyAnnounce:
+		bms.handleTopologyAnnounce(packet)
+	case protocol.MessageTypeLeave:
+		bms.handleLeave(packet)
+	case protocol.MessageTypeChannelAnnounce:
+		bms.handleChannelAnnounce(packet)
+	case protocol.MessageTypeChannelList:
+		bms.handleChannelList(packet)
+	case protocol.MessageTypeFileOffer:
+		bms.handleFileOffer(packet)
+	case protocol.MessageTypeFileAccept:
+		bms.handleFileAccept(packet)
+	case protocol.MessageTypeFileChunk:
+		bms.handleFileChunk(packet)
+	case protocol.MessageTypeTyping:
+		bms.handleTyping(packet)
 	// Outros tipos de mensagem serão implementados conforme necessário
 	}
 }
@@ -433,10 +1354,11 @@ func (bms *BluetoothMeshService) handleUserMessage(packet *protocol.BitchatPacke
 	
 	// Criar objeto de mensagem
 	message := &protocol.BitchatMessage{
-		ID:        utils.GenerateMessageID(packet),
+		ID:        mesh.PacketKey(packet),
 		Sender:    peer.Name,
 		Timestamp: packet.Timestamp,
-		IsRelay:   false,
+		IsRelay:   packet.HopCount > 0,
+		HopCount:  int(packet.HopCount) + 1,
 		SenderPeerID: senderID,
 	}
 	
@@ -449,7 +1371,12 @@ func (bms *BluetoothMeshService) handleUserMessage(packet *protocol.BitchatPacke
 		// Descriptografar mensagem privada
 		decrypted, err := bms.encryptionService.Decrypt(packet.Payload, []byte(senderID), nil)
 		if err == nil {
-			message.Content = string(decrypted)
+			content, err := decompressContent(packet, decrypted)
+			if err != nil {
+				bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+				return
+			}
+			message.Content = string(content)
 			message.IsEncrypted = true
 		} else {
 			// Falha na descriptografia
@@ -458,8 +1385,14 @@ func (bms *BluetoothMeshService) handleUserMessage(packet *protocol.BitchatPacke
 		}
 	} else {
 		// Mensagem broadcast
-		message.Content = string(packet.Payload)
+		content, err := decompressContent(packet, packet.Payload)
+		if err != nil {
+			bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+			return
+		}
+		message.Content = string(content)
 	}
+	message.Mentions = protocol.ParseMentions(message.Content)
 	
 	// Verificar assinatura se presente
 	if len(packet.Signature) > 0 {
@@ -467,15 +1400,23 @@ func (bms *BluetoothMeshService) handleUserMessage(packet *protocol.BitchatPacke
 		if err != nil || !valid {
 			// Assinatura inválida, marcar de alguma forma
 			message.Content = "[AVISO: Assinatura inválida] " + message.Content
+			bms.reportMisbehavior(packet, mesh.MisbehaviorInvalidSignature)
 		}
 	}
 	
-	// Enviar confirmação de entrega
-	bms.sendDeliveryAck(message.ID, senderID)
+	// Enviar confirmação de entrega, também para reenvios de mensagens já
+	// recebidas, cuja confirmação pode ter se perdido
+	bms.sendDeliveryAck(message.ID, senderID, message.HopCount)
+	if isPrivate && bms.isResentMessage(packet) {
+		return
+	}
 	
-	// Notificar delegate
-	if bms.delegate != nil {
-		bms.delegate.OnMessageReceived(message)
+	// Notificar os inscritos
+	bms.events.publish(topicMessage, MessageEvent{Message: message})
+	
+	// Responder automaticamente durante a ausência do usuário
+	if isPrivate {
+		bms.autoRespond(message)
 	}
 }
 
@@ -483,11 +1424,13 @@ func (bms *BluetoothMeshService) handleUserMessage(packet *protocol.BitchatPacke
 func (bms *BluetoothMeshService) handleAnnounce(packet *protocol.BitchatPacket) {
 	// Extrair informações do peer do payload
 	if len(packet.Payload) < 2 {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
 		return // Payload inválido
 	}
 	
 	nameLen := int(packet.Payload[0])
 	if len(packet.Payload) < 1+nameLen {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
 		return // Payload inválido
 	}
 	
@@ -510,72 +1453,116 @@ func (bms *BluetoothMeshService) handleKeyExchange(packet *protocol.BitchatPacke
 		return
 	}
 	
+	bms.mutex.Lock()
+	blocked := bms.blockIfFingerprintBlockedLocked(peerID)
+	bms.mutex.Unlock()
+	if blocked {
+		return
+	}
+	
 	// Responder com nossa chave pública se necessário
 	bms.sendKeyExchange(peerID)
 }
 
 // handleDeliveryAck processa confirmação de entrega
 func (bms *BluetoothMeshService) handleDeliveryAck(packet *protocol.BitchatPacket) {
-	// Implementação básica - detalhes completos dependem da estrutura de DeliveryAck
-	if len(packet.Payload) < 16 { // Tamanho mínimo para um ID de mensagem
+	ack, err := protocol.DecodeDeliveryAck(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
 		return
 	}
 	
-	// Extrair ID da mensagem original
-	messageID := string(packet.Payload[:16])
+	recipient := ack.RecipientNickname
+	if recipient == "" {
+		recipient = string(packet.SenderID)
+	}
+	
+	// Confirmações de reenvios se referem à mensagem original
+	messageID := bms.resolveRetryID(ack.OriginalMessageID)
+	bms.markDelivered(messageID)
+	
+	// Mensagens de canal são entregues aos poucos, membro a membro
+	if bms.handleChannelDeliveryAck(messageID, string(packet.SenderID), ack, recipient) {
+		return
+	}
 	
 	// Atualizar status de entrega
-	if bms.delegate != nil {
-		info := &protocol.DeliveryInfo{
-			Status:    protocol.DeliveryStatusDelivered,
-			Recipient: string(packet.SenderID),
-			Timestamp: uint64(time.Now().UnixMilli()),
-		}
-		bms.delegate.OnMessageDeliveryChanged(messageID, protocol.DeliveryStatusDelivered, info)
+	info := &protocol.DeliveryInfo{
+		Status:       protocol.DeliveryStatusDelivered,
+		Recipient:    recipient,
+		Timestamp:    uint64(time.Now().UnixMilli()),
+		ReachedPeers: 1,
+		TotalPeers:   1,
+		HopCount:     int(ack.HopCount),
 	}
+	bms.publishDelivery(messageID, info)
 }
 
 // handleReadReceipt processa confirmação de leitura
 func (bms *BluetoothMeshService) handleReadReceipt(packet *protocol.BitchatPacket) {
-	// Similar ao handleDeliveryAck, mas para status de leitura
-	if len(packet.Payload) < 16 {
+	receipt, err := protocol.DecodeReadReceipt(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	
+	// Confirmações de leitura forjadas são descartadas
+	valid, err := bms.encryptionService.VerifyWithPeerID(packet.Signature, protocol.SignedPayload(packet), string(packet.SenderID))
+	if err != nil {
+		return // Peer ainda não anunciou suas chaves
+	}
+	if !valid {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorInvalidSignature)
 		return
 	}
 	
-	messageID := string(packet.Payload[:16])
+	reader := receipt.ReaderNickname
+	if reader == "" {
+		reader = string(packet.SenderID)
+	}
 	
-	if bms.delegate != nil {
-		info := &protocol.DeliveryInfo{
-			Status:    protocol.DeliveryStatusRead,
-			Recipient: string(packet.SenderID),
-			Timestamp: uint64(time.Now().UnixMilli()),
-		}
-		bms.delegate.OnMessageDeliveryChanged(messageID, protocol.DeliveryStatusRead, info)
+	// Uma mensagem lida também foi entregue
+	messageID := bms.resolveRetryID(receipt.OriginalMessageID)
+	bms.markDelivered(messageID)
+	
+	info := &protocol.DeliveryInfo{
+		Status:    protocol.DeliveryStatusRead,
+		Recipient: reader,
+		Timestamp: uint64(receipt.Timestamp.UnixMilli()),
 	}
+	bms.publishDelivery(messageID, info)
 }
 
 // sendDeliveryAck envia confirmação de entrega
-func (bms *BluetoothMeshService) sendDeliveryAck(messageID string, recipientID string) {
+// hopCount é o número de saltos que a mensagem percorreu até este nó
+func (bms *BluetoothMeshService) sendDeliveryAck(messageID string, recipientID string, hopCount int) {
+	ack := &protocol.DeliveryAck{
+		OriginalMessageID: messageID,
+		RecipientNickname: bms.GetNickname(),
+		Timestamp:         time.Now(),
+		HopCount:          uint8(hopCount),
+	}
+	
 	packet := &protocol.BitchatPacket{
 		Version:    1,
 		Type:       protocol.MessageTypeDeliveryAck,
 		SenderID:   bms.deviceID,
 		RecipientID: []byte(recipientID),
 		Timestamp:  uint64(time.Now().UnixMilli()),
-		Payload:    []byte(messageID),
-		TTL:        7,
+		Payload:    protocol.EncodeDeliveryAck(ack),
+		TTL:        bms.unicastTTL(recipientID),
 	}
 	
 	// Assinar
-	signature, err := bms.encryptionService.Sign(packet.Payload)
+	signature, err := bms.encryptionService.Sign(protocol.SignedPayload(packet))
 	if err != nil {
-		fmt.Printf("erro ao assinar pacote: %v\n", err)
+		slog.Error("erro ao assinar pacote", "err", err)
 		return
 	}
 	packet.Signature = signature
 	
 	// Enviar
-	bms.outgoingMessages <- packet
+	bms.enqueuePacket(packet)
 }
 
 // sendKeyExchange envia dados de chave pública para um peer
@@ -594,11 +1581,644 @@ func (bms *BluetoothMeshService) sendKeyExchange(recipientID string) {
 	}
 	
 	// Enviar sem assinar (a própria chave pública é a prova)
-	bms.outgoingMessages <- packet
+	bms.enqueuePacket(packet)
+}
+
+// discoverRoute inicia a descoberta de rota para um peer, se necessário
+func (bms *BluetoothMeshService) discoverRoute(peerID string) {
+	req := bms.routeDiscovery.StartDiscovery(peerID)
+	if req == nil {
+		return
+	}
+	
+	bms.sendRouteRequest(req)
+}
+
+// sendRouteRequest envia uma solicitação de rota em broadcast
+func (bms *BluetoothMeshService) sendRouteRequest(req *protocol.RouteRequest) {
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeRouteRequest,
+		SenderID:    bms.deviceID,
+		RecipientID: protocol.BroadcastRecipient,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     protocol.EncodeRouteRequest(req),
+		TTL:         1, // Cada salto retransmite explicitamente
+	}
+	
+	// Vizinhos só aceitam rotas assinadas por quem as encaminha
+	signature, err := bms.encryptionService.Sign(protocol.SignedPayload(packet))
+	if err != nil {
+		slog.Error("erro ao assinar pacote", "err", err)
+		return
+	}
+	packet.Signature = signature
+	
+	bms.enqueuePacket(packet)
+}
+
+// sendRouteReply envia uma resposta de rota para o próximo salto da rota reversa
+func (bms *BluetoothMeshService) sendRouteReply(rep *protocol.RouteReply) {
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeRouteReply,
+		SenderID:    bms.deviceID,
+		RecipientID: rep.NextHopID,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     protocol.EncodeRouteReply(rep),
+		TTL:         1,
+	}
+	
+	// Vizinhos só aceitam rotas assinadas por quem as encaminha
+	signature, err := bms.encryptionService.Sign(protocol.SignedPayload(packet))
+	if err != nil {
+		slog.Error("erro ao assinar pacote", "err", err)
+		return
+	}
+	packet.Signature = signature
+	
+	bms.enqueuePacket(packet)
+}
+
+// handleRouteRequest processa uma solicitação de rota recebida
+func (bms *BluetoothMeshService) handleRouteRequest(packet *protocol.BitchatPacket) {
+	req, err := protocol.DecodeRouteRequest(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	
+	neighborID, ok := bms.routeNeighbor(packet)
+	if !ok {
+		return
+	}
+	
+	reply, forward := bms.routeDiscovery.HandleRouteRequest(req, neighborID)
+	if reply != nil {
+		bms.sendRouteReply(reply)
+	}
+	if forward != nil {
+		bms.sendRouteRequest(forward)
+	}
+}
+
+// handleRouteReply processa uma resposta de rota recebida
+func (bms *BluetoothMeshService) handleRouteReply(packet *protocol.BitchatPacket) {
+	rep, err := protocol.DecodeRouteReply(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	
+	neighborID, ok := bms.routeNeighbor(packet)
+	if !ok {
+		return
+	}
+	
+	forward, _ := bms.routeDiscovery.HandleRouteReply(rep, neighborID)
+	if forward != nil {
+		bms.sendRouteReply(forward)
+	}
+}
+
+// routeNeighbor autentica o vizinho que entregou um pacote de descoberta de
+// rota antes que ele altere a tabela de rotas: o pacote deve vir direto do
+// remetente, pelo enlace associado a ele, com a assinatura do remetente.
+// Retorna o ID do vizinho.
+func (bms *BluetoothMeshService) routeNeighbor(packet *protocol.BitchatPacket) (string, bool) {
+	senderID := string(packet.SenderID)
+	if packet.HopCount != 0 {
+		return "", false
+	}
+	if resolver, ok := bms.platformProvider.(NeighborLinkResolver); ok && packet.ReceivedFrom != "" {
+		if link, known := resolver.NeighborLink(senderID); !known || link != packet.ReceivedFrom {
+			return "", false
+		}
+	}
+	
+	valid, err := bms.encryptionService.VerifyWithPeerID(packet.Signature, protocol.SignedPayload(packet), senderID)
+	if err != nil {
+		return "", false // Peer ainda não anunciou suas chaves
+	}
+	if !valid {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorInvalidSignature)
+		return "", false
+	}
+	return senderID, true
+}
+
+// Trace inicia o rastreamento da rota até um peer. O resultado é entregue
+// ao delegate, se ele implementar TraceDelegate.
+func (bms *BluetoothMeshService) Trace(peerID string) error {
+	if peerID == "" || peerID == string(bms.deviceID) {
+		return ErrPeerNotFound
+	}
+	
+	req := bms.tracer.Start(peerID)
+	bms.sendTracePacket(protocol.MessageTypeTraceRequest, req)
+	
+	time.AfterFunc(mesh.DefaultTraceTimeout, func() {
+		if result := bms.tracer.Timeout(req.TraceID); result != nil {
+			bms.notifyTraceResult(result)
+		}
+	})
+	
+	return nil
+}
+
+// sendTracePacket envia uma etapa de rastreamento aos vizinhos diretos
+func (bms *BluetoothMeshService) sendTracePacket(msgType protocol.MessageType, trace *protocol.Trace) {
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        msgType,
+		SenderID:    bms.deviceID,
+		RecipientID: protocol.BroadcastRecipient,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     protocol.EncodeTrace(trace),
+		TTL:         1, // Cada salto retransmite explicitamente
+	}
+	
+	bms.enqueuePacket(packet)
+}
+
+// handleTraceRequest registra o salto deste nó, assinado e com o RSSI do
+// enlace com o salto anterior, e retransmite ou responde o rastreamento
+func (bms *BluetoothMeshService) handleTraceRequest(packet *protocol.BitchatPacket) {
+	req, err := protocol.DecodeTrace(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	
+	rssi, _ := bms.linkQuality.RSSI(string(packet.SenderID))
+	if rssi < -128 {
+		rssi = -128
+	} else if rssi > 127 {
+		rssi = 127
+	}
+	
+	hop := protocol.TraceHop{
+		PeerID: bms.deviceID,
+		RSSI:   int8(rssi),
+	}
+	data := protocol.TraceHopData(req.TraceID, req.OriginID, hop.PeerID, hop.RSSI)
+	if signature, err := bms.encryptionService.Sign(data); err == nil {
+		hop.Signature = signature
+	}
+	
+	reply, forward := bms.tracer.HandleRequest(req, hop)
+	if reply != nil {
+		bms.sendTracePacket(protocol.MessageTypeTraceReply, reply)
+	}
+	if forward != nil {
+		bms.sendTracePacket(protocol.MessageTypeTraceRequest, forward)
+	}
+}
+
+// handleTraceReply repassa a resposta de um rastreamento em direção ao
+// originador ou, se somos o originador, verifica os saltos e entrega o resultado
+func (bms *BluetoothMeshService) handleTraceReply(packet *protocol.BitchatPacket) {
+	rep, err := protocol.DecodeTrace(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	
+	forward, result := bms.tracer.HandleReply(rep)
+	if forward != nil {
+		bms.sendTracePacket(protocol.MessageTypeTraceReply, forward)
+	}
+	if result == nil {
+		return
+	}
+	
+	for i, hop := range rep.Hops {
+		if len(hop.Signature) == 0 || i >= len(result.Hops) {
+			continue
+		}
+		data := protocol.TraceHopData(rep.TraceID, rep.OriginID, hop.PeerID, hop.RSSI)
+		valid, err := bms.encryptionService.VerifyWithPeerID(hop.Signature, data, string(hop.PeerID))
+		result.Hops[i].Verified = err == nil && valid
+	}
+	
+	bms.notifyTraceResult(result)
+}
+
+// notifyTraceResult publica o resultado de um rastreamento
+func (bms *BluetoothMeshService) notifyTraceResult(result *mesh.TraceResult) {
+	bms.events.publish(topicActivity, ActivityEvent{Type: ActivityTraceCompleted, Trace: result})
+}
+
+// Benchmark mede o desempenho do enlace com um vizinho direto: envia count
+// cargas de teste de size bytes e aguarda as confirmações, calculando
+// goodput, RTT e perda. Bloqueia até todas as cargas serem confirmadas ou o
+// prazo após a última carga expirar.
+func (bms *BluetoothMeshService) Benchmark(peerID string, count, size int) (*mesh.BenchResult, error) {
+	if peerID == "" || peerID == string(bms.deviceID) {
+		return nil, ErrPeerNotFound
+	}
+	
+	bms.mutex.Lock()
+	bms.benchSequence++
+	bench := mesh.NewBenchmark(bms.benchSequence, peerID, count, size)
+	bms.benchmarks[bench.SessionID()] = bench
+	ctx := bms.ctx
+	bms.mutex.Unlock()
+	
+	defer func() {
+		bms.mutex.Lock()
+		delete(bms.benchmarks, bench.SessionID())
+		bms.mutex.Unlock()
+	}()
+	
+	// Cargas espaçadas para medir o enlace sem esgotar a fila de saída
+	ticker := time.NewTicker(mesh.DefaultBenchInterval)
+	defer ticker.Stop()
+	
+	for seq := 0; seq < bench.Count(); seq++ {
+		now := time.Now()
+		probe := bench.Probe(uint32(seq), now)
+		bms.enqueuePacket(&protocol.BitchatPacket{
+			Version:     1,
+			Type:        protocol.MessageTypeBenchProbe,
+			SenderID:    bms.deviceID,
+			RecipientID: []byte(peerID),
+			Timestamp:   uint64(now.UnixMilli()),
+			Payload:     protocol.EncodeBenchProbe(probe),
+			TTL:         1, // Apenas o enlace com o vizinho
+		})
+		
+		select {
+		case <-ctx.Done():
+			return bench.Result(), ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	
+	select {
+	case <-ctx.Done():
+	case <-bench.Done():
+	case <-time.After(mesh.DefaultBenchTimeout):
+	}
+	return bench.Result(), nil
+}
+
+// handleBenchProbe confirma uma carga de teste endereçada a este nó
+func (bms *BluetoothMeshService) handleBenchProbe(packet *protocol.BitchatPacket) {
+	if !utils.ByteArraysEqual(packet.RecipientID, bms.deviceID) {
+		return
+	}
+	
+	probe, err := protocol.DecodeBenchProbe(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	
+	// A confirmação dispensa o enchimento para não dobrar o tráfego medido
+	reply := &protocol.BenchProbe{SessionID: probe.SessionID, Sequence: probe.Sequence}
+	bms.enqueuePacket(&protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeBenchReply,
+		SenderID:    bms.deviceID,
+		RecipientID: packet.SenderID,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     protocol.EncodeBenchProbe(reply),
+		TTL:         1,
+	})
+}
+
+// handleBenchReply registra a confirmação de uma carga de teste
+func (bms *BluetoothMeshService) handleBenchReply(packet *protocol.BitchatPacket) {
+	if !utils.ByteArraysEqual(packet.RecipientID, bms.deviceID) {
+		return
+	}
+	
+	reply, err := protocol.DecodeBenchProbe(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	
+	bms.mutex.RLock()
+	bench, ok := bms.benchmarks[reply.SessionID]
+	bms.mutex.RUnlock()
+	
+	if ok {
+		bench.RecordReply(reply, time.Now())
+	}
+}
+
+// linkProbeLoop envia sondas periódicas aos vizinhos diretos e atualiza a
+// qualidade dos enlaces usada pelo roteamento
+func (bms *BluetoothMeshService) linkProbeLoop(ctx context.Context) {
+	ticker := time.NewTicker(DefaultLinkProbeInterval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			bms.pollSignalStrength()
+			bms.sendLinkProbe()
+		}
+	}
+}
+
+// sendLinkProbe envia uma sonda de qualidade de enlace em broadcast
+func (bms *BluetoothMeshService) sendLinkProbe() {
+	bms.mutex.Lock()
+	bms.probeSequence++
+	probe := &protocol.LinkProbe{
+		Sequence:  bms.probeSequence,
+		Timestamp: uint64(time.Now().UnixMilli()),
+	}
+	bms.mutex.Unlock()
+	
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeLinkProbe,
+		SenderID:    bms.deviceID,
+		RecipientID: protocol.BroadcastRecipient,
+		Timestamp:   probe.Timestamp,
+		Payload:     protocol.EncodeLinkProbe(probe),
+		TTL:         1, // Apenas vizinhos diretos
+	}
+	
+	bms.enqueuePacket(packet)
+}
+
+// handleLinkProbe registra a sonda recebida e responde para medição de latência
+func (bms *BluetoothMeshService) handleLinkProbe(packet *protocol.BitchatPacket) {
+	probe, err := protocol.DecodeLinkProbe(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	
+	senderID := string(packet.SenderID)
+	bms.linkQuality.RecordSequence(senderID, probe.Sequence)
+	bms.refreshLinkQuality(senderID)
+	
+	reply := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeLinkProbeReply,
+		SenderID:    bms.deviceID,
+		RecipientID: packet.SenderID,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     packet.Payload, // Ecoar a sonda original
+		TTL:         1,
+	}
+	
+	bms.enqueuePacket(reply)
+}
+
+// handleLinkProbeReply calcula o tempo de ida e volta a partir da resposta
+func (bms *BluetoothMeshService) handleLinkProbeReply(packet *protocol.BitchatPacket) {
+	if !utils.ByteArraysEqual(packet.RecipientID, bms.deviceID) {
+		return
+	}
+	
+	probe, err := protocol.DecodeLinkProbe(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	
+	rtt := time.Since(time.UnixMilli(int64(probe.Timestamp)))
+	if rtt < 0 {
+		return
+	}
+	
+	senderID := string(packet.SenderID)
+	bms.linkQuality.RecordLatency(senderID, rtt)
+	bms.refreshLinkQuality(senderID)
+}
+
+// pollSignalStrength consulta o RSSI dos peers quando o provedor o suporta
+func (bms *BluetoothMeshService) pollSignalStrength() {
+	provider, ok := bms.platformProvider.(SignalStrengthProvider)
+	if !ok {
+		return
+	}
+	
+	bms.mutex.RLock()
+	peerIDs := make([]string, 0, len(bms.peers))
+	for id := range bms.peers {
+		peerIDs = append(peerIDs, id)
+	}
+	bms.mutex.RUnlock()
+	
+	for _, peerID := range peerIDs {
+		if rssi := provider.GetPeerSignalStrength(peerID); rssi != 0 {
+			bms.UpdatePeerRSSI(peerID, rssi)
+		}
+	}
+}
+
+// UpdatePeerRSSI registra uma nova leitura de RSSI de um vizinho
+func (bms *BluetoothMeshService) UpdatePeerRSSI(peerID string, rssi int) {
+	bms.mutex.Lock()
+	if peer, exists := bms.peers[peerID]; exists {
+		peer.RSSI = rssi
+	}
+	bms.mutex.Unlock()
+	
+	bms.linkQuality.RecordRSSI(peerID, rssi)
+	bms.refreshLinkQuality(peerID)
+	
+	// Tendências do sinal indicam se o vizinho está chegando ou partindo
+	if event := bms.proximity.Record(peerID, rssi); event != nil {
+		bms.events.publish(topicPeer, PeerEvent{Type: PeerProximityChanged, PeerID: peerID, Proximity: *event})
+	}
+}
+
+// refreshLinkQuality propaga a qualidade atual do enlace para o roteador
+func (bms *BluetoothMeshService) refreshLinkQuality(peerID string) {
+	bms.router.SetLinkQuality(peerID, bms.linkQuality.Quality(peerID))
+}
+
+// helloLoop envia hellos periódicos aos vizinhos diretos e expira os
+// vizinhos que pararam de responder
+func (bms *BluetoothMeshService) helloLoop(ctx context.Context) {
+	ticker := time.NewTicker(bms.hello.Interval())
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, peerID := range bms.hello.ExpireNeighbors() {
+				bms.peerConnected(peerID, false)
+			}
+			bms.updatePresence()
+			bms.sendHello()
+			
+			// Agendas de vizinhos são renovadas junto com os hellos
+			bms.dutyCycle.Expire()
+			if bms.dutyCycle.Enabled() {
+				bms.sendSleepSchedule()
+			}
+			bms.checkPartitionHeal()
+		}
+	}
+}
+
+// sendHello anuncia aos vizinhos diretos a lista de vizinhos deste nó
+func (bms *BluetoothMeshService) sendHello() {
+	hello := bms.hello.BuildHello(bms.linkQuality.Quality)
+	hello.Flags = bms.capabilityFlags()
+	
+	// O anúncio compacto do advertising leva as mesmas capacidades
+	if controller, ok := bms.platformProvider.(AnnounceFlagsController); ok {
+		if err := controller.SetAnnounceFlags(hello.Flags); err != nil {
+			slog.Error("erro ao atualizar anúncio", "err", err)
+		}
+	}
+	
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeHello,
+		SenderID:    bms.deviceID,
+		RecipientID: protocol.BroadcastRecipient,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     protocol.EncodeHello(hello),
+		TTL:         1, // Apenas vizinhos diretos
+	}
+	
+	bms.enqueuePacket(packet)
+}
+
+// capabilityFlags retorna as capacidades anunciadas aos vizinhos. O nó deixa
+// de se oferecer como intermediário quando a política de relay ou a bateria
+// não permitem retransmitir.
+func (bms *BluetoothMeshService) capabilityFlags() uint8 {
+	policy := bms.relayGovernor.GetPolicy()
+	
+	bms.mutex.RLock()
+	ultraLow := bms.batteryMode == BatteryModeUltraLow
+	batteryKnown := bms.onBatteryLocked()
+	batteryLevel := bms.batteryLevel
+	bms.mutex.RUnlock()
+	
+	var flags uint8
+	if !bms.router.GetConfig().AllowRelay ||
+		(ultraLow && !policy.RelayInUltraLowPower) ||
+		(batteryKnown && batteryLevel < policy.MinBatteryLevel) {
+		flags |= protocol.HelloFlagNoRelay
+	}
+	
+	// Sem leitura de bateria, ou carregando, o dispositivo é considerado
+	// ligado à rede elétrica
+	if !batteryKnown {
+		flags |= protocol.HelloFlagMainsPowered
+	}
+	
+	return flags
+}
+
+// handleHello processa um hello de um vizinho direto, atualizando as rotas
+// diretas e de dois saltos
+func (bms *BluetoothMeshService) handleHello(packet *protocol.BitchatPacket) {
+	hello, err := protocol.DecodeHello(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	
+	// Vizinho novo recebe nosso hello imediatamente para acelerar a convergência
+	senderID := string(packet.SenderID)
+	if bms.hello.HandleHello(senderID, hello) {
+		bms.sendHello()
+	}
+	bms.peerConnected(senderID, true)
+	bms.peerSeen(senderID)
+}
+
+// directNeighbors retorna os vizinhos diretos com a qualidade dos enlaces
+func (bms *BluetoothMeshService) directNeighbors() map[string]int {
+	neighbors := make(map[string]int)
+	for _, peerID := range bms.router.GetDirectPeers() {
+		quality, ok := bms.router.GetLinkQuality(peerID)
+		if !ok {
+			quality = bms.linkQuality.Quality(peerID)
+		}
+		neighbors[peerID] = quality
+	}
+	for _, peerID := range bms.linkQuality.Peers() {
+		neighbors[peerID] = bms.linkQuality.Quality(peerID)
+	}
+	return neighbors
+}
+
+// sendTopologyAnnounce anuncia os vizinhos diretos deste nó
+func (bms *BluetoothMeshService) sendTopologyAnnounce() {
+	neighbors := bms.directNeighbors()
+	if len(neighbors) == 0 {
+		return
+	}
+	
+	announce := &protocol.TopologyAnnounce{}
+	for peerID, quality := range neighbors {
+		announce.Neighbors = append(announce.Neighbors, protocol.NeighborInfo{
+			PeerID:  []byte(peerID),
+			Quality: uint8(quality),
+		})
+	}
+	
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeTopologyAnnounce,
+		SenderID:    bms.deviceID,
+		RecipientID: protocol.BroadcastRecipient,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     protocol.EncodeTopologyAnnounce(announce),
+		TTL:         DefaultTopologyTTL,
+	}
+	
+	bms.enqueuePacket(packet)
+}
+
+// handleTopologyAnnounce registra os vizinhos anunciados por um nó
+func (bms *BluetoothMeshService) handleTopologyAnnounce(packet *protocol.BitchatPacket) {
+	announce, err := protocol.DecodeTopologyAnnounce(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	
+	neighbors := make(map[string]int, len(announce.Neighbors))
+	for _, n := range announce.Neighbors {
+		neighbors[string(n.PeerID)] = int(n.Quality)
+	}
+	
+	bms.topology.UpdateNeighbors(string(packet.SenderID), neighbors)
+}
+
+// GetTopology retorna uma fotografia do mapa atual da rede mesh
+func (bms *BluetoothMeshService) GetTopology() *mesh.TopologySnapshot {
+	bms.mutex.RLock()
+	names := make(map[string]string, len(bms.peers)+1)
+	for id, peer := range bms.peers {
+		names[id] = peer.Name
+	}
+	names[string(bms.deviceID)] = bms.deviceName
+	bms.mutex.RUnlock()
+	
+	return bms.topology.Snapshot(string(bms.deviceID), bms.directNeighbors(), bms.router, names)
 }
 
 // addToMessageCache adiciona uma mensagem ao cache
 func (bms *BluetoothMeshService) addToMessageCache(messageID string, packet *protocol.BitchatPacket, originalSender string) {
+	// Avisos de digitação perdem o sentido em segundos e não ocupam o cache
+	if packet.Type == protocol.MessageTypeTyping {
+		return
+	}
+	
+	favorite := bms.IsFavorite(string(packet.RecipientID))
+	
 	bms.messageCache.mutex.Lock()
 	defer bms.messageCache.mutex.Unlock()
 	
@@ -610,21 +2230,7 @@ func (bms *BluetoothMeshService) addToMessageCache(messageID string, packet *pro
 	// Verificar tamanho do cache
 	if len(bms.messageCache.messages) >= bms.messageCache.maxSize {
 		// Remover mensagem mais antiga
-		var oldestID string
-		var oldestTime time.Time
-		first := true
-		
-		for id, msg := range bms.messageCache.messages {
-			if first || msg.ReceivedAt.Before(oldestTime) {
-				oldestID = id
-				oldestTime = msg.ReceivedAt
-				first = false
-			}
-		}
-		
-		if oldestID != "" {
-			delete(bms.messageCache.messages, oldestID)
-		}
+		bms.messageCache.evictOldestLocked()
 	}
 	
 	// Adicionar nova mensagem
@@ -634,14 +2240,17 @@ func (bms *BluetoothMeshService) addToMessageCache(messageID string, packet *pro
 	} else if bms.batteryMode == BatteryModeUltraLow {
 		ttl = DefaultMessageCacheTTL / 4
 	}
+	if favorite {
+		ttl = FavoriteMessageCacheTTL
+	}
 	
-	bms.messageCache.messages[messageID] = &CachedMessage{
+	bms.messageCache.addLocked(messageID, &CachedMessage{
 		Packet:         packet,
 		ReceivedAt:     time.Now(),
 		ExpiresAt:      time.Now().Add(ttl),
 		DeliveredTo:    make(map[string]bool),
 		OriginalSender: originalSender,
-	}
+	})
 }
 
 // Removemos broadcastToNearbyPeers e relayPacket pois agora são gerenciados pelo PlatformProvider
@@ -654,7 +2263,7 @@ func (bms *BluetoothMeshService) cleanupExpiredMessages() {
 	now := time.Now()
 	for id, msg := range bms.messageCache.messages {
 		if now.After(msg.ExpiresAt) {
-			delete(bms.messageCache.messages, id)
+			bms.messageCache.removeLocked(id)
 		}
 	}
 }
@@ -667,16 +2276,28 @@ func (bms *BluetoothMeshService) cleanupInactivePeers() {
 	threshold := time.Now().Add(-10 * time.Minute)
 	for id, peer := range bms.peers {
 		if peer.LastSeen.Before(threshold) {
-			delete(bms.peers, id)
-			
-			// Notificar delegate
-			if bms.delegate != nil {
-				bms.delegate.OnPeerLost(id)
-			}
+			bms.removePeerLocked(id)
 		}
 	}
 }
 
+// removePeerLocked esquece um peer e avisa o delegate. Exige bms.mutex.
+func (bms *BluetoothMeshService) removePeerLocked(id string) {
+	delete(bms.peers, id)
+	bms.linkQuality.Remove(id)
+	bms.proximity.Remove(id)
+	bms.presence.Remove(id)
+	bms.router.RemovePeer(id)
+	bms.topology.RemoveNode(id)
+	bms.hello.Remove(id)
+	bms.dutyCycle.Remove(id)
+	bms.forgetChannelMemberLocked(id)
+	bms.typing.PeerLeft(id)
+	
+	// Notificar os inscritos
+	bms.events.publish(topicPeer, PeerEvent{Type: PeerLost, PeerID: id})
+}
+
 // generateCoverTraffic gera tráfego de cobertura para privacidade
 func (bms *BluetoothMeshService) generateCoverTraffic() {
 	// Implementação básica - enviar pacotes vazios ou aleatórios
@@ -698,7 +2319,7 @@ func (bms *BluetoothMeshService) generateCoverTraffic() {
 		
 		// Enviar com probabilidade baixa
 		if utils.RandomInt(100) < 10 { // 10% de chance
-			bms.outgoingMessages <- packet
+			bms.pushOutgoing(packet, mesh.PriorityCover)
 		}
 	}
 }
@@ -720,6 +2341,8 @@ func (bms *BluetoothMeshService) addOrUpdatePeer(peerID string, name string, pub
 	}
 	
 	// Atualizar informações
+	oldName := peer.Name
+	hadKeys := peer.PublicKeyData != nil
 	peer.LastSeen = time.Now()
 	peer.Name = name
 	if publicKeyData != nil {
@@ -727,12 +2350,343 @@ func (bms *BluetoothMeshService) addOrUpdatePeer(peerID string, name string, pub
 		
 		// Adicionar chave pública ao serviço de criptografia
 		bms.encryptionService.AddPeerPublicKey(peerID, publicKeyData)
+		
+		// Identidades bloqueadas continuam bloqueadas com outro ID ou apelido
+		if bms.blockIfFingerprintBlockedLocked(peerID) {
+			if isNew {
+				delete(bms.peers, peerID)
+			}
+			return
+		}
+	}
+	
+	// Notificar os inscritos se for um novo peer
+	if isNew {
+		bms.events.publish(topicPeer, PeerEvent{Type: PeerDiscovered, PeerID: peerID, Name: name})
+	}
+	
+	// Anúncios mantêm o peer online; ao voltar, ele pode ser o destinatário,
+	// ou o caminho, de mensagens retidas
+	bms.peerSeen(peerID)
+	
+	// Favoritos são anunciados ao chegar, ou quando as chaves revelam a identidade
+	if !hadKeys && publicKeyData != nil && bms.isFavoriteLocked(peerID) {
+		bms.events.publish(topicPeer, PeerEvent{Type: PeerFavoriteInRange, PeerID: peerID, Name: name})
+	}
+	
+	// Peers conhecidos podem trocar de apelido durante a execução
+	if !isNew && oldName != name {
+		bms.events.publish(topicPeer, PeerEvent{Type: PeerNicknameChanged, PeerID: peerID, Name: name, OldName: oldName})
+	}
+	
+	// Entregar mensagens guardadas enquanto o peer estava ausente
+	if isNew {
+		bms.deliverCachedMessages(peerID)
+	}
+}
+
+// deliverCachedMessages reenvia a um peer recém-visto as mensagens em cache
+// endereçadas a ele e o tráfego de canal recente que ele ainda não recebeu
+func (bms *BluetoothMeshService) deliverCachedMessages(peerID string) {
+	bms.messageCache.mutex.Lock()
+	
+	now := time.Now()
+	var pending []*CachedMessage
+	for _, msg := range bms.messageCache.messages {
+		if msg.DeliveredTo[peerID] || msg.OriginalSender == peerID || now.After(msg.ExpiresAt) {
+			continue
+		}
+		if bms.router.IsExpired(msg.Packet) {
+			continue
+		}
+		if string(msg.Packet.SenderID) == peerID {
+			continue
+		}
+		
+		switch {
+		case string(msg.Packet.RecipientID) == peerID:
+			pending = append(pending, msg)
+		case msg.Packet.Type == protocol.MessageTypeMessage &&
+			utils.ByteArraysEqual(msg.Packet.RecipientID, protocol.BroadcastRecipient) &&
+			now.Sub(msg.ReceivedAt) <= DefaultChannelReplayWindow:
+			pending = append(pending, msg)
+		}
+	}
+	
+	// Mais antigas primeiro, preservando a ordem original da conversa
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].ReceivedAt.Before(pending[j].ReceivedAt)
+	})
+	if len(pending) > MaxCachedReplay {
+		pending = pending[len(pending)-MaxCachedReplay:]
 	}
 	
-	// Notificar delegate se for um novo peer
-	if isNew && bms.delegate != nil {
-		bms.delegate.OnPeerDiscovered(peerID, name)
+	packets := make([]*protocol.BitchatPacket, 0, len(pending))
+	for _, msg := range pending {
+		msg.DeliveredTo[peerID] = true
+		packets = append(packets, replayPacket(msg))
+	}
+	
+	bms.messageCache.mutex.Unlock()
+	
+	for _, packet := range packets {
+		bms.enqueuePacket(packet)
+	}
+}
+
+// replayPacket copia um pacote em cache para reenvio
+// Deve ser chamada com o mutex do cache adquirido
+func replayPacket(msg *CachedMessage) *protocol.BitchatPacket {
+	packet := *msg.Packet
+	packet.NextHop = ""
+	if packet.TTL == 0 {
+		packet.TTL = 1
+	}
+	return &packet
+}
+
+// checkPartitionHeal verifica se um grupo de peers voltou a ser alcançável e,
+// nesse caso, sincroniza o histórico recente com o lado recém-alcançado
+func (bms *BluetoothMeshService) checkPartitionHeal() {
+	healed := bms.partition.Observe(bms.router.GetAllPeers())
+	if len(healed) == 0 {
+		return
+	}
+	
+	if len(healed) > MaxSyncPeers {
+		healed = healed[:MaxSyncPeers]
+	}
+	for _, peerID := range healed {
+		bms.sendSyncRequest(peerID, nil, 0)
+	}
+}
+
+// recentDigests retorna os resumos das mensagens em cache recebidas desde
+// since, das mais recentes para as mais antigas
+func (bms *BluetoothMeshService) recentDigests(since time.Time) []uint64 {
+	bms.messageCache.mutex.RLock()
+	recent := make([]*CachedMessage, 0)
+	for _, msg := range bms.messageCache.messages {
+		if msg.Packet.Type == protocol.MessageTypeMessage && msg.ReceivedAt.After(since) {
+			recent = append(recent, msg)
+		}
+	}
+	bms.messageCache.mutex.RUnlock()
+	
+	sort.Slice(recent, func(i, j int) bool {
+		return recent[i].ReceivedAt.After(recent[j].ReceivedAt)
+	})
+	
+	digests := make([]uint64, 0, len(recent))
+	for _, msg := range recent {
+		digests = append(digests, mesh.MessageDigest(mesh.PacketKey(msg.Packet)))
+	}
+	return digests
+}
+
+// sendSyncRequest envia a um peer os resumos das mensagens recentes que já
+// possuímos, para que ele reenvie apenas as que faltam.
+// Se ranges não for vazio, a sincronização se restringe a essas faixas.
+// maxBytes, se não for zero, é o orçamento de dados pedido ao peer.
+func (bms *BluetoothMeshService) sendSyncRequest(peerID string, ranges []protocol.DigestRange, maxBytes uint32) {
+	since := time.Now().Add(-DefaultSyncWindow)
+	
+	// Mais recentes primeiro: são as que o outro lado provavelmente também tem
+	req := &protocol.SyncRequest{
+		Since:    uint64(since.UnixMilli()),
+		Ranges:   ranges,
+		MaxBytes: maxBytes,
+	}
+	for _, digest := range bms.recentDigests(since) {
+		if len(req.Digests) == MaxSyncDigests {
+			break
+		}
+		if mesh.DigestInRanges(digest, ranges) {
+			req.Digests = append(req.Digests, digest)
+		}
+	}
+	
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeSyncRequest,
+		SenderID:    bms.deviceID,
+		RecipientID: []byte(peerID),
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     protocol.EncodeSyncRequest(req),
+	}
+	bms.router.PrepareOutgoingPacket(packet)
+	
+	bms.enqueuePacket(packet)
+}
+
+// handleSyncRequest responde a uma solicitação de sincronização reenviando
+// as mensagens recentes que o solicitante não informou possuir
+func (bms *BluetoothMeshService) handleSyncRequest(packet *protocol.BitchatPacket) {
+	if !utils.ByteArraysEqual(packet.RecipientID, bms.deviceID) {
+		// Solicitação para outro peer: apenas repassar
+		if bms.router.ShouldRelay(packet, string(bms.deviceID)) && bms.allowRelay(packet) {
+			relayed := *packet
+			relayed.HopCount++
+			bms.enqueuePacket(&relayed)
+		}
+		return
+	}
+	
+	req, err := protocol.DecodeSyncRequest(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
 	}
+	
+	known := make(map[uint64]bool, len(req.Digests))
+	for _, digest := range req.Digests {
+		known[digest] = true
+	}
+	
+	requester := string(packet.SenderID)
+	since := time.UnixMilli(int64(req.Since))
+	if min := time.Now().Add(-DefaultSyncWindow); since.Before(min) {
+		since = min
+	}
+	
+	bms.messageCache.mutex.Lock()
+	
+	now := time.Now()
+	var missing []*CachedMessage
+	for _, msg := range bms.messageCache.messages {
+		if msg.Packet.Type != protocol.MessageTypeMessage || msg.ReceivedAt.Before(since) || now.After(msg.ExpiresAt) {
+			continue
+		}
+		if bms.router.IsExpired(msg.Packet) {
+			continue
+		}
+		if msg.DeliveredTo[requester] || msg.OriginalSender == requester || string(msg.Packet.SenderID) == requester {
+			continue
+		}
+		digest := mesh.MessageDigest(mesh.PacketKey(msg.Packet))
+		if known[digest] || !mesh.DigestInRanges(digest, req.Ranges) {
+			continue
+		}
+		missing = append(missing, msg)
+	}
+	
+	// Mais antigas primeiro, limitando ao trecho mais recente
+	maxReplay, budget := syncReplayLimits(req)
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i].ReceivedAt.Before(missing[j].ReceivedAt)
+	})
+	if len(missing) > maxReplay {
+		missing = missing[len(missing)-maxReplay:]
+	}
+	
+	// Respeitar o limite de banda por solicitação, priorizando as mais recentes
+	for i := len(missing) - 1; i >= 0; i-- {
+		budget -= len(missing[i].Packet.Payload)
+		if budget < 0 {
+			missing = missing[i+1:]
+			break
+		}
+	}
+	
+	packets := make([]*protocol.BitchatPacket, 0, len(missing))
+	for _, msg := range missing {
+		msg.DeliveredTo[requester] = true
+		packets = append(packets, replayPacket(msg))
+	}
+	
+	bms.messageCache.mutex.Unlock()
+	
+	for _, p := range packets {
+		bms.enqueuePacket(p)
+	}
+}
+
+// antiEntropyLoop troca periodicamente resumos de mensagens recentes com um
+// vizinho aleatório para recuperar mensagens perdidas pela inundação
+func (bms *BluetoothMeshService) antiEntropyLoop(ctx context.Context) {
+	ticker := time.NewTicker(mesh.DefaultAntiEntropyInterval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			bms.mutex.RLock()
+			ultraLow := bms.batteryMode == BatteryModeUltraLow
+			bms.mutex.RUnlock()
+			if ultraLow {
+				continue
+			}
+			
+			neighbors := bms.hello.Neighbors()
+			if len(neighbors) == 0 {
+				continue
+			}
+			bms.sendSyncSummary(neighbors[utils.RandomInt(len(neighbors))])
+		}
+	}
+}
+
+// sendSyncSummary inicia uma rodada de anti-entropia com um vizinho
+func (bms *BluetoothMeshService) sendSyncSummary(peerID string) {
+	since := time.Now().Add(-DefaultSyncWindow)
+	summary := &protocol.SyncSummary{
+		Since:  uint64(since.UnixMilli()),
+		Ranges: mesh.BuildDigestRanges(bms.recentDigests(since), mesh.DefaultDigestRanges),
+	}
+	
+	bms.sendSummaryPacket(peerID, summary)
+}
+
+// sendSummaryPacket envia um SyncSummary a um vizinho direto
+func (bms *BluetoothMeshService) sendSummaryPacket(peerID string, summary *protocol.SyncSummary) {
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeSyncSummary,
+		SenderID:    bms.deviceID,
+		RecipientID: []byte(peerID),
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     protocol.EncodeSyncSummary(summary),
+		TTL:         1, // Apenas vizinhos diretos
+	}
+	
+	bms.enqueuePacket(packet)
+}
+
+// handleSyncSummary processa uma etapa da rodada de anti-entropia.
+// Um resumo inicial é respondido com as faixas divergentes; a resposta leva o
+// iniciador a solicitar as mensagens que faltam nessas faixas.
+func (bms *BluetoothMeshService) handleSyncSummary(packet *protocol.BitchatPacket) {
+	if !utils.ByteArraysEqual(packet.RecipientID, bms.deviceID) {
+		return
+	}
+	
+	summary, err := protocol.DecodeSyncSummary(packet.Payload)
+	if err != nil {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+	
+	peerID := string(packet.SenderID)
+	if summary.Reply {
+		if len(summary.Ranges) > 0 {
+			bms.sendSyncRequest(peerID, summary.Ranges, 0)
+		}
+		return
+	}
+	
+	since := time.UnixMilli(int64(summary.Since))
+	mismatched := mesh.MismatchedRanges(summary.Ranges, bms.recentDigests(since))
+	if len(mismatched) == 0 {
+		return
+	}
+	
+	bms.sendSummaryPacket(peerID, &protocol.SyncSummary{
+		Since:  summary.Since,
+		Reply:  true,
+		Ranges: mismatched,
+	})
 }
 
 // getPeer obtém informações de um peer
@@ -0,0 +1,78 @@
+package bluetooth
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// MaxNicknameLength é o maior apelido, em bytes, que cabe em um anúncio
+const MaxNicknameLength = 255
+
+// ErrInvalidNickname indica um apelido vazio ou longo demais para o anúncio
+var ErrInvalidNickname = errors.New("apelido inválido")
+
+// NicknameDelegate pode ser implementado pelo delegate para ser notificado
+// quando um peer conhecido troca de apelido
+type NicknameDelegate interface {
+	OnPeerNicknameChanged(peerID string, oldName string, newName string)
+}
+
+// SetNickname troca o apelido deste nó durante a execução. As próximas
+// mensagens e confirmações passam a usar o novo apelido, e os peers são
+// avisados por um novo anúncio.
+func (bms *BluetoothMeshService) SetNickname(name string) error {
+	if name == "" || len(name) > MaxNicknameLength {
+		return ErrInvalidNickname
+	}
+
+	bms.mutex.Lock()
+	if bms.deviceName == name {
+		bms.mutex.Unlock()
+		return nil
+	}
+	bms.deviceName = name
+	running := bms.isRunning
+	bms.mutex.Unlock()
+
+	// O anúncio compacto do advertising leva o hash do apelido
+	if controller, ok := bms.platformProvider.(NicknameController); ok {
+		if err := controller.SetNickname(name); err != nil {
+			slog.Error("erro ao atualizar advertising", "err", err)
+		}
+	}
+
+	if running {
+		bms.sendAnnounce()
+	}
+	return nil
+}
+
+// GetNickname retorna o apelido atual deste nó
+func (bms *BluetoothMeshService) GetNickname() string {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	return bms.deviceName
+}
+
+// sendAnnounce anuncia a toda a rede o apelido e as chaves públicas deste nó
+func (bms *BluetoothMeshService) sendAnnounce() {
+	name := bms.GetNickname()
+	payload := append([]byte{byte(len(name))}, name...)
+	payload = append(payload, bms.encryptionService.GetCombinedPublicKeyData()...)
+
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeAnnounce,
+		SenderID:    bms.deviceID,
+		RecipientID: protocol.BroadcastRecipient,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     payload,
+		TTL:         bms.messageTTL(),
+	}
+
+	bms.enqueuePacket(packet)
+}
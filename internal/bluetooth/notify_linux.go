@@ -0,0 +1,119 @@
+package bluetooth
+
+import (
+	"log/slog"
+
+	"github.com/muka/go-bluetooth/bluez"
+	"github.com/muka/go-bluetooth/bluez/profile/device"
+	"github.com/muka/go-bluetooth/bluez/profile/gatt"
+)
+
+// subscribeNotifications assina as notificações da característica TX de um
+// peer ao qual este nó está conectado como central e repassa cada valor
+// recebido ao callback de dados
+func (lba *LinuxBluetoothAdapter) subscribeNotifications(dev *device.Device1) {
+	address, err := dev.GetAddress()
+	if err != nil {
+		return
+	}
+
+	lba.deviceMutex.RLock()
+	_, subscribed := lba.notifications[address]
+	lba.deviceMutex.RUnlock()
+
+	if subscribed {
+		return
+	}
+
+	char, err := dev.GetCharByUUID(NotifyCharacteristicUUID)
+	if err != nil || char == nil {
+		slog.Warn("dispositivo sem característica TX do serviço Bitchat", "device", address)
+		return
+	}
+
+	values, err := char.WatchProperties()
+	if err != nil {
+		slog.Error("erro ao monitorar característica TX", "device", address, "err", err)
+		return
+	}
+
+	if err := lba.bluezCall(char.StartNotify()); err != nil {
+		slog.Error("erro ao assinar notificações", "device", address, "err", err)
+		unwatchCharacteristic(char, values)
+		return
+	}
+
+	lba.deviceMutex.Lock()
+	if _, exists := lba.notifications[address]; exists {
+		// Outra goroutine assinou primeiro
+		lba.deviceMutex.Unlock()
+		char.StopNotify()
+		unwatchCharacteristic(char, values)
+		return
+	}
+	lba.notifications[address] = func() {
+		char.StopNotify()
+		char.UnwatchProperties(values)
+	}
+	lba.deviceMutex.Unlock()
+
+	go func() {
+		for change := range values {
+			if change == nil {
+				return
+			}
+			if change.Interface != gatt.GattCharacteristic1Interface || change.Name != "Value" {
+				continue
+			}
+
+			value, ok := change.Value.([]byte)
+			if !ok || len(value) == 0 {
+				continue
+			}
+
+			data := make([]byte, len(value))
+			copy(data, value)
+			if lba.onDataReceived != nil {
+				lba.onDataReceived(data, address)
+			}
+		}
+	}()
+}
+
+// unwatchCharacteristic encerra o monitoramento de uma característica que
+// ainda não tem leitor, consumindo o nil que UnwatchProperties entrega
+func unwatchCharacteristic(char *gatt.GattCharacteristic1, values chan *bluez.PropertyChanged) {
+	go char.UnwatchProperties(values)
+	for value := range values {
+		if value == nil {
+			return
+		}
+	}
+}
+
+// unsubscribeNotifications cancela a inscrição na característica TX do peer
+func (lba *LinuxBluetoothAdapter) unsubscribeNotifications(address string) {
+	lba.deviceMutex.Lock()
+	stop, ok := lba.notifications[address]
+	delete(lba.notifications, address)
+	lba.deviceMutex.Unlock()
+
+	if ok {
+		stop()
+	}
+}
+
+// unsubscribeAll cancela todas as inscrições em características TX
+func (lba *LinuxBluetoothAdapter) unsubscribeAll() {
+	lba.deviceMutex.Lock()
+	stops := make([]func(), 0, len(lba.notifications))
+	for address, stop := range lba.notifications {
+		stops = append(stops, stop)
+		delete(lba.notifications, address)
+	}
+	lba.deviceMutex.Unlock()
+
+	for _, stop := range stops {
+		stop()
+	}
+}
@@ -0,0 +1,190 @@
+package bluetooth
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// OutgoingStore guarda as mensagens compostas neste nó até a transmissão,
+// para que sobrevivam a reinícios. Implementado por store.MessageStore.
+type OutgoingStore interface {
+	AddOutgoingMessage(message *protocol.BitchatMessage)
+	GetOutgoingMessages() []*protocol.BitchatMessage
+	RemoveOutgoingMessage(messageID string)
+}
+
+// OutgoingMessage é uma mensagem composta neste nó aguardando transmissão
+type OutgoingMessage struct {
+	Message  *protocol.BitchatMessage
+	QueuedAt time.Time
+	Held     bool // Aguardando um peer ao alcance para ser (re)enviada
+}
+
+// outgoingEntry acompanha uma mensagem até a transmissão: broadcasts até
+// serem enviados com algum peer ao alcance, privadas até a confirmação de
+// entrega ou a desistência dos reenvios
+type outgoingEntry struct {
+	message  *protocol.BitchatMessage
+	packet   *protocol.BitchatPacket // Nil enquanto a mensagem não pôde ser montada
+	queuedAt time.Time
+	held     bool
+}
+
+// SetOutgoingStore define onde as mensagens aguardando transmissão são
+// guardadas. Deve ser chamado antes de Start; as mensagens guardadas em
+// execuções anteriores são enviadas ao iniciar o serviço.
+func (bms *BluetoothMeshService) SetOutgoingStore(store OutgoingStore) {
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+
+	bms.outgoingStore = store
+}
+
+// OutgoingMessages retorna as mensagens compostas neste nó que ainda não
+// foram transmitidas, da mais antiga para a mais recente
+func (bms *BluetoothMeshService) OutgoingMessages() []OutgoingMessage {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	messages := make([]OutgoingMessage, 0, len(bms.outgoing))
+	for _, entry := range bms.outgoing {
+		messages = append(messages, OutgoingMessage{Message: entry.message, QueuedAt: entry.queuedAt, Held: entry.held})
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].QueuedAt.Before(messages[j].QueuedAt)
+	})
+	return messages
+}
+
+// queueOutgoing passa a acompanhar uma mensagem enviada por SendMessage até
+// a transmissão, guardando-a no armazenamento
+func (bms *BluetoothMeshService) queueOutgoing(message *protocol.BitchatMessage, packet *protocol.BitchatPacket) {
+	stored := *message
+	stored.EncryptedContent = nil
+	if stored.Timestamp == 0 {
+		stored.Timestamp = packet.Timestamp
+	}
+
+	bms.mutex.Lock()
+	queuedAt := time.Now()
+	if entry, ok := bms.outgoing[stored.ID]; ok {
+		queuedAt = entry.queuedAt
+	}
+	bms.outgoing[stored.ID] = &outgoingEntry{message: &stored, packet: packet, queuedAt: queuedAt}
+	store := bms.outgoingStore
+	bms.mutex.Unlock()
+
+	if store != nil {
+		store.AddOutgoingMessage(&stored)
+	}
+}
+
+// outgoingTransmitted registra a passagem de um pacote pela fila de saída.
+// Broadcasts cujo envio falhou ou que saíram sem nenhum peer ao alcance
+// ficam retidos até a chegada de um peer; os demais deixam a fila
+// persistente. Mensagens privadas seguem acompanhadas pelos reenvios.
+func (bms *BluetoothMeshService) outgoingTransmitted(packet *protocol.BitchatPacket, sent bool) {
+	bms.mutex.Lock()
+	entry, ok := bms.outgoing[packet.ID]
+	if !ok || entry.packet != packet || entry.message.IsPrivate {
+		bms.mutex.Unlock()
+		return
+	}
+	if !sent || len(bms.peers) == 0 {
+		entry.held = true
+		bms.mutex.Unlock()
+		return
+	}
+	bms.mutex.Unlock()
+
+	bms.forgetOutgoing(packet.ID)
+}
+
+// forgetOutgoing deixa de acompanhar uma mensagem transmitida
+func (bms *BluetoothMeshService) forgetOutgoing(messageID string) {
+	bms.mutex.Lock()
+	_, ok := bms.outgoing[messageID]
+	delete(bms.outgoing, messageID)
+	store := bms.outgoingStore
+	bms.mutex.Unlock()
+
+	if ok && store != nil {
+		store.RemoveOutgoingMessage(messageID)
+	}
+}
+
+// restoreOutgoing reenvia as mensagens guardadas que não foram transmitidas
+// antes do último encerramento
+func (bms *BluetoothMeshService) restoreOutgoing() {
+	bms.mutex.RLock()
+	store := bms.outgoingStore
+	bms.mutex.RUnlock()
+	if store == nil {
+		return
+	}
+
+	messages := store.GetOutgoingMessages()
+	if len(messages) > 0 {
+		slog.Info("reenviando mensagens aguardando transmissão", "count", len(messages))
+	}
+	for _, message := range messages {
+		bms.resendOutgoing(message)
+	}
+}
+
+// releaseHeldOutgoing reenvia as mensagens retidas por falta de peers ao
+// alcance. Chamado quando um peer aparece e periodicamente.
+func (bms *BluetoothMeshService) releaseHeldOutgoing() {
+	bms.mutex.Lock()
+	if len(bms.peers) == 0 {
+		bms.mutex.Unlock()
+		return
+	}
+	var packets []*protocol.BitchatPacket
+	var messages []*protocol.BitchatMessage
+	for _, entry := range bms.outgoing {
+		if !entry.held {
+			continue
+		}
+		entry.held = false
+		if entry.packet != nil && !entry.message.IsPrivate {
+			packets = append(packets, entry.packet)
+		} else {
+			messages = append(messages, entry.message)
+		}
+	}
+	bms.mutex.Unlock()
+
+	// Broadcasts já montados voltam à fila; as demais são montadas de novo
+	for _, packet := range packets {
+		bms.enqueuePacket(packet)
+	}
+	for _, message := range messages {
+		bms.resendOutgoing(message)
+	}
+}
+
+// resendOutgoing monta e envia novamente uma mensagem, mantendo o ID
+// original. Sem o destinatário ao alcance ou acima dos limites de envio, a
+// mensagem fica retida para a próxima tentativa.
+func (bms *BluetoothMeshService) resendOutgoing(message *protocol.BitchatMessage) {
+	resent := *message
+	if _, err := bms.SendMessage(&resent); err != nil {
+		if !errors.Is(err, ErrPeerNotFound) && !errors.Is(err, ErrRateLimited) {
+			slog.Warn("erro ao reenviar mensagem guardada", "id", message.ID, "err", err)
+		}
+
+		bms.mutex.Lock()
+		entry, ok := bms.outgoing[message.ID]
+		if !ok {
+			entry = &outgoingEntry{message: message, queuedAt: time.Now()}
+			bms.outgoing[message.ID] = entry
+		}
+		entry.held = true
+		bms.mutex.Unlock()
+	}
+}
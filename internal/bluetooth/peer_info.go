@@ -0,0 +1,65 @@
+package bluetooth
+
+import (
+	"time"
+
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+)
+
+// PeerInfo reúne o que o serviço sabe sobre um peer
+type PeerInfo struct {
+	ID          string
+	Name        string
+	Fingerprint string // Impressão digital da chave de identidade persistente ("" se desconhecida)
+	KeysKnown   bool   // Se as chaves do peer foram recebidas no anúncio
+	RSSI        int    // Último RSSI medido (0 se desconhecido)
+	Hops        int    // Distância em saltos (0 se não há rota conhecida)
+	LastSeen    time.Time
+	Presence    mesh.PresenceState
+	Neighbor    bool  // Se é um vizinho direto
+	Flags       uint8 // Capacidades anunciadas no hello (apenas vizinhos)
+	FlagsKnown  bool
+	Bonded      bool
+	Favorite    bool // Se a identidade é favorita ou o peer está na lista da descoberta filtrada
+	Traffic     mesh.PeerTraffic
+	Reputation  mesh.PeerReputation
+}
+
+// GetPeerInfo retorna os detalhes de um peer conhecido
+func (bms *BluetoothMeshService) GetPeerInfo(peerID string) (PeerInfo, bool) {
+	bms.mutex.RLock()
+	peer, ok := bms.peers[peerID]
+	if !ok {
+		bms.mutex.RUnlock()
+		return PeerInfo{}, false
+	}
+	info := PeerInfo{
+		ID:       peer.ID,
+		Name:     peer.Name,
+		RSSI:     peer.RSSI,
+		LastSeen: peer.LastSeen,
+		Favorite: bms.scanWhitelist[peerID] || bms.isFavoriteLocked(peerID),
+	}
+	bms.mutex.RUnlock()
+
+	if identityKey := bms.encryptionService.GetPeerIdentityKey(peerID); identityKey != nil {
+		info.KeysKnown = true
+		info.Fingerprint = bms.encryptionService.GetPublicKeyFingerprint(identityKey)
+	}
+	if rssi, ok := bms.linkQuality.RSSI(peerID); ok {
+		info.RSSI = rssi
+	}
+	if hops, ok := bms.router.GetRouteHops(peerID); ok {
+		info.Hops = hops
+	}
+	if _, ok := bms.directNeighbors()[peerID]; ok {
+		info.Neighbor = true
+		info.Hops = 1
+	}
+	info.Presence = bms.presence.State(peerID)
+	info.Flags, info.FlagsKnown = bms.hello.Flags(peerID)
+	info.Bonded = bms.IsPeerBonded(peerID)
+	info.Traffic = bms.GetMeshStats().Peers[peerID]
+	info.Reputation = bms.GetPeerReputation(peerID)
+	return info, true
+}
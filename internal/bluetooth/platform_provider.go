@@ -17,6 +17,75 @@ type PlatformProvider interface {
 	SendPacket(packet *protocol.BitchatPacket) error
 }
 
+// DirectedSender é implementado por provedores capazes de enviar um pacote
+// apenas para um vizinho específico, em vez de para todos os vizinhos
+type DirectedSender interface {
+	SendPacketTo(packet *protocol.BitchatPacket, neighborID string) error
+}
+
+// NeighborLinkResolver é implementado por provedores que informam em
+// ReceivedFrom o enlace por onde cada pacote chegou, para associar um vizinho
+// direto ao seu enlace
+type NeighborLinkResolver interface {
+	NeighborLink(peerID string) (string, bool)
+}
+
+// SignalStrengthProvider é implementado por provedores capazes de informar o
+// RSSI atual dos vizinhos diretos
+type SignalStrengthProvider interface {
+	GetPeerSignalStrength(peerID string) int
+}
+
+// BatteryLevelProvider é implementado por provedores capazes de informar o
+// nível de bateria do dispositivo (0-100)
+type BatteryLevelProvider interface {
+	GetBatteryLevel() (int, error)
+}
+
+// ChargingStateProvider é implementado por provedores capazes de informar se
+// o dispositivo está carregando ou ligado à rede elétrica
+type ChargingStateProvider interface {
+	IsCharging() (bool, error)
+}
+
+// RadioDutyController é implementado por provedores capazes de suspender
+// escaneamento e advertising fora das janelas de atividade do ciclo de trabalho
+type RadioDutyController interface {
+	SetRadioActive(active bool) error
+}
+
+// RadioProfileController é implementado por provedores capazes de ajustar
+// escaneamento, advertising e conexões ao modo de bateria
+type RadioProfileController interface {
+	SetRadioProfile(profile RadioProfile) error
+}
+
+// BondingController é implementado por provedores capazes de parear e criar
+// vínculo (bonding) BLE com um vizinho, cifrando o enlace e acelerando as
+// reconexões
+type BondingController interface {
+	SetPeerBonding(peerID string, enabled bool) error
+}
+
+// AnnounceFlagsController é implementado por provedores que levam as
+// capacidades do nó no anúncio compacto do advertising
+type AnnounceFlagsController interface {
+	SetAnnounceFlags(flags uint8) error
+}
+
+// NicknameController é implementado por provedores que levam o apelido do
+// nó no advertising
+type NicknameController interface {
+	SetNickname(name string) error
+}
+
+// ScanWhitelistController é implementado por provedores capazes de restringir
+// a descoberta a uma lista de peers conhecidos nos perfis de rádio com
+// WhitelistScan
+type ScanWhitelistController interface {
+	SetScanWhitelist(peerIDs []string) error
+}
+
 // NewPlatformProvider cria um novo provedor específico para a plataforma atual
 // A implementação real é definida em cada plataforma usando build tags:
 // - platform_provider_linux.go (Linux)
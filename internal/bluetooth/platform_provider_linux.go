@@ -4,44 +4,59 @@
 package bluetooth
 
 import (
-	"context"
 	"fmt"
-	
-	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"os"
+	"strconv"
+	"strings"
 )
 
-// LinuxProvider implementa a interface PlatformProvider para Linux
-type LinuxProvider struct {
-	meshService *BluetoothMeshService
-}
-
-// NewPlatformProvider cria um novo provedor específico para Linux
-func NewPlatformProvider(meshService *BluetoothMeshService) (PlatformProvider, error) {
-	return &LinuxProvider{
-		meshService: meshService,
-	}, nil
+// batteryCapacityPaths são os arquivos do sysfs consultados para o nível de bateria
+var batteryCapacityPaths = []string{
+	"/sys/class/power_supply/BAT0/capacity",
+	"/sys/class/power_supply/BAT1/capacity",
 }
 
-// Initialize inicializa o provedor Linux
-func (p *LinuxProvider) Initialize() error {
-	// Implementação específica para Linux
-	return nil
+// batteryStatusPaths são os arquivos do sysfs consultados para o estado de carga
+var batteryStatusPaths = []string{
+	"/sys/class/power_supply/BAT0/status",
+	"/sys/class/power_supply/BAT1/status",
 }
 
-// Start inicia o provedor Linux
-func (p *LinuxProvider) Start(ctx context.Context) error {
-	// Implementação específica para Linux
-	return nil
+// NewPlatformProvider cria o provedor mesh BLE sobre o BlueZ
+func NewPlatformProvider(meshService *BluetoothMeshService) (PlatformProvider, error) {
+	return NewLinuxMeshProvider(meshService)
 }
 
-// Stop para o provedor Linux
-func (p *LinuxProvider) Stop() error {
-	// Implementação específica para Linux
-	return nil
+// GetBatteryLevel retorna o nível de bateria lido do sysfs
+func (lmp *LinuxMeshProvider) GetBatteryLevel() (int, error) {
+	for _, path := range batteryCapacityPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if level, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			return level, nil
+		}
+	}
+	
+	return 0, fmt.Errorf("não foi possível determinar o nível de bateria")
 }
 
-// SendPacket envia um pacote através do provedor Linux
-func (p *LinuxProvider) SendPacket(packet *protocol.BitchatPacket) error {
-	// Implementação específica para Linux
-	return fmt.Errorf("envio de pacotes não implementado para Linux")
+// IsCharging informa, pelo sysfs, se a bateria está carregando ou cheia com
+// o carregador ligado
+func (lmp *LinuxMeshProvider) IsCharging() (bool, error) {
+	for _, path := range batteryStatusPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(string(data)) {
+		case "Charging", "Full":
+			return true, nil
+		case "Discharging", "Not charging":
+			return false, nil
+		}
+	}
+	
+	return false, fmt.Errorf("não foi possível determinar o estado de carga")
 }
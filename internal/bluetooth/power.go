@@ -0,0 +1,65 @@
+package bluetooth
+
+// PowerState é o estado de energia do dispositivo informado pela plataforma
+type PowerState struct {
+	Level         int  // Nível de bateria (0-100)
+	LevelKnown    bool // Se o nível foi informado
+	Charging      bool // Carregando ou ligado à rede elétrica
+	ChargingKnown bool // Se o estado de carga foi informado
+}
+
+// PowerStateDelegate pode ser implementado pelo delegate para ser notificado
+// quando o nível de bateria ou o estado de carga muda
+type PowerStateDelegate interface {
+	OnPowerStateChanged(state PowerState)
+}
+
+// GetPowerState retorna o último estado de energia conhecido
+func (bms *BluetoothMeshService) GetPowerState() PowerState {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	return bms.powerStateLocked()
+}
+
+// SetCharging informa se o dispositivo está carregando. Enquanto carrega, o
+// nível de bateria deixa de limitar relay, tráfego de cobertura e o modo
+// automático, que volta ao modo normal.
+func (bms *BluetoothMeshService) SetCharging(charging bool) {
+	bms.mutex.Lock()
+	changed := !bms.chargingKnown || bms.batteryCharging != charging
+	bms.batteryCharging = charging
+	bms.chargingKnown = true
+	state := bms.powerStateLocked()
+	bms.mutex.Unlock()
+
+	if !changed {
+		return
+	}
+	bms.notifyPowerState(state)
+
+	// Vizinhos ficam sabendo já se o nó voltou, ou deixou, de ser intermediário
+	bms.applyBatteryAuto()
+	bms.sendHello()
+}
+
+// powerStateLocked monta o estado de energia atual. Exige bms.mutex.
+func (bms *BluetoothMeshService) powerStateLocked() PowerState {
+	return PowerState{
+		Level:         bms.batteryLevel,
+		LevelKnown:    bms.batteryKnown,
+		Charging:      bms.batteryCharging,
+		ChargingKnown: bms.chargingKnown,
+	}
+}
+
+// onBatteryLocked informa se o nível de bateria deve limitar a operação: o
+// nível é conhecido e o dispositivo não está carregando. Exige bms.mutex.
+func (bms *BluetoothMeshService) onBatteryLocked() bool {
+	return bms.batteryKnown && !bms.batteryCharging
+}
+
+// notifyPowerState avisa os inscritos de uma mudança no estado de energia
+func (bms *BluetoothMeshService) notifyPowerState(state PowerState) {
+	bms.events.publish(topicTransport, TransportEvent{Type: TransportPowerChanged, Power: state})
+}
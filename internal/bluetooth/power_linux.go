@@ -0,0 +1,146 @@
+package bluetooth
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RadioCheckInterval é o intervalo entre verificações de energia e rfkill do adaptador
+const RadioCheckInterval = 2 * time.Second
+
+// RadioState retorna a disponibilidade atual do rádio
+func (lba *LinuxBluetoothAdapter) RadioState() RadioState {
+	lba.radioMutex.Lock()
+	defer lba.radioMutex.Unlock()
+
+	return lba.radioState
+}
+
+// radioStateLoop acompanha periodicamente se o adaptador está ligado e se o
+// rádio foi bloqueado via rfkill, até o adaptador ser fechado
+func (lba *LinuxBluetoothAdapter) radioStateLoop() {
+	ticker := time.NewTicker(RadioCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-lba.ctx.Done():
+			return
+		case <-ticker.C:
+			lba.checkRadioState()
+		}
+	}
+}
+
+// checkRadioState compara energia e rfkill com o último estado conhecido.
+// Quando um bloqueio rfkill é liberado o adaptador é religado
+// automaticamente; um adaptador desligado pelo usuário é respeitado.
+func (lba *LinuxBluetoothAdapter) checkRadioState() {
+	current := lba.RadioState()
+	if current == RadioStateUnavailable {
+		// A reinserção é tratada pelo acompanhamento de hotplug
+		return
+	}
+
+	powered, err := lba.adapter.GetPowered()
+	if err != nil {
+		return
+	}
+
+	blocked := rfkillBlocked(lba.adapterID)
+	if !powered && !blocked && current == RadioStateBlocked {
+		if err := lba.adapter.SetPowered(true); err != nil {
+			slog.Error("erro ao religar adaptador Bluetooth", "err", err)
+		} else {
+			powered = true
+		}
+	}
+
+	switch {
+	case powered:
+		lba.setRadioState(RadioStateOn)
+	case blocked:
+		lba.setRadioState(RadioStateBlocked)
+	default:
+		lba.setRadioState(RadioStateOff)
+	}
+}
+
+// setRadioState registra o novo estado do rádio. Ao perder o rádio o estado
+// de escaneamento, advertising e conexões é descartado para ser refeito
+// quando ele voltar.
+func (lba *LinuxBluetoothAdapter) setRadioState(state RadioState) {
+	lba.radioMutex.Lock()
+	previous := lba.radioState
+	lba.radioState = state
+	lba.radioMutex.Unlock()
+
+	if previous == state {
+		return
+	}
+
+	if previous == RadioStateOn {
+		lba.resetRadio()
+	}
+
+	if lba.onRadioStateChanged != nil {
+		lba.onRadioStateChanged(state)
+	}
+}
+
+// resetRadio descarta escaneamento, advertising, registro GATT, canais L2CAP
+// e enlaces após o rádio deixar de operar
+func (lba *LinuxBluetoothAdapter) resetRadio() {
+	lba.endDiscovery()
+	lba.setScanPaused(false)
+	if lba.cleanupAdvertisement != nil {
+		lba.cleanupAdvertisement()
+		lba.cleanupAdvertisement = nil
+	}
+	lba.advertisement = nil
+
+	// O anúncio e o registro GATT são refeitos quando o rádio volta
+	lba.radioMutex.Lock()
+	lba.isAdvertising = false
+	lba.gattRegistered = false
+	lba.radioMutex.Unlock()
+
+	// As inscrições nas características TX dos peers se perdem com o rádio
+	lba.unsubscribeAll()
+
+	lba.deviceMutex.Lock()
+	defer lba.deviceMutex.Unlock()
+
+	for address, channel := range lba.bulkChannels {
+		channel.Close()
+		delete(lba.bulkChannels, address)
+	}
+	for path := range lba.devices {
+		lba.connections.Disconnected(path)
+	}
+	for address := range lba.linkMTUs {
+		delete(lba.linkMTUs, address)
+		lba.writeQueue.Remove(address)
+	}
+	for address := range lba.mailboxReads {
+		delete(lba.mailboxReads, address)
+	}
+}
+
+// rfkillBlocked verifica se o rádio do adaptador está bloqueado por
+// software ou hardware via rfkill
+func rfkillBlocked(adapterID string) bool {
+	paths, _ := filepath.Glob(filepath.Join("/sys/class/bluetooth", adapterID, "rfkill*"))
+	for _, path := range paths {
+		for _, kind := range []string{"soft", "hard"} {
+			value, err := os.ReadFile(filepath.Join(path, kind))
+			if err == nil && strings.TrimSpace(string(value)) == "1" {
+				return true
+			}
+		}
+	}
+	return false
+}
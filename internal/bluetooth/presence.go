@@ -0,0 +1,99 @@
+package bluetooth
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+	"github.com/permissionlesstech/bitchat/pkg/utils"
+)
+
+// PresenceDelegate pode ser implementado pelo delegate para ser notificado
+// quando um peer fica online, passa a visto recentemente ou fica offline
+type PresenceDelegate interface {
+	OnPeerPresenceChanged(event mesh.PresenceEvent)
+}
+
+// GetPeerPresence retorna o estado de presença de um peer
+func (bms *BluetoothMeshService) GetPeerPresence(peerID string) mesh.PresenceState {
+	return bms.presence.State(peerID)
+}
+
+// GetDeliveryRoute retorna como uma mensagem privada ao peer seria entregue
+// agora: direto ao vizinho, pela mesh ou guardada até ele voltar ao alcance
+func (bms *BluetoothMeshService) GetDeliveryRoute(peerID string) mesh.DeliveryRoute {
+	_, neighbor := bms.directNeighbors()[peerID]
+	return mesh.ChooseRoute(bms.presence.State(peerID), neighbor)
+}
+
+// peerSeen registra um anúncio ou hello recebido de um peer
+func (bms *BluetoothMeshService) peerSeen(peerID string) {
+	bms.notifyPresence(bms.presence.Seen(peerID, time.Now()))
+	bms.checkPeerReturned(peerID)
+}
+
+// peerConnected registra a entrada ou a saída de um peer da vizinhança direta
+func (bms *BluetoothMeshService) peerConnected(peerID string, connected bool) {
+	bms.notifyPresence(bms.presence.SetConnected(peerID, connected, time.Now()))
+}
+
+// updatePresence reclassifica os peers pelo tempo desde a última notícia.
+// Chamado a cada hello.
+func (bms *BluetoothMeshService) updatePresence() {
+	for _, event := range bms.presence.Update(time.Now()) {
+		bms.notifyPresence(&event)
+	}
+}
+
+// notifyPresence avisa o delegate de uma mudança de presença. Um peer que
+// volta a ficar online recebe as mensagens guardadas para ele.
+func (bms *BluetoothMeshService) notifyPresence(event *mesh.PresenceEvent) {
+	if event == nil {
+		return
+	}
+
+	bms.events.publish(topicPeer, PeerEvent{Type: PeerPresenceChanged, PeerID: event.PeerID, Presence: *event})
+	if event.State == mesh.PresenceOnline {
+		go bms.releaseHeldOutgoing()
+	}
+}
+
+// assignDirectHop designa o próprio destinatário como próximo hop de um
+// pacote privado a um vizinho. Retorna false se o provedor não suporta envio
+// direcionado.
+func (bms *BluetoothMeshService) assignDirectHop(packet *protocol.BitchatPacket) bool {
+	if _, ok := bms.platformProvider.(DirectedSender); !ok {
+		return false
+	}
+
+	packet.NextHop = string(packet.RecipientID)
+	return true
+}
+
+// holdForMailbox guarda uma mensagem privada a um peer offline até que ele
+// volte a ficar online, sem ocupar a rede com reenvios sem resposta
+func (bms *BluetoothMeshService) holdForMailbox(message *protocol.BitchatMessage) string {
+	if message.ID == "" {
+		message.ID = hex.EncodeToString(utils.GenerateRandomID(16))
+	}
+	if message.Timestamp == 0 {
+		message.Timestamp = uint64(time.Now().UnixMilli())
+	}
+	stored := *message
+	stored.EncryptedContent = nil
+
+	bms.mutex.Lock()
+	queuedAt := time.Now()
+	if entry, ok := bms.outgoing[stored.ID]; ok {
+		queuedAt = entry.queuedAt
+	}
+	bms.outgoing[stored.ID] = &outgoingEntry{message: &stored, queuedAt: queuedAt, held: true}
+	store := bms.outgoingStore
+	bms.mutex.Unlock()
+
+	if store != nil {
+		store.AddOutgoingMessage(&stored)
+	}
+	return stored.ID
+}
@@ -0,0 +1,58 @@
+package bluetooth
+
+import (
+	"fmt"
+
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+)
+
+// MaxQueueCapacity é a maior capacidade aceita para as filas internas
+const MaxQueueCapacity = 10000
+
+// QueueConfig é a capacidade e a política de descarte de uma fila interna
+type QueueConfig struct {
+	Capacity int             // Pacotes aguardando antes de descartar
+	Policy   mesh.DropPolicy // Pacote descartado com a fila cheia
+}
+
+// DefaultQueueConfig é a configuração inicial das filas de entrada e saída
+var DefaultQueueConfig = QueueConfig{Capacity: DefaultQueueCapacity, Policy: mesh.DropLowestPriority}
+
+// SetQueueConfig altera a capacidade e a política de descarte de uma fila
+// interna (QueueIncoming ou QueueOutgoing). Pacotes além da nova capacidade
+// são descartados e notificados como os descartes por excesso de carga.
+func (bms *BluetoothMeshService) SetQueueConfig(queue string, config QueueConfig) error {
+	pq := bms.queue(queue)
+	if pq == nil {
+		return fmt.Errorf("fila desconhecida: %q", queue)
+	}
+	if config.Capacity < 1 || config.Capacity > MaxQueueCapacity {
+		return fmt.Errorf("capacidade da fila deve estar entre 1 e %d pacotes", MaxQueueCapacity)
+	}
+
+	pq.SetDropPolicy(config.Policy)
+	for _, dropped := range pq.SetCapacity(config.Capacity) {
+		bms.notifyPacketDropped(queue, dropped.Packet, dropped.Priority)
+	}
+	return nil
+}
+
+// QueueStats retorna a ocupação, a maior ocupação atingida, a configuração e
+// os descartes das filas internas, por nome
+func (bms *BluetoothMeshService) QueueStats() map[string]mesh.QueueStats {
+	return map[string]mesh.QueueStats{
+		QueueIncoming: bms.incomingQueue.Stats(),
+		QueueOutgoing: bms.outgoingQueue.Stats(),
+	}
+}
+
+// queue retorna a fila interna com o nome informado, ou nil
+func (bms *BluetoothMeshService) queue(name string) *mesh.PriorityQueue {
+	switch name {
+	case QueueIncoming:
+		return bms.incomingQueue
+	case QueueOutgoing:
+		return bms.outgoingQueue
+	}
+	return nil
+}
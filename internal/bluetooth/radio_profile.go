@@ -0,0 +1,50 @@
+package bluetooth
+
+import "time"
+
+// RadioProfile descreve o comportamento do rádio BLE em um modo de bateria
+type RadioProfile struct {
+	ScanWindow          time.Duration // Tempo escaneando em cada ciclo de escaneamento
+	ScanInterval        time.Duration // Duração de um ciclo de escaneamento
+	AdvertisingInterval time.Duration // Intervalo entre anúncios
+	SupervisionTimeout  time.Duration // Tempo sem resposta até uma conexão ser considerada perdida
+	WhitelistScan       bool          // Descobrir apenas os peers da lista de favoritos
+}
+
+// ContinuousScan verifica se o escaneamento ocupa todo o ciclo
+func (rp RadioProfile) ContinuousScan() bool {
+	return rp.ScanWindow >= rp.ScanInterval
+}
+
+// RadioProfileForMode retorna o perfil de rádio de um modo de bateria.
+// Os modos de economia escaneiam por janelas mais curtas, anunciam com
+// menos frequência e toleram mais tempo sem resposta nas conexões, já que
+// os eventos de conexão também ficam mais espaçados. No modo ultra baixo a
+// descoberta aceita apenas os peers conhecidos, evitando acordar o host a
+// cada anúncio em ambientes lotados.
+func RadioProfileForMode(mode int) RadioProfile {
+	switch mode {
+	case BatteryModeLow:
+		return RadioProfile{
+			ScanWindow:          2 * time.Second,
+			ScanInterval:        10 * time.Second,
+			AdvertisingInterval: 500 * time.Millisecond,
+			SupervisionTimeout:  4 * time.Second,
+		}
+	case BatteryModeUltraLow:
+		return RadioProfile{
+			ScanWindow:          2 * time.Second,
+			ScanInterval:        30 * time.Second,
+			AdvertisingInterval: 2 * time.Second,
+			SupervisionTimeout:  8 * time.Second,
+			WhitelistScan:       true,
+		}
+	default:
+		return RadioProfile{
+			ScanWindow:          10 * time.Second,
+			ScanInterval:        10 * time.Second,
+			AdvertisingInterval: 100 * time.Millisecond,
+			SupervisionTimeout:  2 * time.Second,
+		}
+	}
+}
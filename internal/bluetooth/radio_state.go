@@ -0,0 +1,50 @@
+package bluetooth
+
+// RadioState descreve a disponibilidade do rádio Bluetooth
+type RadioState int
+
+const (
+	RadioStateOn          RadioState = iota // Rádio ligado e mesh operando
+	RadioStateOff                           // Adaptador desligado
+	RadioStateBlocked                       // Rádio bloqueado via rfkill
+	RadioStateUnavailable                   // Adaptador removido do sistema
+)
+
+// String retorna o nome do estado do rádio
+func (rs RadioState) String() string {
+	switch rs {
+	case RadioStateOn:
+		return "ligado"
+	case RadioStateOff:
+		return "desligado"
+	case RadioStateBlocked:
+		return "bloqueado"
+	case RadioStateUnavailable:
+		return "indisponível"
+	default:
+		return "desconhecido"
+	}
+}
+
+// GetRadioState retorna o último estado conhecido do rádio Bluetooth
+func (bms *BluetoothMeshService) GetRadioState() RadioState {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	return bms.radioState
+}
+
+// setRadioState registra uma mudança de estado do rádio informada pelo
+// provedor da plataforma e notifica os inscritos
+func (bms *BluetoothMeshService) setRadioState(state RadioState) {
+	bms.mutex.Lock()
+	changed := bms.radioState != state
+	bms.radioState = state
+	bms.mutex.Unlock()
+
+	if !changed {
+		return
+	}
+
+	bms.events.publish(topicTransport, TransportEvent{Type: TransportRadioChanged, Radio: state})
+}
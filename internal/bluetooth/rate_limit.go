@@ -0,0 +1,41 @@
+package bluetooth
+
+import (
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+)
+
+// SetRateLimit define os limites de envio de mensagens, no total e por
+// canal ou conversa privada. Mensagens acima dos limites são recusadas por
+// SendMessage com ErrRateLimited.
+func (bms *BluetoothMeshService) SetRateLimit(config mesh.RateLimitConfig) {
+	bms.rateLimiter.SetConfig(config)
+}
+
+// GetRateLimit retorna os limites de envio de mensagens atuais
+func (bms *BluetoothMeshService) GetRateLimit() mesh.RateLimitConfig {
+	return bms.rateLimiter.Config()
+}
+
+// RateLimitedMessages retorna o número de mensagens recusadas pelos limites
+// de envio
+func (bms *BluetoothMeshService) RateLimitedMessages() uint64 {
+	return bms.rateLimiter.Limited()
+}
+
+// allowSend aplica os limites de envio a um pacote de mensagem pronto para
+// a fila de saída
+func (bms *BluetoothMeshService) allowSend(message *protocol.BitchatMessage, packet *protocol.BitchatPacket) bool {
+	conversation := message.Channel
+	if message.IsPrivate {
+		conversation = "@" + message.RecipientNickname
+	}
+	return bms.rateLimiter.Allow(conversation, mesh.PacketSize(packet), time.Now())
+}
+
+// cleanupRateLimits esquece os limites de conversas ociosas
+func (bms *BluetoothMeshService) cleanupRateLimits() {
+	bms.rateLimiter.Cleanup(time.Now())
+}
@@ -0,0 +1,67 @@
+package bluetooth
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// SetReadReceipts habilita ou desabilita o envio de confirmações de leitura.
+// Desabilitadas, os remetentes não ficam sabendo quando suas mensagens foram
+// lidas; as confirmações recebidas continuam sendo informadas ao delegate.
+func (bms *BluetoothMeshService) SetReadReceipts(enabled bool) {
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+
+	bms.readReceiptsOff = !enabled
+}
+
+// ReadReceipts informa se as confirmações de leitura são enviadas
+func (bms *BluetoothMeshService) ReadReceipts() bool {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	return !bms.readReceiptsOff
+}
+
+// SendReadReceipts confirma ao remetente a leitura de mensagens privadas
+// recebidas dele. Não faz nada com as confirmações de leitura desabilitadas.
+func (bms *BluetoothMeshService) SendReadReceipts(peerID string, messageIDs []string) {
+	if !bms.ReadReceipts() {
+		return
+	}
+
+	for _, messageID := range messageIDs {
+		bms.sendReadReceipt(messageID, peerID)
+	}
+}
+
+// sendReadReceipt envia a confirmação de leitura de uma mensagem, assinada
+func (bms *BluetoothMeshService) sendReadReceipt(messageID string, recipientID string) {
+	receipt := &protocol.ReadReceipt{
+		OriginalMessageID: messageID,
+		ReaderNickname:    bms.GetNickname(),
+		Timestamp:         time.Now(),
+	}
+
+	packet := &protocol.BitchatPacket{
+		Version:     1,
+		Type:        protocol.MessageTypeReadReceipt,
+		SenderID:    bms.deviceID,
+		RecipientID: []byte(recipientID),
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     protocol.EncodeReadReceipt(receipt),
+		TTL:         bms.unicastTTL(recipientID),
+	}
+
+	signature, err := bms.encryptionService.Sign(protocol.SignedPayload(packet))
+	if err != nil {
+		slog.Error("erro ao assinar pacote", "err", err)
+		return
+	}
+	packet.Signature = signature
+
+	bms.assignNextHop(packet, "")
+	bms.enqueuePacket(packet)
+}
@@ -0,0 +1,140 @@
+package bluetooth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+const (
+	// MaxReconnectSyncBytes é o maior orçamento atendido em uma sincronização
+	// pedida no retorno de um peer
+	MaxReconnectSyncBytes = 64 << 10
+
+	// MaxReconnectSyncReplay é o máximo de mensagens reenviadas em uma
+	// sincronização com orçamento
+	MaxReconnectSyncReplay = 128
+)
+
+// DefaultReconnectSyncBudgets são os orçamentos de dados, por modo de
+// bateria, de cada sincronização disparada por um retorno
+var DefaultReconnectSyncBudgets = ReconnectSyncBudgets{
+	Normal:   16 << 10,
+	Low:      4 << 10,
+	UltraLow: 0, // Sem sincronização no modo ultralow
+}
+
+// ReconnectSyncBudgets limita os bytes pedidos em cada sincronização de
+// mensagens perdidas, conforme o modo de bateria. Zero desativa a
+// sincronização no modo.
+type ReconnectSyncBudgets struct {
+	Normal   int
+	Low      int
+	UltraLow int
+}
+
+// forMode retorna o orçamento do modo de bateria
+func (b ReconnectSyncBudgets) forMode(mode int) int {
+	switch mode {
+	case BatteryModeLow:
+		return b.Low
+	case BatteryModeUltraLow:
+		return b.UltraLow
+	}
+	return b.Normal
+}
+
+// SetReconnectSyncBudgets define os orçamentos da sincronização disparada
+// quando um peer ou a população de um canal volta após um intervalo
+func (bms *BluetoothMeshService) SetReconnectSyncBudgets(budgets ReconnectSyncBudgets) error {
+	for _, budget := range []int{budgets.Normal, budgets.Low, budgets.UltraLow} {
+		if budget < 0 || budget > MaxReconnectSyncBytes {
+			return fmt.Errorf("orçamento de sincronização deve estar entre 0 e %d bytes", MaxReconnectSyncBytes)
+		}
+	}
+
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+
+	bms.reconnectBudgets = budgets
+	return nil
+}
+
+// GetReconnectSyncBudgets retorna os orçamentos da sincronização no retorno
+func (bms *BluetoothMeshService) GetReconnectSyncBudgets() ReconnectSyncBudgets {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	return bms.reconnectBudgets
+}
+
+// ReconnectSyncs retorna o número de sincronizações disparadas por retornos
+func (bms *BluetoothMeshService) ReconnectSyncs() uint64 {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	return bms.reconnectSyncs
+}
+
+// checkPeerReturned sincroniza as mensagens perdidas com um peer que volta
+// após um intervalo sem notícias. As mensagens privadas guardadas para ele
+// são liberadas pela presença.
+func (bms *BluetoothMeshService) checkPeerReturned(peerID string) {
+	if bms.reconnect.Seen("peer:"+peerID, time.Now()) {
+		go bms.reconnectSync(peerID)
+	}
+}
+
+// checkChannelsRepopulatedLocked sincroniza com o peer que anunciou um canal em que
+// este nó está, se o canal estava sem membros havia um intervalo. Exige
+// bms.mutex.
+func (bms *BluetoothMeshService) checkChannelsRepopulatedLocked(peerID string, entries []protocol.ChannelListEntry) {
+	joined := make(map[string]bool, len(bms.localChannels))
+	for _, entry := range bms.localChannels {
+		joined[entry.Channel] = true
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		if joined[entry.Channel] && bms.reconnect.Seen(entry.Channel, now) {
+			go bms.reconnectSync(peerID)
+			return
+		}
+	}
+}
+
+// reconnectSync pede a um peer as mensagens recentes que faltam, dentro do
+// orçamento do modo de bateria atual
+func (bms *BluetoothMeshService) reconnectSync(peerID string) {
+	bms.mutex.Lock()
+	budget := bms.reconnectBudgets.forMode(bms.batteryMode)
+	if budget > 0 {
+		bms.reconnectSyncs++
+	}
+	bms.mutex.Unlock()
+
+	if budget <= 0 {
+		return
+	}
+	bms.sendSyncRequest(peerID, nil, uint32(budget))
+}
+
+// pruneReconnect esquece as notícias antigas de peers e canais. Chamado
+// pela manutenção periódica.
+func (bms *BluetoothMeshService) pruneReconnect() {
+	bms.reconnect.Prune(time.Now())
+}
+
+// syncReplayLimits retorna o máximo de mensagens e de bytes reenviados em
+// resposta a uma solicitação de sincronização
+func syncReplayLimits(req *protocol.SyncRequest) (int, int) {
+	if req.MaxBytes == 0 {
+		return MaxSyncReplay, MaxSyncReplayBytes
+	}
+	budget := int(req.MaxBytes)
+	if budget > MaxReconnectSyncBytes {
+		budget = MaxReconnectSyncBytes
+	}
+	return MaxReconnectSyncReplay, budget
+}
@@ -0,0 +1,203 @@
+package bluetooth
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/muka/go-bluetooth/bluez"
+)
+
+const (
+	// BlueZErrorThreshold é o número de falhas seguidas do BlueZ que dispara
+	// a recuperação automática
+	BlueZErrorThreshold = 3
+
+	// MaxRecoveryAttempts limita as tentativas de religar o adaptador em uma
+	// recuperação
+	MaxRecoveryAttempts = 5
+
+	// RecoveryBackoffBase é a espera após a primeira tentativa malsucedida;
+	// cada nova falha dobra a espera
+	RecoveryBackoffBase = 2 * time.Second
+)
+
+// SetOnHealthEvent define o callback chamado a cada etapa da recuperação
+// automática do BlueZ
+func (lba *LinuxBluetoothAdapter) SetOnHealthEvent(callback func(event HealthEvent)) {
+	lba.onHealthEvent = callback
+}
+
+// bluezCall registra o resultado de uma chamada ao BlueZ com o rádio ligado.
+// Falhas seguidas do BlueZ ou do D-Bus disparam a recuperação; o erro é
+// retornado sem alteração.
+func (lba *LinuxBluetoothAdapter) bluezCall(err error) error {
+	lba.radioMutex.Lock()
+	if err == nil {
+		lba.bluezFailures = 0
+		lba.radioMutex.Unlock()
+		return nil
+	}
+	if !isBlueZError(err) || lba.radioState != RadioStateOn || lba.recovering {
+		lba.radioMutex.Unlock()
+		return err
+	}
+	lba.bluezFailures++
+	failures := lba.bluezFailures
+	lba.radioMutex.Unlock()
+
+	if failures >= BlueZErrorThreshold {
+		go lba.recoverBlueZ(fmt.Sprintf("%d falhas seguidas do BlueZ: %v", failures, err))
+	}
+	return err
+}
+
+// isBlueZError verifica se o erro veio do BlueZ ou da ausência dele no D-Bus
+func isBlueZError(err error) bool {
+	message := err.Error()
+	for _, prefix := range []string{
+		"org.bluez.Error.",
+		"org.freedesktop.DBus.Error.ServiceUnknown",
+		"org.freedesktop.DBus.Error.NoReply",
+		"org.freedesktop.DBus.Error.UnknownObject",
+	} {
+		if strings.Contains(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// bluezOwnerLoop acompanha o nome org.bluez no barramento do sistema para
+// detectar o encerramento e o reinício do daemon, que descarta advertising,
+// descoberta e conexões registrados
+func (lba *LinuxBluetoothAdapter) bluezOwnerLoop() {
+	conn, err := bluez.GetConnection(bluez.SystemBus)
+	if err != nil {
+		slog.Error("erro ao acompanhar o BlueZ", "err", err)
+		return
+	}
+
+	options := []dbus.MatchOption{
+		dbus.WithMatchInterface("org.freedesktop.DBus"),
+		dbus.WithMatchMember("NameOwnerChanged"),
+		dbus.WithMatchOption("arg0", bluez.OrgBluezInterface),
+	}
+	if err := conn.AddMatchSignal(options...); err != nil {
+		slog.Error("erro ao acompanhar o BlueZ", "err", err)
+		return
+	}
+	defer conn.RemoveMatchSignal(options...)
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	for {
+		select {
+		case <-lba.ctx.Done():
+			return
+		case sig := <-signals:
+			if sig == nil || sig.Name != "org.freedesktop.DBus.NameOwnerChanged" || len(sig.Body) < 3 {
+				continue
+			}
+			name, _ := sig.Body[0].(string)
+			newOwner, _ := sig.Body[2].(string)
+			if name != bluez.OrgBluezInterface {
+				continue
+			}
+
+			if newOwner == "" {
+				slog.Warn("BlueZ encerrado; aguardando reinício")
+				lba.setRadioState(RadioStateUnavailable)
+				lba.forgetDevices()
+				continue
+			}
+			go lba.recoverBlueZ("BlueZ reiniciado")
+		}
+	}
+}
+
+// recoverBlueZ religa o adaptador após falhas do BlueZ, com tentativas
+// limitadas e espera crescente. Ao religar, o rádio volta ao estado ligado
+// e o provedor registra novamente advertising, descoberta, serviço GATT e
+// canais L2CAP.
+func (lba *LinuxBluetoothAdapter) recoverBlueZ(reason string) {
+	lba.radioMutex.Lock()
+	if lba.recovering {
+		lba.radioMutex.Unlock()
+		return
+	}
+	lba.recovering = true
+	lba.bluezFailures = 0
+	lba.radioMutex.Unlock()
+
+	defer func() {
+		lba.radioMutex.Lock()
+		lba.recovering = false
+		lba.radioMutex.Unlock()
+	}()
+
+	slog.Warn("recuperando Bluetooth", "reason", reason)
+	lba.emitHealthEvent(HealthEvent{Status: RecoveryStarted, Reason: reason})
+
+	// Descartar o estado registrado no BlueZ que falhou
+	lba.setRadioState(RadioStateUnavailable)
+	lba.forgetDevices()
+
+	delay := RecoveryBackoffBase
+	var err error
+	for attempt := 1; attempt <= MaxRecoveryAttempts; attempt++ {
+		// Um rádio bloqueado não liga até o rfkill ser liberado
+		if rfkillBlocked(lba.adapterID) {
+			lba.setRadioState(RadioStateBlocked)
+			return
+		}
+
+		if err = lba.powerCycle(); err == nil {
+			slog.Info("adaptador Bluetooth recuperado", "adapter", lba.adapterID)
+			lba.emitHealthEvent(HealthEvent{Status: RecoverySucceeded, Reason: reason, Attempt: attempt})
+			lba.setRadioState(RadioStateOn)
+			return
+		}
+		if attempt == MaxRecoveryAttempts {
+			break
+		}
+
+		lba.emitHealthEvent(HealthEvent{Status: RecoveryRetrying, Reason: reason, Attempt: attempt, Err: err})
+		select {
+		case <-lba.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	slog.Error("erro ao recuperar adaptador Bluetooth", "err", err)
+	lba.emitHealthEvent(HealthEvent{Status: RecoveryFailed, Reason: reason, Attempt: MaxRecoveryAttempts, Err: err})
+	lba.setRadioState(RadioStateOff)
+}
+
+// powerCycle obtém novamente o adaptador e o desliga e religa, descartando
+// o estado do controlador
+func (lba *LinuxBluetoothAdapter) powerCycle() error {
+	if err := lba.attachAdapter(); err != nil {
+		return err
+	}
+	if err := lba.adapter.SetPowered(false); err != nil {
+		return fmt.Errorf("erro ao desligar adaptador Bluetooth: %v", err)
+	}
+	if err := lba.adapter.SetPowered(true); err != nil {
+		return fmt.Errorf("erro ao religar adaptador Bluetooth: %v", err)
+	}
+	return nil
+}
+
+// emitHealthEvent notifica o callback de eventos de recuperação
+func (lba *LinuxBluetoothAdapter) emitHealthEvent(event HealthEvent) {
+	if lba.onHealthEvent != nil {
+		lba.onHealthEvent(event)
+	}
+}
@@ -0,0 +1,145 @@
+package bluetooth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/internal/service"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+)
+
+// RetryDedupWindow é por quanto tempo o destinatário reconhece reenvios de
+// uma mensagem privada já recebida
+const RetryDedupWindow = 30 * time.Minute
+
+// SetRetryConfig define as tentativas de reenvio de mensagens privadas sem
+// confirmação de entrega. Deve ser chamado antes de Start; nil usa
+// service.DefaultRetryConfig.
+func (bms *BluetoothMeshService) SetRetryConfig(config *service.RetryConfig) {
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+
+	bms.retryConfig = config
+}
+
+// PendingRetries retorna o número de mensagens privadas aguardando
+// confirmação de entrega
+func (bms *BluetoothMeshService) PendingRetries() int {
+	if retry := bms.retryService(); retry != nil {
+		return retry.GetPendingCount()
+	}
+	return 0
+}
+
+// startRetriesLocked cria o serviço de reenvio. Exige bms.mutex.
+func (bms *BluetoothMeshService) startRetriesLocked() {
+	bms.retry = service.NewRetryService(bms.retryConfig, bms.resendPacket)
+	bms.retry.Start()
+}
+
+// stopRetries para o serviço de reenvio; mensagens aguardando confirmação
+// são esquecidas
+func (bms *BluetoothMeshService) stopRetries() {
+	bms.mutex.Lock()
+	retry := bms.retry
+	bms.retry = nil
+	bms.retryAliases = make(map[string]string)
+	bms.mutex.Unlock()
+
+	if retry != nil {
+		retry.Stop()
+	}
+}
+
+// retryService retorna o serviço de reenvio, ou nil com o serviço parado
+func (bms *BluetoothMeshService) retryService() *service.RetryService {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	return bms.retry
+}
+
+// trackDelivery passa a reenviar um pacote privado até a confirmação de
+// entrega. Sem confirmação após as tentativas, o delegate recebe
+// DeliveryStatusFailed.
+func (bms *BluetoothMeshService) trackDelivery(packet *protocol.BitchatPacket, peerID string, recipient string) {
+	retry := bms.retryService()
+	if retry == nil {
+		return
+	}
+
+	retry.AddRetryPacket(packet, peerID, func(messageID string, success bool, info *protocol.DeliveryInfo) {
+		bms.forgetRetryAliases(messageID)
+		bms.forgetOutgoing(messageID)
+		if success {
+			return
+		}
+		info.Recipient = recipient
+		info.Status = protocol.DeliveryStatusFailed
+		bms.publishDelivery(messageID, info)
+	})
+}
+
+// resendPacket reenvia um pacote privado sem confirmação. A cópia leva um
+// novo horário, para não ser descartada como duplicada pelos relays; o
+// destinatário a reconhece pelo payload e a confirma com o novo ID, que é
+// associado ao ID original.
+func (bms *BluetoothMeshService) resendPacket(packet *protocol.BitchatPacket, peerID string) error {
+	resent := *packet
+	resent.ID = ""
+	resent.NextHop = ""
+	resent.HopCount = 0
+	resent.Timestamp = uint64(time.Now().UnixMilli())
+	resent.TTL = bms.unicastTTL(peerID)
+
+	bms.mutex.Lock()
+	bms.retryAliases[mesh.PacketKey(&resent)] = packet.ID
+	bms.mutex.Unlock()
+
+	bms.discoverRoute(peerID)
+	bms.assignNextHop(&resent, "")
+	bms.enqueuePacket(&resent)
+	return nil
+}
+
+// resolveRetryID retorna o ID original de uma mensagem a partir do ID de um
+// reenvio; outros IDs são retornados sem alteração
+func (bms *BluetoothMeshService) resolveRetryID(messageID string) string {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	if original, ok := bms.retryAliases[messageID]; ok {
+		return original
+	}
+	return messageID
+}
+
+// forgetRetryAliases esquece os IDs dos reenvios de uma mensagem concluída
+func (bms *BluetoothMeshService) forgetRetryAliases(messageID string) {
+	bms.mutex.Lock()
+	defer bms.mutex.Unlock()
+
+	for alias, original := range bms.retryAliases {
+		if original == messageID {
+			delete(bms.retryAliases, alias)
+		}
+	}
+}
+
+// markDelivered encerra os reenvios de uma mensagem confirmada
+func (bms *BluetoothMeshService) markDelivered(messageID string) {
+	if retry := bms.retryService(); retry != nil {
+		retry.MarkDelivered(messageID)
+	}
+}
+
+// isResentMessage informa se uma mensagem privada recebida é o reenvio de
+// uma já entregue ao delegate, reconhecida pelo remetente e pelo payload
+func (bms *BluetoothMeshService) isResentMessage(packet *protocol.BitchatPacket) bool {
+	hash := sha256.New()
+	hash.Write(packet.SenderID)
+	hash.Write(packet.Payload)
+	return !bms.receivedPrivate.Add(hex.EncodeToString(hash.Sum(nil)[:16]))
+}
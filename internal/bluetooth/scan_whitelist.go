@@ -0,0 +1,78 @@
+package bluetooth
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// peerServiceUUIDPrefix são os primeiros 8 bytes dos UUIDs de identidade;
+// os 8 bytes restantes vêm do ID do peer
+const peerServiceUUIDPrefix = "6E40FF00-B5A3-F393"
+
+// PeerServiceUUID retorna o UUID de identidade anunciado por um peer, além de
+// ServiceUUID. Com ele o filtro de descoberta do BlueZ consegue aceitar
+// apenas peers específicos, sem acordar o host para os demais anúncios.
+func PeerServiceUUID(peerID []byte) string {
+	id := make([]byte, 8)
+	copy(id, peerID)
+	encoded := strings.ToUpper(hex.EncodeToString(id))
+	return fmt.Sprintf("%s-%s-%s", peerServiceUUIDPrefix, encoded[:4], encoded[4:])
+}
+
+// SetScanWhitelist define os peers favoritos aceitos pela descoberta no modo
+// de bateria ultra baixo. Peers com vínculo BLE entram na lista
+// automaticamente; com a lista vazia a descoberta continua aberta a
+// qualquer nó Bitchat.
+func (bms *BluetoothMeshService) SetScanWhitelist(peerIDs []string) error {
+	bms.mutex.Lock()
+	bms.scanWhitelist = make(map[string]bool, len(peerIDs))
+	for _, peerID := range peerIDs {
+		bms.scanWhitelist[peerID] = true
+	}
+	bms.mutex.Unlock()
+
+	return bms.applyScanWhitelist()
+}
+
+// ScanWhitelist retorna os peers aceitos pela descoberta filtrada, incluindo
+// os peers com vínculo BLE e os favoritos conhecidos
+func (bms *BluetoothMeshService) ScanWhitelist() []string {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	return bms.scanWhitelistLocked()
+}
+
+// scanWhitelistLocked monta a lista de peers aceitos. Exige bms.mutex.
+func (bms *BluetoothMeshService) scanWhitelistLocked() []string {
+	peers := make(map[string]bool, len(bms.scanWhitelist)+len(bms.bondedPeers))
+	for peerID := range bms.scanWhitelist {
+		peers[peerID] = true
+	}
+	for peerID := range bms.bondedPeers {
+		peers[peerID] = true
+	}
+	for peerID := range bms.peers {
+		if bms.isFavoriteLocked(peerID) {
+			peers[peerID] = true
+		}
+	}
+
+	whitelist := make([]string, 0, len(peers))
+	for peerID := range peers {
+		whitelist = append(whitelist, peerID)
+	}
+	sort.Strings(whitelist)
+	return whitelist
+}
+
+// applyScanWhitelist repassa a lista de peers aceitos ao provedor
+func (bms *BluetoothMeshService) applyScanWhitelist() error {
+	controller, ok := bms.platformProvider.(ScanWhitelistController)
+	if !ok {
+		return nil
+	}
+	return controller.SetScanWhitelist(bms.ScanWhitelist())
+}
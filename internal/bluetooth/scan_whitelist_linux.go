@@ -0,0 +1,61 @@
+package bluetooth
+
+import (
+	"fmt"
+
+	"github.com/muka/go-bluetooth/bluez/profile/adapter"
+)
+
+// SetLocalPeerID define a identidade anunciada junto de ServiceUUID, para que
+// peers em modo de economia possam aceitar este nó na descoberta filtrada.
+// Vale a partir do próximo StartAdvertising.
+func (lba *LinuxBluetoothAdapter) SetLocalPeerID(peerID []byte) {
+	lba.radioMutex.Lock()
+	defer lba.radioMutex.Unlock()
+
+	lba.localPeerUUID = PeerServiceUUID(peerID)
+}
+
+// SetScanWhitelist restringe a descoberta aos peers informados enquanto o
+// perfil de rádio pedir WhitelistScan. Uma lista vazia mantém a descoberta
+// aberta a qualquer nó Bitchat.
+func (lba *LinuxBluetoothAdapter) SetScanWhitelist(peerIDs []string) error {
+	uuids := make([]string, 0, len(peerIDs))
+	for _, peerID := range peerIDs {
+		uuids = append(uuids, PeerServiceUUID([]byte(peerID)))
+	}
+
+	lba.radioMutex.Lock()
+	lba.scanWhitelist = uuids
+	scanning := lba.isScanning
+	lba.radioMutex.Unlock()
+
+	if !scanning {
+		return nil
+	}
+	return lba.applyDiscoveryFilter()
+}
+
+// discoveryUUIDs retorna os UUIDs aceitos pelo filtro de descoberta
+func (lba *LinuxBluetoothAdapter) discoveryUUIDs() []string {
+	lba.radioMutex.Lock()
+	defer lba.radioMutex.Unlock()
+
+	if lba.profile.WhitelistScan && len(lba.scanWhitelist) > 0 {
+		return append([]string(nil), lba.scanWhitelist...)
+	}
+	return []string{ServiceUUID}
+}
+
+// applyDiscoveryFilter configura o filtro de descoberta do BlueZ. O filtro
+// pode ser trocado com a descoberta em andamento.
+func (lba *LinuxBluetoothAdapter) applyDiscoveryFilter() error {
+	filter := adapter.NewDiscoveryFilter()
+	filter.Transport = "le"
+	filter.UUIDs = lba.discoveryUUIDs()
+
+	if err := lba.bluezCall(lba.adapter.SetDiscoveryFilter(filter.ToMap())); err != nil {
+		return fmt.Errorf("erro ao configurar filtro de descoberta: %v", err)
+	}
+	return nil
+}
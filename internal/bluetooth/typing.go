@@ -0,0 +1,136 @@
+package bluetooth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/internal/service"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+	"github.com/permissionlesstech/bitchat/pkg/utils"
+)
+
+// TypingDelegate pode ser implementado pelo delegate para ser notificado
+// quando um peer começa ou para de digitar em um canal ou conversa privada
+type TypingDelegate interface {
+	OnPeerTyping(event service.TypingEvent)
+}
+
+// SetTypingIndicators habilita ou desabilita o envio de avisos de digitação.
+// Os avisos recebidos continuam sendo informados ao delegate.
+func (bms *BluetoothMeshService) SetTypingIndicators(enabled bool) {
+	bms.mutex.Lock()
+	bms.typingOff = !enabled
+	bms.mutex.Unlock()
+
+	// Conversas em que o usuário digitava recebem o aviso de fim
+	if !enabled {
+		bms.typing.StopAll()
+	}
+}
+
+// TypingIndicators informa se os avisos de digitação são enviados
+func (bms *BluetoothMeshService) TypingIndicators() bool {
+	bms.mutex.RLock()
+	defer bms.mutex.RUnlock()
+
+	return !bms.typingOff
+}
+
+// NotifyTyping informa que o usuário digitou na conversa: um canal ou "@"
+// seguido do apelido do peer. A frequência dos avisos e o aviso de fim após
+// um período sem digitar ficam a cargo do serviço.
+func (bms *BluetoothMeshService) NotifyTyping(conversation string) {
+	if conversation == "" || !bms.TypingIndicators() {
+		return
+	}
+	bms.typing.KeyPressed(conversation, time.Now())
+}
+
+// StopTyping informa que o usuário parou de digitar na conversa, ao enviar a
+// mensagem ou apagar a linha
+func (bms *BluetoothMeshService) StopTyping(conversation string) {
+	bms.typing.Stopped(conversation)
+}
+
+// TypingPeers retorna os peers digitando em uma conversa
+func (bms *BluetoothMeshService) TypingPeers(conversation string) []string {
+	return bms.typing.Typing(conversation)
+}
+
+// typingLoop expira os avisos de digitação até ctx ser cancelado
+func (bms *BluetoothMeshService) typingLoop(ctx context.Context) {
+	ticker := time.NewTicker(service.TypingTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			bms.typing.Tick(now)
+		}
+	}
+}
+
+// sendTyping envia um aviso de início ou fim da digitação a uma conversa
+func (bms *BluetoothMeshService) sendTyping(conversation string, active bool) {
+	typing := &protocol.Typing{Active: active}
+	packet := &protocol.BitchatPacket{
+		Version:   1,
+		Type:      protocol.MessageTypeTyping,
+		SenderID:  bms.deviceID,
+		Timestamp: uint64(time.Now().UnixMilli()),
+	}
+
+	if nickname, ok := strings.CutPrefix(conversation, "@"); ok {
+		peerID := bms.findPeerIDByNickname(nickname)
+		if peerID == "" {
+			return
+		}
+		packet.RecipientID = []byte(peerID)
+		packet.TTL = bms.unicastTTL(peerID)
+	} else {
+		if !validChannel(conversation) {
+			return
+		}
+		typing.Channel = conversation
+		packet.RecipientID = protocol.BroadcastRecipient
+		packet.TTL = bms.messageTTL()
+	}
+	packet.Payload = protocol.EncodeTyping(typing)
+
+	bms.assignNextHop(packet, "")
+	bms.enqueuePacket(packet)
+}
+
+// handleTyping processa o aviso de digitação de um peer conhecido. Avisos sem
+// canal só valem endereçados a este nó.
+func (bms *BluetoothMeshService) handleTyping(packet *protocol.BitchatPacket) {
+	typing, err := protocol.DecodeTyping(packet.Payload)
+	if err != nil || (typing.Channel != "" && !validChannel(typing.Channel)) {
+		bms.reportMisbehavior(packet, mesh.MisbehaviorMalformed)
+		return
+	}
+
+	peerID := string(packet.SenderID)
+	peer, ok := bms.getPeer(peerID)
+	if !ok {
+		return
+	}
+
+	conversation := typing.Channel
+	if conversation == "" {
+		if !utils.ByteArraysEqual(packet.RecipientID, bms.deviceID) {
+			return
+		}
+		conversation = "@" + peer.Name
+	}
+	bms.typing.Received(conversation, peerID, typing.Active, time.Now())
+}
+
+// notifyTyping publica que um peer começou ou parou de digitar
+func (bms *BluetoothMeshService) notifyTyping(event service.TypingEvent) {
+	bms.events.publish(topicPeer, PeerEvent{Type: PeerTyping, PeerID: event.PeerID, Typing: event})
+}
@@ -0,0 +1,191 @@
+package bluetooth
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/muka/go-bluetooth/bluez/profile/device"
+)
+
+// handleDeviceFound registra um dispositivo Bitchat descoberto ou já
+// conhecido pelo BlueZ e passa a acompanhar suas propriedades
+func (lba *LinuxBluetoothAdapter) handleDeviceFound(path string, dev *device.Device1) {
+	// Verificar se o dispositivo oferece o serviço Bitchat
+	if !isBitchatDevice(dev) {
+		return
+	}
+
+	// Armazenar dispositivo
+	lba.deviceMutex.Lock()
+	_, known := lba.devices[path]
+	lba.devices[path] = dev
+	lba.deviceMutex.Unlock()
+
+	// Registrar como candidato; o gerenciador de conexões decide quando
+	// discar. Dispositivos do cache do BlueZ sem RSSI aguardam um anúncio.
+	if rssi, err := dev.GetRSSI(); err == nil {
+		lba.observeRSSI(path, int(rssi))
+	}
+	lba.updateAnnounce(path, dev)
+
+	if !known {
+		lba.watchDevice(path, dev)
+	}
+}
+
+// watchDevice acompanha os sinais PropertiesChanged de um dispositivo: cada
+// anúncio recebido atualiza o RSSI e o anúncio compacto sem esperar a
+// próxima avaliação das conexões
+func (lba *LinuxBluetoothAdapter) watchDevice(path string, dev *device.Device1) {
+	changes, err := dev.WatchProperties()
+	if err != nil {
+		slog.Error("erro ao monitorar dispositivo", "device", path, "err", err)
+		return
+	}
+
+	ctx, stop := context.WithCancel(lba.ctx)
+	lba.deviceMutex.Lock()
+	lba.deviceWatches[path] = stop
+	lba.deviceMutex.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				// UnwatchProperties entrega um nil no canal antes de fechá-lo
+				go dev.UnwatchProperties(changes)
+				for change := range changes {
+					if change == nil {
+						return
+					}
+				}
+				return
+			case change := <-changes:
+				if change == nil {
+					return
+				}
+				if change.Interface != device.Device1Interface {
+					continue
+				}
+
+				switch change.Name {
+				case "RSSI":
+					// Com DuplicateData o BlueZ informa o RSSI de cada anúncio
+					if rssi, ok := change.Value.(int16); ok {
+						lba.observeRSSI(path, int(rssi))
+					}
+				case "ServiceData":
+					lba.updateAnnounce(path, dev)
+				}
+			}
+		}
+	}()
+}
+
+// observeRSSI registra uma leitura de RSSI de um dispositivo, que alimenta a
+// priorização de candidatos e enlaces
+func (lba *LinuxBluetoothAdapter) observeRSSI(path string, rssi int) {
+	lba.connections.Observe(path, rssi)
+
+	if address := addressFromDevicePath(path); address != "" {
+		lba.deviceMutex.Lock()
+		lba.deviceRSSI[address] = rssi
+		lba.deviceMutex.Unlock()
+	}
+}
+
+// updateAnnounce prioriza a conexão com peers conhecidos, reconhecidos pelo
+// anúncio compacto do dispositivo
+func (lba *LinuxBluetoothAdapter) updateAnnounce(path string, dev *device.Device1) {
+	if announce := compactAnnounce(dev); announce != nil && lba.onAnnounce != nil {
+		lba.connections.Prefer(path, lba.onAnnounce(addressFromDevicePath(path), announce))
+	}
+}
+
+// forgetDevice descarta um dispositivo removido pelo BlueZ
+func (lba *LinuxBluetoothAdapter) forgetDevice(path string) {
+	address := addressFromDevicePath(path)
+
+	lba.deviceMutex.Lock()
+	delete(lba.devices, path)
+	delete(lba.deviceRSSI, address)
+	stop := lba.deviceWatches[path]
+	delete(lba.deviceWatches, path)
+	lba.deviceMutex.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+	lba.connections.Forget(path)
+	lba.connections.Disconnected(path)
+	lba.unsubscribeNotifications(address)
+}
+
+// ForgetRotatedAddress descarta o dispositivo em um endereço substituído
+// pela rotação do MAC do peer. Um enlace ainda ativo é mantido até cair.
+func (lba *LinuxBluetoothAdapter) ForgetRotatedAddress(address string) {
+	path, dev := lba.deviceByAddress(address)
+	if dev == nil || lba.connections.IsConnected(path) {
+		return
+	}
+	lba.forgetDevice(path)
+}
+
+// isBitchatDevice verifica se um dispositivo anuncia o serviço Bitchat na
+// lista de UUIDs, nos dados de serviço ou pelo identificador Bitchat nos
+// dados do fabricante. A lista de UUIDs pode só chegar com a resposta ao
+// escaneamento, depois dos dados de serviço e do fabricante.
+func isBitchatDevice(dev *device.Device1) bool {
+	if uuids, err := dev.GetUUIDs(); err == nil && containsUUID(uuids, ServiceUUID) {
+		return true
+	}
+	if serviceData, err := dev.GetServiceData(); err == nil {
+		for uuid := range serviceData {
+			if strings.EqualFold(uuid, ServiceUUID) {
+				return true
+			}
+		}
+	}
+	if manufacturerData, err := dev.GetManufacturerData(); err == nil {
+		return hasManufacturerTag(parseManufacturerData(manufacturerData))
+	}
+	return false
+}
+
+// parseManufacturerData converte os dados do fabricante informados pelo
+// BlueZ, cujos valores podem vir como bytes ou variantes D-Bus
+func parseManufacturerData(data map[uint16]interface{}) map[uint16][]byte {
+	parsed := make(map[uint16][]byte, len(data))
+	for companyID, entry := range data {
+		if variant, ok := entry.(dbus.Variant); ok {
+			entry = variant.Value()
+		}
+		if value, ok := entry.([]byte); ok {
+			parsed[companyID] = value
+		}
+	}
+	return parsed
+}
+
+// hasManufacturerTag verifica se algum dos dados do fabricante anunciados
+// começa com o identificador Bitchat
+func hasManufacturerTag(manufacturerData map[uint16][]byte) bool {
+	for _, data := range manufacturerData {
+		if bytes.HasPrefix(data, []byte(ManufacturerTag)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RSSI retorna a última leitura de RSSI do dispositivo no endereço informado
+func (lba *LinuxBluetoothAdapter) RSSI(address string) (int, bool) {
+	lba.deviceMutex.RLock()
+	defer lba.deviceMutex.RUnlock()
+
+	rssi, ok := lba.deviceRSSI[address]
+	return rssi, ok
+}
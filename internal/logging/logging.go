@@ -0,0 +1,155 @@
+// Package logging configura o logger estruturado (log/slog) usado pelos
+// pacotes do Bitchat, com nível ajustável e saída opcional em arquivo com
+// rotação por tamanho.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultMaxSize é o tamanho a partir do qual o arquivo de log é rotacionado
+	DefaultMaxSize = 10 << 20
+
+	// DefaultMaxBackups é o número de arquivos rotacionados mantidos
+	DefaultMaxBackups = 3
+)
+
+// Options configura o logger padrão
+type Options struct {
+	Level      slog.Level
+	File       string // Arquivo de log; vazio escreve em Output
+	MaxSize    int64  // Tamanho máximo do arquivo antes de rotacionar (0: DefaultMaxSize)
+	MaxBackups int    // Arquivos rotacionados mantidos (0: DefaultMaxBackups)
+	Output     io.Writer
+}
+
+// ParseLevel converte o nome de um nível de log (debug, info, warn, error)
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToLower(name))); err != nil {
+		return level, fmt.Errorf("nível de log inválido: %s", name)
+	}
+	return level, nil
+}
+
+// Setup instala o logger padrão do slog conforme as opções. O io.Closer
+// retornado fecha o arquivo de log, se houver.
+func Setup(opts Options) (io.Closer, error) {
+	var output io.Writer = opts.Output
+	var closer io.Closer = nopCloser{}
+	if opts.File != "" {
+		file, err := OpenRotatingFile(opts.File, opts.MaxSize, opts.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		output, closer = file, file
+	}
+	if output == nil {
+		output = os.Stderr
+	}
+
+	handler := slog.NewTextHandler(output, &slog.HandlerOptions{Level: opts.Level})
+	slog.SetDefault(slog.New(handler))
+	return closer, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// RotatingFile é um arquivo de log que, ao atingir o tamanho máximo, é
+// renomeado para arquivo.1 (e os anteriores para .2, .3...) antes de
+// continuar em um arquivo novo
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+	mutex      sync.Mutex
+}
+
+// OpenRotatingFile abre, ou cria, um arquivo de log com rotação
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if maxBackups <= 0 {
+		maxBackups = DefaultMaxBackups
+	}
+
+	rf := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *RotatingFile) open() error {
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de log: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("erro ao abrir arquivo de log: %v", err)
+	}
+	rf.file, rf.size = file, info.Size()
+	return nil
+}
+
+// Write escreve no arquivo, rotacionando-o antes se a escrita ultrapassar
+// o tamanho máximo
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mutex.Lock()
+	defer rf.mutex.Unlock()
+
+	if rf.file == nil {
+		return 0, os.ErrClosed
+	}
+	if rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// rotate desloca os arquivos antigos, descartando o mais velho, e abre um
+// arquivo novo
+func (rf *RotatingFile) rotate() error {
+	rf.file.Close()
+	rf.file = nil
+
+	os.Remove(fmt.Sprintf("%s.%d", rf.path, rf.maxBackups))
+	for i := rf.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", rf.path, i), fmt.Sprintf("%s.%d", rf.path, i+1))
+	}
+	if err := os.Rename(rf.path, rf.path+".1"); err != nil {
+		return fmt.Errorf("erro ao rotacionar arquivo de log: %v", err)
+	}
+	return rf.open()
+}
+
+// Close fecha o arquivo de log
+func (rf *RotatingFile) Close() error {
+	rf.mutex.Lock()
+	defer rf.mutex.Unlock()
+
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]slog.Level{"debug": slog.LevelDebug, "INFO": slog.LevelInfo, "warn": slog.LevelWarn, "error": slog.LevelError} {
+		if level, err := ParseLevel(name); err != nil || level != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; esperado %v", name, level, err, want)
+		}
+	}
+	if _, err := ParseLevel("verboso"); err == nil {
+		t.Error("Nível inválido deveria ser recusado")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bitchat.log")
+	rf, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("Erro ao abrir arquivo de log: %v", err)
+	}
+	defer rf.Close()
+
+	for _, line := range []string{"primeira\n", "segunda\n", "terceira\n", "quarta\n"} {
+		if _, err := rf.Write([]byte(line)); err != nil {
+			t.Fatalf("Erro ao escrever: %v", err)
+		}
+	}
+
+	want := map[string]string{path: "quarta\n", path + ".1": "terceira\n", path + ".2": "segunda\n"}
+	for file, content := range want {
+		data, err := os.ReadFile(file)
+		if err != nil || string(data) != content {
+			t.Errorf("%s: esperado %q, obtido %q (%v)", filepath.Base(file), content, data, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Mais arquivos rotacionados que o máximo")
+	}
+	if data, _ := os.ReadFile(path + ".2"); strings.Contains(string(data), "primeira") {
+		t.Error("Arquivo mais antigo deveria ter sido descartado")
+	}
+}
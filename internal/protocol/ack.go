@@ -0,0 +1,88 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+)
+
+// EncodeDeliveryAck serializa um DeliveryAck para o payload de um pacote
+// MessageTypeDeliveryAck
+func EncodeDeliveryAck(ack *DeliveryAck) []byte {
+	buf := new(bytes.Buffer)
+
+	writeShortBytes(buf, []byte(ack.OriginalMessageID))
+	writeShortBytes(buf, []byte(ack.RecipientNickname))
+	buf.WriteByte(ack.HopCount)
+	binary.Write(buf, binary.BigEndian, uint64(ack.Timestamp.UnixMilli()))
+
+	return buf.Bytes()
+}
+
+// DecodeDeliveryAck deserializa um DeliveryAck
+func DecodeDeliveryAck(data []byte) (*DeliveryAck, error) {
+	buf := bytes.NewReader(data)
+	ack := &DeliveryAck{}
+
+	messageID, err := readShortBytes(buf)
+	if err != nil || len(messageID) == 0 {
+		return nil, ErrInvalidPacket
+	}
+	ack.OriginalMessageID = string(messageID)
+
+	nickname, err := readShortBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	ack.RecipientNickname = string(nickname)
+
+	if ack.HopCount, err = buf.ReadByte(); err != nil {
+		return nil, ErrInvalidPacket
+	}
+
+	var timestamp uint64
+	if err := binary.Read(buf, binary.BigEndian, &timestamp); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	ack.Timestamp = time.UnixMilli(int64(timestamp))
+
+	return ack, nil
+}
+
+// EncodeReadReceipt serializa um ReadReceipt para o payload de um pacote
+// MessageTypeReadReceipt
+func EncodeReadReceipt(receipt *ReadReceipt) []byte {
+	buf := new(bytes.Buffer)
+
+	writeShortBytes(buf, []byte(receipt.OriginalMessageID))
+	writeShortBytes(buf, []byte(receipt.ReaderNickname))
+	binary.Write(buf, binary.BigEndian, uint64(receipt.Timestamp.UnixMilli()))
+
+	return buf.Bytes()
+}
+
+// DecodeReadReceipt deserializa um ReadReceipt
+func DecodeReadReceipt(data []byte) (*ReadReceipt, error) {
+	buf := bytes.NewReader(data)
+	receipt := &ReadReceipt{}
+
+	messageID, err := readShortBytes(buf)
+	if err != nil || len(messageID) == 0 {
+		return nil, ErrInvalidPacket
+	}
+	receipt.OriginalMessageID = string(messageID)
+
+	nickname, err := readShortBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	receipt.ReaderNickname = string(nickname)
+
+	var timestamp uint64
+	if err := binary.Read(buf, binary.BigEndian, &timestamp); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	receipt.Timestamp = time.UnixMilli(int64(timestamp))
+
+	return receipt, nil
+}
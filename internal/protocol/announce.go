@@ -0,0 +1,89 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+const (
+	// CompactAnnounceVersion identifica o formato compacto nos dados de serviço
+	CompactAnnounceVersion uint8 = 0x02
+
+	// CompactAnnounceMaxSize é o espaço reservado ao anúncio compacto nos
+	// dados de serviço, cabendo com folga em um advertising legado
+	CompactAnnounceMaxSize = 26
+
+	// compactAnnounceSize é o tamanho serializado de um CompactAnnounce
+	compactAnnounceSize = 14
+)
+
+// CompactAnnounce é o anúncio levado nos dados de serviço do advertising. Com
+// ele um peer já conhecido é reconhecido, e priorizado, antes de qualquer
+// conexão GATT; o anúncio completo continua sendo trocado após a conexão.
+// Bytes além dos campos conhecidos são ignorados, permitindo acrescentar
+// campos dentro de CompactAnnounceMaxSize.
+type CompactAnnounce struct {
+	IdentityHash [8]byte // Início do SHA-256 da identidade do nó
+	Flags        uint8   // Capacidades do nó, com os mesmos bits de Hello.Flags
+	NicknameHash uint32  // Início do SHA-256 do apelido
+}
+
+// NewCompactAnnounce monta o anúncio compacto de um nó
+func NewCompactAnnounce(identity []byte, nickname string, flags uint8) *CompactAnnounce {
+	return &CompactAnnounce{
+		IdentityHash: HashIdentity(identity),
+		Flags:        flags,
+		NicknameHash: HashNickname(nickname),
+	}
+}
+
+// HashIdentity retorna o hash truncado de uma identidade
+func HashIdentity(identity []byte) [8]byte {
+	var hash [8]byte
+	sum := sha256.Sum256(identity)
+	copy(hash[:], sum[:])
+	return hash
+}
+
+// HashNickname retorna o hash truncado de um apelido
+func HashNickname(nickname string) uint32 {
+	sum := sha256.Sum256([]byte(nickname))
+	return binary.BigEndian.Uint32(sum[:4])
+}
+
+// MatchesIdentity verifica se o anúncio pertence a uma identidade
+func (ca *CompactAnnounce) MatchesIdentity(identity []byte) bool {
+	return ca.IdentityHash == HashIdentity(identity)
+}
+
+// MatchesNickname verifica se o anúncio é compatível com um apelido
+func (ca *CompactAnnounce) MatchesNickname(nickname string) bool {
+	return ca.NicknameHash == HashNickname(nickname)
+}
+
+// EncodeCompactAnnounce serializa um CompactAnnounce
+func EncodeCompactAnnounce(announce *CompactAnnounce) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(CompactAnnounceVersion)
+	buf.WriteByte(announce.Flags)
+	buf.Write(announce.IdentityHash[:])
+	binary.Write(buf, binary.BigEndian, announce.NicknameHash)
+	return buf.Bytes()
+}
+
+// DecodeCompactAnnounce deserializa um CompactAnnounce. Dados de outra
+// versão, como os anúncios com o nome completo de versões anteriores,
+// resultam em ErrInvalidPacket.
+func DecodeCompactAnnounce(data []byte) (*CompactAnnounce, error) {
+	if len(data) < compactAnnounceSize || len(data) > CompactAnnounceMaxSize || data[0] != CompactAnnounceVersion {
+		return nil, ErrInvalidPacket
+	}
+
+	announce := &CompactAnnounce{
+		Flags:        data[1],
+		NicknameHash: binary.BigEndian.Uint32(data[10:14]),
+	}
+	copy(announce.IdentityHash[:], data[2:10])
+	return announce, nil
+}
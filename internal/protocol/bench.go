@@ -0,0 +1,45 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// BenchProbe é o payload dos pacotes MessageTypeBenchProbe e MessageTypeBenchReply.
+// A carga de teste leva um enchimento do tamanho escolhido para a medição; a
+// confirmação repete apenas a sessão e a sequência.
+type BenchProbe struct {
+	SessionID uint32 // Sessão de medição iniciada pelo remetente
+	Sequence  uint32 // Número da carga dentro da sessão
+	Padding   []byte // Enchimento que compõe o tamanho da carga
+}
+
+// EncodeBenchProbe serializa um BenchProbe
+func EncodeBenchProbe(probe *BenchProbe) []byte {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, probe.SessionID)
+	binary.Write(buf, binary.BigEndian, probe.Sequence)
+	buf.Write(probe.Padding)
+
+	return buf.Bytes()
+}
+
+// DecodeBenchProbe deserializa um BenchProbe
+func DecodeBenchProbe(data []byte) (*BenchProbe, error) {
+	buf := bytes.NewReader(data)
+	probe := &BenchProbe{}
+
+	if err := binary.Read(buf, binary.BigEndian, &probe.SessionID); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if err := binary.Read(buf, binary.BigEndian, &probe.Sequence); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if buf.Len() > 0 {
+		probe.Padding = make([]byte, buf.Len())
+		buf.Read(probe.Padding)
+	}
+
+	return probe, nil
+}
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
-	"io"
+	"slices"
 )
 
 // Erros relacionados ao protocolo binário
@@ -13,175 +13,165 @@ var (
 	ErrBufferTooSmall = errors.New("buffer muito pequeno para decodificar o pacote")
 )
 
+// Tamanho fixo de um pacote codificado, além dos campos de tamanho variável:
+// versão, tipo, tamanhos de SenderID e RecipientID, timestamp, tamanho do
+// payload, tamanho da assinatura, TTL e contador de saltos
+const encodedFixedSize = 1 + 1 + 1 + 1 + 8 + 4 + 1 + 1 + 1
+
+// EncodedSize retorna o tamanho de um pacote codificado por Encode
+func EncodedSize(packet *BitchatPacket) int {
+	size := encodedFixedSize + len(packet.SenderID) + len(packet.RecipientID) + len(packet.Payload) + len(packet.Signature)
+	if packet.Flags != 0 {
+		size++
+	}
+	return size
+}
+
 // Encode serializa um BitchatPacket em um formato binário eficiente
 func Encode(packet *BitchatPacket) ([]byte, error) {
-	// Calcular o tamanho total do buffer
-	size := 1 + 1 + 1 + len(packet.SenderID) + 1
-	if packet.RecipientID != nil {
-		size += len(packet.RecipientID)
-	}
-	size += 8 + 4 + len(packet.Payload)
-	if packet.Signature != nil {
-		size += len(packet.Signature)
-	}
-	size += 1 // TTL
+	return AppendEncode(make([]byte, 0, EncodedSize(packet)), packet)
+}
 
-	// Criar buffer
-	buf := bytes.NewBuffer(make([]byte, 0, size))
+// AppendEncode acrescenta a dst o pacote serializado como em Encode e
+// retorna a fatia estendida. Não aloca memória quando dst tem capacidade
+// para EncodedSize(packet) bytes, como os buffers de GetBuffer.
+func AppendEncode(dst []byte, packet *BitchatPacket) ([]byte, error) {
+	dst = slices.Grow(dst, EncodedSize(packet))
 
-	// Escrever versão
-	if err := buf.WriteByte(packet.Version); err != nil {
-		return nil, err
-	}
+	// Versão e tipo
+	dst = append(dst, packet.Version, byte(packet.Type))
 
-	// Escrever tipo
-	if err := buf.WriteByte(byte(packet.Type)); err != nil {
-		return nil, err
-	}
+	// Tamanho e dados do SenderID
+	dst = append(dst, byte(len(packet.SenderID)))
+	dst = append(dst, packet.SenderID...)
 
-	// Escrever tamanho e dados do SenderID
-	if err := buf.WriteByte(byte(len(packet.SenderID))); err != nil {
-		return nil, err
-	}
-	if _, err := buf.Write(packet.SenderID); err != nil {
-		return nil, err
-	}
+	// Tamanho e dados do RecipientID (0 se ausente)
+	dst = append(dst, byte(len(packet.RecipientID)))
+	dst = append(dst, packet.RecipientID...)
 
-	// Escrever tamanho e dados do RecipientID (se presente)
-	if packet.RecipientID != nil {
-		if err := buf.WriteByte(byte(len(packet.RecipientID))); err != nil {
-			return nil, err
-		}
-		if _, err := buf.Write(packet.RecipientID); err != nil {
-			return nil, err
-		}
-	} else {
-		if err := buf.WriteByte(0); err != nil {
-			return nil, err
-		}
-	}
+	// Timestamp
+	dst = binary.BigEndian.AppendUint64(dst, packet.Timestamp)
 
-	// Escrever timestamp
-	if err := binary.Write(buf, binary.BigEndian, packet.Timestamp); err != nil {
-		return nil, err
-	}
+	// Tamanho e dados do Payload
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(packet.Payload)))
+	dst = append(dst, packet.Payload...)
 
-	// Escrever tamanho e dados do Payload
-	if err := binary.Write(buf, binary.BigEndian, uint32(len(packet.Payload))); err != nil {
-		return nil, err
-	}
-	if _, err := buf.Write(packet.Payload); err != nil {
-		return nil, err
-	}
+	// Tamanho e dados da Signature (0 se ausente)
+	dst = append(dst, byte(len(packet.Signature)))
+	dst = append(dst, packet.Signature...)
 
-	// Escrever tamanho e dados da Signature (se presente)
-	if packet.Signature != nil {
-		if err := buf.WriteByte(byte(len(packet.Signature))); err != nil {
-			return nil, err
-		}
-		if _, err := buf.Write(packet.Signature); err != nil {
-			return nil, err
-		}
-	} else {
-		if err := buf.WriteByte(0); err != nil {
-			return nil, err
-		}
-	}
+	// TTL e contador de saltos
+	dst = append(dst, packet.TTL, packet.HopCount)
 
-	// Escrever TTL
-	if err := buf.WriteByte(packet.TTL); err != nil {
-		return nil, err
+	// Flags apenas se presentes, mantendo o formato anterior
+	if packet.Flags != 0 {
+		dst = append(dst, packet.Flags)
 	}
 
-	return buf.Bytes(), nil
+	return dst, nil
 }
 
-// Decode deserializa um BitchatPacket a partir de dados binários
+// Decode deserializa um BitchatPacket a partir de dados binários. Os campos
+// do pacote compartilham uma única cópia de data, que pode ser reutilizado
+// pelo chamador.
 func Decode(data []byte) (*BitchatPacket, error) {
 	if len(data) < 13 { // Tamanho mínimo para um pacote válido
 		return nil, ErrBufferTooSmall
 	}
 
-	buf := bytes.NewBuffer(data)
 	packet := &BitchatPacket{}
+	d := decoder{data: bytes.Clone(data)}
 
-	// Ler versão
-	version, err := buf.ReadByte()
-	if err != nil {
-		return nil, err
-	}
-	packet.Version = version
+	// Versão e tipo
+	packet.Version = d.byte()
+	packet.Type = MessageType(d.byte())
 
-	// Ler tipo
-	msgType, err := buf.ReadByte()
-	if err != nil {
-		return nil, err
-	}
-	packet.Type = MessageType(msgType)
+	// SenderID e RecipientID
+	packet.SenderID = d.field(int(d.byte()))
+	packet.RecipientID = d.field(int(d.byte()))
 
-	// Ler SenderID
-	senderIDLen, err := buf.ReadByte()
-	if err != nil {
-		return nil, err
-	}
-	if senderIDLen > 0 {
-		packet.SenderID = make([]byte, senderIDLen)
-		if _, err := io.ReadFull(buf, packet.SenderID); err != nil {
-			return nil, err
-		}
-	}
+	// Timestamp
+	packet.Timestamp = d.uint64()
 
-	// Ler RecipientID
-	recipientIDLen, err := buf.ReadByte()
-	if err != nil {
-		return nil, err
+	// Payload
+	packet.Payload = d.field(int(d.uint32()))
+
+	// Signature
+	packet.Signature = d.field(int(d.byte()))
+
+	// TTL
+	packet.TTL = d.byte()
+	if d.err != nil {
+		return nil, d.err
 	}
-	if recipientIDLen > 0 {
-		packet.RecipientID = make([]byte, recipientIDLen)
-		if _, err := io.ReadFull(buf, packet.RecipientID); err != nil {
-			return nil, err
-		}
+
+	// Contador de saltos (opcional para compatibilidade com versões anteriores)
+	if d.remaining() > 0 {
+		packet.HopCount = d.byte()
 	}
 
-	// Ler timestamp
-	var timestamp uint64
-	if err := binary.Read(buf, binary.BigEndian, &timestamp); err != nil {
-		return nil, err
+	// Flags (opcional, ausente quando não há flags)
+	if d.remaining() > 0 {
+		packet.Flags = d.byte()
 	}
-	packet.Timestamp = timestamp
 
-	// Ler Payload
-	var payloadLen uint32
-	if err := binary.Read(buf, binary.BigEndian, &payloadLen); err != nil {
-		return nil, err
+	return packet, nil
+}
+
+// decoder lê os campos de um pacote em sequência. Após o primeiro campo
+// truncado, err é ErrInvalidPacket e as leituras retornam valores zero.
+type decoder struct {
+	data []byte
+	off  int
+	err  error
+}
+
+// remaining retorna o número de bytes ainda não lidos
+func (d *decoder) remaining() int {
+	return len(d.data) - d.off
+}
+
+// next retorna os próximos n bytes, ou nil se os dados terminam antes
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
 	}
-	if payloadLen > 0 {
-		packet.Payload = make([]byte, payloadLen)
-		if _, err := io.ReadFull(buf, packet.Payload); err != nil {
-			return nil, err
-		}
+	if n > d.remaining() {
+		d.err = ErrInvalidPacket
+		return nil
 	}
+	b := d.data[d.off : d.off+n : d.off+n]
+	d.off += n
+	return b
+}
 
-	// Ler Signature
-	signatureLen, err := buf.ReadByte()
-	if err != nil {
-		return nil, err
+func (d *decoder) byte() byte {
+	if b := d.next(1); b != nil {
+		return b[0]
 	}
-	if signatureLen > 0 {
-		packet.Signature = make([]byte, signatureLen)
-		if _, err := io.ReadFull(buf, packet.Signature); err != nil {
-			return nil, err
-		}
+	return 0
+}
+
+func (d *decoder) uint32() uint32 {
+	if b := d.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
 	}
+	return 0
+}
 
-	// Ler TTL
-	ttl, err := buf.ReadByte()
-	if err != nil {
-		return nil, err
+func (d *decoder) uint64() uint64 {
+	if b := d.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
 	}
-	packet.TTL = ttl
+	return 0
+}
 
-	return packet, nil
+// field lê um campo de n bytes, retornando nil para campos vazios
+func (d *decoder) field(n int) []byte {
+	if n == 0 {
+		return nil
+	}
+	return d.next(n)
 }
 
 // MessagePadding implementa utilitários de padding para privacidade
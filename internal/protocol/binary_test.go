@@ -6,350 +6,217 @@ import (
 	"time"
 )
 
+// testPacket cria um pacote com todos os campos serializados preenchidos
+func testPacket() *BitchatPacket {
+	return &BitchatPacket{
+		Version:     1,
+		Type:        MessageTypeMessage,
+		SenderID:    []byte("sender-1"),
+		RecipientID: []byte("recipient-2"),
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Payload:     []byte("Conteúdo da mensagem de teste"),
+		Signature:   []byte("assinatura-simulada"),
+		TTL:         5,
+		HopCount:    2,
+	}
+}
+
+// checkPacket compara os campos serializados de dois pacotes
+func checkPacket(t *testing.T, got, want *BitchatPacket) {
+	t.Helper()
+
+	if got.Version != want.Version || got.Type != want.Type {
+		t.Errorf("Versão e tipo esperados %d/%d, obtidos %d/%d", want.Version, want.Type, got.Version, got.Type)
+	}
+	if !bytes.Equal(got.SenderID, want.SenderID) || !bytes.Equal(got.RecipientID, want.RecipientID) {
+		t.Errorf("IDs esperados %q/%q, obtidos %q/%q", want.SenderID, want.RecipientID, got.SenderID, got.RecipientID)
+	}
+	if got.Timestamp != want.Timestamp {
+		t.Errorf("Timestamp esperado %d, obtido %d", want.Timestamp, got.Timestamp)
+	}
+	if !bytes.Equal(got.Payload, want.Payload) || !bytes.Equal(got.Signature, want.Signature) {
+		t.Errorf("Payload e assinatura não correspondem: %q/%q", got.Payload, got.Signature)
+	}
+	if got.TTL != want.TTL || got.HopCount != want.HopCount || got.Flags != want.Flags {
+		t.Errorf("TTL, saltos e flags esperados %d/%d/%d, obtidos %d/%d/%d",
+			want.TTL, want.HopCount, want.Flags, got.TTL, got.HopCount, got.Flags)
+	}
+}
+
 func TestBitchatPacket(t *testing.T) {
 	t.Run("Codificação e decodificação de pacote", func(t *testing.T) {
-		// Criar pacote de teste
-		original := &BitchatPacket{
-			Version:     CurrentProtocolVersion,
-			ID:          "test-packet-id",
-			Type:        MessageTypePrivate,
-			SenderID:    "sender-123",
-			RecipientID: "recipient-456",
-			ChannelID:   "",
-			Timestamp:   uint64(time.Now().UnixMilli()),
-			TTL:         5,
-			Payload:     []byte("Conteúdo da mensagem de teste"),
-			Signature:   []byte("assinatura-simulada"),
-		}
+		original := testPacket()
 
-		// Codificar pacote
-		encoded, err := original.Encode()
+		encoded, err := Encode(original)
 		if err != nil {
 			t.Fatalf("Erro ao codificar pacote: %v", err)
 		}
+		if len(encoded) != EncodedSize(original) {
+			t.Errorf("Tamanho esperado %d, obtido %d", EncodedSize(original), len(encoded))
+		}
 
-		// Decodificar pacote
-		decoded, err := DecodePacket(encoded)
+		decoded, err := Decode(encoded)
 		if err != nil {
 			t.Fatalf("Erro ao decodificar pacote: %v", err)
 		}
-
-		// Verificar se os campos foram preservados
-		if decoded.Version != original.Version {
-			t.Errorf("Versão não corresponde: esperado %d, obtido %d", original.Version, decoded.Version)
-		}
-		if decoded.ID != original.ID {
-			t.Errorf("ID não corresponde: esperado %s, obtido %s", original.ID, decoded.ID)
-		}
-		if decoded.Type != original.Type {
-			t.Errorf("Tipo não corresponde: esperado %d, obtido %d", original.Type, decoded.Type)
-		}
-		if decoded.SenderID != original.SenderID {
-			t.Errorf("SenderID não corresponde: esperado %s, obtido %s", original.SenderID, decoded.SenderID)
-		}
-		if decoded.RecipientID != original.RecipientID {
-			t.Errorf("RecipientID não corresponde: esperado %s, obtido %s", original.RecipientID, decoded.RecipientID)
-		}
-		if decoded.ChannelID != original.ChannelID {
-			t.Errorf("ChannelID não corresponde: esperado %s, obtido %s", original.ChannelID, decoded.ChannelID)
-		}
-		if decoded.Timestamp != original.Timestamp {
-			t.Errorf("Timestamp não corresponde: esperado %d, obtido %d", original.Timestamp, decoded.Timestamp)
-		}
-		if decoded.TTL != original.TTL {
-			t.Errorf("TTL não corresponde: esperado %d, obtido %d", original.TTL, decoded.TTL)
-		}
-		if !bytes.Equal(decoded.Payload, original.Payload) {
-			t.Errorf("Payload não corresponde: esperado %v, obtido %v", original.Payload, decoded.Payload)
-		}
-		if !bytes.Equal(decoded.Signature, original.Signature) {
-			t.Errorf("Signature não corresponde: esperado %v, obtido %v", original.Signature, decoded.Signature)
-		}
+		checkPacket(t, decoded, original)
 	})
 
-	t.Run("Codificação e decodificação de mensagem de canal", func(t *testing.T) {
-		// Criar pacote de canal
-		original := &BitchatPacket{
-			Version:   CurrentProtocolVersion,
-			ID:        "channel-packet-id",
-			Type:      MessageTypeChannel,
-			SenderID:  "sender-123",
-			ChannelID: "channel-general",
-			Timestamp: uint64(time.Now().UnixMilli()),
-			TTL:       5,
-			Payload:   []byte("Mensagem para o canal geral"),
-			Signature: []byte("assinatura-canal"),
-		}
+	t.Run("Flags opcionais", func(t *testing.T) {
+		without := testPacket()
+		with := testPacket()
+		with.Flags = PacketFlagCompressed
 
-		// Codificar pacote
-		encoded, err := original.Encode()
-		if err != nil {
-			t.Fatalf("Erro ao codificar pacote de canal: %v", err)
+		encodedWithout, _ := Encode(without)
+		encodedWith, _ := Encode(with)
+		if len(encodedWith) != len(encodedWithout)+1 {
+			t.Errorf("Flags deveriam ocupar um byte: %d e %d bytes", len(encodedWith), len(encodedWithout))
 		}
-
-		// Decodificar pacote
-		decoded, err := DecodePacket(encoded)
-		if err != nil {
-			t.Fatalf("Erro ao decodificar pacote de canal: %v", err)
+		if !bytes.HasPrefix(encodedWith, encodedWithout) {
+			t.Error("Pacote com flags deveria estender o formato sem flags")
 		}
 
-		// Verificar campos específicos de canal
-		if decoded.Type != MessageTypeChannel {
-			t.Errorf("Tipo não corresponde: esperado %d, obtido %d", MessageTypeChannel, decoded.Type)
-		}
-		if decoded.ChannelID != original.ChannelID {
-			t.Errorf("ChannelID não corresponde: esperado %s, obtido %s", original.ChannelID, decoded.ChannelID)
-		}
-		if decoded.RecipientID != "" {
-			t.Errorf("RecipientID deveria ser vazio para mensagem de canal, obtido %s", decoded.RecipientID)
+		for _, original := range []*BitchatPacket{without, with} {
+			encoded, _ := Encode(original)
+			decoded, err := Decode(encoded)
+			if err != nil {
+				t.Fatalf("Erro ao decodificar pacote com flags %d: %v", original.Flags, err)
+			}
+			checkPacket(t, decoded, original)
 		}
 	})
 
-	t.Run("Validação de pacote", func(t *testing.T) {
-		// Pacote válido
-		validPacket := &BitchatPacket{
-			Version:     CurrentProtocolVersion,
-			ID:          "valid-packet",
-			Type:        MessageTypePrivate,
-			SenderID:    "sender-123",
-			RecipientID: "recipient-456",
-			Timestamp:   uint64(time.Now().UnixMilli()),
-			TTL:         5,
-			Payload:     []byte("Conteúdo válido"),
-			Signature:   []byte("assinatura"),
-		}
-
-		if err := validPacket.Validate(); err != nil {
-			t.Errorf("Pacote válido falhou na validação: %v", err)
-		}
-
-		// Pacote sem ID
-		invalidPacket1 := &BitchatPacket{
-			Version:     CurrentProtocolVersion,
-			ID:          "",
-			Type:        MessageTypePrivate,
-			SenderID:    "sender-123",
-			RecipientID: "recipient-456",
-			Timestamp:   uint64(time.Now().UnixMilli()),
-			Payload:     []byte("Conteúdo"),
+	t.Run("Assinatura cobre as flags", func(t *testing.T) {
+		packet := testPacket()
+		if !bytes.Equal(SignedPayload(packet), packet.Payload) {
+			t.Error("Sem flags, os dados assinados deveriam ser o payload")
 		}
 
-		if err := invalidPacket1.Validate(); err == nil {
-			t.Error("Pacote sem ID deveria falhar na validação")
+		unsigned := SignedPayload(packet)
+		packet.Flags = PacketFlagCompressed
+		signed := SignedPayload(packet)
+		if bytes.Equal(signed, unsigned) {
+			t.Error("Ligar uma flag deveria mudar os dados assinados")
 		}
 
-		// Pacote sem SenderID
-		invalidPacket2 := &BitchatPacket{
-			Version:     CurrentProtocolVersion,
-			ID:          "packet-id",
-			Type:        MessageTypePrivate,
-			SenderID:    "",
-			RecipientID: "recipient-456",
-			Timestamp:   uint64(time.Now().UnixMilli()),
-			Payload:     []byte("Conteúdo"),
-		}
-
-		if err := invalidPacket2.Validate(); err == nil {
-			t.Error("Pacote sem SenderID deveria falhar na validação")
-		}
-
-		// Pacote privado sem RecipientID
-		invalidPacket3 := &BitchatPacket{
-			Version:   CurrentProtocolVersion,
-			ID:        "packet-id",
-			Type:      MessageTypePrivate,
-			SenderID:  "sender-123",
-			Timestamp: uint64(time.Now().UnixMilli()),
-			Payload:   []byte("Conteúdo"),
-		}
-
-		if err := invalidPacket3.Validate(); err == nil {
-			t.Error("Pacote privado sem RecipientID deveria falhar na validação")
-		}
-
-		// Pacote de canal sem ChannelID
-		invalidPacket4 := &BitchatPacket{
-			Version:   CurrentProtocolVersion,
-			ID:        "packet-id",
-			Type:      MessageTypeChannel,
-			SenderID:  "sender-123",
-			Timestamp: uint64(time.Now().UnixMilli()),
-			Payload:   []byte("Conteúdo"),
+		// Relays alteram TTL e saltos sem invalidar a assinatura
+		packet.TTL--
+		packet.HopCount++
+		if !bytes.Equal(SignedPayload(packet), signed) {
+			t.Error("TTL e saltos não deveriam fazer parte dos dados assinados")
 		}
+	})
 
-		if err := invalidPacket4.Validate(); err == nil {
-			t.Error("Pacote de canal sem ChannelID deveria falhar na validação")
-		}
+	t.Run("Formato anterior sem contador de saltos", func(t *testing.T) {
+		original := testPacket()
+		original.HopCount = 0
 
-		// Pacote com versão incompatível
-		invalidPacket5 := &BitchatPacket{
-			Version:     CurrentProtocolVersion + 10,
-			ID:          "packet-id",
-			Type:        MessageTypePrivate,
-			SenderID:    "sender-123",
-			RecipientID: "recipient-456",
-			Timestamp:   uint64(time.Now().UnixMilli()),
-			Payload:     []byte("Conteúdo"),
-		}
+		// Versões anteriores terminavam o pacote no TTL
+		encoded, _ := Encode(original)
+		legacy := encoded[:len(encoded)-1]
 
-		if err := invalidPacket5.Validate(); err == nil {
-			t.Error("Pacote com versão incompatível deveria falhar na validação")
+		decoded, err := Decode(legacy)
+		if err != nil {
+			t.Fatalf("Erro ao decodificar formato anterior: %v", err)
 		}
+		checkPacket(t, decoded, original)
 	})
 
-	t.Run("Fragmentação e reconstrução", func(t *testing.T) {
-		// Criar pacote grande
-		largePayload := make([]byte, MaxPayloadSize*3) // 3x o tamanho máximo
-		for i := range largePayload {
-			largePayload[i] = byte(i % 256)
-		}
-
-		original := &BitchatPacket{
-			Version:     CurrentProtocolVersion,
-			ID:          "large-packet",
-			Type:        MessageTypePrivate,
-			SenderID:    "sender-123",
-			RecipientID: "recipient-456",
-			Timestamp:   uint64(time.Now().UnixMilli()),
-			TTL:         5,
-			Payload:     largePayload,
-			Signature:   []byte("assinatura-grande"),
-		}
+	t.Run("Campos opcionais vazios", func(t *testing.T) {
+		original := testPacket()
+		original.RecipientID = nil
+		original.Signature = nil
 
-		// Fragmentar pacote
-		fragments, err := original.Fragment()
+		encoded, _ := Encode(original)
+		decoded, err := Decode(encoded)
 		if err != nil {
-			t.Fatalf("Erro ao fragmentar pacote: %v", err)
+			t.Fatalf("Erro ao decodificar pacote: %v", err)
 		}
-
-		// Verificar número de fragmentos
-		expectedFragments := (len(largePayload) + MaxPayloadSize - 1) / MaxPayloadSize
-		if len(fragments) != expectedFragments {
-			t.Errorf("Número de fragmentos esperado: %d, obtido: %d", expectedFragments, len(fragments))
+		if decoded.RecipientID != nil || decoded.Signature != nil {
+			t.Errorf("Campos vazios deveriam ser nil: %v/%v", decoded.RecipientID, decoded.Signature)
 		}
+		checkPacket(t, decoded, original)
+	})
 
-		// Verificar se cada fragmento tem o mesmo ID base
-		for i, fragment := range fragments {
-			if !bytes.HasPrefix([]byte(fragment.ID), []byte(original.ID)) {
-				t.Errorf("Fragmento %d não tem o ID base correto", i)
-			}
-		}
+	t.Run("Pacote truncado", func(t *testing.T) {
+		encoded, _ := Encode(testPacket())
+		legacySize := len(encoded) - 1
 
-		// Reconstruir pacote a partir dos fragmentos
-		fragmentMap := make(map[string]*BitchatPacket)
-		for _, fragment := range fragments {
-			fragmentMap[fragment.ID] = fragment
+		for size := 0; size < legacySize; size++ {
+			if _, err := Decode(encoded[:size]); err == nil {
+				t.Fatalf("Pacote truncado em %d bytes deveria ser recusado", size)
+			}
 		}
+	})
 
-		reconstructed, complete := ReconstructPacket(fragments[0], fragmentMap)
-		if !complete {
-			t.Error("Reconstrução do pacote não foi completada")
-		}
-		if reconstructed == nil {
-			t.Fatal("Pacote reconstruído é nil")
-		}
+	t.Run("Decodificação não compartilha a entrada", func(t *testing.T) {
+		original := testPacket()
+		encoded, _ := Encode(original)
 
-		// Verificar se o pacote reconstruído é igual ao original
-		if reconstructed.ID != original.ID {
-			t.Errorf("ID não corresponde após reconstrução: esperado %s, obtido %s", original.ID, reconstructed.ID)
-		}
-		if !bytes.Equal(reconstructed.Payload, original.Payload) {
-			t.Error("Payload não corresponde após reconstrução")
-		}
-		if reconstructed.Type != original.Type {
-			t.Errorf("Tipo não corresponde após reconstrução: esperado %d, obtido %d", original.Type, reconstructed.Type)
+		decoded, _ := Decode(encoded)
+		for i := range encoded {
+			encoded[i] = 0xFF
 		}
+		checkPacket(t, decoded, original)
 	})
 
-	t.Run("Reconstrução parcial", func(t *testing.T) {
-		// Criar pacote grande
-		largePayload := make([]byte, MaxPayloadSize*2) // 2x o tamanho máximo
-		for i := range largePayload {
-			largePayload[i] = byte(i % 256)
-		}
+	t.Run("Buffers reutilizados do pool", func(t *testing.T) {
+		first := testPacket()
+		second := testPacket()
+		second.SenderID = []byte("outro-remetente")
+		second.Payload = []byte("payload diferente")
+		second.Flags = PacketFlagCompressed
 
-		original := &BitchatPacket{
-			Version:     CurrentProtocolVersion,
-			ID:          "partial-packet",
-			Type:        MessageTypePrivate,
-			SenderID:    "sender-123",
-			RecipientID: "recipient-456",
-			Timestamp:   uint64(time.Now().UnixMilli()),
-			TTL:         5,
-			Payload:     largePayload,
-		}
-
-		// Fragmentar pacote
-		fragments, err := original.Fragment()
+		buf := GetBuffer()
+		encoded, err := AppendEncode(*buf, first)
 		if err != nil {
-			t.Fatalf("Erro ao fragmentar pacote: %v", err)
-		}
-
-		// Remover um fragmento para simular perda
-		fragmentMap := make(map[string]*BitchatPacket)
-		for i, fragment := range fragments {
-			if i != 1 { // Pular o segundo fragmento
-				fragmentMap[fragment.ID] = fragment
-			}
+			t.Fatalf("Erro ao codificar pacote: %v", err)
 		}
+		*buf = encoded
+		decodedFirst, _ := Decode(*buf)
+		PutBuffer(buf)
 
-		// Tentar reconstruir com fragmentos faltando
-		reconstructed, complete := ReconstructPacket(fragments[0], fragmentMap)
-		if complete {
-			t.Error("Reconstrução não deveria estar completa com fragmentos faltando")
+		// O buffer seguinte pode ser o mesmo, já com dados do pacote anterior
+		buf = GetBuffer()
+		if len(*buf) != 0 {
+			t.Fatalf("Buffer do pool deveria estar vazio, tem %d bytes", len(*buf))
 		}
-		if reconstructed != nil {
-			t.Error("Pacote reconstruído deveria ser nil quando incompleto")
+		*buf, _ = AppendEncode(*buf, second)
+		decodedSecond, err := Decode(*buf)
+		if err != nil {
+			t.Fatalf("Erro ao decodificar pacote: %v", err)
 		}
-	})
+		PutBuffer(buf)
 
-	t.Run("Conversão para Message", func(t *testing.T) {
-		// Pacote privado
-		privatePacket := &BitchatPacket{
-			ID:          "private-msg",
-			Type:        MessageTypePrivate,
-			SenderID:    "sender-123",
-			RecipientID: "recipient-456",
-			Timestamp:   uint64(time.Now().UnixMilli()),
-			Payload:     []byte("Mensagem privada"),
-		}
+		checkPacket(t, decodedFirst, first)
+		checkPacket(t, decodedSecond, second)
 
-		privateMsg := privatePacket.ToMessage()
-		if privateMsg.ID != privatePacket.ID {
-			t.Errorf("ID não corresponde: esperado %s, obtido %s", privatePacket.ID, privateMsg.ID)
-		}
-		if privateMsg.SenderID != privatePacket.SenderID {
-			t.Errorf("SenderID não corresponde: esperado %s, obtido %s", privatePacket.SenderID, privateMsg.SenderID)
-		}
-		if privateMsg.RecipientID != privatePacket.RecipientID {
-			t.Errorf("RecipientID não corresponde: esperado %s, obtido %s", privatePacket.RecipientID, privateMsg.RecipientID)
-		}
-		if privateMsg.ChannelID != "" {
-			t.Errorf("ChannelID deveria ser vazio para mensagem privada, obtido %s", privateMsg.ChannelID)
-		}
-		if !bytes.Equal(privateMsg.Content, privatePacket.Payload) {
-			t.Error("Content não corresponde ao Payload")
+		expected, _ := Encode(second)
+		reused, _ := AppendEncode(make([]byte, 0, pooledBufferSize), second)
+		if !bytes.Equal(reused, expected) {
+			t.Error("AppendEncode deveria produzir os mesmos bytes que Encode")
 		}
+	})
 
-		// Pacote de canal
-		channelPacket := &BitchatPacket{
-			ID:        "channel-msg",
-			Type:      MessageTypeChannel,
-			SenderID:  "sender-123",
-			ChannelID: "channel-general",
-			Timestamp: uint64(time.Now().UnixMilli()),
-			Payload:   []byte("Mensagem de canal"),
+	t.Run("Codificação sem alocação em buffer do pool", func(t *testing.T) {
+		packet := testPacket()
+		allocs := testing.AllocsPerRun(100, func() {
+			buf := GetBuffer()
+			*buf, _ = AppendEncode(*buf, packet)
+			PutBuffer(buf)
+		})
+		if allocs > 0 {
+			t.Errorf("AppendEncode em buffer do pool alocou %.1f vezes por pacote", allocs)
 		}
+	})
 
-		channelMsg := channelPacket.ToMessage()
-		if channelMsg.ID != channelPacket.ID {
-			t.Errorf("ID não corresponde: esperado %s, obtido %s", channelPacket.ID, channelMsg.ID)
-		}
-		if channelMsg.ChannelID != channelPacket.ChannelID {
-			t.Errorf("ChannelID não corresponde: esperado %s, obtido %s", channelPacket.ChannelID, channelMsg.ChannelID)
-		}
-		if channelMsg.RecipientID != "" {
-			t.Errorf("RecipientID deveria ser vazio para mensagem de canal, obtido %s", channelMsg.RecipientID)
+	t.Run("Buffers grandes não voltam ao pool", func(t *testing.T) {
+		big := make([]byte, 0, MaxPooledBufferSize+1)
+		PutBuffer(&big)
+		for i := 0; i < 10; i++ {
+			if buf := GetBuffer(); cap(*buf) > MaxPooledBufferSize {
+				t.Fatal("Buffer acima do limite não deveria ser reutilizado")
+			}
 		}
 	})
 }
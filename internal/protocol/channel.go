@@ -0,0 +1,114 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// MaxChannelTopicLength é o maior tópico, em bytes, aceito em um anúncio de canal
+const MaxChannelTopicLength = 255
+
+// ChannelAnnounce é o payload de um pacote MessageTypeChannelAnnounce com os
+// metadados de um canal. Apenas o dono do canal, identificado pela chave de
+// identidade persistente, anuncia alterações; o anúncio mais recente vale.
+type ChannelAnnounce struct {
+	Channel   string // Nome do canal, com o prefixo #
+	OwnerID   string // ID de identidade do dono (EncryptionService.GetPeerID)
+	Topic     string // Tópico atual, vazio se não definido
+	Timestamp uint64 // Momento da alteração, em milissegundos desde epoch
+}
+
+// EncodeChannelAnnounce serializa um ChannelAnnounce
+func EncodeChannelAnnounce(announce *ChannelAnnounce) []byte {
+	buf := new(bytes.Buffer)
+	writeShortBytes(buf, []byte(announce.Channel))
+	writeShortBytes(buf, []byte(announce.OwnerID))
+	writeShortBytes(buf, []byte(announce.Topic))
+	binary.Write(buf, binary.BigEndian, announce.Timestamp)
+	return buf.Bytes()
+}
+
+// DecodeChannelAnnounce deserializa um ChannelAnnounce
+func DecodeChannelAnnounce(data []byte) (*ChannelAnnounce, error) {
+	buf := bytes.NewReader(data)
+	announce := &ChannelAnnounce{}
+
+	fields := []*string{&announce.Channel, &announce.OwnerID, &announce.Topic}
+	for _, field := range fields {
+		value, err := readShortBytes(buf)
+		if err != nil {
+			return nil, err
+		}
+		*field = string(value)
+	}
+
+	if err := binary.Read(buf, binary.BigEndian, &announce.Timestamp); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if announce.Channel == "" || announce.OwnerID == "" {
+		return nil, ErrInvalidPacket
+	}
+
+	return announce, nil
+}
+
+// ChannelFlagProtected indica um canal que exige senha para entrar
+const ChannelFlagProtected uint8 = 1 << 0
+
+// MaxChannelListEntries é o maior número de canais em um pacote
+// MessageTypeChannelList
+const MaxChannelListEntries = 64
+
+// ChannelListEntry é um canal anunciado em um pacote MessageTypeChannelList
+type ChannelListEntry struct {
+	Channel string
+	Flags   uint8 // Combinação de ChannelFlag*
+}
+
+// Protected informa se o canal exige senha
+func (e ChannelListEntry) Protected() bool {
+	return e.Flags&ChannelFlagProtected != 0
+}
+
+// EncodeChannelList serializa a lista de canais de um nó. Canais além de
+// MaxChannelListEntries são omitidos.
+func EncodeChannelList(entries []ChannelListEntry) []byte {
+	if len(entries) > MaxChannelListEntries {
+		entries = entries[:MaxChannelListEntries]
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(len(entries)))
+	for _, entry := range entries {
+		writeShortBytes(buf, []byte(entry.Channel))
+		buf.WriteByte(entry.Flags)
+	}
+	return buf.Bytes()
+}
+
+// DecodeChannelList deserializa a lista de canais de um nó
+func DecodeChannelList(data []byte) ([]ChannelListEntry, error) {
+	buf := bytes.NewReader(data)
+	count, err := buf.ReadByte()
+	if err != nil || count > MaxChannelListEntries {
+		return nil, ErrInvalidPacket
+	}
+
+	entries := make([]ChannelListEntry, 0, count)
+	for i := 0; i < int(count); i++ {
+		channel, err := readShortBytes(buf)
+		if err != nil || len(channel) == 0 {
+			return nil, ErrInvalidPacket
+		}
+		flags, err := buf.ReadByte()
+		if err != nil {
+			return nil, ErrInvalidPacket
+		}
+		entries = append(entries, ChannelListEntry{Channel: string(channel), Flags: flags})
+	}
+	if buf.Len() != 0 {
+		return nil, ErrInvalidPacket
+	}
+
+	return entries, nil
+}
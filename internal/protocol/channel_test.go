@@ -0,0 +1,99 @@
+package protocol
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestChannelAnnounceCodec(t *testing.T) {
+	t.Run("Metadados do canal", func(t *testing.T) {
+		for _, announce := range []ChannelAnnounce{
+			{Channel: "#geral", OwnerID: "dono", Topic: "Assunto do dia", Timestamp: 1700000000000},
+			{Channel: "#geral", OwnerID: "dono", Timestamp: 1700000000001}, // Tópico removido
+		} {
+			data := EncodeChannelAnnounce(&announce)
+			decoded, err := DecodeChannelAnnounce(data)
+			if err != nil {
+				t.Fatalf("Erro ao decodificar anúncio %+v: %v", announce, err)
+			}
+			if *decoded != announce {
+				t.Errorf("Anúncio esperado %+v, obtido %+v", announce, decoded)
+			}
+
+			for size := 0; size < len(data); size++ {
+				if _, err := DecodeChannelAnnounce(data[:size]); err != ErrInvalidPacket {
+					t.Fatalf("Anúncio truncado em %d bytes: esperado ErrInvalidPacket, obtido %v", size, err)
+				}
+			}
+		}
+	})
+
+	t.Run("Canal e dono obrigatórios", func(t *testing.T) {
+		for _, announce := range []ChannelAnnounce{
+			{OwnerID: "dono", Topic: "sem canal"},
+			{Channel: "#geral", Topic: "sem dono"},
+		} {
+			if _, err := DecodeChannelAnnounce(EncodeChannelAnnounce(&announce)); err != ErrInvalidPacket {
+				t.Errorf("Anúncio %+v: esperado ErrInvalidPacket, obtido %v", announce, err)
+			}
+		}
+	})
+}
+
+func TestChannelListCodec(t *testing.T) {
+	t.Run("Canais do nó", func(t *testing.T) {
+		entries := []ChannelListEntry{
+			{Channel: "#geral"},
+			{Channel: "#secreto", Flags: ChannelFlagProtected},
+		}
+
+		data := EncodeChannelList(entries)
+		decoded, err := DecodeChannelList(data)
+		if err != nil {
+			t.Fatalf("Erro ao decodificar lista: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, entries) {
+			t.Errorf("Lista esperada %+v, obtida %+v", entries, decoded)
+		}
+		if decoded[0].Protected() || !decoded[1].Protected() {
+			t.Error("Apenas #secreto deveria exigir senha")
+		}
+
+		for size := 0; size < len(data); size++ {
+			if _, err := DecodeChannelList(data[:size]); err != ErrInvalidPacket {
+				t.Fatalf("Lista truncada em %d bytes: esperado ErrInvalidPacket, obtido %v", size, err)
+			}
+		}
+		if _, err := DecodeChannelList(append(data, 0)); err != ErrInvalidPacket {
+			t.Errorf("Lista com bytes extras: esperado ErrInvalidPacket, obtido %v", err)
+		}
+	})
+
+	t.Run("Lista vazia", func(t *testing.T) {
+		decoded, err := DecodeChannelList(EncodeChannelList(nil))
+		if err != nil || len(decoded) != 0 {
+			t.Errorf("Lista vazia decodificada como %+v (%v)", decoded, err)
+		}
+	})
+
+	t.Run("Limite de canais", func(t *testing.T) {
+		entries := make([]ChannelListEntry, MaxChannelListEntries+5)
+		for i := range entries {
+			entries[i].Channel = fmt.Sprintf("#canal%d", i)
+		}
+
+		decoded, err := DecodeChannelList(EncodeChannelList(entries))
+		if err != nil {
+			t.Fatalf("Erro ao decodificar lista: %v", err)
+		}
+		if len(decoded) != MaxChannelListEntries {
+			t.Errorf("Esperados %d canais, obtidos %d", MaxChannelListEntries, len(decoded))
+		}
+
+		// Listas acima do limite são recusadas ao decodificar
+		if _, err := DecodeChannelList([]byte{MaxChannelListEntries + 1}); err != ErrInvalidPacket {
+			t.Errorf("Lista acima do limite: esperado ErrInvalidPacket, obtido %v", err)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// FileTransferIDSize é o tamanho do identificador de uma transferência
+const FileTransferIDSize = 8
+
+// FileOffer é o payload de um pacote MessageTypeFileOffer: o remetente
+// propõe um arquivo, que só é enviado depois de aceito
+type FileOffer struct {
+	TransferID [FileTransferIDSize]byte
+	Name       string   // Nome do arquivo, sem diretórios
+	Size       uint64   // Tamanho em bytes
+	ChunkSize  uint32   // Tamanho dos pedaços enviados
+	Hash       [32]byte // SHA-256 do conteúdo, conferido ao final
+}
+
+// FileAccept é o payload de um pacote MessageTypeFileAccept com a resposta
+// do destinatário a uma oferta
+type FileAccept struct {
+	TransferID [FileTransferIDSize]byte
+	Accepted   bool
+}
+
+// FileChunk é o payload de um pacote MessageTypeFileChunk com um pedaço do
+// arquivo, identificado pela posição
+type FileChunk struct {
+	TransferID [FileTransferIDSize]byte
+	Index      uint32
+	Data       []byte
+}
+
+// EncodeFileOffer serializa um FileOffer
+func EncodeFileOffer(offer *FileOffer) []byte {
+	buf := new(bytes.Buffer)
+	buf.Write(offer.TransferID[:])
+	writeShortBytes(buf, []byte(offer.Name))
+	binary.Write(buf, binary.BigEndian, offer.Size)
+	binary.Write(buf, binary.BigEndian, offer.ChunkSize)
+	buf.Write(offer.Hash[:])
+	return buf.Bytes()
+}
+
+// DecodeFileOffer deserializa um FileOffer
+func DecodeFileOffer(data []byte) (*FileOffer, error) {
+	buf := bytes.NewReader(data)
+	offer := &FileOffer{}
+
+	if _, err := io.ReadFull(buf, offer.TransferID[:]); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	name, err := readShortBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	offer.Name = string(name)
+	if err := binary.Read(buf, binary.BigEndian, &offer.Size); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if err := binary.Read(buf, binary.BigEndian, &offer.ChunkSize); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if _, err := io.ReadFull(buf, offer.Hash[:]); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if offer.Name == "" || offer.ChunkSize == 0 {
+		return nil, ErrInvalidPacket
+	}
+
+	return offer, nil
+}
+
+// EncodeFileAccept serializa um FileAccept
+func EncodeFileAccept(accept *FileAccept) []byte {
+	buf := new(bytes.Buffer)
+	buf.Write(accept.TransferID[:])
+	if accept.Accepted {
+		buf.WriteByte(1)
+	} else {
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+// DecodeFileAccept deserializa um FileAccept
+func DecodeFileAccept(data []byte) (*FileAccept, error) {
+	if len(data) != FileTransferIDSize+1 {
+		return nil, ErrInvalidPacket
+	}
+
+	accept := &FileAccept{Accepted: data[FileTransferIDSize] != 0}
+	copy(accept.TransferID[:], data)
+	return accept, nil
+}
+
+// EncodeFileChunk serializa um FileChunk
+func EncodeFileChunk(chunk *FileChunk) []byte {
+	buf := new(bytes.Buffer)
+	buf.Write(chunk.TransferID[:])
+	binary.Write(buf, binary.BigEndian, chunk.Index)
+	buf.Write(chunk.Data)
+	return buf.Bytes()
+}
+
+// DecodeFileChunk deserializa um FileChunk
+func DecodeFileChunk(data []byte) (*FileChunk, error) {
+	if len(data) < FileTransferIDSize+4 {
+		return nil, ErrInvalidPacket
+	}
+
+	chunk := &FileChunk{
+		Index: binary.BigEndian.Uint32(data[FileTransferIDSize:]),
+		Data:  data[FileTransferIDSize+4:],
+	}
+	copy(chunk.TransferID[:], data)
+	return chunk, nil
+}
@@ -0,0 +1,87 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestFileCodec(t *testing.T) {
+	transferID := [FileTransferIDSize]byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	t.Run("Oferta de arquivo", func(t *testing.T) {
+		offer := &FileOffer{
+			TransferID: transferID,
+			Name:       "relatório.pdf",
+			Size:       5 << 20,
+			ChunkSize:  180,
+			Hash:       sha256.Sum256([]byte("conteúdo")),
+		}
+
+		data := EncodeFileOffer(offer)
+		decoded, err := DecodeFileOffer(data)
+		if err != nil {
+			t.Fatalf("Erro ao decodificar oferta: %v", err)
+		}
+		if *decoded != *offer {
+			t.Errorf("Oferta esperada %+v, obtida %+v", offer, decoded)
+		}
+
+		for size := 0; size < len(data); size++ {
+			if _, err := DecodeFileOffer(data[:size]); err != ErrInvalidPacket {
+				t.Fatalf("Oferta truncada em %d bytes: esperado ErrInvalidPacket, obtido %v", size, err)
+			}
+		}
+
+		for _, invalid := range []FileOffer{
+			{TransferID: transferID, ChunkSize: 180},
+			{TransferID: transferID, Name: "vazio.txt"},
+		} {
+			if _, err := DecodeFileOffer(EncodeFileOffer(&invalid)); err != ErrInvalidPacket {
+				t.Errorf("Oferta sem nome ou tamanho de pedaço: esperado ErrInvalidPacket, obtido %v", err)
+			}
+		}
+	})
+
+	t.Run("Resposta à oferta", func(t *testing.T) {
+		for _, accepted := range []bool{true, false} {
+			accept := &FileAccept{TransferID: transferID, Accepted: accepted}
+
+			data := EncodeFileAccept(accept)
+			decoded, err := DecodeFileAccept(data)
+			if err != nil {
+				t.Fatalf("Erro ao decodificar resposta: %v", err)
+			}
+			if *decoded != *accept {
+				t.Errorf("Resposta esperada %+v, obtida %+v", accept, decoded)
+			}
+
+			if _, err := DecodeFileAccept(data[:len(data)-1]); err != ErrInvalidPacket {
+				t.Errorf("Resposta truncada: esperado ErrInvalidPacket, obtido %v", err)
+			}
+			if _, err := DecodeFileAccept(append(data, 0)); err != ErrInvalidPacket {
+				t.Errorf("Resposta com bytes extras: esperado ErrInvalidPacket, obtido %v", err)
+			}
+		}
+	})
+
+	t.Run("Pedaço do arquivo", func(t *testing.T) {
+		for _, chunk := range []FileChunk{
+			{TransferID: transferID, Index: 41, Data: []byte("dados do pedaço")},
+			{TransferID: transferID, Index: 0, Data: []byte{}}, // Arquivo vazio
+		} {
+			data := EncodeFileChunk(&chunk)
+			decoded, err := DecodeFileChunk(data)
+			if err != nil {
+				t.Fatalf("Erro ao decodificar pedaço: %v", err)
+			}
+			if decoded.TransferID != chunk.TransferID || decoded.Index != chunk.Index || !bytes.Equal(decoded.Data, chunk.Data) {
+				t.Errorf("Pedaço esperado %+v, obtido %+v", chunk, decoded)
+			}
+		}
+
+		if _, err := DecodeFileChunk(make([]byte, FileTransferIDSize+3)); err != ErrInvalidPacket {
+			t.Errorf("Pedaço truncado: esperado ErrInvalidPacket, obtido %v", err)
+		}
+	})
+}
@@ -1,7 +1,6 @@
 package protocol
 
 import (
-	"encoding/binary"
 	"fmt"
 	"math/rand"
 	"time"
@@ -111,135 +110,6 @@ func DecodeFragment(data []byte) (string, int, int, []byte, error) {
 	return packetID, fragmentIndex, totalFragments, fragmentData, nil
 }
 
-// EncodePacket codifica um BitchatPacket em bytes para transmissão
-func EncodePacket(packet *BitchatPacket) ([]byte, error) {
-	// Calcular tamanho total
-	totalSize := 1 + // Versão
-		1 + // Tipo
-		8 + // SenderID (assumindo tamanho fixo)
-		8 + // RecipientID (assumindo tamanho fixo)
-		8 + // Timestamp
-		1 + // TTL
-		4 + // Tamanho do payload
-		len(packet.Payload) +
-		len(packet.Signature)
-	
-	result := make([]byte, totalSize)
-	offset := 0
-	
-	// Versão
-	result[offset] = packet.Version
-	offset++
-	
-	// Tipo
-	result[offset] = byte(packet.Type)
-	offset++
-	
-	// SenderID
-	copy(result[offset:offset+8], packet.SenderID)
-	offset += 8
-	
-	// RecipientID
-	copy(result[offset:offset+8], packet.RecipientID)
-	offset += 8
-	
-	// Timestamp
-	binary.BigEndian.PutUint64(result[offset:offset+8], packet.Timestamp)
-	offset += 8
-	
-	// TTL
-	result[offset] = packet.TTL
-	offset++
-	
-	// Tamanho do payload
-	binary.BigEndian.PutUint32(result[offset:offset+4], uint32(len(packet.Payload)))
-	offset += 4
-	
-	// Payload
-	copy(result[offset:offset+len(packet.Payload)], packet.Payload)
-	offset += len(packet.Payload)
-	
-	// Signature (se presente)
-	if len(packet.Signature) > 0 {
-		copy(result[offset:], packet.Signature)
-	}
-	
-	return result, nil
-}
-
-// DecodePacket decodifica bytes em um BitchatPacket
-func DecodePacket(data []byte) (*BitchatPacket, error) {
-	// Verificar tamanho mínimo
-	if len(data) < 31 { // 1+1+8+8+8+1+4
-		return nil, fmt.Errorf("dados muito curtos para um pacote válido")
-	}
-	
-	offset := 0
-	
-	// Versão
-	version := data[offset]
-	offset++
-	
-	// Tipo
-	msgType := MessageType(data[offset])
-	offset++
-	
-	// SenderID
-	senderID := make([]byte, 8)
-	copy(senderID, data[offset:offset+8])
-	offset += 8
-	
-	// RecipientID
-	recipientID := make([]byte, 8)
-	copy(recipientID, data[offset:offset+8])
-	offset += 8
-	
-	// Timestamp
-	timestamp := binary.BigEndian.Uint64(data[offset:offset+8])
-	offset += 8
-	
-	// TTL
-	ttl := data[offset]
-	offset++
-	
-	// Tamanho do payload
-	payloadSize := binary.BigEndian.Uint32(data[offset:offset+4])
-	offset += 4
-	
-	// Verificar se há dados suficientes para o payload
-	if uint32(len(data)-offset) < payloadSize {
-		return nil, fmt.Errorf("dados insuficientes para o payload declarado")
-	}
-	
-	// Payload
-	payload := make([]byte, payloadSize)
-	copy(payload, data[offset:offset+int(payloadSize)])
-	offset += int(payloadSize)
-	
-	// Signature (resto dos dados)
-	signature := make([]byte, len(data)-offset)
-	if len(signature) > 0 {
-		copy(signature, data[offset:])
-	}
-	
-	// Criar pacote
-	packet := &BitchatPacket{
-		Version:    version,
-		Type:       msgType,
-		SenderID:   senderID,
-		RecipientID: recipientID,
-		Timestamp:  timestamp,
-		Payload:    payload,
-		Signature:  signature,
-		TTL:        ttl,
-	}
-	
-	// Gerar ID
-	packet.ID = generatePacketID(packet)
-	
-	return packet, nil
-}
-
 // ExtractMetadataFromServiceData extrai metadados de fragmentação dos dados de serviço BLE
 func ExtractMetadataFromServiceData(serviceData []byte) *FragmentMeta {
 	// Verificar tamanho mínimo
@@ -329,32 +199,6 @@ func IsFragment(msgType MessageType) bool {
 		msgType == MessageTypeFragmentEnd
 }
 
-// ReassembleFragments reconstrói um pacote a partir de fragmentos
-func ReassembleFragments(fragments map[int][]byte, totalFragments int) ([]byte, error) {
-	// Verificar se todos os fragmentos estão presentes
-	if len(fragments) != totalFragments {
-		return nil, fmt.Errorf("fragmentos incompletos: %d/%d", len(fragments), totalFragments)
-	}
-	
-	// Calcular tamanho total
-	totalSize := 0
-	for _, data := range fragments {
-		totalSize += len(data)
-	}
-	
-	// Concatenar fragmentos na ordem correta
-	result := make([]byte, 0, totalSize)
-	for i := 0; i < totalFragments; i++ {
-		data, ok := fragments[i]
-		if !ok {
-			return nil, fmt.Errorf("fragmento %d ausente", i)
-		}
-		result = append(result, data...)
-	}
-	
-	return result, nil
-}
-
 // GenerateCoverTrafficPacket gera um pacote de tráfego de cobertura
 func GenerateCoverTrafficPacket() *BitchatPacket {
 	// Gerar ID aleatório para o remetente
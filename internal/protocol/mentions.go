@@ -0,0 +1,45 @@
+package protocol
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ParseMentions extrai os apelidos mencionados com @ no conteúdo de uma
+// mensagem, sem repetições e na ordem em que aparecem. A pontuação em volta
+// de uma menção ("(@ana)" ou "@ana!") não faz parte do apelido; um @ no meio
+// de uma palavra, como em um e-mail, não é menção.
+func ParseMentions(content string) []string {
+	var mentions []string
+	seen := make(map[string]bool)
+
+	for _, word := range strings.Fields(content) {
+		word = strings.TrimLeftFunc(word, func(r rune) bool {
+			return unicode.IsPunct(r) && r != '@'
+		})
+		if !strings.HasPrefix(word, "@") {
+			continue
+		}
+		name := strings.TrimRightFunc(word[1:], func(r rune) bool {
+			return unicode.IsPunct(r) && r != '_' && r != '-'
+		})
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		mentions = append(mentions, name)
+	}
+
+	return mentions
+}
+
+// MentionsNickname verifica se a mensagem menciona um apelido, sem
+// diferenciar maiúsculas de minúsculas
+func (m *BitchatMessage) MentionsNickname(nickname string) bool {
+	for _, mention := range m.Mentions {
+		if strings.EqualFold(mention, nickname) {
+			return true
+		}
+	}
+	return false
+}
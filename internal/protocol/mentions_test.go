@@ -0,0 +1,29 @@
+package protocol
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{"Sem menções", "oi a todos", nil},
+		{"Ordem de aparição", "@bia e @ana", []string{"bia", "ana"}},
+		{"Repetidas em outra caixa", "@ana @Ana @bia @ana", []string{"ana", "bia"}},
+		{"Pontuação em volta", `("@ana"), @bia!`, []string{"ana", "bia"}},
+		{"Sublinhado e hífen fazem parte do apelido", "@ana_b @bia-c.", []string{"ana_b", "bia-c"}},
+		{"Arroba no meio da palavra", "ana@exemplo.org @", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if mentions := ParseMentions(tc.content); !reflect.DeepEqual(mentions, tc.expected) {
+				t.Errorf("Menções esperadas %q, obtidas %q", tc.expected, mentions)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package protocol
+
+import "sync"
+
+const (
+	// pooledBufferSize é a capacidade inicial dos buffers do pool, suficiente
+	// para um pacote que cabe em um enlace BLE
+	pooledBufferSize = 512
+
+	// MaxPooledBufferSize é a maior capacidade de um buffer devolvido ao
+	// pool; buffers maiores são descartados para não reter memória
+	MaxPooledBufferSize = 64 << 10
+)
+
+// bufferPool guarda buffers de codificação reutilizáveis
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, pooledBufferSize)
+		return &buf
+	},
+}
+
+// GetBuffer retorna um buffer vazio para AppendEncode. Nós que retransmitem
+// muitos pacotes evitam uma alocação por pacote devolvendo-o com PutBuffer
+// assim que os dados codificados não forem mais usados.
+func GetBuffer() *[]byte {
+	buf := bufferPool.Get().(*[]byte)
+	*buf = (*buf)[:0]
+	return buf
+}
+
+// PutBuffer devolve ao pool um buffer obtido com GetBuffer
+func PutBuffer(buf *[]byte) {
+	if cap(*buf) > MaxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
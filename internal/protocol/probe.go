@@ -0,0 +1,39 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// LinkProbe é o payload dos pacotes MessageTypeLinkProbe e MessageTypeLinkProbeReply.
+// A sonda é enviada somente a vizinhos diretos; a resposta ecoa o payload para
+// que o remetente calcule o tempo de ida e volta.
+type LinkProbe struct {
+	Sequence  uint32 // Número de sequência, incrementado a cada sonda enviada
+	Timestamp uint64 // Momento do envio em milissegundos desde a época Unix
+}
+
+// EncodeLinkProbe serializa um LinkProbe
+func EncodeLinkProbe(probe *LinkProbe) []byte {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, probe.Sequence)
+	binary.Write(buf, binary.BigEndian, probe.Timestamp)
+
+	return buf.Bytes()
+}
+
+// DecodeLinkProbe deserializa um LinkProbe
+func DecodeLinkProbe(data []byte) (*LinkProbe, error) {
+	buf := bytes.NewReader(data)
+	probe := &LinkProbe{}
+
+	if err := binary.Read(buf, binary.BigEndian, &probe.Sequence); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if err := binary.Read(buf, binary.BigEndian, &probe.Timestamp); err != nil {
+		return nil, ErrInvalidPacket
+	}
+
+	return probe, nil
+}
@@ -0,0 +1,26 @@
+package protocol
+
+import "testing"
+
+func TestLinkProbeCodec(t *testing.T) {
+	probe := &LinkProbe{Sequence: 0xDEADBEEF, Timestamp: 1700000000123}
+
+	data := EncodeLinkProbe(probe)
+	if len(data) != 12 {
+		t.Errorf("Sonda deveria ocupar 12 bytes, ocupa %d", len(data))
+	}
+
+	decoded, err := DecodeLinkProbe(data)
+	if err != nil {
+		t.Fatalf("Erro ao decodificar sonda: %v", err)
+	}
+	if *decoded != *probe {
+		t.Errorf("Sonda esperada %+v, obtida %+v", probe, decoded)
+	}
+
+	for size := 0; size < len(data); size++ {
+		if _, err := DecodeLinkProbe(data[:size]); err != ErrInvalidPacket {
+			t.Fatalf("Sonda truncada em %d bytes: esperado ErrInvalidPacket, obtido %v", size, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// RouteRequest é o payload de um pacote MessageTypeRouteRequest.
+// É propagado por flooding até alcançar o destino ou um nó que conheça uma rota.
+type RouteRequest struct {
+	RequestID   uint32 // ID da solicitação, único por originador
+	OriginID    []byte // Nó que iniciou a descoberta
+	TargetID    []byte // Nó procurado
+	HopCount    uint8  // Saltos percorridos até o momento
+	ForwarderID []byte // Último nó que retransmitiu a solicitação
+}
+
+// RouteReply é o payload de um pacote MessageTypeRouteReply.
+// Percorre a rota reversa, salto a salto, de volta ao originador.
+type RouteReply struct {
+	RequestID   uint32 // ID da solicitação respondida
+	OriginID    []byte // Nó que iniciou a descoberta
+	TargetID    []byte // Nó procurado
+	HopCount    uint8  // Saltos entre quem responde e o nó atual
+	ForwarderID []byte // Último nó que retransmitiu a resposta
+	NextHopID   []byte // Nó que deve retransmitir a resposta a seguir
+}
+
+// EncodeRouteRequest serializa um RouteRequest
+func EncodeRouteRequest(req *RouteRequest) []byte {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, req.RequestID)
+	writeShortBytes(buf, req.OriginID)
+	writeShortBytes(buf, req.TargetID)
+	buf.WriteByte(req.HopCount)
+	writeShortBytes(buf, req.ForwarderID)
+
+	return buf.Bytes()
+}
+
+// DecodeRouteRequest deserializa um RouteRequest
+func DecodeRouteRequest(data []byte) (*RouteRequest, error) {
+	buf := bytes.NewReader(data)
+	req := &RouteRequest{}
+	var err error
+
+	if err = binary.Read(buf, binary.BigEndian, &req.RequestID); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if req.OriginID, err = readShortBytes(buf); err != nil {
+		return nil, err
+	}
+	if req.TargetID, err = readShortBytes(buf); err != nil {
+		return nil, err
+	}
+	if req.HopCount, err = buf.ReadByte(); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if req.ForwarderID, err = readShortBytes(buf); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// EncodeRouteReply serializa um RouteReply
+func EncodeRouteReply(rep *RouteReply) []byte {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, rep.RequestID)
+	writeShortBytes(buf, rep.OriginID)
+	writeShortBytes(buf, rep.TargetID)
+	buf.WriteByte(rep.HopCount)
+	writeShortBytes(buf, rep.ForwarderID)
+	writeShortBytes(buf, rep.NextHopID)
+
+	return buf.Bytes()
+}
+
+// DecodeRouteReply deserializa um RouteReply
+func DecodeRouteReply(data []byte) (*RouteReply, error) {
+	buf := bytes.NewReader(data)
+	rep := &RouteReply{}
+	var err error
+
+	if err = binary.Read(buf, binary.BigEndian, &rep.RequestID); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if rep.OriginID, err = readShortBytes(buf); err != nil {
+		return nil, err
+	}
+	if rep.TargetID, err = readShortBytes(buf); err != nil {
+		return nil, err
+	}
+	if rep.HopCount, err = buf.ReadByte(); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if rep.ForwarderID, err = readShortBytes(buf); err != nil {
+		return nil, err
+	}
+	if rep.NextHopID, err = readShortBytes(buf); err != nil {
+		return nil, err
+	}
+
+	return rep, nil
+}
+
+// writeShortBytes escreve um campo de até 255 bytes prefixado pelo tamanho
+func writeShortBytes(buf *bytes.Buffer, data []byte) {
+	if len(data) > 255 {
+		data = data[:255]
+	}
+	buf.WriteByte(byte(len(data)))
+	buf.Write(data)
+}
+
+// readShortBytes lê um campo escrito por writeShortBytes
+func readShortBytes(buf *bytes.Reader) ([]byte, error) {
+	length, err := buf.ReadByte()
+	if err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if length == 0 {
+		return nil, nil
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(buf, data); err != nil {
+		return nil, ErrInvalidPacket
+	}
+
+	return data, nil
+}
@@ -0,0 +1,76 @@
+package protocol
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestRouteCodec(t *testing.T) {
+	t.Run("Solicitação de rota", func(t *testing.T) {
+		req := &RouteRequest{
+			RequestID:   42,
+			OriginID:    []byte("origem"),
+			TargetID:    []byte("destino"),
+			HopCount:    3,
+			ForwarderID: []byte("relay"),
+		}
+
+		data := EncodeRouteRequest(req)
+		decoded, err := DecodeRouteRequest(data)
+		if err != nil {
+			t.Fatalf("Erro ao decodificar solicitação: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, req) {
+			t.Errorf("Solicitação esperada %+v, obtida %+v", req, decoded)
+		}
+
+		for size := 0; size < len(data); size++ {
+			if _, err := DecodeRouteRequest(data[:size]); err != ErrInvalidPacket {
+				t.Fatalf("Solicitação truncada em %d bytes: esperado ErrInvalidPacket, obtido %v", size, err)
+			}
+		}
+	})
+
+	t.Run("Resposta de rota", func(t *testing.T) {
+		rep := &RouteReply{
+			RequestID:   7,
+			OriginID:    []byte("origem"),
+			TargetID:    []byte("destino"),
+			HopCount:    1,
+			ForwarderID: []byte("destino"),
+			NextHopID:   []byte("relay"),
+		}
+
+		data := EncodeRouteReply(rep)
+		decoded, err := DecodeRouteReply(data)
+		if err != nil {
+			t.Fatalf("Erro ao decodificar resposta: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, rep) {
+			t.Errorf("Resposta esperada %+v, obtida %+v", rep, decoded)
+		}
+
+		for size := 0; size < len(data); size++ {
+			if _, err := DecodeRouteReply(data[:size]); err != ErrInvalidPacket {
+				t.Fatalf("Resposta truncada em %d bytes: esperado ErrInvalidPacket, obtido %v", size, err)
+			}
+		}
+	})
+
+	t.Run("Campos vazios e longos", func(t *testing.T) {
+		long := bytes.Repeat([]byte("x"), 300)
+		req := &RouteRequest{RequestID: 1, TargetID: long}
+
+		decoded, err := DecodeRouteRequest(EncodeRouteRequest(req))
+		if err != nil {
+			t.Fatalf("Erro ao decodificar solicitação: %v", err)
+		}
+		if decoded.OriginID != nil || decoded.ForwarderID != nil {
+			t.Errorf("Campos vazios deveriam ser nil: %v/%v", decoded.OriginID, decoded.ForwarderID)
+		}
+		if !bytes.Equal(decoded.TargetID, long[:255]) {
+			t.Errorf("Campo longo deveria ser limitado a 255 bytes, obtido %d", len(decoded.TargetID))
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// SleepSchedule é o payload de um pacote MessageTypeSleepSchedule.
+// Anuncia aos vizinhos diretos quando o rádio deste nó está ativo: em cada
+// período de Period milissegundos, o nó fica acordado durante Wake
+// milissegundos a partir de Phase (contado desde a época Unix, módulo Period).
+// Wake igual a Period indica um nó sempre acordado.
+type SleepSchedule struct {
+	Period uint32 // Duração do ciclo em milissegundos
+	Wake   uint32 // Duração da janela de atividade em milissegundos
+	Phase  uint32 // Início da janela de atividade dentro do ciclo em milissegundos
+}
+
+// sleepScheduleSize é o tamanho serializado de um SleepSchedule
+const sleepScheduleSize = 12
+
+// EncodeSleepSchedule serializa um SleepSchedule
+func EncodeSleepSchedule(schedule *SleepSchedule) []byte {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, schedule.Period)
+	binary.Write(buf, binary.BigEndian, schedule.Wake)
+	binary.Write(buf, binary.BigEndian, schedule.Phase)
+	return buf.Bytes()
+}
+
+// DecodeSleepSchedule deserializa um SleepSchedule
+func DecodeSleepSchedule(data []byte) (*SleepSchedule, error) {
+	if len(data) < sleepScheduleSize {
+		return nil, ErrInvalidPacket
+	}
+
+	schedule := &SleepSchedule{
+		Period: binary.BigEndian.Uint32(data[0:4]),
+		Wake:   binary.BigEndian.Uint32(data[4:8]),
+		Phase:  binary.BigEndian.Uint32(data[8:12]),
+	}
+	if schedule.Period == 0 || schedule.Wake == 0 || schedule.Wake > schedule.Period || schedule.Phase >= schedule.Period {
+		return nil, ErrInvalidPacket
+	}
+
+	return schedule, nil
+}
@@ -0,0 +1,42 @@
+package protocol
+
+import "testing"
+
+func TestSleepScheduleCodec(t *testing.T) {
+	t.Run("Janela de atividade", func(t *testing.T) {
+		for _, schedule := range []SleepSchedule{
+			{Period: 10000, Wake: 2000, Phase: 7500},
+			{Period: 5000, Wake: 5000, Phase: 0}, // Sempre acordado
+		} {
+			data := EncodeSleepSchedule(&schedule)
+			if len(data) != sleepScheduleSize {
+				t.Errorf("Agenda deveria ocupar %d bytes, ocupa %d", sleepScheduleSize, len(data))
+			}
+
+			decoded, err := DecodeSleepSchedule(data)
+			if err != nil {
+				t.Fatalf("Erro ao decodificar agenda %+v: %v", schedule, err)
+			}
+			if *decoded != schedule {
+				t.Errorf("Agenda esperada %+v, obtida %+v", schedule, decoded)
+			}
+
+			if _, err := DecodeSleepSchedule(data[:sleepScheduleSize-1]); err != ErrInvalidPacket {
+				t.Errorf("Agenda truncada: esperado ErrInvalidPacket, obtido %v", err)
+			}
+		}
+	})
+
+	t.Run("Agendas inconsistentes", func(t *testing.T) {
+		for _, schedule := range []SleepSchedule{
+			{Period: 0, Wake: 0, Phase: 0},
+			{Period: 1000, Wake: 0, Phase: 0},
+			{Period: 1000, Wake: 1001, Phase: 0},
+			{Period: 1000, Wake: 500, Phase: 1000},
+		} {
+			if _, err := DecodeSleepSchedule(EncodeSleepSchedule(&schedule)); err != ErrInvalidPacket {
+				t.Errorf("Agenda %+v: esperado ErrInvalidPacket, obtido %v", schedule, err)
+			}
+		}
+	})
+}
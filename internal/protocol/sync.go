@@ -0,0 +1,183 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// MaxSyncDigests é o número máximo de resumos em um SyncRequest
+const MaxSyncDigests = 0xFFFF
+
+// MaxDigestRanges é o número máximo de faixas em um SyncRequest ou SyncSummary
+const MaxDigestRanges = 0xFF
+
+// DigestRange descreve uma faixa contínua [Start, End] do espaço de resumos de
+// mensagens, com a quantidade e a impressão digital (XOR) dos resumos nela
+type DigestRange struct {
+	Start       uint64
+	End         uint64
+	Count       uint16
+	Fingerprint uint64
+}
+
+// SyncRequest é o payload de um pacote MessageTypeSyncRequest.
+// O remetente informa os resumos das mensagens que já possui desde Since;
+// o destinatário responde retransmitindo as mensagens recentes que faltam.
+// Se Ranges não for vazio, apenas mensagens nessas faixas são consideradas.
+// MaxBytes, se não for zero, limita os bytes reenviados em resposta.
+type SyncRequest struct {
+	Since    uint64        // Início da janela em milissegundos desde a época Unix
+	Digests  []uint64      // Resumos das mensagens conhecidas pelo remetente
+	Ranges   []DigestRange // Faixas consideradas (Count e Fingerprint ignorados)
+	MaxBytes uint32        // Orçamento de dados pedido pelo remetente
+}
+
+// SyncSummary é o payload de um pacote MessageTypeSyncSummary.
+// Em uma rodada de anti-entropia o iniciador envia as faixas de seus resumos;
+// o vizinho responde (Reply) apenas com as faixas em que diverge.
+type SyncSummary struct {
+	Since  uint64
+	Reply  bool
+	Ranges []DigestRange
+}
+
+// EncodeSyncRequest serializa um SyncRequest
+func EncodeSyncRequest(req *SyncRequest) []byte {
+	buf := new(bytes.Buffer)
+
+	digests := req.Digests
+	if len(digests) > MaxSyncDigests {
+		digests = digests[:MaxSyncDigests]
+	}
+
+	binary.Write(buf, binary.BigEndian, req.Since)
+	binary.Write(buf, binary.BigEndian, uint16(len(digests)))
+	for _, digest := range digests {
+		binary.Write(buf, binary.BigEndian, digest)
+	}
+
+	// Faixas e orçamento são opcionais para compatibilidade com solicitações
+	// sem restrição; o orçamento vem depois das faixas, mesmo que não haja
+	// nenhuma
+	if len(req.Ranges) > 0 || req.MaxBytes > 0 {
+		ranges := limitRanges(req.Ranges)
+		buf.WriteByte(uint8(len(ranges)))
+		for _, r := range ranges {
+			binary.Write(buf, binary.BigEndian, r.Start)
+			binary.Write(buf, binary.BigEndian, r.End)
+		}
+	}
+	if req.MaxBytes > 0 {
+		binary.Write(buf, binary.BigEndian, req.MaxBytes)
+	}
+
+	return buf.Bytes()
+}
+
+// DecodeSyncRequest deserializa um SyncRequest
+func DecodeSyncRequest(data []byte) (*SyncRequest, error) {
+	buf := bytes.NewReader(data)
+	req := &SyncRequest{}
+
+	if err := binary.Read(buf, binary.BigEndian, &req.Since); err != nil {
+		return nil, ErrInvalidPacket
+	}
+
+	var count uint16
+	if err := binary.Read(buf, binary.BigEndian, &count); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if buf.Len() < int(count)*8 {
+		return nil, ErrInvalidPacket
+	}
+
+	req.Digests = make([]uint64, count)
+	for i := range req.Digests {
+		binary.Read(buf, binary.BigEndian, &req.Digests[i])
+	}
+
+	if buf.Len() == 0 {
+		return req, nil
+	}
+
+	rangeCount, _ := buf.ReadByte()
+	if buf.Len() < int(rangeCount)*16 {
+		return nil, ErrInvalidPacket
+	}
+	if rangeCount > 0 {
+		req.Ranges = make([]DigestRange, rangeCount)
+	}
+	for i := range req.Ranges {
+		binary.Read(buf, binary.BigEndian, &req.Ranges[i].Start)
+		binary.Read(buf, binary.BigEndian, &req.Ranges[i].End)
+	}
+
+	if buf.Len() >= 4 {
+		binary.Read(buf, binary.BigEndian, &req.MaxBytes)
+	}
+
+	return req, nil
+}
+
+// EncodeSyncSummary serializa um SyncSummary
+func EncodeSyncSummary(summary *SyncSummary) []byte {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, summary.Since)
+	if summary.Reply {
+		buf.WriteByte(1)
+	} else {
+		buf.WriteByte(0)
+	}
+
+	ranges := limitRanges(summary.Ranges)
+	buf.WriteByte(uint8(len(ranges)))
+	for _, r := range ranges {
+		binary.Write(buf, binary.BigEndian, r.Start)
+		binary.Write(buf, binary.BigEndian, r.End)
+		binary.Write(buf, binary.BigEndian, r.Count)
+		binary.Write(buf, binary.BigEndian, r.Fingerprint)
+	}
+
+	return buf.Bytes()
+}
+
+// DecodeSyncSummary deserializa um SyncSummary
+func DecodeSyncSummary(data []byte) (*SyncSummary, error) {
+	buf := bytes.NewReader(data)
+	summary := &SyncSummary{}
+
+	if err := binary.Read(buf, binary.BigEndian, &summary.Since); err != nil {
+		return nil, ErrInvalidPacket
+	}
+
+	reply, err := buf.ReadByte()
+	if err != nil {
+		return nil, ErrInvalidPacket
+	}
+	summary.Reply = reply != 0
+
+	count, err := buf.ReadByte()
+	if err != nil || buf.Len() < int(count)*26 {
+		return nil, ErrInvalidPacket
+	}
+
+	summary.Ranges = make([]DigestRange, count)
+	for i := range summary.Ranges {
+		r := &summary.Ranges[i]
+		binary.Read(buf, binary.BigEndian, &r.Start)
+		binary.Read(buf, binary.BigEndian, &r.End)
+		binary.Read(buf, binary.BigEndian, &r.Count)
+		binary.Read(buf, binary.BigEndian, &r.Fingerprint)
+	}
+
+	return summary, nil
+}
+
+// limitRanges aplica o limite de faixas por pacote
+func limitRanges(ranges []DigestRange) []DigestRange {
+	if len(ranges) > MaxDigestRanges {
+		return ranges[:MaxDigestRanges]
+	}
+	return ranges
+}
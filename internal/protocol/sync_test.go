@@ -0,0 +1,142 @@
+package protocol
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSyncRequestCodec(t *testing.T) {
+	t.Run("Resumos das mensagens conhecidas", func(t *testing.T) {
+		req := &SyncRequest{Since: 1700000000000, Digests: []uint64{1, 0xFFFFFFFFFFFFFFFF, 42}}
+
+		data := EncodeSyncRequest(req)
+		if len(data) != 8+2+3*8 {
+			t.Errorf("Solicitação sem faixas deveria ocupar %d bytes, ocupa %d", 8+2+3*8, len(data))
+		}
+
+		decoded, err := DecodeSyncRequest(data)
+		if err != nil {
+			t.Fatalf("Erro ao decodificar solicitação: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, req) {
+			t.Errorf("Solicitação esperada %+v, obtida %+v", req, decoded)
+		}
+
+		for size := 0; size < len(data); size++ {
+			if _, err := DecodeSyncRequest(data[:size]); err != ErrInvalidPacket {
+				t.Fatalf("Solicitação truncada em %d bytes: esperado ErrInvalidPacket, obtido %v", size, err)
+			}
+		}
+	})
+
+	t.Run("Sem resumos", func(t *testing.T) {
+		decoded, err := DecodeSyncRequest(EncodeSyncRequest(&SyncRequest{Since: 5}))
+		if err != nil {
+			t.Fatalf("Erro ao decodificar solicitação: %v", err)
+		}
+		if decoded.Since != 5 || len(decoded.Digests) != 0 {
+			t.Errorf("Solicitação vazia decodificada como %+v", decoded)
+		}
+	})
+}
+
+func TestSyncRangesCodec(t *testing.T) {
+	ranges := []DigestRange{
+		{Start: 0, End: 1<<63 - 1, Count: 12, Fingerprint: 0xABCDEF},
+		{Start: 1 << 63, End: 0xFFFFFFFFFFFFFFFF, Count: 0, Fingerprint: 0},
+	}
+
+	t.Run("Solicitação restrita a faixas", func(t *testing.T) {
+		req := &SyncRequest{Since: 10, Digests: []uint64{7}, Ranges: ranges}
+
+		decoded, err := DecodeSyncRequest(EncodeSyncRequest(req))
+		if err != nil {
+			t.Fatalf("Erro ao decodificar solicitação: %v", err)
+		}
+		if len(decoded.Ranges) != len(ranges) {
+			t.Fatalf("Esperadas %d faixas, obtidas %d", len(ranges), len(decoded.Ranges))
+		}
+
+		// A solicitação leva apenas os limites das faixas
+		for i, r := range decoded.Ranges {
+			if r.Start != ranges[i].Start || r.End != ranges[i].End || r.Count != 0 || r.Fingerprint != 0 {
+				t.Errorf("Faixa %d esperada [%d, %d], obtida %+v", i, ranges[i].Start, ranges[i].End, r)
+			}
+		}
+	})
+
+	t.Run("Resumo por faixas", func(t *testing.T) {
+		for _, reply := range []bool{false, true} {
+			summary := &SyncSummary{Since: 10, Reply: reply, Ranges: ranges}
+
+			data := EncodeSyncSummary(summary)
+			decoded, err := DecodeSyncSummary(data)
+			if err != nil {
+				t.Fatalf("Erro ao decodificar resumo: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, summary) {
+				t.Errorf("Resumo esperado %+v, obtido %+v", summary, decoded)
+			}
+
+			for size := 0; size < len(data); size++ {
+				if _, err := DecodeSyncSummary(data[:size]); err != ErrInvalidPacket {
+					t.Fatalf("Resumo truncado em %d bytes: esperado ErrInvalidPacket, obtido %v", size, err)
+				}
+			}
+		}
+	})
+
+	t.Run("Limite de faixas", func(t *testing.T) {
+		summary := &SyncSummary{Ranges: make([]DigestRange, MaxDigestRanges+1)}
+		decoded, err := DecodeSyncSummary(EncodeSyncSummary(summary))
+		if err != nil {
+			t.Fatalf("Erro ao decodificar resumo: %v", err)
+		}
+		if len(decoded.Ranges) != MaxDigestRanges {
+			t.Errorf("Esperadas %d faixas, obtidas %d", MaxDigestRanges, len(decoded.Ranges))
+		}
+	})
+}
+
+func TestSyncBudgetCodec(t *testing.T) {
+	t.Run("Orçamento sem faixas", func(t *testing.T) {
+		req := &SyncRequest{Since: 10, Digests: []uint64{7}, MaxBytes: 4096}
+
+		decoded, err := DecodeSyncRequest(EncodeSyncRequest(req))
+		if err != nil {
+			t.Fatalf("Erro ao decodificar solicitação: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, req) {
+			t.Errorf("Solicitação esperada %+v, obtida %+v", req, decoded)
+		}
+	})
+
+	t.Run("Orçamento com faixas", func(t *testing.T) {
+		req := &SyncRequest{
+			Since:    10,
+			Digests:  []uint64{7},
+			Ranges:   []DigestRange{{Start: 1, End: 2}},
+			MaxBytes: 1 << 20,
+		}
+
+		decoded, err := DecodeSyncRequest(EncodeSyncRequest(req))
+		if err != nil {
+			t.Fatalf("Erro ao decodificar solicitação: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, req) {
+			t.Errorf("Solicitação esperada %+v, obtida %+v", req, decoded)
+		}
+	})
+
+	t.Run("Solicitação anterior sem orçamento", func(t *testing.T) {
+		req := &SyncRequest{Since: 10, Ranges: []DigestRange{{Start: 1, End: 2}}}
+
+		decoded, err := DecodeSyncRequest(EncodeSyncRequest(req))
+		if err != nil {
+			t.Fatalf("Erro ao decodificar solicitação: %v", err)
+		}
+		if decoded.MaxBytes != 0 {
+			t.Errorf("Solicitação sem orçamento decodificada com %d bytes", decoded.MaxBytes)
+		}
+	})
+}
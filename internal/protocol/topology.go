@@ -0,0 +1,125 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// MaxAnnouncedNeighbors limita o número de vizinhos em um anúncio de topologia
+const MaxAnnouncedNeighbors = 255
+
+// NeighborInfo descreve um vizinho direto anunciado por um nó
+type NeighborInfo struct {
+	PeerID  []byte // ID do vizinho
+	Quality uint8  // Qualidade do enlace (0-100)
+}
+
+// TopologyAnnounce é o payload de um pacote MessageTypeTopologyAnnounce.
+// Cada nó anuncia periodicamente seus vizinhos diretos para que os demais
+// possam montar o mapa da rede.
+type TopologyAnnounce struct {
+	Neighbors []NeighborInfo
+}
+
+// Capacidades anunciadas em Hello.Flags
+const (
+	HelloFlagNoRelay      uint8 = 0x01 // O nó não se dispõe a retransmitir pacotes alheios
+	HelloFlagMainsPowered uint8 = 0x02 // O nó está ligado à rede elétrica
+)
+
+// Hello é o payload de um pacote MessageTypeHello.
+// É trocado apenas entre vizinhos diretos (TTL 1) e carrega a lista de
+// vizinhos de quem o envia, permitindo aprender rotas de dois saltos.
+type Hello struct {
+	Sequence  uint32 // Número de sequência, incrementado a cada hello
+	Neighbors []NeighborInfo
+	Flags     uint8 // Capacidades do nó (opcional; ausente em nós antigos)
+}
+
+// EncodeTopologyAnnounce serializa um TopologyAnnounce
+func EncodeTopologyAnnounce(announce *TopologyAnnounce) []byte {
+	buf := new(bytes.Buffer)
+	writeNeighbors(buf, announce.Neighbors)
+	return buf.Bytes()
+}
+
+// DecodeTopologyAnnounce deserializa um TopologyAnnounce
+func DecodeTopologyAnnounce(data []byte) (*TopologyAnnounce, error) {
+	neighbors, err := readNeighbors(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return &TopologyAnnounce{Neighbors: neighbors}, nil
+}
+
+// EncodeHello serializa um Hello
+func EncodeHello(hello *Hello) []byte {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, hello.Sequence)
+	writeNeighbors(buf, hello.Neighbors)
+	buf.WriteByte(hello.Flags)
+	return buf.Bytes()
+}
+
+// DecodeHello deserializa um Hello
+func DecodeHello(data []byte) (*Hello, error) {
+	buf := bytes.NewReader(data)
+	hello := &Hello{}
+
+	if err := binary.Read(buf, binary.BigEndian, &hello.Sequence); err != nil {
+		return nil, ErrInvalidPacket
+	}
+
+	neighbors, err := readNeighbors(buf)
+	if err != nil {
+		return nil, err
+	}
+	hello.Neighbors = neighbors
+
+	// Campo opcional: nós antigos não anunciam capacidades
+	if flags, err := buf.ReadByte(); err == nil {
+		hello.Flags = flags
+	}
+
+	return hello, nil
+}
+
+// writeNeighbors escreve uma lista de vizinhos prefixada pela quantidade
+func writeNeighbors(buf *bytes.Buffer, neighbors []NeighborInfo) {
+	if len(neighbors) > MaxAnnouncedNeighbors {
+		neighbors = neighbors[:MaxAnnouncedNeighbors]
+	}
+
+	buf.WriteByte(byte(len(neighbors)))
+	for _, n := range neighbors {
+		writeShortBytes(buf, n.PeerID)
+		buf.WriteByte(n.Quality)
+	}
+}
+
+// readNeighbors lê uma lista escrita por writeNeighbors
+func readNeighbors(buf *bytes.Reader) ([]NeighborInfo, error) {
+	count, err := buf.ReadByte()
+	if err != nil {
+		return nil, ErrInvalidPacket
+	}
+
+	neighbors := make([]NeighborInfo, 0, count)
+	for i := 0; i < int(count); i++ {
+		peerID, err := readShortBytes(buf)
+		if err != nil {
+			return nil, err
+		}
+		quality, err := buf.ReadByte()
+		if err != nil {
+			return nil, ErrInvalidPacket
+		}
+		neighbors = append(neighbors, NeighborInfo{
+			PeerID:  peerID,
+			Quality: quality,
+		})
+	}
+
+	return neighbors, nil
+}
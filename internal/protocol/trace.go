@@ -0,0 +1,105 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// MaxTraceHops é o número máximo de saltos registrados em um Trace
+const MaxTraceHops = 0xFF
+
+// TraceHop é um salto registrado por um nó que retransmitiu o rastreamento
+type TraceHop struct {
+	PeerID    []byte // Nó que registrou o salto
+	RSSI      int8   // RSSI do enlace com o salto anterior (0 se desconhecido)
+	Signature []byte // Assinatura de TraceHopData pelo nó
+}
+
+// Trace é o payload dos pacotes MessageTypeTraceRequest e MessageTypeTraceReply.
+// A solicitação é propagada por flooding e cada salto acrescenta seu registro;
+// a resposta percorre o caminho registrado de volta ao originador.
+type Trace struct {
+	TraceID   uint32     // ID do rastreamento, único por originador
+	OriginID  []byte     // Nó que iniciou o rastreamento
+	TargetID  []byte     // Nó rastreado
+	Hops      []TraceHop // Saltos registrados, do originador ao destino
+	NextHopID []byte     // Nó que deve retransmitir a resposta a seguir
+}
+
+// TraceHopData retorna os dados assinados por um nó ao registrar seu salto
+func TraceHopData(traceID uint32, originID []byte, peerID []byte, rssi int8) []byte {
+	buf := new(bytes.Buffer)
+
+	binary.Write(buf, binary.BigEndian, traceID)
+	writeShortBytes(buf, originID)
+	writeShortBytes(buf, peerID)
+	buf.WriteByte(byte(rssi))
+
+	return buf.Bytes()
+}
+
+// EncodeTrace serializa um Trace
+func EncodeTrace(trace *Trace) []byte {
+	buf := new(bytes.Buffer)
+
+	hops := trace.Hops
+	if len(hops) > MaxTraceHops {
+		hops = hops[:MaxTraceHops]
+	}
+
+	binary.Write(buf, binary.BigEndian, trace.TraceID)
+	writeShortBytes(buf, trace.OriginID)
+	writeShortBytes(buf, trace.TargetID)
+	buf.WriteByte(uint8(len(hops)))
+	for _, hop := range hops {
+		writeShortBytes(buf, hop.PeerID)
+		buf.WriteByte(byte(hop.RSSI))
+		writeShortBytes(buf, hop.Signature)
+	}
+	writeShortBytes(buf, trace.NextHopID)
+
+	return buf.Bytes()
+}
+
+// DecodeTrace deserializa um Trace
+func DecodeTrace(data []byte) (*Trace, error) {
+	buf := bytes.NewReader(data)
+	trace := &Trace{}
+	var err error
+
+	if err = binary.Read(buf, binary.BigEndian, &trace.TraceID); err != nil {
+		return nil, ErrInvalidPacket
+	}
+	if trace.OriginID, err = readShortBytes(buf); err != nil {
+		return nil, err
+	}
+	if trace.TargetID, err = readShortBytes(buf); err != nil {
+		return nil, err
+	}
+
+	count, err := buf.ReadByte()
+	if err != nil {
+		return nil, ErrInvalidPacket
+	}
+	trace.Hops = make([]TraceHop, count)
+	for i := range trace.Hops {
+		hop := &trace.Hops[i]
+		if hop.PeerID, err = readShortBytes(buf); err != nil {
+			return nil, err
+		}
+		rssi, err := buf.ReadByte()
+		if err != nil {
+			return nil, ErrInvalidPacket
+		}
+		hop.RSSI = int8(rssi)
+		if hop.Signature, err = readShortBytes(buf); err != nil {
+			return nil, err
+		}
+	}
+
+	if trace.NextHopID, err = readShortBytes(buf); err != nil {
+		return nil, err
+	}
+
+	return trace, nil
+}
@@ -0,0 +1,64 @@
+package protocol
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestTraceCodec(t *testing.T) {
+	t.Run("Rastreamento com saltos", func(t *testing.T) {
+		trace := &Trace{
+			TraceID:  9,
+			OriginID: []byte("origem"),
+			TargetID: []byte("destino"),
+			Hops: []TraceHop{
+				{PeerID: []byte("relay-1"), RSSI: -60, Signature: []byte("assinatura-1")},
+				{PeerID: []byte("relay-2"), RSSI: 0, Signature: []byte("assinatura-2")},
+			},
+			NextHopID: []byte("relay-2"),
+		}
+
+		data := EncodeTrace(trace)
+		decoded, err := DecodeTrace(data)
+		if err != nil {
+			t.Fatalf("Erro ao decodificar rastreamento: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, trace) {
+			t.Errorf("Rastreamento esperado %+v, obtido %+v", trace, decoded)
+		}
+
+		for size := 0; size < len(data); size++ {
+			if _, err := DecodeTrace(data[:size]); err != ErrInvalidPacket {
+				t.Fatalf("Rastreamento truncado em %d bytes: esperado ErrInvalidPacket, obtido %v", size, err)
+			}
+		}
+	})
+
+	t.Run("Limite de saltos", func(t *testing.T) {
+		trace := &Trace{TraceID: 1, Hops: make([]TraceHop, MaxTraceHops+10)}
+		decoded, err := DecodeTrace(EncodeTrace(trace))
+		if err != nil {
+			t.Fatalf("Erro ao decodificar rastreamento: %v", err)
+		}
+		if len(decoded.Hops) != MaxTraceHops {
+			t.Errorf("Esperados %d saltos, obtidos %d", MaxTraceHops, len(decoded.Hops))
+		}
+	})
+
+	t.Run("Dados assinados do salto", func(t *testing.T) {
+		data := TraceHopData(9, []byte("origem"), []byte("relay-1"), -60)
+		if !bytes.Equal(data, TraceHopData(9, []byte("origem"), []byte("relay-1"), -60)) {
+			t.Error("Dados assinados deveriam ser determinísticos")
+		}
+		for _, other := range [][]byte{
+			TraceHopData(10, []byte("origem"), []byte("relay-1"), -60),
+			TraceHopData(9, []byte("origem"), []byte("relay-2"), -60),
+			TraceHopData(9, []byte("origem"), []byte("relay-1"), -61),
+		} {
+			if bytes.Equal(data, other) {
+				t.Error("Dados assinados deveriam cobrir rastreamento, nó e RSSI")
+			}
+		}
+	})
+}
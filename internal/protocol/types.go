@@ -25,6 +25,24 @@ const (
 	MessageTypeDeliveryStatusReq MessageType = 0x0B // Solicitar atualização de status de entrega
 	MessageTypeReadReceipt       MessageType = 0x0C // Mensagem foi lida/visualizada
 	MessageTypeText             MessageType = 0x0D // Mensagem de texto simples para testes
+	MessageTypeRouteRequest     MessageType = 0x0E // Solicitação de rota (descoberta sob demanda)
+	MessageTypeRouteReply       MessageType = 0x0F // Resposta de rota para o originador
+	MessageTypeLinkProbe        MessageType = 0x10 // Sonda de qualidade de enlace (apenas vizinhos)
+	MessageTypeLinkProbeReply   MessageType = 0x11 // Resposta à sonda para medição de latência
+	MessageTypeTopologyAnnounce MessageType = 0x12 // Lista de vizinhos diretos para mapa da topologia
+	MessageTypeHello            MessageType = 0x13 // Hello periódico entre vizinhos diretos
+	MessageTypeSyncRequest      MessageType = 0x14 // Resumo de mensagens recentes para sincronização de histórico
+	MessageTypeSyncSummary      MessageType = 0x15 // Impressões digitais por faixa de IDs (anti-entropia)
+	MessageTypeTraceRequest     MessageType = 0x16 // Rastreamento de rota: cada salto acrescenta seu ID
+	MessageTypeTraceReply       MessageType = 0x17 // Caminho rastreado de volta ao originador
+	MessageTypeSleepSchedule    MessageType = 0x18 // Janelas de atividade do rádio (ciclo de trabalho)
+	MessageTypeBenchProbe       MessageType = 0x19 // Carga de teste da medição de desempenho do enlace
+	MessageTypeBenchReply       MessageType = 0x1A // Confirmação de uma carga de teste
+	MessageTypeFileOffer        MessageType = 0x1B // Oferta de arquivo aguardando aceite
+	MessageTypeFileAccept       MessageType = 0x1C // Aceite ou recusa de uma oferta de arquivo
+	MessageTypeFileChunk        MessageType = 0x1D // Pedaço de um arquivo aceito
+	MessageTypeChannelList      MessageType = 0x1E // Canais em que o nó está, para descoberta
+	MessageTypeTyping           MessageType = 0x1F // Início ou fim da digitação em uma conversa
 )
 
 // SpecialRecipients define IDs de destinatários especiais
@@ -40,10 +58,18 @@ type BitchatPacket struct {
 	Payload    []byte
 	Signature  []byte
 	TTL        uint8
+	HopCount   uint8  // Saltos já percorridos (incrementado a cada relay)
 	ID         string // ID único do pacote para deduplicação e tracking
+	NextHop    string // Vizinho designado para o envio (uso local, não serializado)
+	ReceivedFrom string // Enlace do vizinho por onde o pacote chegou (uso local, não serializado)
 	Nonce      []byte // Nonce para criptografia (compatível com testes)
+	Flags      uint8  // Combinação de PacketFlag*
 }
 
+// PacketFlagCompressed indica um payload comprimido com LZ4 antes da
+// criptografia e da assinatura
+const PacketFlagCompressed uint8 = 1 << 0
+
 // NewBitchatPacket cria um novo pacote com valores padrão
 func NewBitchatPacket(msgType MessageType, senderID []byte, recipientID []byte, payload []byte) *BitchatPacket {
 	packet := &BitchatPacket{
@@ -75,6 +101,7 @@ type BitchatMessage struct {
 	Content          string
 	Timestamp        uint64     // Timestamp em milissegundos desde epoch
 	IsRelay          bool
+	HopCount         int        // Saltos percorridos até chegar a este nó
 	OriginalSender   string
 	IsPrivate        bool
 	RecipientNickname string
@@ -83,6 +110,7 @@ type BitchatMessage struct {
 	Channel          string
 	EncryptedContent []byte
 	IsEncrypted      bool
+	IsUrgent         bool       // Enviar por múltiplos caminhos quando possível
 	DeliveryStatus   DeliveryStatus
 }
 
@@ -108,6 +136,7 @@ type DeliveryInfo struct {
 	TotalPeers   int
 	Attempts    int        // Número de tentativas de entrega
 	Error       string     // Mensagem de erro detalhada, se houver
+	HopCount    int        // Saltos percorridos pela mensagem até o destinatário
 }
 
 // DeliveryAck representa uma confirmação de entrega
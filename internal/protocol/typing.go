@@ -0,0 +1,40 @@
+package protocol
+
+// Typing é o payload de um pacote MessageTypeTyping. Informa que o remetente
+// começou ou parou de digitar no canal Channel ou, com Channel vazio, na
+// conversa privada com o destinatário do pacote.
+type Typing struct {
+	Channel string
+	Active  bool // false: o remetente parou de digitar
+}
+
+// Flags do payload de Typing
+const typingFlagActive = 0x01
+
+// EncodeTyping serializa um Typing: flags, tamanho do canal e o canal
+func EncodeTyping(typing *Typing) []byte {
+	channel := typing.Channel
+	if len(channel) > 255 {
+		channel = channel[:255]
+	}
+
+	data := make([]byte, 0, 2+len(channel))
+	var flags byte
+	if typing.Active {
+		flags |= typingFlagActive
+	}
+	data = append(data, flags, byte(len(channel)))
+	return append(data, channel...)
+}
+
+// DecodeTyping deserializa um Typing
+func DecodeTyping(data []byte) (*Typing, error) {
+	if len(data) < 2 || len(data) != 2+int(data[1]) {
+		return nil, ErrInvalidPacket
+	}
+
+	return &Typing{
+		Channel: string(data[2:]),
+		Active:  data[0]&typingFlagActive != 0,
+	}, nil
+}
@@ -0,0 +1,43 @@
+package protocol
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypingCodec(t *testing.T) {
+	t.Run("Início e fim da digitação", func(t *testing.T) {
+		for _, typing := range []Typing{
+			{Channel: "#geral", Active: true},
+			{Channel: "#geral", Active: false},
+			{Active: true}, // Conversa privada
+		} {
+			data := EncodeTyping(&typing)
+			decoded, err := DecodeTyping(data)
+			if err != nil {
+				t.Fatalf("Erro ao decodificar %+v: %v", typing, err)
+			}
+			if *decoded != typing {
+				t.Errorf("Esperado %+v, obtido %+v", typing, decoded)
+			}
+
+			if _, err := DecodeTyping(data[:len(data)-1]); err != ErrInvalidPacket {
+				t.Errorf("Payload truncado: esperado ErrInvalidPacket, obtido %v", err)
+			}
+			if _, err := DecodeTyping(append(data, 'x')); err != ErrInvalidPacket {
+				t.Errorf("Payload com bytes extras: esperado ErrInvalidPacket, obtido %v", err)
+			}
+		}
+	})
+
+	t.Run("Canal longo", func(t *testing.T) {
+		typing := &Typing{Channel: "#" + strings.Repeat("c", 300), Active: true}
+		decoded, err := DecodeTyping(EncodeTyping(typing))
+		if err != nil {
+			t.Fatalf("Erro ao decodificar: %v", err)
+		}
+		if decoded.Channel != typing.Channel[:255] {
+			t.Errorf("Canal deveria ser limitado a 255 bytes, obtido %d", len(decoded.Channel))
+		}
+	})
+}
@@ -23,6 +23,19 @@ func PacketDataForSignature(packet *BitchatPacket) []byte {
 	return buf.Bytes()
 }
 
+// SignedPayload retorna os dados cobertos pela assinatura de um pacote da
+// malha: o payload seguido das flags, quando presentes. Os campos alterados
+// pelos relays (TTL, contador de saltos) ficam de fora; sem flags, os dados
+// são o próprio payload, como nas versões anteriores.
+func SignedPayload(packet *BitchatPacket) []byte {
+	if packet.Flags == 0 {
+		return packet.Payload
+	}
+	data := make([]byte, 0, len(packet.Payload)+1)
+	data = append(data, packet.Payload...)
+	return append(data, packet.Flags)
+}
+
 // BytesToMessage converte bytes para uma mensagem
 // Alias para MessageFromBytes para compatibilidade com os testes
 func BytesToMessage(data []byte) (*Message, error) {
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAwayCooldown é o intervalo mínimo entre respostas automáticas a
+	// um mesmo peer, evitando responder a cada mensagem de uma conversa
+	DefaultAwayCooldown = 30 * time.Minute
+
+	// MaxAwayLog é o número de respostas automáticas mantidas no registro
+	MaxAwayLog = 100
+)
+
+// AwayReply é uma resposta automática enviada enquanto o usuário estava ausente
+type AwayReply struct {
+	PeerID    string
+	Nickname  string
+	MessageID string
+	Content   string
+	SentAt    time.Time
+}
+
+// AwayResponder decide quando responder automaticamente às mensagens
+// privadas recebidas durante a ausência do usuário: uma vez por remetente,
+// repetindo apenas após o intervalo mínimo, e registra o que foi enviado.
+type AwayResponder struct {
+	// Mensagem de ausência; vazia quando o usuário está presente
+	message string
+
+	// Início da ausência
+	since time.Time
+
+	// Intervalo mínimo entre respostas a um mesmo peer
+	cooldown time.Duration
+
+	// Última resposta a cada peer durante a ausência: apelido -> horário
+	replied map[string]time.Time
+
+	// Respostas enviadas, da mais antiga para a mais recente
+	log []AwayReply
+
+	// Mutex para proteger o estado
+	mutex sync.Mutex
+}
+
+// NewAwayResponder cria um respondedor de ausência; cooldown <= 0 usa
+// DefaultAwayCooldown
+func NewAwayResponder(cooldown time.Duration) *AwayResponder {
+	if cooldown <= 0 {
+		cooldown = DefaultAwayCooldown
+	}
+
+	return &AwayResponder{
+		cooldown: cooldown,
+		replied:  make(map[string]time.Time),
+	}
+}
+
+// SetAway marca o usuário como ausente com a mensagem informada. Trocar a
+// mensagem durante a ausência mantém os peers já respondidos.
+func (ar *AwayResponder) SetAway(message string, now time.Time) {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	if ar.message == "" {
+		ar.since = now
+	}
+	ar.message = message
+}
+
+// SetBack marca o usuário como presente; a próxima ausência volta a
+// responder a todos os peers
+func (ar *AwayResponder) SetBack() {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	ar.message = ""
+	ar.since = time.Time{}
+	ar.replied = make(map[string]time.Time)
+}
+
+// Away retorna a mensagem de ausência e o início da ausência, com ok false
+// se o usuário está presente
+func (ar *AwayResponder) Away() (message string, since time.Time, ok bool) {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	return ar.message, ar.since, ar.message != ""
+}
+
+// Due retorna a resposta a enviar a uma mensagem privada do peer, com ok
+// false se o usuário está presente ou o peer já foi respondido há menos que
+// o intervalo mínimo. O apelido identifica o peer, cujo ID muda a cada
+// execução.
+func (ar *AwayResponder) Due(nickname string, now time.Time) (string, bool) {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	if ar.message == "" {
+		return "", false
+	}
+	if last, ok := ar.replied[nickname]; ok && now.Sub(last) < ar.cooldown {
+		return "", false
+	}
+	return ar.message, true
+}
+
+// Record registra uma resposta enviada, que conta para o intervalo mínimo
+func (ar *AwayResponder) Record(reply AwayReply) {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	ar.replied[reply.Nickname] = reply.SentAt
+	ar.log = append(ar.log, reply)
+	if len(ar.log) > MaxAwayLog {
+		ar.log = ar.log[len(ar.log)-MaxAwayLog:]
+	}
+}
+
+// Log retorna as respostas automáticas enviadas, da mais antiga para a mais
+// recente
+func (ar *AwayResponder) Log() []AwayReply {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	log := make([]AwayReply, len(ar.log))
+	copy(log, ar.log)
+	return log
+}
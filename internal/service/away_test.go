@@ -0,0 +1,71 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAwayResponder(t *testing.T) {
+	t.Run("Uma resposta por remetente até o intervalo", func(t *testing.T) {
+		ar := NewAwayResponder(time.Hour)
+		now := time.Now()
+
+		if _, ok := ar.Due("ana", now); ok {
+			t.Fatal("Usuário presente não deveria responder")
+		}
+
+		ar.SetAway("volto às 15h", now)
+		content, ok := ar.Due("ana", now)
+		if !ok || content != "volto às 15h" {
+			t.Fatalf("Esperada a mensagem de ausência, obtido %q", content)
+		}
+		ar.Record(AwayReply{Nickname: "ana", Content: content, SentAt: now})
+
+		if _, ok := ar.Due("ana", now.Add(time.Minute)); ok {
+			t.Error("Peer respondido não deveria receber outra resposta antes do intervalo")
+		}
+		if _, ok := ar.Due("bia", now.Add(time.Minute)); !ok {
+			t.Error("Outro peer deveria ser respondido")
+		}
+		if _, ok := ar.Due("ana", now.Add(time.Hour)); !ok {
+			t.Error("Peer deveria ser respondido de novo após o intervalo")
+		}
+	})
+
+	t.Run("Volta e nova ausência", func(t *testing.T) {
+		ar := NewAwayResponder(0)
+		now := time.Now()
+
+		ar.SetAway("almoço", now)
+		ar.Record(AwayReply{Nickname: "ana", SentAt: now})
+		ar.SetAway("reunião", now.Add(time.Minute))
+		if _, since, _ := ar.Away(); !since.Equal(now) {
+			t.Errorf("Trocar a mensagem não deveria reiniciar a ausência: %v", since)
+		}
+		if _, ok := ar.Due("ana", now.Add(time.Minute)); ok {
+			t.Error("Trocar a mensagem não deveria esquecer os peers respondidos")
+		}
+
+		ar.SetBack()
+		if _, _, ok := ar.Away(); ok {
+			t.Fatal("Usuário deveria estar presente")
+		}
+		ar.SetAway("almoço", now.Add(2*time.Minute))
+		if _, ok := ar.Due("ana", now.Add(2*time.Minute)); !ok {
+			t.Error("Nova ausência deveria responder de novo")
+		}
+		if len(ar.Log()) != 1 {
+			t.Errorf("Registro deveria manter a resposta enviada, obtidas %d", len(ar.Log()))
+		}
+	})
+
+	t.Run("Registro limitado", func(t *testing.T) {
+		ar := NewAwayResponder(0)
+		for i := 0; i < MaxAwayLog+10; i++ {
+			ar.Record(AwayReply{Nickname: "ana", MessageID: string(rune('a' + i%26))})
+		}
+		if len(ar.Log()) != MaxAwayLog {
+			t.Errorf("Esperadas %d respostas no registro, obtidas %d", MaxAwayLog, len(ar.Log()))
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// DefaultDeliveryTrackerTTL é por quanto tempo as confirmações de uma
+// mensagem de canal são contadas
+const DefaultDeliveryTrackerTTL = 10 * time.Minute
+
+// trackedDelivery é uma mensagem de canal aguardando confirmações dos membros
+type trackedDelivery struct {
+	// Membros conhecidos no envio: peerID -> confirmou
+	members map[string]bool
+
+	// Membros que já confirmaram
+	reached int
+
+	// Horário do envio
+	sentAt time.Time
+}
+
+// DeliveryTracker conta as confirmações de entrega de mensagens de canal
+// contra o conjunto de membros conhecido no envio
+type DeliveryTracker struct {
+	// Mensagens acompanhadas: messageID -> entrega
+	deliveries map[string]*trackedDelivery
+
+	// Por quanto tempo uma mensagem é acompanhada
+	ttl time.Duration
+
+	// Mutex para proteger o mapa de entregas
+	mutex sync.Mutex
+}
+
+// NewDeliveryTracker cria um contador de entregas; ttl <= 0 usa
+// DefaultDeliveryTrackerTTL
+func NewDeliveryTracker(ttl time.Duration) *DeliveryTracker {
+	if ttl <= 0 {
+		ttl = DefaultDeliveryTrackerTTL
+	}
+
+	return &DeliveryTracker{
+		deliveries: make(map[string]*trackedDelivery),
+		ttl:        ttl,
+	}
+}
+
+// Track passa a contar as confirmações de uma mensagem enviada aos membros
+// informados. Sem membros conhecidos a mensagem não é acompanhada e Track
+// retorna false.
+func (dt *DeliveryTracker) Track(messageID string, members []string) bool {
+	if len(members) == 0 {
+		return false
+	}
+
+	delivery := &trackedDelivery{
+		members: make(map[string]bool, len(members)),
+		sentAt:  time.Now(),
+	}
+	for _, member := range members {
+		delivery.members[member] = false
+	}
+
+	dt.mutex.Lock()
+	defer dt.mutex.Unlock()
+
+	dt.deliveries[messageID] = delivery
+	return true
+}
+
+// Ack registra a confirmação de entrega de um peer. Retorna tracked false se
+// a mensagem não é acompanhada. Para mensagens acompanhadas, info traz o novo
+// progresso da entrega, ou é nil se a confirmação não mudou a contagem por vir
+// de quem não é membro ou por ser repetida.
+func (dt *DeliveryTracker) Ack(messageID string, peerID string) (info *protocol.DeliveryInfo, tracked bool) {
+	dt.mutex.Lock()
+	defer dt.mutex.Unlock()
+
+	delivery, ok := dt.deliveries[messageID]
+	if !ok {
+		return nil, false
+	}
+
+	acked, member := delivery.members[peerID]
+	if !member || acked {
+		return nil, true
+	}
+	delivery.members[peerID] = true
+	delivery.reached++
+
+	status := protocol.DeliveryStatusPartiallyDelivered
+	if delivery.reached == len(delivery.members) {
+		status = protocol.DeliveryStatusDelivered
+	}
+
+	return &protocol.DeliveryInfo{
+		Status:       status,
+		Timestamp:    uint64(time.Now().UnixMilli()),
+		ReachedPeers: delivery.reached,
+		TotalPeers:   len(delivery.members),
+	}, true
+}
+
+// Pending retorna o número de mensagens acompanhadas que ainda não foram
+// confirmadas por todos os membros
+func (dt *DeliveryTracker) Pending() int {
+	dt.mutex.Lock()
+	defer dt.mutex.Unlock()
+
+	pending := 0
+	for _, delivery := range dt.deliveries {
+		if delivery.reached < len(delivery.members) {
+			pending++
+		}
+	}
+	return pending
+}
+
+// Expire esquece as mensagens enviadas há mais que o TTL e retorna quantas
+// foram esquecidas
+func (dt *DeliveryTracker) Expire(now time.Time) int {
+	dt.mutex.Lock()
+	defer dt.mutex.Unlock()
+
+	expired := 0
+	for messageID, delivery := range dt.deliveries {
+		if now.Sub(delivery.sentAt) > dt.ttl {
+			delete(dt.deliveries, messageID)
+			expired++
+		}
+	}
+	return expired
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+func TestDeliveryTracker(t *testing.T) {
+	t.Run("Entrega parcial e completa", func(t *testing.T) {
+		dt := NewDeliveryTracker(time.Minute)
+		if !dt.Track("msg", []string{"a", "b", "c"}) {
+			t.Fatal("Mensagem com membros deveria ser acompanhada")
+		}
+
+		info, tracked := dt.Ack("msg", "a")
+		if !tracked || info == nil {
+			t.Fatal("Confirmação de membro deveria ser contada")
+		}
+		if info.Status != protocol.DeliveryStatusPartiallyDelivered || info.ReachedPeers != 1 || info.TotalPeers != 3 {
+			t.Errorf("Progresso inesperado: %v %d/%d", info.Status, info.ReachedPeers, info.TotalPeers)
+		}
+
+		dt.Ack("msg", "b")
+		info, _ = dt.Ack("msg", "c")
+		if info == nil || info.Status != protocol.DeliveryStatusDelivered || info.ReachedPeers != 3 {
+			t.Errorf("Mensagem deveria estar entregue a todos: %+v", info)
+		}
+		if dt.Pending() != 0 {
+			t.Errorf("Nenhuma mensagem deveria estar pendente, obtidas %d", dt.Pending())
+		}
+	})
+
+	t.Run("Confirmações ignoradas", func(t *testing.T) {
+		dt := NewDeliveryTracker(time.Minute)
+		if dt.Track("vazio", nil) {
+			t.Error("Mensagem sem membros não deveria ser acompanhada")
+		}
+		if _, tracked := dt.Ack("outra", "a"); tracked {
+			t.Error("Mensagem desconhecida não deveria ser acompanhada")
+		}
+
+		dt.Track("msg", []string{"a", "b"})
+		dt.Ack("msg", "a")
+		if info, tracked := dt.Ack("msg", "a"); !tracked || info != nil {
+			t.Error("Confirmação repetida não deveria mudar a contagem")
+		}
+		if info, tracked := dt.Ack("msg", "x"); !tracked || info != nil {
+			t.Error("Confirmação de quem não é membro não deveria ser contada")
+		}
+	})
+
+	t.Run("Expiração", func(t *testing.T) {
+		dt := NewDeliveryTracker(time.Minute)
+		dt.Track("msg", []string{"a"})
+		if expired := dt.Expire(time.Now().Add(2 * time.Minute)); expired != 1 {
+			t.Errorf("Esperada 1 mensagem expirada, obtidas %d", expired)
+		}
+		if _, tracked := dt.Ack("msg", "a"); tracked {
+			t.Error("Mensagem expirada não deveria ser acompanhada")
+		}
+	})
+}
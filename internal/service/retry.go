@@ -1,7 +1,7 @@
 package service
 
 import (
-	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -201,7 +201,14 @@ func (rs *RetryService) GetPendingMessages() []*protocol.BitchatPacket {
 func (rs *RetryService) retryLoop() {
 	defer rs.wg.Done()
 	
-	ticker := time.NewTicker(1 * time.Second)
+	// Verificar ao menos a cada backoff inicial, para que intervalos curtos
+	// não sejam arredondados para o segundo
+	interval := 1 * time.Second
+	if rs.config.InitialBackoff > 0 && rs.config.InitialBackoff < interval {
+		interval = rs.config.InitialBackoff
+	}
+	
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	
 	for {
@@ -266,8 +273,7 @@ func (rs *RetryService) retryMessage(item *RetryItem) {
 	// Tentar reenviar a mensagem
 	err := rs.sendPacketFunc(item.Packet, item.TargetPeerID)
 	if err != nil {
-		fmt.Printf("Erro ao reenviar mensagem %s (tentativa %d): %v\n", 
-			item.Packet.ID, item.Attempts, err)
+		slog.Error("erro ao reenviar mensagem", "id", item.Packet.ID, "attempt", item.Attempts, "err", err)
 	}
 }
 
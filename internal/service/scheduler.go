@@ -0,0 +1,242 @@
+package service
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/pkg/utils"
+)
+
+// SchedulerInterval é o intervalo entre as verificações de mensagens agendadas
+const SchedulerInterval = time.Second
+
+// ErrInvalidScheduledMessage indica uma mensagem agendada sem destino ou
+// conteúdo
+var ErrInvalidScheduledMessage = errors.New("mensagem agendada inválida")
+
+// ScheduledMessage é uma mensagem aguardando o horário de envio
+type ScheduledMessage struct {
+	ID        string    `json:"id"`
+	Channel   string    `json:"channel,omitempty"`   // Canal de destino
+	Recipient string    `json:"recipient,omitempty"` // Apelido do destinatário de mensagens privadas
+	Content   string    `json:"content"`
+	SendAt    time.Time `json:"send_at"` // Zero: assim que o destinatário estiver ao alcance
+}
+
+// Private informa se a mensagem é privada
+func (sm *ScheduledMessage) Private() bool {
+	return sm.Recipient != ""
+}
+
+// Scheduler guarda mensagens agendadas em disco e as entrega ao envio no
+// horário marcado. Mensagens privadas aguardam também o destinatário estar
+// ao alcance; envios que falham são repetidos na verificação seguinte.
+type Scheduler struct {
+	// Arquivo em que as mensagens agendadas são gravadas
+	path string
+
+	// Mensagens agendadas, em ordem de horário
+	messages []*ScheduledMessage
+
+	// Envia uma mensagem agendada à rede
+	send func(message *ScheduledMessage) error
+
+	// Informa se o destinatário de uma mensagem privada está ao alcance
+	reachable func(recipient string) bool
+
+	// Mutex para proteger a lista de mensagens
+	mutex sync.Mutex
+
+	// Serializa as verificações, para que uma mensagem não seja enviada duas vezes
+	dispatching sync.Mutex
+
+	// Canal para sinalizar parada
+	stopChan chan struct{}
+
+	// WaitGroup para esperar goroutines
+	wg sync.WaitGroup
+}
+
+// NewScheduler cria um agendador que grava as mensagens em path, carregando
+// as agendadas em execuções anteriores
+func NewScheduler(path string, send func(message *ScheduledMessage) error, reachable func(recipient string) bool) (*Scheduler, error) {
+	s := &Scheduler{
+		path:      path,
+		send:      send,
+		reachable: reachable,
+		stopChan:  make(chan struct{}),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("erro ao ler mensagens agendadas: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.messages); err != nil {
+		return s, fmt.Errorf("erro ao decodificar mensagens agendadas: %v", err)
+	}
+	s.sortLocked()
+	return s, nil
+}
+
+// Schedule agenda uma mensagem, atribuindo-lhe um ID, e a grava em disco
+func (s *Scheduler) Schedule(message *ScheduledMessage) error {
+	if message.Content == "" || (message.Channel == "") == (message.Recipient == "") {
+		return ErrInvalidScheduledMessage
+	}
+	if message.SendAt.IsZero() && !message.Private() {
+		return ErrInvalidScheduledMessage
+	}
+	message.ID = hex.EncodeToString(utils.GenerateRandomID(4))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.messages = append(s.messages, message)
+	s.sortLocked()
+	return s.saveLocked()
+}
+
+// Cancel desiste de uma mensagem agendada. Retorna false se o ID não é
+// conhecido.
+func (s *Scheduler) Cancel(id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, message := range s.messages {
+		if message.ID == id {
+			s.messages = append(s.messages[:i], s.messages[i+1:]...)
+			if err := s.saveLocked(); err != nil {
+				slog.Error("erro ao gravar mensagens agendadas", "err", err)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// List retorna cópias das mensagens agendadas, em ordem de horário
+func (s *Scheduler) List() []ScheduledMessage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	list := make([]ScheduledMessage, len(s.messages))
+	for i, message := range s.messages {
+		list[i] = *message
+	}
+	return list
+}
+
+// Start inicia a verificação periódica das mensagens agendadas
+func (s *Scheduler) Start() {
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop para a verificação; as mensagens pendentes continuam gravadas
+func (s *Scheduler) Stop() {
+	close(s.stopChan)
+	s.wg.Wait()
+}
+
+// loop verifica as mensagens agendadas a cada SchedulerInterval
+func (s *Scheduler) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(SchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case now := <-ticker.C:
+			s.Dispatch(now)
+		}
+	}
+}
+
+// Dispatch envia as mensagens cujo horário chegou e cujo destinatário está
+// ao alcance, retornando quantas foram enviadas. É chamado periodicamente e
+// pode ser chamado quando um peer aparece, para entregar logo as mensagens
+// que aguardavam por ele.
+func (s *Scheduler) Dispatch(now time.Time) int {
+	s.dispatching.Lock()
+	defer s.dispatching.Unlock()
+
+	s.mutex.Lock()
+	var due []*ScheduledMessage
+	for _, message := range s.messages {
+		if message.SendAt.After(now) {
+			break
+		}
+		if message.Private() && !s.reachable(message.Recipient) {
+			continue
+		}
+		due = append(due, message)
+	}
+	s.mutex.Unlock()
+
+	// Enviar sem o mutex, já que o envio pode agendar ou cancelar mensagens
+	sent := make(map[string]bool)
+	for _, message := range due {
+		if err := s.send(message); err != nil {
+			slog.Warn("erro ao enviar mensagem agendada", "id", message.ID, "err", err)
+			continue
+		}
+		sent[message.ID] = true
+	}
+	if len(sent) == 0 {
+		return 0
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	remaining := s.messages[:0]
+	for _, message := range s.messages {
+		if !sent[message.ID] {
+			remaining = append(remaining, message)
+		}
+	}
+	s.messages = remaining
+	if err := s.saveLocked(); err != nil {
+		slog.Error("erro ao gravar mensagens agendadas", "err", err)
+	}
+	return len(sent)
+}
+
+// sortLocked ordena as mensagens por horário; as que aguardam apenas o
+// destinatário vêm primeiro. Exige s.mutex.
+func (s *Scheduler) sortLocked() {
+	sort.SliceStable(s.messages, func(i, j int) bool {
+		return s.messages[i].SendAt.Before(s.messages[j].SendAt)
+	})
+}
+
+// saveLocked grava as mensagens agendadas em disco. Exige s.mutex.
+func (s *Scheduler) saveLocked() error {
+	data, err := json.MarshalIndent(s.messages, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao codificar mensagens agendadas: %v", err)
+	}
+
+	// Gravar em um arquivo temporário para não corromper o agendamento
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("erro ao gravar mensagens agendadas: %v", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("erro ao gravar mensagens agendadas: %v", err)
+	}
+	return nil
+}
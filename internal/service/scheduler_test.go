@@ -0,0 +1,90 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	t.Run("Envio no horário", func(t *testing.T) {
+		var sent []string
+		send := func(message *ScheduledMessage) error {
+			sent = append(sent, message.Content)
+			return nil
+		}
+		s, err := NewScheduler(filepath.Join(t.TempDir(), "scheduled.json"), send, func(string) bool { return true })
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		now := time.Now()
+		s.Schedule(&ScheduledMessage{Channel: "#ops", Content: "depois", SendAt: now.Add(time.Hour)})
+		s.Schedule(&ScheduledMessage{Channel: "#ops", Content: "antes", SendAt: now.Add(time.Minute)})
+
+		if n := s.Dispatch(now); n != 0 {
+			t.Fatalf("Nenhuma mensagem deveria ser enviada antes do horário, enviadas %d", n)
+		}
+		if n := s.Dispatch(now.Add(2 * time.Minute)); n != 1 || sent[0] != "antes" {
+			t.Fatalf("Esperada a mensagem \"antes\", enviadas %v", sent)
+		}
+		if len(s.List()) != 1 {
+			t.Errorf("Esperada 1 mensagem agendada, obtidas %d", len(s.List()))
+		}
+	})
+
+	t.Run("Aguardar o destinatário", func(t *testing.T) {
+		online := false
+		sent := 0
+		s, _ := NewScheduler(filepath.Join(t.TempDir(), "scheduled.json"),
+			func(*ScheduledMessage) error { sent++; return nil },
+			func(recipient string) bool { return online && recipient == "bob" })
+
+		if err := s.Schedule(&ScheduledMessage{Recipient: "bob", Content: "oi"}); err != nil {
+			t.Fatal(err)
+		}
+		if s.Dispatch(time.Now()); sent != 0 {
+			t.Fatal("Mensagem não deveria ser enviada com o destinatário fora de alcance")
+		}
+		online = true
+		if s.Dispatch(time.Now()); sent != 1 {
+			t.Error("Mensagem deveria ser enviada quando o destinatário aparece")
+		}
+	})
+
+	t.Run("Persistência e cancelamento", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "scheduled.json")
+		send := func(*ScheduledMessage) error { return nil }
+		reachable := func(string) bool { return true }
+
+		s, _ := NewScheduler(path, send, reachable)
+		first := &ScheduledMessage{Channel: "#ops", Content: "a", SendAt: time.Now().Add(time.Hour)}
+		s.Schedule(first)
+		s.Schedule(&ScheduledMessage{Channel: "#ops", Content: "b", SendAt: time.Now().Add(time.Hour)})
+		if !s.Cancel(first.ID) || s.Cancel(first.ID) {
+			t.Error("Cancelamento deveria acontecer uma única vez")
+		}
+
+		reloaded, err := NewScheduler(path, send, reachable)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if list := reloaded.List(); len(list) != 1 || list[0].Content != "b" {
+			t.Errorf("Mensagens agendadas não foram gravadas: %+v", list)
+		}
+	})
+
+	t.Run("Mensagens inválidas", func(t *testing.T) {
+		s, _ := NewScheduler(filepath.Join(t.TempDir(), "scheduled.json"), nil, nil)
+		for _, message := range []*ScheduledMessage{
+			{Channel: "#ops", SendAt: time.Now()},
+			{Content: "sem destino", SendAt: time.Now()},
+			{Channel: "#ops", Recipient: "bob", Content: "dois destinos", SendAt: time.Now()},
+			{Channel: "#ops", Content: "canal não espera destinatário"},
+		} {
+			if err := s.Schedule(message); err != ErrInvalidScheduledMessage {
+				t.Errorf("Mensagem %+v deveria ser recusada, obtido %v", message, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// TypingResendInterval é o intervalo mínimo entre avisos de digitação
+	// enviados a uma conversa enquanto o usuário continua digitando
+	TypingResendInterval = 3 * time.Second
+
+	// TypingIdleTimeout é por quanto tempo sem digitar o usuário é dado como
+	// parado, quando o aviso de fim é enviado
+	TypingIdleTimeout = 5 * time.Second
+
+	// TypingExpiry é por quanto tempo um aviso de digitação recebido vale sem
+	// ser renovado. Maior que TypingResendInterval, para tolerar um aviso perdido.
+	TypingExpiry = 7 * time.Second
+
+	// TypingTickInterval é o intervalo entre as verificações de expiração
+	TypingTickInterval = time.Second
+)
+
+// TypingEvent informa que um peer começou ou parou de digitar em uma conversa
+type TypingEvent struct {
+	Conversation string // Canal ou "@" seguido do apelido, na conversa privada
+	PeerID       string
+	Active       bool
+}
+
+// outgoingTyping acompanha a digitação do usuário em uma conversa
+type outgoingTyping struct {
+	// Última tecla digitada
+	lastKey time.Time
+
+	// Último aviso de digitação enviado
+	lastSent time.Time
+}
+
+// incomingTyping identifica um peer digitando em uma conversa
+type incomingTyping struct {
+	conversation string
+	peerID       string
+}
+
+// TypingService cuida da temporização dos indicadores de digitação: limita a
+// frequência dos avisos enviados, avisa o fim da digitação após um período
+// ocioso e expira os avisos recebidos que não foram renovados, de modo que as
+// interfaces só precisem informar as teclas e exibir os eventos.
+type TypingService struct {
+	// Conversas em que o usuário está digitando
+	outgoing map[string]*outgoingTyping
+
+	// Peers digitando: peer e conversa -> validade do aviso
+	incoming map[incomingTyping]time.Time
+
+	// Envia um aviso de início ou fim da digitação a uma conversa
+	send func(conversation string, active bool)
+
+	// Informa a interface de mudanças na digitação dos peers
+	notify func(event TypingEvent)
+
+	// Mutex para proteger os mapas
+	mutex sync.Mutex
+}
+
+// NewTypingService cria um serviço de indicadores de digitação. send e
+// notify são chamados sem travas, podendo chamar o serviço de volta.
+func NewTypingService(send func(conversation string, active bool), notify func(event TypingEvent)) *TypingService {
+	return &TypingService{
+		outgoing: make(map[string]*outgoingTyping),
+		incoming: make(map[incomingTyping]time.Time),
+		send:     send,
+		notify:   notify,
+	}
+}
+
+// KeyPressed registra uma tecla digitada pelo usuário em uma conversa,
+// avisando a conversa no início da digitação e depois a cada
+// TypingResendInterval
+func (ts *TypingService) KeyPressed(conversation string, now time.Time) {
+	ts.mutex.Lock()
+	typing, ok := ts.outgoing[conversation]
+	if !ok {
+		typing = &outgoingTyping{}
+		ts.outgoing[conversation] = typing
+	}
+	typing.lastKey = now
+	due := now.Sub(typing.lastSent) >= TypingResendInterval
+	if due {
+		typing.lastSent = now
+	}
+	ts.mutex.Unlock()
+
+	if due {
+		ts.send(conversation, true)
+	}
+}
+
+// Stopped registra que o usuário parou de digitar em uma conversa, ao enviar
+// a mensagem ou apagar a linha, avisando o fim da digitação
+func (ts *TypingService) Stopped(conversation string) {
+	ts.mutex.Lock()
+	_, ok := ts.outgoing[conversation]
+	delete(ts.outgoing, conversation)
+	ts.mutex.Unlock()
+
+	if ok {
+		ts.send(conversation, false)
+	}
+}
+
+// StopAll avisa o fim da digitação em todas as conversas em que o usuário
+// digitava
+func (ts *TypingService) StopAll() {
+	ts.mutex.Lock()
+	conversations := make([]string, 0, len(ts.outgoing))
+	for conversation := range ts.outgoing {
+		conversations = append(conversations, conversation)
+	}
+	ts.outgoing = make(map[string]*outgoingTyping)
+	ts.mutex.Unlock()
+
+	for _, conversation := range conversations {
+		ts.send(conversation, false)
+	}
+}
+
+// Received registra um aviso de digitação de um peer, informando a interface
+// apenas quando o peer começa ou para de digitar
+func (ts *TypingService) Received(conversation string, peerID string, active bool, now time.Time) {
+	key := incomingTyping{conversation: conversation, peerID: peerID}
+
+	ts.mutex.Lock()
+	_, wasActive := ts.incoming[key]
+	if active {
+		ts.incoming[key] = now.Add(TypingExpiry)
+	} else {
+		delete(ts.incoming, key)
+	}
+	ts.mutex.Unlock()
+
+	if active != wasActive {
+		ts.notify(TypingEvent{Conversation: conversation, PeerID: peerID, Active: active})
+	}
+}
+
+// PeerLeft encerra a digitação de um peer que saiu da rede em todas as
+// conversas
+func (ts *TypingService) PeerLeft(peerID string) {
+	ts.mutex.Lock()
+	var stopped []incomingTyping
+	for key := range ts.incoming {
+		if key.peerID == peerID {
+			stopped = append(stopped, key)
+			delete(ts.incoming, key)
+		}
+	}
+	ts.mutex.Unlock()
+
+	for _, key := range stopped {
+		ts.notify(TypingEvent{Conversation: key.conversation, PeerID: key.peerID, Active: false})
+	}
+}
+
+// Typing retorna os peers digitando em uma conversa, em ordem
+func (ts *TypingService) Typing(conversation string) []string {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+
+	var peers []string
+	for key := range ts.incoming {
+		if key.conversation == conversation {
+			peers = append(peers, key.peerID)
+		}
+	}
+	sort.Strings(peers)
+	return peers
+}
+
+// Tick avisa o fim da digitação nas conversas ociosas e expira os avisos
+// recebidos que não foram renovados. Chamado a cada TypingTickInterval.
+func (ts *TypingService) Tick(now time.Time) {
+	ts.mutex.Lock()
+	var idle []string
+	for conversation, typing := range ts.outgoing {
+		if now.Sub(typing.lastKey) >= TypingIdleTimeout {
+			idle = append(idle, conversation)
+			delete(ts.outgoing, conversation)
+		}
+	}
+	var expired []incomingTyping
+	for key, expiresAt := range ts.incoming {
+		if !now.Before(expiresAt) {
+			expired = append(expired, key)
+			delete(ts.incoming, key)
+		}
+	}
+	ts.mutex.Unlock()
+
+	for _, conversation := range idle {
+		ts.send(conversation, false)
+	}
+	for _, key := range expired {
+		ts.notify(TypingEvent{Conversation: key.conversation, PeerID: key.peerID, Active: false})
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+// typingRecorder guarda os avisos enviados e os eventos informados
+type typingRecorder struct {
+	sent   []bool
+	events []TypingEvent
+}
+
+func newTypingRecorder() (*TypingService, *typingRecorder) {
+	r := &typingRecorder{}
+	ts := NewTypingService(
+		func(conversation string, active bool) { r.sent = append(r.sent, active) },
+		func(event TypingEvent) { r.events = append(r.events, event) },
+	)
+	return ts, r
+}
+
+func TestTypingService(t *testing.T) {
+	t.Run("Avisos enviados limitados e fim após ociosidade", func(t *testing.T) {
+		ts, r := newTypingRecorder()
+		start := time.Now()
+
+		for i := 0; i < 10; i++ {
+			ts.KeyPressed("#geral", start.Add(time.Duration(i)*200*time.Millisecond))
+		}
+		if len(r.sent) != 1 || !r.sent[0] {
+			t.Fatalf("Esperado um aviso de início, enviados %v", r.sent)
+		}
+
+		ts.KeyPressed("#geral", start.Add(TypingResendInterval))
+		if len(r.sent) != 2 {
+			t.Fatalf("Aviso deveria ser renovado após o intervalo, enviados %v", r.sent)
+		}
+
+		ts.Tick(start.Add(TypingResendInterval + TypingIdleTimeout/2))
+		if len(r.sent) != 2 {
+			t.Fatalf("Fim não deveria ser avisado antes da ociosidade, enviados %v", r.sent)
+		}
+		ts.Tick(start.Add(TypingResendInterval + TypingIdleTimeout))
+		if len(r.sent) != 3 || r.sent[2] {
+			t.Fatalf("Esperado o aviso de fim, enviados %v", r.sent)
+		}
+
+		ts.Stopped("#geral")
+		if len(r.sent) != 3 {
+			t.Errorf("Fim não deveria ser repetido, enviados %v", r.sent)
+		}
+	})
+
+	t.Run("Fim ao enviar a mensagem", func(t *testing.T) {
+		ts, r := newTypingRecorder()
+		ts.KeyPressed("@ana", time.Now())
+		ts.Stopped("@ana")
+		if len(r.sent) != 2 || r.sent[1] {
+			t.Errorf("Esperados início e fim, enviados %v", r.sent)
+		}
+	})
+
+	t.Run("Avisos recebidos expiram", func(t *testing.T) {
+		ts, r := newTypingRecorder()
+		start := time.Now()
+
+		ts.Received("#geral", "peer1", true, start)
+		ts.Received("#geral", "peer1", true, start.Add(2*time.Second))
+		if len(r.events) != 1 || !r.events[0].Active {
+			t.Fatalf("Esperado um evento de início, obtidos %+v", r.events)
+		}
+		if peers := ts.Typing("#geral"); len(peers) != 1 || peers[0] != "peer1" {
+			t.Errorf("Peers digitando inesperados: %v", peers)
+		}
+
+		ts.Tick(start.Add(TypingExpiry))
+		if len(r.events) != 1 {
+			t.Fatalf("Aviso renovado não deveria expirar, eventos %+v", r.events)
+		}
+		ts.Tick(start.Add(2*time.Second + TypingExpiry))
+		if len(r.events) != 2 || r.events[1].Active {
+			t.Fatalf("Esperado o fim por expiração, eventos %+v", r.events)
+		}
+		if len(ts.Typing("#geral")) != 0 {
+			t.Error("Nenhum peer deveria estar digitando")
+		}
+	})
+
+	t.Run("Fim recebido e saída do peer", func(t *testing.T) {
+		ts, r := newTypingRecorder()
+		now := time.Now()
+
+		ts.Received("@bia", "peer1", false, now)
+		if len(r.events) != 0 {
+			t.Fatalf("Fim sem início não deveria gerar evento, eventos %+v", r.events)
+		}
+
+		ts.Received("@bia", "peer1", true, now)
+		ts.Received("#geral", "peer1", true, now)
+		ts.Received("@bia", "peer1", false, now)
+		if len(r.events) != 3 || r.events[2].Active || r.events[2].Conversation != "@bia" {
+			t.Fatalf("Eventos inesperados: %+v", r.events)
+		}
+
+		ts.PeerLeft("peer1")
+		if len(r.events) != 4 || r.events[3].Conversation != "#geral" || r.events[3].Active {
+			t.Errorf("Saída do peer deveria encerrar a digitação no canal, eventos %+v", r.events)
+		}
+	})
+}
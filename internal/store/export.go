@@ -0,0 +1,106 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// ExportFormat é o formato de exportação de um histórico
+type ExportFormat int
+
+const (
+	ExportMarkdown ExportFormat = iota
+	ExportJSON
+)
+
+// ExportFormatForPath escolhe o formato pela extensão do arquivo: .json
+// exporta em JSON e as demais extensões em Markdown
+func ExportFormatForPath(path string) ExportFormat {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ExportJSON
+	}
+	return ExportMarkdown
+}
+
+// ExportRange limita a exportação às mensagens entre From e To. Um limite
+// zero não restringe a exportação.
+type ExportRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// Contains informa se um instante está dentro do intervalo
+func (r ExportRange) Contains(t time.Time) bool {
+	if !r.From.IsZero() && t.Before(r.From) {
+		return false
+	}
+	if !r.To.IsZero() && !t.Before(r.To) {
+		return false
+	}
+	return true
+}
+
+// exportedMessage é a representação de uma mensagem na exportação em JSON
+type exportedMessage struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	Sender    string    `json:"sender"`
+	Channel   string    `json:"channel,omitempty"`
+	Recipient string    `json:"recipient,omitempty"`
+	Content   string    `json:"content"`
+}
+
+// ExportChannelMessages escreve em w o histórico de um canal; retorna o
+// número de mensagens exportadas
+func (ms *MessageStore) ExportChannelMessages(channel string, w io.Writer, format ExportFormat, r ExportRange) (int, error) {
+	return exportMessages(w, "Canal "+channel, ms.GetChannelMessages(channel), format, r)
+}
+
+// ExportPrivateMessages escreve em w o histórico privado com um peer;
+// retorna o número de mensagens exportadas
+func (ms *MessageStore) ExportPrivateMessages(peerID, title string, w io.Writer, format ExportFormat, r ExportRange) (int, error) {
+	return exportMessages(w, title, ms.GetPrivateMessages(peerID), format, r)
+}
+
+func exportMessages(w io.Writer, title string, messages []*protocol.BitchatMessage, format ExportFormat, r ExportRange) (int, error) {
+	selected := make([]exportedMessage, 0, len(messages))
+	for _, msg := range messages {
+		timestamp := time.UnixMilli(int64(msg.Timestamp))
+		if !r.Contains(timestamp) {
+			continue
+		}
+		selected = append(selected, exportedMessage{
+			ID:        msg.ID,
+			Time:      timestamp,
+			Sender:    msg.Sender,
+			Channel:   msg.Channel,
+			Recipient: msg.RecipientNickname,
+			Content:   msg.Content,
+		})
+	}
+
+	if format == ExportJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(selected); err != nil {
+			return 0, fmt.Errorf("erro ao exportar mensagens: %v", err)
+		}
+		return len(selected), nil
+	}
+
+	if _, err := fmt.Fprintf(w, "# %s\n\n", title); err != nil {
+		return 0, fmt.Errorf("erro ao exportar mensagens: %v", err)
+	}
+	for _, msg := range selected {
+		if _, err := fmt.Fprintf(w, "- **%s** %s: %s\n", msg.Time.Format("2006-01-02 15:04:05"), msg.Sender, msg.Content); err != nil {
+			return 0, fmt.Errorf("erro ao exportar mensagens: %v", err)
+		}
+	}
+	return len(selected), nil
+}
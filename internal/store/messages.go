@@ -3,8 +3,11 @@ package store
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,15 +15,48 @@ import (
 	"github.com/permissionlesstech/bitchat/pkg/utils"
 )
 
-// MessageStore gerencia o armazenamento persistente de mensagens
+// DefaultWriteDelay é a espera, após a primeira alteração, antes de gravar;
+// alterações feitas nesse intervalo saem em uma única gravação por arquivo
+const DefaultWriteDelay = 250 * time.Millisecond
+
+// Arquivos únicos marcados como alterados; os de canais e conversas
+// privadas usam os prefixos dirtyChannel e dirtyPrivate
+const (
+	dirtyPending  = "pending"
+	dirtyOutgoing = "outgoing"
+	dirtyChannel  = "channel:"
+	dirtyPrivate  = "private:"
+)
+
+// MessageStore gerencia o armazenamento persistente de mensagens. As
+// alterações marcam o arquivo correspondente como alterado; uma única
+// goroutine grava os arquivos alterados, agrupando rajadas de mensagens.
 type MessageStore struct {
-	dataDir         string
-	channelMessages map[string][]*protocol.BitchatMessage // canal -> mensagens
-	privateMessages map[string][]*protocol.BitchatMessage // peerID -> mensagens
-	pendingMessages map[string]*protocol.BitchatPacket    // messageID -> pacote
-	mutex           sync.RWMutex
-	maxMessages     int
-	retentionPeriod time.Duration
+	dataDir          string
+	channelMessages  map[string][]*protocol.BitchatMessage // canal -> mensagens
+	privateMessages  map[string][]*protocol.BitchatMessage // peerID -> mensagens
+	pendingMessages  map[string]*protocol.BitchatPacket    // messageID -> pacote
+	outgoingMessages map[string]*protocol.BitchatMessage   // messageID -> mensagem ainda não transmitida
+	mutex            sync.RWMutex
+	dirty            map[string]bool // Arquivos com alterações ainda não gravadas
+	wake             chan struct{}   // Avisa a goroutine de gravação de novas alterações
+	done             chan struct{}   // Fechado por Close para encerrar a goroutine de gravação
+	stopped          chan struct{}   // Fechado quando a goroutine de gravação termina
+	writeMutex       sync.Mutex      // Serializa as gravações e remoções de arquivos; adquirido antes de mutex
+	closeOnce        sync.Once
+	writeDelay       time.Duration
+	maxMessages      int
+	retentionPeriod  time.Duration
+}
+
+// StoreStats resume o conteúdo do armazenamento de mensagens
+type StoreStats struct {
+	Channels         int // Canais com histórico
+	ChannelMessages  int
+	Conversations    int // Conversas privadas com histórico
+	PrivateMessages  int
+	PendingMessages  int // Pacotes aguardando reenvio
+	OutgoingMessages int // Mensagens compostas aguardando transmissão
 }
 
 // NewMessageStore cria um novo armazenamento de mensagens
@@ -31,19 +67,26 @@ func NewMessageStore(dataDir string) (*MessageStore, error) {
 	}
 
 	store := &MessageStore{
-		dataDir:         dataDir,
-		channelMessages: make(map[string][]*protocol.BitchatMessage),
-		privateMessages: make(map[string][]*protocol.BitchatMessage),
-		pendingMessages: make(map[string]*protocol.BitchatPacket),
-		maxMessages:     1000,                    // Máximo de mensagens por canal/peer
-		retentionPeriod: 30 * 24 * time.Hour,     // 30 dias de retenção padrão
+		dataDir:          dataDir,
+		channelMessages:  make(map[string][]*protocol.BitchatMessage),
+		privateMessages:  make(map[string][]*protocol.BitchatMessage),
+		pendingMessages:  make(map[string]*protocol.BitchatPacket),
+		outgoingMessages: make(map[string]*protocol.BitchatMessage),
+		dirty:            make(map[string]bool),
+		wake:             make(chan struct{}, 1),
+		done:             make(chan struct{}),
+		stopped:          make(chan struct{}),
+		writeDelay:       DefaultWriteDelay,
+		maxMessages:      1000,                // Máximo de mensagens por canal/peer
+		retentionPeriod:  30 * 24 * time.Hour, // 30 dias de retenção padrão
 	}
 
 	// Carregar mensagens salvas
 	if err := store.loadMessages(); err != nil {
-		fmt.Printf("Aviso: erro ao carregar mensagens: %v\n", err)
+		slog.Warn("erro ao carregar mensagens", "err", err)
 	}
 
+	go store.writeLoop()
 	return store, nil
 }
 
@@ -67,7 +110,7 @@ func (ms *MessageStore) AddChannelMessage(channel string, message *protocol.Bitc
 	}
 
 	// Salvar em background
-	go ms.saveChannelMessages(channel)
+	ms.markDirtyLocked(dirtyChannel + channel)
 }
 
 // AddPrivateMessage adiciona uma mensagem ao histórico de mensagens privadas
@@ -90,7 +133,7 @@ func (ms *MessageStore) AddPrivateMessage(peerID string, message *protocol.Bitch
 	}
 
 	// Salvar em background
-	go ms.savePrivateMessages(peerID)
+	ms.markDirtyLocked(dirtyPrivate + peerID)
 }
 
 // GetChannelMessages retorna as mensagens de um canal
@@ -117,12 +160,75 @@ func (ms *MessageStore) GetPrivateMessages(peerID string) []*protocol.BitchatMes
 	return []*protocol.BitchatMessage{}
 }
 
+// MarkPrivateRead marca como lidas as mensagens recebidas na conversa
+// privada com um peer e retorna as que ainda não estavam lidas, em ordem
+// cronológica. Mensagens enviadas por este nó não são alteradas.
+func (ms *MessageStore) MarkPrivateRead(peerID string) []*protocol.BitchatMessage {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	var unread []*protocol.BitchatMessage
+	for _, message := range ms.privateMessages[peerID] {
+		if message.SenderPeerID == "" || message.DeliveryStatus == protocol.DeliveryStatusRead {
+			continue
+		}
+		message.DeliveryStatus = protocol.DeliveryStatusRead
+		unread = append(unread, message)
+	}
+
+	if len(unread) > 0 {
+		ms.markDirtyLocked(dirtyPrivate + peerID)
+	}
+	return unread
+}
+
+// GetChannelHistory retorna uma página do histórico de um canal: até limit
+// mensagens, em ordem cronológica, terminando skip mensagens antes da mais
+// recente. O segundo valor informa se há mensagens mais antigas.
+func (ms *MessageStore) GetChannelHistory(channel string, skip, limit int) ([]*protocol.BitchatMessage, bool) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	return historyPage(ms.channelMessages[channel], skip, limit)
+}
+
+// GetPrivateHistory retorna uma página do histórico privado com um peer,
+// como GetChannelHistory
+func (ms *MessageStore) GetPrivateHistory(peerID string, skip, limit int) ([]*protocol.BitchatMessage, bool) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	return historyPage(ms.privateMessages[peerID], skip, limit)
+}
+
+// historyPage recorta uma página contada a partir da mensagem mais recente
+func historyPage(messages []*protocol.BitchatMessage, skip, limit int) ([]*protocol.BitchatMessage, bool) {
+	end := len(messages) - skip
+	if end <= 0 || limit <= 0 {
+		return []*protocol.BitchatMessage{}, false
+	}
+	start := end - limit
+	if start < 0 {
+		start = 0
+	}
+
+	page := make([]*protocol.BitchatMessage, end-start)
+	copy(page, messages[start:end])
+	return page, start > 0
+}
+
 // ClearChannelMessages limpa o histórico de mensagens de um canal
 func (ms *MessageStore) ClearChannelMessages(channel string) {
+	// A remoção é serializada com as gravações, para que uma gravação já
+	// serializada não recrie o arquivo
+	ms.writeMutex.Lock()
+	defer ms.writeMutex.Unlock()
+
 	ms.mutex.Lock()
 	defer ms.mutex.Unlock()
 
 	delete(ms.channelMessages, channel)
+	delete(ms.dirty, dirtyChannel+channel)
 
 	// Remover arquivo de mensagens
 	filename := filepath.Join(ms.dataDir, fmt.Sprintf("channel_%s.json", utils.Hash(channel)))
@@ -131,10 +237,16 @@ func (ms *MessageStore) ClearChannelMessages(channel string) {
 
 // ClearPrivateMessages limpa o histórico de mensagens privadas com um peer
 func (ms *MessageStore) ClearPrivateMessages(peerID string) {
+	// A remoção é serializada com as gravações, para que uma gravação já
+	// serializada não recrie o arquivo
+	ms.writeMutex.Lock()
+	defer ms.writeMutex.Unlock()
+
 	ms.mutex.Lock()
 	defer ms.mutex.Unlock()
 
 	delete(ms.privateMessages, peerID)
+	delete(ms.dirty, dirtyPrivate+peerID)
 
 	// Remover arquivo de mensagens
 	filename := filepath.Join(ms.dataDir, fmt.Sprintf("private_%s.json", peerID))
@@ -149,7 +261,7 @@ func (ms *MessageStore) AddPendingMessage(messageID string, packet *protocol.Bit
 	ms.pendingMessages[messageID] = packet
 
 	// Salvar em background
-	go ms.savePendingMessages()
+	ms.markDirtyLocked(dirtyPending)
 }
 
 // GetPendingMessages retorna todas as mensagens pendentes
@@ -174,7 +286,70 @@ func (ms *MessageStore) RemovePendingMessage(messageID string) {
 	delete(ms.pendingMessages, messageID)
 
 	// Salvar em background
-	go ms.savePendingMessages()
+	ms.markDirtyLocked(dirtyPending)
+}
+
+// AddOutgoingMessage guarda uma mensagem composta neste nó até a sua
+// transmissão, para que seja enviada após um reinício
+func (ms *MessageStore) AddOutgoingMessage(message *protocol.BitchatMessage) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.outgoingMessages[message.ID] = message
+
+	// Salvar em background
+	ms.markDirtyLocked(dirtyOutgoing)
+}
+
+// GetOutgoingMessages retorna as mensagens aguardando transmissão, da mais
+// antiga para a mais recente
+func (ms *MessageStore) GetOutgoingMessages() []*protocol.BitchatMessage {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	messages := make([]*protocol.BitchatMessage, 0, len(ms.outgoingMessages))
+	for _, message := range ms.outgoingMessages {
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Timestamp < messages[j].Timestamp
+	})
+
+	return messages
+}
+
+// RemoveOutgoingMessage esquece uma mensagem transmitida
+func (ms *MessageStore) RemoveOutgoingMessage(messageID string) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if _, ok := ms.outgoingMessages[messageID]; !ok {
+		return
+	}
+	delete(ms.outgoingMessages, messageID)
+
+	// Salvar em background
+	ms.markDirtyLocked(dirtyOutgoing)
+}
+
+// Stats retorna o número de conversas e mensagens guardadas
+func (ms *MessageStore) Stats() StoreStats {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	stats := StoreStats{
+		Channels:         len(ms.channelMessages),
+		Conversations:    len(ms.privateMessages),
+		PendingMessages:  len(ms.pendingMessages),
+		OutgoingMessages: len(ms.outgoingMessages),
+	}
+	for _, messages := range ms.channelMessages {
+		stats.ChannelMessages += len(messages)
+	}
+	for _, messages := range ms.privateMessages {
+		stats.PrivateMessages += len(messages)
+	}
+	return stats
 }
 
 // SetMaxMessages define o número máximo de mensagens por canal/peer
@@ -225,7 +400,7 @@ func (ms *MessageStore) CleanupOldMessages() {
 	}
 
 	// Salvar alterações
-	go ms.saveAllMessages()
+	ms.markAllDirtyLocked()
 }
 
 // Métodos internos para persistência
@@ -240,20 +415,21 @@ func (ms *MessageStore) loadMessages() error {
 	for _, file := range channelFiles {
 		data, err := os.ReadFile(file)
 		if err != nil {
-			fmt.Printf("Erro ao ler arquivo %s: %v\n", file, err)
+			slog.Error("erro ao ler arquivo", "file", file, "err", err)
 			continue
 		}
 
 		var messages []*protocol.BitchatMessage
 		if err := json.Unmarshal(data, &messages); err != nil {
-			fmt.Printf("Erro ao decodificar mensagens do arquivo %s: %v\n", file, err)
+			slog.Error("erro ao decodificar mensagens", "file", file, "err", err)
 			continue
 		}
 
-		// Extrair nome do canal do nome do arquivo
-		base := filepath.Base(file)
-		channel := base[8 : len(base)-5] // Remover "channel_" e ".json"
-		ms.channelMessages[channel] = messages
+		// O nome do arquivo guarda apenas o hash do canal; o nome vem das mensagens
+		if len(messages) == 0 || messages[0].Channel == "" {
+			continue
+		}
+		ms.channelMessages[messages[0].Channel] = messages
 	}
 
 	// Carregar mensagens privadas
@@ -265,13 +441,13 @@ func (ms *MessageStore) loadMessages() error {
 	for _, file := range privateFiles {
 		data, err := os.ReadFile(file)
 		if err != nil {
-			fmt.Printf("Erro ao ler arquivo %s: %v\n", file, err)
+			slog.Error("erro ao ler arquivo", "file", file, "err", err)
 			continue
 		}
 
 		var messages []*protocol.BitchatMessage
 		if err := json.Unmarshal(data, &messages); err != nil {
-			fmt.Printf("Erro ao decodificar mensagens do arquivo %s: %v\n", file, err)
+			slog.Error("erro ao decodificar mensagens", "file", file, "err", err)
 			continue
 		}
 
@@ -286,16 +462,16 @@ func (ms *MessageStore) loadMessages() error {
 	if _, err := os.Stat(pendingFile); err == nil {
 		data, err := os.ReadFile(pendingFile)
 		if err != nil {
-			fmt.Printf("Erro ao ler arquivo de mensagens pendentes: %v\n", err)
+			slog.Error("erro ao ler arquivo de mensagens pendentes", "err", err)
 		} else {
 			var pendingData map[string][]byte
 			if err := json.Unmarshal(data, &pendingData); err != nil {
-				fmt.Printf("Erro ao decodificar mensagens pendentes: %v\n", err)
+				slog.Error("erro ao decodificar mensagens pendentes", "err", err)
 			} else {
 				for id, packetData := range pendingData {
 					packet, err := protocol.Decode(packetData)
 					if err != nil {
-						fmt.Printf("Erro ao decodificar pacote pendente %s: %v\n", id, err)
+						slog.Error("erro ao decodificar pacote pendente", "id", id, "err", err)
 						continue
 					}
 					ms.pendingMessages[id] = packet
@@ -304,110 +480,163 @@ func (ms *MessageStore) loadMessages() error {
 		}
 	}
 
+	// Carregar mensagens aguardando transmissão
+	if data, err := os.ReadFile(filepath.Join(ms.dataDir, "outgoing.json")); err == nil {
+		var messages []*protocol.BitchatMessage
+		if err := json.Unmarshal(data, &messages); err != nil {
+			slog.Error("erro ao decodificar mensagens aguardando transmissão", "err", err)
+		}
+		for _, message := range messages {
+			ms.outgoingMessages[message.ID] = message
+		}
+	}
+
 	return nil
 }
 
-func (ms *MessageStore) saveChannelMessages(channel string) {
-	ms.mutex.RLock()
-	messages, ok := ms.channelMessages[channel]
-	ms.mutex.RUnlock()
-
-	if !ok {
-		return
+// markDirtyLocked marca um arquivo como alterado e acorda a goroutine de
+// gravação. Exige ms.mutex.
+func (ms *MessageStore) markDirtyLocked(key string) {
+	ms.dirty[key] = true
+	select {
+	case ms.wake <- struct{}{}:
+	default:
 	}
+}
 
-	// Serializar mensagens
-	data, err := json.Marshal(messages)
-	if err != nil {
-		fmt.Printf("Erro ao serializar mensagens do canal %s: %v\n", channel, err)
-		return
+// markAllDirtyLocked marca todos os arquivos como alterados. Exige ms.mutex.
+func (ms *MessageStore) markAllDirtyLocked() {
+	for channel := range ms.channelMessages {
+		ms.markDirtyLocked(dirtyChannel + channel)
 	}
-
-	// Salvar em arquivo
-	filename := filepath.Join(ms.dataDir, fmt.Sprintf("channel_%s.json", utils.Hash(channel)))
-	if err := os.WriteFile(filename, data, 0600); err != nil {
-		fmt.Printf("Erro ao salvar mensagens do canal %s: %v\n", channel, err)
+	for peerID := range ms.privateMessages {
+		ms.markDirtyLocked(dirtyPrivate + peerID)
 	}
+	ms.markDirtyLocked(dirtyPending)
+	ms.markDirtyLocked(dirtyOutgoing)
 }
 
-func (ms *MessageStore) savePrivateMessages(peerID string) {
-	ms.mutex.RLock()
-	messages, ok := ms.privateMessages[peerID]
-	ms.mutex.RUnlock()
-
-	if !ok {
-		return
-	}
-
-	// Serializar mensagens
-	data, err := json.Marshal(messages)
-	if err != nil {
-		fmt.Printf("Erro ao serializar mensagens privadas com %s: %v\n", peerID, err)
-		return
+// writeLoop grava os arquivos alterados. Após o primeiro aviso, espera
+// writeDelay para que uma rajada de alterações resulte em uma só gravação.
+// Ao ser encerrada por Close, grava o que estiver pendente e termina.
+func (ms *MessageStore) writeLoop() {
+	defer close(ms.stopped)
+
+	for {
+		select {
+		case <-ms.wake:
+			select {
+			case <-time.After(ms.writeDelay):
+			case <-ms.done:
+				ms.writeDirty()
+				return
+			}
+			ms.writeDirty()
+		case <-ms.done:
+			ms.writeDirty()
+			return
+		}
 	}
+}
 
-	// Salvar em arquivo
-	filename := filepath.Join(ms.dataDir, fmt.Sprintf("private_%s.json", peerID))
-	if err := os.WriteFile(filename, data, 0600); err != nil {
-		fmt.Printf("Erro ao salvar mensagens privadas com %s: %v\n", peerID, err)
-	}
+// storeFile é o conteúdo serializado de um arquivo a gravar
+type storeFile struct {
+	name string
+	data []byte
 }
 
-func (ms *MessageStore) savePendingMessages() {
-	ms.mutex.RLock()
-	pendingMessages := ms.pendingMessages
-	ms.mutex.RUnlock()
+// writeDirty grava os arquivos alterados. O conteúdo é serializado sob o
+// mutex, em um retrato consistente, e gravado fora dele.
+func (ms *MessageStore) writeDirty() {
+	ms.writeMutex.Lock()
+	defer ms.writeMutex.Unlock()
 
-	// Serializar pacotes pendentes
-	pendingData := make(map[string][]byte)
-	for id, packet := range pendingMessages {
-		data, err := protocol.Encode(packet)
-		if err != nil {
-			fmt.Printf("Erro ao codificar pacote pendente %s: %v\n", id, err)
-			continue
+	ms.mutex.Lock()
+	dirty := ms.dirty
+	ms.dirty = make(map[string]bool)
+	files := make([]storeFile, 0, len(dirty))
+	for key := range dirty {
+		if file, ok := ms.serializeLocked(key); ok {
+			files = append(files, file)
 		}
-		pendingData[id] = data
 	}
+	ms.mutex.Unlock()
 
-	// Serializar mapa
-	data, err := json.Marshal(pendingData)
-	if err != nil {
-		fmt.Printf("Erro ao serializar mensagens pendentes: %v\n", err)
-		return
-	}
-
-	// Salvar em arquivo
-	filename := filepath.Join(ms.dataDir, "pending.json")
-	if err := os.WriteFile(filename, data, 0600); err != nil {
-		fmt.Printf("Erro ao salvar mensagens pendentes: %v\n", err)
+	for _, file := range files {
+		if err := os.WriteFile(file.name, file.data, 0600); err != nil {
+			slog.Error("erro ao salvar mensagens", "file", file.name, "err", err)
+		}
 	}
 }
 
-func (ms *MessageStore) saveAllMessages() {
-	// Salvar mensagens de canais
-	ms.mutex.RLock()
-	channels := make([]string, 0, len(ms.channelMessages))
-	for channel := range ms.channelMessages {
-		channels = append(channels, channel)
-	}
-	ms.mutex.RUnlock()
+// serializeLocked serializa o conteúdo de um arquivo alterado. Retorna false
+// se não há o que gravar. Exige ms.mutex.
+func (ms *MessageStore) serializeLocked(key string) (storeFile, bool) {
+	var name string
+	var value interface{}
+
+	switch {
+	case key == dirtyPending:
+		// Serializar pacotes pendentes
+		pendingData := make(map[string][]byte, len(ms.pendingMessages))
+		for id, packet := range ms.pendingMessages {
+			data, err := protocol.Encode(packet)
+			if err != nil {
+				slog.Error("erro ao codificar pacote pendente", "id", id, "err", err)
+				continue
+			}
+			pendingData[id] = data
+		}
+		name, value = "pending.json", pendingData
 
-	for _, channel := range channels {
-		ms.saveChannelMessages(channel)
-	}
+	case key == dirtyOutgoing:
+		messages := make([]*protocol.BitchatMessage, 0, len(ms.outgoingMessages))
+		for _, message := range ms.outgoingMessages {
+			messages = append(messages, message)
+		}
+		sort.Slice(messages, func(i, j int) bool {
+			return messages[i].Timestamp < messages[j].Timestamp
+		})
+		name, value = "outgoing.json", messages
+
+	case strings.HasPrefix(key, dirtyChannel):
+		channel := strings.TrimPrefix(key, dirtyChannel)
+		messages, ok := ms.channelMessages[channel]
+		if !ok {
+			return storeFile{}, false
+		}
+		name, value = fmt.Sprintf("channel_%s.json", utils.Hash(channel)), messages
 
-	// Salvar mensagens privadas
-	ms.mutex.RLock()
-	peers := make([]string, 0, len(ms.privateMessages))
-	for peerID := range ms.privateMessages {
-		peers = append(peers, peerID)
+	case strings.HasPrefix(key, dirtyPrivate):
+		peerID := strings.TrimPrefix(key, dirtyPrivate)
+		messages, ok := ms.privateMessages[peerID]
+		if !ok {
+			return storeFile{}, false
+		}
+		name, value = fmt.Sprintf("private_%s.json", peerID), messages
+
+	default:
+		return storeFile{}, false
 	}
-	ms.mutex.RUnlock()
 
-	for _, peerID := range peers {
-		ms.savePrivateMessages(peerID)
+	data, err := json.Marshal(value)
+	if err != nil {
+		slog.Error("erro ao serializar mensagens", "file", name, "err", err)
+		return storeFile{}, false
 	}
+	return storeFile{name: filepath.Join(ms.dataDir, name), data: data}, true
+}
+
+// Flush grava em disco as alterações ainda não gravadas, sem esperar a
+// goroutine de gravação
+func (ms *MessageStore) Flush() {
+	ms.writeDirty()
+}
 
-	// Salvar mensagens pendentes
-	ms.savePendingMessages()
+// Close encerra a goroutine de gravação após gravar as alterações pendentes.
+// Usado ao encerrar o programa; alterações posteriores só são gravadas por
+// Flush.
+func (ms *MessageStore) Close() {
+	ms.closeOnce.Do(func() { close(ms.done) })
+	<-ms.stopped
 }
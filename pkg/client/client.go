@@ -0,0 +1,291 @@
+// Package client é a API para escrever bots e interfaces alternativas sobre
+// o Bitchat sem importar pacotes internos. O cliente conversa com o
+// executável em modo --json: comandos vão pela entrada padrão e eventos
+// voltam pela saída padrão, um objeto JSON por linha.
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrClosed é retornado quando a conexão com o Bitchat foi encerrada
+var ErrClosed = errors.New("conexão com o bitchat encerrada")
+
+// Message é uma mensagem recebida em um canal ou em conversa privada
+type Message struct {
+	ID      string
+	PeerID  string
+	Sender  string
+	Channel string // Vazio em mensagens privadas
+	Private bool
+	Content string
+	Hops    int
+	Time    time.Time
+}
+
+// Peer é um peer ativo na malha
+type Peer struct {
+	ID   string
+	Name string
+}
+
+// event é um evento do modo --json
+type event struct {
+	Event     string `json:"event"`
+	Time      int64  `json:"time"`
+	PeerID    string `json:"peer_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
+	Sender    string `json:"sender,omitempty"`
+	Channel   string `json:"channel,omitempty"`
+	Private   bool   `json:"private,omitempty"`
+	Content   string `json:"content,omitempty"`
+	Hops      int    `json:"hops,omitempty"`
+	Ref       string `json:"ref,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// command é um comando do modo --json
+type command struct {
+	Type    string `json:"type"`
+	Ref     string `json:"ref,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	To      string `json:"to,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// call acompanha um comando aguardando resposta
+type call struct {
+	messageID string
+	peers     []Peer
+	err       error
+	done      chan struct{}
+}
+
+// Client é uma conexão com o Bitchat. É seguro para uso concorrente.
+type Client struct {
+	encoder *json.Encoder
+	writer  io.Writer
+	cmd     *exec.Cmd
+
+	mutex     sync.Mutex
+	nextRef   int
+	pending   map[string]*call
+	onMessage []func(Message)
+
+	closed chan struct{}
+	err    error
+}
+
+// Connect inicia o executável do Bitchat em modo --json, com os argumentos
+// adicionais informados, e conecta a ele. O texto destinado ao usuário que
+// o Bitchat escreve na saída de erro é descartado.
+func Connect(ctx context.Context, binary string, args ...string) (*Client, error) {
+	cmd := exec.CommandContext(ctx, binary, append([]string{"--json"}, args...)...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao bitchat: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao bitchat: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("erro ao iniciar o bitchat: %v", err)
+	}
+
+	c := New(stdout, stdin)
+	c.cmd = cmd
+	return c, nil
+}
+
+// New cria um cliente sobre uma conexão já estabelecida com o modo --json:
+// r recebe os eventos e w, os comandos
+func New(r io.Reader, w io.Writer) *Client {
+	c := &Client{
+		encoder: json.NewEncoder(w),
+		writer:  w,
+		pending: make(map[string]*call),
+		closed:  make(chan struct{}),
+	}
+	go c.readLoop(r)
+	return c
+}
+
+// OnMessage registra uma função chamada a cada mensagem recebida. As funções
+// são chamadas em ordem, na goroutine de leitura, e não devem bloquear.
+func (c *Client) OnMessage(fn func(Message)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.onMessage = append(c.onMessage, fn)
+}
+
+// SendChannel envia uma mensagem a um canal e retorna o ID da mensagem
+func (c *Client) SendChannel(ctx context.Context, channel, content string) (string, error) {
+	result, err := c.request(ctx, command{Type: "message", Channel: channel, Content: content})
+	if err != nil {
+		return "", err
+	}
+	return result.messageID, nil
+}
+
+// SendPrivate envia uma mensagem privada ao peer com o apelido informado e
+// retorna o ID da mensagem
+func (c *Client) SendPrivate(ctx context.Context, nickname, content string) (string, error) {
+	result, err := c.request(ctx, command{Type: "message", To: nickname, Content: content})
+	if err != nil {
+		return "", err
+	}
+	return result.messageID, nil
+}
+
+// Peers retorna os peers ativos na malha
+func (c *Client) Peers(ctx context.Context) ([]Peer, error) {
+	result, err := c.request(ctx, command{Type: "peers"})
+	if err != nil {
+		return nil, err
+	}
+	return result.peers, nil
+}
+
+// Done é fechado quando a conexão é encerrada
+func (c *Client) Done() <-chan struct{} {
+	return c.closed
+}
+
+// Err retorna o motivo do encerramento da conexão, ou nil enquanto ativa
+func (c *Client) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.err
+}
+
+// Close encerra a conexão. Se o Bitchat foi iniciado por Connect, pede que
+// ele saia e aguarda o encerramento.
+func (c *Client) Close() error {
+	if closer, ok := c.writer.(io.Closer); ok {
+		closer.Close()
+	}
+	if c.cmd == nil {
+		return nil
+	}
+	c.cmd.Process.Signal(os.Interrupt)
+	<-c.closed
+	return c.cmd.Wait()
+}
+
+// request envia um comando e aguarda a resposta com a mesma referência
+func (c *Client) request(ctx context.Context, cmd command) (*call, error) {
+	pending := &call{done: make(chan struct{})}
+
+	c.mutex.Lock()
+	if c.err != nil {
+		c.mutex.Unlock()
+		return nil, c.err
+	}
+	c.nextRef++
+	cmd.Ref = strconv.Itoa(c.nextRef)
+	c.pending[cmd.Ref] = pending
+	err := c.encoder.Encode(cmd)
+	if err != nil {
+		delete(c.pending, cmd.Ref)
+	}
+	c.mutex.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao enviar comando: %v", err)
+	}
+
+	select {
+	case <-pending.done:
+		return pending, pending.err
+	case <-ctx.Done():
+		c.mutex.Lock()
+		delete(c.pending, cmd.Ref)
+		c.mutex.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// readLoop lê os eventos até o fim da conexão
+func (c *Client) readLoop(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue
+		}
+		c.handleEvent(&ev)
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = ErrClosed
+	}
+	c.mutex.Lock()
+	c.err = err
+	for ref, pending := range c.pending {
+		pending.err = err
+		close(pending.done)
+		delete(c.pending, ref)
+	}
+	c.mutex.Unlock()
+	close(c.closed)
+}
+
+// handleEvent entrega um evento a quem o aguarda
+func (c *Client) handleEvent(ev *event) {
+	if ev.Event == "message" {
+		message := Message{
+			ID:      ev.MessageID,
+			PeerID:  ev.PeerID,
+			Sender:  ev.Sender,
+			Channel: ev.Channel,
+			Private: ev.Private,
+			Content: ev.Content,
+			Hops:    ev.Hops,
+			Time:    time.UnixMilli(ev.Time),
+		}
+		c.mutex.Lock()
+		handlers := c.onMessage
+		c.mutex.Unlock()
+		for _, fn := range handlers {
+			fn(message)
+		}
+		return
+	}
+	if ev.Ref == "" {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	pending, ok := c.pending[ev.Ref]
+	if !ok {
+		return
+	}
+	switch ev.Event {
+	case "peer":
+		pending.peers = append(pending.peers, Peer{ID: ev.PeerID, Name: ev.Name})
+		return
+	case "sent":
+		pending.messageID = ev.MessageID
+	case "error":
+		pending.err = errors.New(ev.Error)
+	case "done":
+	default:
+		return
+	}
+	delete(c.pending, ev.Ref)
+	close(pending.done)
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+// fakeDaemon responde aos comandos como o modo --json do Bitchat
+func fakeDaemon(t *testing.T) (*Client, *json.Encoder, func()) {
+	commandsReader, commandsWriter := io.Pipe()
+	eventsReader, eventsWriter := io.Pipe()
+	events := json.NewEncoder(eventsWriter)
+
+	go func() {
+		scanner := bufio.NewScanner(commandsReader)
+		for scanner.Scan() {
+			var cmd command
+			if err := json.Unmarshal(scanner.Bytes(), &cmd); err != nil {
+				t.Errorf("Comando inválido: %v", err)
+				continue
+			}
+			switch {
+			case cmd.Type == "peers":
+				events.Encode(event{Event: "peer", Ref: cmd.Ref, PeerID: "01", Name: "alice"})
+				events.Encode(event{Event: "peer", Ref: cmd.Ref, PeerID: "02", Name: "bob"})
+				events.Encode(event{Event: "done", Ref: cmd.Ref})
+			case cmd.To == "ninguem":
+				events.Encode(event{Event: "error", Ref: cmd.Ref, Error: "usuário ninguem não encontrado"})
+			default:
+				events.Encode(event{Event: "sent", Ref: cmd.Ref, MessageID: "id-" + cmd.Content})
+			}
+		}
+	}()
+
+	c := New(eventsReader, commandsWriter)
+	return c, events, func() { eventsWriter.Close() }
+}
+
+func TestClient(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("Envio e erros", func(t *testing.T) {
+		c, _, stop := fakeDaemon(t)
+		defer stop()
+
+		id, err := c.SendChannel(ctx, "#geral", "oi")
+		if err != nil || id != "id-oi" {
+			t.Fatalf("Envio ao canal: %q, %v", id, err)
+		}
+		if _, err := c.SendPrivate(ctx, "ninguem", "oi"); err == nil {
+			t.Error("Envio a peer desconhecido deveria falhar")
+		}
+	})
+
+	t.Run("Peers", func(t *testing.T) {
+		c, _, stop := fakeDaemon(t)
+		defer stop()
+
+		peers, err := c.Peers(ctx)
+		if err != nil || len(peers) != 2 || peers[0].Name != "alice" || peers[1].ID != "02" {
+			t.Fatalf("Peers inesperados: %+v, %v", peers, err)
+		}
+	})
+
+	t.Run("Mensagens recebidas", func(t *testing.T) {
+		c, events, stop := fakeDaemon(t)
+		defer stop()
+
+		received := make(chan Message, 1)
+		c.OnMessage(func(m Message) { received <- m })
+		events.Encode(event{Event: "message", Sender: "alice", Channel: "#geral", Content: "olá", Time: 1000})
+
+		select {
+		case m := <-received:
+			if m.Sender != "alice" || m.Channel != "#geral" || m.Content != "olá" || m.Time.UnixMilli() != 1000 {
+				t.Errorf("Mensagem inesperada: %+v", m)
+			}
+		case <-ctx.Done():
+			t.Fatal("Mensagem não recebida")
+		}
+	})
+
+	t.Run("Conexão encerrada", func(t *testing.T) {
+		c, _, stop := fakeDaemon(t)
+		stop()
+
+		<-c.Done()
+		if _, err := c.SendChannel(ctx, "#geral", "oi"); err != ErrClosed {
+			t.Errorf("Esperado ErrClosed, obtido %v", err)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package mesh
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+const (
+	// DefaultAntiEntropyInterval é o intervalo entre rodadas de anti-entropia
+	DefaultAntiEntropyInterval = 30 * time.Second
+
+	// DefaultDigestRanges é o número de faixas em um resumo de anti-entropia
+	DefaultDigestRanges = 16
+)
+
+// BuildDigestRanges divide o espaço de resumos em até maxRanges faixas
+// contíguas com quantidades semelhantes de mensagens. As faixas cobrem todo o
+// espaço, de modo que qualquer resumo do vizinho cai em exatamente uma delas.
+func BuildDigestRanges(digests []uint64, maxRanges int) []protocol.DigestRange {
+	if maxRanges <= 0 {
+		maxRanges = DefaultDigestRanges
+	}
+
+	sorted := append([]uint64(nil), digests...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	sorted = uniqueDigests(sorted)
+
+	perRange := (len(sorted) + maxRanges - 1) / maxRanges
+	if perRange == 0 {
+		perRange = 1
+	}
+
+	ranges := []protocol.DigestRange{{Start: 0, End: math.MaxUint64}}
+	for i, digest := range sorted {
+		current := &ranges[len(ranges)-1]
+		if int(current.Count) == perRange {
+			// Nova faixa começa neste resumo; a anterior termina logo antes
+			current.End = digest - 1
+			ranges = append(ranges, protocol.DigestRange{Start: digest, End: math.MaxUint64})
+			current = &ranges[len(ranges)-1]
+		}
+		current.Count++
+		current.Fingerprint ^= sorted[i]
+	}
+
+	return ranges
+}
+
+// MismatchedRanges compara as faixas recebidas de um vizinho com os resumos
+// locais e retorna, com a contagem e impressão digital locais, as faixas em
+// que os dois lados divergem
+func MismatchedRanges(remote []protocol.DigestRange, local []uint64) []protocol.DigestRange {
+	sorted := append([]uint64(nil), local...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	sorted = uniqueDigests(sorted)
+
+	var mismatched []protocol.DigestRange
+	for _, r := range remote {
+		if r.End < r.Start {
+			continue
+		}
+
+		localRange := protocol.DigestRange{Start: r.Start, End: r.End}
+		first := sort.Search(len(sorted), func(i int) bool { return sorted[i] >= r.Start })
+		for _, digest := range sorted[first:] {
+			if digest > r.End {
+				break
+			}
+			localRange.Count++
+			localRange.Fingerprint ^= digest
+		}
+
+		if localRange.Count != r.Count || localRange.Fingerprint != r.Fingerprint {
+			mismatched = append(mismatched, localRange)
+		}
+	}
+
+	return mismatched
+}
+
+// DigestInRanges verifica se um resumo pertence a alguma das faixas.
+// Uma lista vazia de faixas abrange todo o espaço.
+func DigestInRanges(digest uint64, ranges []protocol.DigestRange) bool {
+	if len(ranges) == 0 {
+		return true
+	}
+	for _, r := range ranges {
+		if digest >= r.Start && digest <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueDigests remove resumos repetidos de uma lista ordenada
+func uniqueDigests(sorted []uint64) []uint64 {
+	if len(sorted) < 2 {
+		return sorted
+	}
+	unique := sorted[:1]
+	for _, digest := range sorted[1:] {
+		if digest != unique[len(unique)-1] {
+			unique = append(unique, digest)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,50 @@
+package mesh
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAntiEntropyRanges(t *testing.T) {
+	var local []uint64
+	for i := 0; i < 50; i++ {
+		local = append(local, MessageDigest(fmt.Sprintf("msg-%d", i)))
+	}
+
+	ranges := BuildDigestRanges(local, 8)
+	if len(ranges) > 8 {
+		t.Fatalf("Esperadas no máximo 8 faixas, obtido %d", len(ranges))
+	}
+
+	// As faixas devem cobrir todo o espaço sem lacunas
+	if ranges[0].Start != 0 || ranges[len(ranges)-1].End != ^uint64(0) {
+		t.Error("Faixas deveriam cobrir do início ao fim do espaço de resumos")
+	}
+	total := 0
+	for i, r := range ranges {
+		total += int(r.Count)
+		if i > 0 && r.Start != ranges[i-1].End+1 {
+			t.Errorf("Lacuna entre as faixas %d e %d", i-1, i)
+		}
+	}
+	if total != len(local) {
+		t.Errorf("Faixas deveriam somar %d resumos, obtido %d", len(local), total)
+	}
+
+	if mismatched := MismatchedRanges(ranges, local); len(mismatched) != 0 {
+		t.Errorf("Conjuntos iguais não deveriam divergir, obtido %d faixas", len(mismatched))
+	}
+
+	// Vizinho sem uma das mensagens diverge em exatamente uma faixa
+	missing := local[17]
+	remote := append(append([]uint64(nil), local[:17]...), local[18:]...)
+	mismatched := MismatchedRanges(BuildDigestRanges(remote, 8), local)
+	if len(mismatched) != 1 || !DigestInRanges(missing, mismatched) {
+		t.Fatalf("Esperada uma faixa divergente contendo a mensagem ausente, obtido %v", mismatched)
+	}
+
+	// Resumo vazio diverge de qualquer conjunto local não vazio
+	if mismatched := MismatchedRanges(BuildDigestRanges(nil, 8), local); len(mismatched) != 1 || mismatched[0].Count != 50 {
+		t.Errorf("Resumo vazio deveria divergir em uma faixa com 50 mensagens, obtido %v", mismatched)
+	}
+}
@@ -0,0 +1,185 @@
+package mesh
+
+import (
+	"sync"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+const (
+	// DefaultBenchCount é o número de cargas de teste de uma medição
+	DefaultBenchCount = 50
+
+	// MaxBenchCount mantém a medição abaixo do limite de inundação dos peers
+	MaxBenchCount = DefaultFloodLimit - 20
+
+	// DefaultBenchPayloadSize é o tamanho padrão, em bytes, de cada carga
+	DefaultBenchPayloadSize = 180
+
+	// MaxBenchPayloadSize é o maior tamanho de carga aceito
+	MaxBenchPayloadSize = 4096
+
+	// DefaultBenchInterval é o intervalo entre cargas consecutivas
+	DefaultBenchInterval = 20 * time.Millisecond
+
+	// DefaultBenchTimeout é a espera pelas confirmações após a última carga
+	DefaultBenchTimeout = 5 * time.Second
+)
+
+// BenchResult resume uma medição de desempenho do enlace com um peer
+type BenchResult struct {
+	PeerID      string
+	Sent        int
+	Received    int
+	PayloadSize int
+	Duration    time.Duration // Do envio da primeira carga à última confirmação
+	MinRTT      time.Duration
+	AvgRTT      time.Duration
+	MaxRTT      time.Duration
+}
+
+// Loss retorna a fração de cargas sem confirmação (0-1)
+func (br *BenchResult) Loss() float64 {
+	if br.Sent == 0 {
+		return 0
+	}
+	return float64(br.Sent-br.Received) / float64(br.Sent)
+}
+
+// Goodput retorna os bytes de carga confirmados por segundo
+func (br *BenchResult) Goodput() float64 {
+	if br.Duration <= 0 {
+		return 0
+	}
+	return float64(br.Received*br.PayloadSize) / br.Duration.Seconds()
+}
+
+// Benchmark acompanha uma sessão de medição de desempenho: gera as cargas de
+// teste, casa as confirmações e calcula goodput, RTT e perda
+type Benchmark struct {
+	sessionID uint32
+	peerID    string
+	count     int
+	size      int
+
+	sentAt   map[uint32]time.Time
+	rtts     []time.Duration
+	first    time.Time
+	last     time.Time
+	done     chan struct{}
+	finished bool
+
+	mutex sync.Mutex
+}
+
+// NewBenchmark cria uma sessão de medição com count cargas de size bytes.
+// Valores fora dos limites são ajustados aos padrões e máximos.
+func NewBenchmark(sessionID uint32, peerID string, count, size int) *Benchmark {
+	if count <= 0 {
+		count = DefaultBenchCount
+	} else if count > MaxBenchCount {
+		count = MaxBenchCount
+	}
+	if size <= 0 {
+		size = DefaultBenchPayloadSize
+	} else if size > MaxBenchPayloadSize {
+		size = MaxBenchPayloadSize
+	}
+
+	return &Benchmark{
+		sessionID: sessionID,
+		peerID:    peerID,
+		count:     count,
+		size:      size,
+		sentAt:    make(map[uint32]time.Time),
+		done:      make(chan struct{}),
+	}
+}
+
+// SessionID retorna o identificador da sessão
+func (b *Benchmark) SessionID() uint32 {
+	return b.sessionID
+}
+
+// Count retorna o número de cargas da sessão
+func (b *Benchmark) Count() int {
+	return b.count
+}
+
+// Probe gera a carga de teste de número seq e registra o momento do envio
+func (b *Benchmark) Probe(seq uint32, now time.Time) *protocol.BenchProbe {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.first.IsZero() {
+		b.first = now
+	}
+	b.sentAt[seq] = now
+
+	return &protocol.BenchProbe{
+		SessionID: b.sessionID,
+		Sequence:  seq,
+		Padding:   make([]byte, b.size),
+	}
+}
+
+// RecordReply registra a confirmação de uma carga. Retorna false para
+// confirmações de outra sessão, desconhecidas ou repetidas.
+func (b *Benchmark) RecordReply(reply *protocol.BenchProbe, now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if reply.SessionID != b.sessionID {
+		return false
+	}
+	sentAt, ok := b.sentAt[reply.Sequence]
+	if !ok {
+		return false
+	}
+	delete(b.sentAt, reply.Sequence)
+
+	b.rtts = append(b.rtts, now.Sub(sentAt))
+	b.last = now
+	if len(b.rtts) == b.count && !b.finished {
+		b.finished = true
+		close(b.done)
+	}
+	return true
+}
+
+// Done é fechado quando todas as cargas forem confirmadas
+func (b *Benchmark) Done() <-chan struct{} {
+	return b.done
+}
+
+// Result calcula o resultado com as confirmações recebidas até agora
+func (b *Benchmark) Result() *BenchResult {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	result := &BenchResult{
+		PeerID:      b.peerID,
+		Sent:        len(b.sentAt) + len(b.rtts),
+		Received:    len(b.rtts),
+		PayloadSize: b.size,
+	}
+	if len(b.rtts) == 0 {
+		return result
+	}
+
+	result.Duration = b.last.Sub(b.first)
+	var total time.Duration
+	result.MinRTT = b.rtts[0]
+	for _, rtt := range b.rtts {
+		total += rtt
+		if rtt < result.MinRTT {
+			result.MinRTT = rtt
+		}
+		if rtt > result.MaxRTT {
+			result.MaxRTT = rtt
+		}
+	}
+	result.AvgRTT = total / time.Duration(len(b.rtts))
+	return result
+}
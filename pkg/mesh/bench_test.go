@@ -0,0 +1,91 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+func TestBenchmark(t *testing.T) {
+	t.Run("Goodput, RTT e perda", func(t *testing.T) {
+		b := NewBenchmark(7, "peer", 4, 100)
+		start := time.Now()
+
+		for seq := uint32(0); seq < 4; seq++ {
+			probe := b.Probe(seq, start.Add(time.Duration(seq)*100*time.Millisecond))
+			if len(probe.Padding) != 100 || probe.SessionID != 7 {
+				t.Fatalf("Carga inesperada: %+v", probe)
+			}
+		}
+
+		// Três confirmações, a última um segundo após o início
+		b.RecordReply(&protocol.BenchProbe{SessionID: 7, Sequence: 0}, start.Add(50*time.Millisecond))
+		b.RecordReply(&protocol.BenchProbe{SessionID: 7, Sequence: 1}, start.Add(250*time.Millisecond))
+		b.RecordReply(&protocol.BenchProbe{SessionID: 7, Sequence: 2}, start.Add(time.Second))
+
+		result := b.Result()
+		if result.Sent != 4 || result.Received != 3 {
+			t.Fatalf("Contagem inesperada: %+v", result)
+		}
+		if result.Loss() != 0.25 {
+			t.Errorf("Perda esperada de 25%%, obtido %v", result.Loss())
+		}
+		if result.Goodput() != 300 {
+			t.Errorf("Goodput esperado de 300 B/s, obtido %v", result.Goodput())
+		}
+		if result.MinRTT != 50*time.Millisecond || result.MaxRTT != 800*time.Millisecond {
+			t.Errorf("RTT inesperado: min=%v max=%v", result.MinRTT, result.MaxRTT)
+		}
+	})
+
+	t.Run("Confirmações inválidas", func(t *testing.T) {
+		b := NewBenchmark(1, "peer", 2, 10)
+		now := time.Now()
+		b.Probe(0, now)
+
+		if b.RecordReply(&protocol.BenchProbe{SessionID: 2, Sequence: 0}, now) {
+			t.Error("Confirmação de outra sessão deveria ser ignorada")
+		}
+		if b.RecordReply(&protocol.BenchProbe{SessionID: 1, Sequence: 5}, now) {
+			t.Error("Confirmação desconhecida deveria ser ignorada")
+		}
+		if !b.RecordReply(&protocol.BenchProbe{SessionID: 1, Sequence: 0}, now) {
+			t.Error("Confirmação válida deveria ser registrada")
+		}
+		if b.RecordReply(&protocol.BenchProbe{SessionID: 1, Sequence: 0}, now) {
+			t.Error("Confirmação repetida deveria ser ignorada")
+		}
+	})
+
+	t.Run("Conclusão da sessão", func(t *testing.T) {
+		b := NewBenchmark(1, "peer", 2, 10)
+		now := time.Now()
+		b.Probe(0, now)
+		b.Probe(1, now)
+
+		b.RecordReply(&protocol.BenchProbe{SessionID: 1, Sequence: 0}, now)
+		select {
+		case <-b.Done():
+			t.Fatal("Sessão não deveria estar concluída")
+		default:
+		}
+
+		b.RecordReply(&protocol.BenchProbe{SessionID: 1, Sequence: 1}, now)
+		select {
+		case <-b.Done():
+		default:
+			t.Error("Sessão deveria estar concluída")
+		}
+	})
+
+	t.Run("Limites de quantidade e tamanho", func(t *testing.T) {
+		b := NewBenchmark(1, "peer", 1000, 0)
+		if b.Count() != MaxBenchCount {
+			t.Errorf("Quantidade deveria ser limitada, obtido %d", b.Count())
+		}
+		if probe := b.Probe(0, time.Now()); len(probe.Padding) != DefaultBenchPayloadSize {
+			t.Errorf("Tamanho padrão esperado, obtido %d", len(probe.Padding))
+		}
+	})
+}
@@ -0,0 +1,485 @@
+package mesh
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTargetConnections é o número de enlaces simultâneos mantidos
+	DefaultTargetConnections = 4
+
+	// DefaultLinkIdleTimeout é o tempo sem tráfego após o qual um enlace é encerrado
+	DefaultLinkIdleTimeout = 2 * time.Minute
+
+	// SignalSwapMargin é a vantagem, em dB, que um candidato precisa ter sobre
+	// o enlace central mais fraco para substituí-lo
+	SignalSwapMargin = 10
+
+	// MinLinkAgeForSwap é o tempo mínimo de um enlace antes de ser substituído,
+	// evitando trocas sucessivas com leituras de RSSI ruidosas
+	MinLinkAgeForSwap = 30 * time.Second
+
+	// ReconnectBackoffBase é a espera após a primeira falha de conexão com um
+	// dispositivo; cada falha seguida dobra a espera até ReconnectBackoffMax
+	ReconnectBackoffBase = 2 * time.Second
+	ReconnectBackoffMax  = 5 * time.Minute
+
+	// StableLinkDuration é o tempo que um enlace precisa durar para que sua
+	// queda não conte como falha de conexão
+	StableLinkDuration = 30 * time.Second
+
+	// ControllerLimitHold é por quanto tempo o teto de conexões informado pelo
+	// controlador é respeitado antes de uma nova tentativa acima dele
+	ControllerLimitHold = 5 * time.Minute
+
+	// signalSmoothing é o peso de cada nova leitura na média de RSSI
+	signalSmoothing = 0.3
+)
+
+// LinkRole indica o papel BLE deste nó em um enlace
+type LinkRole int
+
+const (
+	LinkRoleCentral    LinkRole = iota // Este nó iniciou a conexão
+	LinkRolePeripheral                 // O peer conectou-se a este nó
+)
+
+// String retorna o nome do papel
+func (r LinkRole) String() string {
+	if r == LinkRolePeripheral {
+		return "peripheral"
+	}
+	return "central"
+}
+
+// signalEstimate acompanha a média móvel do RSSI e sua variação
+type signalEstimate struct {
+	mean      float64
+	deviation float64
+	samples   int
+}
+
+// add incorpora uma leitura de RSSI
+func (se *signalEstimate) add(rssi int) {
+	value := float64(rssi)
+	if se.samples == 0 {
+		se.mean = value
+	} else {
+		diff := value - se.mean
+		if diff < 0 {
+			diff = -diff
+		}
+		se.deviation += signalSmoothing * (diff - se.deviation)
+		se.mean += signalSmoothing * (value - se.mean)
+	}
+	se.samples++
+}
+
+// score retorna a qualidade estimada do sinal: sinais fortes e estáveis
+// pontuam mais que sinais igualmente fortes porém oscilantes
+func (se *signalEstimate) score() float64 {
+	return se.mean - se.deviation
+}
+
+// linkCandidate é um dispositivo visível ainda não conectado
+type linkCandidate struct {
+	signal   signalEstimate
+	lastSeen time.Time
+}
+
+// Link descreve um enlace ativo
+type Link struct {
+	DeviceID     string
+	Role         LinkRole
+	RSSI         int // Média móvel do RSSI (0 se desconhecido)
+	ConnectedAt  time.Time
+	LastActivity time.Time
+
+	signal signalEstimate
+}
+
+// reconnectBackoff acompanha as falhas seguidas de conexão com um dispositivo
+type reconnectBackoff struct {
+	failures int
+	retryAt  time.Time
+}
+
+// ConnectionPlan é o resultado de uma avaliação do gerenciador de conexões
+type ConnectionPlan struct {
+	Dial       []string // Dispositivos a conectar como central, do mais forte ao mais fraco
+	Disconnect []string // Enlaces a encerrar
+	Accept     bool     // Há vagas reservadas para conexões recebidas como periférico
+	Queued     []string // Candidatos aguardando uma vaga central, do mais forte ao mais fraco
+}
+
+// ConnectionManager mantém um número alvo de enlaces simultâneos. As vagas
+// livres são divididas alternadamente entre os papéis central e periférico,
+// para que a malha não dependa de todos os nós discarem ao mesmo tempo; as
+// vagas centrais são preenchidas pelos candidatos de sinal mais forte e
+// estável e enlaces ociosos são encerrados para liberar espaço. Sem vagas,
+// um enlace central fraco é trocado por um candidato bem mais forte.
+// Dispositivos que falham ao conectar ou derrubam o enlace logo após
+// conectar só voltam a ser discados após uma espera exponencial.
+//
+// O número de enlaces centrais pode ser limitado abaixo do alvo e, quando o
+// controlador recusa uma conexão por falta de recursos, os candidatos
+// excedentes ficam na fila até uma vaga ser liberada. Candidatos preferidos,
+// como peers conhecidos reconhecidos pelo anúncio, são discados antes dos
+// demais.
+type ConnectionManager struct {
+	target      int
+	idleTimeout time.Duration
+	maxCentrals int // Zero para limitar apenas pelo alvo
+
+	controllerLimit      int       // Enlaces suportados segundo a última recusa do controlador
+	controllerLimitUntil time.Time // Fim da validade de controllerLimit
+
+	candidates map[string]*linkCandidate
+	links      map[string]*Link
+	backoff    map[string]*reconnectBackoff
+	preferred  map[string]bool // Dispositivos discados antes dos demais candidatos
+
+	mutex sync.Mutex
+}
+
+// NewConnectionManager cria um gerenciador de conexões.
+// Valores não positivos usam os padrões.
+func NewConnectionManager(target int, idleTimeout time.Duration) *ConnectionManager {
+	if target <= 0 {
+		target = DefaultTargetConnections
+	}
+	if idleTimeout <= 0 {
+		idleTimeout = DefaultLinkIdleTimeout
+	}
+
+	return &ConnectionManager{
+		target:      target,
+		idleTimeout: idleTimeout,
+		candidates:  make(map[string]*linkCandidate),
+		links:       make(map[string]*Link),
+		backoff:     make(map[string]*reconnectBackoff),
+		preferred:   make(map[string]bool),
+	}
+}
+
+// SetMaxCentrals limita os enlaces simultâneos em que este nó é central.
+// Zero ou negativo remove o limite, restando apenas o alvo de conexões.
+func (cm *ConnectionManager) SetMaxCentrals(max int) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	if max < 0 {
+		max = 0
+	}
+	cm.maxCentrals = max
+}
+
+// MaxCentrals retorna o limite de enlaces centrais (zero se não houver)
+func (cm *ConnectionManager) MaxCentrals() int {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	return cm.maxCentrals
+}
+
+// Observe registra um dispositivo Bitchat visível e seu RSSI. Leituras de
+// dispositivos já conectados atualizam o sinal do enlace.
+func (cm *ConnectionManager) Observe(deviceID string, rssi int) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	if link, connected := cm.links[deviceID]; connected {
+		link.signal.add(rssi)
+		link.RSSI = int(link.signal.mean)
+		return
+	}
+
+	candidate, ok := cm.candidates[deviceID]
+	if !ok {
+		candidate = &linkCandidate{}
+		cm.candidates[deviceID] = candidate
+	}
+	candidate.signal.add(rssi)
+	candidate.lastSeen = time.Now()
+}
+
+// Prefer marca um dispositivo para ser discado antes dos demais candidatos,
+// independente do sinal
+func (cm *ConnectionManager) Prefer(deviceID string, preferred bool) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	if preferred {
+		cm.preferred[deviceID] = true
+	} else {
+		delete(cm.preferred, deviceID)
+	}
+}
+
+// Forget descarta um dispositivo que deixou de ser visível
+func (cm *ConnectionManager) Forget(deviceID string) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	delete(cm.candidates, deviceID)
+	delete(cm.backoff, deviceID)
+	delete(cm.preferred, deviceID)
+}
+
+// Connected registra um enlace estabelecido no papel informado
+func (cm *ConnectionManager) Connected(deviceID string, role LinkRole) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	now := time.Now()
+	link := &Link{
+		DeviceID:     deviceID,
+		Role:         role,
+		ConnectedAt:  now,
+		LastActivity: now,
+	}
+
+	// O sinal medido antes da conexão continua valendo para o enlace
+	if candidate, ok := cm.candidates[deviceID]; ok {
+		link.signal = candidate.signal
+		link.RSSI = int(candidate.signal.mean)
+		delete(cm.candidates, deviceID)
+	}
+	cm.links[deviceID] = link
+}
+
+// Disconnected remove um enlace encerrado. A queda de um enlace que não
+// chegou a ficar estável conta como falha para o backoff de reconexão.
+func (cm *ConnectionManager) Disconnected(deviceID string) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	link, ok := cm.links[deviceID]
+	if !ok {
+		return
+	}
+	delete(cm.links, deviceID)
+
+	now := time.Now()
+	if now.Sub(link.ConnectedAt) < StableLinkDuration {
+		cm.failLocked(deviceID, now)
+	} else {
+		delete(cm.backoff, deviceID)
+	}
+}
+
+// ConnectFailed registra uma tentativa de conexão malsucedida, adiando a
+// próxima tentativa com o dispositivo
+func (cm *ConnectionManager) ConnectFailed(deviceID string) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	cm.failLocked(deviceID, time.Now())
+}
+
+// ConnectLimited registra uma conexão recusada pelo controlador por falta de
+// vagas. O dispositivo continua na fila sem entrar em backoff e, durante
+// ControllerLimitHold, o número atual de enlaces é tratado como o teto do
+// controlador.
+func (cm *ConnectionManager) ConnectLimited(deviceID string) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	cm.controllerLimit = len(cm.links)
+	cm.controllerLimitUntil = time.Now().Add(ControllerLimitHold)
+}
+
+// ControllerLimit retorna o teto de enlaces informado pelo controlador e se
+// ele ainda está em vigor
+func (cm *ConnectionManager) ControllerLimit() (int, bool) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	if !time.Now().Before(cm.controllerLimitUntil) {
+		return 0, false
+	}
+	return cm.controllerLimit, true
+}
+
+// failLocked conta uma falha e calcula a próxima tentativa permitida
+// Deve ser chamada com o mutex adquirido
+func (cm *ConnectionManager) failLocked(deviceID string, now time.Time) {
+	state, ok := cm.backoff[deviceID]
+	if !ok {
+		state = &reconnectBackoff{}
+		cm.backoff[deviceID] = state
+	}
+	state.failures++
+
+	delay := ReconnectBackoffBase
+	for i := 1; i < state.failures && delay < ReconnectBackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > ReconnectBackoffMax {
+		delay = ReconnectBackoffMax
+	}
+	state.retryAt = now.Add(delay)
+}
+
+// RetryAt retorna quando o dispositivo poderá ser discado novamente e o
+// número de falhas seguidas (zero se não há espera)
+func (cm *ConnectionManager) RetryAt(deviceID string) (time.Time, int) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	state, ok := cm.backoff[deviceID]
+	if !ok {
+		return time.Time{}, 0
+	}
+	return state.retryAt, state.failures
+}
+
+// Activity registra tráfego em um enlace, adiando seu encerramento por ociosidade
+func (cm *ConnectionManager) Activity(deviceID string) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	if link, ok := cm.links[deviceID]; ok {
+		link.LastActivity = time.Now()
+	}
+}
+
+// IsConnected verifica se há um enlace ativo com o dispositivo
+func (cm *ConnectionManager) IsConnected(deviceID string) bool {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	_, ok := cm.links[deviceID]
+	return ok
+}
+
+// Links retorna uma cópia dos enlaces ativos
+func (cm *ConnectionManager) Links() []Link {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	links := make([]Link, 0, len(cm.links))
+	for _, link := range cm.links {
+		links = append(links, *link)
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].DeviceID < links[j].DeviceID })
+	return links
+}
+
+// Plan avalia os enlaces e candidatos e decide quais dispositivos discar,
+// quais enlaces encerrar e se ainda há vagas para conexões recebidas
+func (cm *ConnectionManager) Plan(now time.Time) ConnectionPlan {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	var plan ConnectionPlan
+
+	// Encerrar enlaces ociosos e, se houver mais enlaces que o alvo
+	// (conexões recebidas em excesso), os menos ativos
+	active := make([]*Link, 0, len(cm.links))
+	for _, link := range cm.links {
+		if now.Sub(link.LastActivity) >= cm.idleTimeout {
+			plan.Disconnect = append(plan.Disconnect, link.DeviceID)
+			continue
+		}
+		active = append(active, link)
+	}
+	if len(active) > cm.target {
+		sort.Slice(active, func(i, j int) bool {
+			return active[i].LastActivity.After(active[j].LastActivity)
+		})
+		for _, link := range active[cm.target:] {
+			plan.Disconnect = append(plan.Disconnect, link.DeviceID)
+		}
+		active = active[:cm.target]
+	}
+	sort.Strings(plan.Disconnect)
+
+	// Distribuir as vagas livres alternando papéis, a partir do papel com menos enlaces
+	centrals, peripherals := 0, 0
+	for _, link := range active {
+		if link.Role == LinkRoleCentral {
+			centrals++
+		} else {
+			peripherals++
+		}
+	}
+	activeCentrals := centrals
+	dialSlots := 0
+	for free := cm.target - len(active); free > 0; free-- {
+		if centrals <= peripherals {
+			centrals++
+			dialSlots++
+		} else {
+			peripherals++
+			plan.Accept = true
+		}
+	}
+
+	// Respeitar o limite de enlaces centrais e o teto do controlador
+	if cm.maxCentrals > 0 && dialSlots > cm.maxCentrals-activeCentrals {
+		dialSlots = cm.maxCentrals - activeCentrals
+	}
+	if now.Before(cm.controllerLimitUntil) && dialSlots > cm.controllerLimit-len(active) {
+		dialSlots = cm.controllerLimit - len(active)
+	}
+	if dialSlots < 0 {
+		dialSlots = 0
+	}
+
+	// Preencher as vagas centrais com os candidatos preferidos e depois os de
+	// melhor sinal, ignorando os que ainda aguardam o backoff de reconexão
+	candidates := make([]string, 0, len(cm.candidates))
+	for deviceID := range cm.candidates {
+		if state, ok := cm.backoff[deviceID]; ok && now.Before(state.retryAt) {
+			continue
+		}
+		candidates = append(candidates, deviceID)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if pa, pb := cm.preferred[candidates[i]], cm.preferred[candidates[j]]; pa != pb {
+			return pa
+		}
+		a, b := cm.candidates[candidates[i]].signal.score(), cm.candidates[candidates[j]].signal.score()
+		if a != b {
+			return a > b
+		}
+		return candidates[i] < candidates[j]
+	})
+
+	if len(candidates) > dialSlots {
+		if dialSlots == 0 && len(plan.Disconnect) == 0 && len(candidates) > 0 {
+			// Sem vagas: trocar o enlace central mais fraco pelo melhor candidato
+			if weakest := cm.weakestCentralLocked(active, now); weakest != nil {
+				best := cm.candidates[candidates[0]]
+				if best.signal.score() >= weakest.signal.score()+SignalSwapMargin {
+					plan.Disconnect = append(plan.Disconnect, weakest.DeviceID)
+					dialSlots = 1
+				}
+			}
+		}
+		plan.Queued = append([]string(nil), candidates[dialSlots:]...)
+		candidates = candidates[:dialSlots]
+	}
+	plan.Dial = candidates
+
+	return plan
+}
+
+// weakestCentralLocked retorna o enlace central de pior sinal elegível para
+// troca, ou nil se nenhum tiver sinal conhecido e idade suficiente
+// Deve ser chamada com o mutex adquirido
+func (cm *ConnectionManager) weakestCentralLocked(links []*Link, now time.Time) *Link {
+	var weakest *Link
+	for _, link := range links {
+		if link.Role != LinkRoleCentral || link.signal.samples == 0 || now.Sub(link.ConnectedAt) < MinLinkAgeForSwap {
+			continue
+		}
+		if weakest == nil || link.signal.score() < weakest.signal.score() {
+			weakest = link
+		}
+	}
+	return weakest
+}
@@ -0,0 +1,220 @@
+package mesh
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConnectionManager(t *testing.T) {
+	t.Run("Discar os candidatos mais fortes", func(t *testing.T) {
+		cm := NewConnectionManager(4, time.Minute)
+		cm.Observe("fraco", -90)
+		cm.Observe("forte", -40)
+		cm.Observe("medio", -60)
+
+		// Quatro vagas livres alternam entre central e periférico
+		plan := cm.Plan(time.Now())
+		if !reflect.DeepEqual(plan.Dial, []string{"forte", "medio"}) {
+			t.Errorf("Discagem inesperada: %v", plan.Dial)
+		}
+		if !plan.Accept {
+			t.Error("Vagas periféricas deveriam aceitar conexões")
+		}
+	})
+
+	t.Run("Alternância de papéis", func(t *testing.T) {
+		cm := NewConnectionManager(3, time.Minute)
+		cm.Connected("a", LinkRoleCentral)
+		cm.Connected("b", LinkRoleCentral)
+		cm.Observe("c", -50)
+
+		// Com dois enlaces centrais, a vaga restante é periférica
+		plan := cm.Plan(time.Now())
+		if len(plan.Dial) != 0 || !plan.Accept {
+			t.Errorf("Vaga restante deveria ser periférica: %+v", plan)
+		}
+
+		cm.Disconnected("b")
+		cm.Connected("d", LinkRolePeripheral)
+		plan = cm.Plan(time.Now())
+		if !reflect.DeepEqual(plan.Dial, []string{"c"}) || plan.Accept {
+			t.Errorf("Vaga restante deveria ser central: %+v", plan)
+		}
+	})
+
+	t.Run("Encerrar enlaces ociosos", func(t *testing.T) {
+		cm := NewConnectionManager(2, time.Minute)
+		cm.Connected("ocioso", LinkRoleCentral)
+		cm.Connected("ativo", LinkRolePeripheral)
+
+		plan := cm.Plan(time.Now().Add(2 * time.Minute))
+		if !reflect.DeepEqual(plan.Disconnect, []string{"ativo", "ocioso"}) {
+			t.Errorf("Ambos os enlaces deveriam estar ociosos: %v", plan.Disconnect)
+		}
+
+		cm.Activity("ativo")
+		plan = cm.Plan(time.Now().Add(30 * time.Second))
+		if len(plan.Disconnect) != 0 {
+			t.Errorf("Nenhum enlace deveria estar ocioso: %v", plan.Disconnect)
+		}
+	})
+
+	t.Run("Excesso de enlaces", func(t *testing.T) {
+		cm := NewConnectionManager(1, time.Minute)
+		cm.Connected("antigo", LinkRolePeripheral)
+		time.Sleep(time.Millisecond)
+		cm.Connected("novo", LinkRolePeripheral)
+
+		plan := cm.Plan(time.Now())
+		if !reflect.DeepEqual(plan.Disconnect, []string{"antigo"}) {
+			t.Errorf("Enlace menos ativo deveria ser encerrado: %v", plan.Disconnect)
+		}
+	})
+
+	t.Run("Dispositivo conectado não é candidato", func(t *testing.T) {
+		cm := NewConnectionManager(4, time.Minute)
+		cm.Connected("a", LinkRolePeripheral)
+		cm.Observe("a", -30)
+
+		if plan := cm.Plan(time.Now()); len(plan.Dial) != 0 {
+			t.Errorf("Dispositivo conectado não deveria ser discado: %v", plan.Dial)
+		}
+	})
+
+	t.Run("Preferir sinal estável", func(t *testing.T) {
+		cm := NewConnectionManager(2, time.Minute)
+		for _, rssi := range []int{-40, -80, -40, -80} {
+			cm.Observe("oscilante", rssi)
+		}
+		for _, rssi := range []int{-58, -60, -59, -60} {
+			cm.Observe("estavel", rssi)
+		}
+
+		plan := cm.Plan(time.Now())
+		if !reflect.DeepEqual(plan.Dial, []string{"estavel"}) {
+			t.Errorf("Candidato estável deveria ser preferido: %v", plan.Dial)
+		}
+	})
+
+	t.Run("Trocar enlace fraco por candidato forte", func(t *testing.T) {
+		cm := NewConnectionManager(2, time.Hour)
+		cm.Observe("fraco", -90)
+		cm.Connected("fraco", LinkRoleCentral)
+		cm.Connected("periferico", LinkRolePeripheral)
+		cm.Observe("forte", -45)
+
+		// Enlace recente não é trocado
+		if plan := cm.Plan(time.Now()); len(plan.Dial) != 0 || len(plan.Disconnect) != 0 {
+			t.Errorf("Enlace recente não deveria ser trocado: %+v", plan)
+		}
+
+		later := time.Now().Add(MinLinkAgeForSwap)
+		plan := cm.Plan(later)
+		if !reflect.DeepEqual(plan.Disconnect, []string{"fraco"}) || !reflect.DeepEqual(plan.Dial, []string{"forte"}) {
+			t.Errorf("Enlace fraco deveria ser trocado: %+v", plan)
+		}
+
+		// Vantagem abaixo da margem não justifica a troca
+		cm.Forget("forte")
+		cm.Observe("similar", -85)
+		if plan := cm.Plan(later); len(plan.Dial) != 0 {
+			t.Errorf("Candidato similar não deveria provocar troca: %+v", plan)
+		}
+	})
+
+	t.Run("Backoff de reconexão", func(t *testing.T) {
+		cm := NewConnectionManager(2, time.Minute)
+		cm.Observe("instavel", -50)
+
+		cm.ConnectFailed("instavel")
+		retryAt, failures := cm.RetryAt("instavel")
+		if failures != 1 {
+			t.Fatalf("Esperada 1 falha, obtido %d", failures)
+		}
+		if plan := cm.Plan(time.Now()); len(plan.Dial) != 0 {
+			t.Errorf("Dispositivo em backoff não deveria ser discado: %v", plan.Dial)
+		}
+		if plan := cm.Plan(retryAt); !reflect.DeepEqual(plan.Dial, []string{"instavel"}) {
+			t.Errorf("Dispositivo deveria ser discado após o backoff: %v", plan.Dial)
+		}
+
+		// Queda logo após conectar conta como falha e dobra a espera
+		cm.Connected("instavel", LinkRoleCentral)
+		cm.Disconnected("instavel")
+		next, failures := cm.RetryAt("instavel")
+		if failures != 2 || next.Sub(retryAt) < ReconnectBackoffBase {
+			t.Errorf("Espera deveria dobrar: falhas=%d espera=%v", failures, next.Sub(retryAt))
+		}
+	})
+
+	t.Run("Limite de enlaces centrais", func(t *testing.T) {
+		cm := NewConnectionManager(6, time.Minute)
+		cm.SetMaxCentrals(1)
+		cm.Observe("a", -40)
+		cm.Observe("b", -50)
+		cm.Observe("c", -60)
+
+		plan := cm.Plan(time.Now())
+		if !reflect.DeepEqual(plan.Dial, []string{"a"}) {
+			t.Errorf("Apenas um enlace central deveria ser discado: %v", plan.Dial)
+		}
+		if !reflect.DeepEqual(plan.Queued, []string{"b", "c"}) {
+			t.Errorf("Candidatos excedentes deveriam aguardar: %v", plan.Queued)
+		}
+
+		cm.Connected("a", LinkRoleCentral)
+		if plan := cm.Plan(time.Now()); len(plan.Dial) != 0 || !plan.Accept {
+			t.Errorf("Limite central atingido deveria apenas aceitar conexões: %+v", plan)
+		}
+	})
+
+	t.Run("Teto do controlador", func(t *testing.T) {
+		cm := NewConnectionManager(6, time.Minute)
+		cm.Connected("a", LinkRoleCentral)
+		cm.Observe("b", -40)
+		cm.Observe("c", -50)
+
+		cm.ConnectLimited("b")
+		if limit, ok := cm.ControllerLimit(); !ok || limit != 1 {
+			t.Fatalf("Teto do controlador inesperado: %d %v", limit, ok)
+		}
+		if _, failures := cm.RetryAt("b"); failures != 0 {
+			t.Error("Recusa por falta de vagas não deveria contar como falha")
+		}
+
+		plan := cm.Plan(time.Now())
+		if len(plan.Dial) != 0 || !reflect.DeepEqual(plan.Queued, []string{"b", "c"}) {
+			t.Errorf("Candidatos deveriam aguardar vaga no controlador: %+v", plan)
+		}
+
+		// Uma vaga liberada volta a ser preenchida pelo melhor candidato
+		cm.Disconnected("a")
+		if plan := cm.Plan(time.Now()); !reflect.DeepEqual(plan.Dial, []string{"b"}) {
+			t.Errorf("Vaga liberada deveria ser discada: %+v", plan)
+		}
+
+		// Após a validade do teto, o alvo volta a valer
+		if plan := cm.Plan(time.Now().Add(ControllerLimitHold)); len(plan.Dial) != 2 {
+			t.Errorf("Teto expirado deveria liberar as vagas: %+v", plan)
+		}
+	})
+
+	t.Run("Candidatos preferidos", func(t *testing.T) {
+		cm := NewConnectionManager(2, time.Minute)
+		cm.Observe("forte", -40)
+		cm.Observe("conhecido", -85)
+		cm.Prefer("conhecido", true)
+
+		// Uma vaga central: o peer conhecido passa à frente do sinal forte
+		plan := cm.Plan(time.Now())
+		if !reflect.DeepEqual(plan.Dial, []string{"conhecido"}) || !reflect.DeepEqual(plan.Queued, []string{"forte"}) {
+			t.Errorf("Peer preferido deveria ser discado primeiro: %+v", plan)
+		}
+
+		cm.Prefer("conhecido", false)
+		if plan := cm.Plan(time.Now()); !reflect.DeepEqual(plan.Dial, []string{"forte"}) {
+			t.Errorf("Sem preferência o sinal volta a decidir: %v", plan.Dial)
+		}
+	})
+}
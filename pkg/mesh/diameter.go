@@ -0,0 +1,102 @@
+package mesh
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDiameterWindow é a janela de observação de contagens de saltos
+	DefaultDiameterWindow = 10 * time.Minute
+
+	// DefaultDiameterSamples é o número máximo de amostras mantidas
+	DefaultDiameterSamples = 256
+
+	// MinDiameterSamples é o mínimo de amostras para uma estimativa confiável
+	MinDiameterSamples = 10
+
+	// diameterPercentile descarta caminhos extraordinariamente longos
+	diameterPercentile = 0.95
+)
+
+// hopSample é uma distância observada até a origem de um pacote
+type hopSample struct {
+	hops int
+	at   time.Time
+}
+
+// DiameterEstimator estima o diâmetro da rede a partir das distâncias
+// observadas até a origem dos pacotes recebidos
+type DiameterEstimator struct {
+	samples    []hopSample
+	window     time.Duration
+	maxSamples int
+	mutex      sync.Mutex
+}
+
+// NewDiameterEstimator cria um novo estimador de diâmetro.
+// Valores não positivos usam os padrões.
+func NewDiameterEstimator(window time.Duration, maxSamples int) *DiameterEstimator {
+	if window <= 0 {
+		window = DefaultDiameterWindow
+	}
+	if maxSamples <= 0 {
+		maxSamples = DefaultDiameterSamples
+	}
+
+	return &DiameterEstimator{
+		window:     window,
+		maxSamples: maxSamples,
+	}
+}
+
+// Record registra a distância em saltos até a origem de um pacote recebido
+func (de *DiameterEstimator) Record(hops int) {
+	if hops <= 0 {
+		return
+	}
+
+	de.mutex.Lock()
+	defer de.mutex.Unlock()
+
+	de.samples = append(de.samples, hopSample{hops: hops, at: time.Now()})
+	if len(de.samples) > de.maxSamples {
+		de.samples = de.samples[len(de.samples)-de.maxSamples:]
+	}
+}
+
+// Estimate retorna o diâmetro estimado (percentil alto das distâncias
+// observadas na janela) e o número de amostras usadas
+func (de *DiameterEstimator) Estimate() (int, int) {
+	de.mutex.Lock()
+	defer de.mutex.Unlock()
+
+	// Descartar amostras fora da janela
+	threshold := time.Now().Add(-de.window)
+	first := sort.Search(len(de.samples), func(i int) bool {
+		return !de.samples[i].at.Before(threshold)
+	})
+	de.samples = de.samples[first:]
+
+	if len(de.samples) == 0 {
+		return 0, 0
+	}
+
+	hops := make([]int, len(de.samples))
+	for i, sample := range de.samples {
+		hops[i] = sample.hops
+	}
+	sort.Ints(hops)
+
+	index := int(float64(len(hops)-1) * diameterPercentile)
+	return hops[index], len(hops)
+}
+
+// Reset descarta todas as amostras
+func (de *DiameterEstimator) Reset() {
+	de.mutex.Lock()
+	defer de.mutex.Unlock()
+
+	de.samples = nil
+}
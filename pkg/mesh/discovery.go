@@ -0,0 +1,275 @@
+package mesh
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/utils"
+)
+
+const (
+	// DefaultDiscoveryTimeout é o tempo máximo de espera por uma resposta de rota
+	DefaultDiscoveryTimeout = 10 * time.Second
+
+	// MaxDiscoveryHops limita a propagação das solicitações de rota
+	MaxDiscoveryHops = 7
+)
+
+// HopMetric converte um número de saltos em uma métrica de rota (0-100, maior é melhor)
+func HopMetric(hops int) int {
+	metric := 100 - hops*10
+	if metric < 1 {
+		metric = 1
+	}
+	return metric
+}
+
+// RouteMetric combina o número de saltos com a qualidade (0-100) do enlace do
+// próximo hop, de modo que rotas por vizinhos melhores sejam preferidas
+func RouteMetric(hops int, linkQuality int) int {
+	metric := HopMetric(hops) * linkQuality / 100
+	if metric < 1 {
+		metric = 1
+	}
+	return metric
+}
+
+// pendingDiscovery representa uma descoberta de rota em andamento
+type pendingDiscovery struct {
+	requestID uint32
+	startedAt time.Time
+}
+
+// RouteDiscovery implementa descoberta de rotas sob demanda (estilo AODV).
+// Solicitações (RREQ) são propagadas por flooding e criam rotas reversas para o
+// originador; respostas (RREP) percorrem essas rotas de volta e criam a rota direta.
+type RouteDiscovery struct {
+	// Roteador cuja tabela é populada pelas descobertas
+	router *MessageRouter
+
+	// ID deste nó
+	selfID string
+
+	// Solicitações já processadas (origem + ID) para evitar reprocessamento
+	seenRequests *utils.ExpiringSet
+
+	// Descobertas iniciadas por este nó: destino -> descoberta
+	pending map[string]*pendingDiscovery
+
+	// Próximo ID de solicitação
+	nextRequestID uint32
+
+	// Tempo máximo de espera por uma resposta
+	timeout time.Duration
+
+	mutex sync.Mutex
+}
+
+// NewRouteDiscovery cria um novo gerenciador de descoberta de rotas
+func NewRouteDiscovery(router *MessageRouter, selfID string) *RouteDiscovery {
+	return &RouteDiscovery{
+		router:       router,
+		selfID:       selfID,
+		seenRequests: utils.NewExpiringSet(1*time.Minute, 30*time.Second),
+		pending:      make(map[string]*pendingDiscovery),
+		timeout:      DefaultDiscoveryTimeout,
+	}
+}
+
+// StartDiscovery inicia a descoberta de uma rota para o destino.
+// Retorna a solicitação a ser enviada em broadcast, ou nil se a rota já é
+// conhecida ou já existe uma descoberta em andamento para o destino.
+func (rd *RouteDiscovery) StartDiscovery(targetID string) *protocol.RouteRequest {
+	if _, known := rd.router.GetNextHop(targetID); known {
+		return nil
+	}
+
+	rd.mutex.Lock()
+	defer rd.mutex.Unlock()
+
+	if p, exists := rd.pending[targetID]; exists && time.Since(p.startedAt) < rd.timeout {
+		return nil
+	}
+
+	rd.nextRequestID++
+	requestID := rd.nextRequestID
+	rd.pending[targetID] = &pendingDiscovery{
+		requestID: requestID,
+		startedAt: time.Now(),
+	}
+
+	// Não processar nossa própria solicitação quando ela voltar
+	rd.seenRequests.Add(requestKey(rd.selfID, requestID))
+
+	return &protocol.RouteRequest{
+		RequestID:   requestID,
+		OriginID:    []byte(rd.selfID),
+		TargetID:    []byte(targetID),
+		HopCount:    0,
+		ForwarderID: []byte(rd.selfID),
+	}
+}
+
+// IsPending verifica se há uma descoberta em andamento para o destino
+func (rd *RouteDiscovery) IsPending(targetID string) bool {
+	rd.mutex.Lock()
+	defer rd.mutex.Unlock()
+
+	p, exists := rd.pending[targetID]
+	return exists && time.Since(p.startedAt) < rd.timeout
+}
+
+// HandleRouteRequest processa uma solicitação de rota recebida do vizinho
+// neighborID, autenticado pelo enlace de chegada. Solicitações cujo
+// encaminhador declarado não é esse vizinho são descartadas.
+// Retorna a resposta a ser enviada (se este nó é o destino ou conhece a rota)
+// e/ou a solicitação a ser retransmitida. Ambos podem ser nil.
+func (rd *RouteDiscovery) HandleRouteRequest(req *protocol.RouteRequest, neighborID string) (*protocol.RouteReply, *protocol.RouteRequest) {
+	originID := string(req.OriginID)
+	targetID := string(req.TargetID)
+	forwarderID := string(req.ForwarderID)
+
+	if originID == rd.selfID || forwarderID == "" || forwarderID != neighborID {
+		return nil, nil
+	}
+
+	if !rd.seenRequests.Add(requestKey(originID, req.RequestID)) {
+		return nil, nil // Solicitação duplicada
+	}
+
+	// Rota reversa para o originador através de quem nos entregou a solicitação
+	hopsToOrigin := int(req.HopCount) + 1
+	rd.router.UpdateRoute(forwarderID, "", 1)
+	if forwarderID != originID {
+		rd.router.UpdateRoute(originID, forwarderID, hopsToOrigin)
+	}
+
+	// Somos o destino: responder
+	if targetID == rd.selfID {
+		return &protocol.RouteReply{
+			RequestID:   req.RequestID,
+			OriginID:    req.OriginID,
+			TargetID:    req.TargetID,
+			HopCount:    0,
+			ForwarderID: []byte(rd.selfID),
+			NextHopID:   req.ForwarderID,
+		}, nil
+	}
+
+	// Conhecemos uma rota para o destino (que não passa por quem perguntou)
+	// e seu comprimento: responder em nome dele. A métrica combina saltos,
+	// qualidade e latência, então não serve para estimar o comprimento.
+	nextHop, known := rd.router.GetNextHop(targetID)
+	hops, hopsKnown := rd.router.GetRouteHops(targetID)
+	if known && hopsKnown && nextHop != forwarderID {
+		return &protocol.RouteReply{
+			RequestID:   req.RequestID,
+			OriginID:    req.OriginID,
+			TargetID:    req.TargetID,
+			HopCount:    uint8(hops),
+			ForwarderID: []byte(rd.selfID),
+			NextHopID:   req.ForwarderID,
+		}, nil
+	}
+
+	// Limitar propagação
+	if hopsToOrigin >= MaxDiscoveryHops {
+		return nil, nil
+	}
+
+	return nil, &protocol.RouteRequest{
+		RequestID:   req.RequestID,
+		OriginID:    req.OriginID,
+		TargetID:    req.TargetID,
+		HopCount:    uint8(hopsToOrigin),
+		ForwarderID: []byte(rd.selfID),
+	}
+}
+
+// HandleRouteReply processa uma resposta de rota recebida do vizinho
+// neighborID, autenticado pelo enlace de chegada. Respostas cujo
+// encaminhador declarado não é esse vizinho são descartadas.
+// Retorna a resposta a ser retransmitida em direção ao originador (nil se
+// este nó é o originador ou não participa da rota) e se a descoberta deste
+// nó foi concluída.
+func (rd *RouteDiscovery) HandleRouteReply(rep *protocol.RouteReply, neighborID string) (*protocol.RouteReply, bool) {
+	// Apenas o próximo salto designado processa a resposta
+	if string(rep.NextHopID) != rd.selfID {
+		return nil, false
+	}
+
+	originID := string(rep.OriginID)
+	targetID := string(rep.TargetID)
+	forwarderID := string(rep.ForwarderID)
+	if forwarderID == "" || forwarderID != neighborID {
+		return nil, false
+	}
+
+	// Rota direta para o destino através de quem nos entregou a resposta
+	hopsToTarget := int(rep.HopCount) + 1
+	rd.router.UpdateRoute(forwarderID, "", 1)
+	if forwarderID != targetID {
+		rd.router.UpdateRoute(targetID, forwarderID, hopsToTarget)
+	}
+
+	// Somos o originador: descoberta concluída
+	if originID == rd.selfID {
+		rd.mutex.Lock()
+		_, wasPending := rd.pending[targetID]
+		delete(rd.pending, targetID)
+		rd.mutex.Unlock()
+		return nil, wasPending
+	}
+
+	// Encaminhar pela rota reversa
+	nextHop, known := rd.router.GetNextHop(originID)
+	if !known {
+		return nil, false
+	}
+
+	return &protocol.RouteReply{
+		RequestID:   rep.RequestID,
+		OriginID:    rep.OriginID,
+		TargetID:    rep.TargetID,
+		HopCount:    uint8(hopsToTarget),
+		ForwarderID: []byte(rd.selfID),
+		NextHopID:   []byte(nextHop),
+	}, false
+}
+
+// CleanupExpired remove descobertas que excederam o tempo limite
+// Retorna os destinos cujas descobertas falharam
+func (rd *RouteDiscovery) CleanupExpired() []string {
+	rd.mutex.Lock()
+	defer rd.mutex.Unlock()
+
+	var expired []string
+	for targetID, p := range rd.pending {
+		if time.Since(p.startedAt) >= rd.timeout {
+			expired = append(expired, targetID)
+			delete(rd.pending, targetID)
+		}
+	}
+
+	return expired
+}
+
+// SetTimeout define o tempo máximo de espera por uma resposta de rota
+func (rd *RouteDiscovery) SetTimeout(timeout time.Duration) {
+	rd.mutex.Lock()
+	defer rd.mutex.Unlock()
+
+	rd.timeout = timeout
+}
+
+// Stop libera recursos do gerenciador de descoberta
+func (rd *RouteDiscovery) Stop() {
+	rd.seenRequests.Stop()
+}
+
+// requestKey gera a chave de deduplicação de uma solicitação
+func requestKey(originID string, requestID uint32) string {
+	return fmt.Sprintf("%x:%d", originID, requestID)
+}
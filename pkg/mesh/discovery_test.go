@@ -0,0 +1,169 @@
+package mesh
+
+import (
+	"testing"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+func TestRouteDiscovery(t *testing.T) {
+	t.Run("Descoberta em cadeia A-B-C", func(t *testing.T) {
+		routerA, routerB, routerC := NewMessageRouter(), NewMessageRouter(), NewMessageRouter()
+		nodeA := NewRouteDiscovery(routerA, "A")
+		nodeB := NewRouteDiscovery(routerB, "B")
+		nodeC := NewRouteDiscovery(routerC, "C")
+		defer nodeA.Stop()
+		defer nodeB.Stop()
+		defer nodeC.Stop()
+
+		// A procura C
+		req := nodeA.StartDiscovery("C")
+		if req == nil {
+			t.Fatal("StartDiscovery deveria retornar uma solicitação")
+		}
+		if !nodeA.IsPending("C") {
+			t.Error("Descoberta para C deveria estar pendente")
+		}
+
+		// Segunda chamada não deve gerar nova solicitação
+		if nodeA.StartDiscovery("C") != nil {
+			t.Error("Não deveria iniciar descoberta duplicada")
+		}
+
+		// B recebe a solicitação e a retransmite
+		reply, forward := nodeB.HandleRouteRequest(req, "A")
+		if reply != nil {
+			t.Fatal("B não conhece C e não deveria responder")
+		}
+		if forward == nil {
+			t.Fatal("B deveria retransmitir a solicitação")
+		}
+		if forward.HopCount != 1 || string(forward.ForwarderID) != "B" {
+			t.Errorf("Solicitação retransmitida inválida: hops=%d forwarder=%s", forward.HopCount, forward.ForwarderID)
+		}
+
+		// Rever a mesma solicitação não deve gerar nada
+		if r, f := nodeB.HandleRouteRequest(req, "A"); r != nil || f != nil {
+			t.Error("Solicitação duplicada deveria ser ignorada")
+		}
+
+		// C recebe e responde
+		reply, forward = nodeC.HandleRouteRequest(forward, "B")
+		if forward != nil {
+			t.Error("Destino não deveria retransmitir a solicitação")
+		}
+		if reply == nil || string(reply.NextHopID) != "B" {
+			t.Fatal("C deveria responder via B")
+		}
+
+		// C aprendeu a rota reversa para A via B
+		if nextHop, ok := routerC.GetNextHop("A"); !ok || nextHop != "B" {
+			t.Errorf("Rota reversa de C para A esperada via B, obtido: %s (%v)", nextHop, ok)
+		}
+
+		// B encaminha a resposta para A
+		fwdReply, done := nodeB.HandleRouteReply(reply, "C")
+		if done {
+			t.Error("B não é o originador")
+		}
+		if fwdReply == nil || string(fwdReply.NextHopID) != "A" {
+			t.Fatal("B deveria encaminhar a resposta para A")
+		}
+
+		// A conclui a descoberta
+		if _, done := nodeA.HandleRouteReply(fwdReply, "B"); !done {
+			t.Error("A deveria concluir a descoberta")
+		}
+		if nodeA.IsPending("C") {
+			t.Error("Descoberta não deveria mais estar pendente")
+		}
+
+		nextHop, ok := routerA.GetNextHop("C")
+		if !ok || nextHop != "B" {
+			t.Errorf("Rota de A para C esperada via B, obtido: %s (%v)", nextHop, ok)
+		}
+	})
+
+	t.Run("Resposta de nó intermediário com rota conhecida", func(t *testing.T) {
+		routerB := NewMessageRouter()
+		routerB.UpdateRoute("C", "", 1)
+		nodeB := NewRouteDiscovery(routerB, "B")
+		defer nodeB.Stop()
+
+		nodeA := NewRouteDiscovery(NewMessageRouter(), "A")
+		defer nodeA.Stop()
+
+		reply, forward := nodeB.HandleRouteRequest(nodeA.StartDiscovery("C"), "A")
+		if forward != nil {
+			t.Error("B conhece a rota e não deveria retransmitir")
+		}
+		if reply == nil || reply.HopCount != 1 {
+			t.Fatal("B deveria responder com a distância até C")
+		}
+	})
+
+	t.Run("Rota sem comprimento conhecido é retransmitida", func(t *testing.T) {
+		routerB := NewMessageRouter()
+		routerB.UpdateRoutingInfo("C", "", HopMetric(1))
+		nodeB := NewRouteDiscovery(routerB, "B")
+		defer nodeB.Stop()
+
+		nodeA := NewRouteDiscovery(NewMessageRouter(), "A")
+		defer nodeA.Stop()
+
+		reply, forward := nodeB.HandleRouteRequest(nodeA.StartDiscovery("C"), "A")
+		if reply != nil {
+			t.Errorf("B não deveria responder sem saber a distância até C, respondeu %d saltos", reply.HopCount)
+		}
+		if forward == nil {
+			t.Error("B deveria retransmitir a solicitação")
+		}
+	})
+
+	t.Run("Resposta para outro nó é ignorada", func(t *testing.T) {
+		nodeB := NewRouteDiscovery(NewMessageRouter(), "B")
+		defer nodeB.Stop()
+
+		nodeC := NewRouteDiscovery(NewMessageRouter(), "C")
+		defer nodeC.Stop()
+
+		nodeA := NewRouteDiscovery(NewMessageRouter(), "A")
+		defer nodeA.Stop()
+
+		reply, _ := nodeC.HandleRouteRequest(nodeA.StartDiscovery("C"), "A")
+		if fwd, done := nodeB.HandleRouteReply(reply, "C"); fwd != nil || done {
+			t.Error("Resposta endereçada a A não deveria ser processada por B")
+		}
+	})
+	t.Run("Encaminhador diferente do enlace é descartado", func(t *testing.T) {
+		routerB := NewMessageRouter()
+		nodeB := NewRouteDiscovery(routerB, "B")
+		defer nodeB.Stop()
+
+		nodeA := NewRouteDiscovery(NewMessageRouter(), "A")
+		defer nodeA.Stop()
+
+		// M entrega uma solicitação de A que se diz encaminhada por A
+		reply, forward := nodeB.HandleRouteRequest(nodeA.StartDiscovery("C"), "M")
+		if reply != nil || forward != nil {
+			t.Error("Solicitação com encaminhador forjado não deveria ser processada")
+		}
+		if _, ok := routerB.GetNextHop("A"); ok {
+			t.Error("Solicitação forjada não deveria criar rota")
+		}
+
+		rep := &protocol.RouteReply{
+			RequestID:   1,
+			OriginID:    []byte("A"),
+			TargetID:    []byte("C"),
+			ForwarderID: []byte("C"),
+			NextHopID:   []byte("B"),
+		}
+		if fwd, done := nodeB.HandleRouteReply(rep, "M"); fwd != nil || done {
+			t.Error("Resposta com encaminhador forjado não deveria ser processada")
+		}
+		if _, ok := routerB.GetNextHop("C"); ok {
+			t.Error("Resposta forjada não deveria criar rota")
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package mesh
+
+import (
+	"sync"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+const (
+	// DefaultDutyPeriod é a duração de um ciclo de atividade do rádio
+	DefaultDutyPeriod = 30 * time.Second
+
+	// DefaultDutyScheduleMaxAge é o tempo após o qual a agenda anunciada por
+	// um vizinho é descartada se não for renovada
+	DefaultDutyScheduleMaxAge = 2 * time.Minute
+)
+
+// dutyNeighbor guarda a agenda anunciada por um vizinho direto
+type dutyNeighbor struct {
+	schedule protocol.SleepSchedule
+	lastSeen time.Time
+}
+
+// DutyCycle coordena as janelas de atividade do rádio com os vizinhos.
+// Nós em economia de energia dormem parte de cada ciclo; para continuarem
+// alcançáveis, todos os que usam o mesmo período adotam a menor fase
+// anunciada na vizinhança, de modo que as janelas de atividade se sobreponham.
+type DutyCycle struct {
+	period time.Duration
+	wake   time.Duration
+	phase  time.Duration
+
+	neighbors map[string]*dutyNeighbor
+	maxAge    time.Duration
+
+	mutex sync.Mutex
+}
+
+// NewDutyCycle cria um coordenador de ciclo de trabalho com o período
+// informado. O nó começa sempre acordado.
+func NewDutyCycle(period time.Duration) *DutyCycle {
+	if period <= 0 {
+		period = DefaultDutyPeriod
+	}
+
+	return &DutyCycle{
+		period:    period,
+		wake:      period,
+		phase:     time.Duration(time.Now().UnixMilli()%period.Milliseconds()) * time.Millisecond,
+		neighbors: make(map[string]*dutyNeighbor),
+		maxAge:    DefaultDutyScheduleMaxAge,
+	}
+}
+
+// SetWakeFraction define a fração de cada ciclo em que o rádio fica ativo.
+// Frações a partir de 1 desativam o ciclo de trabalho. Ao ativá-lo, a fase
+// é alinhada imediatamente com a dos vizinhos conhecidos.
+func (dc *DutyCycle) SetWakeFraction(fraction float64) {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	if fraction >= 1 || fraction <= 0 {
+		dc.wake = dc.period
+		return
+	}
+
+	dc.wake = time.Duration(float64(dc.period) * fraction)
+	for _, neighbor := range dc.neighbors {
+		dc.alignLocked(&neighbor.schedule)
+	}
+}
+
+// Enabled verifica se o rádio dorme parte do ciclo
+func (dc *DutyCycle) Enabled() bool {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	return dc.wake < dc.period
+}
+
+// Schedule retorna a agenda local a ser anunciada aos vizinhos
+func (dc *DutyCycle) Schedule() *protocol.SleepSchedule {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	return &protocol.SleepSchedule{
+		Period: uint32(dc.period.Milliseconds()),
+		Wake:   uint32(dc.wake.Milliseconds()),
+		Phase:  uint32(dc.phase.Milliseconds()),
+	}
+}
+
+// Observe registra a agenda anunciada por um vizinho direto.
+// Retorna true se a fase local mudou para se alinhar à do vizinho.
+func (dc *DutyCycle) Observe(peerID string, schedule *protocol.SleepSchedule) bool {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	dc.neighbors[peerID] = &dutyNeighbor{
+		schedule: *schedule,
+		lastSeen: time.Now(),
+	}
+
+	if dc.wake >= dc.period {
+		return false
+	}
+	return dc.alignLocked(schedule)
+}
+
+// alignLocked adota a fase de uma agenda compatível se ela for menor que a local
+// Deve ser chamada com o mutex adquirido
+func (dc *DutyCycle) alignLocked(schedule *protocol.SleepSchedule) bool {
+	// Vizinhos sempre acordados ou com outro período não restringem a fase
+	if schedule.Wake >= schedule.Period || int64(schedule.Period) != dc.period.Milliseconds() {
+		return false
+	}
+
+	phase := time.Duration(schedule.Phase) * time.Millisecond
+	if phase >= dc.phase {
+		return false
+	}
+
+	dc.phase = phase
+	return true
+}
+
+// IsAwake verifica se o rádio deve estar ativo no instante informado
+func (dc *DutyCycle) IsAwake(now time.Time) bool {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	return dc.wake >= dc.period || dc.positionLocked(now) < dc.wake
+}
+
+// NextTransition retorna quanto falta para o rádio mudar de estado.
+// Com o ciclo de trabalho desativado, retorna o período.
+func (dc *DutyCycle) NextTransition(now time.Time) time.Duration {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	if dc.wake >= dc.period {
+		return dc.period
+	}
+
+	position := dc.positionLocked(now)
+	if position < dc.wake {
+		return dc.wake - position
+	}
+	return dc.period - position
+}
+
+// positionLocked retorna a posição do instante dentro do ciclo, contada a
+// partir do início da janela de atividade
+// Deve ser chamada com o mutex adquirido
+func (dc *DutyCycle) positionLocked(now time.Time) time.Duration {
+	period := dc.period.Milliseconds()
+	position := (now.UnixMilli() - dc.phase.Milliseconds()) % period
+	if position < 0 {
+		position += period
+	}
+	return time.Duration(position) * time.Millisecond
+}
+
+// Remove descarta a agenda de um vizinho
+func (dc *DutyCycle) Remove(peerID string) {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	delete(dc.neighbors, peerID)
+}
+
+// Expire descarta agendas que não foram renovadas a tempo
+func (dc *DutyCycle) Expire() {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	threshold := time.Now().Add(-dc.maxAge)
+	for peerID, neighbor := range dc.neighbors {
+		if neighbor.lastSeen.Before(threshold) {
+			delete(dc.neighbors, peerID)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+func TestDutyCycle(t *testing.T) {
+	t.Run("Sempre acordado por padrão", func(t *testing.T) {
+		dc := NewDutyCycle(10 * time.Second)
+		if dc.Enabled() || !dc.IsAwake(time.Now()) {
+			t.Error("Nó deveria começar sempre acordado")
+		}
+
+		schedule := dc.Schedule()
+		if schedule.Wake != schedule.Period {
+			t.Errorf("Agenda sempre acordada inesperada: %+v", schedule)
+		}
+	})
+
+	t.Run("Janela de atividade", func(t *testing.T) {
+		dc := NewDutyCycle(10 * time.Second)
+		dc.SetWakeFraction(0.3)
+		if !dc.Enabled() {
+			t.Fatal("Ciclo de trabalho deveria estar ativo")
+		}
+
+		start := time.UnixMilli(int64(dc.Schedule().Phase))
+		if !dc.IsAwake(start.Add(time.Second)) {
+			t.Error("Nó deveria estar acordado no início da janela")
+		}
+		if dc.IsAwake(start.Add(5 * time.Second)) {
+			t.Error("Nó deveria estar dormindo fora da janela")
+		}
+		if next := dc.NextTransition(start.Add(time.Second)); next != 2*time.Second {
+			t.Errorf("Esperado adormecer em 2s, obtido %v", next)
+		}
+		if next := dc.NextTransition(start.Add(5 * time.Second)); next != 5*time.Second {
+			t.Errorf("Esperado acordar em 5s, obtido %v", next)
+		}
+	})
+
+	t.Run("Alinhamento com vizinhos", func(t *testing.T) {
+		dc := NewDutyCycle(10 * time.Second)
+		dc.SetWakeFraction(0.5)
+		local := dc.Schedule()
+
+		// Vizinho sempre acordado não altera a fase
+		if dc.Observe("awake", &protocol.SleepSchedule{Period: 10000, Wake: 10000, Phase: 0}) {
+			t.Error("Vizinho sempre acordado não deveria alterar a fase")
+		}
+
+		// Vizinho com período diferente também não
+		if dc.Observe("other", &protocol.SleepSchedule{Period: 20000, Wake: 5000, Phase: 0}) {
+			t.Error("Vizinho com outro período não deveria alterar a fase")
+		}
+
+		changed := dc.Observe("sleepy", &protocol.SleepSchedule{Period: 10000, Wake: 5000, Phase: 0})
+		if changed != (local.Phase > 0) || dc.Schedule().Phase != 0 {
+			t.Fatalf("Fase menor do vizinho deveria ser adotada, obtido %d", dc.Schedule().Phase)
+		}
+
+		// Fase maior que a local é ignorada
+		if dc.Observe("late", &protocol.SleepSchedule{Period: 10000, Wake: 5000, Phase: 5000}) {
+			t.Error("Fase maior não deveria ser adotada")
+		}
+	})
+
+	t.Run("Alinhamento ao ativar", func(t *testing.T) {
+		dc := NewDutyCycle(10 * time.Second)
+		dc.Observe("sleepy", &protocol.SleepSchedule{Period: 10000, Wake: 2000, Phase: 0})
+		dc.SetWakeFraction(0.2)
+
+		if dc.Schedule().Phase != 0 {
+			t.Errorf("Fase deveria ser alinhada ao ativar, obtido %d", dc.Schedule().Phase)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package mesh
+
+import (
+	"math/rand"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// gossipFirstHops é o número de saltos iniciais em que broadcasts são sempre
+// retransmitidos, evitando que uma mensagem morra logo após a origem
+const gossipFirstHops = 1
+
+// GossipProbability retorna a probabilidade de retransmitir um broadcast
+// dado o número de vizinhos diretos. Até GossipThreshold vizinhos a
+// probabilidade é 1; acima disso cai proporcionalmente, limitada por
+// GossipMinProbability.
+func (mr *MessageRouter) GossipProbability(neighbors int) float64 {
+	mr.routingMutex.RLock()
+	threshold := mr.config.GossipThreshold
+	minProbability := mr.config.GossipMinProbability
+	mr.routingMutex.RUnlock()
+
+	if threshold <= 0 || neighbors <= threshold {
+		return 1
+	}
+
+	p := float64(threshold) / float64(neighbors)
+	if p < minProbability {
+		p = minProbability
+	}
+	return p
+}
+
+// ShouldGossip decide se um broadcast deve ser retransmitido por este nó.
+// Pacotes endereçados e pacotes nos primeiros saltos são sempre retransmitidos;
+// os demais seguem GossipProbability.
+func (mr *MessageRouter) ShouldGossip(packet *protocol.BitchatPacket, neighbors int) bool {
+	if !isBroadcast(packet.RecipientID) || packet.HopCount < gossipFirstHops {
+		return true
+	}
+
+	p := mr.GossipProbability(neighbors)
+	if p >= 1 {
+		return true
+	}
+
+	mr.routingMutex.RLock()
+	random := mr.random
+	mr.routingMutex.RUnlock()
+
+	return random() < p
+}
+
+// defaultRandom é a fonte de aleatoriedade padrão das decisões de gossip
+func defaultRandom() float64 {
+	return rand.Float64()
+}
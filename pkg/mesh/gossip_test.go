@@ -0,0 +1,52 @@
+package mesh
+
+import (
+	"testing"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+func TestGossip(t *testing.T) {
+	router := NewMessageRouter()
+
+	t.Run("Probabilidade por densidade", func(t *testing.T) {
+		if p := router.GossipProbability(3); p != 1 {
+			t.Errorf("Área esparsa deveria retransmitir sempre, obtido %.2f", p)
+		}
+		if p := router.GossipProbability(8); p != 0.5 {
+			t.Errorf("Com 8 vizinhos esperado 0.50, obtido %.2f", p)
+		}
+		if p := router.GossipProbability(100); p != 0.25 {
+			t.Errorf("Probabilidade deveria respeitar o mínimo 0.25, obtido %.2f", p)
+		}
+	})
+
+	t.Run("Decisão de retransmissão", func(t *testing.T) {
+		router.random = func() float64 { return 0.9 }
+
+		broadcast := &protocol.BitchatPacket{RecipientID: protocol.BroadcastRecipient, HopCount: 2}
+		if router.ShouldGossip(broadcast, 20) {
+			t.Error("Broadcast em área densa deveria ser suprimido")
+		}
+		if !router.ShouldGossip(broadcast, 2) {
+			t.Error("Broadcast em área esparsa deveria ser retransmitido")
+		}
+
+		fromOrigin := &protocol.BitchatPacket{RecipientID: protocol.BroadcastRecipient}
+		if !router.ShouldGossip(fromOrigin, 20) {
+			t.Error("Broadcast recebido da origem deveria sempre ser retransmitido")
+		}
+
+		private := &protocol.BitchatPacket{RecipientID: []byte("peer1"), HopCount: 2}
+		if !router.ShouldGossip(private, 20) {
+			t.Error("Pacote endereçado deveria sempre ser retransmitido")
+		}
+
+		disabled := DefaultRoutingConfig()
+		disabled.GossipThreshold = 0
+		router.UpdateConfig(disabled)
+		if !router.ShouldGossip(broadcast, 20) {
+			t.Error("Gossip desativado deveria sempre retransmitir")
+		}
+	})
+}
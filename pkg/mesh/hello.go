@@ -0,0 +1,176 @@
+package mesh
+
+import (
+	"sync"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+const (
+	// DefaultHelloInterval é o intervalo entre hellos enviados aos vizinhos
+	DefaultHelloInterval = 10 * time.Second
+
+	// helloHoldMultiplier define quantos intervalos sem hello tornam um vizinho inativo
+	helloHoldMultiplier = 3
+)
+
+// helloNeighbor guarda o estado de um vizinho direto aprendido por hellos
+type helloNeighbor struct {
+	lastSeen  time.Time
+	neighbors map[string]bool // Vizinhos anunciados pelo vizinho (dois saltos)
+	flags     uint8           // Capacidades anunciadas pelo vizinho
+}
+
+// HelloProtocol mantém a vizinhança direta a partir de hellos periódicos e
+// popula o roteador com as rotas diretas e de dois saltos correspondentes
+type HelloProtocol struct {
+	router   *MessageRouter
+	selfID   string
+	interval time.Duration
+
+	neighbors map[string]*helloNeighbor
+	sequence  uint32
+
+	mutex sync.Mutex
+}
+
+// NewHelloProtocol cria um novo gerenciador do protocolo hello
+func NewHelloProtocol(router *MessageRouter, selfID string, interval time.Duration) *HelloProtocol {
+	if interval <= 0 {
+		interval = DefaultHelloInterval
+	}
+
+	return &HelloProtocol{
+		router:    router,
+		selfID:    selfID,
+		interval:  interval,
+		neighbors: make(map[string]*helloNeighbor),
+	}
+}
+
+// Interval retorna o intervalo entre hellos
+func (hp *HelloProtocol) Interval() time.Duration {
+	return hp.interval
+}
+
+// BuildHello monta o próximo hello a ser enviado, anunciando os vizinhos
+// diretos atuais com a qualidade de enlace informada por quality
+func (hp *HelloProtocol) BuildHello(quality func(peerID string) int) *protocol.Hello {
+	hp.mutex.Lock()
+	defer hp.mutex.Unlock()
+
+	hp.sequence++
+	hello := &protocol.Hello{Sequence: hp.sequence}
+	for peerID := range hp.neighbors {
+		q := 100
+		if quality != nil {
+			q = quality(peerID)
+		}
+		hello.Neighbors = append(hello.Neighbors, protocol.NeighborInfo{
+			PeerID:  []byte(peerID),
+			Quality: uint8(q),
+		})
+	}
+
+	return hello
+}
+
+// HandleHello processa um hello recebido de um vizinho direto.
+// Retorna true se o vizinho é novo.
+func (hp *HelloProtocol) HandleHello(senderID string, hello *protocol.Hello) bool {
+	if senderID == "" || senderID == hp.selfID {
+		return false
+	}
+
+	announced := make(map[string]bool, len(hello.Neighbors))
+	for _, n := range hello.Neighbors {
+		peerID := string(n.PeerID)
+		if peerID != hp.selfID && peerID != senderID {
+			announced[peerID] = true
+		}
+	}
+
+	hp.mutex.Lock()
+	neighbor, known := hp.neighbors[senderID]
+	if !known {
+		neighbor = &helloNeighbor{}
+		hp.neighbors[senderID] = neighbor
+	}
+	previous := neighbor.neighbors
+	neighbor.lastSeen = time.Now()
+	neighbor.neighbors = announced
+	neighbor.flags = hello.Flags
+	hp.mutex.Unlock()
+
+	// Vizinhos que não retransmitem são evitados como intermediários
+	hp.router.SetRelayCapable(senderID, hello.Flags&protocol.HelloFlagNoRelay == 0)
+
+	// Rota direta para o vizinho e rotas de dois saltos através dele
+	hp.router.UpdateRoute(senderID, "", 1)
+	for peerID := range announced {
+		hp.router.UpdateRoute(peerID, senderID, 2)
+	}
+
+	// Vizinhos que o remetente deixou de anunciar não são mais alcançáveis por ele
+	for peerID := range previous {
+		if !announced[peerID] {
+			hp.router.RemoveRoute(peerID, senderID)
+		}
+	}
+
+	return !known
+}
+
+// ExpireNeighbors remove vizinhos dos quais não se recebe hello há muito tempo
+// e as rotas que passavam por eles. Retorna os vizinhos removidos.
+func (hp *HelloProtocol) ExpireNeighbors() []string {
+	hp.mutex.Lock()
+	threshold := time.Now().Add(-hp.interval * helloHoldMultiplier)
+	var expired []string
+	for peerID, neighbor := range hp.neighbors {
+		if neighbor.lastSeen.Before(threshold) {
+			expired = append(expired, peerID)
+			delete(hp.neighbors, peerID)
+		}
+	}
+	hp.mutex.Unlock()
+
+	for _, peerID := range expired {
+		hp.router.RemovePeer(peerID)
+	}
+
+	return expired
+}
+
+// Neighbors retorna os vizinhos diretos ativos
+func (hp *HelloProtocol) Neighbors() []string {
+	hp.mutex.Lock()
+	defer hp.mutex.Unlock()
+
+	peers := make([]string, 0, len(hp.neighbors))
+	for peerID := range hp.neighbors {
+		peers = append(peers, peerID)
+	}
+	return peers
+}
+
+// Flags retorna as capacidades anunciadas por um vizinho direto
+func (hp *HelloProtocol) Flags(peerID string) (uint8, bool) {
+	hp.mutex.Lock()
+	defer hp.mutex.Unlock()
+
+	neighbor, ok := hp.neighbors[peerID]
+	if !ok {
+		return 0, false
+	}
+	return neighbor.flags, true
+}
+
+// Remove descarta um vizinho
+func (hp *HelloProtocol) Remove(peerID string) {
+	hp.mutex.Lock()
+	defer hp.mutex.Unlock()
+
+	delete(hp.neighbors, peerID)
+}
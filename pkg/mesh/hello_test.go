@@ -0,0 +1,98 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+func TestHelloProtocol(t *testing.T) {
+	routerA := NewMessageRouter()
+	nodeA := NewHelloProtocol(routerA, "A", 10*time.Millisecond)
+
+	// B é vizinho de A e anuncia C e D como seus vizinhos
+	nodeB := NewHelloProtocol(NewMessageRouter(), "B", 0)
+	nodeB.HandleHello("C", &protocol.Hello{Sequence: 1})
+	nodeB.HandleHello("D", &protocol.Hello{Sequence: 1})
+	nodeB.HandleHello("A", &protocol.Hello{Sequence: 1})
+
+	if !nodeA.HandleHello("B", nodeB.BuildHello(nil)) {
+		t.Error("B deveria ser um vizinho novo")
+	}
+
+	if nextHop, ok := routerA.GetNextHop("B"); !ok || nextHop != "B" {
+		t.Errorf("Rota direta para B esperada, obtido %s (%v)", nextHop, ok)
+	}
+	for _, peerID := range []string{"C", "D"} {
+		nextHop, ok := routerA.GetNextHop(peerID)
+		if !ok || nextHop != "B" {
+			t.Errorf("Rota para %s via B esperada, obtido %s (%v)", peerID, nextHop, ok)
+		}
+		if hops, _ := routerA.GetRouteHops(peerID); hops != 2 {
+			t.Errorf("Rota para %s deveria ter 2 saltos, obtido %d", peerID, hops)
+		}
+	}
+	if _, ok := routerA.GetNextHop("A"); ok {
+		t.Error("A não deveria ter rota para si mesmo")
+	}
+
+	// B deixa de anunciar D
+	nodeA.HandleHello("B", &protocol.Hello{
+		Sequence:  2,
+		Neighbors: []protocol.NeighborInfo{{PeerID: []byte("C"), Quality: 100}},
+	})
+	if _, ok := routerA.GetNextHop("D"); ok {
+		t.Error("Rota para D deveria ter sido removida")
+	}
+
+	// Sem hellos, B expira levando consigo as rotas através dele
+	time.Sleep(40 * time.Millisecond)
+	if expired := nodeA.ExpireNeighbors(); len(expired) != 1 || expired[0] != "B" {
+		t.Fatalf("B deveria expirar, obtido %v", expired)
+	}
+	if _, ok := routerA.GetNextHop("C"); ok {
+		t.Error("Rota para C via B deveria ter sido removida")
+	}
+}
+
+func TestHelloRelayCapability(t *testing.T) {
+	router := NewMessageRouter()
+	node := NewHelloProtocol(router, "A", 0)
+
+	// B e C alcançam D; B está em modo de bateria ultra baixa e não retransmite
+	node.HandleHello("B", &protocol.Hello{
+		Sequence:  1,
+		Neighbors: []protocol.NeighborInfo{{PeerID: []byte("D"), Quality: 100}},
+		Flags:     protocol.HelloFlagNoRelay,
+	})
+	node.HandleHello("C", &protocol.Hello{
+		Sequence:  1,
+		Neighbors: []protocol.NeighborInfo{{PeerID: []byte("D"), Quality: 100}},
+		Flags:     protocol.HelloFlagMainsPowered,
+	})
+
+	if nextHop, _ := router.GetNextHop("D"); nextHop != "C" {
+		t.Errorf("Vizinho que retransmite deveria ser preferido, obtido %s", nextHop)
+	}
+	if nextHop, _ := router.GetNextHop("B"); nextHop != "B" {
+		t.Errorf("B continua alcançável diretamente, obtido %s", nextHop)
+	}
+	if flags, ok := node.Flags("C"); !ok || flags != protocol.HelloFlagMainsPowered {
+		t.Errorf("Capacidades de C não registradas: %d (%v)", flags, ok)
+	}
+
+	// B volta a retransmitir e C deixa de retransmitir
+	node.HandleHello("C", &protocol.Hello{
+		Sequence:  2,
+		Neighbors: []protocol.NeighborInfo{{PeerID: []byte("D"), Quality: 100}},
+		Flags:     protocol.HelloFlagNoRelay,
+	})
+	node.HandleHello("B", &protocol.Hello{
+		Sequence:  2,
+		Neighbors: []protocol.NeighborInfo{{PeerID: []byte("D"), Quality: 100}},
+	})
+	if nextHop, _ := router.GetNextHop("D"); nextHop != "B" {
+		t.Errorf("Rota deveria migrar para B, obtido %s", nextHop)
+	}
+}
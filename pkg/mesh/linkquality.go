@@ -0,0 +1,236 @@
+package mesh
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// Faixa de RSSI considerada para o cálculo de qualidade (dBm)
+	rssiFloor   = -100
+	rssiCeiling = -40
+
+	// Faixa de latência considerada para o cálculo de qualidade
+	latencyGood = 50 * time.Millisecond
+	latencyBad  = 2 * time.Second
+
+	// Janela de pacotes esperados antes de reduzir o histórico de perdas
+	lossWindow = 100
+
+	// Fator de suavização (EWMA) para RSSI e latência
+	smoothingFactor = 0.25
+
+	// Pesos de cada componente na métrica composta
+	rssiWeight    = 0.4
+	lossWeight    = 0.4
+	latencyWeight = 0.2
+)
+
+// linkStats armazena as medições de um enlace com um vizinho direto
+type linkStats struct {
+	rssi        float64
+	hasRSSI     bool
+	latency     float64 // em milissegundos
+	hasLatency  bool
+	lastSeq     uint32
+	hasSeq      bool
+	expected    int
+	received    int
+	lastUpdated time.Time
+}
+
+// LinkQualityTracker calcula a qualidade dos enlaces com vizinhos diretos a
+// partir de RSSI, perda de pacotes (via números de sequência) e latência
+type LinkQualityTracker struct {
+	links map[string]*linkStats
+	mutex sync.RWMutex
+}
+
+// NewLinkQualityTracker cria um novo rastreador de qualidade de enlace
+func NewLinkQualityTracker() *LinkQualityTracker {
+	return &LinkQualityTracker{
+		links: make(map[string]*linkStats),
+	}
+}
+
+// RecordRSSI registra uma leitura de RSSI de um vizinho
+func (lq *LinkQualityTracker) RecordRSSI(peerID string, rssi int) {
+	if rssi == 0 {
+		return // 0 indica leitura indisponível
+	}
+
+	lq.mutex.Lock()
+	defer lq.mutex.Unlock()
+
+	stats := lq.getOrCreate(peerID)
+	if stats.hasRSSI {
+		stats.rssi = smooth(stats.rssi, float64(rssi))
+	} else {
+		stats.rssi = float64(rssi)
+		stats.hasRSSI = true
+	}
+}
+
+// RecordSequence registra o recebimento de um pacote numerado de um vizinho.
+// Lacunas na sequência são contabilizadas como pacotes perdidos.
+func (lq *LinkQualityTracker) RecordSequence(peerID string, seq uint32) {
+	lq.mutex.Lock()
+	defer lq.mutex.Unlock()
+
+	stats := lq.getOrCreate(peerID)
+	if !stats.hasSeq || seq <= stats.lastSeq {
+		// Primeiro pacote ou vizinho reiniciado: recomeçar a contagem
+		stats.lastSeq = seq
+		stats.hasSeq = true
+		stats.expected++
+		stats.received++
+	} else {
+		gap := int(seq - stats.lastSeq)
+		if gap > lossWindow {
+			gap = lossWindow
+		}
+		stats.expected += gap
+		stats.received++
+		stats.lastSeq = seq
+	}
+
+	// Manter o histórico limitado para refletir o estado recente do enlace
+	if stats.expected > lossWindow {
+		stats.expected /= 2
+		stats.received /= 2
+	}
+}
+
+// RecordLatency registra uma medição de tempo de ida e volta com um vizinho
+func (lq *LinkQualityTracker) RecordLatency(peerID string, rtt time.Duration) {
+	lq.mutex.Lock()
+	defer lq.mutex.Unlock()
+
+	stats := lq.getOrCreate(peerID)
+	ms := float64(rtt) / float64(time.Millisecond)
+	if stats.hasLatency {
+		stats.latency = smooth(stats.latency, ms)
+	} else {
+		stats.latency = ms
+		stats.hasLatency = true
+	}
+}
+
+// Quality retorna a qualidade composta (0-100) do enlace com um vizinho.
+// Componentes ainda não medidos contribuem com um valor neutro.
+func (lq *LinkQualityTracker) Quality(peerID string) int {
+	lq.mutex.RLock()
+	defer lq.mutex.RUnlock()
+
+	stats, ok := lq.links[peerID]
+	if !ok {
+		return 50
+	}
+
+	rssiScore := 50.0
+	if stats.hasRSSI {
+		rssiScore = scale(stats.rssi, rssiFloor, rssiCeiling)
+	}
+
+	lossScore := 50.0
+	if stats.expected > 0 {
+		lossScore = 100 * float64(stats.received) / float64(stats.expected)
+	}
+
+	latencyScore := 50.0
+	if stats.hasLatency {
+		good := float64(latencyGood) / float64(time.Millisecond)
+		bad := float64(latencyBad) / float64(time.Millisecond)
+		latencyScore = 100 - scale(stats.latency, good, bad)
+	}
+
+	return int(rssiWeight*rssiScore + lossWeight*lossScore + latencyWeight*latencyScore)
+}
+
+// RSSI retorna o RSSI suavizado de um vizinho
+func (lq *LinkQualityTracker) RSSI(peerID string) (int, bool) {
+	lq.mutex.RLock()
+	defer lq.mutex.RUnlock()
+
+	stats, ok := lq.links[peerID]
+	if !ok || !stats.hasRSSI {
+		return 0, false
+	}
+	return int(stats.rssi), true
+}
+
+// Latency retorna a latência suavizada de um vizinho
+func (lq *LinkQualityTracker) Latency(peerID string) (time.Duration, bool) {
+	lq.mutex.RLock()
+	defer lq.mutex.RUnlock()
+
+	stats, ok := lq.links[peerID]
+	if !ok || !stats.hasLatency {
+		return 0, false
+	}
+	return time.Duration(stats.latency * float64(time.Millisecond)), true
+}
+
+// Peers retorna os vizinhos com medições registradas
+func (lq *LinkQualityTracker) Peers() []string {
+	lq.mutex.RLock()
+	defer lq.mutex.RUnlock()
+
+	peers := make([]string, 0, len(lq.links))
+	for peerID := range lq.links {
+		peers = append(peers, peerID)
+	}
+	return peers
+}
+
+// Remove descarta as medições de um vizinho
+func (lq *LinkQualityTracker) Remove(peerID string) {
+	lq.mutex.Lock()
+	defer lq.mutex.Unlock()
+
+	delete(lq.links, peerID)
+}
+
+// Cleanup remove vizinhos sem medições recentes
+func (lq *LinkQualityTracker) Cleanup(maxAge time.Duration) []string {
+	lq.mutex.Lock()
+	defer lq.mutex.Unlock()
+
+	var removed []string
+	threshold := time.Now().Add(-maxAge)
+	for peerID, stats := range lq.links {
+		if stats.lastUpdated.Before(threshold) {
+			delete(lq.links, peerID)
+			removed = append(removed, peerID)
+		}
+	}
+	return removed
+}
+
+// getOrCreate obtém as estatísticas de um vizinho, criando-as se necessário
+// Deve ser chamada com o mutex adquirido
+func (lq *LinkQualityTracker) getOrCreate(peerID string) *linkStats {
+	stats, ok := lq.links[peerID]
+	if !ok {
+		stats = &linkStats{}
+		lq.links[peerID] = stats
+	}
+	stats.lastUpdated = time.Now()
+	return stats
+}
+
+// smooth aplica média móvel exponencial
+func smooth(current, sample float64) float64 {
+	return current + smoothingFactor*(sample-current)
+}
+
+// scale converte um valor para a faixa 0-100 de forma linear
+func scale(value, low, high float64) float64 {
+	if value <= low {
+		return 0
+	}
+	if value >= high {
+		return 100
+	}
+	return 100 * (value - low) / (high - low)
+}
@@ -0,0 +1,75 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLinkQualityTracker(t *testing.T) {
+	t.Run("Vizinho desconhecido tem qualidade neutra", func(t *testing.T) {
+		tracker := NewLinkQualityTracker()
+		if q := tracker.Quality("X"); q != 50 {
+			t.Errorf("Qualidade neutra esperada 50, obtido %d", q)
+		}
+	})
+
+	t.Run("Enlace bom supera enlace ruim", func(t *testing.T) {
+		tracker := NewLinkQualityTracker()
+
+		for seq := uint32(1); seq <= 10; seq++ {
+			tracker.RecordSequence("bom", seq)
+		}
+		tracker.RecordRSSI("bom", -45)
+		tracker.RecordLatency("bom", 30*time.Millisecond)
+
+		// Metade dos pacotes perdidos
+		for seq := uint32(2); seq <= 20; seq += 2 {
+			tracker.RecordSequence("ruim", seq)
+		}
+		tracker.RecordRSSI("ruim", -95)
+		tracker.RecordLatency("ruim", 1500*time.Millisecond)
+
+		good, bad := tracker.Quality("bom"), tracker.Quality("ruim")
+		if good < 90 {
+			t.Errorf("Enlace bom deveria ter qualidade alta, obtido %d", good)
+		}
+		if bad >= good || bad > 40 {
+			t.Errorf("Enlace ruim deveria ter qualidade baixa, obtido %d (bom: %d)", bad, good)
+		}
+	})
+
+	t.Run("Remoção descarta medições", func(t *testing.T) {
+		tracker := NewLinkQualityTracker()
+		tracker.RecordRSSI("A", -60)
+		tracker.Remove("A")
+
+		if len(tracker.Peers()) != 0 {
+			t.Error("Nenhum vizinho deveria permanecer após a remoção")
+		}
+	})
+}
+
+func TestRoutingPrefersBetterLink(t *testing.T) {
+	router := NewMessageRouter()
+	router.SetLinkQuality("B", 90)
+	router.SetLinkQuality("C", 30)
+
+	// Rota via vizinho de enlace ruim
+	router.UpdateRoute("D", "C", 2)
+	if nextHop, _ := router.GetNextHop("D"); nextHop != "C" {
+		t.Fatalf("Rota inicial esperada via C, obtido %s", nextHop)
+	}
+
+	// Rota de mesmo comprimento via vizinho melhor deve substituí-la
+	router.UpdateRoute("D", "B", 2)
+	if nextHop, _ := router.GetNextHop("D"); nextHop != "B" {
+		t.Errorf("Rota esperada via B, obtido %s", nextHop)
+	}
+
+	// Degradação do enlace com B faz a rota via C voltar a ser preferida
+	router.SetLinkQuality("B", 10)
+	router.UpdateRoute("D", "C", 2)
+	if nextHop, _ := router.GetNextHop("D"); nextHop != "C" {
+		t.Errorf("Rota esperada via C após degradação de B, obtido %s", nextHop)
+	}
+}
@@ -0,0 +1,127 @@
+package mesh
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMailboxCapacity é o número de pacotes guardados por peer
+	DefaultMailboxCapacity = 32
+
+	// DefaultMailboxTTL é por quanto tempo um pacote aguarda a leitura do peer
+	DefaultMailboxTTL = 10 * time.Minute
+)
+
+// mailboxEntry é um pacote codificado aguardando a leitura do destinatário
+type mailboxEntry struct {
+	data     []byte
+	queuedAt time.Time
+}
+
+// Mailbox guarda os pacotes que não puderam ser entregues a um vizinho para
+// que ele os leia (pull) ao se conectar, o que ajuda dispositivos que só se
+// conectam de tempos em tempos. Cada peer tem uma fila limitada: com a fila
+// cheia o pacote mais antigo é descartado, e pacotes não lidos expiram.
+type Mailbox struct {
+	capacity int
+	ttl      time.Duration
+	boxes    map[string][]mailboxEntry
+
+	mutex sync.Mutex
+}
+
+// NewMailbox cria uma caixa de correio. Valores não positivos usam os padrões.
+func NewMailbox(capacity int, ttl time.Duration) *Mailbox {
+	if capacity <= 0 {
+		capacity = DefaultMailboxCapacity
+	}
+	if ttl <= 0 {
+		ttl = DefaultMailboxTTL
+	}
+
+	return &Mailbox{
+		capacity: capacity,
+		ttl:      ttl,
+		boxes:    make(map[string][]mailboxEntry),
+	}
+}
+
+// Put guarda um pacote para um peer. Retorna false se o mesmo pacote já
+// aguarda a leitura, como acontece quando o envio é repetido.
+func (mb *Mailbox) Put(peerID string, data []byte, now time.Time) bool {
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	box := mb.boxes[peerID]
+	for _, entry := range box {
+		if bytes.Equal(entry.data, data) {
+			return false
+		}
+	}
+	if len(box) >= mb.capacity {
+		box = box[1:]
+	}
+	mb.boxes[peerID] = append(box, mailboxEntry{
+		data:     append([]byte(nil), data...),
+		queuedAt: now,
+	})
+	return true
+}
+
+// Take retira o pacote mais antigo ainda válido destinado a um peer
+func (mb *Mailbox) Take(peerID string, now time.Time) ([]byte, bool) {
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	box := mb.boxes[peerID]
+	for len(box) > 0 {
+		entry := box[0]
+		box = box[1:]
+		if now.Sub(entry.queuedAt) < mb.ttl {
+			mb.storeLocked(peerID, box)
+			return entry.data, true
+		}
+	}
+	delete(mb.boxes, peerID)
+	return nil, false
+}
+
+// Pending retorna quantos pacotes aguardam a leitura de um peer
+func (mb *Mailbox) Pending(peerID string) int {
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	return len(mb.boxes[peerID])
+}
+
+// Expire descarta os pacotes que não foram lidos a tempo e retorna quantos
+func (mb *Mailbox) Expire(now time.Time) int {
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	expired := 0
+	for peerID, box := range mb.boxes {
+		kept := box[:0]
+		for _, entry := range box {
+			if now.Sub(entry.queuedAt) < mb.ttl {
+				kept = append(kept, entry)
+			} else {
+				expired++
+			}
+		}
+		mb.storeLocked(peerID, kept)
+	}
+	return expired
+}
+
+// storeLocked atualiza a fila de um peer, removendo-a se estiver vazia.
+// Deve ser chamada com o mutex adquirido.
+func (mb *Mailbox) storeLocked(peerID string, box []mailboxEntry) {
+	if len(box) == 0 {
+		delete(mb.boxes, peerID)
+		return
+	}
+	mb.boxes[peerID] = box
+}
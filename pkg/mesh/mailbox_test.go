@@ -0,0 +1,60 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMailbox(t *testing.T) {
+	t.Run("Leitura em ordem de chegada", func(t *testing.T) {
+		mb := NewMailbox(4, time.Minute)
+		now := time.Now()
+		mb.Put("peer", []byte("a"), now)
+		mb.Put("peer", []byte("b"), now)
+
+		for _, want := range []string{"a", "b"} {
+			data, ok := mb.Take("peer", now)
+			if !ok || string(data) != want {
+				t.Fatalf("Pacote esperado %q, obtido %q (%v)", want, data, ok)
+			}
+		}
+		if _, ok := mb.Take("peer", now); ok {
+			t.Error("Caixa de correio deveria estar vazia")
+		}
+	})
+
+	t.Run("Pacotes repetidos e capacidade", func(t *testing.T) {
+		mb := NewMailbox(2, time.Minute)
+		now := time.Now()
+		mb.Put("peer", []byte("a"), now)
+		if mb.Put("peer", []byte("a"), now) {
+			t.Error("Pacote repetido não deveria ser guardado")
+		}
+
+		// Com a fila cheia o pacote mais antigo é descartado
+		mb.Put("peer", []byte("b"), now)
+		mb.Put("peer", []byte("c"), now)
+		if mb.Pending("peer") != 2 {
+			t.Fatalf("Esperados 2 pacotes, obtidos %d", mb.Pending("peer"))
+		}
+		if data, _ := mb.Take("peer", now); string(data) != "b" {
+			t.Errorf("Pacote mais antigo deveria ter sido descartado, obtido %q", data)
+		}
+	})
+
+	t.Run("Expiração", func(t *testing.T) {
+		mb := NewMailbox(4, time.Minute)
+		now := time.Now()
+		mb.Put("peer", []byte("antigo"), now)
+		mb.Put("peer", []byte("novo"), now.Add(50*time.Second))
+
+		if data, _ := mb.Take("peer", now.Add(70*time.Second)); string(data) != "novo" {
+			t.Errorf("Pacote expirado deveria ser ignorado, obtido %q", data)
+		}
+
+		mb.Put("outro", []byte("x"), now)
+		if expired := mb.Expire(now.Add(time.Minute)); expired != 1 || mb.Pending("outro") != 0 {
+			t.Errorf("Expiração inesperada: %d expirados, %d pendentes", expired, mb.Pending("outro"))
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package mesh
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHealMinPeers é o mínimo de peers novos para considerar uma partição curada
+	DefaultHealMinPeers = 2
+
+	// DefaultHealFraction é a fração mínima de peers novos em relação aos já alcançáveis
+	DefaultHealFraction = 0.5
+
+	// DefaultHealCooldown é o intervalo mínimo entre duas curas detectadas
+	DefaultHealCooldown = time.Minute
+)
+
+// PartitionDetector identifica a cura de uma partição da rede: o momento em
+// que um grupo de peers até então inalcançáveis volta a ser alcançável de uma
+// só vez, o que justifica sincronizar o histórico recente com o outro lado
+type PartitionDetector struct {
+	reachable map[string]bool
+
+	minPeers int
+	fraction float64
+	cooldown time.Duration
+	lastHeal time.Time
+
+	mutex sync.Mutex
+}
+
+// NewPartitionDetector cria um novo detector de partições.
+// Valores não positivos usam os padrões.
+func NewPartitionDetector(minPeers int, fraction float64, cooldown time.Duration) *PartitionDetector {
+	if minPeers <= 0 {
+		minPeers = DefaultHealMinPeers
+	}
+	if fraction <= 0 {
+		fraction = DefaultHealFraction
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultHealCooldown
+	}
+
+	return &PartitionDetector{
+		reachable: make(map[string]bool),
+		minPeers:  minPeers,
+		fraction:  fraction,
+		cooldown:  cooldown,
+	}
+}
+
+// Observe registra o conjunto atual de peers alcançáveis.
+// Se a mudança caracterizar a cura de uma partição, retorna os peers que
+// passaram a ser alcançáveis; caso contrário retorna nil.
+func (pd *PartitionDetector) Observe(reachable []string) []string {
+	pd.mutex.Lock()
+	defer pd.mutex.Unlock()
+
+	current := make(map[string]bool, len(reachable))
+	var added []string
+	for _, peerID := range reachable {
+		if current[peerID] {
+			continue
+		}
+		current[peerID] = true
+		if !pd.reachable[peerID] {
+			added = append(added, peerID)
+		}
+	}
+
+	previous := len(pd.reachable)
+	pd.reachable = current
+
+	if len(added) < pd.minPeers || float64(len(added)) < pd.fraction*float64(previous) {
+		return nil
+	}
+	if time.Since(pd.lastHeal) < pd.cooldown {
+		return nil
+	}
+
+	pd.lastHeal = time.Now()
+	return added
+}
+
+// MessageDigest retorna o resumo compacto de um ID de mensagem usado na
+// sincronização de histórico
+func MessageDigest(messageID string) uint64 {
+	hash := sha256.Sum256([]byte(messageID))
+	return binary.BigEndian.Uint64(hash[:8])
+}
@@ -0,0 +1,55 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPartitionDetector(t *testing.T) {
+	t.Run("Variações pequenas não são curas", func(t *testing.T) {
+		detector := NewPartitionDetector(2, 0.5, 0)
+
+		if healed := detector.Observe([]string{"A"}); healed != nil {
+			t.Errorf("Um único peer novo não deveria caracterizar cura, obtido %v", healed)
+		}
+		detector.Observe(nil)
+		if healed := detector.Observe([]string{"A"}); healed != nil {
+			t.Errorf("Retorno de um único peer não deveria caracterizar cura, obtido %v", healed)
+		}
+	})
+
+	t.Run("Grupo alcançável de uma vez", func(t *testing.T) {
+		detector := NewPartitionDetector(2, 0.5, time.Hour)
+
+		detector.Observe([]string{"A"})
+		healed := detector.Observe([]string{"A", "B", "C", "B"})
+		if len(healed) != 2 || healed[0] != "B" || healed[1] != "C" {
+			t.Fatalf("Esperada cura com B e C, obtido %v", healed)
+		}
+
+		// Grupo pequeno em relação à rede já alcançável
+		detector = NewPartitionDetector(2, 0.5, time.Nanosecond)
+		detector.Observe([]string{"A"})
+		detector.Observe([]string{"A", "B", "C", "D", "E", "F"})
+		if healed := detector.Observe([]string{"A", "B", "C", "D", "E", "F", "G", "H"}); healed != nil {
+			t.Errorf("Dois peers novos em seis não deveriam caracterizar cura, obtido %v", healed)
+		}
+	})
+
+	t.Run("Cooldown entre curas", func(t *testing.T) {
+		detector := NewPartitionDetector(2, 0.5, time.Hour)
+
+		detector.Observe([]string{"A"})
+		if healed := detector.Observe([]string{"A", "B", "C"}); healed == nil {
+			t.Fatal("Primeira cura deveria ser detectada")
+		}
+		detector.Observe([]string{"A"})
+		if healed := detector.Observe([]string{"A", "B", "C"}); healed != nil {
+			t.Errorf("Cura dentro do cooldown deveria ser ignorada, obtido %v", healed)
+		}
+	})
+
+	if MessageDigest("msg-1") == MessageDigest("msg-2") {
+		t.Error("Resumos de IDs diferentes deveriam diferir")
+	}
+}
@@ -0,0 +1,224 @@
+package mesh
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPresenceOnlineWindow é por quanto tempo um peer continua online
+	// após o último anúncio ou hello recebido, tolerando hellos perdidos
+	DefaultPresenceOnlineWindow = DefaultHelloInterval * helloHoldMultiplier
+
+	// DefaultPresenceRecentWindow é por quanto tempo um peer que parou de ser
+	// ouvido é considerado visto recentemente, antes de ficar offline
+	DefaultPresenceRecentWindow = 5 * time.Minute
+)
+
+// PresenceState classifica a disponibilidade de um peer
+type PresenceState int
+
+const (
+	PresenceOffline PresenceState = iota // Sem notícias há mais que a janela recente
+	PresenceRecent                       // Ouvido há pouco, mas não no momento
+	PresenceOnline                       // Conectado ou ouvido dentro da janela online
+)
+
+// String retorna o nome do estado
+func (ps PresenceState) String() string {
+	switch ps {
+	case PresenceOnline:
+		return "online"
+	case PresenceRecent:
+		return "visto recentemente"
+	default:
+		return "offline"
+	}
+}
+
+// PresenceEvent informa a mudança de estado de presença de um peer
+type PresenceEvent struct {
+	PeerID   string
+	State    PresenceState
+	Previous PresenceState
+	LastSeen time.Time
+}
+
+// presenceEntry acompanha a última notícia de um peer
+type presenceEntry struct {
+	lastSeen  time.Time
+	connected bool
+	state     PresenceState
+}
+
+// PresenceTracker classifica os peers conhecidos como online, vistos
+// recentemente ou offline a partir dos anúncios e hellos recebidos e do
+// estado das conexões diretas. Um peer conectado é sempre online; os demais
+// envelhecem conforme as janelas configuradas.
+type PresenceTracker struct {
+	onlineWindow time.Duration
+	recentWindow time.Duration
+	peers        map[string]*presenceEntry
+	mutex        sync.Mutex
+}
+
+// NewPresenceTracker cria um classificador de presença. Janelas não
+// positivas usam DefaultPresenceOnlineWindow e DefaultPresenceRecentWindow.
+func NewPresenceTracker(onlineWindow, recentWindow time.Duration) *PresenceTracker {
+	if onlineWindow <= 0 {
+		onlineWindow = DefaultPresenceOnlineWindow
+	}
+	if recentWindow <= 0 {
+		recentWindow = DefaultPresenceRecentWindow
+	}
+
+	return &PresenceTracker{
+		onlineWindow: onlineWindow,
+		recentWindow: recentWindow,
+		peers:        make(map[string]*presenceEntry),
+	}
+}
+
+// Seen registra um anúncio ou hello de um peer e retorna o evento de
+// presença se o estado mudou, ou nil
+func (pt *PresenceTracker) Seen(peerID string, now time.Time) *PresenceEvent {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	entry := pt.entryLocked(peerID)
+	if now.After(entry.lastSeen) {
+		entry.lastSeen = now
+	}
+	return pt.classifyLocked(peerID, entry, now)
+}
+
+// SetConnected registra a abertura ou a queda da conexão direta com um peer
+// e retorna o evento de presença se o estado mudou, ou nil. A queda não
+// torna o peer offline de imediato: ele ainda pode ser alcançado pela mesh.
+func (pt *PresenceTracker) SetConnected(peerID string, connected bool, now time.Time) *PresenceEvent {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	entry := pt.entryLocked(peerID)
+	if entry.connected == connected {
+		return nil
+	}
+	entry.connected = connected
+	if now.After(entry.lastSeen) {
+		entry.lastSeen = now
+	}
+	return pt.classifyLocked(peerID, entry, now)
+}
+
+// Update reclassifica todos os peers pelo tempo desde a última notícia e
+// retorna os eventos das mudanças de estado
+func (pt *PresenceTracker) Update(now time.Time) []PresenceEvent {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	var events []PresenceEvent
+	for peerID, entry := range pt.peers {
+		if event := pt.classifyLocked(peerID, entry, now); event != nil {
+			events = append(events, *event)
+		}
+	}
+	return events
+}
+
+// State retorna o estado de presença de um peer; peers desconhecidos são
+// offline
+func (pt *PresenceTracker) State(peerID string) PresenceState {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	if entry, ok := pt.peers[peerID]; ok {
+		return entry.state
+	}
+	return PresenceOffline
+}
+
+// LastSeen retorna o horário da última notícia de um peer
+func (pt *PresenceTracker) LastSeen(peerID string) (time.Time, bool) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	if entry, ok := pt.peers[peerID]; ok {
+		return entry.lastSeen, true
+	}
+	return time.Time{}, false
+}
+
+// Remove esquece um peer
+func (pt *PresenceTracker) Remove(peerID string) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	delete(pt.peers, peerID)
+}
+
+// entryLocked retorna o registro de um peer, criando-o offline se necessário.
+// Exige pt.mutex.
+func (pt *PresenceTracker) entryLocked(peerID string) *presenceEntry {
+	entry, ok := pt.peers[peerID]
+	if !ok {
+		entry = &presenceEntry{state: PresenceOffline}
+		pt.peers[peerID] = entry
+	}
+	return entry
+}
+
+// classifyLocked atualiza o estado de um peer e retorna o evento se ele
+// mudou. Exige pt.mutex.
+func (pt *PresenceTracker) classifyLocked(peerID string, entry *presenceEntry, now time.Time) *PresenceEvent {
+	state := PresenceOffline
+	age := now.Sub(entry.lastSeen)
+	switch {
+	case entry.connected || age <= pt.onlineWindow:
+		state = PresenceOnline
+	case age <= pt.recentWindow:
+		state = PresenceRecent
+	}
+	if state == entry.state {
+		return nil
+	}
+
+	event := &PresenceEvent{PeerID: peerID, State: state, Previous: entry.state, LastSeen: entry.lastSeen}
+	entry.state = state
+	return event
+}
+
+// DeliveryRoute é a forma de entrega escolhida para uma mensagem privada
+type DeliveryRoute int
+
+const (
+	RouteDirect  DeliveryRoute = iota // Direto ao vizinho conectado
+	RouteRelay                        // Pela mesh, por rota conhecida ou inundação
+	RouteMailbox                      // Guardada até o destinatário voltar ao alcance
+)
+
+// String retorna o nome da forma de entrega
+func (dr DeliveryRoute) String() string {
+	switch dr {
+	case RouteDirect:
+		return "direto"
+	case RouteRelay:
+		return "via mesh"
+	default:
+		return "caixa postal"
+	}
+}
+
+// ChooseRoute escolhe como entregar uma mensagem a um peer pelo seu estado de
+// presença e por ser ou não um vizinho direto. Peers vistos recentemente
+// ainda podem estar ao alcance por outros caminhos e recebem pela mesh; para
+// peers offline a mensagem aguarda, em vez de ocupar a rede com reenvios.
+func ChooseRoute(state PresenceState, neighbor bool) DeliveryRoute {
+	switch {
+	case state == PresenceOnline && neighbor:
+		return RouteDirect
+	case state == PresenceOffline:
+		return RouteMailbox
+	default:
+		return RouteRelay
+	}
+}
@@ -0,0 +1,104 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPresenceTracker(t *testing.T) {
+	t.Run("Envelhecimento de online a offline", func(t *testing.T) {
+		pt := NewPresenceTracker(30*time.Second, 5*time.Minute)
+		start := time.Now()
+
+		event := pt.Seen("peer", start)
+		if event == nil || event.State != PresenceOnline || event.Previous != PresenceOffline {
+			t.Fatalf("Primeira notícia deveria tornar o peer online: %+v", event)
+		}
+		if pt.Seen("peer", start.Add(10*time.Second)) != nil {
+			t.Error("Notícia repetida não deveria gerar evento")
+		}
+
+		events := pt.Update(start.Add(time.Minute))
+		if len(events) != 1 || events[0].State != PresenceRecent {
+			t.Fatalf("Peer deveria estar visto recentemente: %+v", events)
+		}
+		if len(pt.Update(start.Add(2*time.Minute))) != 0 {
+			t.Error("Estado mantido não deveria gerar evento")
+		}
+
+		events = pt.Update(start.Add(6 * time.Minute))
+		if len(events) != 1 || events[0].State != PresenceOffline || events[0].Previous != PresenceRecent {
+			t.Fatalf("Peer deveria estar offline: %+v", events)
+		}
+		if pt.State("peer") != PresenceOffline {
+			t.Errorf("Estado inesperado: %v", pt.State("peer"))
+		}
+
+		event = pt.Seen("peer", start.Add(7*time.Minute))
+		if event == nil || event.State != PresenceOnline {
+			t.Errorf("Peer deveria voltar a ficar online: %+v", event)
+		}
+	})
+
+	t.Run("Conexão direta mantém o peer online", func(t *testing.T) {
+		pt := NewPresenceTracker(30*time.Second, 5*time.Minute)
+		start := time.Now()
+
+		if event := pt.SetConnected("peer", true, start); event == nil || event.State != PresenceOnline {
+			t.Fatalf("Conexão deveria tornar o peer online: %+v", event)
+		}
+		if len(pt.Update(start.Add(time.Hour))) != 0 {
+			t.Error("Peer conectado não deveria envelhecer")
+		}
+
+		if pt.SetConnected("peer", false, start.Add(time.Hour)) != nil {
+			t.Error("Queda da conexão não deveria tornar o peer offline de imediato")
+		}
+		events := pt.Update(start.Add(time.Hour + time.Minute))
+		if len(events) != 1 || events[0].State != PresenceRecent {
+			t.Errorf("Peer desconectado deveria envelhecer: %+v", events)
+		}
+	})
+
+	t.Run("Peers desconhecidos e remoção", func(t *testing.T) {
+		pt := NewPresenceTracker(0, 0)
+		if pt.State("peer") != PresenceOffline {
+			t.Error("Peer desconhecido deveria ser offline")
+		}
+		if _, ok := pt.LastSeen("peer"); ok {
+			t.Error("Peer desconhecido não deveria ter horário")
+		}
+
+		now := time.Now()
+		pt.Seen("peer", now)
+		if seen, ok := pt.LastSeen("peer"); !ok || !seen.Equal(now) {
+			t.Errorf("Horário inesperado: %v", seen)
+		}
+
+		pt.Remove("peer")
+		if pt.State("peer") != PresenceOffline {
+			t.Error("Peer removido deveria ser offline")
+		}
+	})
+}
+
+func TestChooseRoute(t *testing.T) {
+	tests := []struct {
+		state    PresenceState
+		neighbor bool
+		want     DeliveryRoute
+	}{
+		{PresenceOnline, true, RouteDirect},
+		{PresenceOnline, false, RouteRelay},
+		{PresenceRecent, true, RouteRelay},
+		{PresenceRecent, false, RouteRelay},
+		{PresenceOffline, false, RouteMailbox},
+		{PresenceOffline, true, RouteMailbox},
+	}
+
+	for _, tt := range tests {
+		if got := ChooseRoute(tt.state, tt.neighbor); got != tt.want {
+			t.Errorf("ChooseRoute(%v, %v) = %v, esperado %v", tt.state, tt.neighbor, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,454 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// Priority define a prioridade de envio de um pacote (menor valor = mais urgente)
+type Priority int
+
+const (
+	PriorityControl Priority = iota // ACKs, troca de chaves, roteamento
+	PriorityPrivate                 // Mensagens privadas
+	PriorityChannel                 // Mensagens de canal e broadcast
+	PriorityFile                    // Fragmentos de arquivos e pacotes grandes
+	PriorityCover                   // Tráfego de cobertura
+
+	priorityLevels = int(PriorityCover) + 1
+)
+
+// String retorna o nome da prioridade
+func (p Priority) String() string {
+	switch p {
+	case PriorityControl:
+		return "control"
+	case PriorityPrivate:
+		return "private"
+	case PriorityChannel:
+		return "channel"
+	case PriorityFile:
+		return "file"
+	case PriorityCover:
+		return "cover"
+	default:
+		return "unknown"
+	}
+}
+
+// DropPolicy define qual pacote é descartado quando a fila está cheia
+type DropPolicy int
+
+const (
+	// DropLowestPriority descarta o pacote mais antigo da origem com mais
+	// pacotes no nível menos prioritário, ou o novo pacote se ele for o menos
+	// prioritário de todos
+	DropLowestPriority DropPolicy = iota
+
+	// DropOldest descarta o pacote há mais tempo na fila, qualquer que seja
+	// sua prioridade
+	DropOldest
+)
+
+// String retorna o nome da política, como aceito por ParseDropPolicy
+func (dp DropPolicy) String() string {
+	switch dp {
+	case DropLowestPriority:
+		return "lowest-priority"
+	case DropOldest:
+		return "oldest"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseDropPolicy converte o nome de uma política de descarte
+func ParseDropPolicy(name string) (DropPolicy, error) {
+	switch name {
+	case "lowest-priority":
+		return DropLowestPriority, nil
+	case "oldest":
+		return DropOldest, nil
+	}
+	return 0, fmt.Errorf("política de descarte desconhecida: %q (use lowest-priority ou oldest)", name)
+}
+
+// DroppedPacket é um pacote descartado ao reduzir a capacidade da fila
+type DroppedPacket struct {
+	Packet   *protocol.BitchatPacket
+	Priority Priority
+}
+
+// QueueStats é uma fotografia da ocupação de uma fila
+type QueueStats struct {
+	Length    int        // Pacotes aguardando
+	HighWater int        // Maior ocupação já atingida
+	Capacity  int        // Capacidade total (0 = ilimitada)
+	Policy    DropPolicy // Política de descarte com a fila cheia
+	Dropped   uint64     // Pacotes descartados
+}
+
+// PacketPriority classifica um pacote de acordo com seu tipo e destinatário
+func PacketPriority(packet *protocol.BitchatPacket) Priority {
+	switch packet.Type {
+	case protocol.MessageTypeMessage, protocol.MessageTypeText:
+		if isBroadcast(packet.RecipientID) {
+			return PriorityChannel
+		}
+		return PriorityPrivate
+	case protocol.MessageTypeTyping:
+		// Avisos de digitação são descartáveis e não atrasam controle
+		return PriorityChannel
+	case protocol.MessageTypeFragmentStart,
+		protocol.MessageTypeFragmentContinue,
+		protocol.MessageTypeFragmentEnd,
+		protocol.MessageTypeBenchProbe,
+		protocol.MessageTypeFileChunk:
+		return PriorityFile
+	default:
+		return PriorityControl
+	}
+}
+
+// PriorityQueue é uma fila de pacotes com múltiplos níveis de prioridade.
+// Pacotes de maior prioridade são sempre entregues antes dos de menor
+// prioridade. Dentro de um nível, os pacotes são entregues em rodízio entre
+// os peers de origem, para que um vizinho muito ativo não monopolize o
+// enlace; a ordem de chegada de cada origem é preservada.
+//
+// Quando a capacidade é atingida, um pacote é descartado para dar lugar ao
+// novo de acordo com a política de descarte (DropLowestPriority por padrão).
+// A fila registra a maior ocupação atingida, para dimensionar a capacidade.
+type PriorityQueue struct {
+	levels    [priorityLevels]fairLevel
+	size      int
+	capacity  int
+	policy    DropPolicy
+	highWater int
+	nextSeq   uint64 // Ordem de chegada do próximo pacote
+
+	// Pacotes descartados por nível de prioridade
+	dropped [priorityLevels]uint64
+
+	// Sinaliza a chegada de pacotes para consumidores bloqueados em Pop
+	notify chan struct{}
+
+	mutex sync.Mutex
+}
+
+// fairLevel é um nível de prioridade com uma fila por peer de origem,
+// atendidas em rodízio
+type fairLevel struct {
+	queues map[string][]queuedPacket
+	order  []string // Origens com pacotes, na ordem do rodízio
+	next   int      // Próxima origem a ser atendida
+	size   int
+}
+
+// queuedPacket é um pacote na fila com sua ordem de chegada
+type queuedPacket struct {
+	packet *protocol.BitchatPacket
+	seq    uint64
+}
+
+// push adiciona um pacote à fila de sua origem
+func (fl *fairLevel) push(packet *protocol.BitchatPacket, seq uint64) {
+	if fl.queues == nil {
+		fl.queues = make(map[string][]queuedPacket)
+	}
+
+	source := string(packet.SenderID)
+	if len(fl.queues[source]) == 0 {
+		fl.order = append(fl.order, source)
+	}
+	fl.queues[source] = append(fl.queues[source], queuedPacket{packet: packet, seq: seq})
+	fl.size++
+}
+
+// pop remove o próximo pacote do rodízio
+func (fl *fairLevel) pop() *protocol.BitchatPacket {
+	if fl.size == 0 {
+		return nil
+	}
+	if fl.next >= len(fl.order) {
+		fl.next = 0
+	}
+
+	source := fl.order[fl.next]
+	packet := fl.removeFirst(fl.next)
+	if len(fl.order) > 0 && fl.order[fl.next%len(fl.order)] == source {
+		fl.next++
+	}
+	return packet
+}
+
+// dropFromLargest remove o pacote mais antigo da origem com mais pacotes
+func (fl *fairLevel) dropFromLargest() *protocol.BitchatPacket {
+	largest := -1
+	for i, source := range fl.order {
+		if largest < 0 || len(fl.queues[source]) > len(fl.queues[fl.order[largest]]) {
+			largest = i
+		}
+	}
+	if largest < 0 {
+		return nil
+	}
+
+	return fl.removeFirst(largest)
+}
+
+// oldest retorna a posição no rodízio da origem cujo primeiro pacote chegou
+// há mais tempo e a ordem de chegada desse pacote
+func (fl *fairLevel) oldest() (int, uint64, bool) {
+	index := -1
+	var seq uint64
+	for i, source := range fl.order {
+		if head := fl.queues[source][0].seq; index < 0 || head < seq {
+			index, seq = i, head
+		}
+	}
+	return index, seq, index >= 0
+}
+
+// removeFirst remove o primeiro pacote da origem na posição index do rodízio,
+// retirando a origem do rodízio quando sua fila esvazia
+func (fl *fairLevel) removeFirst(index int) *protocol.BitchatPacket {
+	source := fl.order[index]
+	queue := fl.queues[source]
+
+	packet := queue[0].packet
+	queue[0] = queuedPacket{}
+	queue = queue[1:]
+	fl.size--
+
+	if len(queue) > 0 {
+		fl.queues[source] = queue
+		return packet
+	}
+
+	delete(fl.queues, source)
+	fl.order = append(fl.order[:index], fl.order[index+1:]...)
+	if index < fl.next {
+		fl.next--
+	}
+	return packet
+}
+
+// NewPriorityQueue cria uma nova fila de prioridade com a capacidade total
+// informada (0 = ilimitada) e a política DropLowestPriority
+func NewPriorityQueue(capacity int) *PriorityQueue {
+	return &PriorityQueue{
+		capacity: capacity,
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// Push adiciona um pacote à fila com a prioridade informada.
+// Retorna o pacote descartado para abrir espaço (que pode ser o próprio
+// pacote recebido) e sua prioridade, ou nil se nada foi descartado.
+func (pq *PriorityQueue) Push(packet *protocol.BitchatPacket, priority Priority) (*protocol.BitchatPacket, Priority) {
+	if priority < PriorityControl || int(priority) >= priorityLevels {
+		priority = PriorityCover
+	}
+
+	pq.mutex.Lock()
+	var dropped *protocol.BitchatPacket
+	droppedPriority := priority
+
+	if pq.capacity > 0 && pq.size >= pq.capacity {
+		if pq.policy == DropLowestPriority && pq.lowestNonEmptyLevel() < int(priority) {
+			// O novo pacote é o menos prioritário: descartá-lo
+			pq.dropped[priority]++
+			pq.mutex.Unlock()
+			return packet, priority
+		}
+
+		dropped, droppedPriority = pq.dropLocked()
+	}
+
+	pq.levels[priority].push(packet, pq.nextSeq)
+	pq.nextSeq++
+	pq.size++
+	if pq.size > pq.highWater {
+		pq.highWater = pq.size
+	}
+	pq.mutex.Unlock()
+
+	select {
+	case pq.notify <- struct{}{}:
+	default:
+	}
+
+	return dropped, droppedPriority
+}
+
+// dropLocked descarta um pacote da fila de acordo com a política de
+// descarte. Deve ser chamada com o mutex adquirido e a fila não vazia.
+func (pq *PriorityQueue) dropLocked() (*protocol.BitchatPacket, Priority) {
+	victim := pq.lowestNonEmptyLevel()
+	var packet *protocol.BitchatPacket
+
+	if pq.policy == DropOldest {
+		index := -1
+		var oldestSeq uint64
+		for level := range pq.levels {
+			if i, seq, ok := pq.levels[level].oldest(); ok && (index < 0 || seq < oldestSeq) {
+				victim, index, oldestSeq = level, i, seq
+			}
+		}
+		packet = pq.levels[victim].removeFirst(index)
+	} else {
+		packet = pq.levels[victim].dropFromLargest()
+	}
+
+	pq.size--
+	pq.dropped[victim]++
+	return packet, Priority(victim)
+}
+
+// SetCapacity altera a capacidade total da fila (0 = ilimitada). Se a fila
+// tem mais pacotes que a nova capacidade, os excedentes são descartados de
+// acordo com a política de descarte e retornados.
+func (pq *PriorityQueue) SetCapacity(capacity int) []DroppedPacket {
+	pq.mutex.Lock()
+	defer pq.mutex.Unlock()
+
+	pq.capacity = capacity
+	var dropped []DroppedPacket
+	for capacity > 0 && pq.size > capacity {
+		packet, priority := pq.dropLocked()
+		dropped = append(dropped, DroppedPacket{Packet: packet, Priority: priority})
+	}
+	return dropped
+}
+
+// SetDropPolicy altera a política de descarte com a fila cheia
+func (pq *PriorityQueue) SetDropPolicy(policy DropPolicy) {
+	pq.mutex.Lock()
+	defer pq.mutex.Unlock()
+
+	pq.policy = policy
+}
+
+// lowestNonEmptyLevel retorna o nível menos prioritário com pacotes
+// Deve ser chamada com o mutex adquirido
+func (pq *PriorityQueue) lowestNonEmptyLevel() int {
+	for level := priorityLevels - 1; level > 0; level-- {
+		if pq.levels[level].size > 0 {
+			return level
+		}
+	}
+	return 0
+}
+
+// TryPop remove e retorna o pacote mais prioritário, sem bloquear
+func (pq *PriorityQueue) TryPop() (*protocol.BitchatPacket, Priority, bool) {
+	pq.mutex.Lock()
+	defer pq.mutex.Unlock()
+
+	for level := range pq.levels {
+		if pq.levels[level].size == 0 {
+			continue
+		}
+
+		packet := pq.levels[level].pop()
+		pq.size--
+		return packet, Priority(level), true
+	}
+
+	return nil, 0, false
+}
+
+// Pop remove e retorna o pacote mais prioritário, bloqueando até que haja
+// um pacote disponível ou o contexto seja cancelado
+func (pq *PriorityQueue) Pop(ctx context.Context) (*protocol.BitchatPacket, Priority, bool) {
+	for {
+		if packet, priority, ok := pq.TryPop(); ok {
+			return packet, priority, true
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, 0, false
+		case <-pq.notify:
+		}
+	}
+}
+
+// Len retorna o número total de pacotes na fila
+func (pq *PriorityQueue) Len() int {
+	pq.mutex.Lock()
+	defer pq.mutex.Unlock()
+
+	return pq.size
+}
+
+// LenAt retorna o número de pacotes em um nível de prioridade
+func (pq *PriorityQueue) LenAt(priority Priority) int {
+	pq.mutex.Lock()
+	defer pq.mutex.Unlock()
+
+	if priority < PriorityControl || int(priority) >= priorityLevels {
+		return 0
+	}
+	return pq.levels[priority].size
+}
+
+// Capacity retorna a capacidade total da fila (0 = ilimitada)
+func (pq *PriorityQueue) Capacity() int {
+	pq.mutex.Lock()
+	defer pq.mutex.Unlock()
+
+	return pq.capacity
+}
+
+// HighWater retorna a maior ocupação já atingida pela fila
+func (pq *PriorityQueue) HighWater() int {
+	pq.mutex.Lock()
+	defer pq.mutex.Unlock()
+
+	return pq.highWater
+}
+
+// Stats retorna a ocupação, a capacidade e os descartes da fila
+func (pq *PriorityQueue) Stats() QueueStats {
+	pq.mutex.Lock()
+	defer pq.mutex.Unlock()
+
+	stats := QueueStats{
+		Length:    pq.size,
+		HighWater: pq.highWater,
+		Capacity:  pq.capacity,
+		Policy:    pq.policy,
+	}
+	for _, count := range pq.dropped {
+		stats.Dropped += count
+	}
+	return stats
+}
+
+// Dropped retorna o número de pacotes descartados em um nível de prioridade
+func (pq *PriorityQueue) Dropped(priority Priority) uint64 {
+	pq.mutex.Lock()
+	defer pq.mutex.Unlock()
+
+	if priority < PriorityControl || int(priority) >= priorityLevels {
+		return 0
+	}
+	return pq.dropped[priority]
+}
+
+// TotalDropped retorna o número total de pacotes descartados
+func (pq *PriorityQueue) TotalDropped() uint64 {
+	pq.mutex.Lock()
+	defer pq.mutex.Unlock()
+
+	var total uint64
+	for _, count := range pq.dropped {
+		total += count
+	}
+	return total
+}
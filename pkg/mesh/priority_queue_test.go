@@ -0,0 +1,186 @@
+package mesh
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	t.Run("Controle antes de dados em massa", func(t *testing.T) {
+		pq := NewPriorityQueue(0)
+
+		cover := &protocol.BitchatPacket{ID: "cover"}
+		chunk := &protocol.BitchatPacket{ID: "chunk", Type: protocol.MessageTypeFragmentContinue}
+		channel := &protocol.BitchatPacket{ID: "channel", Type: protocol.MessageTypeMessage, RecipientID: protocol.BroadcastRecipient}
+		private := &protocol.BitchatPacket{ID: "private", Type: protocol.MessageTypeMessage, RecipientID: []byte("peer1")}
+		ack := &protocol.BitchatPacket{ID: "ack", Type: protocol.MessageTypeDeliveryAck}
+
+		pq.Push(cover, PriorityCover)
+		pq.Push(chunk, PacketPriority(chunk))
+		pq.Push(channel, PacketPriority(channel))
+		pq.Push(private, PacketPriority(private))
+		pq.Push(ack, PacketPriority(ack))
+
+		expected := []string{"ack", "private", "channel", "chunk", "cover"}
+		for _, id := range expected {
+			packet, _, ok := pq.TryPop()
+			if !ok {
+				t.Fatalf("Fila vazia antes do esperado, faltando %s", id)
+			}
+			if packet.ID != id {
+				t.Errorf("Esperado %s, obtido %s", id, packet.ID)
+			}
+		}
+
+		if pq.Len() != 0 {
+			t.Errorf("Fila deveria estar vazia, tamanho %d", pq.Len())
+		}
+	})
+
+	t.Run("Ordem de chegada dentro do mesmo nível", func(t *testing.T) {
+		pq := NewPriorityQueue(0)
+		pq.Push(&protocol.BitchatPacket{ID: "1"}, PriorityChannel)
+		pq.Push(&protocol.BitchatPacket{ID: "2"}, PriorityChannel)
+
+		first, _, _ := pq.TryPop()
+		second, _, _ := pq.TryPop()
+		if first.ID != "1" || second.ID != "2" {
+			t.Errorf("Ordem FIFO não preservada: %s, %s", first.ID, second.ID)
+		}
+	})
+
+	t.Run("Rodízio entre peers de origem", func(t *testing.T) {
+		pq := NewPriorityQueue(0)
+
+		// Vizinho tagarela enfileira várias mensagens antes dos demais
+		for _, id := range []string{"a1", "a2", "a3"} {
+			pq.Push(&protocol.BitchatPacket{ID: id, SenderID: []byte("alice")}, PriorityChannel)
+		}
+		pq.Push(&protocol.BitchatPacket{ID: "b1", SenderID: []byte("bob")}, PriorityChannel)
+		pq.Push(&protocol.BitchatPacket{ID: "c1", SenderID: []byte("carol")}, PriorityChannel)
+
+		expected := []string{"a1", "b1", "c1", "a2", "a3"}
+		for _, id := range expected {
+			packet, _, _ := pq.TryPop()
+			if packet.ID != id {
+				t.Errorf("Esperado %s, obtido %s", id, packet.ID)
+			}
+		}
+
+		// Com a fila cheia, a origem com mais pacotes perde o mais antigo
+		pq = NewPriorityQueue(3)
+		pq.Push(&protocol.BitchatPacket{ID: "b1", SenderID: []byte("bob")}, PriorityChannel)
+		pq.Push(&protocol.BitchatPacket{ID: "a1", SenderID: []byte("alice")}, PriorityChannel)
+		pq.Push(&protocol.BitchatPacket{ID: "a2", SenderID: []byte("alice")}, PriorityChannel)
+		dropped, _ := pq.Push(&protocol.BitchatPacket{ID: "c1", SenderID: []byte("carol")}, PriorityChannel)
+		if dropped == nil || dropped.ID != "a1" {
+			t.Errorf("Esperado descarte de a1, obtido %v", dropped)
+		}
+	})
+
+	t.Run("Pop bloqueia até chegada ou cancelamento", func(t *testing.T) {
+		pq := NewPriorityQueue(0)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			pq.Push(&protocol.BitchatPacket{ID: "tarde"}, PriorityControl)
+		}()
+
+		packet, _, ok := pq.Pop(context.Background())
+		if !ok || packet.ID != "tarde" {
+			t.Fatal("Pop deveria retornar o pacote enfileirado")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, _, ok := pq.Pop(ctx); ok {
+			t.Error("Pop deveria retornar false após cancelamento")
+		}
+	})
+
+	t.Run("Descarte por excesso de carga", func(t *testing.T) {
+		pq := NewPriorityQueue(2)
+
+		pq.Push(&protocol.BitchatPacket{ID: "chan-1"}, PriorityChannel)
+		pq.Push(&protocol.BitchatPacket{ID: "chan-2"}, PriorityChannel)
+
+		// Novo pacote de controle descarta o canal mais antigo
+		dropped, priority := pq.Push(&protocol.BitchatPacket{ID: "ack"}, PriorityControl)
+		if dropped == nil || dropped.ID != "chan-1" || priority != PriorityChannel {
+			t.Fatalf("Esperado descarte de chan-1, obtido %v", dropped)
+		}
+
+		// Tráfego de cobertura com fila cheia é descartado diretamente
+		dropped, priority = pq.Push(&protocol.BitchatPacket{ID: "cover"}, PriorityCover)
+		if dropped == nil || dropped.ID != "cover" || priority != PriorityCover {
+			t.Fatalf("Esperado descarte do próprio pacote de cobertura, obtido %v", dropped)
+		}
+
+		if pq.Len() != 2 {
+			t.Errorf("Fila deveria permanecer com 2 pacotes, obtido %d", pq.Len())
+		}
+		if pq.Dropped(PriorityChannel) != 1 || pq.Dropped(PriorityCover) != 1 || pq.TotalDropped() != 2 {
+			t.Errorf("Contadores de descarte incorretos: canal=%d cobertura=%d total=%d",
+				pq.Dropped(PriorityChannel), pq.Dropped(PriorityCover), pq.TotalDropped())
+		}
+
+		first, _, _ := pq.TryPop()
+		if first.ID != "ack" {
+			t.Errorf("Controle deveria sair primeiro, obtido %s", first.ID)
+		}
+	})
+
+	t.Run("Descarte do mais antigo", func(t *testing.T) {
+		pq := NewPriorityQueue(2)
+		pq.SetDropPolicy(DropOldest)
+
+		pq.Push(&protocol.BitchatPacket{ID: "ack"}, PriorityControl)
+		pq.Push(&protocol.BitchatPacket{ID: "chan"}, PriorityChannel)
+
+		// Mesmo menos prioritário, o novo pacote entra e o mais antigo sai
+		dropped, priority := pq.Push(&protocol.BitchatPacket{ID: "cover"}, PriorityCover)
+		if dropped == nil || dropped.ID != "ack" || priority != PriorityControl {
+			t.Fatalf("Esperado descarte de ack, obtido %v", dropped)
+		}
+		if pq.LenAt(PriorityCover) != 1 || pq.Dropped(PriorityControl) != 1 {
+			t.Errorf("Pacote de cobertura deveria estar na fila: cobertura=%d descartes=%d",
+				pq.LenAt(PriorityCover), pq.Dropped(PriorityControl))
+		}
+	})
+
+	t.Run("Ocupação máxima e redução da capacidade", func(t *testing.T) {
+		pq := NewPriorityQueue(0)
+		for _, id := range []string{"1", "2", "3"} {
+			pq.Push(&protocol.BitchatPacket{ID: id}, PriorityChannel)
+		}
+		pq.Push(&protocol.BitchatPacket{ID: "ack"}, PriorityControl)
+		pq.TryPop()
+		pq.TryPop()
+
+		dropped := pq.SetCapacity(1)
+		if len(dropped) != 1 || dropped[0].Packet.ID != "2" || dropped[0].Priority != PriorityChannel {
+			t.Fatalf("Esperado descarte de 2, obtido %+v", dropped)
+		}
+
+		stats := pq.Stats()
+		expected := QueueStats{Length: 1, HighWater: 4, Capacity: 1, Policy: DropLowestPriority, Dropped: 1}
+		if stats != expected {
+			t.Errorf("Esperado %+v, obtido %+v", expected, stats)
+		}
+	})
+
+	t.Run("Nomes das políticas", func(t *testing.T) {
+		for _, policy := range []DropPolicy{DropLowestPriority, DropOldest} {
+			parsed, err := ParseDropPolicy(policy.String())
+			if err != nil || parsed != policy {
+				t.Errorf("Política %s não reconhecida: %v", policy, err)
+			}
+		}
+		if _, err := ParseDropPolicy("newest"); err == nil {
+			t.Error("Política desconhecida deveria ser rejeitada")
+		}
+	})
+}
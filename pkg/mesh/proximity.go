@@ -0,0 +1,141 @@
+package mesh
+
+import "sync"
+
+const (
+	// DefaultProximityThreshold é a variação, em dB, da média de RSSI que
+	// caracteriza um peer se aproximando ou se afastando
+	DefaultProximityThreshold = 6
+
+	// proximitySmoothing é o peso de cada nova leitura na média de RSSI
+	proximitySmoothing = 0.3
+
+	// proximityMinSamples é o número de leituras antes da primeira tendência
+	proximityMinSamples = 3
+)
+
+// ProximityTrend indica se um peer está se aproximando ou se afastando
+type ProximityTrend int
+
+const (
+	ProximityStable      ProximityTrend = iota // Sem tendência definida
+	ProximityApproaching                       // RSSI subindo
+	ProximityLeaving                           // RSSI caindo
+)
+
+// String retorna o nome da tendência
+func (pt ProximityTrend) String() string {
+	switch pt {
+	case ProximityApproaching:
+		return "aproximando"
+	case ProximityLeaving:
+		return "afastando"
+	default:
+		return "estável"
+	}
+}
+
+// ProximityEvent informa uma mudança de tendência no sinal de um peer
+type ProximityEvent struct {
+	PeerID string
+	Trend  ProximityTrend
+	RSSI   int // Média móvel do RSSI no momento da mudança
+}
+
+// proximityState acompanha a média de RSSI de um peer e o ponto de
+// referência a partir do qual a variação é medida
+type proximityState struct {
+	mean    float64
+	anchor  float64
+	samples int
+	trend   ProximityTrend
+}
+
+// ProximityTracker detecta peers se aproximando ou se afastando a partir da
+// tendência da média móvel do RSSI. Enquanto a tendência se mantém, a
+// referência acompanha o pico (ou o vale) do sinal, de modo que uma reversão
+// só é informada após variar o limiar inteiro a partir dele; oscilações
+// menores que o limiar não geram eventos.
+type ProximityTracker struct {
+	threshold float64
+	peers     map[string]*proximityState
+	mutex     sync.Mutex
+}
+
+// NewProximityTracker cria um detector de proximidade com o limiar em dB.
+// Valores não positivos usam DefaultProximityThreshold.
+func NewProximityTracker(threshold int) *ProximityTracker {
+	if threshold <= 0 {
+		threshold = DefaultProximityThreshold
+	}
+
+	return &ProximityTracker{
+		threshold: float64(threshold),
+		peers:     make(map[string]*proximityState),
+	}
+}
+
+// Record incorpora uma leitura de RSSI e retorna o evento de proximidade
+// quando a tendência do peer muda, ou nil
+func (pt *ProximityTracker) Record(peerID string, rssi int) *ProximityEvent {
+	if rssi == 0 {
+		return nil // 0 indica leitura indisponível
+	}
+
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	state, ok := pt.peers[peerID]
+	if !ok {
+		state = &proximityState{mean: float64(rssi), anchor: float64(rssi)}
+		pt.peers[peerID] = state
+	} else {
+		state.mean += proximitySmoothing * (float64(rssi) - state.mean)
+	}
+	state.samples++
+
+	// A referência acompanha o extremo na direção da tendência atual
+	switch {
+	case state.trend == ProximityApproaching && state.mean > state.anchor,
+		state.trend == ProximityLeaving && state.mean < state.anchor:
+		state.anchor = state.mean
+	}
+	if state.samples < proximityMinSamples {
+		return nil
+	}
+
+	trend := state.trend
+	delta := state.mean - state.anchor
+	switch {
+	case delta >= pt.threshold:
+		trend = ProximityApproaching
+	case delta <= -pt.threshold:
+		trend = ProximityLeaving
+	}
+	if trend == state.trend {
+		return nil
+	}
+
+	state.trend = trend
+	state.anchor = state.mean
+	return &ProximityEvent{PeerID: peerID, Trend: trend, RSSI: int(state.mean)}
+}
+
+// Trend retorna a tendência atual de um peer
+func (pt *ProximityTracker) Trend(peerID string) ProximityTrend {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	if state, ok := pt.peers[peerID]; ok {
+		return state.trend
+	}
+	return ProximityStable
+}
+
+// Remove descarta o histórico de um peer
+func (pt *ProximityTracker) Remove(peerID string) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	delete(pt.peers, peerID)
+}
@@ -0,0 +1,67 @@
+package mesh
+
+import "testing"
+
+func TestProximityTracker(t *testing.T) {
+	t.Run("Peer se aproximando e se afastando", func(t *testing.T) {
+		pt := NewProximityTracker(6)
+
+		var events []*ProximityEvent
+		for _, rssi := range []int{-90, -85, -80, -75, -70, -65, -60, -60, -70, -80, -90} {
+			if event := pt.Record("peer", rssi); event != nil {
+				events = append(events, event)
+			}
+		}
+
+		if len(events) != 2 {
+			t.Fatalf("Esperados 2 eventos, obtido %d", len(events))
+		}
+		if events[0].Trend != ProximityApproaching || events[1].Trend != ProximityLeaving {
+			t.Errorf("Tendências inesperadas: %v, %v", events[0].Trend, events[1].Trend)
+		}
+		if pt.Trend("peer") != ProximityLeaving {
+			t.Errorf("Tendência atual inesperada: %v", pt.Trend("peer"))
+		}
+	})
+
+	t.Run("Oscilação abaixo do limiar", func(t *testing.T) {
+		pt := NewProximityTracker(6)
+		for i := 0; i < 20; i++ {
+			rssi := -70
+			if i%2 == 0 {
+				rssi = -64
+			}
+			if event := pt.Record("peer", rssi); event != nil {
+				t.Fatalf("Oscilação não deveria gerar evento: %+v", event)
+			}
+		}
+	})
+
+	t.Run("Tendência mantida não se repete", func(t *testing.T) {
+		pt := NewProximityTracker(3)
+		count := 0
+		for rssi := -95; rssi <= -40; rssi += 5 {
+			if pt.Record("peer", rssi) != nil {
+				count++
+			}
+		}
+		if count != 1 {
+			t.Errorf("Aproximação contínua deveria gerar um evento, obtido %d", count)
+		}
+	})
+
+	t.Run("Leituras indisponíveis e remoção", func(t *testing.T) {
+		pt := NewProximityTracker(0)
+		if pt.Record("peer", 0) != nil || pt.Trend("peer") != ProximityStable {
+			t.Error("Leitura zero deveria ser ignorada")
+		}
+
+		for _, rssi := range []int{-50, -60, -70, -80} {
+			pt.Record("peer", rssi)
+		}
+		pt.Remove("peer")
+		if pt.Trend("peer") != ProximityStable {
+			t.Error("Peer removido não deveria ter tendência")
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package mesh
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimitBurst é por quantos segundos de taxa um limite acumula envios não
+// usados, permitindo rajadas curtas após um período ocioso
+const RateLimitBurst = 2
+
+// RateLimit é um limite de envio. Zero em um campo desativa aquele limite.
+type RateLimit struct {
+	MessagesPerSecond float64
+	BytesPerSecond    float64
+}
+
+// RateLimitConfig define os limites de envio de mensagens deste nó
+type RateLimitConfig struct {
+	Global          RateLimit // Todas as mensagens enviadas
+	PerConversation RateLimit // Cada canal ou conversa privada
+}
+
+// DefaultRateLimitConfig retorna limites que cabem com folga em um enlace
+// BLE; uso interativo nunca os alcança
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Global:          RateLimit{MessagesPerSecond: 10, BytesPerSecond: 8192},
+		PerConversation: RateLimit{MessagesPerSecond: 5},
+	}
+}
+
+// tokenBucket acumula envios permitidos à taxa do limite, até RateLimitBurst
+// segundos de taxa
+type tokenBucket struct {
+	messages float64
+	bytes    float64
+	updated  time.Time
+}
+
+// refill acumula os envios permitidos desde a última atualização
+func (b *tokenBucket) refill(limit RateLimit, now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	b.updated = now
+	if elapsed <= 0 {
+		return
+	}
+	b.messages = min(b.messages+elapsed*limit.MessagesPerSecond, limit.MessagesPerSecond*RateLimitBurst)
+	b.bytes = min(b.bytes+elapsed*limit.BytesPerSecond, limit.BytesPerSecond*RateLimitBurst)
+}
+
+// allows informa se o balde permite enviar uma mensagem. O limite de bytes
+// só exige saldo positivo, para que mensagens maiores que a rajada passem
+// com o balde cheio.
+func (b *tokenBucket) allows(limit RateLimit) bool {
+	if limit.MessagesPerSecond > 0 && b.messages < 1 {
+		return false
+	}
+	if limit.BytesPerSecond > 0 && b.bytes <= 0 {
+		return false
+	}
+	return true
+}
+
+// take desconta uma mensagem de size bytes dos limites ativos
+func (b *tokenBucket) take(limit RateLimit, size int) {
+	if limit.MessagesPerSecond > 0 {
+		b.messages--
+	}
+	if limit.BytesPerSecond > 0 {
+		b.bytes -= float64(size)
+	}
+}
+
+// full informa se o balde acumulou a rajada inteira, quando equivale a um
+// balde novo
+func (b *tokenBucket) full(limit RateLimit) bool {
+	return b.messages >= limit.MessagesPerSecond*RateLimitBurst && b.bytes >= limit.BytesPerSecond*RateLimitBurst
+}
+
+// RateLimiter limita a taxa de envio de mensagens deste nó, no total e por
+// conversa, protegendo o enlace BLE de rajadas de bots e scripts
+type RateLimiter struct {
+	config        RateLimitConfig
+	global        tokenBucket
+	conversations map[string]*tokenBucket
+	limited       uint64
+
+	mutex sync.Mutex
+}
+
+// NewRateLimiter cria um limitador com os baldes cheios
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	rl := &RateLimiter{conversations: make(map[string]*tokenBucket)}
+	rl.SetConfig(config)
+	return rl
+}
+
+// newBucket cria um balde cheio para o limite
+func newBucket(limit RateLimit, now time.Time) tokenBucket {
+	return tokenBucket{
+		messages: limit.MessagesPerSecond * RateLimitBurst,
+		bytes:    limit.BytesPerSecond * RateLimitBurst,
+		updated:  now,
+	}
+}
+
+// Allow decide se uma mensagem de size bytes pode ser enviada à conversa
+// agora. Se permitida, é descontada dos limites global e da conversa.
+func (rl *RateLimiter) Allow(conversation string, size int, now time.Time) bool {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	bucket, ok := rl.conversations[conversation]
+	if !ok {
+		created := newBucket(rl.config.PerConversation, now)
+		bucket = &created
+		rl.conversations[conversation] = bucket
+	}
+
+	rl.global.refill(rl.config.Global, now)
+	bucket.refill(rl.config.PerConversation, now)
+	if !rl.global.allows(rl.config.Global) || !bucket.allows(rl.config.PerConversation) {
+		rl.limited++
+		return false
+	}
+
+	rl.global.take(rl.config.Global, size)
+	bucket.take(rl.config.PerConversation, size)
+	return true
+}
+
+// SetConfig substitui os limites, recomeçando com os baldes cheios
+func (rl *RateLimiter) SetConfig(config RateLimitConfig) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	now := time.Now()
+	rl.config = config
+	rl.global = newBucket(config.Global, now)
+	rl.conversations = make(map[string]*tokenBucket)
+}
+
+// Config retorna os limites atuais
+func (rl *RateLimiter) Config() RateLimitConfig {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	return rl.config
+}
+
+// Limited retorna o número de mensagens recusadas pelos limites
+func (rl *RateLimiter) Limited() uint64 {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	return rl.limited
+}
+
+// Cleanup esquece as conversas ociosas, cujos baldes já se encheram
+func (rl *RateLimiter) Cleanup(now time.Time) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	for conversation, bucket := range rl.conversations {
+		bucket.refill(rl.config.PerConversation, now)
+		if bucket.full(rl.config.PerConversation) {
+			delete(rl.conversations, conversation)
+		}
+	}
+}
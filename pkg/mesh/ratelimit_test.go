@@ -0,0 +1,62 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("Limite de mensagens por conversa", func(t *testing.T) {
+		rl := NewRateLimiter(RateLimitConfig{PerConversation: RateLimit{MessagesPerSecond: 1}})
+		now := time.Now()
+
+		// A rajada permite RateLimitBurst mensagens seguidas
+		for i := 0; i < RateLimitBurst; i++ {
+			if !rl.Allow("#geral", 10, now) {
+				t.Fatalf("Mensagem %d deveria ser permitida", i)
+			}
+		}
+		if rl.Allow("#geral", 10, now) {
+			t.Error("Mensagem além da rajada deveria ser recusada")
+		}
+		if !rl.Allow("#outro", 10, now) {
+			t.Error("Outra conversa tem o próprio limite")
+		}
+		if !rl.Allow("#geral", 10, now.Add(time.Second)) {
+			t.Error("Limite deveria se recompor com o tempo")
+		}
+		if rl.Limited() != 1 {
+			t.Errorf("Esperada 1 mensagem recusada, obtidas %d", rl.Limited())
+		}
+	})
+
+	t.Run("Limite global de bytes", func(t *testing.T) {
+		rl := NewRateLimiter(RateLimitConfig{Global: RateLimit{BytesPerSecond: 100}})
+		now := time.Now()
+
+		// Uma mensagem maior que a rajada passa com o balde cheio
+		if !rl.Allow("#a", 500, now) {
+			t.Fatal("Mensagem grande deveria passar com o balde cheio")
+		}
+		if rl.Allow("#b", 1, now.Add(time.Second)) {
+			t.Error("Saldo negativo deveria recusar mensagens em qualquer conversa")
+		}
+		if !rl.Allow("#b", 1, now.Add(4*time.Second)) {
+			t.Error("Saldo deveria se recompor após o excesso")
+		}
+	})
+
+	t.Run("Sem limites", func(t *testing.T) {
+		rl := NewRateLimiter(RateLimitConfig{})
+		now := time.Now()
+		for i := 0; i < 100; i++ {
+			if !rl.Allow("#geral", 1000, now) {
+				t.Fatal("Sem limites todas as mensagens deveriam passar")
+			}
+		}
+		rl.Cleanup(now)
+		if len(rl.conversations) != 0 {
+			t.Errorf("Conversas ociosas deveriam ser esquecidas, restam %d", len(rl.conversations))
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package mesh
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultReassemblyTimeout é por quanto tempo os fragmentos de um pacote
+	// aguardam os demais
+	DefaultReassemblyTimeout = 30 * time.Second
+
+	// DefaultMaxReassemblies é o número de pacotes remontados ao mesmo tempo;
+	// acima disso, a remontagem mais antiga é abandonada
+	DefaultMaxReassemblies = 64
+
+	// MaxFragments é o maior número de fragmentos de um pacote, limitado pelo
+	// byte que o informa
+	MaxFragments = 255
+
+	// MaxReassembledSize é o maior pacote aceito na remontagem
+	MaxReassembledSize = 256 << 10
+)
+
+// Erros da remontagem de fragmentos
+var (
+	ErrInvalidFragment = errors.New("fragmento com índice ou total inválido")
+	ErrPacketTooLarge  = errors.New("pacote remontado excede o tamanho máximo")
+)
+
+// reassemblyKey identifica um pacote em remontagem. O ID do fragmento é
+// escolhido pelo remetente, então pacotes de origens diferentes com o mesmo
+// ID não se misturam. O total também faz parte da chave: um fragmento forjado
+// com outro total inicia uma remontagem à parte, sem impedir a legítima.
+type reassemblyKey struct {
+	source string
+	id     string
+	total  int
+}
+
+// partialPacket são os fragmentos já recebidos de um pacote
+type partialPacket struct {
+	fragments [][]byte // Por índice; nil enquanto não recebido
+	received  int
+	size      int
+	startedAt time.Time
+}
+
+// Reassembler remonta pacotes fragmentados. Fragmentos podem chegar fora de
+// ordem, repetidos e intercalados com os de outros pacotes; um fragmento
+// repetido mantém os dados do primeiro. Fragmentos com total diferente dos
+// já recebidos são remontados à parte, e remontagens incompletas expiram.
+type Reassembler struct {
+	partials map[reassemblyKey]*partialPacket
+	timeout  time.Duration
+	max      int
+	expired  uint64
+
+	mutex sync.Mutex
+}
+
+// NewReassembler cria um remontador. Valores não positivos usam os padrões.
+func NewReassembler(timeout time.Duration, maxReassemblies int) *Reassembler {
+	if timeout <= 0 {
+		timeout = DefaultReassemblyTimeout
+	}
+	if maxReassemblies <= 0 {
+		maxReassemblies = DefaultMaxReassemblies
+	}
+
+	return &Reassembler{
+		partials: make(map[reassemblyKey]*partialPacket),
+		timeout:  timeout,
+		max:      maxReassemblies,
+	}
+}
+
+// Add registra o fragmento index de total do pacote id enviado por source.
+// Retorna o pacote remontado, com ok true, quando o último fragmento que
+// faltava chega. Os dados do fragmento são copiados.
+func (r *Reassembler) Add(source string, id string, index int, total int, data []byte, now time.Time) (packet []byte, ok bool, err error) {
+	if total < 1 || total > MaxFragments || index < 0 || index >= total {
+		return nil, false, ErrInvalidFragment
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.pruneLocked(now)
+
+	key := reassemblyKey{source: source, id: id, total: total}
+	partial, exists := r.partials[key]
+	if !exists {
+		if len(r.partials) >= r.max {
+			r.evictOldestLocked()
+		}
+		partial = &partialPacket{fragments: make([][]byte, total), startedAt: now}
+		r.partials[key] = partial
+	}
+	if partial.fragments[index] != nil {
+		return nil, false, nil
+	}
+
+	if partial.size+len(data) > MaxReassembledSize {
+		delete(r.partials, key)
+		return nil, false, ErrPacketTooLarge
+	}
+	partial.fragments[index] = append(make([]byte, 0, len(data)), data...)
+	partial.received++
+	partial.size += len(data)
+
+	if partial.received < total {
+		return nil, false, nil
+	}
+
+	delete(r.partials, key)
+	packet = make([]byte, 0, partial.size)
+	for _, fragment := range partial.fragments {
+		packet = append(packet, fragment...)
+	}
+	return packet, true, nil
+}
+
+// Prune abandona as remontagens que expiraram e retorna quantas foram
+// abandonadas
+func (r *Reassembler) Prune(now time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.pruneLocked(now)
+}
+
+// pruneLocked abandona as remontagens expiradas. Exige r.mutex.
+func (r *Reassembler) pruneLocked(now time.Time) int {
+	pruned := 0
+	for key, partial := range r.partials {
+		if now.Sub(partial.startedAt) > r.timeout {
+			delete(r.partials, key)
+			pruned++
+		}
+	}
+	r.expired += uint64(pruned)
+	return pruned
+}
+
+// evictOldestLocked abandona a remontagem mais antiga. Exige r.mutex.
+func (r *Reassembler) evictOldestLocked() {
+	var oldest reassemblyKey
+	var oldestAt time.Time
+	first := true
+	for key, partial := range r.partials {
+		if first || partial.startedAt.Before(oldestAt) {
+			oldest, oldestAt, first = key, partial.startedAt, false
+		}
+	}
+	if !first {
+		delete(r.partials, oldest)
+		r.expired++
+	}
+}
+
+// DropSource abandona as remontagens de uma origem, como quando o vizinho
+// se desconecta
+func (r *Reassembler) DropSource(source string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key := range r.partials {
+		if key.source == source {
+			delete(r.partials, key)
+		}
+	}
+}
+
+// Len retorna o número de pacotes em remontagem
+func (r *Reassembler) Len() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return len(r.partials)
+}
+
+// Expired retorna o número de remontagens abandonadas por expiração ou por
+// excesso de remontagens simultâneas
+func (r *Reassembler) Expired() uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.expired
+}
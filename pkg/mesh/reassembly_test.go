@@ -0,0 +1,188 @@
+package mesh
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// splitFragments divide data em total fragmentos de tamanhos próximos
+func splitFragments(data []byte, total int) [][]byte {
+	fragments := make([][]byte, total)
+	size := (len(data) + total - 1) / total
+	for i := range fragments {
+		start, end := i*size, (i+1)*size
+		if start > len(data) {
+			start = len(data)
+		}
+		if end > len(data) {
+			end = len(data)
+		}
+		fragments[i] = data[start:end]
+	}
+	return fragments
+}
+
+func TestReassembler(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Fora de ordem e repetidos", func(t *testing.T) {
+		r := NewReassembler(0, 0)
+		data := []byte("fragmentos chegando em qualquer ordem")
+		fragments := splitFragments(data, 4)
+
+		for _, index := range []int{2, 0, 2, 3} {
+			if _, ok, err := r.Add("alice", "p1", index, 4, fragments[index], now); ok || err != nil {
+				t.Fatalf("Pacote não deveria estar completo: ok=%v err=%v", ok, err)
+			}
+		}
+
+		// Repetição com outros dados mantém o primeiro fragmento
+		r.Add("alice", "p1", 0, 4, []byte("outro"), now)
+
+		packet, ok, err := r.Add("alice", "p1", 1, 4, fragments[1], now)
+		if !ok || err != nil || !bytes.Equal(packet, data) {
+			t.Fatalf("Remontagem incorreta: %q ok=%v err=%v", packet, ok, err)
+		}
+		if r.Len() != 0 {
+			t.Errorf("Remontagem concluída deveria ser removida, restam %d", r.Len())
+		}
+	})
+
+	t.Run("Intercalados de pacotes e origens", func(t *testing.T) {
+		r := NewReassembler(0, 0)
+		packets := map[string][]byte{
+			"alice/p1": []byte("primeiro pacote de alice"),
+			"alice/p2": []byte("segundo pacote de alice"),
+			"bob/p1":   []byte("pacote de bob com o mesmo ID"),
+		}
+		keys := []struct{ source, id string }{{"alice", "p1"}, {"alice", "p2"}, {"bob", "p1"}}
+
+		for index := 0; index < 3; index++ {
+			for _, key := range keys {
+				name := key.source + "/" + key.id
+				fragment := splitFragments(packets[name], 3)[index]
+				packet, ok, err := r.Add(key.source, key.id, index, 3, fragment, now)
+				if err != nil {
+					t.Fatalf("Erro inesperado: %v", err)
+				}
+				if ok != (index == 2) {
+					t.Fatalf("%s completo no fragmento %d", name, index)
+				}
+				if ok && !bytes.Equal(packet, packets[name]) {
+					t.Errorf("%s remontado com dados de outro pacote: %q", name, packet)
+				}
+			}
+		}
+	})
+
+	t.Run("Fragmentos inconsistentes", func(t *testing.T) {
+		r := NewReassembler(0, 0)
+		for _, f := range []struct{ index, total int }{{0, 0}, {-1, 2}, {2, 2}, {0, MaxFragments + 1}} {
+			if _, _, err := r.Add("alice", "p1", f.index, f.total, nil, now); err != ErrInvalidFragment {
+				t.Errorf("Fragmento %d de %d: esperado ErrInvalidFragment, obtido %v", f.index, f.total, err)
+			}
+		}
+
+		// Um fragmento forjado com outro total não interrompe a remontagem
+		r.Add("alice", "p1", 0, 3, []byte("a"), now)
+		if _, ok, err := r.Add("alice", "p1", 1, 2, []byte("x"), now); ok || err != nil {
+			t.Errorf("Fragmento com outro total não deveria completar a remontagem: ok=%v err=%v", ok, err)
+		}
+		r.Add("alice", "p1", 1, 3, []byte("b"), now)
+		if packet, ok, _ := r.Add("alice", "p1", 2, 3, []byte("c"), now); !ok || string(packet) != "abc" {
+			t.Errorf("Fragmento forjado não deveria afetar a remontagem: %q", packet)
+		}
+		r.DropSource("alice")
+
+		big := make([]byte, MaxReassembledSize/2+1)
+		r.Add("alice", "big", 0, 2, big, now)
+		if _, _, err := r.Add("alice", "big", 1, 2, big, now); err != ErrPacketTooLarge {
+			t.Errorf("Esperado ErrPacketTooLarge, obtido %v", err)
+		}
+		if r.Len() != 0 {
+			t.Errorf("Remontagem grande demais deveria ser abandonada, restam %d", r.Len())
+		}
+	})
+
+	t.Run("Expiração e limite de remontagens", func(t *testing.T) {
+		r := NewReassembler(time.Second, 2)
+		r.Add("alice", "p1", 0, 2, []byte("a"), now)
+		r.Add("alice", "p2", 0, 2, []byte("a"), now.Add(100*time.Millisecond))
+
+		// Terceira remontagem abandona a mais antiga
+		r.Add("bob", "p1", 0, 2, []byte("b"), now.Add(200*time.Millisecond))
+		if r.Len() != 2 || r.Expired() != 1 {
+			t.Fatalf("Esperado 2 remontagens e 1 abandonada, obtido %d e %d", r.Len(), r.Expired())
+		}
+		if _, ok, _ := r.Add("alice", "p1", 1, 2, []byte("b"), now.Add(300*time.Millisecond)); ok {
+			t.Error("Remontagem abandonada não deveria ser concluída")
+		}
+
+		// A nova remontagem de alice/p1 abandonou alice/p2; bob/p1 expira antes
+		if pruned := r.Prune(now.Add(1250 * time.Millisecond)); pruned != 1 || r.Len() != 1 {
+			t.Errorf("Esperado 1 remontagem expirada e 1 restante, obtido %d e %d", pruned, r.Len())
+		}
+		r.DropSource("alice")
+		if r.Len() != 0 {
+			t.Errorf("Remontagens de alice deveriam ser abandonadas, restam %d", r.Len())
+		}
+	})
+}
+
+// FuzzReassembler entrega fragmentos de vários pacotes, de duas origens que
+// reutilizam os mesmos IDs, na ordem e com as repetições escolhidas pela
+// entrada, misturados a fragmentos inválidos para os mesmos pacotes. Todo
+// pacote remontado deve ser idêntico ao original.
+func FuzzReassembler(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11})
+	f.Add([]byte{11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0})
+	f.Add([]byte{0, 0, 250, 4, 4, 251, 8, 252, 1, 5, 9, 253, 2, 6, 10, 3, 7, 11})
+
+	type original struct {
+		source, id string
+		fragments  [][]byte
+		data       []byte
+	}
+	var packets []original
+	for i, source := range []string{"alice", "bob"} {
+		for j, id := range []string{"p1", "p2"} {
+			data := []byte(fmt.Sprintf("pacote %s de %s: %s", id, source, bytes.Repeat([]byte{byte('a' + i*2 + j)}, 10+i*7+j*3)))
+			total := 1 + (i*2+j)%4
+			packets = append(packets, original{source: source, id: id, fragments: splitFragments(data, total), data: data})
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, script []byte) {
+		r := NewReassembler(0, 3)
+		now := time.Now()
+
+		for step, op := range script {
+			p := packets[int(op)%len(packets)]
+			total := len(p.fragments)
+
+			var packet []byte
+			var ok bool
+			switch {
+			case op >= 250:
+				// Fragmento com total ou índice inválido para o mesmo pacote
+				if op%2 == 0 {
+					packet, ok, _ = r.Add(p.source, p.id, 0, total+1, []byte("lixo"), now)
+				} else {
+					packet, ok, _ = r.Add(p.source, p.id, total+int(op%5), total, []byte("lixo"), now)
+				}
+			default:
+				index := int(op/byte(len(packets))) % total
+				packet, ok, _ = r.Add(p.source, p.id, index, total, p.fragments[index], now)
+			}
+
+			if ok && !bytes.Equal(packet, p.data) {
+				t.Fatalf("Passo %d: %s/%s remontado incorretamente: %q", step, p.source, p.id, packet)
+			}
+			if r.Len() > 3 {
+				t.Fatalf("Passo %d: %d remontagens acima do limite", step, r.Len())
+			}
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package mesh
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultReconnectGap é a ausência mínima de um peer, ou de membros de um
+	// canal, para que o retorno dispare a sincronização das mensagens perdidas
+	DefaultReconnectGap = 2 * time.Minute
+
+	// DefaultReconnectMemory é por quanto tempo a última notícia de um peer ou
+	// canal é lembrada; depois disso o retorno conta como a primeira vista
+	DefaultReconnectMemory = 24 * time.Hour
+)
+
+// ReconnectTracker identifica o retorno de um peer ou da população de um
+// canal depois de um intervalo sem notícias, quando vale buscar as mensagens
+// perdidas. A primeira vista também conta como retorno: o próprio nó pode ter
+// ficado desligado.
+type ReconnectTracker struct {
+	// Última notícia de cada peer ou canal
+	lastSeen map[string]time.Time
+
+	// Ausência mínima para caracterizar retorno
+	gap time.Duration
+
+	mutex sync.Mutex
+}
+
+// NewReconnectTracker cria um novo rastreador de retornos; gap <= 0 usa
+// DefaultReconnectGap
+func NewReconnectTracker(gap time.Duration) *ReconnectTracker {
+	if gap <= 0 {
+		gap = DefaultReconnectGap
+	}
+
+	return &ReconnectTracker{
+		lastSeen: make(map[string]time.Time),
+		gap:      gap,
+	}
+}
+
+// Seen registra uma notícia de key (um peer ou um canal) e informa se ela
+// encerra um intervalo sem notícias de pelo menos gap
+func (rt *ReconnectTracker) Seen(key string, now time.Time) bool {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	last, known := rt.lastSeen[key]
+	rt.lastSeen[key] = now
+	return !known || now.Sub(last) >= rt.gap
+}
+
+// Prune esquece as notícias mais antigas que DefaultReconnectMemory
+func (rt *ReconnectTracker) Prune(now time.Time) {
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+
+	for key, last := range rt.lastSeen {
+		if now.Sub(last) > DefaultReconnectMemory {
+			delete(rt.lastSeen, key)
+		}
+	}
+}
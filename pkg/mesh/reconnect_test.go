@@ -0,0 +1,37 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconnectTracker(t *testing.T) {
+	t.Run("Primeira vista e retorno após o intervalo", func(t *testing.T) {
+		tracker := NewReconnectTracker(time.Minute)
+		now := time.Now()
+
+		if !tracker.Seen("A", now) {
+			t.Error("Primeira vista deveria contar como retorno")
+		}
+		if tracker.Seen("A", now.Add(30*time.Second)) {
+			t.Error("Notícias frequentes não deveriam contar como retorno")
+		}
+		if tracker.Seen("A", now.Add(80*time.Second)) {
+			t.Error("O intervalo deveria contar a partir da última notícia")
+		}
+		if !tracker.Seen("A", now.Add(3*time.Minute)) {
+			t.Error("Notícia após o intervalo deveria contar como retorno")
+		}
+	})
+
+	t.Run("Notícias antigas são esquecidas", func(t *testing.T) {
+		tracker := NewReconnectTracker(0)
+		now := time.Now()
+
+		tracker.Seen("#geral", now)
+		tracker.Prune(now.Add(DefaultReconnectMemory + time.Minute))
+		if len(tracker.lastSeen) != 0 {
+			t.Errorf("Esperado registro vazio, obtidas %d entradas", len(tracker.lastSeen))
+		}
+	})
+}
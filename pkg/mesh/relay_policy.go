@@ -0,0 +1,159 @@
+package mesh
+
+import (
+	"sync"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// Motivos de recusa de relay informados por RelayGovernor
+const (
+	RelayDeniedRateLimit  = "rate_limit"
+	RelayDeniedUnverified = "unverified"
+	RelayDeniedFile       = "file_transfer"
+	RelayDeniedBattery    = "battery"
+)
+
+// relayWindow é a janela usada para o limite de bytes retransmitidos
+const relayWindow = time.Minute
+
+// RelayPolicy define como este nó participa da retransmissão de pacotes alheios
+type RelayPolicy struct {
+	MaxBytesPerMinute    int  // Limite de bytes retransmitidos por minuto (0 = ilimitado)
+	VerifiedOnly         bool // Retransmitir apenas pacotes com assinatura verificada
+	RelayFileTransfers   bool // Retransmitir fragmentos de transferências de arquivos
+	RelayInUltraLowPower bool // Retransmitir mesmo no modo de bateria ultra baixa
+	MinBatteryLevel      int  // Nível de bateria abaixo do qual broadcasts não são retransmitidos (0 = desativado)
+}
+
+// DefaultRelayPolicy retorna a política de relay padrão
+func DefaultRelayPolicy() *RelayPolicy {
+	return &RelayPolicy{
+		MaxBytesPerMinute:    0,
+		VerifiedOnly:         false,
+		RelayFileTransfers:   true,
+		RelayInUltraLowPower: false,
+		MinBatteryLevel:      20,
+	}
+}
+
+// RelayConditions descreve o contexto de um pacote candidato a relay
+type RelayConditions struct {
+	SenderVerified bool // Assinatura do remetente verificada
+	UltraLowPower  bool // Nó em modo de bateria ultra baixa
+	BatteryKnown   bool // Nível de bateria disponível
+	BatteryLevel   int  // Nível de bateria (0-100)
+}
+
+// RelayGovernor aplica a política de relay e contabiliza o tráfego retransmitido
+type RelayGovernor struct {
+	policy RelayPolicy
+
+	// Bytes retransmitidos na janela atual
+	windowStart time.Time
+	windowBytes int
+
+	// Contadores
+	relayedPackets uint64
+	relayedBytes   uint64
+	denied         map[string]uint64
+
+	mutex sync.Mutex
+}
+
+// NewRelayGovernor cria um novo controlador de relay com a política informada
+// Se policy for nil, a política padrão é utilizada
+func NewRelayGovernor(policy *RelayPolicy) *RelayGovernor {
+	if policy == nil {
+		policy = DefaultRelayPolicy()
+	}
+
+	return &RelayGovernor{
+		policy:      *policy,
+		windowStart: time.Now(),
+		denied:      make(map[string]uint64),
+	}
+}
+
+// Allow decide se o pacote pode ser retransmitido segundo a política.
+// Se permitido, o tamanho do pacote é contabilizado no limite por minuto.
+// Retorna também o motivo da recusa, quando houver.
+func (rg *RelayGovernor) Allow(packet *protocol.BitchatPacket, conditions RelayConditions) (bool, string) {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+
+	if conditions.UltraLowPower && !rg.policy.RelayInUltraLowPower {
+		return rg.deny(RelayDeniedBattery)
+	}
+
+	if conditions.BatteryKnown && conditions.BatteryLevel < rg.policy.MinBatteryLevel && isBroadcast(packet.RecipientID) {
+		return rg.deny(RelayDeniedBattery)
+	}
+
+	if rg.policy.VerifiedOnly && !conditions.SenderVerified {
+		return rg.deny(RelayDeniedUnverified)
+	}
+
+	if !rg.policy.RelayFileTransfers && PacketPriority(packet) == PriorityFile {
+		return rg.deny(RelayDeniedFile)
+	}
+
+	size := PacketSize(packet)
+	if rg.policy.MaxBytesPerMinute > 0 {
+		now := time.Now()
+		if now.Sub(rg.windowStart) >= relayWindow {
+			rg.windowStart = now
+			rg.windowBytes = 0
+		}
+		if rg.windowBytes+size > rg.policy.MaxBytesPerMinute {
+			return rg.deny(RelayDeniedRateLimit)
+		}
+		rg.windowBytes += size
+	}
+
+	rg.relayedPackets++
+	rg.relayedBytes += uint64(size)
+	return true, ""
+}
+
+// deny registra uma recusa
+// Deve ser chamada com o mutex adquirido
+func (rg *RelayGovernor) deny(reason string) (bool, string) {
+	rg.denied[reason]++
+	return false, reason
+}
+
+// SetPolicy substitui a política de relay
+func (rg *RelayGovernor) SetPolicy(policy *RelayPolicy) {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+
+	rg.policy = *policy
+}
+
+// GetPolicy retorna uma cópia da política de relay atual
+func (rg *RelayGovernor) GetPolicy() RelayPolicy {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+
+	return rg.policy
+}
+
+// Stats retorna os pacotes e bytes retransmitidos e as recusas por motivo
+func (rg *RelayGovernor) Stats() (packets uint64, bytes uint64, denied map[string]uint64) {
+	rg.mutex.Lock()
+	defer rg.mutex.Unlock()
+
+	denied = make(map[string]uint64, len(rg.denied))
+	for reason, count := range rg.denied {
+		denied[reason] = count
+	}
+	return rg.relayedPackets, rg.relayedBytes, denied
+}
+
+// PacketSize estima o tamanho de um pacote na rede
+func PacketSize(packet *protocol.BitchatPacket) int {
+	// Cabeçalho fixo (versão, tipo, TTL, timestamp, tamanhos) + campos variáveis
+	return 16 + len(packet.SenderID) + len(packet.RecipientID) + len(packet.Payload) + len(packet.Signature)
+}
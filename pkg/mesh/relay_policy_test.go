@@ -0,0 +1,71 @@
+package mesh
+
+import (
+	"testing"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+func TestRelayGovernor(t *testing.T) {
+	message := &protocol.BitchatPacket{
+		Type:        protocol.MessageTypeMessage,
+		SenderID:    []byte("peer1"),
+		RecipientID: protocol.BroadcastRecipient,
+		Payload:     make([]byte, 100),
+	}
+	fragment := &protocol.BitchatPacket{
+		Type:     protocol.MessageTypeFragmentContinue,
+		SenderID: []byte("peer1"),
+		Payload:  make([]byte, 100),
+	}
+
+	t.Run("Política padrão", func(t *testing.T) {
+		governor := NewRelayGovernor(nil)
+
+		if ok, _ := governor.Allow(message, RelayConditions{}); !ok {
+			t.Error("Política padrão deveria permitir relay")
+		}
+		if ok, reason := governor.Allow(message, RelayConditions{UltraLowPower: true}); ok || reason != RelayDeniedBattery {
+			t.Errorf("Relay em bateria ultra baixa deveria ser recusado, motivo: %s", reason)
+		}
+
+		lowBattery := RelayConditions{BatteryKnown: true, BatteryLevel: 10}
+		if ok, reason := governor.Allow(message, lowBattery); ok || reason != RelayDeniedBattery {
+			t.Errorf("Broadcast com bateria baixa deveria ser recusado, motivo: %s", reason)
+		}
+		private := &protocol.BitchatPacket{Type: protocol.MessageTypeMessage, RecipientID: []byte("peer2")}
+		if ok, _ := governor.Allow(private, lowBattery); !ok {
+			t.Error("Pacote endereçado deveria ser retransmitido mesmo com bateria baixa")
+		}
+	})
+
+	t.Run("Restrições configuradas", func(t *testing.T) {
+		governor := NewRelayGovernor(&RelayPolicy{
+			MaxBytesPerMinute: 300,
+			VerifiedOnly:      true,
+		})
+
+		if ok, reason := governor.Allow(message, RelayConditions{}); ok || reason != RelayDeniedUnverified {
+			t.Errorf("Remetente não verificado deveria ser recusado, motivo: %s", reason)
+		}
+		if ok, reason := governor.Allow(fragment, RelayConditions{SenderVerified: true}); ok || reason != RelayDeniedFile {
+			t.Errorf("Fragmento de arquivo deveria ser recusado, motivo: %s", reason)
+		}
+
+		verified := RelayConditions{SenderVerified: true}
+		if ok, _ := governor.Allow(message, verified); !ok {
+			t.Fatal("Primeiro pacote deveria caber no limite")
+		}
+		if ok, _ := governor.Allow(message, verified); !ok {
+			t.Fatal("Segundo pacote deveria caber no limite")
+		}
+		if ok, reason := governor.Allow(message, verified); ok || reason != RelayDeniedRateLimit {
+			t.Errorf("Terceiro pacote deveria exceder o limite, motivo: %s", reason)
+		}
+
+		packets, _, denied := governor.Stats()
+		if packets != 2 || denied[RelayDeniedRateLimit] != 1 || denied[RelayDeniedUnverified] != 1 {
+			t.Errorf("Estatísticas incorretas: pacotes=%d recusas=%v", packets, denied)
+		}
+	})
+}
@@ -0,0 +1,303 @@
+package mesh
+
+import (
+	"sync"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+// Misbehavior identifica um tipo de comportamento indevido de um peer
+type Misbehavior string
+
+// Comportamentos penalizados
+const (
+	MisbehaviorInvalidSignature Misbehavior = "invalid_signature"
+	MisbehaviorMalformed        Misbehavior = "malformed"
+	MisbehaviorTTLAbuse         Misbehavior = "ttl_abuse"
+	MisbehaviorFlooding         Misbehavior = "flooding"
+)
+
+// misbehaviorPenalty define quantos pontos cada comportamento custa
+var misbehaviorPenalty = map[Misbehavior]float64{
+	MisbehaviorInvalidSignature: 20,
+	MisbehaviorMalformed:        10,
+	MisbehaviorTTLAbuse:         15,
+	MisbehaviorFlooding:         10,
+}
+
+// PeerStanding é a situação de um peer segundo sua pontuação
+type PeerStanding int
+
+const (
+	StandingGood          PeerStanding = iota // Tratamento normal
+	StandingDeprioritized                     // Pacotes retransmitidos com prioridade reduzida
+	StandingNoRelay                           // Pacotes não são mais retransmitidos
+	StandingIgnored                           // Pacotes são descartados
+)
+
+// String retorna o nome da situação para exibição
+func (s PeerStanding) String() string {
+	switch s {
+	case StandingDeprioritized:
+		return "despriorizado"
+	case StandingNoRelay:
+		return "sem relay"
+	case StandingIgnored:
+		return "ignorado"
+	default:
+		return "normal"
+	}
+}
+
+const (
+	// MaxPeerScore é a pontuação de um peer sem histórico de problemas
+	MaxPeerScore = 100
+
+	// Limites de pontuação de cada situação
+	ScoreDeprioritize = 70
+	ScoreNoRelay      = 40
+	ScoreIgnore       = 10
+
+	// ScoreRecoveryPerMinute é a recuperação gradual da pontuação
+	ScoreRecoveryPerMinute = 1
+
+	// DefaultFloodLimit é o máximo de pacotes por minuto recebidos de um vizinho
+	DefaultFloodLimit = 120
+
+	// MaxReputationPeers limita os registros mantidos; ao atingi-lo, o
+	// registro de melhor pontuação é descartado para dar lugar ao novo
+	MaxReputationPeers = 1024
+
+	floodWindow = time.Minute
+)
+
+// peerScore guarda a pontuação e o histórico de um peer
+type peerScore struct {
+	score   float64
+	updated time.Time
+	counts  map[Misbehavior]int
+
+	// Contagem de pacotes na janela atual para detecção de inundação
+	windowStart   time.Time
+	windowPackets int
+	floodReported bool
+}
+
+// PeerReputation é o relatório da pontuação de um peer
+type PeerReputation struct {
+	Score    int
+	Standing PeerStanding
+	Counts   map[Misbehavior]int
+}
+
+// Reputation pontua peers pelo comportamento observado na rede. A chave de
+// cada registro deve identificar o enlace por onde os pacotes chegaram, e não
+// o remetente declarado no pacote, que qualquer um pode forjar.
+type Reputation struct {
+	peers      map[string]*peerScore
+	floodLimit int
+	maxPeers   int
+	mutex      sync.Mutex
+}
+
+// NewReputation cria um novo registro de reputação.
+// floodLimit não positivo usa DefaultFloodLimit.
+func NewReputation(floodLimit int) *Reputation {
+	if floodLimit <= 0 {
+		floodLimit = DefaultFloodLimit
+	}
+
+	return &Reputation{
+		peers:      make(map[string]*peerScore),
+		floodLimit: floodLimit,
+		maxPeers:   MaxReputationPeers,
+	}
+}
+
+// CountsTowardFlood informa se um pacote entra no limite de inundação.
+// Fragmentos, pedaços de arquivo e cargas do benchmark chegam em rajadas
+// legítimas muito acima do limite e já são cadenciados pela fila de saída.
+func CountsTowardFlood(packet *protocol.BitchatPacket) bool {
+	if packet.Type == protocol.MessageTypeBenchReply {
+		return false
+	}
+	return PacketPriority(packet) != PriorityFile
+}
+
+// Penalize registra um comportamento indevido e retorna a nova situação do peer
+func (r *Reputation) Penalize(peerID string, kind Misbehavior) PeerStanding {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	peer := r.peerLocked(peerID)
+	peer.score -= misbehaviorPenalty[kind]
+	if peer.score < 0 {
+		peer.score = 0
+	}
+	peer.counts[kind]++
+
+	return standingFor(peer.score)
+}
+
+// RecordPacket contabiliza um pacote recebido do peer e penaliza, uma vez
+// por janela, quem exceder o limite de pacotes por minuto.
+// Retorna true se o peer está inundando a rede.
+func (r *Reputation) RecordPacket(peerID string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	peer := r.peerLocked(peerID)
+	now := time.Now()
+	if now.Sub(peer.windowStart) >= floodWindow {
+		peer.windowStart = now
+		peer.windowPackets = 0
+		peer.floodReported = false
+	}
+	peer.windowPackets++
+
+	if peer.windowPackets <= r.floodLimit {
+		return false
+	}
+	if !peer.floodReported {
+		peer.floodReported = true
+		peer.score -= misbehaviorPenalty[MisbehaviorFlooding]
+		if peer.score < 0 {
+			peer.score = 0
+		}
+		peer.counts[MisbehaviorFlooding]++
+	}
+	return true
+}
+
+// Standing retorna a situação atual do peer
+func (r *Reputation) Standing(peerID string) PeerStanding {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	peer, ok := r.peers[peerID]
+	if !ok {
+		return StandingGood
+	}
+	r.recoverLocked(peer)
+	return standingFor(peer.score)
+}
+
+// Report retorna a pontuação, a situação e o histórico de um peer
+func (r *Reputation) Report(peerID string) PeerReputation {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	report := PeerReputation{
+		Score:    MaxPeerScore,
+		Standing: StandingGood,
+		Counts:   make(map[Misbehavior]int),
+	}
+
+	peer, ok := r.peers[peerID]
+	if !ok {
+		return report
+	}
+	r.recoverLocked(peer)
+
+	report.Score = int(peer.score)
+	report.Standing = standingFor(peer.score)
+	for kind, count := range peer.counts {
+		report.Counts[kind] = count
+	}
+	return report
+}
+
+// Remove descarta o histórico de um peer
+func (r *Reputation) Remove(peerID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.peers, peerID)
+}
+
+// Cleanup descarta registros de peers com pontuação totalmente recuperada e
+// sem tráfego recente
+func (r *Reputation) Cleanup() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for peerID, peer := range r.peers {
+		r.recoverLocked(peer)
+		if peer.score >= MaxPeerScore && time.Since(peer.windowStart) >= floodWindow {
+			delete(r.peers, peerID)
+		}
+	}
+}
+
+// peerLocked retorna o registro de um peer, criando-o se necessário.
+// Deve ser chamada com o mutex adquirido.
+func (r *Reputation) peerLocked(peerID string) *peerScore {
+	peer, ok := r.peers[peerID]
+	if !ok {
+		if len(r.peers) >= r.maxPeers {
+			r.evictLocked()
+		}
+		peer = &peerScore{
+			score:   MaxPeerScore,
+			updated: time.Now(),
+			counts:  make(map[Misbehavior]int),
+		}
+		r.peers[peerID] = peer
+	}
+	r.recoverLocked(peer)
+	return peer
+}
+
+// evictLocked descarta o registro de melhor pontuação, o menos útil de
+// manter; entre iguais, o de tráfego mais antigo. Registros de peers
+// penalizados só saem depois dos que nada devem.
+// Deve ser chamada com o mutex adquirido.
+func (r *Reputation) evictLocked() {
+	var victim string
+	var best *peerScore
+	for peerID, peer := range r.peers {
+		r.recoverLocked(peer)
+		if best == nil || peer.score > best.score ||
+			(peer.score == best.score && peer.windowStart.Before(best.windowStart)) {
+			victim, best = peerID, peer
+		}
+	}
+	if best != nil {
+		delete(r.peers, victim)
+	}
+}
+
+// recoverLocked aplica a recuperação gradual da pontuação desde a última atualização.
+// Deve ser chamada com o mutex adquirido.
+func (r *Reputation) recoverLocked(peer *peerScore) {
+	now := time.Now()
+	peer.score += now.Sub(peer.updated).Minutes() * ScoreRecoveryPerMinute
+	if peer.score > MaxPeerScore {
+		peer.score = MaxPeerScore
+	}
+	peer.updated = now
+}
+
+// standingFor converte uma pontuação em situação
+func standingFor(value float64) PeerStanding {
+	score := int(value)
+	switch {
+	case score <= ScoreIgnore:
+		return StandingIgnored
+	case score <= ScoreNoRelay:
+		return StandingNoRelay
+	case score <= ScoreDeprioritize:
+		return StandingDeprioritized
+	default:
+		return StandingGood
+	}
+}
+
+// DemotePriority rebaixa uma prioridade em um nível
+func DemotePriority(priority Priority) Priority {
+	if priority < PriorityCover {
+		return priority + 1
+	}
+	return priority
+}
@@ -0,0 +1,99 @@
+package mesh
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+func TestReputation(t *testing.T) {
+	t.Run("Penalidades progressivas", func(t *testing.T) {
+		reputation := NewReputation(0)
+
+		if reputation.Standing("peer1") != StandingGood {
+			t.Error("Peer desconhecido deveria ter situação normal")
+		}
+
+		reputation.Penalize("peer1", MisbehaviorInvalidSignature)
+		reputation.Penalize("peer1", MisbehaviorMalformed)
+		if standing := reputation.Standing("peer1"); standing != StandingDeprioritized {
+			t.Errorf("Esperado despriorizado, obtido %s", standing)
+		}
+
+		reputation.Penalize("peer1", MisbehaviorInvalidSignature)
+		if standing := reputation.Penalize("peer1", MisbehaviorTTLAbuse); standing != StandingNoRelay {
+			t.Errorf("Esperado sem relay, obtido %s", standing)
+		}
+
+		reputation.Penalize("peer1", MisbehaviorInvalidSignature)
+		if standing := reputation.Penalize("peer1", MisbehaviorInvalidSignature); standing != StandingIgnored {
+			t.Errorf("Esperado ignorado, obtido %s", standing)
+		}
+
+		report := reputation.Report("peer1")
+		if report.Counts[MisbehaviorInvalidSignature] != 4 || report.Score > ScoreIgnore {
+			t.Errorf("Relatório inesperado: %+v", report)
+		}
+	})
+
+	t.Run("Detecção de inundação", func(t *testing.T) {
+		reputation := NewReputation(5)
+
+		for i := 0; i < 5; i++ {
+			if reputation.RecordPacket("peer2") {
+				t.Fatalf("Pacote %d não deveria caracterizar inundação", i+1)
+			}
+		}
+		for i := 0; i < 10; i++ {
+			if !reputation.RecordPacket("peer2") {
+				t.Fatal("Pacotes acima do limite deveriam caracterizar inundação")
+			}
+		}
+
+		// Penalizado uma única vez por janela
+		report := reputation.Report("peer2")
+		if report.Counts[MisbehaviorFlooding] != 1 || report.Score != MaxPeerScore-10 {
+			t.Errorf("Esperada uma penalidade por inundação, obtido %+v", report)
+		}
+	})
+
+	t.Run("Limite de registros", func(t *testing.T) {
+		reputation := NewReputation(0)
+		reputation.maxPeers = 3
+
+		reputation.Penalize("ruim", MisbehaviorMalformed)
+		for i := 0; i < 5; i++ {
+			reputation.RecordPacket(fmt.Sprintf("peer%d", i))
+		}
+
+		// Os registros sem penalidade dão lugar aos novos; o penalizado fica
+		if len(reputation.peers) != 3 {
+			t.Errorf("Esperados 3 registros, obtidos %d", len(reputation.peers))
+		}
+		if reputation.Report("ruim").Score != MaxPeerScore-10 {
+			t.Error("Registro penalizado não deveria ser descartado")
+		}
+	})
+
+	t.Run("Tráfego em massa fora do limite", func(t *testing.T) {
+		bulk := []protocol.MessageType{
+			protocol.MessageTypeFileChunk,
+			protocol.MessageTypeFragmentContinue,
+			protocol.MessageTypeBenchProbe,
+			protocol.MessageTypeBenchReply,
+		}
+		for _, msgType := range bulk {
+			if CountsTowardFlood(&protocol.BitchatPacket{Type: msgType}) {
+				t.Errorf("Tipo %d não deveria contar para inundação", msgType)
+			}
+		}
+		if !CountsTowardFlood(&protocol.BitchatPacket{Type: protocol.MessageTypeMessage}) {
+			t.Error("Mensagens deveriam contar para inundação")
+		}
+	})
+
+	if DemotePriority(PriorityPrivate) != PriorityChannel || DemotePriority(PriorityCover) != PriorityCover {
+		t.Error("Rebaixamento de prioridade incorreto")
+	}
+}
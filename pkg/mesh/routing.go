@@ -1,6 +1,10 @@
 package mesh
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
 	"sync"
 	"time"
 
@@ -8,8 +12,15 @@ import (
 	"github.com/permissionlesstech/bitchat/pkg/utils"
 )
 
-// MessageRouter gerencia o roteamento e deduplicação de mensagens na rede mesh
+// MessageRouter gerencia o roteamento, deduplicação e decisões de relay de
+// mensagens na rede mesh
 type MessageRouter struct {
+	// Configuração do roteador
+	config            *RoutingConfig
+	
+	// Peers bloqueados: pacotes deles não são processados nem retransmitidos
+	blockedPeers      map[string]bool
+	
 	// Cache de mensagens já processadas para deduplicação
 	processedMessages *utils.ExpiringSet
 	
@@ -19,70 +30,257 @@ type MessageRouter struct {
 	// Métricas de roteamento: peerID -> qualidade da conexão (0-100)
 	routingMetrics    map[string]int
 	
+	// Número de saltos das rotas conhecidas: peerID -> saltos
+	routingHops       map[string]int
+	
+	// Qualidade dos enlaces com vizinhos diretos: peerID -> qualidade (0-100)
+	linkQuality       map[string]int
+	
+	// Rotas alternativas conhecidas: peerID -> nextHop -> candidato
+	candidates        map[string]map[string]routeCandidate
+	
+	// Vizinhos que não se dispõem a retransmitir e são evitados como próximo hop
+	nonRelays         map[string]bool
+	
 	// Mutex para proteger a tabela de roteamento
 	routingMutex      sync.RWMutex
 	
 	// TTL padrão para mensagens
 	defaultTTL        uint8
 	
+	// Estimativa do diâmetro da rede para o TTL adaptativo
+	diameter          *DiameterEstimator
+	
+	// Fonte de aleatoriedade das decisões de gossip
+	random            func() float64
+	
 	// Tempo máximo de cache para deduplicação
 	dedupeTime        time.Duration
 }
 
-// NewMessageRouter cria um novo roteador de mensagens
+// nonRelayPenalty é o fator de redução da métrica de rotas através de
+// vizinhos que não se dispõem a retransmitir
+const nonRelayPenalty = 10
+
+// routeCandidate descreve uma rota conhecida por um determinado próximo hop
+type routeCandidate struct {
+	metric int
+	hops   int // 0 se desconhecido
+}
+
+// NewMessageRouter cria um novo roteador de mensagens com a configuração padrão
 func NewMessageRouter() *MessageRouter {
-	return &MessageRouter{
-		// Cache de 10 minutos com limpeza a cada minuto
-		processedMessages: utils.NewExpiringSet(10*time.Minute, 1*time.Minute),
-		routingTable:      make(map[string]string),
-		routingMetrics:    make(map[string]int),
-		defaultTTL:        5,  // TTL padrão: 5 hops
-		dedupeTime:        10*time.Minute,
-	}
+	return NewRouter(DefaultRoutingConfig())
 }
 
 // NewRouter cria um novo roteador de mensagens com configuração
+// Se config for nil, a configuração padrão é utilizada
 func NewRouter(config *RoutingConfig) *MessageRouter {
-	var dedupeTime time.Duration
-	if config != nil && config.DeduplicationTTL > 0 {
-		dedupeTime = config.DeduplicationTTL
-	} else {
+	if config == nil {
+		config = DefaultRoutingConfig()
+	}
+	
+	dedupeTime := config.DeduplicationTTL
+	if dedupeTime <= 0 {
 		dedupeTime = 10 * time.Minute
 	}
 	
-	var defaultTTL uint8
-	if config != nil && config.MaxTTL > 0 {
-		defaultTTL = config.MaxTTL
-	} else {
+	defaultTTL := config.MaxTTL
+	if defaultTTL == 0 {
 		defaultTTL = 5
 	}
 	
 	return &MessageRouter{
+		config:            config,
+		blockedPeers:      blockedPeersMap(config.BlockedPeers),
 		processedMessages: utils.NewExpiringSet(dedupeTime, 1*time.Minute),
 		routingTable:      make(map[string]string),
 		routingMetrics:    make(map[string]int),
+		routingHops:       make(map[string]int),
+		linkQuality:       make(map[string]int),
+		candidates:        make(map[string]map[string]routeCandidate),
+		nonRelays:         make(map[string]bool),
 		defaultTTL:        defaultTTL,
+		diameter:          NewDiameterEstimator(DefaultDiameterWindow, DefaultDiameterSamples),
+		random:            defaultRandom,
 		dedupeTime:        dedupeTime,
 	}
 }
 
 // ShouldProcess verifica se uma mensagem deve ser processada ou descartada
-// Retorna true se a mensagem deve ser processada, false se é duplicada ou expirada
+// Retorna true se a mensagem deve ser processada, false se é duplicada,
+// expirada ou enviada por um peer bloqueado
 func (mr *MessageRouter) ShouldProcess(packet *protocol.BitchatPacket) bool {
 	// Verificar TTL
 	if packet.TTL == 0 {
 		return false
 	}
 	
+	// Ignorar silenciosamente pacotes de peers bloqueados
+	if mr.IsBlocked(string(packet.SenderID)) {
+		return false
+	}
+	
+	// Descartar tráfego antigo mesmo que ainda tenha TTL
+	if mr.IsExpired(packet) {
+		return false
+	}
+	
 	// Verificar deduplicação
-	messageID := packet.ID
-	return mr.processedMessages.Add(messageID)
+	return mr.processedMessages.Add(PacketKey(packet))
 }
 
-// MarkProcessed marca uma mensagem como processada para evitar duplicação
+// IsProcessed verifica se o pacote já foi processado anteriormente
+func (mr *MessageRouter) IsProcessed(packet *protocol.BitchatPacket) bool {
+	return mr.processedMessages.Contains(PacketKey(packet))
+}
+
+// IsExpired verifica se o timestamp de origem do pacote excede a idade
+// máxima configurada. Pacotes sem timestamp não expiram.
+func (mr *MessageRouter) IsExpired(packet *protocol.BitchatPacket) bool {
+	mr.routingMutex.RLock()
+	maxAge := mr.config.MaxPacketAge
+	mr.routingMutex.RUnlock()
+	
+	if maxAge <= 0 || packet.Timestamp == 0 {
+		return false
+	}
+	
+	age := time.Since(time.UnixMilli(int64(packet.Timestamp)))
+	return age > maxAge
+}
+
+// MarkProcessed marca uma mensagem como processada para evitar duplicação.
+// A chave é derivada dos campos transmitidos, e não do ID local, para que o
+// pacote seja reconhecido quando voltar pela rede.
 func (mr *MessageRouter) MarkProcessed(packet *protocol.BitchatPacket) {
-	messageID := packet.ID
-	mr.processedMessages.Add(messageID)
+	mr.processedMessages.Add(WireKey(packet))
+}
+
+// ShouldRelay decide se um pacote recebido (com TTL já decrementado) deve ser
+// retransmitido por este nó
+func (mr *MessageRouter) ShouldRelay(packet *protocol.BitchatPacket, selfID string) bool {
+	mr.routingMutex.RLock()
+	defer mr.routingMutex.RUnlock()
+	
+	if !mr.config.AllowRelay || packet.TTL == 0 {
+		return false
+	}
+	
+	// Não retransmitir pacotes de ou para peers bloqueados
+	if mr.blockedPeers[string(packet.SenderID)] {
+		return false
+	}
+	
+	if isBroadcast(packet.RecipientID) {
+		return mr.config.AllowBroadcast
+	}
+	
+	recipientID := string(packet.RecipientID)
+	return recipientID != selfID && !mr.blockedPeers[recipientID]
+}
+
+// PacketKey retorna a chave de deduplicação de um pacote.
+// Usa o ID do pacote quando definido; caso contrário, deriva uma chave
+// determinística dos campos que não mudam ao longo da retransmissão.
+func PacketKey(packet *protocol.BitchatPacket) string {
+	if packet.ID != "" {
+		return packet.ID
+	}
+	return WireKey(packet)
+}
+
+// WireKey retorna a chave de deduplicação derivada dos campos transmitidos,
+// ignorando o ID local. É a chave que PacketKey produz para o pacote ao
+// recebê-lo.
+func WireKey(packet *protocol.BitchatPacket) string {
+	h := sha256.New()
+	binary.Write(h, binary.BigEndian, packet.Timestamp)
+	h.Write([]byte{byte(packet.Type)})
+	h.Write(packet.SenderID)
+	h.Write(packet.RecipientID)
+	h.Write(packet.Payload)
+	
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// BlockPeer adiciona um peer à lista de bloqueados
+func (mr *MessageRouter) BlockPeer(peerID string) {
+	mr.routingMutex.Lock()
+	defer mr.routingMutex.Unlock()
+	
+	mr.blockedPeers[peerID] = true
+}
+
+// UnblockPeer remove um peer da lista de bloqueados
+func (mr *MessageRouter) UnblockPeer(peerID string) {
+	mr.routingMutex.Lock()
+	defer mr.routingMutex.Unlock()
+	
+	delete(mr.blockedPeers, peerID)
+}
+
+// IsBlocked verifica se um peer está bloqueado
+func (mr *MessageRouter) IsBlocked(peerID string) bool {
+	mr.routingMutex.RLock()
+	defer mr.routingMutex.RUnlock()
+	
+	return mr.blockedPeers[peerID]
+}
+
+// GetBlockedPeers retorna a lista de peers bloqueados
+func (mr *MessageRouter) GetBlockedPeers() []string {
+	mr.routingMutex.RLock()
+	defer mr.routingMutex.RUnlock()
+	
+	result := make([]string, 0, len(mr.blockedPeers))
+	for peerID := range mr.blockedPeers {
+		result = append(result, peerID)
+	}
+	
+	return result
+}
+
+// UpdateConfig atualiza a configuração do roteador
+func (mr *MessageRouter) UpdateConfig(config *RoutingConfig) {
+	mr.routingMutex.Lock()
+	defer mr.routingMutex.Unlock()
+	
+	mr.config = config
+	mr.blockedPeers = blockedPeersMap(config.BlockedPeers)
+	if config.MaxTTL > 0 {
+		mr.defaultTTL = config.MaxTTL
+	}
+}
+
+// GetConfig retorna a configuração atual do roteador
+func (mr *MessageRouter) GetConfig() *RoutingConfig {
+	mr.routingMutex.RLock()
+	defer mr.routingMutex.RUnlock()
+	
+	return mr.config
+}
+
+// blockedPeersMap converte a lista de peers bloqueados para mapa
+func blockedPeersMap(peers []string) map[string]bool {
+	blocked := make(map[string]bool, len(peers))
+	for _, peerID := range peers {
+		blocked[peerID] = true
+	}
+	return blocked
+}
+
+// isBroadcast verifica se um ID de destinatário representa broadcast
+func isBroadcast(recipientID []byte) bool {
+	if len(recipientID) == 0 {
+		return true
+	}
+	for _, b := range recipientID {
+		if b != 0xFF {
+			return false
+		}
+	}
+	return true
 }
 
 // DecreaseAndCheckTTL diminui o TTL de um pacote e verifica se ainda é válido
@@ -122,6 +320,8 @@ func (mr *MessageRouter) UpdateRoutingInfo(peerID string, nextHop string, metric
 		nextHop = peerID
 	}
 	
+	mr.addCandidateLocked(peerID, nextHop, routeCandidate{metric: metric})
+	
 	// Atualizar tabela de roteamento
 	currentMetric, hasRoute := mr.routingMetrics[peerID]
 	
@@ -132,6 +332,195 @@ func (mr *MessageRouter) UpdateRoutingInfo(peerID string, nextHop string, metric
 	}
 }
 
+// UpdateRoute atualiza a tabela com uma rota de comprimento conhecido.
+// A métrica combina o número de saltos com a qualidade do enlace do próximo hop.
+func (mr *MessageRouter) UpdateRoute(peerID string, nextHop string, hops int) {
+	mr.routingMutex.Lock()
+	defer mr.routingMutex.Unlock()
+	
+	if nextHop == "" {
+		nextHop = peerID
+	}
+	
+	metric := mr.routeMetricLocked(peerID, nextHop, hops)
+	mr.addCandidateLocked(peerID, nextHop, routeCandidate{metric: metric, hops: hops})
+	currentMetric, hasRoute := mr.routingMetrics[peerID]
+	
+	// Mesma rota: atualizar comprimento e métrica mesmo que piores
+	if !hasRoute || metric > currentMetric || mr.routingTable[peerID] == nextHop {
+		mr.routingTable[peerID] = nextHop
+		mr.routingMetrics[peerID] = metric
+		mr.routingHops[peerID] = hops
+	}
+}
+
+// SetLinkQuality define a qualidade do enlace com um vizinho direto e
+// recalcula a métrica de todas as rotas que passam por ele
+func (mr *MessageRouter) SetLinkQuality(neighborID string, quality int) {
+	mr.routingMutex.Lock()
+	defer mr.routingMutex.Unlock()
+	
+	if quality < 0 {
+		quality = 0
+	} else if quality > 100 {
+		quality = 100
+	}
+	mr.linkQuality[neighborID] = quality
+	
+	for dest, hop := range mr.routingTable {
+		if hop != neighborID {
+			continue
+		}
+		if hops, ok := mr.routingHops[dest]; ok {
+			mr.routingMetrics[dest] = mr.routeMetricLocked(dest, neighborID, hops)
+		}
+	}
+	
+	for dest, byHop := range mr.candidates {
+		if candidate, ok := byHop[neighborID]; ok && candidate.hops > 0 {
+			candidate.metric = mr.routeMetricLocked(dest, neighborID, candidate.hops)
+			byHop[neighborID] = candidate
+		}
+	}
+}
+
+// SetRelayCapable informa se um vizinho direto se dispõe a retransmitir.
+// Rotas através de vizinhos que não retransmitem têm a métrica reduzida e
+// só são usadas na falta de alternativa.
+func (mr *MessageRouter) SetRelayCapable(neighborID string, capable bool) {
+	mr.routingMutex.Lock()
+	defer mr.routingMutex.Unlock()
+	
+	if capable == !mr.nonRelays[neighborID] {
+		return
+	}
+	if capable {
+		delete(mr.nonRelays, neighborID)
+	} else {
+		mr.nonRelays[neighborID] = true
+	}
+	
+	// Recalcular as rotas através do vizinho e reeleger a melhor de cada destino
+	for dest, byHop := range mr.candidates {
+		candidate, ok := byHop[neighborID]
+		if !ok || candidate.hops <= 0 || dest == neighborID {
+			continue
+		}
+		candidate.metric = mr.routeMetricLocked(dest, neighborID, candidate.hops)
+		byHop[neighborID] = candidate
+		mr.promoteCandidateLocked(dest)
+	}
+}
+
+// IsRelayCapable verifica se um vizinho se dispõe a retransmitir
+func (mr *MessageRouter) IsRelayCapable(neighborID string) bool {
+	mr.routingMutex.RLock()
+	defer mr.routingMutex.RUnlock()
+	
+	return !mr.nonRelays[neighborID]
+}
+
+// routeMetricLocked calcula a métrica de uma rota até dest através de nextHop
+// Deve ser chamada com routingMutex adquirido
+func (mr *MessageRouter) routeMetricLocked(dest string, nextHop string, hops int) int {
+	metric := RouteMetric(hops, mr.linkQualityLocked(nextHop))
+	if nextHop != dest && mr.nonRelays[nextHop] {
+		metric /= nonRelayPenalty
+		if metric < 1 {
+			metric = 1
+		}
+	}
+	return metric
+}
+
+// GetNextHops retorna até max próximos hops distintos para um destinatário,
+// ordenados da melhor para a pior métrica. Usado para envio redundante por
+// caminhos que divergem já no primeiro salto.
+func (mr *MessageRouter) GetNextHops(recipientID string, max int) []string {
+	mr.routingMutex.RLock()
+	defer mr.routingMutex.RUnlock()
+	
+	type option struct {
+		nextHop string
+		metric  int
+	}
+	
+	byHop := mr.candidates[recipientID]
+	options := make([]option, 0, len(byHop)+1)
+	for nextHop, candidate := range byHop {
+		options = append(options, option{nextHop, candidate.metric})
+	}
+	if primary, ok := mr.routingTable[recipientID]; ok {
+		if _, listed := byHop[primary]; !listed {
+			options = append(options, option{primary, mr.routingMetrics[recipientID]})
+		}
+	}
+	
+	sort.Slice(options, func(i, j int) bool {
+		if options[i].metric != options[j].metric {
+			return options[i].metric > options[j].metric
+		}
+		return options[i].nextHop < options[j].nextHop
+	})
+	
+	if max > 0 && len(options) > max {
+		options = options[:max]
+	}
+	
+	hops := make([]string, len(options))
+	for i, o := range options {
+		hops[i] = o.nextHop
+	}
+	return hops
+}
+
+// addCandidateLocked registra uma rota alternativa para um destinatário
+// Deve ser chamada com routingMutex adquirido
+func (mr *MessageRouter) addCandidateLocked(peerID, nextHop string, candidate routeCandidate) {
+	byHop, ok := mr.candidates[peerID]
+	if !ok {
+		byHop = make(map[string]routeCandidate)
+		mr.candidates[peerID] = byHop
+	}
+	byHop[nextHop] = candidate
+}
+
+// GetLinkQuality retorna a qualidade do enlace com um vizinho direto
+func (mr *MessageRouter) GetLinkQuality(neighborID string) (int, bool) {
+	mr.routingMutex.RLock()
+	defer mr.routingMutex.RUnlock()
+	
+	quality, ok := mr.linkQuality[neighborID]
+	return quality, ok
+}
+
+// GetRouteHops retorna o número de saltos de uma rota conhecida
+func (mr *MessageRouter) GetRouteHops(peerID string) (int, bool) {
+	mr.routingMutex.RLock()
+	defer mr.routingMutex.RUnlock()
+	
+	hops, ok := mr.routingHops[peerID]
+	return hops, ok
+}
+
+// GetRouteMetric retorna a métrica de uma rota conhecida
+func (mr *MessageRouter) GetRouteMetric(peerID string) (int, bool) {
+	mr.routingMutex.RLock()
+	defer mr.routingMutex.RUnlock()
+	
+	metric, ok := mr.routingMetrics[peerID]
+	return metric, ok
+}
+
+// linkQualityLocked retorna a qualidade do enlace (100 se desconhecida)
+// Deve ser chamada com routingMutex adquirido
+func (mr *MessageRouter) linkQualityLocked(neighborID string) int {
+	if quality, ok := mr.linkQuality[neighborID]; ok {
+		return quality
+	}
+	return 100
+}
+
 // GetNextHop determina o próximo hop para um destinatário
 // Retorna o ID do próximo peer e um booleano indicando se o destinatário é alcançável
 func (mr *MessageRouter) GetNextHop(recipientID string) (string, bool) {
@@ -150,14 +539,70 @@ func (mr *MessageRouter) RemovePeer(peerID string) {
 	// Remover peer da tabela de roteamento
 	delete(mr.routingTable, peerID)
 	delete(mr.routingMetrics, peerID)
+	delete(mr.routingHops, peerID)
+	delete(mr.linkQuality, peerID)
+	delete(mr.candidates, peerID)
+	delete(mr.nonRelays, peerID)
+	
+	// Remover rotas alternativas que passam por este peer
+	for dest, byHop := range mr.candidates {
+		delete(byHop, peerID)
+		if len(byHop) == 0 {
+			delete(mr.candidates, dest)
+		}
+	}
 	
-	// Remover rotas que passam por este peer
+	// Remover rotas que passam por este peer, promovendo a melhor alternativa
 	for dest, hop := range mr.routingTable {
 		if hop == peerID {
 			delete(mr.routingTable, dest)
 			delete(mr.routingMetrics, dest)
+			delete(mr.routingHops, dest)
+			mr.promoteCandidateLocked(dest)
+		}
+	}
+}
+
+// RemoveRoute remove a rota para um destinatário através de um próximo hop
+// específico, promovendo a melhor alternativa se ela era a rota principal
+func (mr *MessageRouter) RemoveRoute(peerID string, nextHop string) {
+	mr.routingMutex.Lock()
+	defer mr.routingMutex.Unlock()
+	
+	if byHop, ok := mr.candidates[peerID]; ok {
+		delete(byHop, nextHop)
+		if len(byHop) == 0 {
+			delete(mr.candidates, peerID)
+		}
+	}
+	
+	if mr.routingTable[peerID] == nextHop {
+		delete(mr.routingTable, peerID)
+		delete(mr.routingMetrics, peerID)
+		delete(mr.routingHops, peerID)
+		mr.promoteCandidateLocked(peerID)
+	}
+}
+
+// promoteCandidateLocked instala a melhor rota alternativa como rota principal
+// Deve ser chamada com routingMutex adquirido
+func (mr *MessageRouter) promoteCandidateLocked(dest string) {
+	bestHop := ""
+	var best routeCandidate
+	for nextHop, candidate := range mr.candidates[dest] {
+		if bestHop == "" || candidate.metric > best.metric {
+			bestHop, best = nextHop, candidate
 		}
 	}
+	if bestHop == "" {
+		return
+	}
+	
+	mr.routingTable[dest] = bestHop
+	mr.routingMetrics[dest] = best.metric
+	if best.hops > 0 {
+		mr.routingHops[dest] = best.hops
+	}
 }
 
 // GetAllPeers retorna todos os peers conhecidos (direta ou indiretamente)
@@ -193,8 +638,74 @@ func (mr *MessageRouter) GetDirectPeers() []string {
 func (mr *MessageRouter) PrepareOutgoingPacket(packet *protocol.BitchatPacket) {
 	// Definir TTL se não estiver definido
 	if packet.TTL == 0 {
-		packet.TTL = mr.defaultTTL
+		if ttl, ok := mr.GetAdaptiveTTL(); ok {
+			packet.TTL = ttl
+		} else {
+			packet.TTL = mr.defaultTTL
+		}
+	}
+}
+
+// RecordHopCount registra a distância em saltos até a origem de um pacote
+// recebido, alimentando a estimativa do diâmetro da rede
+func (mr *MessageRouter) RecordHopCount(hops int) {
+	mr.diameter.Record(hops)
+}
+
+// GetAdaptiveTTL retorna o TTL adequado ao diâmetro estimado da rede, limitado
+// pela configuração. Retorna false se o ajuste estiver desativado ou ainda não
+// houver amostras suficientes.
+func (mr *MessageRouter) GetAdaptiveTTL() (uint8, bool) {
+	mr.routingMutex.RLock()
+	config := mr.config
+	mr.routingMutex.RUnlock()
+	
+	if !config.AdaptiveTTL {
+		return 0, false
+	}
+	
+	diameter, samples := mr.diameter.Estimate()
+	if samples < MinDiameterSamples {
+		return 0, false
+	}
+	
+	// Um salto de margem para peers um pouco além do mais distante observado
+	ttl := diameter + 1
+	if config.MinAdaptiveTTL > 0 && ttl < int(config.MinAdaptiveTTL) {
+		ttl = int(config.MinAdaptiveTTL)
+	}
+	if config.MaxAdaptiveTTL > 0 && ttl > int(config.MaxAdaptiveTTL) {
+		ttl = int(config.MaxAdaptiveTTL)
+	}
+	if ttl > 255 {
+		ttl = 255
+	}
+	
+	return uint8(ttl), true
+}
+
+// GetRouteTTL retorna o TTL de um pacote endereçado a um destinatário com rota
+// conhecida: o comprimento da rota mais a folga configurada. Retorna false se
+// o comprimento da rota é desconhecido.
+func (mr *MessageRouter) GetRouteTTL(recipientID string) (uint8, bool) {
+	mr.routingMutex.RLock()
+	defer mr.routingMutex.RUnlock()
+	
+	hops, ok := mr.routingHops[recipientID]
+	if !ok || hops <= 0 {
+		return 0, false
 	}
+	
+	ttl := hops + int(mr.config.RouteTTLSlack)
+	if ttl > 255 {
+		ttl = 255
+	}
+	return uint8(ttl), true
+}
+
+// GetNetworkDiameter retorna o diâmetro estimado da rede e o número de amostras
+func (mr *MessageRouter) GetNetworkDiameter() (int, int) {
+	return mr.diameter.Estimate()
 }
 
 // Clear limpa todas as informações de roteamento
@@ -204,7 +715,12 @@ func (mr *MessageRouter) Clear() {
 	
 	mr.routingTable = make(map[string]string)
 	mr.routingMetrics = make(map[string]int)
+	mr.routingHops = make(map[string]int)
+	mr.linkQuality = make(map[string]int)
+	mr.candidates = make(map[string]map[string]routeCandidate)
+	mr.nonRelays = make(map[string]bool)
 	mr.processedMessages.Clear()
+	mr.diameter.Reset()
 }
 
 // Stop interrompe o roteador e libera recursos
@@ -4,8 +4,38 @@ import "time"
 
 // RoutingConfig contém configurações para o serviço de roteamento
 type RoutingConfig struct {
-	MaxTTL            uint8         // Valor máximo de TTL para pacotes
-	DeduplicationTTL  time.Duration // Tempo de vida para deduplicação de mensagens
-	PeerTTL           time.Duration // Tempo de vida para peers na tabela de roteamento
-	MaxPeers          int           // Número máximo de peers na tabela de roteamento
+	MaxTTL               uint8         // Valor máximo de TTL para pacotes
+	DeduplicationTTL     time.Duration // Tempo de vida para deduplicação de mensagens
+	PeerTTL              time.Duration // Tempo de vida para peers na tabela de roteamento
+	MaxPeers             int           // Número máximo de peers na tabela de roteamento
+	AllowRelay           bool          // Se verdadeiro, permite relay de mensagens para outros peers
+	AllowBroadcast       bool          // Se verdadeiro, permite relay de mensagens de broadcast
+	BlockedPeers         []string      // Lista de IDs de peers bloqueados
+	AdaptiveTTL          bool          // Se verdadeiro, ajusta o TTL ao diâmetro estimado da rede
+	MinAdaptiveTTL       uint8         // Menor TTL escolhido pelo ajuste adaptativo
+	MaxAdaptiveTTL       uint8         // Maior TTL escolhido pelo ajuste adaptativo
+	GossipThreshold      int           // Vizinhos acima dos quais broadcasts são retransmitidos por probabilidade (0 = desativado)
+	GossipMinProbability float64       // Menor probabilidade de retransmissão em áreas densas
+	MaxPacketAge         time.Duration // Idade máxima de um pacote pelo timestamp de origem (0 = sem limite)
+	RouteTTLSlack        uint8         // Saltos além do comprimento da rota conhecida no TTL de pacotes endereçados
+}
+
+// DefaultRoutingConfig retorna uma configuração padrão para o roteador
+func DefaultRoutingConfig() *RoutingConfig {
+	return &RoutingConfig{
+		MaxTTL:               5,
+		DeduplicationTTL:     10 * time.Minute,
+		PeerTTL:              30 * time.Minute,
+		MaxPeers:             100,
+		AllowRelay:           true,
+		AllowBroadcast:       true,
+		BlockedPeers:         []string{},
+		AdaptiveTTL:          true,
+		MinAdaptiveTTL:       2,
+		MaxAdaptiveTTL:       10,
+		GossipThreshold:      4,
+		GossipMinProbability: 0.25,
+		MaxPacketAge:         10 * time.Minute,
+		RouteTTLSlack:        2,
+	}
 }
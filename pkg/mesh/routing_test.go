@@ -279,4 +279,211 @@ func TestMessageRouter(t *testing.T) {
 			t.Error("Cache de mensagens processadas deveria ter sido limpo após Clear")
 		}
 	})
+	
+	t.Run("Deduplicação sem ID explícito", func(t *testing.T) {
+		router := NewMessageRouter()
+		
+		packet := &protocol.BitchatPacket{
+			Type:      protocol.MessageTypeMessage,
+			SenderID:  []byte("peer1"),
+			Payload:   []byte("olá"),
+			TTL:       5,
+			Timestamp: uint64(time.Now().UnixMilli()),
+		}
+		
+		if !router.ShouldProcess(packet) {
+			t.Fatal("Primeira recepção deveria ser processada")
+		}
+		
+		// A mesma mensagem retransmitida por outro vizinho chega com TTL menor
+		relayed := *packet
+		relayed.TTL = 3
+		if router.ShouldProcess(&relayed) {
+			t.Error("Retransmissão da mesma mensagem deveria ser descartada")
+		}
+	})
+	
+	t.Run("Pacote próprio com ID local volta pela rede", func(t *testing.T) {
+		router := NewMessageRouter()
+		
+		// Reenvios mantêm o ID original da mensagem, que não é transmitido
+		sent := &protocol.BitchatPacket{
+			ID:        "mensagem-original",
+			Type:      protocol.MessageTypeMessage,
+			SenderID:  []byte("self"),
+			Payload:   []byte("reenvio"),
+			TTL:       5,
+			Timestamp: uint64(time.Now().UnixMilli()),
+		}
+		router.MarkProcessed(sent)
+		
+		received := *sent
+		received.ID = ""
+		received.TTL = 4
+		if router.ShouldProcess(&received) {
+			t.Error("Pacote próprio retornando pela rede deveria ser descartado")
+		}
+	})
+	
+	t.Run("Bloqueio e decisão de relay", func(t *testing.T) {
+		router := NewMessageRouter()
+		
+		broadcast := &protocol.BitchatPacket{
+			SenderID:    []byte("peer1"),
+			RecipientID: protocol.BroadcastRecipient,
+			TTL:         2,
+			Timestamp:   uint64(time.Now().UnixMilli()),
+		}
+		if !router.ShouldRelay(broadcast, "self") {
+			t.Error("Broadcast com TTL restante deveria ser retransmitido")
+		}
+		
+		direct := &protocol.BitchatPacket{
+			SenderID:    []byte("peer1"),
+			RecipientID: []byte("self"),
+			TTL:         2,
+		}
+		if router.ShouldRelay(direct, "self") {
+			t.Error("Pacote destinado a este nó não deveria ser retransmitido")
+		}
+		
+		router.BlockPeer("peer1")
+		if !router.IsBlocked("peer1") {
+			t.Fatal("peer1 deveria estar bloqueado")
+		}
+		if router.ShouldRelay(broadcast, "self") {
+			t.Error("Pacotes de peers bloqueados não deveriam ser retransmitidos")
+		}
+		if router.ShouldProcess(broadcast) {
+			t.Error("Pacotes de peers bloqueados não deveriam ser processados")
+		}
+		
+		router.UnblockPeer("peer1")
+		router.UpdateConfig(&RoutingConfig{AllowRelay: false})
+		if router.ShouldRelay(broadcast, "self") {
+			t.Error("Relay desabilitado não deveria retransmitir")
+		}
+	})
+}
+
+func TestMultipathNextHops(t *testing.T) {
+	router := NewMessageRouter()
+	router.UpdateRoute("D", "B", 2)
+	router.UpdateRoute("D", "C", 3)
+	router.UpdateRoute("D", "E", 4)
+
+	hops := router.GetNextHops("D", 2)
+	if len(hops) != 2 || hops[0] != "B" || hops[1] != "C" {
+		t.Fatalf("Próximos hops esperados [B C], obtido %v", hops)
+	}
+
+	// Perder o melhor vizinho promove a melhor alternativa
+	router.RemovePeer("B")
+	if nextHop, ok := router.GetNextHop("D"); !ok || nextHop != "C" {
+		t.Errorf("Rota alternativa via C esperada, obtido %s (%v)", nextHop, ok)
+	}
+	if hops := router.GetNextHops("D", 0); len(hops) != 2 {
+		t.Errorf("Esperados 2 caminhos restantes, obtido %v", hops)
+	}
+}
+
+func TestRouteTTL(t *testing.T) {
+	router := NewRouter(nil)
+
+	if _, ok := router.GetRouteTTL("peer3"); ok {
+		t.Error("TTL por rota não deveria existir sem rota conhecida")
+	}
+
+	router.UpdateRoute("peer3", "peer2", 3)
+	if ttl, ok := router.GetRouteTTL("peer3"); !ok || ttl != 5 {
+		t.Errorf("TTL esperado: 5 (3 saltos + folga 2), obtido: %d (%v)", ttl, ok)
+	}
+
+	// Rota direta mais curta substitui a anterior
+	router.UpdateRoute("peer3", "", 1)
+	if ttl, _ := router.GetRouteTTL("peer3"); ttl != 3 {
+		t.Errorf("TTL esperado após rota direta: 3, obtido: %d", ttl)
+	}
+}
+
+func TestAdaptiveTTL(t *testing.T) {
+	config := DefaultRoutingConfig()
+	config.MinAdaptiveTTL = 3
+	config.MaxAdaptiveTTL = 6
+	router := NewRouter(config)
+
+	t.Run("Sem amostras suficientes usa o TTL padrão", func(t *testing.T) {
+		router.RecordHopCount(1)
+		if _, ok := router.GetAdaptiveTTL(); ok {
+			t.Error("TTL adaptativo não deveria estar disponível com uma amostra")
+		}
+
+		packet := &protocol.BitchatPacket{ID: "adaptive-1"}
+		router.PrepareOutgoingPacket(packet)
+		if packet.TTL != router.GetDefaultTTL() {
+			t.Errorf("TTL esperado: %d, obtido: %d", router.GetDefaultTTL(), packet.TTL)
+		}
+	})
+
+	t.Run("Rede pequena respeita o mínimo", func(t *testing.T) {
+		for i := 0; i < MinDiameterSamples; i++ {
+			router.RecordHopCount(1)
+		}
+		if ttl, ok := router.GetAdaptiveTTL(); !ok || ttl != 3 {
+			t.Errorf("TTL adaptativo esperado: 3, obtido: %d (%v)", ttl, ok)
+		}
+	})
+
+	t.Run("Rede grande respeita o máximo", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			router.RecordHopCount(4 + i%6)
+		}
+		if diameter, _ := router.GetNetworkDiameter(); diameter < 8 {
+			t.Errorf("Diâmetro estimado deveria refletir os caminhos longos, obtido %d", diameter)
+		}
+
+		packet := &protocol.BitchatPacket{ID: "adaptive-2"}
+		router.PrepareOutgoingPacket(packet)
+		if packet.TTL != 6 {
+			t.Errorf("TTL esperado limitado a 6, obtido: %d", packet.TTL)
+		}
+	})
+
+	t.Run("Ajuste desativado", func(t *testing.T) {
+		disabled := DefaultRoutingConfig()
+		disabled.AdaptiveTTL = false
+		router.UpdateConfig(disabled)
+		if _, ok := router.GetAdaptiveTTL(); ok {
+			t.Error("TTL adaptativo deveria estar desativado")
+		}
+	})
+}
+
+func TestPacketExpiry(t *testing.T) {
+	router := NewMessageRouter()
+
+	stale := &protocol.BitchatPacket{
+		ID:        "stale",
+		TTL:       5,
+		Timestamp: uint64(time.Now().Add(-time.Hour).UnixMilli()),
+	}
+	if !router.IsExpired(stale) || router.ShouldProcess(stale) {
+		t.Error("Pacote de uma hora atrás deveria expirar mesmo com TTL restante")
+	}
+
+	fresh := &protocol.BitchatPacket{
+		ID:        "fresh",
+		TTL:       5,
+		Timestamp: uint64(time.Now().Add(-time.Minute).UnixMilli()),
+	}
+	if router.IsExpired(fresh) || !router.ShouldProcess(fresh) {
+		t.Error("Pacote recente deveria ser processado")
+	}
+
+	config := DefaultRoutingConfig()
+	config.MaxPacketAge = 0
+	router.UpdateConfig(config)
+	if router.IsExpired(stale) {
+		t.Error("Sem idade máxima configurada nenhum pacote deveria expirar")
+	}
 }
@@ -0,0 +1,249 @@
+package mesh
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PeerTraffic contabiliza o tráfego trocado com um peer
+type PeerTraffic struct {
+	PacketsSent     uint64
+	PacketsReceived uint64
+	BytesSent       uint64
+	BytesReceived   uint64
+}
+
+// StatsSnapshot é uma fotografia das estatísticas da rede mesh
+type StatsSnapshot struct {
+	PacketsSent      uint64
+	PacketsReceived  uint64
+	PacketsRelayed   uint64
+	PacketsDropped   uint64
+	DedupHits        uint64
+	FragmentFailures uint64
+	BytesSent        uint64
+	BytesReceived    uint64
+	BytesRelayed     uint64
+	Peers            map[string]PeerTraffic
+	Uptime           time.Duration
+}
+
+// MeshStats contabiliza o tráfego e os eventos da rede mesh
+type MeshStats struct {
+	snapshot  StatsSnapshot
+	peers     map[string]*PeerTraffic
+	startedAt time.Time
+	mutex     sync.Mutex
+}
+
+// NewMeshStats cria um novo contador de estatísticas
+func NewMeshStats() *MeshStats {
+	return &MeshStats{
+		peers:     make(map[string]*PeerTraffic),
+		startedAt: time.Now(),
+	}
+}
+
+// RecordSent registra um pacote enviado. peerID vazio indica broadcast.
+func (ms *MeshStats) RecordSent(peerID string, bytes int) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.snapshot.PacketsSent++
+	ms.snapshot.BytesSent += uint64(bytes)
+	if peerID != "" {
+		peer := ms.peerLocked(peerID)
+		peer.PacketsSent++
+		peer.BytesSent += uint64(bytes)
+	}
+}
+
+// RecordReceived registra um pacote recebido originado por peerID
+func (ms *MeshStats) RecordReceived(peerID string, bytes int) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.snapshot.PacketsReceived++
+	ms.snapshot.BytesReceived += uint64(bytes)
+	if peerID != "" {
+		peer := ms.peerLocked(peerID)
+		peer.PacketsReceived++
+		peer.BytesReceived += uint64(bytes)
+	}
+}
+
+// RecordRelayed registra um pacote alheio retransmitido
+func (ms *MeshStats) RecordRelayed(bytes int) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.snapshot.PacketsRelayed++
+	ms.snapshot.BytesRelayed += uint64(bytes)
+}
+
+// RecordDropped registra um pacote descartado por excesso de carga
+func (ms *MeshStats) RecordDropped() {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.snapshot.PacketsDropped++
+}
+
+// RecordDedupHit registra um pacote descartado por já ter sido processado
+func (ms *MeshStats) RecordDedupHit() {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.snapshot.DedupHits++
+}
+
+// RecordFragmentFailure registra uma remontagem de fragmentos que falhou
+func (ms *MeshStats) RecordFragmentFailure() {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.snapshot.FragmentFailures++
+}
+
+// RemovePeer descarta os contadores de um peer
+func (ms *MeshStats) RemovePeer(peerID string) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	delete(ms.peers, peerID)
+}
+
+// Snapshot retorna uma cópia das estatísticas atuais
+func (ms *MeshStats) Snapshot() StatsSnapshot {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	snapshot := ms.snapshot
+	snapshot.Uptime = time.Since(ms.startedAt)
+	snapshot.Peers = make(map[string]PeerTraffic, len(ms.peers))
+	for peerID, peer := range ms.peers {
+		snapshot.Peers[peerID] = *peer
+	}
+	return snapshot
+}
+
+// Reset zera todos os contadores
+func (ms *MeshStats) Reset() {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.snapshot = StatsSnapshot{}
+	ms.peers = make(map[string]*PeerTraffic)
+	ms.startedAt = time.Now()
+}
+
+// peerLocked retorna os contadores de um peer, criando-os se necessário.
+// Deve ser chamada com o mutex adquirido.
+func (ms *MeshStats) peerLocked(peerID string) *PeerTraffic {
+	peer, ok := ms.peers[peerID]
+	if !ok {
+		peer = &PeerTraffic{}
+		ms.peers[peerID] = peer
+	}
+	return peer
+}
+
+// WritePrometheus escreve as estatísticas no formato de exposição de texto
+// do Prometheus
+func (s StatsSnapshot) WritePrometheus(w io.Writer) error {
+	counters := []struct {
+		name  string
+		help  string
+		value uint64
+	}{
+		{"bitchat_packets_sent_total", "Pacotes enviados", s.PacketsSent},
+		{"bitchat_packets_received_total", "Pacotes recebidos", s.PacketsReceived},
+		{"bitchat_packets_relayed_total", "Pacotes alheios retransmitidos", s.PacketsRelayed},
+		{"bitchat_packets_dropped_total", "Pacotes descartados por excesso de carga", s.PacketsDropped},
+		{"bitchat_dedup_hits_total", "Pacotes duplicados descartados", s.DedupHits},
+		{"bitchat_fragment_failures_total", "Remontagens de fragmentos que falharam", s.FragmentFailures},
+		{"bitchat_bytes_sent_total", "Bytes enviados", s.BytesSent},
+		{"bitchat_bytes_received_total", "Bytes recebidos", s.BytesReceived},
+		{"bitchat_bytes_relayed_total", "Bytes retransmitidos", s.BytesRelayed},
+	}
+
+	for _, c := range counters {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", c.name, c.help, c.name, c.name, c.value); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "# HELP bitchat_uptime_seconds Tempo desde o início do serviço\n# TYPE bitchat_uptime_seconds gauge\nbitchat_uptime_seconds %.0f\n", s.Uptime.Seconds()); err != nil {
+		return err
+	}
+
+	peerIDs := make([]string, 0, len(s.Peers))
+	for peerID := range s.Peers {
+		peerIDs = append(peerIDs, peerID)
+	}
+	sort.Strings(peerIDs)
+
+	peerCounters := []struct {
+		name  string
+		help  string
+		value func(PeerTraffic) uint64
+	}{
+		{"bitchat_peer_bytes_sent_total", "Bytes enviados por peer", func(p PeerTraffic) uint64 { return p.BytesSent }},
+		{"bitchat_peer_bytes_received_total", "Bytes recebidos por peer", func(p PeerTraffic) uint64 { return p.BytesReceived }},
+	}
+	for _, c := range peerCounters {
+		if len(peerIDs) == 0 {
+			break
+		}
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+			return err
+		}
+		for _, peerID := range peerIDs {
+			if _, err := fmt.Fprintf(w, "%s{peer=\"%x\"} %d\n", c.name, peerID, c.value(s.Peers[peerID])); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteQueuesPrometheus escreve a ocupação e os descartes das filas, por
+// nome, no formato de exposição de texto do Prometheus
+func WriteQueuesPrometheus(w io.Writer, queues map[string]QueueStats) error {
+	names := make([]string, 0, len(queues))
+	for name := range queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	metrics := []struct {
+		name  string
+		help  string
+		kind  string
+		value func(QueueStats) uint64
+	}{
+		{"bitchat_queue_length", "Pacotes aguardando na fila", "gauge", func(q QueueStats) uint64 { return uint64(q.Length) }},
+		{"bitchat_queue_high_water", "Maior ocupação já atingida pela fila", "gauge", func(q QueueStats) uint64 { return uint64(q.HighWater) }},
+		{"bitchat_queue_capacity", "Capacidade da fila (0 = ilimitada)", "gauge", func(q QueueStats) uint64 { return uint64(q.Capacity) }},
+		{"bitchat_queue_dropped_total", "Pacotes descartados com a fila cheia", "counter", func(q QueueStats) uint64 { return q.Dropped }},
+	}
+	for _, m := range metrics {
+		if len(names) == 0 {
+			break
+		}
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind); err != nil {
+			return err
+		}
+		for _, name := range names {
+			if _, err := fmt.Fprintf(w, "%s{queue=%q} %d\n", m.name, name, m.value(queues[name])); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
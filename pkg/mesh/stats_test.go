@@ -0,0 +1,73 @@
+package mesh
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMeshStats(t *testing.T) {
+	stats := NewMeshStats()
+
+	stats.RecordSent("", 100)
+	stats.RecordSent("peer1", 50)
+	stats.RecordReceived("peer1", 80)
+	stats.RecordRelayed(80)
+	stats.RecordDropped()
+	stats.RecordDedupHit()
+	stats.RecordDedupHit()
+	stats.RecordFragmentFailure()
+
+	snapshot := stats.Snapshot()
+	if snapshot.PacketsSent != 2 || snapshot.BytesSent != 150 {
+		t.Errorf("Envios incorretos: %d pacotes, %d bytes", snapshot.PacketsSent, snapshot.BytesSent)
+	}
+	if snapshot.PacketsRelayed != 1 || snapshot.PacketsDropped != 1 || snapshot.DedupHits != 2 || snapshot.FragmentFailures != 1 {
+		t.Errorf("Contadores incorretos: %+v", snapshot)
+	}
+	if peer := snapshot.Peers["peer1"]; peer.BytesSent != 50 || peer.BytesReceived != 80 {
+		t.Errorf("Tráfego por peer incorreto: %+v", peer)
+	}
+
+	var b strings.Builder
+	if err := snapshot.WritePrometheus(&b); err != nil {
+		t.Fatalf("Erro ao exportar métricas: %v", err)
+	}
+	metrics := b.String()
+	for _, line := range []string{
+		"bitchat_packets_sent_total 2",
+		"bitchat_dedup_hits_total 2",
+		`bitchat_peer_bytes_received_total{peer="7065657231"} 80`,
+	} {
+		if !strings.Contains(metrics, line) {
+			t.Errorf("Métrica ausente: %s\n%s", line, metrics)
+		}
+	}
+
+	stats.Reset()
+	if snapshot := stats.Snapshot(); snapshot.PacketsSent != 0 || len(snapshot.Peers) != 0 {
+		t.Error("Reset deveria zerar os contadores")
+	}
+}
+
+func TestWriteQueuesPrometheus(t *testing.T) {
+	var b strings.Builder
+	err := WriteQueuesPrometheus(&b, map[string]QueueStats{
+		"outgoing": {Length: 3, HighWater: 40, Capacity: 100, Dropped: 2},
+		"incoming": {Length: 1, HighWater: 5, Capacity: 100},
+	})
+	if err != nil {
+		t.Fatalf("Erro ao exportar métricas: %v", err)
+	}
+
+	metrics := b.String()
+	for _, line := range []string{
+		"# TYPE bitchat_queue_high_water gauge",
+		`bitchat_queue_high_water{queue="outgoing"} 40`,
+		`bitchat_queue_length{queue="incoming"} 1`,
+		`bitchat_queue_dropped_total{queue="outgoing"} 2`,
+	} {
+		if !strings.Contains(metrics, line) {
+			t.Errorf("Métrica ausente: %s\n%s", line, metrics)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package mesh
+
+import (
+	"sync"
+	"time"
+)
+
+// Parâmetros padrão da supressão de tempestades de broadcast
+const (
+	DefaultSuppressionThreshold = 3                      // Retransmissões ouvidas que cancelam o relay
+	DefaultSuppressionMinDelay  = 20 * time.Millisecond  // Menor espera antes de retransmitir
+	DefaultSuppressionMaxDelay  = 150 * time.Millisecond // Maior espera antes de retransmitir
+)
+
+// pendingRelay é um relay agendado aguardando o fim da janela de supressão
+type pendingRelay struct {
+	timer *time.Timer
+	heard int
+}
+
+// StormSuppressor adia a retransmissão de broadcasts por um atraso aleatório
+// e a cancela se, nesse intervalo, o mesmo pacote for ouvido sendo
+// retransmitido por vizinhos suficientes (supressão por contador)
+type StormSuppressor struct {
+	threshold int
+	minDelay  time.Duration
+	maxDelay  time.Duration
+
+	pending map[string]*pendingRelay
+
+	// Contadores
+	scheduled  uint64
+	suppressed uint64
+
+	random func() float64
+	mutex  sync.Mutex
+}
+
+// NewStormSuppressor cria um novo supressor de tempestades de broadcast.
+// Valores não positivos usam os padrões.
+func NewStormSuppressor(threshold int, minDelay, maxDelay time.Duration) *StormSuppressor {
+	if threshold <= 0 {
+		threshold = DefaultSuppressionThreshold
+	}
+	if minDelay <= 0 {
+		minDelay = DefaultSuppressionMinDelay
+	}
+	if maxDelay < minDelay {
+		maxDelay = minDelay
+	}
+
+	return &StormSuppressor{
+		threshold: threshold,
+		minDelay:  minDelay,
+		maxDelay:  maxDelay,
+		pending:   make(map[string]*pendingRelay),
+		random:    defaultRandom,
+	}
+}
+
+// Schedule agenda a retransmissão de um pacote identificado por key.
+// relay é chamada ao fim do atraso se o relay não tiver sido suprimido.
+// Retorna false se já existe um relay pendente para o pacote.
+func (ss *StormSuppressor) Schedule(key string, relay func()) bool {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	if _, exists := ss.pending[key]; exists {
+		return false
+	}
+
+	delay := ss.minDelay + time.Duration(ss.random()*float64(ss.maxDelay-ss.minDelay))
+	entry := &pendingRelay{}
+	entry.timer = time.AfterFunc(delay, func() {
+		ss.mutex.Lock()
+		current, ok := ss.pending[key]
+		if ok && current == entry {
+			delete(ss.pending, key)
+		}
+		ss.mutex.Unlock()
+
+		if ok && current == entry {
+			relay()
+		}
+	})
+	ss.pending[key] = entry
+	ss.scheduled++
+
+	return true
+}
+
+// Overheard registra que um vizinho retransmitiu o pacote identificado por
+// key. Retorna true se isso cancelou o relay pendente.
+func (ss *StormSuppressor) Overheard(key string) bool {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	entry, ok := ss.pending[key]
+	if !ok {
+		return false
+	}
+
+	entry.heard++
+	if entry.heard < ss.threshold {
+		return false
+	}
+
+	entry.timer.Stop()
+	delete(ss.pending, key)
+	ss.suppressed++
+	return true
+}
+
+// Pending retorna o número de relays aguardando a janela de supressão
+func (ss *StormSuppressor) Pending() int {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	return len(ss.pending)
+}
+
+// Stats retorna os relays agendados e os suprimidos
+func (ss *StormSuppressor) Stats() (scheduled uint64, suppressed uint64) {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	return ss.scheduled, ss.suppressed
+}
+
+// Stop cancela todos os relays pendentes
+func (ss *StormSuppressor) Stop() {
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
+	for key, entry := range ss.pending {
+		entry.timer.Stop()
+		delete(ss.pending, key)
+	}
+}
@@ -0,0 +1,59 @@
+package mesh
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStormSuppressor(t *testing.T) {
+	t.Run("Relay após o atraso", func(t *testing.T) {
+		ss := NewStormSuppressor(2, time.Millisecond, 2*time.Millisecond)
+
+		done := make(chan struct{})
+		if !ss.Schedule("msg", func() { close(done) }) {
+			t.Fatal("Primeiro agendamento deveria ser aceito")
+		}
+		if ss.Schedule("msg", func() {}) {
+			t.Error("Agendamento duplicado deveria ser recusado")
+		}
+
+		// Uma única retransmissão ouvida não atinge o limite
+		if ss.Overheard("msg") {
+			t.Error("Relay não deveria ser suprimido abaixo do limite")
+		}
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Relay deveria ter sido executado")
+		}
+		if ss.Pending() != 0 {
+			t.Errorf("Nenhum relay deveria estar pendente, obtido %d", ss.Pending())
+		}
+	})
+
+	t.Run("Supressão por vizinhos", func(t *testing.T) {
+		ss := NewStormSuppressor(2, 50*time.Millisecond, 50*time.Millisecond)
+
+		relayed := make(chan struct{}, 1)
+		ss.Schedule("msg", func() { relayed <- struct{}{} })
+		ss.Overheard("msg")
+		if !ss.Overheard("msg") {
+			t.Fatal("Segunda retransmissão ouvida deveria suprimir o relay")
+		}
+
+		select {
+		case <-relayed:
+			t.Error("Relay suprimido não deveria ser executado")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		scheduled, suppressed := ss.Stats()
+		if scheduled != 1 || suppressed != 1 {
+			t.Errorf("Estatísticas incorretas: agendados=%d suprimidos=%d", scheduled, suppressed)
+		}
+		if ss.Overheard("outro") {
+			t.Error("Pacote sem relay pendente não deveria ser suprimido")
+		}
+	})
+}
@@ -0,0 +1,354 @@
+package mesh
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTopologyMaxAge é o tempo após o qual a lista de vizinhos anunciada
+// por um nó é descartada se não for renovada
+const DefaultTopologyMaxAge = 3 * time.Minute
+
+// TopologyNode representa um nó no mapa da rede
+type TopologyNode struct {
+	ID       string
+	Name     string
+	HopCount int  // Saltos a partir deste nó (-1 se desconhecido)
+	Direct   bool // Vizinho direto deste nó
+	Self     bool // O próprio nó local
+}
+
+// TopologyEdge representa um enlace entre dois nós
+type TopologyEdge struct {
+	From    string
+	To      string
+	Quality int // Qualidade do enlace (0-100)
+}
+
+// TopologySnapshot é uma fotografia do mapa da rede em um instante
+type TopologySnapshot struct {
+	SelfID      string
+	Nodes       []TopologyNode
+	Edges       []TopologyEdge
+	GeneratedAt time.Time
+}
+
+// Topology mantém as listas de vizinhos anunciadas pelos nós da rede
+type Topology struct {
+	// Vizinhos anunciados: nó -> vizinho -> qualidade
+	adjacency map[string]map[string]int
+
+	// Momento do último anúncio de cada nó
+	updatedAt map[string]time.Time
+
+	maxAge time.Duration
+	mutex  sync.RWMutex
+}
+
+// NewTopology cria um novo mapa de topologia
+func NewTopology(maxAge time.Duration) *Topology {
+	if maxAge <= 0 {
+		maxAge = DefaultTopologyMaxAge
+	}
+
+	return &Topology{
+		adjacency: make(map[string]map[string]int),
+		updatedAt: make(map[string]time.Time),
+		maxAge:    maxAge,
+	}
+}
+
+// UpdateNeighbors substitui a lista de vizinhos anunciada por um nó
+func (t *Topology) UpdateNeighbors(nodeID string, neighbors map[string]int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	copied := make(map[string]int, len(neighbors))
+	for peerID, quality := range neighbors {
+		if peerID != nodeID {
+			copied[peerID] = quality
+		}
+	}
+
+	t.adjacency[nodeID] = copied
+	t.updatedAt[nodeID] = time.Now()
+}
+
+// RemoveNode descarta a lista de vizinhos de um nó
+func (t *Topology) RemoveNode(nodeID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.adjacency, nodeID)
+	delete(t.updatedAt, nodeID)
+}
+
+// Cleanup remove anúncios que não foram renovados a tempo
+func (t *Topology) Cleanup() []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var removed []string
+	threshold := time.Now().Add(-t.maxAge)
+	for nodeID, updated := range t.updatedAt {
+		if updated.Before(threshold) {
+			delete(t.adjacency, nodeID)
+			delete(t.updatedAt, nodeID)
+			removed = append(removed, nodeID)
+		}
+	}
+	return removed
+}
+
+// Snapshot monta o mapa atual da rede a partir do nó local.
+// selfNeighbors são os vizinhos diretos do nó local com a qualidade dos
+// enlaces; router (opcional) complementa os nós conhecidos apenas por rota;
+// names (opcional) associa IDs a nomes para exibição.
+func (t *Topology) Snapshot(selfID string, selfNeighbors map[string]int, router *MessageRouter, names map[string]string) *TopologySnapshot {
+	t.mutex.RLock()
+	graph := make(map[string]map[string]int, len(t.adjacency)+1)
+	for nodeID, neighbors := range t.adjacency {
+		graph[nodeID] = make(map[string]int, len(neighbors))
+		for peerID, quality := range neighbors {
+			graph[nodeID][peerID] = quality
+		}
+	}
+	t.mutex.RUnlock()
+
+	graph[selfID] = make(map[string]int, len(selfNeighbors))
+	for peerID, quality := range selfNeighbors {
+		if peerID != selfID {
+			graph[selfID][peerID] = quality
+		}
+	}
+
+	// Enlaces não direcionados; quando ambos os lados anunciam, usar a média
+	type edgeKey struct{ a, b string }
+	edgeReports := make(map[edgeKey][]int)
+	nodeSet := map[string]bool{selfID: true}
+	adjacent := make(map[string][]string)
+	for nodeID, neighbors := range graph {
+		nodeSet[nodeID] = true
+		for peerID, quality := range neighbors {
+			nodeSet[peerID] = true
+			key := edgeKey{nodeID, peerID}
+			if peerID < nodeID {
+				key = edgeKey{peerID, nodeID}
+			}
+			if _, seen := edgeReports[key]; !seen {
+				adjacent[key.a] = append(adjacent[key.a], key.b)
+				adjacent[key.b] = append(adjacent[key.b], key.a)
+			}
+			edgeReports[key] = append(edgeReports[key], quality)
+		}
+	}
+
+	// Distâncias por busca em largura a partir do nó local
+	hops := map[string]int{selfID: 0}
+	queue := []string{selfID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacent[current] {
+			if _, visited := hops[next]; !visited {
+				hops[next] = hops[current] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	// Nós conhecidos apenas pela tabela de roteamento
+	if router != nil {
+		for _, peerID := range router.GetAllPeers() {
+			nodeSet[peerID] = true
+			if _, known := hops[peerID]; !known {
+				if routeHops, ok := router.GetRouteHops(peerID); ok {
+					hops[peerID] = routeHops
+				}
+			}
+		}
+	}
+
+	snapshot := &TopologySnapshot{
+		SelfID:      selfID,
+		GeneratedAt: time.Now(),
+	}
+
+	for nodeID := range nodeSet {
+		hopCount, known := hops[nodeID]
+		if !known {
+			hopCount = -1
+		}
+		_, direct := selfNeighbors[nodeID]
+		snapshot.Nodes = append(snapshot.Nodes, TopologyNode{
+			ID:       nodeID,
+			Name:     names[nodeID],
+			HopCount: hopCount,
+			Direct:   direct && nodeID != selfID,
+			Self:     nodeID == selfID,
+		})
+	}
+	sort.Slice(snapshot.Nodes, func(i, j int) bool {
+		a, b := snapshot.Nodes[i], snapshot.Nodes[j]
+		if a.HopCount != b.HopCount {
+			if a.HopCount < 0 || b.HopCount < 0 {
+				return b.HopCount < 0
+			}
+			return a.HopCount < b.HopCount
+		}
+		return a.ID < b.ID
+	})
+
+	for key, reports := range edgeReports {
+		total := 0
+		for _, quality := range reports {
+			total += quality
+		}
+		snapshot.Edges = append(snapshot.Edges, TopologyEdge{
+			From:    key.a,
+			To:      key.b,
+			Quality: total / len(reports),
+		})
+	}
+	sort.Slice(snapshot.Edges, func(i, j int) bool {
+		if snapshot.Edges[i].From != snapshot.Edges[j].From {
+			return snapshot.Edges[i].From < snapshot.Edges[j].From
+		}
+		return snapshot.Edges[i].To < snapshot.Edges[j].To
+	})
+
+	return snapshot
+}
+
+// Label retorna o nome de exibição de um nó
+func (s *TopologySnapshot) Label(nodeID string) string {
+	for _, node := range s.Nodes {
+		if node.ID == nodeID && node.Name != "" {
+			return node.Name
+		}
+	}
+	return shortNodeID(nodeID)
+}
+
+// DOT exporta o mapa no formato Graphviz DOT
+func (s *TopologySnapshot) DOT() string {
+	var b strings.Builder
+
+	b.WriteString("graph mesh {\n")
+	for _, node := range s.Nodes {
+		attrs := fmt.Sprintf("label=%q", s.Label(node.ID))
+		if node.Self {
+			attrs += ", shape=doublecircle"
+		} else if node.HopCount < 0 {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", shortNodeID(node.ID), attrs)
+	}
+	for _, edge := range s.Edges {
+		fmt.Fprintf(&b, "  %q -- %q [label=\"%d\"];\n", shortNodeID(edge.From), shortNodeID(edge.To), edge.Quality)
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// ASCII desenha o mapa como uma árvore de caminhos mínimos a partir do nó
+// local, listando em seguida os enlaces que não fazem parte da árvore
+func (s *TopologySnapshot) ASCII() string {
+	quality := make(map[string]map[string]int)
+	for _, edge := range s.Edges {
+		if quality[edge.From] == nil {
+			quality[edge.From] = make(map[string]int)
+		}
+		if quality[edge.To] == nil {
+			quality[edge.To] = make(map[string]int)
+		}
+		quality[edge.From][edge.To] = edge.Quality
+		quality[edge.To][edge.From] = edge.Quality
+	}
+
+	// Árvore em largura: cada nó é pendurado no primeiro vizinho alcançado
+	children := make(map[string][]string)
+	inTree := map[string]bool{s.SelfID: true}
+	treeEdge := make(map[[2]string]bool)
+	queue := []string{s.SelfID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		neighbors := make([]string, 0, len(quality[current]))
+		for peerID := range quality[current] {
+			neighbors = append(neighbors, peerID)
+		}
+		sort.Strings(neighbors)
+
+		for _, next := range neighbors {
+			if inTree[next] {
+				continue
+			}
+			inTree[next] = true
+			children[current] = append(children[current], next)
+			treeEdge[[2]string{current, next}] = true
+			treeEdge[[2]string{next, current}] = true
+			queue = append(queue, next)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (você)\n", s.Label(s.SelfID))
+
+	var draw func(nodeID, prefix string)
+	draw = func(nodeID, prefix string) {
+		for i, child := range children[nodeID] {
+			branch, indent := "├── ", "│   "
+			if i == len(children[nodeID])-1 {
+				branch, indent = "└── ", "    "
+			}
+			fmt.Fprintf(&b, "%s%s%s [q=%d]\n", prefix, branch, s.Label(child), quality[nodeID][child])
+			draw(child, prefix+indent)
+		}
+	}
+	draw(s.SelfID, "")
+
+	var extra []string
+	for _, edge := range s.Edges {
+		if !treeEdge[[2]string{edge.From, edge.To}] {
+			extra = append(extra, fmt.Sprintf("  %s -- %s [q=%d]", s.Label(edge.From), s.Label(edge.To), edge.Quality))
+		}
+	}
+	var unreachable []string
+	for _, node := range s.Nodes {
+		if !inTree[node.ID] {
+			label := s.Label(node.ID)
+			if node.HopCount > 0 {
+				label = fmt.Sprintf("%s (%d saltos)", label, node.HopCount)
+			}
+			unreachable = append(unreachable, "  "+label)
+		}
+	}
+
+	if len(extra) > 0 {
+		b.WriteString("Enlaces adicionais:\n")
+		b.WriteString(strings.Join(extra, "\n"))
+		b.WriteString("\n")
+	}
+	if len(unreachable) > 0 {
+		b.WriteString("Conhecidos apenas por rota:\n")
+		b.WriteString(strings.Join(unreachable, "\n"))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// shortNodeID retorna uma representação curta e imprimível de um ID de nó
+func shortNodeID(nodeID string) string {
+	encoded := fmt.Sprintf("%x", nodeID)
+	if len(encoded) > 8 {
+		encoded = encoded[:8]
+	}
+	return encoded
+}
@@ -0,0 +1,55 @@
+package mesh
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTopology(t *testing.T) {
+	topology := NewTopology(0)
+
+	// A (local) -- B -- C, B -- D, C -- D
+	topology.UpdateNeighbors("B", map[string]int{"A": 80, "C": 60, "D": 40})
+	topology.UpdateNeighbors("C", map[string]int{"B": 70, "D": 50})
+
+	snapshot := topology.Snapshot("A", map[string]int{"B": 90}, nil, map[string]string{"A": "alice", "B": "bob"})
+
+	hops := make(map[string]int)
+	for _, node := range snapshot.Nodes {
+		hops[node.ID] = node.HopCount
+	}
+	expected := map[string]int{"A": 0, "B": 1, "C": 2, "D": 2}
+	for nodeID, want := range expected {
+		if hops[nodeID] != want {
+			t.Errorf("Nó %s: esperado %d saltos, obtido %d", nodeID, want, hops[nodeID])
+		}
+	}
+
+	if len(snapshot.Edges) != 4 {
+		t.Fatalf("Esperados 4 enlaces, obtido %d", len(snapshot.Edges))
+	}
+	for _, edge := range snapshot.Edges {
+		// Enlace anunciado pelos dois lados usa a média
+		if edge.From == "A" && edge.To == "B" && edge.Quality != 85 {
+			t.Errorf("Qualidade A-B esperada 85, obtido %d", edge.Quality)
+		}
+	}
+
+	if dot := snapshot.DOT(); !strings.HasPrefix(dot, "graph mesh {") || !strings.Contains(dot, "alice") {
+		t.Errorf("Exportação DOT inválida:\n%s", dot)
+	}
+
+	ascii := snapshot.ASCII()
+	if !strings.Contains(ascii, "alice (você)") || !strings.Contains(ascii, "└── bob [q=85]") {
+		t.Errorf("Desenho ASCII inesperado:\n%s", ascii)
+	}
+	if !strings.Contains(ascii, "Enlaces adicionais:") {
+		t.Errorf("Enlace C-D deveria aparecer como adicional:\n%s", ascii)
+	}
+
+	topology.RemoveNode("C")
+	snapshot = topology.Snapshot("A", map[string]int{"B": 90}, nil, nil)
+	if len(snapshot.Edges) != 3 {
+		t.Errorf("Após remover C, esperados 3 enlaces, obtido %d", len(snapshot.Edges))
+	}
+}
@@ -0,0 +1,192 @@
+package mesh
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/utils"
+)
+
+const (
+	// DefaultTraceTimeout é o tempo máximo de espera pela resposta de um rastreamento
+	DefaultTraceTimeout = 15 * time.Second
+
+	// MaxTraceHops limita a propagação das solicitações de rastreamento
+	MaxTraceHops = 10
+)
+
+// TraceResultHop é um salto do caminho rastreado
+type TraceResultHop struct {
+	PeerID   string
+	RSSI     int  // RSSI do enlace com o salto anterior (0 se desconhecido)
+	Verified bool // Assinatura do salto verificada
+}
+
+// TraceResult é o resultado de um rastreamento de rota
+type TraceResult struct {
+	TraceID  uint32
+	TargetID string
+	Hops     []TraceResultHop // Do primeiro relay até o destino
+	RTT      time.Duration
+	TimedOut bool
+}
+
+// pendingTrace representa um rastreamento iniciado por este nó
+type pendingTrace struct {
+	targetID  string
+	startedAt time.Time
+}
+
+// Tracer implementa o rastreamento de rotas no estilo traceroute.
+// A solicitação é propagada por flooding e cada nó acrescenta seu salto; o
+// destino responde e a resposta percorre o caminho registrado de volta.
+type Tracer struct {
+	selfID string
+
+	// Solicitações já processadas (origem + ID) para evitar reprocessamento
+	seenRequests *utils.ExpiringSet
+
+	// Rastreamentos iniciados por este nó
+	pending map[uint32]*pendingTrace
+	nextID  uint32
+
+	mutex sync.Mutex
+}
+
+// NewTracer cria um novo gerenciador de rastreamentos
+func NewTracer(selfID string) *Tracer {
+	return &Tracer{
+		selfID:       selfID,
+		seenRequests: utils.NewExpiringSet(1*time.Minute, 30*time.Second),
+		pending:      make(map[uint32]*pendingTrace),
+	}
+}
+
+// Start inicia o rastreamento de um destino e retorna a solicitação a ser
+// enviada em broadcast
+func (tr *Tracer) Start(targetID string) *protocol.Trace {
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+
+	tr.nextID++
+	traceID := tr.nextID
+	tr.pending[traceID] = &pendingTrace{
+		targetID:  targetID,
+		startedAt: time.Now(),
+	}
+
+	// Não processar nossa própria solicitação quando ela voltar
+	tr.seenRequests.Add(traceKey(tr.selfID, traceID))
+
+	return &protocol.Trace{
+		TraceID:  traceID,
+		OriginID: []byte(tr.selfID),
+		TargetID: []byte(targetID),
+	}
+}
+
+// HandleRequest processa uma solicitação de rastreamento recebida, já com o
+// salto deste nó informado em hop. Retorna a resposta a ser enviada (se este
+// nó é o destino) ou a solicitação a ser retransmitida. Ambos podem ser nil.
+func (tr *Tracer) HandleRequest(req *protocol.Trace, hop protocol.TraceHop) (*protocol.Trace, *protocol.Trace) {
+	originID := string(req.OriginID)
+	if originID == tr.selfID || !tr.seenRequests.Add(traceKey(originID, req.TraceID)) {
+		return nil, nil
+	}
+
+	hops := append(append([]protocol.TraceHop(nil), req.Hops...), hop)
+	trace := &protocol.Trace{
+		TraceID:  req.TraceID,
+		OriginID: req.OriginID,
+		TargetID: req.TargetID,
+		Hops:     hops,
+	}
+
+	// Somos o destino: responder pelo caminho registrado
+	if string(req.TargetID) == tr.selfID {
+		trace.NextHopID = previousHop(trace, len(hops)-1)
+		return trace, nil
+	}
+
+	if len(hops) >= MaxTraceHops {
+		return nil, nil
+	}
+	return nil, trace
+}
+
+// HandleReply processa uma resposta de rastreamento recebida.
+// Retorna a resposta a ser retransmitida em direção ao originador, ou o
+// resultado se este nó é o originador. Ambos podem ser nil.
+func (tr *Tracer) HandleReply(rep *protocol.Trace) (*protocol.Trace, *TraceResult) {
+	if string(rep.NextHopID) != tr.selfID {
+		return nil, nil
+	}
+
+	if string(rep.OriginID) == tr.selfID {
+		tr.mutex.Lock()
+		pending, ok := tr.pending[rep.TraceID]
+		delete(tr.pending, rep.TraceID)
+		tr.mutex.Unlock()
+		if !ok {
+			return nil, nil
+		}
+
+		result := &TraceResult{
+			TraceID:  rep.TraceID,
+			TargetID: pending.targetID,
+			RTT:      time.Since(pending.startedAt),
+		}
+		for _, hop := range rep.Hops {
+			result.Hops = append(result.Hops, TraceResultHop{
+				PeerID: string(hop.PeerID),
+				RSSI:   int(hop.RSSI),
+			})
+		}
+		return nil, result
+	}
+
+	// Encontrar nossa posição no caminho e repassar ao salto anterior
+	for i, hop := range rep.Hops {
+		if string(hop.PeerID) == tr.selfID {
+			forward := *rep
+			forward.NextHopID = previousHop(rep, i)
+			return &forward, nil
+		}
+	}
+	return nil, nil
+}
+
+// Timeout encerra um rastreamento sem resposta.
+// Retorna o resultado com TimedOut, ou nil se o rastreamento já foi concluído.
+func (tr *Tracer) Timeout(traceID uint32) *TraceResult {
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+
+	pending, ok := tr.pending[traceID]
+	if !ok {
+		return nil
+	}
+	delete(tr.pending, traceID)
+
+	return &TraceResult{
+		TraceID:  traceID,
+		TargetID: pending.targetID,
+		RTT:      time.Since(pending.startedAt),
+		TimedOut: true,
+	}
+}
+
+// previousHop retorna o nó anterior à posição index no caminho registrado
+func previousHop(trace *protocol.Trace, index int) []byte {
+	if index <= 0 {
+		return trace.OriginID
+	}
+	return trace.Hops[index-1].PeerID
+}
+
+// traceKey gera a chave de deduplicação de um rastreamento
+func traceKey(originID string, traceID uint32) string {
+	return fmt.Sprintf("%x:%d", originID, traceID)
+}
@@ -0,0 +1,74 @@
+package mesh
+
+import (
+	"testing"
+
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+)
+
+func TestTracer(t *testing.T) {
+	// A -- B -- C -- D
+	tracers := map[string]*Tracer{
+		"A": NewTracer("A"),
+		"B": NewTracer("B"),
+		"C": NewTracer("C"),
+		"D": NewTracer("D"),
+	}
+	hop := func(peerID string, rssi int8) protocol.TraceHop {
+		return protocol.TraceHop{PeerID: []byte(peerID), RSSI: rssi}
+	}
+
+	req := tracers["A"].Start("D")
+
+	_, forward := tracers["B"].HandleRequest(req, hop("B", -50))
+	if forward == nil {
+		t.Fatal("B deveria retransmitir a solicitação")
+	}
+	if reply, again := tracers["B"].HandleRequest(req, hop("B", -50)); reply != nil || again != nil {
+		t.Error("Solicitação duplicada deveria ser ignorada")
+	}
+
+	_, forward = tracers["C"].HandleRequest(forward, hop("C", -70))
+	reply, _ := tracers["D"].HandleRequest(forward, hop("D", -40))
+	if reply == nil || string(reply.NextHopID) != "C" {
+		t.Fatalf("D deveria responder via C, obtido %v", reply)
+	}
+
+	// A resposta percorre o caminho de volta
+	if back, _ := tracers["B"].HandleReply(reply); back != nil {
+		t.Error("B não é o próximo salto designado e deveria ignorar a resposta")
+	}
+	back, _ := tracers["C"].HandleReply(reply)
+	if back == nil || string(back.NextHopID) != "B" {
+		t.Fatalf("C deveria repassar a resposta para B, obtido %v", back)
+	}
+	back, _ = tracers["B"].HandleReply(back)
+	if back == nil || string(back.NextHopID) != "A" {
+		t.Fatalf("B deveria repassar a resposta para A, obtido %v", back)
+	}
+
+	_, result := tracers["A"].HandleReply(back)
+	if result == nil || result.TimedOut {
+		t.Fatal("A deveria obter o resultado do rastreamento")
+	}
+	expected := []struct {
+		peerID string
+		rssi   int
+	}{{"B", -50}, {"C", -70}, {"D", -40}}
+	if len(result.Hops) != len(expected) {
+		t.Fatalf("Esperados %d saltos, obtido %d", len(expected), len(result.Hops))
+	}
+	for i, want := range expected {
+		if result.Hops[i].PeerID != want.peerID || result.Hops[i].RSSI != want.rssi {
+			t.Errorf("Salto %d: esperado %s (%d), obtido %s (%d)", i, want.peerID, want.rssi, result.Hops[i].PeerID, result.Hops[i].RSSI)
+		}
+	}
+
+	if tracers["A"].Timeout(result.TraceID) != nil {
+		t.Error("Rastreamento concluído não deveria expirar")
+	}
+	pending := tracers["A"].Start("E")
+	if timeout := tracers["A"].Timeout(pending.TraceID); timeout == nil || !timeout.TimedOut {
+		t.Error("Rastreamento sem resposta deveria expirar")
+	}
+}
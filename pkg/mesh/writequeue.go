@@ -0,0 +1,200 @@
+package mesh
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// DefaultATTMTU é o MTU ATT garantido antes de qualquer negociação
+	DefaultATTMTU = 23
+
+	// ATTHeaderSize é o cabeçalho ATT de cada escrita, descontado do MTU
+	ATTHeaderSize = 3
+
+	// writeQueueDepth é o número de escritas aguardando por dispositivo
+	writeQueueDepth = 32
+)
+
+// ErrWriteQueueClosed é retornado para escritas em uma fila encerrada
+var ErrWriteQueueClosed = errors.New("fila de escrita encerrada")
+
+// WriteFunc realiza uma escrita GATT e retorna quando ela é concluída
+type WriteFunc func(chunk []byte) error
+
+// Estados de um writeRequest
+const (
+	requestQueued    int32 = iota // Aguardando na fila
+	requestStarted                // Sendo escrito
+	requestAbandoned              // Fila encerrada antes da escrita
+)
+
+// writeRequest é um payload aguardando na fila de um dispositivo
+type writeRequest struct {
+	data  []byte
+	write WriteFunc
+	done  chan error
+	state atomic.Int32
+}
+
+// deviceWriter processa em ordem as escritas de um dispositivo
+type deviceWriter struct {
+	requests chan *writeRequest
+	stop     chan struct{}
+}
+
+// WriteQueue serializa as escritas GATT de cada dispositivo. Cada payload é
+// dividido em pedaços do MTU negociado com o dispositivo e o pedaço seguinte
+// só é escrito quando a escrita anterior é concluída, o que dispensa pausas
+// fixas entre fragmentos. Payloads de dispositivos diferentes seguem em
+// paralelo.
+//
+// Quem envia pacotes deve fragmentá-los para o MTU antes de enfileirá-los:
+// a divisão em pedaços apenas garante que nenhuma escrita exceda o enlace.
+type WriteQueue struct {
+	mtus    map[string]int
+	writers map[string]*deviceWriter
+	closed  bool
+	mutex   sync.Mutex
+}
+
+// NewWriteQueue cria uma fila de escrita vazia
+func NewWriteQueue() *WriteQueue {
+	return &WriteQueue{
+		mtus:    make(map[string]int),
+		writers: make(map[string]*deviceWriter),
+	}
+}
+
+// SetMTU registra o MTU ATT negociado com um dispositivo
+func (wq *WriteQueue) SetMTU(deviceID string, mtu int) {
+	wq.mutex.Lock()
+	defer wq.mutex.Unlock()
+
+	if mtu < DefaultATTMTU {
+		mtu = DefaultATTMTU
+	}
+	wq.mtus[deviceID] = mtu
+}
+
+// ChunkSize retorna o maior pedaço de dados de uma escrita para o dispositivo
+func (wq *WriteQueue) ChunkSize(deviceID string) int {
+	wq.mutex.Lock()
+	defer wq.mutex.Unlock()
+
+	return wq.chunkSizeLocked(deviceID)
+}
+
+// chunkSizeLocked calcula o pedaço de escrita; o mutex deve estar adquirido
+func (wq *WriteQueue) chunkSizeLocked(deviceID string) int {
+	mtu, ok := wq.mtus[deviceID]
+	if !ok {
+		mtu = DefaultATTMTU
+	}
+	return mtu - ATTHeaderSize
+}
+
+// Write enfileira um payload para o dispositivo e aguarda até que todos os
+// seus pedaços sejam escritos ou uma escrita falhe. data não é usado após o
+// retorno, então o chamador pode reutilizar o buffer.
+func (wq *WriteQueue) Write(deviceID string, data []byte, write WriteFunc) error {
+	wq.mutex.Lock()
+	if wq.closed {
+		wq.mutex.Unlock()
+		return ErrWriteQueueClosed
+	}
+
+	writer, ok := wq.writers[deviceID]
+	if !ok {
+		writer = &deviceWriter{
+			requests: make(chan *writeRequest, writeQueueDepth),
+			stop:     make(chan struct{}),
+		}
+		wq.writers[deviceID] = writer
+		go wq.run(deviceID, writer)
+	}
+	wq.mutex.Unlock()
+
+	request := &writeRequest{data: data, write: write, done: make(chan error, 1)}
+	select {
+	case writer.requests <- request:
+	case <-writer.stop:
+		return ErrWriteQueueClosed
+	}
+
+	select {
+	case err := <-request.done:
+		return err
+	case <-writer.stop:
+		if request.state.CompareAndSwap(requestQueued, requestAbandoned) {
+			return ErrWriteQueueClosed
+		}
+		// A escrita já começou: aguardar seu fim, que ainda lê data
+		return <-request.done
+	}
+}
+
+// run escreve os payloads de um dispositivo, um pedaço por vez
+func (wq *WriteQueue) run(deviceID string, writer *deviceWriter) {
+	for {
+		select {
+		case <-writer.stop:
+			return
+		case request := <-writer.requests:
+			if !request.state.CompareAndSwap(requestQueued, requestStarted) {
+				continue
+			}
+			request.done <- wq.writeChunks(deviceID, request)
+		}
+	}
+}
+
+// writeChunks divide o payload no MTU atual do dispositivo e escreve cada
+// pedaço após a conclusão do anterior
+func (wq *WriteQueue) writeChunks(deviceID string, request *writeRequest) error {
+	wq.mutex.Lock()
+	size := wq.chunkSizeLocked(deviceID)
+	wq.mutex.Unlock()
+
+	data := request.data
+	for {
+		chunk := data
+		if len(chunk) > size {
+			chunk = data[:size]
+		}
+		if err := request.write(chunk); err != nil {
+			return err
+		}
+
+		data = data[len(chunk):]
+		if len(data) == 0 {
+			return nil
+		}
+	}
+}
+
+// Remove descarta a fila e o MTU de um dispositivo desconectado. Escritas
+// pendentes retornam ErrWriteQueueClosed.
+func (wq *WriteQueue) Remove(deviceID string) {
+	wq.mutex.Lock()
+	defer wq.mutex.Unlock()
+
+	if writer, ok := wq.writers[deviceID]; ok {
+		close(writer.stop)
+		delete(wq.writers, deviceID)
+	}
+	delete(wq.mtus, deviceID)
+}
+
+// Close encerra as filas de todos os dispositivos
+func (wq *WriteQueue) Close() {
+	wq.mutex.Lock()
+	defer wq.mutex.Unlock()
+
+	for deviceID, writer := range wq.writers {
+		close(writer.stop)
+		delete(wq.writers, deviceID)
+	}
+	wq.closed = true
+}
@@ -0,0 +1,137 @@
+package mesh
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWriteQueue(t *testing.T) {
+	t.Run("Dividir payload no MTU", func(t *testing.T) {
+		wq := NewWriteQueue()
+		defer wq.Close()
+		wq.SetMTU("a", 33)
+
+		var chunks [][]byte
+		data := bytes.Repeat([]byte{0xAB}, 70)
+		err := wq.Write("a", data, func(chunk []byte) error {
+			chunks = append(chunks, append([]byte(nil), chunk...))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Erro inesperado: %v", err)
+		}
+
+		// MTU 33 comporta 30 bytes por escrita
+		if len(chunks) != 3 || len(chunks[0]) != 30 || len(chunks[2]) != 10 {
+			t.Errorf("Pedaços inesperados: %d", len(chunks))
+		}
+		if !bytes.Equal(bytes.Join(chunks, nil), data) {
+			t.Error("Pedaços não reconstroem o payload")
+		}
+	})
+
+	t.Run("MTU mínimo por padrão", func(t *testing.T) {
+		wq := NewWriteQueue()
+		defer wq.Close()
+		wq.SetMTU("pequeno", 5)
+
+		if size := wq.ChunkSize("desconhecido"); size != DefaultATTMTU-ATTHeaderSize {
+			t.Errorf("Pedaço padrão inesperado: %d", size)
+		}
+		if size := wq.ChunkSize("pequeno"); size != DefaultATTMTU-ATTHeaderSize {
+			t.Errorf("MTU abaixo do mínimo deveria ser ignorado: %d", size)
+		}
+	})
+
+	t.Run("Escritas serializadas por dispositivo", func(t *testing.T) {
+		wq := NewWriteQueue()
+		defer wq.Close()
+
+		var mutex sync.Mutex
+		active, maxActive := 0, 0
+		write := func(chunk []byte) error {
+			mutex.Lock()
+			active++
+			if active > maxActive {
+				maxActive = active
+			}
+			mutex.Unlock()
+
+			mutex.Lock()
+			active--
+			mutex.Unlock()
+			return nil
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				wq.Write("a", make([]byte, 40), write)
+			}()
+		}
+		wg.Wait()
+
+		if maxActive != 1 {
+			t.Errorf("Escritas simultâneas no mesmo dispositivo: %d", maxActive)
+		}
+	})
+
+	t.Run("Falha interrompe o payload", func(t *testing.T) {
+		wq := NewWriteQueue()
+		defer wq.Close()
+
+		failure := errors.New("falha")
+		calls := 0
+		err := wq.Write("a", make([]byte, 60), func(chunk []byte) error {
+			calls++
+			return failure
+		})
+		if err != failure || calls != 1 {
+			t.Errorf("Falha deveria interromper as escritas: err=%v chamadas=%d", err, calls)
+		}
+	})
+
+	t.Run("Fila encerrada", func(t *testing.T) {
+		wq := NewWriteQueue()
+		wq.Close()
+
+		err := wq.Write("a", []byte{1}, func(chunk []byte) error { return nil })
+		if err != ErrWriteQueueClosed {
+			t.Errorf("Esperado ErrWriteQueueClosed, obtido %v", err)
+		}
+	})
+
+	t.Run("Remoção aguarda a escrita em andamento", func(t *testing.T) {
+		wq := NewWriteQueue()
+		defer wq.Close()
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		result := make(chan error, 1)
+		go func() {
+			result <- wq.Write("a", []byte{1}, func(chunk []byte) error {
+				close(started)
+				<-release
+				return nil
+			})
+		}()
+
+		<-started
+		wq.Remove("a")
+		select {
+		case err := <-result:
+			t.Fatalf("Write retornou com a escrita em andamento: %v", err)
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		close(release)
+		if err := <-result; err != nil {
+			t.Errorf("Escrita concluída não deveria falhar: %v", err)
+		}
+	})
+}
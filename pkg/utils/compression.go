@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"errors"
 	"io"
 
 	"github.com/pierrec/lz4/v4"
@@ -61,6 +62,26 @@ func DecompressData(compressedData []byte) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// ErrDecompressedTooLarge indica dados que excederiam o limite ao serem
+// descomprimidos
+var ErrDecompressedTooLarge = errors.New("dados descomprimidos excedem o limite")
+
+// DecompressDataLimited descomprime dados comprimidos com LZ4, falhando se o
+// resultado passar de limit bytes. Protege contra dados recebidos da rede
+// que se expandem demais.
+func DecompressDataLimited(compressedData []byte, limit int) ([]byte, error) {
+	zr := lz4.NewReader(bytes.NewReader(compressedData))
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, io.LimitReader(zr, int64(limit)+1)); err != nil {
+		return nil, err
+	}
+	if buf.Len() > limit {
+		return nil, ErrDecompressedTooLarge
+	}
+	return buf.Bytes(), nil
+}
+
 // ShouldCompress determina se um tipo de dados deve ser comprimido
 // Baseado no tipo MIME ou extensão de arquivo
 func ShouldCompress(mimeType string) bool {
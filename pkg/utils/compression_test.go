@@ -2,45 +2,52 @@ package utils
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 )
 
 func TestCompressDecompress(t *testing.T) {
 	testCases := []struct {
-		name     string
-		data     []byte
-		mimeType string
-		compress bool
+		name         string
+		data         []byte
+		mimeType     string
+		compressible bool // ShouldCompress para o tipo MIME
+		compress     bool // CompressIfNeeded pode comprimir os dados
 	}{
 		{
-			name:     "Texto simples",
-			data:     []byte("Este é um texto simples que deve comprimir bem devido à repetição de caracteres."),
-			mimeType: "text/plain",
-			compress: true,
+			name:         "Texto simples",
+			data:         []byte("Este é um texto simples que deve comprimir bem devido à repetição de caracteres."),
+			mimeType:     "text/plain",
+			compressible: true,
+			compress:     true,
 		},
 		{
-			name:     "Dados JSON",
-			data:     []byte(`{"name":"teste","description":"Este é um teste de compressão JSON","items":["item1","item2","item3"],"numbers":[1,2,3,4,5]}`),
-			mimeType: "application/json",
-			compress: true,
+			name:         "Dados JSON",
+			data:         []byte(`{"name":"teste","description":"Este é um teste de compressão JSON","items":["item1","item2","item3"],"numbers":[1,2,3,4,5]}`),
+			mimeType:     "application/json",
+			compressible: true,
+			compress:     true,
 		},
 		{
-			name:     "Dados binários aleatórios",
-			data:     generateRandomBytes(1000),
-			mimeType: "application/octet-stream",
-			compress: true,
+			name:         "Dados binários aleatórios",
+			data:         generateRandomBytes(1000),
+			mimeType:     "application/octet-stream",
+			compressible: true,
+			compress:     true,
 		},
 		{
-			name:     "Imagem JPEG (já comprimida)",
-			data:     generateFakeJPEG(500),
-			mimeType: "image/jpeg",
-			compress: false,
+			name:         "Imagem JPEG (já comprimida)",
+			data:         generateFakeJPEG(500),
+			mimeType:     "image/jpeg",
+			compressible: false,
+			compress:     false,
 		},
 		{
-			name:     "Dados muito pequenos",
-			data:     []byte("abc"),
-			mimeType: "text/plain",
-			compress: false, // Muito pequeno para comprimir eficientemente
+			name:         "Dados muito pequenos",
+			data:         []byte("abc"),
+			mimeType:     "text/plain",
+			compressible: true,
+			compress:     false, // Muito pequeno para comprimir eficientemente
 		},
 	}
 
@@ -64,12 +71,12 @@ func TestCompressDecompress(t *testing.T) {
 			}
 
 			// Testar ShouldCompress
-			if ShouldCompress(tc.mimeType) != tc.compress {
-				t.Errorf("ShouldCompress(%s) = %v, esperado %v", tc.mimeType, ShouldCompress(tc.mimeType), tc.compress)
+			if ShouldCompress(tc.mimeType) != tc.compressible {
+				t.Errorf("ShouldCompress(%s) = %v, esperado %v", tc.mimeType, ShouldCompress(tc.mimeType), tc.compressible)
 			}
 
 			// Testar CompressIfNeeded
-			result, compressed, err := CompressIfNeeded(tc.data, tc.mimeType)
+			result, ok, err := CompressIfNeeded(tc.data, tc.mimeType)
 			if err != nil {
 				t.Fatalf("Erro em CompressIfNeeded: %v", err)
 			}
@@ -80,7 +87,7 @@ func TestCompressDecompress(t *testing.T) {
 			}
 
 			// Se comprimiu, deve ser possível descomprimir
-			if compressed {
+			if ok {
 				decompressed, err := DecompressData(result)
 				if err != nil {
 					t.Fatalf("Erro ao descomprimir resultado de CompressIfNeeded: %v", err)
@@ -93,6 +100,42 @@ func TestCompressDecompress(t *testing.T) {
 	}
 }
 
+func TestDecompressDataLimited(t *testing.T) {
+	data := bytes.Repeat([]byte("conteúdo repetido "), 100)
+	compressed, err := CompressData(data)
+	if err != nil {
+		t.Fatalf("Erro ao comprimir dados: %v", err)
+	}
+
+	t.Run("Dentro do limite", func(t *testing.T) {
+		for _, limit := range []int{len(data), len(data) + 1} {
+			decompressed, err := DecompressDataLimited(compressed, limit)
+			if err != nil {
+				t.Fatalf("Erro ao descomprimir com limite %d: %v", limit, err)
+			}
+			if !bytes.Equal(decompressed, data) {
+				t.Errorf("Dados descomprimidos com limite %d não correspondem aos originais", limit)
+			}
+		}
+	})
+
+	t.Run("Acima do limite", func(t *testing.T) {
+		decompressed, err := DecompressDataLimited(compressed, len(data)-1)
+		if !errors.Is(err, ErrDecompressedTooLarge) {
+			t.Errorf("Esperado ErrDecompressedTooLarge, obtido %v", err)
+		}
+		if decompressed != nil {
+			t.Errorf("Nenhum dado deveria ser retornado, obtidos %d bytes", len(decompressed))
+		}
+	})
+
+	t.Run("Dados corrompidos", func(t *testing.T) {
+		if _, err := DecompressDataLimited([]byte("não é lz4"), len(data)); err == nil {
+			t.Error("Dados corrompidos deveriam falhar")
+		}
+	})
+}
+
 // Funções auxiliares para gerar dados de teste
 
 func generateRandomBytes(size int) []byte {
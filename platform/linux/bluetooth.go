@@ -1,477 +0,0 @@
-// +build linux
-
-package linux
-
-import (
-	"context"
-	"fmt"
-	"sync"
-
-	"github.com/godbus/dbus/v5"
-	"github.com/muka/go-bluetooth/bluez/profile/adapter"
-	"github.com/muka/go-bluetooth/bluez/profile/device"
-	"github.com/permissionlesstech/bitchat/platform"
-)
-
-const (
-	bitchatServiceUUID = "6E400001-B5A3-F393-E0A9-E50E24DCCA9E" // UUID do serviço Bitchat
-	rxCharacteristicUUID = "6E400002-B5A3-F393-E0A9-E50E24DCCA9E" // Característica para receber dados
-	txCharacteristicUUID = "6E400003-B5A3-F393-E0A9-E50E24DCCA9E" // Característica para enviar dados
-)
-
-// LinuxBluetoothAdapter implementa a interface BluetoothAdapter para Linux usando BlueZ
-type LinuxBluetoothAdapter struct {
-	adapter           *adapter.Adapter1
-	adapterID         string
-	advertisement     interface{}
-	cleanupAdvertisement func() error
-	gattManager       interface{}
-	gattService       interface{}
-	gattCharacteristics map[string]interface{}
-	
-	devices           map[string]*device.Device1
-	deviceInfo        map[string]platform.BluetoothDevice
-	
-	isRunning         bool
-	isDiscovering     bool
-	isAdvertising     bool
-	
-	ctx               context.Context
-	cancel            context.CancelFunc
-	
-	onDeviceDiscovered          func(device platform.BluetoothDevice)
-	onCharacteristicRead        func(deviceID, serviceUUID, characteristicUUID string) []byte
-	onCharacteristicWrite       func(deviceID, serviceUUID, characteristicUUID string, value []byte)
-	onConnectionStateChanged    func(deviceID string, connected bool)
-	
-	mutex             sync.RWMutex
-}
-
-// NewLinuxBluetoothAdapter cria uma nova instância do adaptador Bluetooth para Linux
-func NewLinuxBluetoothAdapter() (*LinuxBluetoothAdapter, error) {
-	// Simplificado para compilação
-	adapterID := "hci0" // Adaptador padrão
-	adapter, err := adapter.NewAdapter1FromAdapterID(adapterID)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao criar adaptador: %v", err)
-	}
-	
-	// Criar contexto cancelável
-	ctx, cancel := context.WithCancel(context.Background())
-	
-	return &LinuxBluetoothAdapter{
-		adapter:            adapter,
-		adapterID:          adapterID,
-		devices:            make(map[string]*device.Device1),
-		deviceInfo:         make(map[string]platform.BluetoothDevice),
-		gattCharacteristics: make(map[string]interface{}),
-		ctx:                ctx,
-		cancel:             cancel,
-	}, nil
-}
-
-// Initialize inicializa o adaptador Bluetooth
-func (a *LinuxBluetoothAdapter) Initialize() error {
-	// Ligar o adaptador
-	if err := a.adapter.SetPowered(true); err != nil {
-		return fmt.Errorf("erro ao ligar adaptador Bluetooth: %v", err)
-	}
-	
-	// Configuração GATT simplificada para compilação
-	// Implementação completa requer ajustes na API
-	
-	return nil
-}
-
-// Start inicia o adaptador Bluetooth
-func (a *LinuxBluetoothAdapter) Start(ctx context.Context) error {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-	
-	if a.isRunning {
-		return nil
-	}
-	
-	// Configurar descoberta de dispositivos
-	options := make(map[string]interface{})
-	options["Transport"] = "le" // Apenas BLE
-	
-	// Configurar callback para novos dispositivos
-	err := a.adapter.SetDiscoveryFilter(options)
-	if err != nil {
-		return fmt.Errorf("erro ao configurar filtro de descoberta: %v", err)
-	}
-	
-	// Registrar para eventos de dispositivos
-	// Nota: A API atual não suporta diretamente eventos On, usaremos monitoramento periódico
-	// em vez de callbacks diretos
-	
-	a.isRunning = true
-	
-	return nil
-}
-
-// Stop para o adaptador Bluetooth
-func (a *LinuxBluetoothAdapter) Stop() error {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-	
-	if !a.isRunning {
-		return nil
-	}
-	
-	// Parar descoberta
-	if a.isDiscovering {
-		if err := a.adapter.StopDiscovery(); err != nil {
-			return fmt.Errorf("erro ao parar descoberta: %v", err)
-		}
-		a.isDiscovering = false
-	}
-	
-	// Parar anúncio
-	if a.isAdvertising && a.cleanupAdvertisement != nil {
-		if err := a.cleanupAdvertisement(); err != nil {
-			return fmt.Errorf("erro ao parar anúncio: %v", err)
-		}
-		a.isAdvertising = false
-	}
-	
-	// Parar serviço GATT
-	// Nota: Implementação simplificada para evitar erros de compilação
-	a.gattService = nil
-	a.gattCharacteristics = make(map[string]interface{})
-	
-	// Desregistrar eventos - simplificado para compilação
-	
-	a.isRunning = false
-	
-	return nil
-}
-
-// IsRunning verifica se o adaptador está em execução
-func (a *LinuxBluetoothAdapter) IsRunning() bool {
-	a.mutex.RLock()
-	defer a.mutex.RUnlock()
-	
-	return a.isRunning
-}
-
-// SetName define o nome do adaptador Bluetooth
-func (a *LinuxBluetoothAdapter) SetName(name string) error {
-	return a.adapter.SetAlias(name)
-}
-
-// GetName retorna o nome do adaptador Bluetooth
-func (a *LinuxBluetoothAdapter) GetName() (string, error) {
-	return a.adapter.GetAlias()
-}
-
-// SetDiscoverable define se o adaptador é descobrível
-func (a *LinuxBluetoothAdapter) SetDiscoverable(discoverable bool) error {
-	if err := a.adapter.SetDiscoverable(discoverable); err != nil {
-		return err
-	}
-	
-	// Se for descobrível, definir tempo de descoberta para 0 (infinito)
-	if discoverable {
-		return a.adapter.SetDiscoverableTimeout(0)
-	}
-	
-	return nil
-}
-
-// IsDiscoverable verifica se o adaptador é descobrível
-func (a *LinuxBluetoothAdapter) IsDiscoverable() (bool, error) {
-	return a.adapter.GetDiscoverable()
-}
-
-// StartDiscovery inicia a descoberta de dispositivos
-func (a *LinuxBluetoothAdapter) StartDiscovery() error {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-	
-	if a.isDiscovering {
-		return nil
-	}
-	
-	if err := a.adapter.StartDiscovery(); err != nil {
-		return fmt.Errorf("erro ao iniciar descoberta: %v", err)
-	}
-	
-	a.isDiscovering = true
-	
-	return nil
-}
-
-// StopDiscovery para a descoberta de dispositivos
-func (a *LinuxBluetoothAdapter) StopDiscovery() error {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-	
-	if !a.isDiscovering {
-		return nil
-	}
-	
-	if err := a.adapter.StopDiscovery(); err != nil {
-		return fmt.Errorf("erro ao parar descoberta: %v", err)
-	}
-	
-	a.isDiscovering = false
-	
-	return nil
-}
-
-// IsDiscovering verifica se a descoberta está ativa
-func (a *LinuxBluetoothAdapter) IsDiscovering() (bool, error) {
-	a.mutex.RLock()
-	defer a.mutex.RUnlock()
-	
-	return a.isDiscovering, nil
-}
-
-// GetDiscoveredDevices retorna a lista de dispositivos descobertos
-func (a *LinuxBluetoothAdapter) GetDiscoveredDevices() ([]platform.BluetoothDevice, error) {
-	a.mutex.RLock()
-	defer a.mutex.RUnlock()
-	
-	devices := make([]platform.BluetoothDevice, 0, len(a.deviceInfo))
-	for _, device := range a.deviceInfo {
-		devices = append(devices, device)
-	}
-	
-	return devices, nil
-}
-
-// StartAdvertising inicia o anúncio BLE
-func (a *LinuxBluetoothAdapter) StartAdvertising(serviceUUID string, manufacturerData []byte) error {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-
-	if a.isAdvertising {
-		return nil
-	}
-
-	// Simplificado para compilação
-
-	// Implementação simplificada para compilação
-	fmt.Printf("Iniciando anúncio para serviço %s com %d bytes de dados\n", serviceUUID, len(manufacturerData))
-
-	// Armazenar estado
-	a.cleanupAdvertisement = func() error { return nil }
-	
-	a.isAdvertising = true
-	
-	return nil
-}
-
-// StopAdvertising para o anúncio BLE
-func (a *LinuxBluetoothAdapter) StopAdvertising() error {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-	
-	if !a.isAdvertising || a.advertisement == nil {
-		return nil
-	}
-	
-	// Parar anúncio
-	if a.cleanupAdvertisement != nil {
-		if err := a.cleanupAdvertisement(); err != nil {
-			return fmt.Errorf("erro ao parar anúncio: %v", err)
-		}
-		a.cleanupAdvertisement = nil
-	}
-	
-	a.isAdvertising = false
-	a.advertisement = nil
-	
-	return nil
-}
-
-// IsAdvertising verifica se o anúncio está ativo
-func (a *LinuxBluetoothAdapter) IsAdvertising() (bool, error) {
-	a.mutex.RLock()
-	defer a.mutex.RUnlock()
-	
-	return a.isAdvertising, nil
-}
-
-// RegisterGATTService registra um serviço GATT
-func (a *LinuxBluetoothAdapter) RegisterGATTService(serviceUUID string, characteristicUUIDs []string) error {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-	
-	// Verificar se o adaptador está em execução
-	if !a.isRunning {
-		return fmt.Errorf("adaptador não está em execução")
-	}
-
-	// Implementação simplificada para compilação
-	fmt.Printf("Registrando serviço GATT %s com %d características\n", serviceUUID, len(characteristicUUIDs))
-
-	return nil
-}
-
-// UpdateCharacteristic atualiza o valor de uma característica GATT
-func (a *LinuxBluetoothAdapter) UpdateCharacteristic(serviceUUID, characteristicUUID string, value []byte) error {
-	a.mutex.RLock()
-	defer a.mutex.RUnlock()
-	
-	_, ok := a.gattCharacteristics[characteristicUUID]
-	if !ok {
-		return fmt.Errorf("característica %s não encontrada", characteristicUUID)
-	}
-	
-	// Implementação simplificada para evitar erros de compilação
-	return nil
-}
-
-// SetOnDeviceDiscoveredCallback define o callback para dispositivos descobertos
-func (a *LinuxBluetoothAdapter) SetOnDeviceDiscoveredCallback(callback func(device platform.BluetoothDevice)) {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-	
-	a.onDeviceDiscovered = callback
-}
-
-// SetOnCharacteristicReadCallback define o callback para leitura de características
-func (a *LinuxBluetoothAdapter) SetOnCharacteristicReadCallback(callback func(deviceID, serviceUUID, characteristicUUID string) []byte) {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-	
-	a.onCharacteristicRead = callback
-}
-
-// SetOnCharacteristicWriteCallback define o callback para escrita de características
-func (a *LinuxBluetoothAdapter) SetOnCharacteristicWriteCallback(callback func(deviceID, serviceUUID, characteristicUUID string, value []byte)) {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-	
-	a.onCharacteristicWrite = callback
-}
-
-// SetOnConnectionStateChangedCallback define o callback para mudanças de estado de conexão
-func (a *LinuxBluetoothAdapter) SetOnConnectionStateChangedCallback(callback func(deviceID string, connected bool)) {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-	
-	a.onConnectionStateChanged = callback
-}
-
-// SendData envia dados para um dispositivo
-func (a *LinuxBluetoothAdapter) SendData(deviceID string, serviceUUID, characteristicUUID string, data []byte) error {
-	a.mutex.RLock()
-	defer a.mutex.RUnlock()
-	
-	// Obter dispositivo
-	_, ok := a.devices[deviceID]
-	if !ok {
-		return fmt.Errorf("dispositivo %s não encontrado", deviceID)
-	}
-	
-	// Implementação simplificada para compilação
-	fmt.Printf("Enviando %d bytes para dispositivo %s (característica %s)\n", len(data), deviceID, characteristicUUID)
-	
-	return nil
-}
-
-// ReadCharacteristic lê o valor de uma característica
-func (a *LinuxBluetoothAdapter) ReadCharacteristic(deviceID, serviceUUID, characteristicUUID string) ([]byte, error) {
-	a.mutex.RLock()
-	defer a.mutex.RUnlock()
-	
-	// Obter dispositivo
-	_, ok := a.devices[deviceID]
-	if !ok {
-		return nil, fmt.Errorf("dispositivo %s não encontrado", deviceID)
-	}
-	
-	// Implementação simplificada para compilação
-	fmt.Printf("Lendo característica %s do dispositivo %s\n", characteristicUUID, deviceID)
-	
-	// Retornar dados vazios
-	return []byte{}, nil
-}
-
-// GetAdapterInfo retorna informações sobre o adaptador Bluetooth
-func (a *LinuxBluetoothAdapter) GetAdapterInfo() (platform.BluetoothAdapterInfo, error) {
-	a.mutex.RLock()
-	defer a.mutex.RUnlock()
-	
-	info := platform.BluetoothAdapterInfo{
-		Name:     "",
-		Address:  "",
-		Powered:  a.isRunning,
-	}
-	
-	// Obter informações adicionais se o adaptador estiver em execução
-	if a.isRunning && a.adapter != nil {
-		name, err := a.adapter.GetName()
-		if err != nil {
-			return platform.BluetoothAdapterInfo{}, fmt.Errorf("erro ao obter nome do adaptador: %v", err)
-		}
-		
-		address, err := a.adapter.GetAddress()
-		if err != nil {
-			return platform.BluetoothAdapterInfo{}, fmt.Errorf("erro ao obter endereço do adaptador: %v", err)
-		}
-		
-		info.Name = name
-		info.Address = address
-	}
-	
-	return info, nil
-}
-
-// Handlers internos para eventos
-
-func (a *LinuxBluetoothAdapter) handleDeviceFound(device *device.Device1) {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-	
-	// Armazenar dispositivo
-	deviceID := string(device.Path())
-	a.devices[deviceID] = device
-	
-	// Obter informações do dispositivo
-	name, _ := device.GetName()
-	address, _ := device.GetAddress()
-	rssi, _ := device.GetRSSI()
-	connected, _ := device.GetConnected()
-	
-	// Criar objeto de dispositivo
-	deviceInfo := platform.BluetoothDevice{
-		ID:        deviceID,
-		Name:      name,
-		Address:   address,
-		RSSI:      int(rssi),
-		Connected: connected,
-		ServiceData: make(map[string][]byte),
-	}
-	
-	// Armazenar informações
-	a.deviceInfo[deviceID] = deviceInfo
-	
-	// Notificar callback
-	if a.onDeviceDiscovered != nil {
-		a.onDeviceDiscovered(deviceInfo)
-	}
-	
-	// Monitoramento de conexão simplificado para compilação
-	fmt.Printf("Dispositivo encontrado: %s (%s)\n", name, address)
-}
-
-func (a *LinuxBluetoothAdapter) handleDeviceRemoved(devicePath dbus.ObjectPath) {
-	a.mutex.Lock()
-	defer a.mutex.Unlock()
-	
-	deviceID := string(devicePath)
-	
-	// Remover dispositivo
-	delete(a.devices, deviceID)
-	delete(a.deviceInfo, deviceID)
-	
-	// Notificar desconexão
-	if a.onConnectionStateChanged != nil {
-		a.onConnectionStateChanged(deviceID, false)
-	}
-}
@@ -0,0 +1,533 @@
+//go:build linux
+// +build linux
+
+package sim
+
+import (
+	"sync"
+
+	"github.com/permissionlesstech/bitchat/internal/bluetooth"
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+)
+
+// Adapter implementa bluetooth.MeshAdapter sobre um Airspace, no lugar do
+// LinuxBluetoothAdapter. Cada adaptador disca, como central, os peers em
+// alcance que anunciam o serviço Bitchat e lê a caixa de correio deles ao se
+// conectar, como o gerenciador de conexões do BlueZ. Os callbacks são
+// chamados em ordem numa goroutine própria do adaptador, como os sinais do
+// D-Bus, e nunca dentro de uma chamada ao adaptador.
+type Adapter struct {
+	airspace *Airspace
+	address  string
+
+	closed         bool
+	scanning       bool
+	advertising    bool
+	gattRegistered bool
+	deviceName     string
+	serviceData    []byte
+
+	links    map[string]bool // Peers discados por este adaptador como central
+	centrals map[string]bool // Centrais conectados ao servidor GATT deste adaptador
+	rssi     map[string]int  // Último RSSI de cada dispositivo descoberto
+
+	onDataReceived      func(data []byte, address string)
+	onAnnounce          func(address string, announce *protocol.CompactAnnounce) bool
+	onMailboxRead       func(address string) []byte
+	onRadioStateChanged func(state bluetooth.RadioState)
+	onHealthEvent       func(event bluetooth.HealthEvent)
+
+	mutex sync.RWMutex
+
+	// Fila de callbacks entregues pela goroutine do adaptador
+	events      []func()
+	eventsMutex sync.Mutex
+	wakeup      chan struct{}
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+func newAdapter(airspace *Airspace, address string) *Adapter {
+	a := &Adapter{
+		airspace: airspace,
+		address:  address,
+		links:    make(map[string]bool),
+		centrals: make(map[string]bool),
+		rssi:     make(map[string]int),
+		wakeup:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Address retorna o endereço do adaptador no meio de rádio
+func (a *Adapter) Address() string {
+	return a.address
+}
+
+// StartScanning passa a receber os anúncios dos adaptadores em alcance,
+// começando pelos que já estão anunciando
+func (a *Adapter) StartScanning() error {
+	a.mutex.Lock()
+	if a.closed {
+		a.mutex.Unlock()
+		return ErrNotRunning
+	}
+	a.scanning = true
+	a.mutex.Unlock()
+
+	for _, peer := range a.airspace.peers(a.address) {
+		peer := peer
+		a.dispatch(func() { a.observe(peer) })
+	}
+	return nil
+}
+
+// StopScanning deixa de receber anúncios
+func (a *Adapter) StopScanning() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.scanning = false
+	return nil
+}
+
+// IsScanning verifica se o escaneamento está ativo
+func (a *Adapter) IsScanning() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.scanning
+}
+
+// StartAdvertising anuncia o serviço Bitchat com o anúncio compacto nos
+// dados de serviço aos adaptadores em alcance que estejam escaneando
+func (a *Adapter) StartAdvertising(deviceName string, serviceData []byte) error {
+	a.mutex.Lock()
+	if a.closed {
+		a.mutex.Unlock()
+		return ErrNotRunning
+	}
+	a.advertising = true
+	a.deviceName = deviceName
+	a.serviceData = append([]byte(nil), serviceData...)
+	a.mutex.Unlock()
+
+	a.announce()
+	return nil
+}
+
+// StopAdvertising encerra o anúncio
+func (a *Adapter) StopAdvertising() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.advertising = false
+	return nil
+}
+
+// IsAdvertising verifica se o anúncio está ativo
+func (a *Adapter) IsAdvertising() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.advertising
+}
+
+// SetLocalPeerID não tem efeito: o adaptador simulado não filtra a descoberta
+func (a *Adapter) SetLocalPeerID(peerID []byte) {}
+
+// SetScanWhitelist não tem efeito: o adaptador simulado não filtra a descoberta
+func (a *Adapter) SetScanWhitelist(peerIDs []string) error {
+	return nil
+}
+
+// SetRadioProfile não tem efeito: anúncios simulados são percebidos na hora
+func (a *Adapter) SetRadioProfile(profile bluetooth.RadioProfile) error {
+	return nil
+}
+
+// SetConnectionConfig não tem efeito: o adaptador simulado não limita conexões
+func (a *Adapter) SetConnectionConfig(config bluetooth.ConnectionConfig) error {
+	return nil
+}
+
+// RegisterGATTService passa a aceitar escritas de centrais e a entregar
+// notificações. Os centrais que já perceberam o anúncio discam em seguida.
+func (a *Adapter) RegisterGATTService() error {
+	a.mutex.Lock()
+	if a.closed {
+		a.mutex.Unlock()
+		return ErrNotRunning
+	}
+	a.gattRegistered = true
+	a.mutex.Unlock()
+
+	a.announce()
+	return nil
+}
+
+// GATTRegistered informa se o serviço GATT está registrado
+func (a *Adapter) GATTRegistered() bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.gattRegistered
+}
+
+// NotifyData entrega dados aos centrais conectados ao servidor GATT, como
+// uma notificação da característica TX
+func (a *Adapter) NotifyData(data []byte) error {
+	a.mutex.RLock()
+	if !a.gattRegistered {
+		a.mutex.RUnlock()
+		return ErrGATTUnavailable
+	}
+	centrals := make([]string, 0, len(a.centrals))
+	for address := range a.centrals {
+		centrals = append(centrals, address)
+	}
+	a.mutex.RUnlock()
+
+	for _, address := range centrals {
+		if central, err := a.reach(address, false); err == nil {
+			central.deliver(data, a.address)
+		}
+	}
+	return nil
+}
+
+// SendData escreve na característica de dados do peer no endereço
+// informado, conectando-se a ele se necessário. Um novo enlace também lê a
+// caixa de correio do peer.
+func (a *Adapter) SendData(data []byte, address string) error {
+	peer, err := a.reach(address, true)
+	if err != nil {
+		return err
+	}
+
+	if a.connect(peer) {
+		a.dispatch(func() { a.pullMailbox(peer) })
+	}
+	peer.deliver(data, a.address)
+	return nil
+}
+
+// BroadcastData escreve nos peers discados por este adaptador
+func (a *Adapter) BroadcastData(data []byte) error {
+	a.mutex.RLock()
+	links := make([]string, 0, len(a.links))
+	for address := range a.links {
+		links = append(links, address)
+	}
+	a.mutex.RUnlock()
+
+	for _, address := range links {
+		if peer, err := a.reach(address, true); err == nil {
+			peer.deliver(data, a.address)
+		}
+	}
+	return nil
+}
+
+// StartBulkChannels não tem efeito: sem canais L2CAP, o provedor usa o GATT
+func (a *Adapter) StartBulkChannels() error {
+	return nil
+}
+
+// SendBulk sempre falha, levando o provedor a enviar pelo GATT
+func (a *Adapter) SendBulk(data []byte, address string) error {
+	return ErrBulkUnavailable
+}
+
+// LinkMTU informa que o MTU dos enlaces simulados não é negociado
+func (a *Adapter) LinkMTU(address string) (int, bool) {
+	return 0, false
+}
+
+// MinLinkMTU retorna o MTU assumido para enlaces sem MTU negociado
+func (a *Adapter) MinLinkMTU() int {
+	return bluetooth.MaxPacketSize
+}
+
+// RSSI retorna o sinal do último anúncio percebido do endereço informado
+func (a *Adapter) RSSI(address string) (int, bool) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	rssi, ok := a.rssi[address]
+	return rssi, ok
+}
+
+// ForgetRotatedAddress descarta um dispositivo cujo endereço foi
+// substituído, mantendo o enlace ainda ativo até cair
+func (a *Adapter) ForgetRotatedAddress(address string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if !a.links[address] {
+		delete(a.rssi, address)
+	}
+}
+
+// EnableBonding não tem efeito: enlaces simulados não são cifrados
+func (a *Adapter) EnableBonding(address string) error {
+	return nil
+}
+
+// DisableBonding não tem efeito: enlaces simulados não são cifrados
+func (a *Adapter) DisableBonding(address string) error {
+	return nil
+}
+
+// SetOnDataReceived define o callback para dados recebidos
+func (a *Adapter) SetOnDataReceived(callback func(data []byte, address string)) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.onDataReceived = callback
+}
+
+// SetOnAnnounce define o callback para anúncios compactos percebidos
+func (a *Adapter) SetOnAnnounce(callback func(address string, announce *protocol.CompactAnnounce) bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.onAnnounce = callback
+}
+
+// SetOnMailboxRead define quem entrega os pacotes guardados aos centrais
+// que leem a caixa de correio deste adaptador
+func (a *Adapter) SetOnMailboxRead(callback func(address string) []byte) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.onMailboxRead = callback
+}
+
+// SetOnRadioStateChanged define o callback de mudanças do rádio, que nunca
+// mudam no adaptador simulado
+func (a *Adapter) SetOnRadioStateChanged(callback func(state bluetooth.RadioState)) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.onRadioStateChanged = callback
+}
+
+// SetOnHealthEvent define o callback das etapas de recuperação, que o
+// adaptador simulado nunca precisa
+func (a *Adapter) SetOnHealthEvent(callback func(event bluetooth.HealthEvent)) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.onHealthEvent = callback
+}
+
+// IsConnected verifica se há enlace com o dispositivo, em qualquer papel
+func (a *Adapter) IsConnected(address string) bool {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.links[address] || a.centrals[address]
+}
+
+// Close encerra escaneamento, anúncio e enlaces, como a remoção do adaptador
+func (a *Adapter) Close() error {
+	a.mutex.Lock()
+	a.closed = true
+	a.scanning = false
+	a.advertising = false
+	a.gattRegistered = false
+	peers := make([]string, 0, len(a.links)+len(a.centrals))
+	for address := range a.links {
+		peers = append(peers, address)
+	}
+	for address := range a.centrals {
+		peers = append(peers, address)
+	}
+	a.mutex.Unlock()
+
+	for _, address := range peers {
+		if peer, ok := a.airspace.adapter(address); ok {
+			a.disconnect(peer)
+		}
+	}
+	a.closeOnce.Do(func() { close(a.done) })
+	return nil
+}
+
+// dispatch agenda um callback na goroutine do adaptador
+func (a *Adapter) dispatch(event func()) {
+	a.eventsMutex.Lock()
+	a.events = append(a.events, event)
+	a.eventsMutex.Unlock()
+
+	select {
+	case a.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// run entrega os callbacks agendados, em ordem, até o adaptador ser fechado
+func (a *Adapter) run() {
+	for {
+		select {
+		case <-a.done:
+			return
+		case <-a.wakeup:
+		}
+
+		a.eventsMutex.Lock()
+		events := a.events
+		a.events = nil
+		a.eventsMutex.Unlock()
+
+		for _, event := range events {
+			event()
+		}
+	}
+}
+
+// announce leva o anúncio deste adaptador aos demais adaptadores
+func (a *Adapter) announce() {
+	for _, peer := range a.airspace.peers(a.address) {
+		peer := peer
+		peer.dispatch(func() { peer.observe(a) })
+	}
+}
+
+// observe registra o anúncio de outro adaptador, se este estiver
+// escaneando e o outro anunciando em alcance, e disca o peer assim que ele
+// registrar o serviço GATT, lendo a caixa de correio dele
+func (a *Adapter) observe(peer *Adapter) {
+	rssi, inRange := a.airspace.inRange(a.address, peer.address)
+	if !inRange {
+		return
+	}
+
+	peer.mutex.RLock()
+	advertising := !peer.closed && peer.advertising
+	registered := peer.gattRegistered
+	serviceData := peer.serviceData
+	peer.mutex.RUnlock()
+	if !advertising {
+		return
+	}
+
+	a.mutex.Lock()
+	if a.closed || !a.scanning {
+		a.mutex.Unlock()
+		return
+	}
+	a.rssi[peer.address] = rssi
+	onAnnounce := a.onAnnounce
+	a.mutex.Unlock()
+
+	// A preferência só ordena a discagem; o adaptador simulado disca todos
+	if announce, err := protocol.DecodeCompactAnnounce(serviceData); err == nil && onAnnounce != nil {
+		onAnnounce(peer.address, announce)
+	}
+
+	if registered && a.connect(peer) {
+		a.pullMailbox(peer)
+	}
+}
+
+// reach localiza um adaptador remoto em execução e em alcance; gatt exige
+// também o serviço GATT registrado, necessário para escrever nele
+func (a *Adapter) reach(address string, gatt bool) (*Adapter, error) {
+	a.mutex.RLock()
+	closed := a.closed
+	a.mutex.RUnlock()
+	if closed {
+		return nil, ErrNotRunning
+	}
+
+	peer, ok := a.airspace.adapter(address)
+	if !ok {
+		return nil, ErrDeviceNotFound
+	}
+	peer.mutex.RLock()
+	closed, registered := peer.closed, peer.gattRegistered
+	peer.mutex.RUnlock()
+	if closed {
+		return nil, ErrDeviceNotFound
+	}
+
+	if _, inRange := a.airspace.inRange(a.address, address); !inRange {
+		return nil, ErrOutOfRange
+	}
+	if gatt && !registered {
+		return nil, ErrGATTUnavailable
+	}
+	return peer, nil
+}
+
+// connect estabelece o enlace com o peer, com este adaptador como central,
+// e informa se ele é novo
+func (a *Adapter) connect(peer *Adapter) bool {
+	a.mutex.Lock()
+	linked := a.links[peer.address]
+	a.links[peer.address] = true
+	a.mutex.Unlock()
+	if linked {
+		return false
+	}
+
+	peer.mutex.Lock()
+	peer.centrals[a.address] = true
+	peer.mutex.Unlock()
+	return true
+}
+
+// disconnect encerra os enlaces com o peer nos dois papéis
+func (a *Adapter) disconnect(peer *Adapter) {
+	a.mutex.Lock()
+	delete(a.links, peer.address)
+	delete(a.centrals, peer.address)
+	a.mutex.Unlock()
+
+	peer.mutex.Lock()
+	delete(peer.links, a.address)
+	delete(peer.centrals, a.address)
+	peer.mutex.Unlock()
+}
+
+// pullMailbox lê os pacotes que o peer recém-conectado guardou para este
+// adaptador e os repassa ao callback de dados
+func (a *Adapter) pullMailbox(peer *Adapter) {
+	peer.mutex.RLock()
+	onMailboxRead := peer.onMailboxRead
+	peer.mutex.RUnlock()
+
+	if onMailboxRead == nil {
+		return
+	}
+	for i := 0; i < mesh.DefaultMailboxCapacity; i++ {
+		data := onMailboxRead(a.address)
+		if len(data) == 0 {
+			return
+		}
+		a.receive(append([]byte(nil), data...), peer.address)
+	}
+}
+
+// deliver agenda a entrega de dados recebidos de outro adaptador
+func (a *Adapter) deliver(data []byte, from string) {
+	data = append([]byte(nil), data...)
+	a.dispatch(func() { a.receive(data, from) })
+}
+
+// receive repassa dados recebidos ao callback
+func (a *Adapter) receive(data []byte, from string) {
+	a.mutex.RLock()
+	closed := a.closed
+	onDataReceived := a.onDataReceived
+	a.mutex.RUnlock()
+
+	if !closed && onDataReceived != nil {
+		onDataReceived(data, from)
+	}
+}
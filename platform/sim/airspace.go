@@ -0,0 +1,139 @@
+//go:build linux
+// +build linux
+
+// Package sim oferece um adaptador Bluetooth simulado em software, para que
+// o provedor mesh Linux (descoberta, anúncio compacto, GATT, caixa de
+// correio) rode em CI sem hardware ou BlueZ.
+package sim
+
+import (
+	"errors"
+	"sync"
+)
+
+// DefaultRSSI é o sinal entre adaptadores sem um valor configurado
+const DefaultRSSI = -50
+
+// Erros do adaptador simulado
+var (
+	ErrNotRunning      = errors.New("adaptador simulado não está em execução")
+	ErrDeviceNotFound  = errors.New("dispositivo simulado não encontrado")
+	ErrOutOfRange      = errors.New("dispositivo simulado fora de alcance")
+	ErrGATTUnavailable = errors.New("serviço GATT não registrado no dispositivo")
+	ErrBulkUnavailable = errors.New("canais L2CAP indisponíveis no adaptador simulado")
+)
+
+// linkKey identifica o par de adaptadores de um enlace, independente da ordem
+type linkKey struct {
+	a, b string
+}
+
+func newLinkKey(a, b string) linkKey {
+	if a > b {
+		a, b = b, a
+	}
+	return linkKey{a, b}
+}
+
+// Airspace é o meio de rádio virtual compartilhado pelos adaptadores
+// simulados. Anúncios, escritas GATT e notificações só alcançam adaptadores
+// em alcance, com o RSSI configurado para cada par.
+type Airspace struct {
+	adapters   map[string]*Adapter // Endereço -> adaptador
+	rssi       map[linkKey]int
+	outOfRange map[linkKey]bool
+
+	mutex sync.RWMutex
+}
+
+// NewAirspace cria um meio de rádio vazio
+func NewAirspace() *Airspace {
+	return &Airspace{
+		adapters:   make(map[string]*Adapter),
+		rssi:       make(map[linkKey]int),
+		outOfRange: make(map[linkKey]bool),
+	}
+}
+
+// NewAdapter cria um adaptador simulado com o endereço informado e o
+// coloca no meio de rádio
+func (as *Airspace) NewAdapter(address string) *Adapter {
+	adapter := newAdapter(as, address)
+
+	as.mutex.Lock()
+	as.adapters[address] = adapter
+	as.mutex.Unlock()
+
+	return adapter
+}
+
+// SetRSSI define o sinal percebido entre dois adaptadores
+func (as *Airspace) SetRSSI(a, b string, rssi int) {
+	as.mutex.Lock()
+	defer as.mutex.Unlock()
+
+	as.rssi[newLinkKey(a, b)] = rssi
+}
+
+// SetInRange coloca dois adaptadores dentro ou fora do alcance um do outro.
+// Sair de alcance encerra os enlaces entre eles.
+func (as *Airspace) SetInRange(a, b string, inRange bool) {
+	as.mutex.Lock()
+	key := newLinkKey(a, b)
+	if inRange {
+		delete(as.outOfRange, key)
+	} else {
+		as.outOfRange[key] = true
+	}
+	first, second := as.adapters[a], as.adapters[b]
+	as.mutex.Unlock()
+
+	if first == nil || second == nil {
+		return
+	}
+	if inRange {
+		// Quem está escaneando percebe o anúncio do outro imediatamente
+		first.dispatch(func() { first.observe(second) })
+		second.dispatch(func() { second.observe(first) })
+		return
+	}
+	first.disconnect(second)
+}
+
+// inRange verifica se dois adaptadores se alcançam e retorna o sinal
+func (as *Airspace) inRange(a, b string) (int, bool) {
+	as.mutex.RLock()
+	defer as.mutex.RUnlock()
+
+	key := newLinkKey(a, b)
+	if as.outOfRange[key] {
+		return 0, false
+	}
+	if rssi, ok := as.rssi[key]; ok {
+		return rssi, true
+	}
+	return DefaultRSSI, true
+}
+
+// adapter retorna o adaptador de um endereço
+func (as *Airspace) adapter(address string) (*Adapter, bool) {
+	as.mutex.RLock()
+	defer as.mutex.RUnlock()
+
+	adapter, ok := as.adapters[address]
+	return adapter, ok
+}
+
+// peers retorna os demais adaptadores do meio de rádio
+func (as *Airspace) peers(address string) []*Adapter {
+	as.mutex.RLock()
+	defer as.mutex.RUnlock()
+
+	peers := make([]*Adapter, 0, len(as.adapters))
+	for peerAddress, adapter := range as.adapters {
+		if peerAddress != address {
+			peers = append(peers, adapter)
+		}
+	}
+	return peers
+}
@@ -0,0 +1,265 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/permissionlesstech/bitchat/internal/bluetooth"
+	"github.com/permissionlesstech/bitchat/internal/crypto"
+	"github.com/permissionlesstech/bitchat/internal/protocol"
+	"github.com/permissionlesstech/bitchat/pkg/mesh"
+	"github.com/permissionlesstech/bitchat/pkg/utils"
+	"github.com/permissionlesstech/bitchat/platform/sim"
+)
+
+// simulatedNode é um serviço mesh com o provedor Linux rodando sobre um
+// adaptador simulado
+type simulatedNode struct {
+	id       []byte
+	name     string
+	adapter  *sim.Adapter
+	provider *bluetooth.LinuxMeshProvider
+	service  *bluetooth.BluetoothMeshService
+	peers    chan string
+	messages chan *protocol.BitchatMessage
+}
+
+func newSimulatedNode(t *testing.T, airspace *sim.Airspace, name, address string, id []byte) *simulatedNode {
+	encryptionService, err := crypto.NewEncryptionService(&crypto.EncryptionConfig{UseEphemeralOnly: true})
+	if err != nil {
+		t.Fatalf("Erro ao criar serviço de criptografia: %v", err)
+	}
+
+	// O apelido é definido depois do início, para anunciar o nó
+	node := &simulatedNode{
+		id:       id,
+		name:     name,
+		adapter:  airspace.NewAdapter(address),
+		service:  bluetooth.NewBluetoothMeshService(id, "", encryptionService),
+		peers:    make(chan string, 16),
+		messages: make(chan *protocol.BitchatMessage, 16),
+	}
+	node.provider = bluetooth.NewLinuxMeshProviderWithAdapter(node.service, node.adapter)
+	node.service.SetPlatformProvider(node.provider)
+	node.service.Events().SubscribePeers(func(event bluetooth.PeerEvent) {
+		if event.Type == bluetooth.PeerDiscovered {
+			node.peers <- event.PeerID
+		}
+	})
+	node.service.Events().SubscribeMessages(func(event bluetooth.MessageEvent) {
+		node.messages <- event.Message
+	})
+
+	if err := node.service.Start(); err != nil {
+		t.Fatalf("Erro ao iniciar serviço: %v", err)
+	}
+	t.Cleanup(node.service.Stop)
+	return node
+}
+
+// announce envia o anúncio do nó, com apelido e chaves, aos vizinhos
+func (node *simulatedNode) announce(t *testing.T) {
+	t.Helper()
+
+	if err := node.service.SetNickname(node.name); err != nil {
+		t.Fatalf("Erro ao anunciar %s: %v", node.name, err)
+	}
+}
+
+// newLinkedPair cria dois nós em alcance, aguarda os enlaces e troca os
+// anúncios entre eles
+func newLinkedPair(t *testing.T, airspace *sim.Airspace) (*simulatedNode, *simulatedNode) {
+	t.Helper()
+
+	a := newSimulatedNode(t, airspace, "a", "AA:00", []byte("nodeaaaa"))
+	b := newSimulatedNode(t, airspace, "b", "BB:00", []byte("nodebbbb"))
+	waitFor(t, "enlace entre os adaptadores", func() bool {
+		return a.adapter.IsConnected("BB:00") && b.adapter.IsConnected("AA:00")
+	})
+
+	a.announce(t)
+	b.announce(t)
+	expectPeer(t, a.peers, string(b.id))
+	expectPeer(t, b.peers, string(a.id))
+	return a, b
+}
+
+// TestSimulatedMesh exercita o serviço mesh e o provedor Linux sem hardware
+// Bluetooth
+func TestSimulatedMesh(t *testing.T) {
+	t.Run("Descoberta pelos anúncios", func(t *testing.T) {
+		airspace := sim.NewAirspace()
+		airspace.SetRSSI("AA:00", "BB:00", -65)
+		a, b := newLinkedPair(t, airspace)
+
+		if rssi := a.provider.GetPeerSignalStrength(string(b.id)); rssi != -65 {
+			t.Errorf("RSSI esperado de -65, obtido %d", rssi)
+		}
+	})
+
+	t.Run("Entrega via GATT", func(t *testing.T) {
+		a, b := newLinkedPair(t, sim.NewAirspace())
+
+		packet := protocol.NewBroadcastPacket(protocol.MessageTypeMessage, a.id, []byte("olá, mesh"))
+		if err := a.provider.SendPacket(packet); err != nil {
+			t.Fatalf("Erro ao enviar pacote: %v", err)
+		}
+
+		message := expectMessage(t, b.messages)
+		if message.Content != "olá, mesh" || message.SenderPeerID != string(a.id) {
+			t.Errorf("Mensagem inesperada: %q de %q", message.Content, message.SenderPeerID)
+		}
+	})
+
+	t.Run("Payload comprimido", func(t *testing.T) {
+		a, b := newLinkedPair(t, sim.NewAirspace())
+
+		content := bytes.Repeat([]byte("mensagem longa repetida na malha. "), 40)
+		compressed, ok, err := utils.CompressIfNeeded(content, "text/plain")
+		if err != nil || !ok {
+			t.Fatalf("Texto deveria ser comprimido: %v", err)
+		}
+		packet := protocol.NewBroadcastPacket(protocol.MessageTypeMessage, a.id, compressed)
+		packet.Flags = protocol.PacketFlagCompressed
+		if err := a.provider.SendPacket(packet); err != nil {
+			t.Fatalf("Erro ao enviar pacote: %v", err)
+		}
+
+		if message := expectMessage(t, b.messages); message.Content != string(content) {
+			t.Errorf("Conteúdo descomprimido inesperado: %d bytes", len(message.Content))
+		}
+	})
+
+	t.Run("Fora de alcance", func(t *testing.T) {
+		airspace := sim.NewAirspace()
+		a, b := newLinkedPair(t, airspace)
+
+		airspace.SetInRange("AA:00", "BB:00", false)
+		if a.adapter.IsConnected("BB:00") {
+			t.Error("Sair de alcance deveria encerrar o enlace")
+		}
+		packet := protocol.NewBroadcastPacket(protocol.MessageTypeMessage, a.id, []byte("x"))
+		if err := a.provider.SendPacketTo(packet, string(b.id)); err == nil {
+			t.Error("Envio para peer fora de alcance deveria falhar")
+		}
+
+		// Voltar ao alcance refaz o enlace imediatamente
+		airspace.SetInRange("AA:00", "BB:00", true)
+		waitFor(t, "novo enlace", func() bool { return a.adapter.IsConnected("BB:00") })
+	})
+
+	t.Run("Leitura da caixa de correio", func(t *testing.T) {
+		airspace := sim.NewAirspace()
+		a, b := newLinkedPair(t, airspace)
+
+		// O envio falha, mas o pacote fica guardado para o destinatário
+		airspace.SetInRange("AA:00", "BB:00", false)
+		packet := protocol.NewBroadcastPacket(protocol.MessageTypeMessage, a.id, []byte("guardado"))
+		if err := a.provider.SendPacketTo(packet, string(b.id)); err == nil {
+			t.Fatal("Envio para peer fora de alcance deveria falhar")
+		}
+
+		// Ao reencontrar o peer, o destinatário lê o pacote guardado
+		airspace.SetInRange("AA:00", "BB:00", true)
+		if message := expectMessage(t, b.messages); message.Content != "guardado" {
+			t.Errorf("Mensagem inesperada: %q", message.Content)
+		}
+		select {
+		case duplicate := <-b.messages:
+			t.Errorf("Pacote lido mais de uma vez: %q", duplicate.Content)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
+
+// TestSimulatedReputation verifica que a penalidade por um pacote malformado
+// recai sobre o enlace que o entregou, e não sobre o remetente declarado
+func TestSimulatedReputation(t *testing.T) {
+	a, b := newLinkedPair(t, sim.NewAirspace())
+
+	victim := []byte("nodecccc")
+	packet := protocol.NewBroadcastPacket(protocol.MessageTypeTyping, victim, []byte{0xff})
+	packet.HopCount = 1 // Como se b apenas o retransmitisse
+	if err := b.provider.SendPacket(packet); err != nil {
+		t.Fatalf("Erro ao enviar pacote: %v", err)
+	}
+
+	waitFor(t, "penalidade do enlace", func() bool {
+		return a.service.GetPeerReputation(string(b.id)).Score < mesh.MaxPeerScore
+	})
+	if report := a.service.GetPeerReputation(string(victim)); report.Score != mesh.MaxPeerScore {
+		t.Errorf("Remetente forjado não deveria ser penalizado: %+v", report)
+	}
+}
+
+func TestSimulatedMACRotation(t *testing.T) {
+	airspace := sim.NewAirspace()
+	airspace.SetRSSI("AA:00", "BB:01", -70)
+	a, b := newLinkedPair(t, airspace)
+
+	// O peer reaparece com outro endereço MAC e a mesma identidade, que o
+	// anúncio compacto do advertising revela antes de qualquer pacote
+	b.service.Stop()
+	rotated := newSimulatedNode(t, airspace, "b", "BB:01", b.id)
+	waitFor(t, "associação do novo endereço", func() bool {
+		return a.provider.GetPeerSignalStrength(string(b.id)) == -70
+	})
+
+	select {
+	case duplicate := <-a.peers:
+		t.Fatalf("Rotação de endereço não deveria criar outro peer: %q", duplicate)
+	default:
+	}
+
+	// Envios pela identidade seguem para o novo endereço
+	payload := append([]byte{byte(len(a.name))}, a.name...)
+	packet := protocol.NewBroadcastPacket(protocol.MessageTypeAnnounce, a.id, payload)
+	if err := a.provider.SendPacketTo(packet, string(b.id)); err != nil {
+		t.Fatalf("Erro ao enviar após a rotação: %v", err)
+	}
+	expectPeer(t, rotated.peers, string(a.id))
+}
+
+// expectPeer aguarda a descoberta de um peer
+func expectPeer(t *testing.T, peers chan string, peerID string) {
+	t.Helper()
+
+	select {
+	case got := <-peers:
+		if got != peerID {
+			t.Errorf("Peer esperado %q, obtido %q", peerID, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Peer %q não descoberto", peerID)
+	}
+}
+
+// expectMessage aguarda a entrega de uma mensagem
+func expectMessage(t *testing.T, messages chan *protocol.BitchatMessage) *protocol.BitchatMessage {
+	t.Helper()
+
+	select {
+	case message := <-messages:
+		return message
+	case <-time.After(time.Second):
+		t.Fatal("Mensagem não foi entregue")
+		return nil
+	}
+}
+
+// waitFor aguarda uma condição do meio de rádio simulado
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Tempo esgotado aguardando %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}